@@ -0,0 +1,351 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"cuelang.org/go/cue/build"
+)
+
+// The attestation written by export --attest is an in-toto statement with
+// a SLSA provenance predicate. See https://in-toto.io/Statement/v1 and
+// https://slsa.dev/provenance/v1.
+const (
+	attestStatementType = "https://in-toto.io/Statement/v1"
+	attestPredicateType = "https://slsa.dev/provenance/v1"
+	attestBuildType     = "https://cuelang.org/attestation/export/v1"
+	attestBuilderID     = "https://cuelang.org/go/cmd/cue"
+)
+
+// attestEnv lists the environment variables that affect evaluation and
+// are therefore recorded in attestations.
+var attestEnv = []string{
+	"CUE_EXPERIMENT",
+	"CUE_PROXY",
+	"CUE_REGISTRY",
+}
+
+type attestStatement struct {
+	Type          string               `json:"_type"`
+	Subject       []attestResource     `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     attestProvenancePred `json:"predicate"`
+}
+
+type attestProvenancePred struct {
+	BuildDefinition struct {
+		BuildType            string           `json:"buildType"`
+		ExternalParameters   attestParams     `json:"externalParameters"`
+		InternalParameters   attestInternal   `json:"internalParameters"`
+		ResolvedDependencies []attestResource `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID      string            `json:"id"`
+			Version map[string]string `json:"version"`
+		} `json:"builder"`
+	} `json:"runDetails"`
+}
+
+type attestParams struct {
+	Args  []string       `json:"args"`
+	Flags map[string]any `json:"flags"`
+}
+
+type attestInternal struct {
+	Dir string            `json:"dir"`
+	Env map[string]string `json:"env"`
+}
+
+// attestResource is an in-toto resource descriptor.
+type attestResource struct {
+	Name        string            `json:"name"`
+	Digest      map[string]string `json:"digest,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// checkAttestable reports an error if the inputs of b cannot be recorded
+// in an attestation.
+func checkAttestable(b *buildPlan) error {
+	for _, f := range attestDataFiles(b) {
+		if f.Filename == "-" {
+			return fmt.Errorf("--%s cannot be used when reading from stdin", flagAttest)
+		}
+	}
+	return nil
+}
+
+// attestDataFiles returns the data files read by b: those given on the
+// command line and, when importing, those found in package directories.
+func attestDataFiles(b *buildPlan) []*build.File {
+	var files []*build.File
+	if b.orphanInstance != nil {
+		files = append(files, b.orphanInstance.OrphanedFiles...)
+	}
+	if b.importing {
+		for _, inst := range b.insts {
+			if inst.User {
+				continue
+			}
+			for _, f := range inst.OrphanedFiles {
+				if b.matchFile(filepath.Base(f.Filename)) {
+					files = append(files, f)
+				}
+			}
+		}
+	}
+	return files
+}
+
+// writeAttestation writes an attestation of the inputs of b and of the
+// output with the given name and SHA-256 digest to the file given with
+// --attest.
+func writeAttestation(cmd *Command, b *buildPlan, args []string, output string, digest []byte) error {
+	a, err := newAttester()
+	if err != nil {
+		return err
+	}
+	insts := b.insts
+	if b.schemaInst != nil {
+		insts = append(insts[:len(insts):len(insts)], b.schemaInst)
+	}
+	if err := a.addInstances(insts); err != nil {
+		return err
+	}
+	for _, f := range attestDataFiles(b) {
+		if err := a.addFile(f.Filename, nil); err != nil {
+			return err
+		}
+	}
+
+	var s attestStatement
+	s.Type = attestStatementType
+	s.PredicateType = attestPredicateType
+	if output != "-" {
+		output = a.name(output)
+	}
+	s.Subject = []attestResource{{
+		Name:   output,
+		Digest: map[string]string{"sha256": hex.EncodeToString(digest)},
+	}}
+
+	def := &s.Predicate.BuildDefinition
+	def.BuildType = attestBuildType
+	def.ExternalParameters.Args = append([]string{}, args...)
+	def.ExternalParameters.Flags = map[string]any{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		switch flagName(f.Name) {
+		case flagAttest, flagForce, flagVerbose:
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			def.ExternalParameters.Flags[f.Name] = sv.GetSlice()
+		} else {
+			def.ExternalParameters.Flags[f.Name] = f.Value.String()
+		}
+	})
+	def.InternalParameters.Dir = a.cwd
+	def.InternalParameters.Env = map[string]string{}
+	for _, key := range attestEnv {
+		if v, ok := os.LookupEnv(key); ok {
+			def.InternalParameters.Env[key] = v
+		}
+	}
+	def.ResolvedDependencies = a.resources()
+
+	builder := &s.Predicate.RunDetails.Builder
+	builder.ID = attestBuilderID
+	builder.Version = map[string]string{"cue": toolVersion()}
+
+	data, err := json.MarshalIndent(&s, "", "    ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return writeOutputFile(flagAttest.String(cmd), data, flagForce.Bool(cmd))
+}
+
+// writeOutputFile writes data to the named file, which must not exist
+// unless force is set.
+func writeOutputFile(name string, data []byte, force bool) error {
+	mode := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(name, mode, 0o644)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("error writing %q: %w", name, err)
+		}
+		return err
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err1 != nil && err == nil {
+		err = err1
+	}
+	return err
+}
+
+// An attester collects the files read by an evaluation and the modules
+// they belong to.
+type attester struct {
+	cwd      string
+	modCache string
+
+	files   map[string]string // name to SHA-256 digest
+	modules map[string]bool
+}
+
+func newAttester() (*attester, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	modCache, err := modCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &attester{
+		cwd:      cwd,
+		modCache: modCache,
+		files:    map[string]string{},
+		modules:  map[string]bool{},
+	}, nil
+}
+
+// addInstances adds the files of insts and of all the packages they
+// import, along with the module files of their modules.
+func (a *attester) addInstances(insts []*build.Instance) error {
+	seen := map[*build.Instance]bool{}
+	for _, inst := range insts {
+		for _, p := range append([]*build.Instance{inst}, inst.Dependencies()...) {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			if p.Root != "" {
+				modFile := filepath.Join(p.Root, "cue.mod", "module.cue")
+				if _, err := os.Stat(modFile); err == nil {
+					if err := a.addFile(modFile, nil); err != nil {
+						return err
+					}
+				}
+			}
+			for _, f := range p.BuildFiles {
+				var data []byte
+				switch src := f.Source.(type) {
+				case []byte:
+					data = src
+				case string:
+					data = []byte(src)
+				case nil:
+				default:
+					return fmt.Errorf("cannot attest %s: contents not available", f.Filename)
+				}
+				if err := a.addFile(f.Filename, data); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// Dependencies are loaded from the module cache, where the root of
+	// each module is named after its version.
+	for m := range a.modules {
+		modFile := filepath.Join(a.modCache, filepath.FromSlash(m), "cue.mod", "module.cue")
+		if _, err := os.Stat(modFile); err == nil {
+			if err := a.addFile(modFile, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addFile adds the file with the given name and contents. If data is nil,
+// the file is read.
+func (a *attester) addFile(filename string, data []byte) error {
+	if data == nil {
+		var err error
+		if data, err = os.ReadFile(filename); err != nil {
+			return err
+		}
+	}
+	sum := sha256.Sum256(data)
+	a.files[a.name(filename)] = hex.EncodeToString(sum[:])
+	return nil
+}
+
+// name returns the name under which a file is recorded. Files in the
+// module cache are named by the module version they belong to, as in
+// foo.com/bar@v1.2.3/baz/baz.cue, and files in the current directory are
+// named relative to it.
+func (a *attester) name(filename string) string {
+	filename, _ = filepath.Abs(filename)
+	if rel, ok := relPath(a.modCache, filename); ok {
+		elems := strings.Split(rel, "/")
+		for i, e := range elems {
+			if strings.Contains(e, "@") {
+				a.modules[strings.Join(elems[:i+1], "/")] = true
+				break
+			}
+		}
+		return rel
+	}
+	if rel, ok := relPath(a.cwd, filename); ok {
+		return rel
+	}
+	return filepath.ToSlash(filename)
+}
+
+// relPath returns filename relative to dir, using forward slashes, if it
+// is within dir.
+func relPath(dir, filename string) (string, bool) {
+	rel, err := filepath.Rel(dir, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// resources returns the modules and files collected by a, ordered by name.
+func (a *attester) resources() []attestResource {
+	var r []attestResource
+	for m := range a.modules {
+		r = append(r, attestResource{
+			Name:        m,
+			Annotations: map[string]string{"type": "module"},
+		})
+	}
+	for name, sum := range a.files {
+		r = append(r, attestResource{
+			Name:   name,
+			Digest: map[string]string{"sha256": sum},
+		})
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Name < r[j].Name })
+	return r
+}
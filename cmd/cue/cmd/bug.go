@@ -0,0 +1,182 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/internal/bug"
+)
+
+func newBugCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bug <cmd> [arguments]",
+		Short: "prepare reproducers for bug reports",
+		Long: `Bug provides commands that turn a configuration that triggers a bug in
+cue into a self-contained reproducer that can be attached to a bug report.
+
+Reproducers are written as txtar archives holding a module. The comment
+at the top of the archive shows the command that reproduces the problem
+and its output.
+`,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			stderr := cmd.Stderr()
+			if len(args) == 0 {
+				fmt.Fprintln(stderr, "bug must be run as one of its subcommands")
+			} else {
+				fmt.Fprintf(stderr, "bug must be run as one of its subcommands: unknown subcommand %q\n", args[0])
+			}
+			fmt.Fprintln(stderr, "Run 'cue help bug' for known subcommands.")
+			os.Exit(1) // TODO: get rid of this
+			return nil
+		}),
+	}
+
+	cmd.AddCommand(newBugMinimizeCmd(c))
+	cmd.AddCommand(newBugObfuscateCmd(c))
+	return cmd
+}
+
+func newBugMinimizeCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "minimize [packages]",
+		Short: "write a minimal reproducer for an error",
+		Long: `Minimize writes the smallest reproducer it can find for an error
+reported for the given packages, as cue vet would report it.
+
+The error is selected with the --match flag, a regular expression that
+is matched against the messages of the errors, prefixed by their path,
+as in
+
+	a.b: conflicting values 1 and 2
+
+Without --match, the first error is used. To reproduce an assertion that
+does not hold, such as a value that is unexpectedly not concrete, add a
+field that fails in that case and select its error.
+
+Minimize repeatedly removes files, declarations, list elements and
+imports, and replaces operations such as & and | by one of their
+operands, keeping each change after which the error is still reported.
+Packages of the current module that are imported by the given packages
+are minimized along with them. This can take a while for large
+configurations.
+
+The result can be passed to cue bug obfuscate to also hide names and
+strings.
+
+Example:
+
+	$ cue bug minimize --match 'replicas: invalid value' ./service > repro.txtar
+`,
+		RunE: mkRunE(c, runBugMinimize),
+	}
+	cmd.Flags().BoolP(string(flagConcrete), "c", false,
+		"require the evaluation to be concrete, as for cue vet -c")
+	cmd.Flags().String(string(flagMatch), "",
+		"regular expression selecting the error to reproduce")
+	cmd.Flags().StringP(string(flagOutFile), "o", "",
+		"write the reproducer to the given file instead of standard output")
+	return cmd
+}
+
+func runBugMinimize(cmd *Command, args []string) error {
+	binst := loadFromArgs(args, nil)
+	if binst == nil {
+		return nil
+	}
+	var match *regexp.Regexp
+	if s := flagMatch.String(cmd); s != "" {
+		var err error
+		if match, err = regexp.Compile(s); err != nil {
+			return fmt.Errorf("invalid --match: %v", err)
+		}
+	}
+	a, err := bug.Minimize(binst, &bug.Config{
+		Concrete: flagConcrete.Bool(cmd),
+	}, match)
+	if err != nil {
+		return err
+	}
+	return writeArchive(cmd, a)
+}
+
+func newBugObfuscateCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "obfuscate [packages]",
+		Short: "write an anonymized reproducer for a configuration",
+		Long: `Obfuscate writes a reproducer for the given packages in which all
+identifiers, labels, package and file names, and strings are
+consistently replaced with generated names, so that it can be shared
+without revealing proprietary information. Comments and attributes are
+removed. The structure of the configuration, numbers, and uses of
+builtins and of packages from other modules are kept.
+
+Packages of the current module that are imported by the given packages
+are included in the reproducer.
+
+Obfuscate checks that the reproducer reports errors for the same
+fields as the original configuration, as cue vet would. If replacing
+all strings changes the errors, for instance because some strings are
+used as regular expressions, only strings that are also used as field
+names are replaced. If the errors still differ, a warning is printed and
+the reproducer should be checked by hand.
+
+Always review the output before sharing it.
+
+Example:
+
+	$ cue bug obfuscate ./service > repro.txtar
+`,
+		RunE: mkRunE(c, runBugObfuscate),
+	}
+	cmd.Flags().BoolP(string(flagConcrete), "c", false,
+		"require the evaluation to be concrete, as for cue vet -c")
+	cmd.Flags().StringP(string(flagOutFile), "o", "",
+		"write the reproducer to the given file instead of standard output")
+	return cmd
+}
+
+func runBugObfuscate(cmd *Command, args []string) error {
+	binst := loadFromArgs(args, nil)
+	if binst == nil {
+		return nil
+	}
+	a, preserved, err := bug.Obfuscate(binst, &bug.Config{
+		Concrete: flagConcrete.Bool(cmd),
+	})
+	if err != nil {
+		return err
+	}
+	if !preserved {
+		fmt.Fprintln(cmd.ErrOrStderr(), "warning: the reproducer does not report the same errors as the original configuration")
+	}
+	return writeArchive(cmd, a)
+}
+
+// writeArchive writes a to the file named by the --outfile flag, or to
+// standard output if it is not set.
+func writeArchive(cmd *Command, a *txtar.Archive) error {
+	b := txtar.Format(a)
+	if out := flagOutFile.String(cmd); out != "" && out != "-" {
+		return os.WriteFile(out, b, 0o666)
+	}
+	_, err := cmd.OutOrStdout().Write(b)
+	return err
+}
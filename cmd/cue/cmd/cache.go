@@ -0,0 +1,217 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/tools/cachedir"
+)
+
+// resultCache records the results of commands, keyed by a hash of the
+// transitive inputs of the packages they operate on and of the options
+// affecting their outcome, so that later invocations with the same inputs
+// can reuse them. The cache is stored in the file system and thus shared
+// by all processes.
+type resultCache struct {
+	cacheDir *cachedir.Dir
+	cache    *cachedir.Cache
+	dir      string
+	options  string
+}
+
+// vetCacheResult is the outcome of vetting a package that is recorded in
+// the cache. Packages with errors are not recorded.
+type vetCacheResult string
+
+const (
+	vetCacheOK         vetCacheResult = "ok"
+	vetCacheIncomplete vetCacheResult = "incomplete"
+)
+
+// newResultCache returns the cache for the results of cmd, which are
+// stored in cache c of the cache directory.
+func newResultCache(cmd *Command, c *cachedir.Cache) (*resultCache, error) {
+	cacheDir, err := cachedir.Default()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := cacheDir.Create(c)
+	if err != nil {
+		return nil, err
+	}
+	// The options include the version of cue, the working directory, and
+	// all flags that may affect the outcome of the command.
+	var b strings.Builder
+	fmt.Fprintf(&b, "version %s\n", toolVersion())
+	if wd, err := os.Getwd(); err == nil {
+		fmt.Fprintf(&b, "dir %s\n", wd)
+	}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		switch flagName(f.Name) {
+		case flagCache, flagVerbose:
+			return
+		}
+		fmt.Fprintf(&b, "%s=%s %v\n", f.Name, f.Value, f.Changed)
+	})
+	return &resultCache{
+		cacheDir: cacheDir,
+		cache:    c,
+		dir:      dir,
+		options:  b.String(),
+	}, nil
+}
+
+// toolVersion identifies the build of cue, so that results are not
+// reused across versions.
+func toolVersion() string {
+	s := version
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return s
+	}
+	s += " " + bi.Main.Version
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision", "vcs.modified":
+			s += " " + setting.Value
+		}
+	}
+	return s
+}
+
+// key returns the key for insts and the data files given on their own,
+// which is a hash of the options of the cache and the files of insts, all
+// of their dependencies, and the data files. It returns an empty key if
+// any of these files cannot be read.
+func (c *resultCache) key(data []*build.File, insts ...*build.Instance) string {
+	h := sha256.New()
+	io.WriteString(h, c.options)
+
+	seen := map[*build.Instance]bool{}
+	var all []*build.Instance
+	for _, inst := range insts {
+		if data, err := os.ReadFile(filepath.Join(inst.Root, "cue.mod", "module.cue")); err == nil {
+			fmt.Fprintf(h, "module %d\n", len(data))
+			h.Write(data)
+		}
+		deps := inst.Dependencies()
+		sort.Slice(deps, func(i, j int) bool {
+			return deps[i].ImportPath < deps[j].ImportPath
+		})
+		for _, p := range append([]*build.Instance{inst}, deps...) {
+			if !seen[p] {
+				seen[p] = true
+				all = append(all, p)
+			}
+		}
+	}
+	for _, p := range all {
+		fmt.Fprintf(h, "package %s %s\n", p.ImportPath, p.PkgName)
+		// Data files given along with a package are among its orphaned
+		// files.
+		for _, files := range [][]*build.File{p.BuildFiles, p.OrphanedFiles} {
+			for _, f := range files {
+				if !hashFile(h, f) {
+					return ""
+				}
+			}
+		}
+	}
+	io.WriteString(h, "data\n")
+	for _, f := range data {
+		if !hashFile(h, f) {
+			return ""
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashFile writes the name and contents of f to h. It reports whether the
+// contents could be read.
+func hashFile(h io.Writer, f *build.File) bool {
+	var data []byte
+	switch src := f.Source.(type) {
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	case nil:
+		if f.Filename == "-" {
+			return false
+		}
+		var err error
+		if data, err = os.ReadFile(f.Filename); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+	fmt.Fprintf(h, "file %s %s %d\n", f.Filename, f.Encoding, len(data))
+	h.Write(data)
+	return true
+}
+
+// lookup returns the recorded result for key, if any.
+func (c *resultCache) lookup(key string) ([]byte, bool) {
+	if key == "" {
+		return nil, false
+	}
+	file := filepath.Join(c.dir, key)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	cachedir.MarkUsed(file)
+	return data, true
+}
+
+// record records the result for key. Failures to write to the cache are
+// ignored, as they only affect performance.
+func (c *resultCache) record(key string, data []byte) {
+	if key == "" {
+		return
+	}
+	unlock, err := c.cacheDir.RLock(c.cache)
+	if err != nil {
+		return
+	}
+	defer unlock()
+	f, err := os.CreateTemp(c.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(c.dir, key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
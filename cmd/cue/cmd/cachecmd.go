@@ -0,0 +1,171 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/tools/cachedir"
+)
+
+func newCacheCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache <cmd> [arguments]",
+		Short: "inspect and clean the caches of cue",
+		Long: `Cache provides commands that report and reclaim the disk space used by
+the caches of cue. The caches are kept in subdirectories of the cache
+directory, $CUE_CACHE_DIR, which defaults to the cue directory of the
+user's cache directory:
+
+	mod     downloaded modules; overridden by $CUE_MODCACHE
+	vet     packages that passed cue vet --cache
+	export  output recorded by cue export --cache
+	lsp     indexes of language servers
+
+See "cue help environment" for details.
+`,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			stderr := cmd.Stderr()
+			if len(args) == 0 {
+				fmt.Fprintln(stderr, "cache must be run as one of its subcommands")
+			} else {
+				fmt.Fprintf(stderr, "cache must be run as one of its subcommands: unknown subcommand %q\n", args[0])
+			}
+			fmt.Fprintln(stderr, "Run 'cue help cache' for known subcommands.")
+			os.Exit(1) // TODO: get rid of this
+			return nil
+		}),
+	}
+
+	cmd.AddCommand(newCacheInfoCmd(c))
+	cmd.AddCommand(newCacheCleanCmd(c))
+	return cmd
+}
+
+func newCacheInfoCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "info [<cache>...]",
+		Short: "report the disk usage of the caches",
+		Long: `Info prints the directory, the number of files and the size of the
+given caches, or of all caches if none is given.
+`,
+		RunE: mkRunE(c, runCacheInfo),
+	}
+	return cmd
+}
+
+func runCacheInfo(cmd *Command, args []string) error {
+	dir, caches, err := cachesFromArgs(args)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, c := range caches {
+		u, err := dir.Usage(c)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d files\t%s\n", c.Name, dir.Path(c), u.Files, formatSize(u.Size))
+	}
+	return w.Flush()
+}
+
+func newCacheCleanCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clean [<cache>...]",
+		Short: "remove the contents of the caches",
+		Long: `Clean removes the contents of the given caches, or of all caches if none
+is given.
+
+With --unused, only the files that have not been used for the given
+duration are removed, from the caches that can be trimmed in this way:
+the caches of recorded results, but not the module cache, whose files
+depend on each other. If no cache is given, the other caches are left
+untouched.
+
+Examples:
+
+	$ cue cache clean
+	$ cue cache clean mod
+	$ cue cache clean --unused 720h
+`,
+		RunE: mkRunE(c, runCacheClean),
+	}
+	cmd.Flags().Duration(string(flagUnused), 0,
+		"only remove the files not used for this long")
+	return cmd
+}
+
+func runCacheClean(cmd *Command, args []string) error {
+	dir, caches, err := cachesFromArgs(args)
+	if err != nil {
+		return err
+	}
+	unused := flagUnused.Duration(cmd)
+	for _, c := range caches {
+		if unused == 0 {
+			if err := dir.Clean(c); err != nil {
+				return err
+			}
+			continue
+		}
+		if !c.Evictable && len(args) == 0 {
+			continue
+		}
+		if _, err := dir.Trim(c, unused); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cachesFromArgs returns the cache directory and the caches named by
+// args, or all caches if args is empty.
+func cachesFromArgs(args []string) (*cachedir.Dir, []*cachedir.Cache, error) {
+	dir, err := cachedir.Default()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(args) == 0 {
+		return dir, cachedir.Caches(), nil
+	}
+	var caches []*cachedir.Cache
+	for _, name := range args {
+		c := cachedir.Lookup(name)
+		if c == nil {
+			return nil, nil, fmt.Errorf("unknown cache %q", name)
+		}
+		caches = append(caches, c)
+	}
+	return dir, caches, nil
+}
+
+// formatSize formats a number of bytes for humans.
+func formatSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
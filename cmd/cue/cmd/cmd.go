@@ -132,6 +132,36 @@ the second to standard output (i.e. it echos it again).
 		}
 	}
 
+The --dry-run flag prints the tasks of a command in the order in
+which they would run, along with the tasks they depend on and
+their inputs, without running any of them. Inputs that depend on
+the results of other tasks are printed as far as they are known.
+
+	$ cue cmd --dry-run prompter
+
+The --allow-tasks and --write-dir flags restrict what a command may
+do, which is useful when running commands from untrusted packages.
+The --allow-tasks flag lists the kinds of tasks that may be run.
+Each entry is a task kind, such as tool/file.Read, a pattern such
+as tool/file.*, or the name of a tool package, such as exec, which
+is short for tool/exec.*. If the flag is not set, all kinds of tasks
+may be run. The --write-dir flag restricts the files created or
+removed by tool/file tasks to be within the given directory. Note
+that this does not restrict what tool/exec tasks may do.
+
+Violations are reported before any task is run. Tasks with inputs
+that depend on other tasks are checked again before they run.
+
+	$ cue cmd --allow-tasks=cli,file --write-dir=out gen
+
+The --report flag writes a report listing each task that was part
+of the command, whether it passed, failed or was skipped, how long it
+took, and its results, which CI systems can use to display the
+outcome of a command. The report is written as JUnit XML if the file
+name ends in .xml, and as JSON otherwise.
+
+	$ cue cmd --report=report.xml gen
+
 Run "cue help commands" for more details on tasks and commands.
 `,
 		RunE: mkRunE(c, func(cmd *Command, args []string) error {
@@ -182,6 +212,14 @@ Run "cue help commands" for more details on tasks and commands.
 	cmd.Flags().SetInterspersed(false)
 
 	addInjectionFlags(cmd.Flags(), true, false)
+	cmd.Flags().Bool(string(flagDryRun), false,
+		"print the tasks of the command and their inputs without running them")
+	cmd.Flags().StringSlice(string(flagAllowTasks), nil,
+		"comma-separated list of task kinds the command may run, such as exec or tool/file.Read")
+	cmd.Flags().String(string(flagWriteDir), "",
+		"restrict the files written by tool/file tasks to be within this directory")
+	cmd.Flags().String(string(flagReport), "",
+		"write a report of the tasks run to this file, as JUnit XML if it ends in .xml or as JSON otherwise")
 
 	return cmd
 }
@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -34,11 +35,15 @@ import (
 	"cuelang.org/go/cue/load"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/sops"
+	cueyaml "cuelang.org/go/encoding/yaml"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
 	"cuelang.org/go/internal/value"
+	"cuelang.org/go/tools/incomplete"
+	"cuelang.org/go/tools/redact"
 )
 
 var requestedVersion = os.Getenv("CUE_SYNTAX_OVERRIDE")
@@ -50,29 +55,42 @@ func defaultConfig() (*config, error) {
 	}
 	return &config{
 		loadCfg: &load.Config{
-			ParseFile: func(name string, src interface{}) (*ast.File, error) {
-				version := internal.APIVersionSupported
-				if requestedVersion != "" {
-					switch {
-					case strings.HasPrefix(requestedVersion, "v0.1"):
-						version = -1000 + 100
-					}
-				}
-				options := []parser.Option{
-					parser.FromVersion(version),
-					parser.ParseComments,
-				}
-				// TODO: consolidate all options into a single CUE_DEBUG variable.
-				if os.Getenv("CUE_DEBUG_PARSER_TRACE") != "" {
-					options = append(options, parser.Trace)
-				}
-				return parser.ParseFile(name, src, options...)
-			},
-			Registry: reg,
+			ParseFile:           parseFileFunc(),
+			Registry:            reg,
+			ExplainImportCycles: true,
+			Decrypter:           &sops.Command{},
 		},
 	}, nil
 }
 
+// parseFileFunc returns the function used to parse CUE files, or nil
+// to let the loader parse files according to the language version of
+// the module they belong to.
+func parseFileFunc() func(name string, src interface{}) (*ast.File, error) {
+	// TODO: consolidate all options into a single CUE_DEBUG variable.
+	trace := os.Getenv("CUE_DEBUG_PARSER_TRACE") != ""
+	if requestedVersion == "" && !trace {
+		return nil
+	}
+	return func(name string, src interface{}) (*ast.File, error) {
+		version := internal.APIVersionSupported
+		if requestedVersion != "" {
+			switch {
+			case strings.HasPrefix(requestedVersion, "v0.1"):
+				version = -1000 + 100
+			}
+		}
+		options := []parser.Option{
+			parser.FromVersion(version),
+			parser.ParseComments,
+		}
+		if trace {
+			options = append(options, parser.Trace)
+		}
+		return parser.ParseFile(name, src, options...)
+	}
+}
+
 var inTest = false
 
 func getLang() language.Tag {
@@ -89,6 +107,22 @@ func exitOnErr(cmd *Command, err error, fatal bool) {
 		return
 	}
 
+	switch cmd.errorFormat() {
+	case errorFormatGitHub:
+		printGitHubDiagnostics(cmd.Stderr(), err)
+		if fatal {
+			exit()
+		}
+		return
+	case errorFormatGitLab:
+		cmd.addGitLabDiagnostics(err)
+		cmd.hasErr = true
+		if fatal {
+			exit()
+		}
+		return
+	}
+
 	// Link x/text as our localizer.
 	p := message.NewPrinter(getLang())
 	format := func(w io.Writer, format string, args ...interface{}) {
@@ -116,6 +150,7 @@ func loadFromArgs(args []string, cfg *load.Config) []*build.Instance {
 	if len(binst) == 0 {
 		return nil
 	}
+	addStatsPackages(binst)
 
 	return binst
 }
@@ -146,8 +181,21 @@ type buildPlan struct {
 	imported []*ast.File
 
 	expressions []ast.Expr // only evaluate these expressions within results
+	exprSources []string   // the source of each expression
 	schema      ast.Expr   // selects schema in instance for orphaned values
 
+	// keyed indicates that the results of expressions are combined into a
+	// single struct, keyed by the source of each expression.
+	keyed bool
+
+	// schemaImport holds the import path of the package in which schema is
+	// evaluated if it refers to a schema in a module registry.
+	schemaImport string
+
+	// schemaInst holds the instance in which schema is evaluated, if data
+	// files are checked against a schema.
+	schemaInst *build.Instance
+
 	// orphan placement flags.
 	perFile    bool
 	useList    bool
@@ -157,6 +205,11 @@ type buildPlan struct {
 	// outFile defines the file to output to. Default is CUE stdout.
 	outFile *build.File
 
+	// jobs is the number of instances that are evaluated concurrently
+	// when there are several. Instances are evaluated one at a time if
+	// it is less than two.
+	jobs int
+
 	encConfig *encoding.Config
 }
 
@@ -168,6 +221,8 @@ func (b *buildPlan) instances() iterator {
 	switch {
 	case len(b.orphaned) > 0:
 		i = newStreamingIterator(b)
+	case len(b.insts) > 1 && b.jobs > 1 && b.instance == nil:
+		i = newParallelIterator(b.cmd, b.insts, b.jobs)
 	case len(b.insts) > 0:
 		i = &instanceIterator{
 			inst: b.instance,
@@ -182,11 +237,15 @@ func (b *buildPlan) instances() iterator {
 		b.instance = nil
 	}
 	if len(b.expressions) > 0 {
-		return &expressionIter{
+		iter := &expressionIter{
 			iter: i,
 			expr: b.expressions,
 			i:    len(b.expressions),
 		}
+		if b.keyed {
+			iter.keys = b.exprSources
+		}
+		return iter
 	}
 	return i
 }
@@ -245,6 +304,10 @@ type streamingIterator struct {
 	v   cue.Value
 	f   *ast.File
 	e   error
+
+	// data holds the current value as decoded, before it is unified with
+	// the schema.
+	data cue.Value
 }
 
 func newStreamingIterator(b *buildPlan) *streamingIterator {
@@ -298,6 +361,7 @@ func (i *streamingIterator) scan() bool {
 		return false
 	}
 	i.v = v
+	i.data = v
 	if schema := i.b.encConfig.Schema; schema.Exists() {
 		i.v = i.v.Unify(schema) // TODO(required fields): don't merge in schema
 		i.e = i.v.Err()
@@ -328,10 +392,19 @@ func (i *streamingIterator) err() error {
 	return i.e
 }
 
+// expressionIter evaluates expressions within the values of iter. All
+// expressions are evaluated within the same value, so that the work of
+// evaluating it is shared.
 type expressionIter struct {
 	iter iterator
 	expr []ast.Expr
 	i    int
+
+	// keys, if non-nil, holds a label for each expression. The results
+	// are then combined into a single struct for each value of iter.
+	keys []string
+
+	v cue.Value // the current value of iter
 }
 
 func (i *expressionIter) err() error { return i.iter.err() }
@@ -340,12 +413,13 @@ func (i *expressionIter) id() string { return i.iter.id() }
 
 func (i *expressionIter) scan() bool {
 	i.i++
-	if i.i < len(i.expr) {
+	if i.i < len(i.expr) && i.keys == nil {
 		return true
 	}
 	if !i.iter.scan() {
 		return false
 	}
+	i.v = i.iter.value()
 	i.i = 0
 	return true
 }
@@ -354,11 +428,21 @@ func (i *expressionIter) file() *ast.File { return nil }
 
 func (i *expressionIter) value() cue.Value {
 	if len(i.expr) == 0 {
-		return i.iter.value()
+		return i.v
 	}
-	v := i.iter.value()
-	return v.Context().BuildExpr(i.expr[i.i],
-		cue.Scope(v),
+	if i.keys == nil {
+		return i.eval(i.expr[i.i])
+	}
+	v := i.v.Context().CompileString("{}")
+	for j, x := range i.expr {
+		v = v.FillPath(cue.MakePath(cue.Str(i.keys[j])), i.eval(x))
+	}
+	return v
+}
+
+func (i *expressionIter) eval(x ast.Expr) cue.Value {
+	return i.v.Context().BuildExpr(x,
+		cue.Scope(i.v),
 		cue.InferBuiltins(true),
 		cue.ImportPath(i.iter.id()),
 	)
@@ -538,10 +622,22 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 
 	adt.DebugSort, _ = strconv.Atoi(os.Getenv("CUE_DEBUG_SORT_ARCS"))
 
-	builds := loadFromArgs(args, cfg.loadCfg)
-	if builds == nil {
-		return nil, errors.Newf(token.NoPos, "invalid args")
+	args, remote := splitRemotePackageArgs(args, cfg.loadCfg)
+	var builds []*build.Instance
+	if len(args) > 0 || len(remote) == 0 {
+		builds = loadFromArgs(args, cfg.loadCfg)
+		if builds == nil {
+			return nil, errors.Newf(token.NoPos, "invalid args")
+		}
 	}
+	for _, arg := range remote {
+		b, err := loadRemotePackage(arg, cfg.loadCfg)
+		if err != nil {
+			return nil, err
+		}
+		builds = append(builds, b)
+	}
+	cmd.useExperiments(builds...)
 
 	if err := p.parsePlacementFlags(); err != nil {
 		return nil, err
@@ -574,6 +670,22 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 			"use of -n/--name flag without a directory")
 	}
 
+	if p.schemaImport != "" {
+		if len(p.insts) > 0 && p.orphanInstance != nil {
+			return nil, errors.Newf(token.NoPos,
+				"cannot combine packages and data files with a schema from a registry")
+		}
+		v, err := p.loadRegistrySchema()
+		if err != nil {
+			return nil, err
+		}
+		if p.orphanInstance != nil {
+			p.encConfig.Schema = v
+		} else {
+			p.instance = &instance{val: v}
+		}
+	}
+
 	if b := p.orphanInstance; b != nil {
 		schemas, values, err := p.getDecoders(b)
 		if err != nil {
@@ -603,11 +715,17 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 		}
 
 		var schema *build.Instance
-		switch n := len(p.insts); n {
+		switch n := len(p.insts); {
+		case p.schemaImport != "":
+			// The schema was loaded from the registry above.
+			if len(schemas) > 0 || len(b.Files) > 0 {
+				return nil, errors.Newf(token.NoPos,
+					"cannot combine schema files with a schema from a registry")
+			}
 		default:
 			return nil, errors.Newf(token.NoPos,
 				"too many packages defined (%d) in combination with files", n)
-		case 1:
+		case n == 1:
 			if len(schemas) > 0 {
 				return nil, errors.Newf(token.NoPos,
 					"cannot combine packages with individual schema files")
@@ -615,7 +733,7 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 			schema = p.insts[0]
 			p.insts = nil
 
-		case 0:
+		case n == 0:
 			bb := *b
 			schema = &bb
 			b.BuildFiles = nil
@@ -635,6 +753,7 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 				return nil, err
 			}
 			p.instance = inst
+			p.schemaInst = schema
 			p.encConfig.Schema = inst.Value()
 			if p.schema != nil {
 				v := cmd.ctx.BuildExpr(p.schema,
@@ -647,7 +766,7 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 				}
 				p.encConfig.Schema = v
 			}
-		} else if p.schema != nil {
+		} else if p.schema != nil && p.schemaImport == "" {
 			return nil, errors.Newf(token.NoPos,
 				"-d/--schema flag specified without a schema")
 		}
@@ -671,7 +790,7 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 		}
 	}
 
-	if len(p.expressions) > 1 {
+	if len(p.expressions) > 1 && !p.keyed {
 		p.encConfig.Stream = true
 	}
 	return p, nil
@@ -704,8 +823,13 @@ func (b *buildPlan) parseFlags() (err error) {
 			return err
 		}
 		b.expressions = append(b.expressions, expr)
+		b.exprSources = append(b.exprSources, strings.TrimSpace(e))
 	}
+	b.keyed = flagKeyed.Bool(b.cmd)
 	if s := flagSchema.String(b.cmd); s != "" {
+		if importPath, expr, ok := splitSchemaImport(s); ok {
+			b.schemaImport, s = importPath, expr
+		}
 		b.schema, err = parser.ParseExpr("--schema", s)
 		if err != nil {
 			return err
@@ -726,6 +850,66 @@ func (b *buildPlan) parseFlags() (err error) {
 		Strict:        flagStrict.Bool(b.cmd),
 		InlineImports: flagInlineImports.Bool(b.cmd),
 		EscapeHTML:    flagEscape.Bool(b.cmd),
+
+		OpenAPISelectSchemas:    flagOpenAPISchemas.StringArray(b.cmd),
+		OpenAPISelectOperations: flagOpenAPIOperations.StringArray(b.cmd),
+		OpenAPIComponentsOnly:   flagOpenAPIComponentsOnly.Bool(b.cmd),
+
+		// The loader decrypts data files, except when they are imported,
+		// so that their plaintext is not written to disk. Imported files
+		// that are encrypted are rejected instead.
+		RejectEncrypted: b.importing,
+	}
+	if s := flagFieldOrder.String(b.cmd); s != "" {
+		if b.encConfig.FieldOrder, err = encoding.ParseFieldOrder(s); err != nil {
+			return err
+		}
+	}
+	keys := &encoding.KeyConfig{
+		Prefix:    flagKeyPrefix.String(b.cmd),
+		Separator: flagKeySeparator.String(b.cmd),
+	}
+	if s := flagKeyCase.String(b.cmd); s != "" {
+		if keys.Case, err = encoding.ParseKeyCase(s); err != nil {
+			return err
+		}
+	}
+	if s := flagQuote.String(b.cmd); s != "" {
+		if keys.Quote, err = encoding.ParseQuoting(s); err != nil {
+			return err
+		}
+	}
+	b.encConfig.Keys = keys
+	if paths := flagRedactPath.StringArray(b.cmd); flagRedact.Bool(b.cmd) || len(paths) > 0 {
+		b.encConfig.Redact = &redact.Config{Paths: paths}
+	}
+	b.encConfig.Provenance = flagProvenance.Bool(b.cmd)
+	if paths := flagAllowIncomplete.StringArray(b.cmd); flagCheckConcrete.Bool(b.cmd) || len(paths) > 0 {
+		b.encConfig.Incomplete = &incomplete.Config{Paths: paths}
+	}
+	b.encConfig.YAML = yamlConfig(b.cmd)
+	b.encConfig.OpenAPIDefinitionName, err = openAPIDefinitionName(flagOpenAPINames.String(b.cmd))
+	return err
+}
+
+// yamlConfig returns the configuration for decoding YAML selected by
+// the --yaml* flags, or nil if none are set.
+func yamlConfig(cmd *Command) *cueyaml.Config {
+	cfg := &cueyaml.Config{
+		YAML11Bools: flagYAMLBools.Bool(cmd),
+		Sexagesimal: flagYAMLSexagesimal.Bool(cmd),
+		Timestamps:  flagYAMLTimestamps.Bool(cmd),
+		Strict:      flagYAMLStrict.Bool(cmd),
+	}
+	if flagYAMLReport.Bool(cmd) {
+		// Coercions are not errors, so do not use cmd.Stderr.
+		stderr := cmd.ErrOrStderr()
+		cfg.Report = func(c cueyaml.Coercion) {
+			fmt.Fprintln(stderr, c)
+		}
+	}
+	if cfg.YAML11Bools || cfg.Sexagesimal || cfg.Timestamps || cfg.Strict || cfg.Report != nil {
+		return cfg
 	}
 	return nil
 }
@@ -734,6 +918,7 @@ func buildInstances(cmd *Command, binst []*build.Instance, ignoreErrors bool) []
 	// TODO:
 	// If there are no files and User is true, then use those?
 	// Always use all files in user mode?
+	cmd.useExperiments(binst...)
 	instances, err := cmd.ctx.BuildInstances(binst)
 	exitOnErr(cmd, err, true)
 
@@ -17,18 +17,25 @@ package cmd
 // This file contains code or initializing and running custom commands.
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/spf13/cobra"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/token"
 	itask "cuelang.org/go/internal/task"
 	"cuelang.org/go/internal/value"
@@ -36,7 +43,9 @@ import (
 	_ "cuelang.org/go/pkg/tool/exec"
 	_ "cuelang.org/go/pkg/tool/file"
 	_ "cuelang.org/go/pkg/tool/http"
+	_ "cuelang.org/go/pkg/tool/kafka"
 	_ "cuelang.org/go/pkg/tool/os"
+	_ "cuelang.org/go/pkg/tool/sops"
 	"cuelang.org/go/tools/flow"
 )
 
@@ -151,34 +160,164 @@ func doTasks(cmd *Command, typ, command string, root *cue.Instance) error {
 		IgnoreConcrete: true,
 	}
 
+	policy, err := newTaskPolicy(cmd, cfg.Root)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		cfg.Policy = policy.check
+	}
+
+	if flagDryRun.Bool(cmd) {
+		return dryRunTasks(cmd, cfg, root)
+	}
+
+	report := flagReport.String(cmd)
+	cfg.Report = report != ""
+
 	c := flow.New(cfg, root, newTaskFunc(cmd))
 
-	err := c.Run(context.Background())
+	err = c.Run(context.Background())
+	if report != "" {
+		exitOnErr(cmd, writeReport(report, c.Report()), err == nil)
+	}
 	exitOnErr(cmd, err, true)
 
 	return err
 }
 
-// func (r *customRunner) tagReference(t *task, ref cue.Value) error {
-// 	inst, path := ref.Reference()
-// 	if len(path) == 0 {
-// 		return errors.Newf(ref.Pos(),
-// 			"$after must be a reference or list of references, found %s", ref)
-// 	}
-// 	if inst != r.root {
-// 		return errors.Newf(ref.Pos(),
-// 			"reference in $after must refer to value in same package")
-// 	}
-// 	// TODO: allow referring to group of tasks.
-// 	if !r.tagDependencies(t, path) {
-// 		return errors.Newf(ref.Pos(),
-// 			"reference %s does not refer to task or task group",
-// 			strings.Join(path, "."), // TODO: more correct representation.
-// 		)
-
-// 	}
-// 	return nil
-// }
+// writeReport writes r to the given file, as JUnit XML if the file name ends
+// in .xml and as JSON otherwise.
+func writeReport(filename string, r *flow.Report) error {
+	var buf bytes.Buffer
+	var err error
+	if filepath.Ext(filename) == ".xml" {
+		err = r.WriteJUnit(&buf)
+	} else {
+		err = r.WriteJSON(&buf)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0o644)
+}
+
+// dryRunTasks prints the tasks of a command in the order in which they would
+// run, along with their inputs, without running them. Inputs that depend on
+// the outputs of other tasks are printed as far as they can be resolved.
+func dryRunTasks(cmd *Command, cfg *flow.Config, root *cue.Instance) error {
+	taskFunc := newTaskFunc(cmd)
+	c := flow.New(cfg, root, func(v cue.Value) (flow.Runner, error) {
+		r, err := taskFunc(v)
+		if r == nil || err != nil {
+			return r, err
+		}
+		return flow.RunnerFunc(func(t *flow.Task) error { return nil }), nil
+	})
+
+	// Running the workflow with tasks that do nothing reports any errors
+	// in its definition and resolves the tasks that depend on other tasks.
+	err := c.Run(context.Background())
+	exitOnErr(cmd, err, true)
+
+	w := cmd.OutOrStdout()
+	for i, t := range sortTasks(c.Tasks()) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		kind, _ := t.Value().LookupPath(cue.MakePath(cue.Str("$id"))).String()
+		fmt.Fprintf(w, "%s: %s\n", taskName(cfg.Root, t), kind)
+		if deps := t.Dependencies(); len(deps) > 0 {
+			names := make([]string, len(deps))
+			for i, d := range deps {
+				names[i] = taskName(cfg.Root, d)
+			}
+			sort.Strings(names)
+			fmt.Fprintf(w, "\tafter: %s\n", strings.Join(names, ", "))
+		}
+		b, err := format.Node(taskInputs(t.Value()))
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+			if line != "" {
+				line = "\t" + line
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
+
+// sortTasks returns tasks ordered such that each task follows the tasks it
+// depends on, and such that tasks otherwise retain their order.
+func sortTasks(tasks []*flow.Task) []*flow.Task {
+	sorted := make([]*flow.Task, 0, len(tasks))
+	done := map[*flow.Task]bool{}
+	var visit func(t *flow.Task)
+	visit = func(t *flow.Task) {
+		if done[t] {
+			return
+		}
+		done[t] = true
+		for _, d := range t.Dependencies() {
+			visit(d)
+		}
+		sorted = append(sorted, t)
+	}
+	for _, t := range tasks {
+		visit(t)
+	}
+	return sorted
+}
+
+// taskName returns the path of t relative to root, or its full path if t is
+// not defined within root.
+func taskName(root cue.Path, t *flow.Task) string {
+	rs, ts := root.Selectors(), t.Path().Selectors()
+	if len(ts) <= len(rs) {
+		return t.Path().String()
+	}
+	for i, s := range rs {
+		if s.String() != ts[i].String() {
+			return t.Path().String()
+		}
+	}
+	return cue.MakePath(ts[len(rs):]...).String()
+}
+
+// taskInputs returns the fields of the task v, other than the ones
+// that identify the task or order it, along with any imports they need.
+func taskInputs(v cue.Value) *ast.File {
+	var decls []ast.Decl
+	switch x := v.Syntax(cue.Final(), cue.Docs(false), cue.Attributes(false), cue.Optional(false)).(type) {
+	case *ast.File:
+		for _, d := range x.Decls {
+			if e, ok := d.(*ast.EmbedDecl); ok {
+				if s, ok := e.Expr.(*ast.StructLit); ok {
+					decls = append(decls, s.Elts...)
+					continue
+				}
+			}
+			decls = append(decls, d)
+		}
+	case *ast.StructLit:
+		decls = x.Elts
+	case ast.Expr:
+		decls = []ast.Decl{&ast.EmbedDecl{Expr: x}}
+	}
+	f := &ast.File{}
+	for _, d := range decls {
+		if x, ok := d.(*ast.Field); ok {
+			switch name, _, _ := ast.LabelName(x.Label); name {
+			case "$id", "$after", "kind":
+				continue
+			}
+		}
+		f.Decls = append(f.Decls, d)
+	}
+	return f
+}
 
 func isTask(v cue.Value) bool {
 	// This mimics the v0.2 behavior. The cutoff is really quite arbitrary. A
@@ -205,24 +344,33 @@ var legacyKinds = map[string]string{
 	"testserver": "cmd/cue/cmd.Test",
 }
 
+// taskKind reports the kind of the task v, such as "tool/exec.Run".
+func taskKind(v cue.Value) (string, error) {
+	kind, err := v.Lookup("$id").String()
+	if err != nil {
+		// Lookup kind for backwards compatibility.
+		// This should not be supported for cue run.
+		var err1 error
+		kind, err1 = v.Lookup("kind").String()
+		if err1 != nil || legacyKinds[kind] == "" {
+			return "", errors.Promote(err1, "newTask")
+		}
+	}
+	if k, ok := legacyKinds[kind]; ok {
+		kind = k
+	}
+	return kind, nil
+}
+
 func newTaskFunc(cmd *Command) flow.TaskFunc {
 	return func(v cue.Value) (flow.Runner, error) {
 		if !isTask(v) {
 			return nil, nil
 		}
 
-		kind, err := v.Lookup("$id").String()
+		kind, err := taskKind(v)
 		if err != nil {
-			// Lookup kind for backwards compatibility.
-			// This should not be supported for cue run.
-			var err1 error
-			kind, err1 = v.Lookup("kind").String()
-			if err1 != nil || legacyKinds[kind] == "" {
-				return nil, errors.Promote(err1, "newTask")
-			}
-		}
-		if k, ok := legacyKinds[kind]; ok {
-			kind = k
+			return nil, err
 		}
 		rf := itask.Lookup(kind)
 		if rf == nil {
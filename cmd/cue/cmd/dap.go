@@ -0,0 +1,61 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/internal/dap"
+)
+
+func newDAPCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dap",
+		Short: "step through evaluation using the Debug Adapter Protocol",
+		Long: `Dap runs a debug adapter that speaks the Debug Adapter Protocol over
+standard input and output. Editors can use it to step through the
+evaluation of a CUE package.
+
+A launch request accepts the following arguments:
+
+	program      the package or files to evaluate, as for cue eval
+	             (default ".")
+	cwd          the directory to evaluate in
+	tags         a list of tags, as for the -t flag
+	stopOnEntry  pause before evaluation starts
+
+Evaluation is shown as a single thread whose stack frames are the values
+being evaluated, identified by their paths. Function breakpoints are CUE
+paths that may contain wildcards, such as a.*.b, and pause evaluation
+when a matching value is evaluated. Source breakpoints pause evaluation
+of values defined on the breakpoint's line. The "errors" exception
+filter pauses when a value becomes an error.
+
+While paused, the variables of a frame show the status, the value
+computed so far, the conjuncts, and the fields of the value.
+`,
+		Args: cobra.NoArgs,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			return dap.Serve(cmd.InOrStdin(), cmd.OutOrStdout(), &dap.Config{Dir: cwd})
+		}),
+	}
+	return cmd
+}
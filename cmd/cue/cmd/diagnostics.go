@@ -0,0 +1,220 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// Supported values of the --error-format flag.
+const (
+	errorFormatText   = "text"
+	errorFormatGitHub = "github"
+	errorFormatGitLab = "gitlab"
+)
+
+// errorFormat returns the value of the --error-format flag.
+func (c *Command) errorFormat() string {
+	f := c.root.PersistentFlags().Lookup(string(flagErrorFormat))
+	if f == nil {
+		return errorFormatText
+	}
+	return f.Value.String()
+}
+
+// errorReport returns the value of the --error-report flag.
+func (c *Command) errorReport() string {
+	f := c.root.PersistentFlags().Lookup(string(flagErrorReport))
+	if f == nil {
+		return ""
+	}
+	return f.Value.String()
+}
+
+// checkErrorFormat reports an error if the --error-format flag has an
+// unknown value, or if it is not combined with --error-report when, and
+// only when, it writes a report.
+func checkErrorFormat(c *Command) error {
+	f := c.errorFormat()
+	switch f {
+	case errorFormatText, errorFormatGitHub, errorFormatGitLab:
+	default:
+		return fmt.Errorf("unknown error format %q; must be one of text, github, or gitlab", f)
+	}
+	switch report := c.errorReport(); {
+	case f == errorFormatGitLab && report == "":
+		return fmt.Errorf("--error-format=gitlab requires --error-report to name the file to write the report to")
+	case f != errorFormatGitLab && report != "":
+		return fmt.Errorf("--error-report requires --error-format=gitlab")
+	}
+	return nil
+}
+
+// A diagnostic is a single error message at a single location.
+type diagnostic struct {
+	msg  string
+	file string // relative to the current directory, using forward slashes
+	line int
+	col  int
+}
+
+// diagnostics converts err to diagnostics, one for each position of each
+// of its errors. Errors without a position result in a diagnostic without
+// a file.
+func diagnostics(err error) []diagnostic {
+	cwd, _ := os.Getwd()
+	var a []diagnostic
+	for _, e := range errors.Errors(err) {
+		msg := errors.String(e)
+		positions := errors.Positions(e)
+		if len(positions) == 0 {
+			a = append(a, diagnostic{msg: msg})
+			continue
+		}
+		for _, p := range positions {
+			a = append(a, newDiagnostic(msg, p, cwd))
+		}
+	}
+	return a
+}
+
+func newDiagnostic(msg string, p token.Pos, cwd string) diagnostic {
+	pos := p.Position()
+	file := pos.Filename
+	if cwd != "" && filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(cwd, file); err == nil {
+			file = rel
+		}
+	}
+	return diagnostic{
+		msg:  msg,
+		file: filepath.ToSlash(file),
+		line: pos.Line,
+		col:  pos.Column,
+	}
+}
+
+// printGitHubDiagnostics writes err as GitHub Actions workflow commands,
+// which cause GitHub to annotate the reported lines.
+//
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message.
+func printGitHubDiagnostics(w io.Writer, err error) {
+	for _, d := range diagnostics(err) {
+		var props []string
+		if d.file != "" {
+			props = append(props, "file="+escapeGitHubProperty(d.file))
+			if d.line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", d.line))
+			}
+			if d.col > 0 {
+				props = append(props, fmt.Sprintf("col=%d", d.col))
+			}
+		}
+		cmd := "::error"
+		if len(props) > 0 {
+			cmd += " " + strings.Join(props, ",")
+		}
+		fmt.Fprintf(w, "%s::%s\n", cmd, escapeGitHubData(d.msg))
+	}
+}
+
+var (
+	githubDataEscaper = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	)
+	githubPropertyEscaper = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	)
+)
+
+func escapeGitHubData(s string) string     { return githubDataEscaper.Replace(s) }
+func escapeGitHubProperty(s string) string { return githubPropertyEscaper.Replace(s) }
+
+// A gitlabIssue is an entry of a GitLab code quality report.
+//
+// See https://docs.gitlab.com/ee/ci/testing/code_quality.html#implement-a-custom-tool.
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// addGitLabDiagnostics records err to be reported in the code quality
+// report written when the command completes.
+func (c *Command) addGitLabDiagnostics(err error) {
+	check := "cue"
+	if c.Command != nil && c.Command != c.root {
+		check += " " + c.Command.Name()
+	}
+	for _, d := range diagnostics(err) {
+		line := d.line
+		if line == 0 {
+			line = 1
+		}
+		h := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%s", check, d.file, line, d.msg)))
+		c.gitlabIssues = append(c.gitlabIssues, gitlabIssue{
+			Description: d.msg,
+			CheckName:   check,
+			Fingerprint: hex.EncodeToString(h[:]),
+			Severity:    "major",
+			Location: gitlabLocation{
+				Path:  d.file,
+				Lines: gitlabLines{Begin: line},
+			},
+		})
+	}
+}
+
+// writeGitLabReport writes the recorded diagnostics as a GitLab code
+// quality report to the named file. The report is written even if there
+// are no diagnostics, so that GitLab knows that earlier issues are fixed.
+func (c *Command) writeGitLabReport(file string) error {
+	issues := c.gitlabIssues
+	if issues == nil {
+		issues = []gitlabIssue{}
+	}
+	b, err := json.MarshalIndent(issues, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(b, '\n'), 0o666)
+}
@@ -0,0 +1,199 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+func newDocCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doc [package [name]]",
+		Short: "show documentation for a package or field",
+		Long: `Doc prints the documentation of a CUE package.
+
+Without a name, doc prints the package documentation followed by each
+regular field and definition at the top level of the package, along with
+its documentation. Structs and lists are abbreviated as {...} and [...].
+With a name, which is a CUE path such as #Config or #Config.name, doc
+prints the full declaration of that field, including the documentation of
+the fields within it.
+
+The package is given as for other commands, and defaults to the package in
+the current directory. With the modules experiment enabled, the package may
+also refer to a package in a module registry, such as foo.com/bar@v1 or
+foo.com/bar@v1.2.3:bar. The module is fetched from the registry, or the
+module cache, without adding it as a dependency of the current module.
+A major version selects the latest version of the module.
+
+Examples:
+
+  $ cue doc
+  $ cue doc . '#Config'
+  $ cue doc foo.com/bar@v1 '#Config.name'
+`,
+		RunE: mkRunE(c, runDoc),
+		Args: cobra.MaximumNArgs(2),
+	}
+	return cmd
+}
+
+func runDoc(cmd *Command, args []string) error {
+	var name string
+	if len(args) == 2 {
+		args, name = args[:1], args[1]
+	}
+	b, err := parseArgs(cmd, args, &config{})
+	exitOnErr(cmd, err, true)
+	if len(b.insts) != 1 {
+		return fmt.Errorf("doc requires a single package")
+	}
+	inst := b.insts[0]
+	v := cmd.ctx.BuildInstance(inst)
+	exitOnErr(cmd, v.Err(), true)
+
+	var f *ast.File
+	if name == "" {
+		f = packageDoc(inst, v)
+	} else {
+		p := cue.ParsePath(name)
+		if err := p.Err(); err != nil {
+			return fmt.Errorf("invalid name %q: %v", name, err)
+		}
+		field := lookupDocField(docFields(v), p.Selectors())
+		if field == nil {
+			return fmt.Errorf("no field %s in package %s", name, inst.ImportPath)
+		}
+		f = &ast.File{Decls: []ast.Decl{field}}
+	}
+	data, err := format.Node(f)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+// packageDoc returns a file holding the documentation of the package
+// clause of inst, followed by the declaration of each field at the top
+// level of v, with structs and lists abbreviated.
+func packageDoc(inst *build.Instance, v cue.Value) *ast.File {
+	pkg := &ast.Package{Name: ast.NewIdent(inst.PkgName)}
+	for _, file := range inst.Files {
+		if cg := internal.FileComment(file); cg != nil {
+			ast.AddComment(pkg, &ast.CommentGroup{Doc: true, List: cg.List})
+		}
+	}
+	if inst.ImportPath != "" {
+		ast.AddComment(pkg, &ast.CommentGroup{
+			Line:     true,
+			Position: 10,
+			List:     []*ast.Comment{{Text: fmt.Sprintf("// import %q", inst.ImportPath)}},
+		})
+	}
+	f := &ast.File{Decls: []ast.Decl{pkg}}
+	for _, field := range docFields(v) {
+		switch field.Value.(type) {
+		case *ast.StructLit:
+			field.Value = &ast.StructLit{
+				Elts:   []ast.Decl{&ast.Ellipsis{Ellipsis: token.NoSpace.Pos()}},
+				Rbrace: token.NoSpace.Pos(),
+			}
+		case *ast.ListLit:
+			field.Value = ast.NewList(&ast.Ellipsis{})
+		}
+		ast.SetRelPos(field, token.NewSection)
+		f.Decls = append(f.Decls, field)
+	}
+	return f
+}
+
+// lookupDocField returns the declaration of the field selected by sels
+// within fields, or nil if there is no such field. Only fields declared
+// within struct literals can be selected.
+func lookupDocField(fields []*ast.Field, sels []cue.Selector) *ast.Field {
+	for _, field := range fields {
+		if !matchLabel(field.Label, sels[0]) {
+			continue
+		}
+		if len(sels) == 1 {
+			return field
+		}
+		if s, ok := field.Value.(*ast.StructLit); ok {
+			return lookupDocField(structFields(s.Elts), sels[1:])
+		}
+		return nil
+	}
+	return nil
+}
+
+// matchLabel reports whether label is selected by sel.
+func matchLabel(label ast.Label, sel cue.Selector) bool {
+	name, _, err := ast.LabelName(label)
+	if err != nil {
+		return false
+	}
+	switch sel.LabelType() {
+	case cue.DefinitionLabel:
+		return name == sel.String()
+	case cue.StringLabel:
+		return name == sel.Unquoted()
+	}
+	return false
+}
+
+// docFields returns the declarations of the regular fields and definitions
+// of v, as exported with their documentation.
+func docFields(v cue.Value) []*ast.Field {
+	n := v.Syntax(
+		cue.Definitions(true),
+		cue.Optional(true),
+		cue.Hidden(false),
+		cue.Docs(true),
+	)
+	var decls []ast.Decl
+	switch x := n.(type) {
+	case *ast.File:
+		decls = x.Decls
+	case *ast.StructLit:
+		decls = x.Elts
+	}
+	return structFields(decls)
+}
+
+// structFields returns the fields in decls, omitting hidden fields.
+func structFields(decls []ast.Decl) []*ast.Field {
+	var fields []*ast.Field
+	for _, d := range decls {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, isIdent, _ := ast.LabelName(f.Label); isIdent && internal.IsHidden(name) {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
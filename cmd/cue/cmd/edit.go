@@ -0,0 +1,213 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/tools/writeback"
+)
+
+func newEditCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit <cmd> [arguments]",
+		Short: "edit values in CUE files in place",
+		Long: `Edit provides commands that change the values of a CUE package by
+editing its source files in place, preserving their comments and formatting.
+`,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			stderr := cmd.Stderr()
+			if len(args) == 0 {
+				fmt.Fprintln(stderr, "edit must be run as one of its subcommands")
+			} else {
+				fmt.Fprintf(stderr, "edit must be run as one of its subcommands: unknown subcommand %q\n", args[0])
+			}
+			fmt.Fprintln(stderr, "Run 'cue help edit' for known subcommands.")
+			os.Exit(1) // TODO: get rid of this
+			return nil
+		}),
+	}
+
+	cmd.AddCommand(newEditSetCmd(c))
+	return cmd
+}
+
+func newEditSetCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <path> <value> [<package>]",
+		Short: "set concrete values in CUE files",
+		Long: `Set sets the value at a path of a package, the package in the current
+directory by default, by rewriting the source of the value in place.
+
+The path is a CUE path, like web.containers[0].image. The value is read
+as a CUE expression if it is a concrete value, such as 3, true or
+[80, 443], and as a string otherwise; quote it, as in '"1.0"', to set a
+string that looks like another value.
+
+Only the text of the literal values declared for the path changes; the
+rest of the files, including comments and formatting, is left untouched.
+If the path has no literal value in the package, a field is added to the
+innermost struct literal enclosing it. The package is validated, including
+against the schemas the values are unified with, before any file is
+written.
+
+With --batch, the paths and values are read from a file, or standard
+input if the file is -, with one path=value pair per line. Blank lines
+and lines starting with // are ignored. All edits are validated before
+any file is written.
+
+Examples:
+
+	$ cue edit set web.image nginx:1.27
+	$ cue edit set web.replicas 3 ./prod
+	$ cue edit set --batch bumps.txt ./prod
+`,
+		RunE: mkRunE(c, runEditSet),
+	}
+	cmd.Flags().String(string(flagBatch), "",
+		"read path=value pairs from this file, or - for standard input")
+	cmd.Flags().Bool(string(flagDryrun), false,
+		"only print the names of the files that would be modified")
+	return cmd
+}
+
+// An editPair is a path to set and its value.
+type editPair struct {
+	path  string
+	value string
+}
+
+func runEditSet(cmd *Command, args []string) error {
+	var pairs []editPair
+	if batch := flagBatch.String(cmd); batch != "" {
+		if len(args) > 1 {
+			return fmt.Errorf("too many arguments; with --batch, only a package may be given")
+		}
+		var err error
+		pairs, err = readEditPairs(cmd, batch)
+		if err != nil {
+			return err
+		}
+	} else {
+		if len(args) < 2 || len(args) > 3 {
+			return fmt.Errorf("want a path, a value and, optionally, a package")
+		}
+		pairs = []editPair{{path: args[0], value: args[1]}}
+		args = args[2:]
+	}
+	pkg := "."
+	if len(args) > 0 {
+		pkg = args[0]
+	}
+
+	insts := load.Instances([]string{pkg}, nil)
+	if len(insts) != 1 {
+		return fmt.Errorf("%s must match exactly one package, found %d", pkg, len(insts))
+	}
+	inst := insts[0]
+	if inst.Err != nil {
+		return inst.Err
+	}
+	cmd.useExperiments(inst)
+	e, err := writeback.New(cmd.ctx, inst)
+	if err != nil {
+		return err
+	}
+	for _, p := range pairs {
+		path := cue.ParsePath(p.path)
+		if err := path.Err(); err != nil {
+			return fmt.Errorf("invalid path %q: %v", p.path, err)
+		}
+		if err := e.FillPath(path, editValue(cmd.ctx, p.value)); err != nil {
+			return err
+		}
+	}
+
+	files := e.Files()
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	cwd, _ := os.Getwd()
+	for _, name := range names {
+		if flagDryrun.Bool(cmd) {
+			rel, err := filepath.Rel(cwd, name)
+			if err != nil {
+				rel = name
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), filepath.ToSlash(rel))
+			continue
+		}
+		if err := os.WriteFile(name, files[name], 0o666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// editValue returns the expression of value s: s itself if it is a
+// concrete CUE value and a string holding s otherwise.
+func editValue(ctx *cue.Context, s string) ast.Expr {
+	x, err := parser.ParseExpr("value", s)
+	if err == nil && ctx.BuildExpr(x).Validate(cue.Concrete(true)) == nil {
+		return x
+	}
+	return ast.NewString(s)
+}
+
+// readEditPairs reads the path=value pairs of the batch file name.
+func readEditPairs(cmd *Command, name string) ([]editPair, error) {
+	var r io.Reader
+	if name == "-" {
+		r = cmd.InOrStdin()
+	} else {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var pairs []editPair
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "//") {
+			continue
+		}
+		path, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: want path=value", name, line)
+		}
+		pairs = append(pairs, editPair{
+			path:  strings.TrimSpace(path),
+			value: strings.TrimSpace(value),
+		})
+	}
+	return pairs, scanner.Err()
+}
@@ -20,11 +20,13 @@ import (
 	"github.com/spf13/cobra"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/tools/redact"
 )
 
 // newEvalCmd creates a new eval command
@@ -48,6 +50,26 @@ Examples:
   $ cue eval foo.cue -e a[0] -e a[2]
   "a"
   "c"
+
+All expressions are evaluated within a single evaluation of the
+configuration. With --keyed, their results are combined into a
+single struct, keyed by the text of each expression:
+
+  $ cue eval foo.cue -e a[0] -e a[2] --keyed
+  "a[0]": "a"
+  "a[2]": "c"
+
+With --out tree, the value is printed as an indented tree with a line
+for each field and list element, showing its kind and, if concrete,
+its value. Structs that are not closed and open lists are marked with
+an ellipsis, and values that are not concrete with "(incomplete)". The
+--depth flag limits the number of levels that are shown.
+
+  $ cue eval foo.cue --out tree
+  a: list [3]
+  ├── 0: string = "a"
+  ├── 1: string = "b"
+  └── 2: string = "c"
 `,
 		RunE: mkRunE(c, runEval),
 	}
@@ -57,6 +79,8 @@ Examples:
 	addInjectionFlags(cmd.Flags(), false, false)
 
 	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "evaluate this expression only")
+	cmd.Flags().Bool(string(flagKeyed), false,
+		"combine the results of all expressions into a struct keyed by expression")
 
 	cmd.Flags().BoolP(string(flagConcrete), "c", false,
 		"require the evaluation to be concrete")
@@ -73,6 +97,11 @@ Examples:
 	cmd.Flags().BoolP(string(flagAll), "a", false,
 		"show optional and hidden fields")
 
+	cmd.Flags().Int(string(flagDepth), 0,
+		"maximum depth of --out tree output (0 for unlimited)")
+
+	addRedactFlags(cmd.Flags())
+
 	// TODO: Option to include comments in output.
 	return cmd
 }
@@ -106,6 +135,7 @@ func runEval(cmd *Command, args []string) error {
 		opts = append(opts, format.Simplify())
 	}
 	b.encConfig.Format = opts
+	b.encConfig.TreeDepth = flagDepth.Int(cmd)
 
 	e, err := encoding.NewEncoder(b.outFile, b.encConfig)
 	exitOnErr(cmd, err, true)
@@ -140,7 +170,7 @@ func runEval(cmd *Command, args []string) error {
 			syn = append(syn, cue.Hidden(true))
 		}
 
-		if len(b.expressions) > 1 {
+		if len(b.expressions) > 1 && !b.keyed {
 			b, _ := format.Node(b.expressions[i%len(b.expressions)])
 			id = string(b)
 		}
@@ -162,7 +192,12 @@ func runEval(cmd *Command, args []string) error {
 			}
 		}
 
-		f := internal.ToFile(v.Syntax(syn...))
+		var n ast.Node = v.Syntax(syn...)
+		if b.encConfig.Redact != nil {
+			n, err = redact.Syntax(v, b.encConfig.Redact, syn...)
+			exitOnErr(cmd, err, true)
+		}
+		f := internal.ToFile(n)
 		f.Filename = id
 		err := e.EncodeFile(f)
 		if err != nil {
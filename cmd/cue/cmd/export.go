@@ -15,10 +15,23 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/cue/build"
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/tools/cachedir"
+	"cuelang.org/go/tools/metadata"
 )
 
 // newExportCmd creates and export command
@@ -76,6 +89,244 @@ If the package is not explicitly defined by the '-p' flag, it must be uniquely
 defined by the files in the current directory.
 
 
+Selecting values
+
+The --expression (-e) flag exports the result of an expression
+evaluated within the configuration instead of the configuration
+itself. If it is given multiple times, the results are written as a
+stream of values in formats that support this, such as YAML. All
+expressions are evaluated within a single evaluation of the
+configuration, so extracting several values from a large
+configuration costs little more than extracting one. With --keyed,
+the results are instead combined into a single struct, keyed by the
+text of each expression:
+
+	cue export -e db.host -e 'ports[0]' --keyed
+
+yields
+
+	{
+	    "db.host": "localhost",
+	    "ports[0]": 8080
+	}
+
+
+Exporting many packages
+
+When several packages are exported, such as with ./..., they are
+evaluated concurrently, and their results written in the order in
+which the packages were given. The --jobs flag bounds the number of
+packages evaluated at once; it defaults to the number of CPUs. Each
+concurrent evaluation shares the evaluation of imported packages among
+the packages it exports, so that packages importing a common package,
+such as the schemas of many similar services, do not repeat its
+evaluation. Use --jobs 1 to evaluate packages one at a time in a single
+evaluation.
+
+	cue export ./services/... --jobs 8 --out yaml
+
+
+Ordering fields
+
+By default, fields are written in the order in which they are first
+declared. The --field-order flag selects another order, which is
+applied to all output formats:
+
+	source  the order of declaration (default)
+	 alpha  sorted by label
+	schema  the order in which the definitions a struct was unified
+	        with declare their fields, followed by other fields in
+	        the order of declaration
+
+Stable orders help to avoid spurious differences when the output is
+checked into version control.
+
+
+Redacting sensitive values
+
+With --redact, the values of fields with a @sensitive attribute are
+replaced with the string "<redacted>" in the output. The attribute
+applies to all values a field is unified with, so it is typically
+placed in a schema:
+
+	#Database: {
+		user:     string
+		password: string @sensitive()
+	}
+
+Values can also be selected with --redact-path, which takes a pattern
+of dot-separated labels, each of which may contain shell-style
+wildcards such as '*'. List elements are matched by index. For instance, "db.*.password" matches the
+password field of any field of db. Patterns are relative to the
+exported value, such as the result of -e. Redacted values are still
+validated before they are replaced.
+
+
+Tracing values to their sources
+
+With --provenance, each field and list element in CUE or YAML output
+is followed by a comment listing the files and lines that declare it,
+including the constraints it was unified with. Files within the
+current directory are listed relative to it. For instance, given
+
+	-- schema.cue --
+	#Service: port: int & >0
+
+	-- app.cue --
+	service: #Service & {
+		port: 8080
+	}
+
+"cue export --out yaml --provenance" yields
+
+	service: # app.cue:1
+	  port: 8080 # app.cue:2, schema.cue:1
+
+
+Exporting metadata
+
+With --metadata, the doc comments and attributes of the exported
+fields, which JSON and YAML output cannot hold, are written to the
+given file as a JSON object keyed by the path of each field that has
+any, so that tools such as user interfaces and documentation sites can
+present them alongside the data. The comments and attributes of a
+field include those of the schemas it is unified with. For instance,
+given
+
+	#Service: {
+		// Number of instances.
+		replicas: int @ui(slider)
+	}
+	web: #Service & {replicas: 2}
+
+"cue export --metadata meta.json" writes
+
+	{
+	    "web.replicas": {
+	        "doc": "Number of instances.",
+	        "attributes": [
+	            {
+	                "name": "ui",
+	                "contents": "slider"
+	            }
+	        ]
+	    }
+	}
+
+When several values are exported, the file holds one such object per
+value. An existing file is only overwritten with --force.
+
+
+Allowing incomplete values
+
+Exported values must be concrete. With --check-concrete, values of
+fields with an @incomplete attribute may instead remain incomplete,
+in which case they are omitted from the output. This is useful for
+optional sections that are not always filled in:
+
+	#Service: {
+		name: string
+		tls?: {
+			cert: string
+		} @incomplete()
+	}
+
+Values can also be selected with --allow-incomplete, which takes a
+pattern like --redact-path and implies --check-concrete. Patterns are
+relative to the exported value, such as the result of -e. Values that
+may be incomplete are still checked for other errors, such as
+conflicts. All other values that are not concrete are reported, each
+with its path and position. Elements of lists that are omitted are
+written as null, so that the indices of other elements are retained.
+
+	cue export --allow-incomplete 'services.*.tls'
+
+
+Caching output
+
+With --cache, the output written to stdout is recorded, keyed by a
+hash of the contents of the exported packages and those they import,
+the module file, the working directory, the version of cue, and the
+flags. Repeated invocations with the same inputs, such as those made
+by scripts, print the recorded output without loading or evaluating
+the packages again. The cache is kept in the export directory of
+$CUE_CACHE_DIR, which defaults to the cue directory of the user's
+cache directory, and is shared by all cue processes. The cache is not
+used when reading data files or stdin, with -T, or with -o.
+
+  cue export --cache ./config
+
+
+Writing patches
+
+With --out patch, the output is not the exported value itself, but a
+patch that changes a previous version of it, read from the file given
+with --patch-base, into the exported value. The previous version may
+be the output of an earlier export or the current state of a running
+system, such as a Kubernetes object, and may be read from stdin with
+"--patch-base -". Unchanged fields are omitted from the patch and
+removed fields are set to null, so that applying the patch makes only
+the changes needed. An identical value yields {}. The patch is
+written as JSON unless another encoding is given, as in
+"--out patch+yaml".
+
+The type of patch is selected with --patch-type:
+
+	    merge  a JSON Merge Patch as defined by RFC 7386, in which
+	           lists are replaced as a whole (default)
+	strategic  a Kubernetes strategic merge patch, in which lists of
+	           objects identified by the field given with --patch-key
+	           (default "name") are merged element by element, with
+	           removed elements marked by "$patch": "delete" and the
+	           order of the elements given by a "$setElementOrder"
+	           directive
+
+As null deletes a field in a patch, fields may not be set to null.
+For example, given old.json holding
+
+	{"replicas": 2, "image": "app:v1", "debug": true}
+
+and a value with replicas 3, image "app:v1" and no debug field,
+"cue export --out patch --patch-base old.json" yields
+
+	{
+	    "replicas": 3,
+	    "debug": null
+	}
+
+  cue export --out patch --patch-base deployment.json | \
+	kubectl patch deployment app --type merge --patch-file /dev/stdin
+
+
+Attesting outputs
+
+With --attest, an attestation recording everything the export read is
+written to the given file, so that the output can be audited and
+verified to be reproducible. The attestation is an in-toto statement
+(https://in-toto.io/Statement/v1) with a SLSA provenance predicate
+(https://slsa.dev/provenance/v1). Its subject is the output, named by
+its file or "-" for stdout, with its SHA-256 digest. The predicate
+records:
+
+	- the arguments and the flags given to cue export, including the
+	  values injected with -t and whether --inject-vars was used;
+	- the working directory and the CUE_EXPERIMENT and CUE_REGISTRY
+	  environment variables;
+	- the SHA-256 digest of each file read, including the module.cue
+	  files and the files of all imported packages;
+	- the versions of the modules from which packages were imported,
+	  with their files named as in foo.com/bar@v1.2.3/baz/baz.cue.
+
+Files in the current directory are named relative to it. An output can
+be verified by repeating the export in a directory holding the files
+listed in the attestation with the same digests, and comparing the
+digest of the result. Values injected with --inject-vars, such as the
+current time, are not recorded and make the export not reproducible.
+Reading from stdin is not supported.
+
+  cue export ./config -o config.yaml --attest config.intoto.json
+
+
 Formats
 
 The following formats are recognized:
@@ -94,6 +345,74 @@ The following formats are recognized:
 
  binary  output as raw binary
               The evaluated value must be of type string or bytes.
+
+k8smanifest  output as Kubernetes manifest
+              Outputs the Kubernetes objects found in the value as YAML
+              documents separated by "---", ordered by kind, namespace
+              and name. Objects may be nested in structs and lists, or
+              given as a List object. With --kustomization, a
+              kustomization.yaml file listing the output file is
+              written alongside it.
+
+	cue export --out k8smanifest -o manifests/app.yaml --kustomization
+
+  patch  output as a patch
+              Outputs the changes from the previous version of the
+              value given with --patch-base as a JSON Merge Patch or
+              a strategic merge patch. See "Writing patches" above.
+
+    env  output as environment variables
+              The evaluated value must be a struct. Each scalar is
+              written as a KEY=VALUE line, with the labels of its path
+              converted to upper case and joined with "_".
+
+  flags  output as command line flags
+              The evaluated value must be a struct. Each scalar is
+              written as a --key=value line, with the labels of its
+              path joined with ".". A list of scalars is written as a
+              repeated flag.
+
+
+Environment variables and flags
+
+For the env and flags formats, nested structs and lists are
+flattened: list elements are labeled by their index, and null is
+written as the empty string. Keys are derived from the path of each
+value as follows:
+
+	--key-prefix     a first label, joined to every key
+	--key-separator  joins labels (default "_" for env, "." for flags)
+	--key-case       preserve, upper, or lower (default upper for env,
+	                 preserve for flags)
+
+For env, characters that are not letters, digits, or underscores are
+replaced with underscores. Values are quoted according to --quote:
+
+	 shell  single-quoted if they contain characters special to a
+	        POSIX shell (default)
+	double  always double-quoted, with backslashes, double quotes,
+	        dollar signs, backquotes and newlines escaped, as read
+	        by most dotenv loaders
+	  none  written as is; values may not contain newlines
+
+For example, given
+
+	db: {host: "localhost", port: 5432}
+	tags: ["a", "b c"]
+
+"cue export --out env" yields
+
+	DB_HOST=localhost
+	DB_PORT=5432
+	TAGS_0=a
+	TAGS_1='b c'
+
+and "cue export --out flags" yields
+
+	--db.host=localhost
+	--db.port=5432
+	--tags=a
+	--tags='b c'
 `,
 		// TODO: some formats are missing for sure, like "jsonl" or "textproto" from internal/filetypes/types.cue.
 		RunE: mkRunE(c, runExport),
@@ -105,6 +424,34 @@ The following formats are recognized:
 
 	cmd.Flags().Bool(string(flagEscape), false, "use HTML escaping")
 	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "export this expression only")
+	cmd.Flags().Bool(string(flagKeyed), false,
+		"combine the results of all expressions into a struct keyed by expression")
+	cmd.Flags().Bool(string(flagKustomize), false,
+		"write a kustomization.yaml file listing the output file (requires --out k8smanifest)")
+	cmd.Flags().String(string(flagFieldOrder), string(encoding.SourceOrder),
+		"order of fields in the output: source, alpha, or schema")
+	addRedactFlags(cmd.Flags())
+	cmd.Flags().Bool(string(flagProvenance), false,
+		"annotate each field with the files and lines that declare it (cue and yaml output only)")
+	cmd.Flags().String(string(flagMetadata), "",
+		"write the doc comments and attributes of the exported fields as JSON to this file")
+	cmd.Flags().String(string(flagPatchBase), "",
+		"file with the previous version of the output, or - for stdin (requires --out patch)")
+	cmd.Flags().String(string(flagPatchType), string(encoding.MergePatch),
+		"type of patch: merge or strategic")
+	cmd.Flags().String(string(flagPatchKey), "name",
+		"field identifying the elements of lists of objects in a strategic merge patch")
+	cmd.Flags().Bool(string(flagCheckConcrete), false,
+		"allow values of fields with an @incomplete attribute to be incomplete, omitting them from the output")
+	cmd.Flags().StringArray(string(flagAllowIncomplete), nil,
+		"allow values at paths matching this pattern to be incomplete; implies --check-concrete")
+	addKeyFlags(cmd.Flags())
+	cmd.Flags().Bool(string(flagCache), false,
+		"reuse the output of previous runs with unchanged inputs")
+	cmd.Flags().String(string(flagAttest), "",
+		"write an attestation of the inputs and the output to this file")
+	cmd.Flags().Int(string(flagJobs), 0,
+		"number of packages evaluated concurrently (default the number of CPUs)")
 
 	return cmd
 }
@@ -113,6 +460,74 @@ func runExport(cmd *Command, args []string) error {
 	b, err := parseArgs(cmd, args, &config{outMode: filetypes.Export})
 	exitOnErr(cmd, err, true)
 
+	kustomize := flagKustomize.Bool(cmd)
+	if kustomize {
+		switch {
+		case b.outFile.Interpretation != build.KubernetesManifest:
+			return fmt.Errorf("--%s requires --%s k8smanifest", flagKustomize, flagOut)
+		case b.outFile.Filename == "-":
+			return fmt.Errorf("--%s requires --%s", flagKustomize, flagOutFile)
+		}
+	}
+
+	// Tracing observes a single evaluation.
+	b.jobs = flagJobs.Int(cmd)
+	switch {
+	case flagTraceOut.String(cmd) != "":
+		b.jobs = 1
+	case b.jobs <= 0:
+		b.jobs = runtime.GOMAXPROCS(0)
+	}
+
+	attest := flagAttest.String(cmd) != ""
+	var outHash hash.Hash
+	if attest {
+		err := checkAttestable(b)
+		exitOnErr(cmd, err, true)
+		if b.outFile.Filename == "-" {
+			outHash = sha256.New()
+			b.encConfig.Stdout = io.MultiWriter(b.encConfig.Stdout, outHash)
+		}
+	}
+
+	patch := b.outFile.Interpretation == build.Patch
+	switch base := flagPatchBase.String(cmd); {
+	case patch && base == "":
+		return fmt.Errorf("--%s patch requires --%s", flagOut, flagPatchBase)
+	case patch:
+		b.encConfig.Patch, err = patchConfig(cmd, base)
+		exitOnErr(cmd, err, true)
+	case base != "":
+		return fmt.Errorf("--%s requires --%s patch", flagPatchBase, flagOut)
+	}
+
+	metaFile := flagMetadata.String(cmd)
+	var meta bytes.Buffer
+
+	// Only output written to stdout for packages and data files loaded
+	// from files is cached: other inputs cannot be hashed up front.
+	var cache *resultCache
+	var key string
+	var out bytes.Buffer
+	if flagCache.Bool(cmd) && b.outFile.Filename == "-" && b.instance == nil && !patch && !attest &&
+		!b.importing && !flagInjectVars.Bool(cmd) && metaFile == "" {
+		cache, err = newResultCache(cmd, cachedir.Export)
+		exitOnErr(cmd, err, true)
+		var data []*build.File
+		for _, d := range b.orphaned {
+			data = append(data, d.file)
+		}
+		key = cache.key(data, b.insts...)
+		if data, ok := cache.lookup(key); ok {
+			if flagVerbose.Bool(cmd) {
+				fmt.Fprintln(cmd.ErrOrStderr(), "using cached output")
+			}
+			_, err := cmd.OutOrStdout().Write(data)
+			return err
+		}
+		b.encConfig.Stdout = io.MultiWriter(b.encConfig.Stdout, &out)
+	}
+
 	enc, err := encoding.NewEncoder(b.outFile, b.encConfig)
 	exitOnErr(cmd, err, true)
 
@@ -122,11 +537,75 @@ func runExport(cmd *Command, args []string) error {
 		v := iter.value()
 		err = enc.Encode(v)
 		exitOnErr(cmd, err, true)
+		if metaFile != "" {
+			data, err := json.MarshalIndent(metadata.Extract(v), "", "    ")
+			exitOnErr(cmd, err, true)
+			meta.Write(data)
+			meta.WriteByte('\n')
+		}
 	}
 	exitOnErr(cmd, iter.err(), true)
 
 	err = enc.Close()
 	exitOnErr(cmd, err, true)
 
+	if cache != nil && !cmd.hasErr {
+		cache.record(key, out.Bytes())
+	}
+
+	if metaFile != "" && !cmd.hasErr {
+		err := writeOutputFile(metaFile, meta.Bytes(), flagForce.Bool(cmd))
+		exitOnErr(cmd, err, true)
+	}
+
+	if kustomize {
+		err := writeKustomization(b.outFile.Filename, flagForce.Bool(cmd))
+		exitOnErr(cmd, err, true)
+	}
+
+	if attest && !cmd.hasErr {
+		var digest []byte
+		if outHash != nil {
+			digest = outHash.Sum(nil)
+		} else {
+			data, err := os.ReadFile(b.outFile.Filename)
+			exitOnErr(cmd, err, true)
+			sum := sha256.Sum256(data)
+			digest = sum[:]
+		}
+		err := writeAttestation(cmd, b, args, b.outFile.Filename, digest)
+		exitOnErr(cmd, err, true)
+	}
 	return nil
 }
+
+// patchConfig returns the configuration for patch output against the
+// previous version of the output in the file base.
+func patchConfig(cmd *Command, base string) (*encoding.PatchConfig, error) {
+	f, err := filetypes.ParseFile(base, filetypes.Input)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := encoding.ParsePatchType(flagPatchType.String(cmd))
+	if err != nil {
+		return nil, err
+	}
+	return &encoding.PatchConfig{
+		Base:     f,
+		Type:     typ,
+		MergeKey: flagPatchKey.String(cmd),
+	}, nil
+}
+
+// writeKustomization writes a kustomization.yaml file that lists the
+// given manifest file as its only resource, in the manifest's directory.
+func writeKustomization(manifest string, force bool) error {
+	path := filepath.Join(filepath.Dir(manifest), "kustomization.yaml")
+	data := fmt.Sprintf(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+  - %s
+`, filepath.ToSlash(filepath.Base(manifest)))
+
+	return writeOutputFile(path, []byte(data), force)
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// RegisterQualifier registers name as a file type qualifier that is
+// equivalent to spec, which is a qualifier that may combine tags and set
+// options, such as "json+indent=0". This allows programs that embed the
+// cue command to provide qualifiers for their own file types. Qualifiers
+// may also be registered with the CUE_QUALIFIERS environment variable.
+func RegisterQualifier(name, spec string) error {
+	return filetypes.RegisterQualifier(name, spec)
+}
+
+// A Codec implements a custom file encoding. The options given in the
+// qualifier selecting the encoding, such as indent=2 in "mycodec+indent=2",
+// are passed in the Tags field of the file.
+type Codec struct {
+	// Decode decodes the contents of r, read from the file f, as a single
+	// value. It is nil if the encoding cannot be read.
+	Decode func(f *build.File, r io.Reader) (ast.Expr, error)
+
+	// Encode writes v to w, to be written to the file f. It is nil if the
+	// encoding cannot be written.
+	Encode func(f *build.File, w io.Writer, v cue.Value) error
+}
+
+// RegisterCodec registers c as the codec of a custom file encoding with
+// the given name, which the cue command then accepts as a qualifier, as
+// in "cue export name: file.bin". Files in a custom encoding are
+// interpreted as data.
+func RegisterCodec(name string, c Codec) error {
+	return encoding.RegisterCodec(name, encoding.Codec(c))
+}
+
+// registerEnvQualifiers registers the qualifiers configured with the
+// CUE_QUALIFIERS environment variable, a comma-separated list of
+// name=spec entries.
+func registerEnvQualifiers() error {
+	env := os.Getenv("CUE_QUALIFIERS")
+	if env == "" {
+		return nil
+	}
+	for _, entry := range strings.Split(env, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("bad value for $CUE_QUALIFIERS: entry %q is not of the form name=qualifier", entry)
+		}
+		if err := RegisterQualifier(name, spec); err != nil {
+			return fmt.Errorf("bad value for $CUE_QUALIFIERS: %v", err)
+		}
+	}
+	return nil
+}
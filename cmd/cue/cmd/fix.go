@@ -15,16 +15,24 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/langversion"
 	"cuelang.org/go/cue/load"
+	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/mod/semver"
 	"cuelang.org/go/tools/fix"
 	"github.com/spf13/cobra"
 )
@@ -38,12 +46,39 @@ After you update to a new CUE release, fix helps make the necessary changes
 to your program.
 
 Without any packages, fix applies to all files within a module.
+
+By default fix applies all fixes that are not marked optional. Use -r
+to apply only the named fixes, including optional ones. The available
+fixes are listed by --list.
+
+With --to-version, fix migrates the module from the language version
+declared in its cue.mod/module.cue file to the given version. It applies
+the fixes introduced after the declared version up to and including the
+target version, and then updates the language.version field of the
+module file accordingly.
+
+	cue fix --to-version v0.8.0
+
+With --apply-suggestions, fix instead repairs errors for which a fix is
+suggested, such as unused imports, missing commas, labels that need
+quoting, and misspelled field names that differ from an allowed field by
+two swapped characters. Each applied fix is reported. As fixing an error
+may reveal or resolve others, errors without a suggested fix are only
+reported if no fix was applied; run fix again to see what remains.
 `,
 		RunE: mkRunE(c, runFixAll),
 	}
 
 	cmd.Flags().BoolP(string(flagForce), "f", false,
 		"rewrite even when there are errors")
+	cmd.Flags().StringSliceP(string(flagRewrite), "r", nil,
+		"comma-separated list of fixes to apply")
+	cmd.Flags().Bool(string(flagList), false,
+		"list the available fixes")
+	cmd.Flags().String(string(flagToVersion), "",
+		"migrate the module to the given language version")
+	cmd.Flags().Bool(string(flagSuggestions), false,
+		"apply the fixes suggested by errors")
 
 	return cmd
 }
@@ -54,10 +89,39 @@ func runFixAll(cmd *Command, args []string) error {
 		return err
 	}
 
+	if flagList.Bool(cmd) {
+		w := cmd.OutOrStdout()
+		for _, f := range fix.Fixers() {
+			fmt.Fprintf(w, "%-16s %-8s %s", f.Name, f.Version, f.Doc)
+			if f.Optional {
+				fmt.Fprint(w, " (optional)")
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+
 	var opts []fix.Option
 	if flagSimplify.Bool(cmd) {
 		opts = append(opts, fix.Simplify())
 	}
+	if names := flagRewrite.StringSlice(cmd); len(names) > 0 {
+		for _, name := range names {
+			if _, ok := fix.Lookup(name); !ok {
+				return fmt.Errorf("unknown fix %q; run 'cue fix --list' for a list of fixes", name)
+			}
+		}
+		opts = append(opts, fix.Select(names...))
+	}
+
+	var upgrade *languageUpgrade
+	if to := flagToVersion.String(cmd); to != "" {
+		upgrade, err = newLanguageUpgrade(to)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, fix.UpgradeVersion(upgrade.from, upgrade.to))
+	}
 
 	if len(args) == 0 {
 		args = []string{"./..."}
@@ -86,6 +150,10 @@ func runFixAll(cmd *Command, args []string) error {
 		Tools: true,
 	})
 
+	if flagSuggestions.Bool(cmd) {
+		return applySuggestions(cmd, instances)
+	}
+
 	errs := fix.Instances(instances, opts...)
 
 	if errs != nil && flagForce.Bool(cmd) {
@@ -113,9 +181,210 @@ func runFixAll(cmd *Command, args []string) error {
 		}
 	}
 
+	if upgrade != nil && errs == nil {
+		if err := upgrade.writeModFile(); err != nil {
+			return err
+		}
+	}
 	return errs
 }
 
+// applySuggestions applies the fixes suggested by the errors of the given
+// instances. If no fix applies, it returns the errors.
+func applySuggestions(cmd *Command, instances []*build.Instance) error {
+	cmd.useExperiments(instances...)
+	var errs, remaining errors.Error
+	for _, inst := range instances {
+		var err error = inst.Err
+		if err == nil {
+			err = cmd.ctx.BuildInstance(inst).Validate()
+		}
+		if err != nil {
+			errs = errors.Append(errs, errors.Promote(err, "fix"))
+		}
+	}
+
+	edits := map[string][]errors.Edit{}
+	seen := map[errors.Edit]bool{}
+	cwd, _ := os.Getwd()
+	for _, e := range errors.Errors(errs) {
+		suggestions := errors.Suggestions(e)
+		if len(suggestions) == 0 {
+			remaining = errors.Append(remaining, e)
+			continue
+		}
+		s := suggestions[0]
+		for _, edit := range s.Edits {
+			if !seen[edit] {
+				seen[edit] = true
+				file := edit.Pos.Filename()
+				edits[file] = append(edits[file], edit)
+			}
+		}
+		pos := s.Edits[0].Pos.Position()
+		if rel, err := filepath.Rel(cwd, pos.Filename); err == nil {
+			pos.Filename = rel
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", pos, s.Message)
+	}
+
+	for file, a := range edits {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		data = applyEdits(data, a)
+		if b, err := format.Source(data); err == nil {
+			data = b
+		}
+		if err := os.WriteFile(file, data, 0o666); err != nil {
+			return err
+		}
+	}
+	if len(edits) > 0 {
+		return nil
+	}
+	return remaining
+}
+
+// applyEdits applies the given edits to src, skipping edits that overlap
+// with a preceding one. An edit that removes all text from the lines it
+// spans removes those lines altogether.
+func applyEdits(src []byte, edits []errors.Edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Pos.Offset() < edits[j].Pos.Offset()
+	})
+	var b bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		start, end := e.Pos.Offset(), e.End.Offset()
+		if start < last || end > len(src) {
+			continue
+		}
+		if e.NewText == "" {
+			lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+			lineEnd := len(src)
+			if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+				lineEnd = end + i + 1
+			}
+			if lineStart >= last &&
+				len(bytes.TrimSpace(src[lineStart:start])) == 0 &&
+				len(bytes.TrimSpace(src[end:lineEnd])) == 0 {
+				start, end = lineStart, lineEnd
+			}
+		}
+		b.Write(src[last:start])
+		b.WriteString(e.NewText)
+		last = end
+	}
+	b.Write(src[last:])
+	return b.Bytes()
+}
+
+// languageUpgrade holds the state for migrating a module to a newer
+// language version with cue fix --to-version.
+type languageUpgrade struct {
+	modFile string
+	data    []byte
+	file    *ast.File
+	from    string
+	to      string
+}
+
+func newLanguageUpgrade(to string) (*languageUpgrade, error) {
+	if err := langversion.Check(to); err != nil {
+		return nil, fmt.Errorf("invalid --%s: %v", flagToVersion, err)
+	}
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		return nil, err
+	}
+	modFile := filepath.Join(modRoot, "cue.mod", "module.cue")
+	data, err := os.ReadFile(modFile)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile(modFile, data, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	u := &languageUpgrade{
+		modFile: modFile,
+		data:    data,
+		file:    f,
+		to:      semver.Canonical(to),
+	}
+	if lit := languageVersionLit(f); lit != nil {
+		if u.from, err = strconv.Unquote(lit.Value); err != nil {
+			return nil, fmt.Errorf("%s: invalid language version %s", modFile, lit.Value)
+		}
+	}
+	if u.from != "" && semver.Compare(u.to, u.from) < 0 {
+		return nil, fmt.Errorf("cannot migrate from language version %s to older version %s", u.from, u.to)
+	}
+	return u, nil
+}
+
+// writeModFile sets the language version in the module file to the
+// target version. The file is edited in place rather than reformatted
+// so that its layout and comments are preserved.
+func (u *languageUpgrade) writeModFile() error {
+	data := u.data
+	quoted := strconv.Quote(u.to)
+	switch lit, lang := languageVersionLit(u.file), languageStruct(u.file); {
+	case lit != nil:
+		start := lit.Pos().Offset()
+		data = append(data[:start:start], append([]byte(quoted), data[start+len(lit.Value):]...)...)
+	case lang != nil && lang.Rbrace.IsValid():
+		end := lang.Rbrace.Offset()
+		data = append(data[:end:end], append([]byte("\tversion: "+quoted+"\n"), data[end:]...)...)
+	case lang != nil:
+		return fmt.Errorf("%s: cannot add language version", u.modFile)
+	default:
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			data = append(data, '\n')
+		}
+		data = append(data, "language: version: "+quoted+"\n"...)
+	}
+	return os.WriteFile(u.modFile, data, 0o666)
+}
+
+// languageStruct returns the value of the top-level language field in
+// the module file f, or nil if there is none.
+func languageStruct(f *ast.File) *ast.StructLit {
+	for _, d := range f.Decls {
+		if field, ok := d.(*ast.Field); ok && fieldName(field) == "language" {
+			s, _ := field.Value.(*ast.StructLit)
+			return s
+		}
+	}
+	return nil
+}
+
+// languageVersionLit returns the literal holding the language version
+// in the module file f, or nil if there is none.
+func languageVersionLit(f *ast.File) *ast.BasicLit {
+	lang := languageStruct(f)
+	if lang == nil {
+		return nil
+	}
+	for _, d := range lang.Elts {
+		if field, ok := d.(*ast.Field); ok && fieldName(field) == "version" {
+			lit, _ := field.Value.(*ast.BasicLit)
+			return lit
+		}
+	}
+	return nil
+}
+
+func fieldName(f *ast.Field) string {
+	name, _, err := ast.LabelName(f.Label)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
 func appendDirs(a []string, base string) []string {
 	_ = filepath.WalkDir(base, func(path string, entry fs.DirEntry, err error) error {
 		if err == nil && entry.IsDir() && path != base {
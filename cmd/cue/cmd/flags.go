@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 )
 
@@ -34,21 +36,102 @@ const (
 	flagInject        flagName = "inject"
 	flagInjectVars    flagName = "inject-vars"
 
-	flagExpression  flagName = "expression"
-	flagSchema      flagName = "schema"
-	flagEscape      flagName = "escape"
-	flagGlob        flagName = "name"
-	flagRecursive   flagName = "recursive"
-	flagMerge       flagName = "merge"
-	flagList        flagName = "list"
-	flagPath        flagName = "path"
-	flagFiles       flagName = "files"
-	flagProtoPath   flagName = "proto_path"
-	flagProtoEnum   flagName = "proto_enum"
-	flagExt         flagName = "ext"
-	flagWithContext flagName = "with-context"
-	flagOut         flagName = "out"
-	flagOutFile     flagName = "outfile"
+	flagExpression   flagName = "expression"
+	flagSchema       flagName = "schema"
+	flagEscape       flagName = "escape"
+	flagGlob         flagName = "name"
+	flagRecursive    flagName = "recursive"
+	flagMerge        flagName = "merge"
+	flagList         flagName = "list"
+	flagPath         flagName = "path"
+	flagFiles        flagName = "files"
+	flagProtoPath    flagName = "proto_path"
+	flagProtoEnum    flagName = "proto_enum"
+	flagProtoMap     flagName = "proto_map"
+	flagExt          flagName = "ext"
+	flagWithContext  flagName = "with-context"
+	flagOut          flagName = "out"
+	flagOutFile      flagName = "outfile"
+	flagRewrite      flagName = "rewrite"
+	flagErrorFormat  flagName = "error-format"
+	flagErrorReport  flagName = "error-report"
+	flagLogLevel     flagName = "log-level"
+	flagLogFormat    flagName = "log-format"
+	flagMajor        flagName = "major"
+	flagToVersion    flagName = "to-version"
+	flagKustomize    flagName = "kustomization"
+	flagExamples     flagName = "examples"
+	flagCoverage     flagName = "coverage"
+	flagCompat       flagName = "compat"
+	flagCount        flagName = "count"
+	flagSeed         flagName = "seed"
+	flagExhaustive   flagName = "exhaustive"
+	flagRedact       flagName = "redact"
+	flagRedactPath   flagName = "redact-path"
+	flagFieldOrder   flagName = "field-order"
+	flagProvenance   flagName = "provenance"
+	flagPatchBase    flagName = "patch-base"
+	flagPatchType    flagName = "patch-type"
+	flagPatchKey     flagName = "patch-key"
+	flagDepth        flagName = "depth"
+	flagCache        flagName = "cache"
+	flagAttest       flagName = "attest"
+	flagJobs         flagName = "jobs"
+	flagEach         flagName = "each"
+	flagKeyed        flagName = "keyed"
+	flagStrings      flagName = "strings"
+	flagReport       flagName = "report"
+	flagSuggestions  flagName = "apply-suggestions"
+	flagCommentWidth flagName = "comment-width"
+	flagListen       flagName = "listen"
+
+	flagModule          flagName = "module"
+	flagLanguageVersion flagName = "language-version"
+	flagRequire         flagName = "require"
+	flagDropRequire     flagName = "drop-require"
+	flagReplace         flagName = "replace"
+	flagDropReplace     flagName = "drop-replace"
+	flagRetract         flagName = "retract"
+	flagDropRetract     flagName = "drop-retract"
+
+	flagKeyPrefix    flagName = "key-prefix"
+	flagKeySeparator flagName = "key-separator"
+	flagKeyCase      flagName = "key-case"
+	flagQuote        flagName = "quote"
+
+	flagOpenAPISchemas        flagName = "openapi-schemas"
+	flagOpenAPIOperations     flagName = "openapi-operations"
+	flagOpenAPIComponentsOnly flagName = "openapi-components-only"
+	flagOpenAPINames          flagName = "openapi-names"
+
+	flagJSONSchemaMap flagName = "jsonschema-map"
+
+	flagYAMLBools       flagName = "yaml-bools"
+	flagYAMLSexagesimal flagName = "yaml-sexagesimal"
+	flagYAMLTimestamps  flagName = "yaml-timestamps"
+	flagYAMLStrict      flagName = "yaml-strict"
+	flagYAMLReport      flagName = "yaml-report"
+
+	flagStrictDeprecations flagName = "strict-deprecations"
+	flagDryRun             flagName = "dry-run"
+	flagAllowTasks         flagName = "allow-tasks"
+	flagWriteDir           flagName = "write-dir"
+	flagCheckConcrete      flagName = "check-concrete"
+	flagAllowIncomplete    flagName = "allow-incomplete"
+	flagTraceOut           flagName = "trace-out"
+	flagMatch              flagName = "match"
+	flagSchemaRegistry     flagName = "schema-registry"
+	flagSubject            flagName = "subject"
+	flagSchemaFormat       flagName = "format"
+	flagRecordName         flagName = "record-name"
+	flagTemplate           flagName = "template"
+	flagParam              flagName = "param"
+	flagInterval           flagName = "interval"
+	flagOnce               flagName = "once"
+	flagListIgnores        flagName = "list-ignores"
+	flagBatch              flagName = "batch"
+	flagUnused             flagName = "unused"
+	flagMetadata           flagName = "metadata"
 )
 
 func addOutFlags(f *pflag.FlagSet, allowNonCUE bool) {
@@ -61,9 +144,29 @@ func addOutFlags(f *pflag.FlagSet, allowNonCUE bool) {
 	f.BoolP(string(flagForce), "f", false, "force overwriting existing files")
 }
 
+func addRedactFlags(f *pflag.FlagSet) {
+	f.Bool(string(flagRedact), false,
+		"replace values of fields with a @sensitive attribute with a placeholder")
+	f.StringArray(string(flagRedactPath), nil,
+		"redact values at paths matching this pattern; implies --redact")
+}
+
+func addKeyFlags(f *pflag.FlagSet) {
+	f.String(string(flagKeyPrefix), "",
+		"prefix for the keys of env and flags output")
+	f.String(string(flagKeySeparator), "",
+		`separator joining the labels of keys in env and flags output (default "_" for env, "." for flags)`)
+	f.String(string(flagKeyCase), "",
+		"case of keys in env and flags output: preserve, upper, or lower (default upper for env, preserve for flags)")
+	f.String(string(flagQuote), "shell",
+		"quoting of values in env and flags output: shell, double, or none")
+}
+
 func addGlobalFlags(f *pflag.FlagSet) {
 	f.Bool(string(flagTrace), false,
 		"trace computation")
+	f.String(string(flagTraceOut), "",
+		"write an interactive HTML trace of the evaluation to the given file")
 	f.BoolP(string(flagSimplify), "s", false,
 		"simplify output")
 	f.BoolP(string(flagIgnore), "i", false,
@@ -73,6 +176,14 @@ func addGlobalFlags(f *pflag.FlagSet) {
 	f.BoolP(string(flagVerbose), "v", false,
 		"print information about progress")
 	f.BoolP(string(flagAllErrors), "E", false, "print all available errors")
+	f.String(string(flagErrorFormat), errorFormatText,
+		"format of error messages: text, github, or gitlab")
+	f.String(string(flagErrorReport), "",
+		"file to write the report of --error-format=gitlab to")
+	f.String(string(flagLogLevel), "",
+		"enable diagnostic logs at the given level, such as debug or registry=debug (see 'cue help flags')")
+	f.String(string(flagLogFormat), "text",
+		"format of diagnostic logs: text or json")
 }
 
 func addOrphanFlags(f *pflag.FlagSet) {
@@ -84,13 +195,19 @@ func addOrphanFlags(f *pflag.FlagSet) {
 	f.Bool(string(flagWithContext), false, "import as object with contextual data")
 	f.StringArrayP(string(flagProtoPath), "I", nil, "paths in which to search for imports")
 	f.String(string(flagProtoEnum), "int", "mode for rendering enums (int|json)")
+	f.StringArray(string(flagProtoMap), nil, "map a proto package and its subpackages to a CUE import path: <proto package>=<import path>")
 	f.StringP(string(flagGlob), "n", "", "glob filter for non-CUE file names in directories")
 	f.Bool(string(flagMerge), true, "merge non-CUE files")
+	f.Bool(string(flagYAMLBools), false, "decode YAML 1.1 booleans such as yes, no, on and off as booleans")
+	f.Bool(string(flagYAMLSexagesimal), false, "decode YAML 1.1 base 60 numbers such as 1:30 as numbers")
+	f.Bool(string(flagYAMLTimestamps), false, "normalize YAML timestamps to RFC 3339 strings")
+	f.Bool(string(flagYAMLStrict), false, "report an error for YAML plain scalars whose type differs between YAML 1.1 and 1.2")
+	f.Bool(string(flagYAMLReport), false, "report YAML plain scalars whose type differs between YAML 1.1 and 1.2, and how they were decoded")
 }
 
 func addInjectionFlags(f *pflag.FlagSet, auto, hidden bool) {
 	f.StringArrayP(string(flagInject), "t", nil,
-		"set the value of a tagged field")
+		"set the value of a tagged field (key=value or key@=file)")
 	f.BoolP(string(flagInjectVars), "T", auto,
 		"inject system variables in tags")
 	if hidden {
@@ -111,7 +228,27 @@ func (f flagName) String(cmd *Command) string {
 	return v
 }
 
+func (f flagName) Int(cmd *Command) int {
+	v, _ := cmd.Flags().GetInt(string(f))
+	return v
+}
+
+func (f flagName) Int64(cmd *Command) int64 {
+	v, _ := cmd.Flags().GetInt64(string(f))
+	return v
+}
+
+func (f flagName) Duration(cmd *Command) time.Duration {
+	v, _ := cmd.Flags().GetDuration(string(f))
+	return v
+}
+
 func (f flagName) StringArray(cmd *Command) []string {
 	v, _ := cmd.Flags().GetStringArray(string(f))
 	return v
 }
+
+func (f flagName) StringSlice(cmd *Command) []string {
+	v, _ := cmd.Flags().GetStringSlice(string(f))
+	return v
+}
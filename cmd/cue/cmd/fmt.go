@@ -15,15 +15,20 @@
 package cmd
 
 import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
-	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/load"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/fmtserver"
 	"cuelang.org/go/tools/fix"
 )
 
@@ -32,8 +37,75 @@ func newFmtCmd(c *Command) *cobra.Command {
 		Use:   "fmt [-s] [inputs]",
 		Short: "formats CUE configuration files",
 		Long: `Fmt formats the given files or the files for the given packages in place
+
+The --strings flag converts double-quoted string literals to another
+form. Labels and interpolations are left unchanged.
+
+	keep       keep strings as written (default)
+	readable   convert strings that need three or more escape
+	           sequences to the form that needs the fewest, such
+	           as a raw string for regular expressions or a
+	           multiline string for embedded scripts
+	quoted     write all strings on a single line
+	multiline  write strings containing newlines as multiline strings
+	raw        like multiline, but use #-delimited raw strings to
+	           avoid escaping quotes and backslashes
+
+For example, with --strings readable
+
+	re: "^\\d+\\.\\d+\\.\\d+$"
+
+is written as
+
+	re: #"^\d+\.\d+\.\d+$"#
+
+The --listen flag runs fmt as a server that formats files on request,
+so that editors can format on save without starting cue each time. The
+address is - for standard input and output, or unix:<path> for a Unix
+domain socket that only the current user can connect to; requests are
+not accepted over the network, as they can write files. Each request is
+a JSON object on a line of its own, naming the file and optionally
+holding its contents and options; the other flags set the default
+options:
+
+	{"id": 1, "path": "/src/x.cue", "source": "a:   1\n"}
+	{"id": 2, "path": "/src/y.cue", "options": {"simplify": true, "strings": "readable", "commentWidth": 80}}
+	{"id": 3, "path": "/src/z.cue", "write": true}
+
+The file is read from disk if the request holds no source, and written
+back if write is true, which is only allowed for existing .cue files
+without a source in the request. Each response is a JSON object on a
+line of its own, holding the id of the request and either the formatted
+file and whether it changed, or an error:
+
+	{"id":1,"source":"a: 1\n","changed":true}
+	{"id":2,"error":"expected operand, found '}':\n    /src/y.cue:3:1"}
+
+A line that is not a valid request ends the session with an error.
+
+The --comment-width flag reflows comments that are on lines of their own
+to the given width, counting a tab as eight columns. Paragraphs are
+rewrapped, while blank comment lines, indented lines, and list items are
+preserved. Comments between the label and value of a field are moved
+before the field. Running fmt again does not change the result.
 `,
 		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			o := fmtserver.Options{
+				Simplify:     flagSimplify.Bool(cmd),
+				Strings:      flagStrings.String(cmd),
+				CommentWidth: flagCommentWidth.Int(cmd),
+			}
+			opts, err := o.FormatOptions()
+			if err != nil {
+				return fmt.Errorf("invalid --%s value %q: must be keep, readable, quoted, multiline, or raw", flagStrings, o.Strings)
+			}
+			if addr := flagListen.String(cmd); addr != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("no inputs may be given with --%s", flagListen)
+				}
+				return serveFmt(cmd, addr, o)
+			}
+
 			plan, err := newBuildPlan(cmd, &config{loadCfg: &load.Config{
 				Tests:       true,
 				Tools:       true,
@@ -47,11 +119,6 @@ func newFmtCmd(c *Command) *cobra.Command {
 				exitOnErr(cmd, errors.Newf(token.NoPos, "invalid args"), true)
 			}
 
-			opts := []format.Option{}
-			if flagSimplify.Bool(cmd) {
-				opts = append(opts, format.Simplify())
-			}
-
 			cfg := *plan.encConfig
 			cfg.Format = opts
 			cfg.Force = true
@@ -101,5 +168,39 @@ func newFmtCmd(c *Command) *cobra.Command {
 			return nil
 		}),
 	}
+	cmd.Flags().String(string(flagStrings), "keep",
+		"form of string literals: keep, readable, quoted, multiline, or raw")
+	cmd.Flags().Int(string(flagCommentWidth), 0,
+		"reflow comments to the given line width")
+	cmd.Flags().String(string(flagListen), "",
+		"serve formatting requests on standard input (-) or a unix socket (unix:<path>)")
 	return cmd
 }
+
+// serveFmt serves formatting requests on addr until the input ends,
+// for standard input and output, or forever, for a socket.
+func serveFmt(cmd *Command, addr string, o fmtserver.Options) error {
+	s, err := fmtserver.New(o)
+	if err != nil {
+		return err
+	}
+	if addr == "-" {
+		return s.Serve(cmd.InOrStdin(), cmd.OutOrStdout())
+	}
+	// Requests can write files, so they are not accepted over the network,
+	// where any local user or web page could send them.
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok || path == "" {
+		return fmt.Errorf("invalid --listen address %q: must be - or unix:<path>", addr)
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	if err := os.Chmod(path, 0o600); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "listening on %s\n", l.Addr())
+	return s.ServeListener(l)
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/tools/datagen"
+)
+
+func newGenCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gen <cmd> [arguments]",
+		Short: "generate artifacts from CUE",
+		Long: `Gen generates artifacts from CUE schemas.
+`,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			stderr := cmd.Stderr()
+			if len(args) == 0 {
+				fmt.Fprintln(stderr, "gen must be run as one of its subcommands")
+			} else {
+				fmt.Fprintf(stderr, "gen must be run as one of its subcommands: unknown subcommand %q\n", args[0])
+			}
+			fmt.Fprintln(stderr, "Run 'cue help gen' for known subcommands.")
+			os.Exit(1) // TODO: get rid of this
+			return nil
+		}),
+	}
+	cmd.AddCommand(newGenDataCmd(c))
+	return cmd
+}
+
+func newGenDataCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "data <definition> [packages]",
+		Short: "generate data that satisfies a definition",
+		Long: `Data generates concrete values that satisfy the given definition of the
+packages, for use as test fixtures or to fuzz systems that consume
+such data.
+
+Numbers are generated within the bounds of the definition, strings to
+match its regular expressions and rune limits, and lists to have a
+length within their limits. Disjunctions, such as enumerations, are
+decided by picking one of the disjuncts, and optional fields are set
+at random. A generated field respects the values of the fields that
+precede it in the definition.
+
+By default, a single random value is generated. The -n flag sets the
+number of values. With --exhaustive, a small set of values is
+generated instead that together use every disjunct, set and omit
+every optional field, and include the bounds of numbers and list
+lengths.
+
+Values are random unless a seed is given with --seed, in which case
+the same values are generated for the same definition. Each value
+is checked against the definition. Constraints that cannot be
+satisfied by generation, such as those relating a field to later
+fields, may cause generation to fail.
+
+The values are written as JSON by default. Use --out to select another
+format, and -o to write to a file.
+
+	# Generate three configurations as YAML.
+	cue gen data '#Config' ./schema -n 3 --out yaml
+
+	# Generate fixtures covering the variations of #Config.
+	cue gen data '#Config' --exhaustive --seed 1 --out jsonl
+`,
+		RunE: mkRunE(c, runGenData),
+	}
+	addOutFlags(cmd.Flags(), true)
+	cmd.Flags().IntP(string(flagCount), "n", 1, "number of values to generate")
+	cmd.Flags().Int64(string(flagSeed), 0, "seed for the random generator (default random)")
+	cmd.Flags().Bool(string(flagExhaustive), false,
+		"generate a set of values covering the choices of the definition")
+	return cmd
+}
+
+func runGenData(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("gen data requires a definition")
+	}
+	path := cue.ParsePath(args[0])
+	if err := path.Err(); err != nil {
+		return err
+	}
+	b, err := parseArgs(cmd, args[1:], &config{outMode: filetypes.Export})
+	exitOnErr(cmd, err, true)
+
+	seed := flagSeed.Int64(cmd)
+	if !cmd.Flag(string(flagSeed)).Changed {
+		seed = time.Now().UnixNano()
+	}
+	g := datagen.New(&datagen.Config{Seed: seed})
+
+	enc, err := encoding.NewEncoder(b.outFile, b.encConfig)
+	exitOnErr(cmd, err, true)
+
+	iter := b.instances()
+	defer iter.close()
+	for iter.scan() {
+		v := iter.value().LookupPath(path)
+		if !v.Exists() {
+			return fmt.Errorf("%s not found", args[0])
+		}
+		exitOnErr(cmd, v.Err(), true)
+
+		var values []cue.Value
+		if flagExhaustive.Bool(cmd) {
+			values, err = g.Exhaustive(v)
+			exitOnErr(cmd, err, true)
+		} else {
+			for i := 0; i < flagCount.Int(cmd); i++ {
+				x, err := g.Generate(v)
+				exitOnErr(cmd, err, true)
+				values = append(values, x)
+			}
+		}
+		for _, x := range values {
+			exitOnErr(cmd, enc.Encode(x), true)
+		}
+	}
+	exitOnErr(cmd, iter.err(), true)
+	return enc.Close()
+}
@@ -118,7 +118,11 @@ Go structs are converted to cue structs adhering to the following conventions:
 	- Field tags are translated to CUE's field attributes. In some cases,
 	  the contents are rewritten to reflect the corresponding types in CUE.
 	  The @go attribute is added if the field name or type definition differs
-	  between the generated CUE and the original Go.
+	  between the generated CUE and the original Go. The @json and @yaml
+	  attributes record the original json and yaml tags if they differ
+	  from the CUE field name, followed by ",omitempty" for optional
+	  fields. Go code generated from CUE uses these attributes to
+	  reproduce the original tags.
 
 
 Native CUE Constraints
@@ -1371,6 +1375,17 @@ func (e *extractor) addFields(x *types.Struct, st *cueast.StructLit) {
 			e.addAttr(field, "protobuf", strings.Join(split, ","))
 		}
 
+		// Carry over JSON and YAML tags that cannot be derived from the
+		// field name and optionality, so that they can be reproduced when
+		// generating Go code.
+		jsonTag, hasJSON := tags.Lookup("json")
+		if hasJSON && jsonTag != canonicalTag(name, kind) {
+			e.addAttr(field, "json", jsonTag)
+		}
+		if t, ok := tags.Lookup("yaml"); ok && (!hasJSON || t != jsonTag) {
+			e.addAttr(field, "yaml", t)
+		}
+
 		// Carry over XML tags.
 		if t := reflect.StructTag(tag).Get("xml"); t != "" {
 			e.addAttr(field, "xml", t)
@@ -1440,3 +1455,12 @@ func getName(name string, tag string) string {
 
 	return name
 }
+
+// canonicalTag returns the json or yaml tag that Go code generation derives
+// for a field with the given CUE name and kind.
+func canonicalTag(name string, kind fieldKind) string {
+	if kind == optional {
+		return name + ",omitempty"
+	}
+	return name
+}
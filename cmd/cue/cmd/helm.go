@@ -0,0 +1,228 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/encoding/helm"
+	cuejson "cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/yaml"
+	internaljson "cuelang.org/go/internal/encoding/json"
+)
+
+func newHelmCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "helm <cmd> [arguments]",
+		Short: "convert between CUE and Helm chart values",
+		Long: `Helm converts between CUE and the files a Helm chart uses to describe
+its values: the values.schema.json JSON Schema and the default values
+in values.yaml.
+
+Chart consumers can use "cue helm import" to validate their values
+against a chart in CUE. Chart authors can use "cue helm export" to keep
+a CUE definition as the source of truth for values.schema.json.
+`,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			stderr := cmd.Stderr()
+			if len(args) == 0 {
+				fmt.Fprintln(stderr, "helm must be run as one of its subcommands")
+			} else {
+				fmt.Fprintf(stderr, "helm must be run as one of its subcommands: unknown subcommand %q\n", args[0])
+			}
+			fmt.Fprintln(stderr, "Run 'cue help helm' for known subcommands.")
+			os.Exit(1) // TODO: get rid of this
+			return nil
+		}),
+	}
+	cmd.AddCommand(newHelmImportCmd(c))
+	cmd.AddCommand(newHelmExportCmd(c))
+	return cmd
+}
+
+func newHelmImportCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [chartdir]",
+		Short: "convert the values schema and defaults of a Helm chart to CUE",
+		Long: `Import converts the values.schema.json and values.yaml files of the Helm
+chart in the given directory, or the current directory if none is given,
+into CUE. At least one of the files must be present.
+
+The fields of the generated file describe the values of the chart, with
+each value set in values.yaml marked as the default of its field.
+Unifying the result with the values for a release therefore validates
+them and yields the values Helm would use. It is an error if the
+defaults do not conform to the schema.
+
+The result is written to values.cue in the chart directory, unless
+another file is selected with -o.
+`,
+		RunE: mkRunE(c, runHelmImport),
+	}
+	cmd.Flags().StringP(string(flagPackage), "p", "values", "package name of the generated file")
+	cmd.Flags().StringP(string(flagOutFile), "o", "", "output file (default <chartdir>/values.cue); - for stdout")
+	cmd.Flags().BoolP(string(flagForce), "f", false, "force overwriting an existing file")
+	return cmd
+}
+
+func runHelmImport(cmd *Command, args []string) error {
+	dir := "."
+	switch len(args) {
+	case 0:
+	case 1:
+		dir = args[0]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	schemaFile := filepath.Join(dir, "values.schema.json")
+	valuesFile := filepath.Join(dir, "values.yaml")
+
+	var schema, values cue.Value
+	data, err := os.ReadFile(schemaFile)
+	switch {
+	case err == nil:
+		expr, err := cuejson.Extract(schemaFile, data)
+		if err != nil {
+			return err
+		}
+		schema = cmd.ctx.BuildExpr(expr)
+		if err := schema.Err(); err != nil {
+			return err
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	data, err = os.ReadFile(valuesFile)
+	switch {
+	case err == nil:
+		f, err := yaml.Extract(valuesFile, data)
+		if err != nil {
+			return err
+		}
+		values = cmd.ctx.BuildFile(f)
+		if err := values.Err(); err != nil {
+			return err
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	if !schema.Exists() && !values.Exists() {
+		return fmt.Errorf("no values.schema.json or values.yaml file found in %s", dir)
+	}
+
+	f, err := helm.Extract(schema, values, &helm.Config{
+		PkgName: flagPackage.String(cmd),
+	})
+	if err != nil {
+		return err
+	}
+	b, err := format.Node(f, format.Simplify())
+	if err != nil {
+		return err
+	}
+	out := flagOutFile.String(cmd)
+	if out == "" {
+		out = filepath.Join(dir, "values.cue")
+	}
+	return writeHelmOutput(cmd, out, b)
+}
+
+func newHelmExportCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [package]",
+		Short: "generate a Helm values.schema.json file from CUE",
+		Long: `Export generates the contents of a Helm chart's values.schema.json file
+from a CUE definition, #Values by default, in the given package.
+Definitions referred to by the values definition are included in the
+definitions section of the schema.
+
+The schema is written to stdout, unless an output file is selected
+with -o.
+
+	cue helm export -o mychart/values.schema.json ./schema
+`,
+		RunE: mkRunE(c, runHelmExport),
+	}
+	cmd.Flags().StringP(string(flagExpression), "e", "#Values", "definition describing the values")
+	cmd.Flags().StringP(string(flagOutFile), "o", "-", "output file; - for stdout")
+	cmd.Flags().BoolP(string(flagForce), "f", false, "force overwriting an existing file")
+	return cmd
+}
+
+func runHelmExport(cmd *Command, args []string) error {
+	cfg, err := defaultConfig()
+	if err != nil {
+		return err
+	}
+	insts := load.Instances(args, cfg.loadCfg)
+	if len(insts) != 1 {
+		return fmt.Errorf("helm export requires a single package")
+	}
+	if err := insts[0].Err; err != nil {
+		return err
+	}
+	cmd.useExperiments(insts...)
+	v := cmd.ctx.BuildInstance(insts[0])
+	if err := v.Err(); err != nil {
+		return err
+	}
+	expr := flagExpression.String(cmd)
+	v = v.LookupPath(cue.ParsePath(expr))
+	if !v.Exists() {
+		return fmt.Errorf("%s not found", expr)
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+	s, err := helm.Generate(v, nil)
+	if err != nil {
+		return err
+	}
+	b, err := internaljson.Encode(s)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	return writeHelmOutput(cmd, flagOutFile.String(cmd), buf.Bytes())
+}
+
+// writeHelmOutput writes data to the file out, or to stdout if out is -.
+func writeHelmOutput(cmd *Command, out string, data []byte) error {
+	if out == "-" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if !flagForce.Bool(cmd) {
+		if _, err := os.Stat(out); err == nil {
+			return fmt.Errorf("%s already exists; use -f to overwrite", out)
+		}
+	}
+	return os.WriteFile(out, data, 0o666)
+}
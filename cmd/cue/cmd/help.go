@@ -15,7 +15,16 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
 	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
 )
 
 // TODO: intersperse the examples at the end of the texts in the
@@ -33,7 +42,14 @@ func newHelpCmd(c *Command) *cobra.Command {
 		Short: "Help about any command",
 		Long: `Help provides help for any command in the application.
 Simply type ` + c.Name() + ` help [path to command] for full details.`,
-		Run: func(_ *cobra.Command, args []string) {
+		Run: func(hc *cobra.Command, args []string) {
+			if list, _ := hc.Flags().GetBool(string(flagList)); list {
+				if len(args) == 0 || (args[0] != "injection" && args[0] != "inject") {
+					exitOnErr(c, errors.New("--list is only supported by cue help injection"), true)
+				}
+				exitOnErr(c, listTags(c, args[1:]), true)
+				return
+			}
 			cmd, _, e := c.Root().Find(args)
 			if len(args) > 0 && args[0] == "cmd" {
 				// args is one of:
@@ -63,9 +79,103 @@ Simply type ` + c.Name() + ` help [path to command] for full details.`,
 			}
 		},
 	}
+	cmd.Flags().Bool(string(flagList), false,
+		"with injection: list the tags declared by the given packages")
 	return cmd
 }
 
+// listTags prints the injection tags declared by each of the packages in
+// args.
+func listTags(c *Command, args []string) error {
+	cfg, err := defaultConfig()
+	if err != nil {
+		return err
+	}
+	binst := loadFromArgs(args, cfg.loadCfg)
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+	printed := false
+	for _, inst := range binst {
+		if err := tagsLoadError(inst); err != nil {
+			return err
+		}
+		tags, err := load.Tags(inst)
+		if err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		if printed {
+			fmt.Fprintln(w)
+		}
+		printed = true
+		fmt.Fprintf(w, "%s:\n", inst.ImportPath)
+
+		// A tag may be declared for multiple fields.
+		seen := map[string]bool{}
+		for _, t := range tags {
+			if seen[t.Name] {
+				continue
+			}
+			seen[t.Name] = true
+			values := t.Type
+			if len(t.Values) > 0 {
+				values = strings.Join(t.Values, "|")
+			}
+			fmt.Fprintf(w, "  -t %s=%s\t%s\n", t.Name, values, tagUsage(t))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	// Remove the padding of tags without a description.
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if strings.HasSuffix(line, "\n") {
+			line = strings.TrimRight(line, " \n") + "\n"
+		}
+		fmt.Fprint(c.OutOrStdout(), line)
+	}
+	return nil
+}
+
+// tagsLoadError returns the error loading inst, ignoring missing required
+// tags, which are expected when listing tags.
+func tagsLoadError(inst *build.Instance) error {
+	for _, err := range errors.Errors(inst.Err) {
+		var missing *load.MissingTagsError
+		if !errors.As(err, &missing) {
+			return inst.Err
+		}
+	}
+	return nil
+}
+
+// tagUsage returns the description of t followed by its options.
+func tagUsage(t *load.Tag) string {
+	var opts []string
+	if t.Required {
+		opts = append(opts, "required")
+	}
+	if t.HasDefault {
+		opts = append(opts, fmt.Sprintf("default: %s", t.Default))
+	}
+	if len(t.Shorthands) > 0 {
+		opts = append(opts, "shorthands: "+strings.Join(t.Shorthands, ", "))
+	}
+	if t.Var != "" {
+		opts = append(opts, "var: "+t.Var)
+	}
+	usage := t.Doc
+	if opts != nil {
+		if usage != "" {
+			usage += " "
+		}
+		usage += "(" + strings.Join(opts, "; ") + ")"
+	}
+	return usage
+}
+
 // TODO(mvdan): having the help topics as top-level commands means that `cue topic`
 // is taken and works as well as `cue help topic`, which is unnecessary.
 // Consider removing support for the short form at some point.
@@ -107,6 +217,15 @@ pkg itself, while foo/.../bar matches all directories named bar
 within foo. In all cases, directories containing cue.mod
 directories are excluded from the result.
 
+With the modules experiment enabled, a package in a module
+registry may be evaluated directly by specifying its import path
+followed by a version, such as foo.com/bar/config@v1.2.3, or by a
+major version, such as foo.com/bar/config@v1, to use the latest
+version. As in imports, a package qualifier may follow, as in
+foo.com/bar@v1.2.3:config. The package is fetched from the
+registry configured with CUE_REGISTRY and does not need to be a
+dependency of the current module, if any.
+
 Directory and file names that begin with "." or "_" are ignored,
 unless explicitly listed as inputs.
 
@@ -126,6 +245,24 @@ differently.
 If a data file has multiple values, such as allowed with JSON
 Lines or YAML, each value is interpreted as a separate file.
 
+YAML and JSON files encrypted with SOPS (https://getsops.io) are
+decrypted with the sops command, version 3.9 or later, when they
+are given as inputs, so that secrets can be unified with other
+values without first writing their plaintext to disk. Keys are
+configured as usual for sops, for instance with SOPS_AGE_KEY_FILE.
+Encrypted files are not decrypted by cue import, which would write
+their plaintext to disk.
+
+YAML is decoded following the YAML 1.2 core schema, so that plain
+scalars such as no, on, 1:30 and 2001-12-14 are strings, except that
+integers with a leading zero, such as 0755, are octal as in YAML 1.1.
+The --yaml-bools and --yaml-sexagesimal flags decode YAML 1.1
+booleans and base 60 numbers as such, and --yaml-timestamps rewrites
+timestamps as RFC 3339 strings. The --yaml-report flag lists the plain
+scalars whose type differs between YAML 1.1 and 1.2 along with how they
+were decoded, and --yaml-strict makes them an error, so that they must
+be quoted or tagged.
+
 If the --schema/-d is specified, data files are not merged, and
 are compared against the specified schema within a package or
 non-data file. For OpenAPI, the -d flag specifies a schema name.
@@ -133,6 +270,17 @@ For JSON Schema the -d flag specifies a schema defined in
 "definitions". In all other cases, the -d flag is a CUE
 expression that is evaluated within the package.
 
+With the modules experiment enabled, the -d flag may also
+refer to a schema published in a module registry, using the
+form importpath:expression, where importpath is an import path
+with a major version, as used in an import declaration, and
+expression is evaluated within the imported package. The data
+files, or the CUE package when no data files are given, are
+then validated against this schema without the need for a
+module or an import declaration. For instance:
+
+    cue vet -d foo.com/schemas/k8s@v1:#Deployment deploy.yaml
+
 Examples (also see also "flags" and "filetypes" help topics):
 
 # Show the definition of each package named foo for each
@@ -144,6 +292,9 @@ $ cue export ./pkg -d Foo foo.yaml
 
 # Unify data.json with schema.json.
 $ cue export data.json schema: schema.json
+
+# Validate foo.yaml against #Deployment in a published module.
+$ cue export -d foo.com/schemas/k8s@v1:#Deployment foo.yaml
 `,
 }
 
@@ -182,12 +333,101 @@ setting.
 
 		Requires that CUE_EXPERIMENT=modules is enabled.
 
+	CUE_PROXY
+		A comma-separated list of module proxies from which to
+		download modules instead of the registries configured with
+		CUE_REGISTRY. A module proxy is a plain HTTP server, such as
+		a static file server or a cache, which serves the versions of
+		a module and the contents of each version as files:
+
+			https://proxy.example.com/example.com/foo/@v/list
+			https://proxy.example.com/example.com/foo/@v/v1.2.3.mod
+			https://proxy.example.com/example.com/foo/@v/v1.2.3.zip
+
+		The cache/download directory of $CUE_MODCACHE has that layout
+		and can be served as a module proxy. Proxies are consulted in
+		order, moving on to the next one when a proxy does not have a
+		module version (HTTP status 404 or 410). The list may include
+		"direct", which stands for the registries configured with
+		CUE_REGISTRY, and "off", which disallows downloading modules.
+
+		For example, given:
+			CUE_PROXY=https://proxy.acme.com,direct
+		modules are downloaded from proxy.acme.com, or from the
+		registry if the proxy does not have them.
+
+		Requires that CUE_EXPERIMENT=modules is enabled.
+
+	CUE_SUMDB
+		The checksum database against which downloaded modules are
+		verified, given as its verifier key optionally followed by a
+		space and its URL, which defaults to https:// followed by the
+		name in the key. A checksum database is a transparency log
+		served with the protocol of the Go checksum database, whose
+		records list the digests of the module.cue file and of the
+		zip file of a module version, as in:
+
+			example.com/foo v1.2.3 mod sha256:a6c0...
+			example.com/foo v1.2.3 zip sha256:4e1b...
+
+		Module versions with checksums recorded in the cue.mod/sums
+		file of the main module, as written by "cue mod tidy", are
+		verified against that file instead. If CUE_SUMDB is unset or
+		"off", module versions missing from cue.mod/sums are trusted
+		on their first download.
+
+		Requires that CUE_EXPERIMENT=modules is enabled.
+
+	CUE_NOSUMDB
+		A comma-separated list of glob patterns, as in Go's path.Match,
+		of module path prefixes that are not looked up in the
+		checksum database, such as those of private modules.
+
+		Requires that CUE_EXPERIMENT=modules is enabled.
+
+	CUE_CACHE_DIR
+		The directory where the cue command keeps its caches, each
+		in a subdirectory named after it, such as mod for the
+		downloaded modules. It defaults to the cue directory of the
+		user's cache directory. See "cue help cache".
+
 	CUE_MODCACHE
 		The directory where the cue command will store downloaded
-		modules.
+		modules. It defaults to the mod directory of $CUE_CACHE_DIR.
+
+		Requires that CUE_EXPERIMENT=modules is enabled.
+
+	CUE_ADVISORY_DB
+		The URL of an advisory database consulted by "cue mod tidy"
+		and "cue mod upgrade", which warn about each dependency
+		version affected by an advisory published in the database.
+		The advisories of a module are fetched from the URL followed
+		by a slash, the module path with its major version, and
+		".json", as in https://db.example.com/example.com/foo@v1.json,
+		and have the following schema:
+
+			advisories: [...{
+				id!:      string
+				summary!: string
+				url?:     string
+				affected!: [...{
+					introduced?: string // first affected version
+					fixed?:      string // first fixed version
+				}]
+			}]
+
+		A missing introduced version means that all the versions
+		before fixed are affected, and a missing fixed version that
+		no version is fixed. Modules without advisories need no
+		document.
 
 		Requires that CUE_EXPERIMENT=modules is enabled.
 
+	CUE_QUALIFIERS
+		A comma-separated list of name=qualifier entries, each
+		defining an additional file type qualifier, such as
+		compact=json+indent=0. See "cue help filetypes".
+
 	CUE_EXPERIMENT
 		Comma-separated list of experiments to enable or disable.
 		The list of available experiments may change arbitrarily over
@@ -261,6 +501,46 @@ a different file. The -files flag may only be used in
 combination with the import command.
 
 
+Reporting errors in CI systems
+
+The --error-format flag selects how errors are reported. The default,
+text, prints errors for humans. The github format prints errors as
+GitHub Actions workflow commands, such as
+
+	::error file=x.cue,line=3,col=4::a: conflicting values 2 and 1
+
+which annotate the reported lines in pull requests. The gitlab format
+writes a GitLab code quality report, a JSON list of issues, to the file
+named by the --error-report flag when the command completes. For example:
+
+	script:
+	  - cue vet --error-format=gitlab --error-report=gl-code-quality-report.json ./...
+	artifacts:
+	  reports:
+	    codequality: gl-code-quality-report.json
+
+File names are reported relative to the current directory, which should
+be the root of the repository.
+
+
+Diagnostic logs
+
+Diagnostic logs are written to stderr. By default, only warnings and
+errors are logged. The --log-level flag sets the levels to log instead.
+Its value is a comma-separated list of levels (debug, info, warn, or
+error), each of which either applies to all components or, when written
+as component=level, to a single component. The components are:
+
+	loader     loading of packages and module dependencies
+	registry   module registry configuration, authentication, and requests
+	evaluator  building of instances
+	flow       scheduling and execution of tasks by cue cmd
+
+For example, --log-level=registry=debug,warn logs registry requests
+while limiting other components to warnings. The --log-format flag
+selects between logs in the text (default) and json formats.
+
+
 Examples:
 
 # Put a value at a path based on its "kind" and "name" fields.
@@ -286,6 +566,12 @@ var filetypeHelp = &cobra.Command{
     jsonl       .jsonl/.ldjson  Line-separated JSON values.
     jsonschema                  JSON Schema.
     openapi                     OpenAPI schema.
+    k8smanifest                 Kubernetes objects as a stream of
+                                documents ordered by kind, namespace
+                                and name (output only).
+    patch                       Changes from a previous version of
+                                a document, as a merge patch (output
+                                only; see 'cue help export').
 	pb                          Use Protobuf mappings (e.g. json+pb)
     textproto    .textproto     Text-based protocol buffers.
     proto        .proto         Protocol Buffer definitions.
@@ -294,6 +580,15 @@ var filetypeHelp = &cobra.Command{
                                 must be of type string.
     binary                      Raw binary file; the evaluated value
                                 must be of type string or bytes.
+    tree                        The value as an indented tree of
+                                fields with their kinds and values,
+                                for browsing large values (output only).
+    env                         KEY=VALUE lines of environment
+                                variables, one per scalar of the
+                                flattened value (output only).
+    flags                       --key=value command line arguments,
+                                one per scalar of the flattened value
+                                (output only).
 
 OpenAPI, JSON Schema and Protocol Buffer definitions are
 always interpreted as schema. YAML and JSON are always
@@ -337,6 +632,24 @@ defaults as requested.
     graph       Like data, but allow references.
     schema      Export data and definitions.
 
+Some encodings accept options, which are given in a qualifier as
+<option>=<value>:
+
+    Option      Description
+    indent      The number of spaces used to indent JSON output;
+                0 selects compact output (e.g. json+indent=0).
+
+Additional qualifiers can be defined with the CUE_QUALIFIERS
+environment variable, which holds a comma-separated list of
+name=qualifier entries. For instance, with
+
+    CUE_QUALIFIERS=compact=json+indent=0,mydata=yaml
+
+the command "cue export --out compact" prints compact JSON and
+"cue eval mydata: file.bin" reads file.bin as YAML. Programs
+that embed the cue command may also register qualifiers and
+custom encodings.
+
 Many commands also support the --out and --outfile/-o flags.
 The --out flag specifies the output type using a qualifier
 (without the ':'). The -o flag specifies an output file
@@ -366,8 +679,9 @@ $ cue export -e name -o=text:foo
 }
 
 var injectHelp = &cobra.Command{
-	Use:   "injection",
-	Short: "inject files or values into specific fields for a build",
+	Use:     "injection",
+	Aliases: []string{"inject"},
+	Short:   "inject files or values into specific fields for a build",
 	Long: `Many of the cue commands allow injecting values or
 selecting files from the command line using the --inject/-t flag.
 
@@ -407,7 +721,7 @@ an "--inject key=value" flag will modify the field to
 
 By default, the injected value is treated as a string.
 Alternatively, the "type" option allows a value to be interpreted
-as an int, number, or bool. For instance, for a field
+as an int, number, bool, or JSON value. For instance, for a field
 
    field: x @tag(key,type=int)
 
@@ -415,7 +729,13 @@ the flag "-t key=2" modifies the field to
 
    field: x & 2
 
-Valid values for type are "int", "number", "bool", and "string".
+Valid values for type are "int", "number", "bool", "string", and
+"json". A value of type "json" may be any JSON value, such as
+
+   -t 'key={"replicas": 2, "debug": true}'
+
+The contents of a file can be injected with "-t key@=file", which
+interprets the contents of the file as a value of the tag's type.
 
 A tag attribute can also define shorthand values, which can be
 injected into the fields without having to specify the key. For
@@ -433,6 +753,34 @@ field. For instance
 
 ensures the user may only specify "prod" or "staging".
 
+A tag with the "required" option must be set. For instance, for
+
+   environment: string @tag(env,required)
+
+any command fails if "-t env=..." is not specified. All missing
+required tags are reported at once.
+
+The "values" option lists the values a tag may be set to, the
+"default" option gives a value that is injected if the tag is not
+set, and the "doc" option describes the tag. For instance, for
+
+   replicas: int @tag(replicas,type=int,values=1|3|5,default=1,doc="number of replicas")
+
+"-t replicas=2" is an error, and replicas is 1 if the tag is not
+set. Values are checked against the type and allowed values of
+a tag as they are injected.
+
+
+Listing tags
+
+The --list flag lists the tags declared by each package of a
+configuration, along with their types or allowed values, their
+descriptions, and their options. For instance,
+
+   cue help inject --list ./...
+
+lists the tags declared by the packages in the current module.
+
 
 Tag variables
 
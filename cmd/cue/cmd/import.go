@@ -17,13 +17,16 @@ package cmd
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/build"
@@ -34,8 +37,12 @@ import (
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/jsonschema"
 	"cuelang.org/go/encoding/protobuf"
+	cueyaml "cuelang.org/go/encoding/yaml"
 	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/module"
 	"cuelang.org/go/internal/third_party/yaml"
 )
 
@@ -104,6 +111,79 @@ subdirectories as well all dependencies.
 
 The module root is implicitly added as an import path.
 
+Packages whose import path lies within the current module are
+written to the corresponding directory of the module, mirroring
+the package hierarchy, regardless of where the .proto files are
+located. The --proto_map flag maps a proto package, and all
+packages nested within it, to an import path, taking precedence
+over the go_package option. For instance, with
+
+   cue import proto --proto_map acme=acme.test/api/schemas ./...
+
+the proto package acme.mixer.v1 is written as the package v1 with
+import path acme.test/api/schemas/mixer/v1. A package name may be
+given after a semicolon, as in go_package, in which case the
+mapping only applies to the proto package itself.
+
+
+OpenAPI mode
+
+OpenAPI mode converts the schemas in #/components/schemas of an
+OpenAPI document to CUE definitions. Large documents can be
+restricted to the schemas that are needed:
+
+  --openapi-schemas      only import schemas of which the name
+                         matches the given glob pattern.
+  --openapi-operations   only import schemas used by operations of
+                         which the operationId or a tag matches the
+                         given glob pattern.
+
+Both flags may be repeated, and schemas referred to by selected
+schemas are always included. The --openapi-components-only flag
+omits the info section of the document from the output.
+
+The --openapi-names flag selects how definitions are named:
+
+  full    use the schema name as is; names that are not valid
+          identifiers are stored in #SchemaMap (default).
+  short   use the part of the schema name after the last ".",
+          as in #Pod for io.k8s.api.core.v1.Pod.
+  camel   join the parts of the schema name in camel case,
+          as in #IoK8sApiCoreV1Pod for io.k8s.api.core.v1.Pod.
+
+It is an error for two imported schemas to map to the same name.
+
+  $ cue import openapi --openapi-operations 'pets*' \
+      --openapi-names short -p pets -o pets.cue api.yaml
+
+
+JSON Schema mode
+
+JSON Schema mode converts each JSON Schema file to CUE. By default, the
+CUE file is written next to the schema file, and references to other
+schemas are converted to imports of the host and path of their URL.
+
+The --jsonschema-map flag converts a collection of schemas that refer
+to each other instead. Each schema file is converted to a separate
+package, and references between the schemas, including relative
+references, are converted to imports of these packages. Schema files
+referred to by the given ones are converted as well, if they can be
+found locally: a schema with a URL below the $id of a given schema is
+looked up relative to the file of that schema.
+
+The flag maps a URL, without its scheme, or a directory, and all the
+schemas below it, to an import path, mirroring the hierarchy of the
+schemas in the package hierarchy. A .json, .yaml or .yml extension is
+removed from the import path of a schema, and a package name may be
+given after a colon. Schema files within the current module map to
+the package at the same location by default. Packages whose import
+path lies within the current module are written to the corresponding
+directory of the module; others are written to cue.mod/gen.
+
+  $ cue import jsonschema \
+      --jsonschema-map https://schemas.acme.com/v1=acme.test/api/v1 \
+      ./schemas/person.json
+
 
 Binary mode
 
@@ -259,6 +339,11 @@ Example:
 	cmd.Flags().Bool(string(flagDryrun), false, "only run simulation")
 	cmd.Flags().BoolP(string(flagRecursive), "R", false, "recursively parse string values")
 	cmd.Flags().StringArray(string(flagExt), nil, "match files with these extensions")
+	cmd.Flags().StringArray(string(flagOpenAPISchemas), nil, "only import OpenAPI component schemas matching this glob")
+	cmd.Flags().StringArray(string(flagOpenAPIOperations), nil, "only import OpenAPI schemas used by operations with a matching operationId or tag")
+	cmd.Flags().Bool(string(flagOpenAPIComponentsOnly), false, "only import OpenAPI schemas, omitting the info section")
+	cmd.Flags().String(string(flagOpenAPINames), "full", "naming of definitions for OpenAPI schemas: full, short, or camel")
+	cmd.Flags().StringArray(string(flagJSONSchemaMap), nil, "map JSON Schema URLs or directories to CUE import paths: <URL or directory>=<import path>")
 
 	return cmd
 }
@@ -300,6 +385,10 @@ func runImport(cmd *Command, args []string) (err error) {
 		case "auto", "openapi", "jsonschema":
 			c.interpretation = build.Interpretation(mode)
 			c.encoding = "yaml"
+			if mode == "jsonschema" && len(flagJSONSchemaMap.StringArray(cmd)) > 0 {
+				// The schemas are converted as a whole by jsonSchemaMode.
+				c.interpretation = ""
+			}
 		case "data":
 			// default mode for encoding/ no interpretation.
 			c.encoding = ""
@@ -319,6 +408,12 @@ func runImport(cmd *Command, args []string) (err error) {
 		err = genericMode(cmd, b)
 	case "proto":
 		err = protoMode(b)
+	case "jsonschema":
+		if len(flagJSONSchemaMap.StringArray(cmd)) > 0 {
+			err = jsonSchemaMode(b)
+		} else {
+			err = genericMode(cmd, b)
+		}
 	}
 
 	exitOnErr(cmd, err, true)
@@ -366,6 +461,16 @@ func protoMode(b *buildPlan) error {
 		PkgName:  b.encConfig.PkgName,
 		EnumMode: flagProtoEnum.String(b.cmd),
 	}
+	for _, m := range flagProtoMap.StringArray(b.cmd) {
+		pkg, importPath, ok := strings.Cut(m, "=")
+		if !ok || importPath == "" {
+			return fmt.Errorf("invalid --%s value %q: must be of the form <proto package>=<import path>", flagProtoMap, m)
+		}
+		if c.ImportPaths == nil {
+			c.ImportPaths = map[string]string{}
+		}
+		c.ImportPaths[pkg] = importPath
+	}
 	if module != "" {
 		// We only allow imports from packages within the module if an actual
 		// module is allowed.
@@ -409,6 +514,126 @@ func protoMode(b *buildPlan) error {
 	return nil
 }
 
+func jsonSchemaMode(b *buildPlan) error {
+	root := ""
+	modPath := ""
+	var schemaFiles []*build.File
+	for _, inst := range b.insts {
+		for _, f := range inst.OrphanedFiles {
+			switch f.Encoding {
+			case build.JSON, build.YAML:
+				schemaFiles = append(schemaFiles, f)
+			}
+		}
+		if root == "" && inst.Root != "" {
+			root = inst.Root
+			modPath = inst.Module
+		}
+	}
+	if root != "" && modPath == "" {
+		// Instances of files given on the command line have no module.
+		modFile := filepath.Join(root, "cue.mod", "module.cue")
+		if data, err := os.ReadFile(modFile); err == nil {
+			mf, err := modfile.ParseNonStrict(data, modFile)
+			if err != nil {
+				return err
+			}
+			modPath = mf.Module
+		}
+	}
+	if p, _, ok := module.SplitPathVersion(modPath); ok {
+		modPath = p
+	}
+
+	ctx := b.cmd.ctx
+	load := func(filename string) (cue.Value, error) {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		if filepath.Ext(filename) == ".json" {
+			expr, err := json.Extract(filename, data)
+			if err != nil {
+				return cue.Value{}, err
+			}
+			return ctx.BuildExpr(expr), nil
+		}
+		f, err := cueyaml.ExtractWithConfig(filename, data, b.encConfig.YAML)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		return ctx.BuildFile(f), nil
+	}
+	cfg := &jsonschema.Config{
+		ImportPaths: map[string]string{},
+		LoadFile:    load,
+		Strict:      b.encConfig.Strict,
+	}
+	if root != "" && modPath != "" {
+		// Schema files within the module are converted to the packages
+		// at the same location by default.
+		cfg.ImportPaths[filepath.ToSlash(root)] = modPath
+	}
+	for _, m := range flagJSONSchemaMap.StringArray(b.cmd) {
+		key, importPath, ok := strings.Cut(m, "=")
+		if !ok || key == "" || importPath == "" {
+			return fmt.Errorf("invalid --%s value %q: must be of the form <URL or directory>=<import path>", flagJSONSchemaMap, m)
+		}
+		if u, err := url.Parse(key); err == nil && u.Scheme != "" && u.Host != "" {
+			key = u.Host + u.Path
+		} else if abs, err := filepath.Abs(key); err == nil {
+			key = filepath.ToSlash(abs)
+		}
+		cfg.ImportPaths[key] = importPath
+	}
+
+	e := jsonschema.NewExtractor(cfg)
+	for _, f := range schemaFiles {
+		v, err := load(f.Filename)
+		if err != nil {
+			return err
+		}
+		if err := e.AddFile(f.Filename, v); err != nil {
+			return err
+		}
+	}
+	insts, err := e.Instances()
+	if err != nil {
+		return err
+	}
+
+	genDir := ""
+	if root != "" {
+		genDir = internal.GenPath(root)
+	}
+	for _, inst := range insts {
+		dir := ""
+		switch rel, ok := strings.CutPrefix(inst.ImportPath+"/", modPath+"/"); {
+		case modPath != "" && ok:
+			// Mirror the package hierarchy within the module.
+			dir = filepath.Join(root, filepath.FromSlash(rel))
+		case genDir != "":
+			dir = filepath.Join(genDir, filepath.FromSlash(inst.ImportPath))
+		default:
+			return fmt.Errorf("cannot place package %s for %s outside of a module", inst.ImportPath, inst.DisplayPath)
+		}
+		for _, f := range inst.Files {
+			f.Filename = filepath.Join(dir, f.Filename)
+			cueFile, err := getFilename(b, f, root, flagForce.Bool(b.cmd))
+			if err != nil {
+				return err
+			}
+			if cueFile == "" {
+				continue // skipped
+			}
+			if err := writeFile(b, f, cueFile); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func genericMode(cmd *Command, b *buildPlan) error {
 	pkgFlag := flagPackage.String(cmd)
 	for _, pkg := range b.insts {
@@ -620,3 +845,29 @@ func (h *hoister) uniqueName(base, prefix, typ string) string {
 		typ += "x"
 	}
 }
+
+// openAPIDefinitionName returns the function used to name the definitions
+// for OpenAPI schemas, as selected by the --openapi-names flag.
+func openAPIDefinitionName(style string) (func(string) string, error) {
+	switch style {
+	case "", "full":
+		return nil, nil
+	case "short":
+		return func(name string) string {
+			return name[strings.LastIndexByte(name, '.')+1:]
+		}, nil
+	case "camel":
+		return func(name string) string {
+			var b strings.Builder
+			for _, s := range strings.FieldsFunc(name, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			}) {
+				r, n := utf8.DecodeRuneInString(s)
+				b.WriteRune(unicode.ToUpper(r))
+				b.WriteString(s[n:])
+			}
+			return b.String()
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid value %q for --%s: must be full, short, or camel", style, flagOpenAPINames)
+}
@@ -0,0 +1,318 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+func newInitCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init --template <package> [dir]",
+		Short: "create files from a template",
+		Long: `Init instantiates a template, writing the files it declares to the
+given directory, or to the current directory if none is given. A
+template is a CUE package, typically published to a module registry,
+that declares parameters and the files to create from them, for
+instance to scaffold the configuration of a new service.
+
+The template is given with --template, either as a reference to a
+package in a registry, such as foo.com/templates/service@v1 or
+foo.com/templates/service@v1.2.0, or as a local directory. A major
+version selects the latest version of the template.
+
+A template declares its parameters as the fields of params and the
+files to create as the fields of files, keyed by their path relative
+to the output directory:
+
+	package service
+
+	params: {
+		// name is the name of the service.
+		name: string & =~"^[a-z][a-z0-9-]*$"
+
+		// port is the port on which the service listens.
+		port: *8080 | int & >0 & <65536
+	}
+
+	files: {
+		"cue.mod/module.cue": """
+			module: "example.com/\(params.name)"
+			language: version: "v0.8.0"
+			"""
+		"config/service.yaml": {
+			service: params.name
+			listen:  params.port
+		}
+	}
+
+Files given as strings are written as is. Other values are encoded
+according to the extension of the file, such as .cue, .json, or .yaml.
+
+Parameters are given with --param name=value. The values of string
+parameters are taken literally; other values are parsed as CUE, as in
+--param port=9090. Parameters that are not given and have no default
+are prompted for on stdin, along with their documentation. All
+parameters are validated against the template before any file is
+written, and existing files are only overwritten with --force.
+
+	cue init --template foo.com/templates/service@v1 --param name=orders
+`,
+		RunE: mkRunE(c, runInit),
+	}
+
+	cmd.Flags().String(string(flagTemplate), "", "package of the template to instantiate")
+	cmd.Flags().StringArray(string(flagParam), nil, "set a parameter of the template, as in name=value")
+	cmd.Flags().BoolP(string(flagForce), "f", false, "overwrite existing files")
+	return cmd
+}
+
+func runInit(cmd *Command, args []string) error {
+	tmpl := flagTemplate.String(cmd)
+	if tmpl == "" {
+		return fmt.Errorf("no template specified; use --%s, or 'cue mod init' to create an empty module", flagTemplate)
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("too many arguments")
+	}
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	cfg, err := defaultConfig()
+	if err != nil {
+		return err
+	}
+	b, err := loadTemplate(tmpl, cfg.loadCfg)
+	if err != nil {
+		return err
+	}
+	v := cmd.ctx.BuildInstance(b)
+	if err := v.Err(); err != nil {
+		return err
+	}
+	params := v.LookupPath(cue.MakePath(cue.Str("params")))
+	files := v.LookupPath(cue.MakePath(cue.Str("files")))
+	if !files.Exists() {
+		return fmt.Errorf("%s is not a template: no files declared", tmpl)
+	}
+
+	for _, p := range flagParam.StringArray(cmd) {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok {
+			return fmt.Errorf("invalid parameter %q: must be of the form name=value", p)
+		}
+		if v, err = setParam(v, params, name, value); err != nil {
+			return err
+		}
+		params = v.LookupPath(cue.MakePath(cue.Str("params")))
+	}
+	if v, err = promptParams(cmd, v, params); err != nil {
+		return err
+	}
+	if err := v.LookupPath(cue.MakePath(cue.Str("params"))).Validate(cue.Concrete(true)); err != nil {
+		return err
+	}
+
+	out, err := templateFiles(v.LookupPath(cue.MakePath(cue.Str("files"))))
+	if err != nil {
+		return err
+	}
+	if !flagForce.Bool(cmd) {
+		for _, f := range out {
+			path := filepath.Join(dir, filepath.FromSlash(f.name))
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists; use --%s to overwrite", path, flagForce)
+			}
+		}
+	}
+	for _, f := range out {
+		path := filepath.Join(dir, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, f.data, 0o666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTemplate loads the template package referred to by arg, which is
+// either a package in a registry or a local directory.
+func loadTemplate(arg string, cfg *load.Config) (*build.Instance, error) {
+	if remotePackageRE.MatchString(arg) && cfg.Registry != nil {
+		if _, err := os.Stat(arg); err != nil {
+			return loadRemotePackage(arg, cfg)
+		}
+	}
+	insts := load.Instances([]string{arg}, cfg)
+	if len(insts) != 1 {
+		return nil, fmt.Errorf("template must be a single package")
+	}
+	return insts[0], insts[0].Err
+}
+
+// setParam sets the parameter name of the template v to the given value,
+// which is parsed as CUE unless the parameter is a string.
+func setParam(v, params cue.Value, name, value string) (cue.Value, error) {
+	p := params.LookupPath(cue.MakePath(cue.Str(name)))
+	if !p.Exists() {
+		return v, fmt.Errorf("unknown parameter %q", name)
+	}
+	var x cue.Value
+	if p.IncompleteKind() == cue.StringKind {
+		x = v.Context().Encode(value)
+	} else {
+		x = v.Context().CompileString(value, cue.Filename("--param "+name))
+	}
+	if err := x.Err(); err != nil {
+		return v, err
+	}
+	v = v.FillPath(cue.MakePath(cue.Str("params"), cue.Str(name)), x)
+	return v, v.LookupPath(cue.MakePath(cue.Str("params"), cue.Str(name))).Err()
+}
+
+// promptParams prompts for the values of the parameters that have no
+// value yet, reading them from stdin. Invalid values are prompted for
+// again.
+func promptParams(cmd *Command, v, params cue.Value) (cue.Value, error) {
+	iter, err := params.Fields()
+	if err != nil {
+		return v, err
+	}
+	var in *bufio.Reader
+	w := cmd.OutOrStdout()
+	for iter.Next() {
+		p := iter.Value()
+		if p.IsConcrete() {
+			continue
+		}
+		if _, ok := p.Default(); ok {
+			continue
+		}
+		name := iter.Selector().Unquoted()
+		if in == nil {
+			in = bufio.NewReader(cmd.InOrStdin())
+		}
+		for {
+			if doc := paramDoc(name, p); doc != "" {
+				fmt.Fprintf(w, "%s (%s): ", name, doc)
+			} else {
+				fmt.Fprintf(w, "%s: ", name)
+			}
+			line, err := in.ReadString('\n')
+			line = strings.TrimSpace(line)
+			if err == io.EOF && line == "" {
+				fmt.Fprintln(w)
+				return v, fmt.Errorf("no value given for parameter %s; use --%s %s=value", name, flagParam, name)
+			} else if err != nil && err != io.EOF {
+				return v, err
+			}
+			x, err := setParam(v, params, name, line)
+			if err == nil {
+				err = x.LookupPath(cue.MakePath(cue.Str("params"), cue.Str(name))).Validate(cue.Concrete(true))
+			}
+			if err == nil {
+				v = x
+				break
+			}
+			fmt.Fprintln(w, err)
+		}
+	}
+	return v, nil
+}
+
+// paramDoc returns the first line of the documentation of a parameter,
+// omitting the name of the parameter if the documentation starts with
+// it, as is customary.
+func paramDoc(name string, v cue.Value) string {
+	for _, c := range v.Doc() {
+		text, _, _ := strings.Cut(strings.TrimSpace(c.Text()), "\n")
+		if text != "" {
+			text = strings.TrimPrefix(text, name+" is ")
+			return strings.TrimSuffix(text, ".")
+		}
+	}
+	return ""
+}
+
+type templateFile struct {
+	name string
+	data []byte
+}
+
+// templateFiles returns the contents of the files declared by a template.
+func templateFiles(files cue.Value) ([]templateFile, error) {
+	iter, err := files.Fields()
+	if err != nil {
+		return nil, err
+	}
+	var out []templateFile
+	for iter.Next() {
+		name := iter.Selector().Unquoted()
+		f := iter.Value()
+		if clean := path.Clean(name); clean != name || path.IsAbs(name) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return nil, errors.Newf(f.Pos(), "invalid file path %q", name)
+		}
+		if err := f.Validate(cue.Concrete(true)); err != nil {
+			return nil, err
+		}
+		var data []byte
+		if f.Kind() == cue.StringKind {
+			s, _ := f.String()
+			if s != "" && !strings.HasSuffix(s, "\n") {
+				s += "\n"
+			}
+			data = []byte(s)
+		} else {
+			bf, err := filetypes.ParseFile(name, filetypes.Export)
+			if err != nil {
+				return nil, errors.Newf(f.Pos(), "cannot determine the format of %s; give its contents as a string", name)
+			}
+			var buf bytes.Buffer
+			enc, err := encoding.NewEncoder(bf, &encoding.Config{Mode: filetypes.Export, Out: &buf})
+			if err != nil {
+				return nil, err
+			}
+			if err := enc.Encode(f); err != nil {
+				return nil, err
+			}
+			if err := enc.Close(); err != nil {
+				return nil, err
+			}
+			data = buf.Bytes()
+		}
+		out = append(out, templateFile{name: name, data: data})
+	}
+	return out, nil
+}
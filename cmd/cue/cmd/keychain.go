@@ -0,0 +1,205 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Secret stores such as the macOS keychain or libsecret on Linux tend to
+// have low size limits, so rather than storing logins there directly, we
+// store a key there which is used to encrypt the logins file.
+
+const (
+	keychainService = "cue"
+	keychainAccount = "logins-key"
+)
+
+// errSecretNotFound is returned by a keychain if a secret does not exist.
+var errSecretNotFound = errors.New("secret not found")
+
+// A keychain stores secrets in the operating system's credential store.
+type keychain interface {
+	// get returns the secret for the given service and account,
+	// or errSecretNotFound if there is none.
+	get(service, account string) (string, error)
+	// set stores the secret for the given service and account,
+	// replacing any existing one.
+	set(service, account, secret string) error
+}
+
+// systemKeychain returns the keychain of the operating system,
+// or nil if none is available. It is a variable so that tests can
+// replace it.
+var systemKeychain = func() keychain {
+	if os.Getenv("CUE_NO_KEYCHAIN") != "" {
+		return nil
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return macKeychain{}
+		}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		// libsecret requires a D-Bus session, which is not available
+		// in headless environments such as most CI systems.
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return nil
+		}
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return secretToolKeychain{}
+		}
+	}
+	// TODO: support the Windows Credential Manager.
+	return nil
+}
+
+// macKeychain uses the macOS keychain via the security command.
+type macKeychain struct{}
+
+func (macKeychain) get(service, account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password",
+		"-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		// The security command exits with status 44 if the item
+		// could not be found.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return "", errSecretNotFound
+		}
+		return "", fmt.Errorf("cannot read from keychain: %v", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (k macKeychain) set(service, account, secret string) error {
+	// Arguments of a command can be seen by other users, so the secret
+	// is passed to the security command on its standard input, which it
+	// reads commands from in interactive mode. The service, account, and
+	// secret never contain quotes.
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %q -a %q -w %q\n",
+		service, account, secret))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cannot write to keychain: %v: %s", err, bytes.TrimSpace(out))
+	}
+	// The security command does not report errors in interactive mode
+	// by its exit status, so check that the secret was stored.
+	got, err := k.get(service, account)
+	if err == nil && got != secret {
+		err = errors.New("secret was not stored")
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write to keychain: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// secretToolKeychain uses libsecret via the secret-tool command.
+type secretToolKeychain struct{}
+
+func (secretToolKeychain) get(service, account string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup",
+		"service", service, "account", account).Output()
+	if err != nil {
+		// secret-tool exits with status 1 and no output if the item
+		// could not be found.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+			return "", errSecretNotFound
+		}
+		return "", fmt.Errorf("cannot read from secret service: %v", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (secretToolKeychain) set(service, account, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label=CUE registry logins",
+		"service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cannot write to secret service: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// loginsKey returns the key used to encrypt the logins file, creating and
+// storing a new one in kc if create is set and there is none yet.
+func loginsKey(kc keychain, create bool) ([]byte, error) {
+	s, err := kc.get(keychainService, keychainAccount)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(s)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("invalid logins key in keychain")
+		}
+		return key, nil
+	}
+	if !errors.Is(err, errSecretNotFound) || !create {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := kc.set(keychainService, keychainAccount, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// encryptLogins encrypts data with AES-GCM, prefixing the result with the
+// random nonce used.
+func encryptLogins(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func decryptLogins(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted logins file is too short")
+	}
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, data, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"cuelang.org/go/cue/logging"
+)
+
+var logComponents = []logging.Component{
+	logging.Loader,
+	logging.Registry,
+	logging.Evaluator,
+	logging.Flow,
+}
+
+// setupLogging installs a log handler as configured by the --log-level and
+// --log-format flags. It returns a function restoring the previous handler.
+func setupLogging(c *Command) (restore func(), err error) {
+	spec := flagLogLevel.String(c)
+	format := flagLogFormat.String(c)
+	if format != "text" && format != "json" {
+		return nil, fmt.Errorf("unknown log format %q; must be text or json", format)
+	}
+	// Warnings are logged unless the flag says otherwise, as they are
+	// meant to be seen by users.
+	var defaultLevel slog.Leveler = slog.LevelWarn
+	levels := map[logging.Component]slog.Leveler{}
+	if spec != "" {
+		defaultLevel, levels, err = parseLogLevels(spec)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var h slog.Handler
+	w := c.root.ErrOrStderr()
+	opts := &slog.HandlerOptions{
+		Level: slog.LevelDebug - 8, // filtering is done by logging.LevelFilter
+	}
+	if inTest {
+		opts.ReplaceAttr = dropTime
+	}
+	if format == "json" {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		h = slog.NewTextHandler(w, opts)
+	}
+	prev := logging.Handler()
+	logging.SetHandler(logging.LevelFilter(h, defaultLevel, levels))
+	return func() { logging.SetHandler(prev) }, nil
+}
+
+// parseLogLevels parses a --log-level flag value: a comma-separated list
+// of levels, each either applying to all components or, in the form
+// component=level, to a single component. Components not otherwise
+// configured log at the default level, or not at all if no default is
+// given.
+func parseLogLevels(spec string) (slog.Leveler, map[logging.Component]slog.Leveler, error) {
+	var defaultLevel slog.Leveler = slog.Level(1 << 10) // effectively disabled
+	levels := map[logging.Component]slog.Leveler{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, levelStr, ok := strings.Cut(item, "=")
+		if !ok {
+			levelStr = name
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(levelStr)); err != nil {
+			return nil, nil, fmt.Errorf("invalid log level %q", levelStr)
+		}
+		if !ok {
+			defaultLevel = level
+			continue
+		}
+		c := logging.Component(name)
+		if !isLogComponent(c) {
+			return nil, nil, fmt.Errorf("unknown log component %q; must be one of %s", name, logComponentNames())
+		}
+		levels[c] = level
+	}
+	return defaultLevel, levels, nil
+}
+
+func isLogComponent(c logging.Component) bool {
+	for _, x := range logComponents {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+func logComponentNames() string {
+	var a []string
+	for _, c := range logComponents {
+		a = append(a, string(c))
+	}
+	return strings.Join(a, ", ")
+}
+
+// dropTime removes the time from log records, so that the output is
+// deterministic in tests.
+func dropTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == "duration") {
+		return slog.Attr{}
+	}
+	return a
+}
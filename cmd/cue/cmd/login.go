@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -29,18 +30,7 @@ import (
 	"golang.org/x/oauth2"
 )
 
-// TODO: We need a testscript to cover "cue login" with its oauth2 device flow.
-// Perhaps with a small net/http/httptest server to mock the basics of the oauth2 flow?
-//
-// It should also test edge cases like:
-//  * succeed whether or not a keychain is available
-//  * load either plaintext or encrypted files, preferring the encrypted one
-//  * existing login entries are kept when adding a new one
-//  * using the well-known endpoint to locate oauth2 endpoints
-//  * obtaining a new access token when it expires via the refresh token, and store the refreshed one
-//  * asking the user to re-run "cue login" if the access token expires without a refresh token
-//  * registry strings with a path prefix or an insecure option
-//
+// Note that login_test.go covers the oauth2 device flow against a mock server.
 // We will have end-to-end tests which will cover authentication with registry.cue.works,
 // but they will use an existing token stored as a secret to avoid the human device flow in "cue login".
 
@@ -56,9 +46,23 @@ func newLoginCmd(c *Command) *cobra.Command {
 
 Log into a CUE registry via the OAuth 2.0 Device Authorization Grant.
 Without an argument, CUE_REGISTRY is used if it points to a single registry.
+A registry may be suffixed with +insecure to use plain HTTP, as in CUE_REGISTRY.
+
+The OAuth 2.0 endpoints of a registry are discovered via its authorization
+server metadata at /.well-known/oauth-authorization-server, falling back to
+the endpoints used by registry.cue.works.
 
 Once the authorization is successful, a token is stored in a cue/logins.json file
 inside your user's config directory, such as $XDG_CONFIG_HOME or %AppData%.
+If a credential store is available, such as the macOS keychain or the
+Secret Service on Linux, the file is encrypted with a key kept in that
+store and named cue/logins.json.enc instead. Set CUE_NO_KEYCHAIN to a
+non-empty value to disable the use of credential stores. Encrypted logins
+that cannot be decrypted are not used, and an error is only reported when
+a registry requires authentication.
+
+Access tokens are refreshed automatically when they expire,
+and the refreshed tokens are stored in the same way.
 `,
 		Args: cobra.MaximumNArgs(1),
 		RunE: mkRunE(c, func(cmd *Command, args []string) error {
@@ -84,8 +88,12 @@ inside your user's config directory, such as $XDG_CONFIG_HOME or %AppData%.
 			if strings.Contains(registry, ",") {
 				return fmt.Errorf("need a single CUE registry to log into")
 			}
+			host, insecure := parseLoginRegistry(registry)
 
-			oauthCfg := registryOAuthConfig(registry)
+			oauthCfg, err := discoverOAuthConfig(ctx, http.DefaultClient, host, insecure)
+			if err != nil {
+				return err
+			}
 
 			resp, err := oauthCfg.DeviceAuth(ctx)
 			if err != nil {
@@ -93,9 +101,12 @@ inside your user's config directory, such as $XDG_CONFIG_HOME or %AppData%.
 			}
 			// TODO: we could try using $BROWSER or xdg-open here,
 			// falling back to the text instructions below
-			fmt.Printf("Enter the code %s via: %s\n", resp.UserCode, resp.VerificationURI)
-			fmt.Printf("Or just open: %s\n", resp.VerificationURIComplete)
-			fmt.Println()
+			w := cmd.OutOrStdout()
+			fmt.Fprintf(w, "Enter the code %s via: %s\n", resp.UserCode, resp.VerificationURI)
+			if resp.VerificationURIComplete != "" {
+				fmt.Fprintf(w, "Or just open: %s\n", resp.VerificationURIComplete)
+			}
+			fmt.Fprintln(w)
 			tok, err := oauthCfg.DeviceAccessToken(ctx, resp)
 			if err != nil {
 				return fmt.Errorf("cannot obtain the OAuth2 token: %v", err)
@@ -105,32 +116,37 @@ inside your user's config directory, such as $XDG_CONFIG_HOME or %AppData%.
 			if err != nil {
 				return fmt.Errorf("cannot find the path to store CUE registry logins: %v", err)
 			}
-			logins, err := readLogins(loginsPath)
+			login := loginFromToken(tok)
+			login.TokenURL = oauthCfg.Endpoint.TokenURL
+			encrypted, err := updateLogin(loginsPath, host, login)
 			if err != nil {
-				return fmt.Errorf("cannot load CUE registry logins: %v", err)
-			}
-
-			logins.Registries[registry] = loginFromToken(tok)
-
-			if err := writeLogins(loginsPath, logins); err != nil {
 				return fmt.Errorf("cannot store CUE registry logins: %v", err)
 			}
-			fmt.Printf("Login for %s stored in %s\n", registry, loginsPath)
-			// TODO: Once we support encryption, we should print a warning if it's not available.
+			if encrypted {
+				fmt.Fprintf(w, "Login for %s stored in %s.enc\n", host, loginsPath)
+			} else {
+				fmt.Fprintf(w, "Login for %s stored in %s\n", host, loginsPath)
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: no credential store available; the login is stored unencrypted\n")
+			}
 			return nil
 		}),
 	}
 	return cmd
 }
 
+// parseLoginRegistry returns the host of a registry as accepted by
+// CUE_REGISTRY and whether it should be accessed via plain HTTP.
+func parseLoginRegistry(registry string) (host string, insecure bool) {
+	registry, insecure = strings.CutSuffix(registry, "+insecure")
+	// Logins are per host, so drop any repository prefix.
+	host, _, _ = strings.Cut(registry, "/")
+	return host, insecure
+}
+
 func registryOAuthConfig(host string) oauth2.Config {
-	// For now, we use the OAuth endpoints as implemented by registry.cue.works,
-	// but other OCI registries may support the OAuth device flow with different ones.
-	//
-	// TODO: Query /.well-known/oauth-authorization-server to obtain
-	// token_endpoint and device_authorization_endpoint per the Oauth RFCs:
-	// * https://datatracker.ietf.org/doc/html/rfc8414#section-3
-	// * https://datatracker.ietf.org/doc/html/rfc8628#section-4
+	// These are the OAuth endpoints as implemented by registry.cue.works.
+	// Other registries may advertise different endpoints via their
+	// authorization server metadata; see discoverOAuthConfig.
 	return oauth2.Config{
 		Endpoint: oauth2.Endpoint{
 			DeviceAuthURL: "https://" + host + "/login/device/code",
@@ -139,14 +155,51 @@ func registryOAuthConfig(host string) oauth2.Config {
 	}
 }
 
-// TODO: Encrypt the JSON file if the system has a secret store available,
-// such as libsecret on Linux. Such secret stores tend to have low size limits,
-// so rather than store the entire JSON blob there, store an encryption key.
-// There are a number of Go packages which integrate with multiple OS keychains.
+// discoverOAuthConfig obtains the OAuth2 endpoints of a registry from its
+// authorization server metadata, per the OAuth RFCs:
+//   - https://datatracker.ietf.org/doc/html/rfc8414#section-3
+//   - https://datatracker.ietf.org/doc/html/rfc8628#section-4
 //
-// The encrypted form of logins.json can be logins.json.enc, for example.
-// If a user has an existing logins.json file and encryption is available,
-// we should replace the file with logins.json.enc transparently.
+// If the registry does not provide such metadata, the endpoints
+// of registry.cue.works are used.
+func discoverOAuthConfig(ctx context.Context, client *http.Client, host string, insecure bool) (oauth2.Config, error) {
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+	cfg := registryOAuthConfig(host)
+	if insecure {
+		cfg.Endpoint.DeviceAuthURL = "http://" + strings.TrimPrefix(cfg.Endpoint.DeviceAuthURL, "https://")
+		cfg.Endpoint.TokenURL = "http://" + strings.TrimPrefix(cfg.Endpoint.TokenURL, "https://")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", scheme+"://"+host+"/.well-known/oauth-authorization-server", nil)
+	if err != nil {
+		return cfg, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return cfg, fmt.Errorf("cannot discover the OAuth2 endpoints of %s: %v", host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		registryLogger.Debug("no OAuth2 authorization server metadata; using default endpoints", "host", host, "status", resp.StatusCode)
+		return cfg, nil
+	}
+	var meta struct {
+		TokenEndpoint               string `json:"token_endpoint"`
+		DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return cfg, fmt.Errorf("invalid OAuth2 authorization server metadata for %s: %v", host, err)
+	}
+	if meta.TokenEndpoint == "" || meta.DeviceAuthorizationEndpoint == "" {
+		return cfg, fmt.Errorf("registry %s does not support the OAuth2 device flow", host)
+	}
+	cfg.Endpoint.TokenURL = meta.TokenEndpoint
+	cfg.Endpoint.DeviceAuthURL = meta.DeviceAuthorizationEndpoint
+	return cfg, nil
+}
 
 // TODO: When running "cue login", try to prevent overwriting concurrent changes
 // when writing to the file on disk. For example, grab a lock, or check if the size
@@ -160,16 +213,45 @@ func findLoginsPath() (string, error) {
 	return filepath.Join(configDir, "cue", "logins.json"), nil
 }
 
+// A loginsUnavailableError is returned by readLogins if the logins are
+// encrypted and cannot be decrypted, for instance because no credential
+// store is available. Registries that do not require authentication can
+// still be used without them.
+type loginsUnavailableError struct {
+	path string
+	err  error
+}
+
+func (e *loginsUnavailableError) Error() string {
+	return fmt.Sprintf("cannot decrypt %s: %v", e.path, e.err)
+}
+
+func (e *loginsUnavailableError) Unwrap() error { return e.err }
+
+// readLogins reads the logins stored at path, or the encrypted logins
+// stored at path+".enc" if that file exists. If the encrypted logins
+// cannot be decrypted, it returns no logins along with a
+// *loginsUnavailableError.
 func readLogins(path string) (*cueLogins, error) {
 	logins := &cueLogins{
 		// Initialize the map so we can insert entries.
 		Registries: map[string]cueRegistryLogin{},
 	}
-	body, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return logins, nil
+	body, err := os.ReadFile(path + ".enc")
+	switch {
+	case err == nil:
+		if body, err = decryptLoginsFile(body); err != nil {
+			return logins, &loginsUnavailableError{path: path + ".enc", err: err}
 		}
+	case errors.Is(err, fs.ErrNotExist):
+		body, err = os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return logins, nil
+			}
+			return nil, err
+		}
+	default:
 		return nil, err
 	}
 	if err := json.Unmarshal(body, logins); err != nil {
@@ -178,22 +260,71 @@ func readLogins(path string) (*cueLogins, error) {
 	return logins, nil
 }
 
-func writeLogins(path string, logins *cueLogins) error {
+// decryptLoginsFile decrypts the contents of an encrypted logins file with
+// the key kept in the system keychain.
+func decryptLoginsFile(data []byte) ([]byte, error) {
+	kc := systemKeychain()
+	if kc == nil {
+		return nil, fmt.Errorf("no credential store available")
+	}
+	key, err := loginsKey(kc, false)
+	if errors.Is(err, errSecretNotFound) {
+		return nil, fmt.Errorf("no key for the logins in the credential store")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decryptLogins(key, data)
+}
+
+// writeLogins stores logins at path. If a credential store is available,
+// the logins are encrypted and stored at path+".enc" instead, replacing
+// any unencrypted file. It reports whether the logins were encrypted.
+func writeLogins(path string, logins *cueLogins) (encrypted bool, err error) {
 	// Indenting and a trailing newline are not necessary, but nicer to humans.
 	body, err := json.MarshalIndent(logins, "", "\t")
 	if err != nil {
-		return err
+		return false, err
 	}
 	body = append(body, '\n')
 
 	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
-		return err
+		return false, err
+	}
+	if kc := systemKeychain(); kc != nil {
+		key, err := loginsKey(kc, true)
+		if err != nil {
+			registryLogger.Warn("cannot use credential store; storing logins unencrypted", "err", err)
+		} else {
+			data, err := encryptLogins(key, body)
+			if err != nil {
+				return false, err
+			}
+			if err := os.WriteFile(path+".enc", data, 0o600); err != nil {
+				return false, err
+			}
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return true, err
+			}
+			return true, nil
+		}
 	}
 	// Discourage other users from reading this file.
 	if err := os.WriteFile(path, body, 0o600); err != nil {
-		return err
+		return false, err
+	}
+	return false, nil
+}
+
+// updateLogin stores the login for the given registry host, keeping
+// the logins for other registries.
+func updateLogin(path, host string, login cueRegistryLogin) (encrypted bool, err error) {
+	logins, err := readLogins(path)
+	if err != nil {
+		return false, err
 	}
-	return nil
+	logins.Registries[host] = login
+	return writeLogins(path, logins)
 }
 
 type cueLogins struct {
@@ -217,6 +348,11 @@ type cueRegistryLogin struct {
 	RefreshToken string `json:"refresh_token,omitempty"`
 
 	Expiry *time.Time `json:"expiry,omitempty"`
+
+	// TokenURL is the OAuth2 token endpoint used to refresh the access
+	// token, as discovered by "cue login". If empty, the endpoint used by
+	// registry.cue.works is assumed.
+	TokenURL string `json:"token_url,omitempty"`
 }
 
 func loginFromToken(tok *oauth2.Token) cueRegistryLogin {
@@ -0,0 +1,367 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cuelabs.dev/go/oci/ociregistry/ociauth"
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/logging"
+)
+
+// fakeOAuthServer implements the parts of an OAuth2 authorization server
+// used by "cue login", as well as a registry endpoint that reports the
+// access token it was called with.
+type fakeOAuthServer struct {
+	*httptest.Server
+	wellKnown bool
+	tokens    atomic.Int32
+}
+
+func newFakeOAuthServer(t *testing.T, wellKnown bool) *fakeOAuthServer {
+	s := &fakeOAuthServer{wellKnown: wellKnown}
+	tokenPath, devicePath := "/login/oauth/token", "/login/device/code"
+	if wellKnown {
+		tokenPath, devicePath = "/oauth/token", "/oauth/device"
+	}
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		if !s.wellKnown {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, map[string]any{
+			"issuer":                        s.URL,
+			"token_endpoint":                s.URL + tokenPath,
+			"device_authorization_endpoint": s.URL + devicePath,
+		})
+	})
+	mux.HandleFunc(devicePath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"device_code":      "device-code",
+			"user_code":        "USER-CODE",
+			"verification_uri": s.URL + "/device",
+			"interval":         1,
+			"expires_in":       60,
+		})
+	})
+	mux.HandleFunc(tokenPath, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.Form.Get("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			qt.Check(t, qt.Equals(r.Form.Get("device_code"), "device-code"))
+		case "refresh_token":
+			qt.Check(t, qt.Equals(r.Form.Get("refresh_token"), "refresh-token"))
+		default:
+			http.Error(w, "unexpected grant type", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]any{
+			"access_token":  fmt.Sprintf("access-token-%d", s.tokens.Add(1)),
+			"token_type":    "Bearer",
+			"refresh_token": "refresh-token",
+			"expires_in":    3600,
+		})
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeOAuthServer) host() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// fakeKeychain is an in-memory keychain.
+type fakeKeychain map[string]string
+
+func (k fakeKeychain) get(service, account string) (string, error) {
+	s, ok := k[service+"/"+account]
+	if !ok {
+		return "", errSecretNotFound
+	}
+	return s, nil
+}
+
+func (k fakeKeychain) set(service, account, secret string) error {
+	k[service+"/"+account] = secret
+	return nil
+}
+
+// setupLoginEnv points the user's config directory to a temporary
+// directory and sets the keychain to kc, returning the logins path.
+func setupLoginEnv(t *testing.T, kc keychain) string {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	t.Setenv("CUE_REGISTRY", "")
+	old := systemKeychain
+	systemKeychain = func() keychain {
+		if kc == nil {
+			return nil
+		}
+		return kc
+	}
+	t.Cleanup(func() { systemKeychain = old })
+	path, err := findLoginsPath()
+	qt.Assert(t, qt.IsNil(err))
+	return path
+}
+
+func runLogin(t *testing.T, registry string) string {
+	cmd, err := New([]string{"login", registry})
+	qt.Assert(t, qt.IsNil(err))
+	var buf bytes.Buffer
+	cmd.SetOutput(&buf)
+	cmd.root.SetErr(&buf)
+	err = cmd.Run(context.Background())
+	qt.Assert(t, qt.IsNil(err), qt.Commentf("output: %s", buf.String()))
+	return buf.String()
+}
+
+func TestLogin(t *testing.T) {
+	for _, wellKnown := range []bool{true, false} {
+		t.Run(fmt.Sprintf("wellKnown=%v", wellKnown), func(t *testing.T) {
+			srv := newFakeOAuthServer(t, wellKnown)
+			kc := fakeKeychain{}
+			path := setupLoginEnv(t, kc)
+
+			// Existing logins for other registries must be kept.
+			_, err := updateLogin(path, "other.example", cueRegistryLogin{AccessToken: "other"})
+			qt.Assert(t, qt.IsNil(err))
+
+			out := runLogin(t, srv.host()+"+insecure")
+			qt.Assert(t, qt.StringContains(out, "Enter the code USER-CODE via: "+srv.URL+"/device"))
+			qt.Assert(t, qt.StringContains(out, "stored in "+path+".enc"))
+
+			// The logins are encrypted with a key kept in the keychain.
+			_, err = os.Stat(path)
+			qt.Assert(t, qt.ErrorIs(err, os.ErrNotExist))
+			data, err := os.ReadFile(path + ".enc")
+			qt.Assert(t, qt.IsNil(err))
+			qt.Assert(t, qt.IsFalse(bytes.Contains(data, []byte("access-token"))))
+			qt.Assert(t, qt.HasLen(kc, 1))
+
+			logins, err := readLogins(path)
+			qt.Assert(t, qt.IsNil(err))
+			qt.Assert(t, qt.Equals(logins.Registries["other.example"].AccessToken, "other"))
+			login := logins.Registries[srv.host()]
+			qt.Assert(t, qt.Equals(login.AccessToken, "access-token-1"))
+			qt.Assert(t, qt.Equals(login.RefreshToken, "refresh-token"))
+			if wellKnown {
+				qt.Assert(t, qt.Equals(login.TokenURL, srv.URL+"/oauth/token"))
+			} else {
+				qt.Assert(t, qt.Equals(login.TokenURL, srv.URL+"/login/oauth/token"))
+			}
+		})
+	}
+}
+
+func TestLoginWithoutKeychain(t *testing.T) {
+	srv := newFakeOAuthServer(t, true)
+	path := setupLoginEnv(t, nil)
+
+	out := runLogin(t, srv.host()+"+insecure")
+	qt.Assert(t, qt.StringContains(out, "stored in "+path+"\n"))
+	qt.Assert(t, qt.StringContains(out, "warning: no credential store available"))
+
+	data, err := os.ReadFile(path)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.StringContains(string(data), `"access_token": "access-token-1"`))
+}
+
+func TestLoginRefresh(t *testing.T) {
+	srv := newFakeOAuthServer(t, true)
+	kc := fakeKeychain{}
+	path := setupLoginEnv(t, kc)
+
+	expired := time.Now().Add(-time.Hour)
+	logins := &cueLogins{Registries: map[string]cueRegistryLogin{
+		srv.host(): {
+			AccessToken:  "expired",
+			TokenType:    "Bearer",
+			RefreshToken: "refresh-token",
+			Expiry:       &expired,
+			TokenURL:     srv.URL + "/oauth/token",
+		},
+	}}
+	// Start with an unencrypted file, which is migrated when the
+	// refreshed token is stored.
+	os.MkdirAll(filepath.Dir(path), 0o777)
+	data, _ := json.Marshal(logins)
+	qt.Assert(t, qt.IsNil(os.WriteFile(path, data, 0o600)))
+	logins, err := readLogins(path)
+	qt.Assert(t, qt.IsNil(err))
+
+	a := &cueLoginsAuthorizer{
+		logins:        logins,
+		loginsPath:    path,
+		cachedClients: map[string]*http.Client{},
+	}
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", srv.URL+"/v2/", nil)
+		resp, err := a.DoRequest(req, ociauth.Scope{})
+		qt.Assert(t, qt.IsNil(err))
+		body := new(bytes.Buffer)
+		body.ReadFrom(resp.Body)
+		resp.Body.Close()
+		// The token is only refreshed once.
+		qt.Assert(t, qt.Equals(body.String(), "Bearer access-token-1"))
+	}
+
+	stored, err := readLogins(path)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(stored.Registries[srv.host()].AccessToken, "access-token-1"))
+	qt.Assert(t, qt.Equals(stored.Registries[srv.host()].TokenURL, srv.URL+"/oauth/token"))
+	_, err = os.Stat(path + ".enc")
+	qt.Assert(t, qt.IsNil(err))
+}
+
+func TestLoginRefreshNotStored(t *testing.T) {
+	srv := newFakeOAuthServer(t, true)
+	setupLoginEnv(t, nil)
+
+	// The logins cannot be stored below a regular file.
+	file := filepath.Join(t.TempDir(), "file")
+	qt.Assert(t, qt.IsNil(os.WriteFile(file, nil, 0o666)))
+
+	var logs bytes.Buffer
+	prev := logging.Handler()
+	logging.SetHandler(logging.LevelFilter(slog.NewTextHandler(&logs, nil), slog.LevelWarn, nil))
+	t.Cleanup(func() { logging.SetHandler(prev) })
+
+	expired := time.Now().Add(-time.Hour)
+	a := &cueLoginsAuthorizer{
+		logins: &cueLogins{Registries: map[string]cueRegistryLogin{
+			srv.host(): {
+				AccessToken:  "expired",
+				TokenType:    "Bearer",
+				RefreshToken: "refresh-token",
+				Expiry:       &expired,
+				TokenURL:     srv.URL + "/oauth/token",
+			},
+		}},
+		loginsPath:    filepath.Join(file, "logins.json"),
+		cachedClients: map[string]*http.Client{},
+	}
+	req, _ := http.NewRequest("GET", srv.URL+"/v2/", nil)
+	resp, err := a.DoRequest(req, ociauth.Scope{})
+	qt.Assert(t, qt.IsNil(err))
+	resp.Body.Close()
+	qt.Assert(t, qt.StringContains(logs.String(), "level=WARN msg=\"cannot store the refreshed login for "+srv.host()+"; run 'cue login "+srv.host()+"' if later commands fail to authenticate\""))
+}
+
+func TestLoginExpired(t *testing.T) {
+	srv := newFakeOAuthServer(t, true)
+	path := setupLoginEnv(t, nil)
+
+	expired := time.Now().Add(-time.Hour)
+	a := &cueLoginsAuthorizer{
+		logins: &cueLogins{Registries: map[string]cueRegistryLogin{
+			srv.host(): {AccessToken: "expired", Expiry: &expired},
+		}},
+		loginsPath:    path,
+		cachedClients: map[string]*http.Client{},
+	}
+	req, _ := http.NewRequest("GET", srv.URL+"/v2/", nil)
+	_, err := a.DoRequest(req, ociauth.Scope{})
+	qt.Assert(t, qt.ErrorMatches(err, `.*the login for .* has expired; run 'cue login .*' again`))
+}
+
+// noAuthorizer sends requests without authorization.
+type noAuthorizer struct{}
+
+func (noAuthorizer) DoRequest(req *http.Request, requiredScope ociauth.Scope) (*http.Response, error) {
+	return http.DefaultClient.Do(req)
+}
+
+func TestLoginsUnavailable(t *testing.T) {
+	kc := fakeKeychain{}
+	path := setupLoginEnv(t, kc)
+	encrypted, err := updateLogin(path, "other.example", cueRegistryLogin{AccessToken: "other"})
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsTrue(encrypted))
+
+	// Without a credential store, the encrypted logins cannot be read.
+	setupLoginEnv(t, nil)
+	logins, err := readLogins(path)
+	var unavailable *loginsUnavailableError
+	qt.Assert(t, qt.ErrorAs(err, &unavailable))
+	qt.Assert(t, qt.ErrorMatches(err, `cannot decrypt .*logins.json.enc: no credential store available`))
+	qt.Assert(t, qt.HasLen(logins.Registries, 0))
+
+	// Registries can still be used as long as they do not require
+	// authentication.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/private" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	a := &cueLoginsAuthorizer{
+		logins:        logins,
+		loginsPath:    path,
+		loginsErr:     err,
+		cachedClients: map[string]*http.Client{},
+		next:          noAuthorizer{},
+	}
+	req, _ := http.NewRequest("GET", srv.URL+"/public", nil)
+	resp, err := a.DoRequest(req, ociauth.Scope{})
+	qt.Assert(t, qt.IsNil(err))
+	resp.Body.Close()
+	qt.Assert(t, qt.Equals(resp.StatusCode, http.StatusOK))
+
+	req, _ = http.NewRequest("GET", srv.URL+"/private", nil)
+	_, err = a.DoRequest(req, ociauth.Scope{})
+	qt.Assert(t, qt.ErrorMatches(err, `.* requires authentication, but the CUE registry logins cannot be used: cannot decrypt .*: no credential store available`))
+}
+
+func TestParseLoginRegistry(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		host     string
+		insecure bool
+	}{
+		{"registry.cue.works", "registry.cue.works", false},
+		{"localhost:5000+insecure", "localhost:5000", true},
+		{"example.com/some/prefix", "example.com", false},
+	} {
+		host, insecure := parseLoginRegistry(tc.in)
+		qt.Check(t, qt.Equals(host, tc.host))
+		qt.Check(t, qt.Equals(insecure, tc.insecure))
+	}
+}
@@ -44,9 +44,16 @@ func newModCmd(c *Command) *cobra.Command {
 		}),
 	}
 
+	cmd.AddCommand(newModEditCmd(c))
+	cmd.AddCommand(newModInfoCmd(c))
 	cmd.AddCommand(newModInitCmd(c))
 	cmd.AddCommand(newModUploadCmd(c))
 	cmd.AddCommand(newModTidyCmd(c))
+	cmd.AddCommand(newModUpgradeCmd(c))
+	cmd.AddCommand(newModVendorCmd(c))
+	cmd.AddCommand(newModVerifyCmd(c))
+	cmd.AddCommand(newModWatchCmd(c))
+	cmd.AddCommand(newModWhyCmd(c))
 	return cmd
 }
 
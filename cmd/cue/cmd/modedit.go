@@ -0,0 +1,443 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+func newModEditCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		// TODO: this command is still experimental, don't show it in
+		// the documentation just yet.
+		Hidden: true,
+
+		Use:   "edit [flags]",
+		Short: "edit cue.mod/module.cue",
+		Long: `WARNING: THIS COMMAND IS EXPERIMENTAL.
+
+Edit provides a command-line interface for editing cue.mod/module.cue,
+for use by tools and scripts. Comments and the layout of the parts of
+the file that are not edited are preserved.
+
+The edits are specified by the following flags, which may be given more
+than once where noted. They are applied in the order listed here.
+
+	--module path
+		set the module path
+	--language-version version
+		set the language version
+	--drop-require path@vN (repeated)
+		remove the dependency on the given major version of a module
+	--require path@version (repeated)
+		add a dependency on the given module version, or update the
+		version of an existing dependency with the same major version
+	--drop-replace path@version (repeated)
+		remove the replacement of the given module version, or of all
+		versions of the module if only the major version is given
+	--replace old@version=new (repeated)
+		replace the given module version, or all versions of the module
+		if only the major version is given, by new, which is either a
+		module version new@version or a local directory starting with
+		./, ../, or /
+	--drop-retract version (repeated)
+		remove the retraction of the given version or [low,high] range
+	--retract version (repeated)
+		retract the given version or inclusive [low,high] range of
+		versions of the current module
+
+The result is checked against the module file schema before it is
+written. Note that retract entries are not yet supported by the schema,
+so edits that add them are rejected.
+
+For example:
+
+	cue mod edit --require example.com/foo@v1.2.3 --language-version v0.8.0
+`,
+		RunE: mkRunE(c, runModEdit),
+		Args: cobra.ExactArgs(0),
+	}
+	cmd.Flags().String(string(flagModule), "", "set the module path")
+	cmd.Flags().String(string(flagLanguageVersion), "", "set the language version")
+	cmd.Flags().StringArray(string(flagDropRequire), nil, "remove a dependency")
+	cmd.Flags().StringArray(string(flagRequire), nil, "add or update a dependency")
+	cmd.Flags().StringArray(string(flagDropReplace), nil, "remove a replacement")
+	cmd.Flags().StringArray(string(flagReplace), nil, "add a replacement")
+	cmd.Flags().StringArray(string(flagDropRetract), nil, "remove a retraction")
+	cmd.Flags().StringArray(string(flagRetract), nil, "add a retraction")
+	return cmd
+}
+
+func runModEdit(cmd *Command, args []string) error {
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		return err
+	}
+	modPath := filepath.Join(modRoot, "cue.mod", "module.cue")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return err
+	}
+	f, err := parser.ParseFile(modPath, data, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	if s := flagModule.String(cmd); s != "" {
+		if err := module.CheckPath(s); err != nil {
+			return fmt.Errorf("invalid --%s: %v", flagModule, err)
+		}
+		setField(&f.Decls, "module", ast.NewString(s))
+	}
+	if s := flagLanguageVersion.String(cmd); s != "" {
+		if !semver.IsValid(s) {
+			return fmt.Errorf("invalid --%s: %q is not a valid semantic version", flagLanguageVersion, s)
+		}
+		setField(structDecls(&f.Decls, "language"), "version", ast.NewString(s))
+	}
+	for _, s := range flagDropRequire.StringArray(cmd) {
+		if _, _, ok := module.SplitPathVersion(s); !ok {
+			return fmt.Errorf("invalid --%s: %q must be of the form path@vN", flagDropRequire, s)
+		}
+		if deps := lookupStruct(f.Decls, "deps"); deps != nil {
+			removeField(&deps.Elts, s)
+		}
+	}
+	for _, s := range flagRequire.StringArray(cmd) {
+		v, err := module.ParseVersion(s)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %v", flagRequire, err)
+		}
+		dep := structDecls(structDecls(&f.Decls, "deps"), v.Path())
+		setField(dep, "v", ast.NewString(v.Version()))
+	}
+	for _, s := range flagDropReplace.StringArray(cmd) {
+		path, vers, err := parseReplaced(s)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %v", flagDropReplace, err)
+		}
+		dep := lookupStruct(f.Decls, "deps", path)
+		switch {
+		case dep == nil:
+		case vers == "":
+			removeField(&dep.Elts, "replaceAll")
+		default:
+			if replace := lookupStruct(dep.Elts, "replace"); replace != nil {
+				removeField(&replace.Elts, vers)
+			}
+		}
+	}
+	for _, s := range flagReplace.StringArray(cmd) {
+		old, new, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("invalid --%s: %q must be of the form old@version=new", flagReplace, s)
+		}
+		path, vers, err := parseReplaced(old)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %v", flagReplace, err)
+		}
+		repl, err := replacement(new)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %v", flagReplace, err)
+		}
+		if lookupStruct(f.Decls, "deps", path) == nil {
+			return fmt.Errorf("invalid --%s: %s is not a dependency; add it with --%s first", flagReplace, path, flagRequire)
+		}
+		dep := structDecls(structDecls(&f.Decls, "deps"), path)
+		if vers == "" {
+			setField(dep, "replaceAll", repl)
+		} else {
+			setField(structDecls(dep, "replace"), vers, repl)
+		}
+	}
+	for _, s := range flagDropRetract.StringArray(cmd) {
+		x, err := retraction(s)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %v", flagDropRetract, err)
+		}
+		if list := listField(&f.Decls, "retract"); list != nil {
+			list.Elts = removeRetraction(list.Elts, x)
+		}
+	}
+	for _, s := range flagRetract.StringArray(cmd) {
+		x, err := retraction(s)
+		if err != nil {
+			return fmt.Errorf("invalid --%s: %v", flagRetract, err)
+		}
+		list := listField(&f.Decls, "retract")
+		if list == nil {
+			list = ast.NewList()
+			setField(&f.Decls, "retract", list)
+		}
+		list.Elts = append(removeRetraction(list.Elts, x), x)
+	}
+
+	tidyModFile(f)
+	data, err = format.Node(f)
+	if err != nil {
+		return err
+	}
+	if _, err := modfile.ParseNonStrict(data, modPath); err != nil {
+		if len(flagRetract.StringArray(cmd)) > 0 {
+			return fmt.Errorf("edited module file is invalid; retract entries are not yet supported: %v", err)
+		}
+		return fmt.Errorf("edited module file is invalid: %v", err)
+	}
+	return os.WriteFile(modPath, data, 0o666)
+}
+
+// parseReplaced parses the module version to be replaced, which is either
+// a canonical version or just a major version, and returns the module path
+// with its major version, and the version if it is canonical.
+func parseReplaced(s string) (path, vers string, err error) {
+	_, vers, ok := module.SplitPathVersion(s)
+	if !ok {
+		return "", "", fmt.Errorf("%q must be of the form path@version", s)
+	}
+	if semver.Major(vers) == vers {
+		return s, "", nil
+	}
+	v, err := module.ParseVersion(s)
+	if err != nil {
+		return "", "", err
+	}
+	return v.Path(), v.Version(), nil
+}
+
+// replacement returns the replacement for a module, which is either a local
+// directory or a module version.
+func replacement(s string) (ast.Expr, error) {
+	if strings.HasPrefix(s, "./") || strings.HasPrefix(s, "../") || strings.HasPrefix(s, "/") {
+		return ast.NewString(s), nil
+	}
+	v, err := module.ParseVersion(s)
+	if err != nil {
+		return nil, err
+	}
+	x := ast.NewStruct(ast.NewIdent("m"), ast.NewString(v.Path()), ast.NewIdent("v"), ast.NewString(v.Version()))
+	for _, f := range x.Elts {
+		ast.SetRelPos(f, token.Newline)
+	}
+	return x, nil
+}
+
+// retraction returns the retract entry for s, which is either a version or
+// a range of the form [low,high].
+func retraction(s string) (ast.Expr, error) {
+	if !strings.HasPrefix(s, "[") {
+		if semver.Canonical(s) != s {
+			return nil, fmt.Errorf("%q is not a canonical semantic version", s)
+		}
+		return ast.NewString(s), nil
+	}
+	low, high, ok := strings.Cut(strings.TrimSuffix(s[1:], "]"), ",")
+	low, high = strings.TrimSpace(low), strings.TrimSpace(high)
+	if !ok || !strings.HasSuffix(s, "]") ||
+		semver.Canonical(low) != low || semver.Canonical(high) != high {
+		return nil, fmt.Errorf("%q must be of the form [low,high] with canonical semantic versions", s)
+	}
+	return ast.NewStruct("from", ast.NewString(low), "to", ast.NewString(high)), nil
+}
+
+// removeRetraction removes the entries of list that are equal to x, which
+// was created by retraction.
+func removeRetraction(list []ast.Expr, x ast.Expr) []ast.Expr {
+	key := retractionKey(x)
+	a := list[:0]
+	for _, e := range list {
+		if retractionKey(e) != key {
+			a = append(a, e)
+		}
+	}
+	return a
+}
+
+func retractionKey(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		s, _ := literal.Unquote(x.Value)
+		return s
+	case *ast.StructLit:
+		return stringField(x.Elts, "from") + "," + stringField(x.Elts, "to")
+	}
+	return ""
+}
+
+func stringField(decls []ast.Decl, name string) string {
+	if f := lookupField(decls, name); f != nil {
+		if x, ok := f.Value.(*ast.BasicLit); ok {
+			s, _ := literal.Unquote(x.Value)
+			return s
+		}
+	}
+	return ""
+}
+
+// lookupField returns the regular field with the given name in decls, or
+// nil if there is no such field.
+func lookupField(decls []ast.Decl, name string) *ast.Field {
+	for _, d := range decls {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if s, _, err := ast.LabelName(f.Label); err == nil && s == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// setField sets the value of the field with the given name in decls,
+// adding the field if it does not exist.
+func setField(decls *[]ast.Decl, name string, value ast.Expr) {
+	if f := lookupField(*decls, name); f != nil {
+		ast.SetPos(value, f.Value.Pos())
+		ast.SetComments(value, ast.Comments(f.Value))
+		f.Value = value
+		return
+	}
+	f := &ast.Field{Label: fieldLabel(name), Value: value}
+	ast.SetRelPos(f, token.Newline)
+	*decls = append(*decls, f)
+}
+
+// removeField removes the field with the given name from decls, if it
+// exists.
+func removeField(decls *[]ast.Decl, name string) {
+	if f := lookupField(*decls, name); f != nil {
+		a := (*decls)[:0]
+		for _, d := range *decls {
+			if d != f {
+				a = append(a, d)
+			}
+		}
+		*decls = a
+	}
+}
+
+// lookupStruct returns the struct value at the given path of field names
+// in decls, or nil if there is no such struct.
+func lookupStruct(decls []ast.Decl, path ...string) *ast.StructLit {
+	var s *ast.StructLit
+	for _, name := range path {
+		f := lookupField(decls, name)
+		if f == nil {
+			return nil
+		}
+		x, ok := f.Value.(*ast.StructLit)
+		if !ok {
+			return nil
+		}
+		s, decls = x, x.Elts
+	}
+	return s
+}
+
+// structDecls returns the declarations of the struct value of the field
+// with the given name in decls, adding the field if it does not exist.
+func structDecls(decls *[]ast.Decl, name string) *[]ast.Decl {
+	f := lookupField(*decls, name)
+	if f == nil {
+		f = &ast.Field{Label: fieldLabel(name), Value: ast.NewStruct()}
+		ast.SetRelPos(f, token.Newline)
+		*decls = append(*decls, f)
+	}
+	s, ok := f.Value.(*ast.StructLit)
+	if !ok {
+		s = ast.NewStruct()
+		f.Value = s
+	}
+	return &s.Elts
+}
+
+// listField returns the list value of the field with the given name in
+// decls, or nil if there is no such field.
+func listField(decls *[]ast.Decl, name string) *ast.ListLit {
+	if f := lookupField(*decls, name); f != nil {
+		if x, ok := f.Value.(*ast.ListLit); ok {
+			return x
+		}
+	}
+	return nil
+}
+
+func fieldLabel(name string) ast.Label {
+	if ast.IsValidIdent(name) {
+		return ast.NewIdent(name)
+	}
+	return ast.NewString(name)
+}
+
+// tidyModFile removes the parts of a module file left empty by edits and
+// adds braces to structs that were written without them, but that now have
+// more than one field.
+func tidyModFile(f *ast.File) {
+	if deps := lookupStruct(f.Decls, "deps"); deps != nil {
+		for _, d := range deps.Elts {
+			if dep, ok := d.(*ast.Field); ok {
+				if x, ok := dep.Value.(*ast.StructLit); ok {
+					removeEmpty(&x.Elts, "replace")
+				}
+			}
+		}
+	}
+	removeEmpty(&f.Decls, "deps")
+	removeEmpty(&f.Decls, "retract")
+
+	ast.Walk(f, func(n ast.Node) bool {
+		if s, ok := n.(*ast.StructLit); ok && !s.Lbrace.IsValid() && len(s.Elts) > 1 {
+			s.Lbrace = token.Blank.Pos()
+			s.Rbrace = token.Newline.Pos()
+		}
+		return true
+	}, nil)
+}
+
+// removeEmpty removes the field with the given name from decls if its value
+// is an empty struct or list.
+func removeEmpty(decls *[]ast.Decl, name string) {
+	f := lookupField(*decls, name)
+	if f == nil {
+		return
+	}
+	switch x := f.Value.(type) {
+	case *ast.StructLit:
+		if len(x.Elts) > 0 {
+			return
+		}
+	case *ast.ListLit:
+		if len(x.Elts) > 0 {
+			return
+		}
+	default:
+		return
+	}
+	removeField(decls, name)
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/internal/mod/modregistry"
+	"cuelang.org/go/internal/mod/module"
+)
+
+func newModInfoCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		// TODO: this command is still experimental, don't show it in
+		// the documentation just yet.
+		Hidden: true,
+
+		Use:   "info <module>@<version>",
+		Short: "show information about a published module",
+		Long: `WARNING: THIS COMMAND IS EXPERIMENTAL.
+
+Info fetches the given version of a module from its registry and prints
+information about it. The version must be a full semantic version, such
+as v1.2.3.
+
+The digest is that of the module zip file, as printed by "cue mod publish".
+If the module was published from a version control checkout, info also
+prints the following:
+
+	vcs           the version control system, such as git
+	vcs.revision  the revision of the commit the module was published from
+	vcs.time      the time of that commit
+	vcs.modified  whether the module had uncommitted changes
+`,
+		RunE: mkRunE(c, runModInfo),
+		Args: cobra.ExactArgs(1),
+	}
+	return cmd
+}
+
+func runModInfo(cmd *Command, args []string) error {
+	mv, err := module.ParseVersion(args[0])
+	if err != nil {
+		return err
+	}
+	reg, err := getRegistry()
+	if err != nil {
+		return err
+	}
+	if reg == nil {
+		return fmt.Errorf("no registry configured")
+	}
+	m, err := modregistry.NewClient(reg).GetModule(context.Background(), mv)
+	if err != nil {
+		return err
+	}
+	meta, err := m.Metadata()
+	if err != nil {
+		return fmt.Errorf("module %v: %v", mv, err)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "module\t%s\n", mv.Path())
+	fmt.Fprintf(w, "version\t%s\n", mv.Version())
+	fmt.Fprintf(w, "digest\t%s\n", m.ZipDigest())
+	if meta != nil {
+		fmt.Fprintf(w, "vcs\t%s\n", meta.VCSType)
+		fmt.Fprintf(w, "vcs.revision\t%s\n", meta.VCSCommit)
+		if !meta.VCSCommitTime.IsZero() {
+			fmt.Fprintf(w, "vcs.time\t%s\n", meta.VCSCommitTime.Format(time.RFC3339))
+		}
+		fmt.Fprintf(w, "vcs.modified\t%t\n", meta.VCSDirty)
+	}
+	return w.Flush()
+}
@@ -16,16 +16,21 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 
+	digest "github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
 
 	"cuelang.org/go/internal/mod/modfile"
 	"cuelang.org/go/internal/mod/modregistry"
 	"cuelang.org/go/internal/mod/module"
 	"cuelang.org/go/internal/mod/modzip"
+	"cuelang.org/go/internal/vcs"
 )
 
 func newModUploadCmd(c *Command) *cobra.Command {
@@ -38,8 +43,28 @@ func newModUploadCmd(c *Command) *cobra.Command {
 		Short: "publish the current module to a registry",
 		Long: `WARNING: THIS COMMAND IS EXPERIMENTAL.
 
-Publish the current module to an OCI registry.
+Publish the current module to an OCI registry and print the digest of the
+published module zip file. Module zip files are reproducible: publishing the
+same files always results in the same digest.
 Also note that this command does no dependency or other checks at the moment.
+
+If the module.cue file has a files section, only the files it includes are
+published. For example, to publish only CUE files and a LICENSE file,
+excluding any files in a testdata directory:
+
+	files: {
+		include: ["*.cue", "LICENSE"]
+		exclude: ["testdata"]
+	}
+
+Patterns use the syntax of Go's path.Match. A pattern matching a directory
+matches all the files within it, and a pattern without a slash is also
+matched against the base name of each file. The cue.mod/module.cue file is
+always published. It is an error for an include pattern to match no files.
+
+If the module is inside a git repository, the revision and time of the
+current commit are recorded in the published module, along with whether the
+module directory has uncommitted changes. Use "cue mod info" to show them.
 `,
 		RunE: mkRunE(c, runModUpload),
 		Args: cobra.ExactArgs(1),
@@ -81,19 +106,82 @@ func runModUpload(cmd *Command, args []string) error {
 	defer os.Remove(zf.Name())
 	defer zf.Close()
 
+	if err := checkModuleFiles(modRoot, mf); err != nil {
+		return err
+	}
 	// TODO verify that all dependencies exist in the registry.
-	if err := modzip.CreateFromDir(zf, mv, modRoot); err != nil {
+	if err := modzip.CreateFromDirFiltered(zf, mv, modRoot, mf.Files.Includes); err != nil {
 		return err
 	}
 	info, err := zf.Stat()
 	if err != nil {
 		return err
 	}
+	// Module zip files are reproducible, so the digest printed below can
+	// be used to check that a published module matches its source.
+	zipDigest, err := digest.FromReader(io.NewSectionReader(zf, 0, info.Size()))
+	if err != nil {
+		return err
+	}
 
+	meta, err := vcsMetadata(context.Background(), modRoot)
+	if err != nil {
+		return err
+	}
 	rclient := modregistry.NewClient(reg)
-	if err := rclient.PutModule(context.Background(), mv, zf, info.Size()); err != nil {
+	if err := rclient.PutModuleWithMetadata(context.Background(), mv, zf, info.Size(), meta); err != nil {
 		return fmt.Errorf("cannot put module: %v", err)
 	}
-	fmt.Printf("published %s\n", mv)
+	fmt.Printf("published %s with digest %s\n", mv, zipDigest)
+	return nil
+}
+
+// vcsMetadata returns the metadata describing the state of the version
+// control system containing the module at modRoot, or nil if there is none.
+func vcsMetadata(ctx context.Context, modRoot string) (*modregistry.Metadata, error) {
+	v, err := vcs.Detect(modRoot)
+	if err != nil {
+		if errors.Is(err, vcs.ErrNoVCS) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	status, err := v.Status(ctx, modRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine VCS status of %s: %v", modRoot, err)
+	}
+	return &modregistry.Metadata{
+		VCSType:       v.Type(),
+		VCSCommit:     status.Revision,
+		VCSCommitTime: status.CommitTime,
+		VCSDirty:      status.Uncommitted,
+	}, nil
+}
+
+// checkModuleFiles checks that each include pattern in the files section
+// of the module file mf matches at least one file in the module.
+func checkModuleFiles(modRoot string, mf *modfile.File) error {
+	if mf.Files == nil || len(mf.Files.Include) == 0 {
+		return nil
+	}
+	cf, err := modzip.CheckDir(modRoot)
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, len(cf.Valid))
+	for _, f := range cf.Valid {
+		rel, err := filepath.Rel(modRoot, f)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	for _, pattern := range mf.Files.Include {
+		if !slices.ContainsFunc(files, func(f string) bool {
+			return modfile.MatchFile(pattern, f)
+		}) {
+			return fmt.Errorf("include pattern %q in %s matches no files", pattern, filepath.Join(modRoot, "cue.mod", "module.cue"))
+		}
+	}
 	return nil
 }
@@ -17,13 +17,21 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/internal/mod/modadvisory"
+	"cuelang.org/go/internal/mod/modfile"
 	"cuelang.org/go/internal/mod/modload"
+	"cuelang.org/go/internal/mod/modreplace"
+	"cuelang.org/go/internal/mod/modsum"
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/tools/cachedir"
 )
 
 func newModTidyCmd(c *Command) *cobra.Command {
@@ -37,6 +45,15 @@ func newModTidyCmd(c *Command) *cobra.Command {
 		Long: `WARNING: THIS COMMAND IS EXPERIMENTAL.
 
 Currently this command must be run in the module's root directory.
+
+When the CUE_ADVISORY_DB environment variable is set, the selected
+versions of the dependencies are checked against the advisory database
+at that URL, and a warning is printed for each version affected by an
+advisory. See "cue help environment".
+
+The checksums of the dependencies are recorded in the cue.mod/sums file,
+against which later downloads of the dependencies are verified. See the
+description of CUE_SUMDB in "cue help environment".
 `,
 		RunE: mkRunE(c, runModTidy),
 		Args: cobra.ExactArgs(0),
@@ -46,7 +63,7 @@ Currently this command must be run in the module's root directory.
 }
 
 func runModTidy(cmd *Command, args []string) error {
-	reg, err := getCachedRegistry()
+	reg, chk, err := getCheckedRegistry()
 	if err != nil {
 		return err
 	}
@@ -58,29 +75,89 @@ func runModTidy(cmd *Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	mf, err := modload.Load(ctx, os.DirFS(modRoot), ".", reg)
+	modPath := filepath.Join(modRoot, "cue.mod", "module.cue")
+	oldData, err := os.ReadFile(modPath)
+	if err != nil {
+		return err
+	}
+	oldFile, err := modfile.ParseNonStrict(oldData, modPath)
+	if err != nil {
+		return err
+	}
+	mf, err := modload.Load(ctx, os.DirFS(modRoot), ".", modreplace.NewRegistry(reg, oldFile, modRoot))
 	if err != nil {
 		return err
 	}
+	if err := checkModuleFiles(modRoot, mf); err != nil {
+		return err
+	}
+	checkAdvisories(ctx, cmd, mf)
 	// TODO check whether it's changed or not.
 	data, err := mf.Format()
 	if err != nil {
 		return fmt.Errorf("internal error: invalid module.cue file generated: %v", err)
 	}
-	modPath := filepath.Join(modRoot, "cue.mod", "module.cue")
-	oldData, err := os.ReadFile(modPath)
-	if err != nil {
-		// Shouldn't happen because modload.Load returns an error
-		// if it can't load the module file.
+	if !bytes.Equal(data, oldData) {
+		if err := os.WriteFile(modPath, data, 0o666); err != nil {
+			return err
+		}
+	}
+	return writeSums(modRoot, mf, chk)
+}
+
+// writeSums updates the cue.mod/sums file of the module rooted at
+// modRoot to hold the checksums of the dependencies of mf recorded by
+// chk. The file is not created if there are no checksums to record.
+func writeSums(modRoot string, mf *modfile.File, chk *modsum.Checker) error {
+	// As in checkAdvisories, the dependency versions are derived
+	// from the Deps field of the unparsed module file.
+	var versions []module.Version
+	for mpath, dep := range mf.Deps {
+		if v, err := module.NewVersion(mpath, dep.Version); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	data := chk.Sums().Filter(versions).Format()
+	sumsPath := filepath.Join(modRoot, "cue.mod", "sums")
+	oldData, err := os.ReadFile(sumsPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return err
 	}
-	if bytes.Equal(data, oldData) {
+	if bytes.Equal(data, oldData) || (len(data) == 0 && err != nil) {
 		return nil
 	}
-	if err := os.WriteFile(modPath, data, 0o666); err != nil {
-		return err
+	return os.WriteFile(sumsPath, data, 0o666)
+}
+
+// checkAdvisories warns about the dependencies of mf that are affected by
+// an advisory in the database configured with CUE_ADVISORY_DB, if any.
+// Failing to consult the database is not an error, as advisories are
+// only informative.
+func checkAdvisories(ctx context.Context, cmd *Command, mf *modfile.File) {
+	dbURL := os.Getenv("CUE_ADVISORY_DB")
+	if dbURL == "" {
+		return
+	}
+	// The module file is not parsed, so its dependency versions are
+	// derived from its Deps field rather than from DepVersions.
+	var versions []module.Version
+	for mpath, dep := range mf.Deps {
+		v, err := module.NewVersion(mpath, dep.Version)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	module.Sort(versions)
+	db := &modadvisory.HTTPDatabase{URL: dbURL}
+	findings, err := modadvisory.Check(ctx, db, versions)
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: cannot check advisories: %v\n", err)
+		return
+	}
+	for _, f := range findings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", f)
 	}
-	return nil
 }
 
 func findModuleRoot() (string, error) {
@@ -105,13 +182,11 @@ func findModuleRoot() (string, error) {
 }
 
 func modCacheDir() (string, error) {
-	if dir := os.Getenv("CUE_MODCACHE"); dir != "" {
-		return dir, nil
-	}
-	sysCacheDir, err := os.UserCacheDir()
+	dir, err := cachedir.Default()
 	if err != nil {
-		return "", fmt.Errorf("cannot determine system cache directory: %v", err)
+		return "", err
 	}
-	// TODO rethink cache namespace as per comments in https://review.gerrithub.io/c/cue-lang/cue/+/1173535/18
-	return filepath.Join(sysCacheDir, "cue"), nil
+	// A failed migration only means that the modules are downloaded again.
+	dir.MigrateModules()
+	return dir.Path(cachedir.Modules), nil
 }
@@ -0,0 +1,391 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/modimports"
+	"cuelang.org/go/internal/mod/modload"
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+func newModUpgradeCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		// TODO: this command is still experimental, don't show it in
+		// the documentation just yet.
+		Hidden: true,
+
+		Use:   "upgrade [--major] <module>[@vN]",
+		Short: "upgrade a module dependency",
+		Long: `WARNING: THIS COMMAND IS EXPERIMENTAL.
+
+Upgrade upgrades a dependency of the current module to its latest version.
+The module is specified by its path, optionally followed by the major
+version of the dependency to upgrade, which is needed when the current
+module depends on more than one major version of the module.
+
+Without --major, the dependency is upgraded to the latest version with the
+same major version. With --major, it is upgraded to the latest version of
+the latest major version available in the registry. In that case, import
+paths in the module that mention the old major version explicitly, such as
+example.com/foo@v1, are rewritten to refer to the new one.
+
+After a major version upgrade, the packages imported from the module are
+compared between the old and the new version, and any changes that may
+break the current module are reported: removed fields and definitions,
+new required fields, and values that no longer accept everything accepted
+by the old version.
+
+Finally, the module's dependencies are tidied as with "cue mod tidy".
+`,
+		RunE: mkRunE(c, runModUpgrade),
+		Args: cobra.ExactArgs(1),
+	}
+	cmd.Flags().Bool(string(flagMajor), false, "upgrade to the latest major version")
+	return cmd
+}
+
+func runModUpgrade(cmd *Command, args []string) error {
+	reg, err := getCachedRegistry()
+	if err != nil {
+		return err
+	}
+	if reg == nil {
+		return fmt.Errorf("no module registry configured")
+	}
+	ctx := context.Background()
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		return err
+	}
+	modPath := filepath.Join(modRoot, "cue.mod", "module.cue")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return err
+	}
+	mf, err := modfile.ParseNonStrict(data, modPath)
+	if err != nil {
+		return err
+	}
+	old, err := findDependency(mf, args[0])
+	if err != nil {
+		return err
+	}
+	oldMajor := semver.Major(old.Version())
+
+	newMajor := oldMajor
+	if flagMajor.Bool(cmd) {
+		newMajor, err = latestMajorVersion(ctx, reg, old.BasePath(), oldMajor)
+		if err != nil {
+			return err
+		}
+	}
+	versions, err := reg.ModuleVersions(ctx, old.BasePath()+"@"+newMajor)
+	if err != nil {
+		return err
+	}
+	latest := modload.LatestVersion(versions)
+	if latest == "" || semver.Compare(latest, old.Version()) <= 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is already at the latest version %s\n", old.Path(), old.Version())
+		return nil
+	}
+	upgraded, err := module.NewVersion(old.BasePath()+"@"+newMajor, latest)
+	if err != nil {
+		return err
+	}
+
+	dep := mf.Deps[old.Path()]
+	delete(mf.Deps, old.Path())
+	mf.Deps[upgraded.Path()] = &modfile.Dep{
+		Version: upgraded.Version(),
+		Default: dep.Default,
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "upgraded %s %s => %s %s\n", old.Path(), old.Version(), upgraded.Path(), upgraded.Version())
+
+	var pkgs []string
+	if newMajor != oldMajor {
+		pkgs, err = rewriteMajorImports(cmd, modRoot, mf, old, newMajor)
+		if err != nil {
+			return err
+		}
+	}
+	data, err = mf.Format()
+	if err != nil {
+		return fmt.Errorf("internal error: invalid module.cue file generated: %v", err)
+	}
+	if err := os.WriteFile(modPath, data, 0o666); err != nil {
+		return err
+	}
+	if err := runModTidy(cmd, nil); err != nil {
+		return err
+	}
+
+	cfg := &load.Config{Registry: reg}
+	for _, pkg := range pkgs {
+		if err := reportAPIChanges(cmd, cfg, pkg, old, upgraded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findDependency returns the dependency of mf selected by arg, which holds
+// a module path optionally followed by a major version.
+func findDependency(mf *modfile.File, arg string) (module.Version, error) {
+	basePath, major, _ := strings.Cut(arg, "@")
+	var found []module.Version
+	for _, v := range mf.DepVersions() {
+		if v.BasePath() == basePath && (major == "" || semver.Major(v.Version()) == major) {
+			found = append(found, v)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return module.Version{}, fmt.Errorf("module %s is not a dependency of the current module", arg)
+	case 1:
+		return found[0], nil
+	}
+	return module.Version{}, fmt.Errorf("ambiguous module %s: specify the major version to upgrade, as in %s", arg, found[0].Path())
+}
+
+// latestMajorVersion returns the latest major version of the module with
+// the given base path that is available in the registry, starting the
+// search from the major version current.
+func latestMajorVersion(ctx context.Context, reg modload.Registry, basePath, current string) (string, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(current, "v"))
+	if err != nil {
+		return "", fmt.Errorf("invalid major version %q", current)
+	}
+	latest := current
+	for {
+		n++
+		major := "v" + strconv.Itoa(n)
+		versions, err := reg.ModuleVersions(ctx, basePath+"@"+major)
+		if err != nil {
+			return "", err
+		}
+		if modload.LatestVersion(versions) == "" {
+			return latest, nil
+		}
+		latest = major
+	}
+}
+
+// rewriteMajorImports rewrites the imports of packages in the module old
+// that explicitly mention its major version to use newMajor instead,
+// and returns the canonical paths, without a major version, of all the
+// packages of the module imported by the main module.
+func rewriteMajorImports(cmd *Command, modRoot string, mf *modfile.File, old module.Version, newMajor string) ([]string, error) {
+	oldMajor := semver.Major(old.Version())
+	// rewrites holds the import path literals to rewrite in each file.
+	rewrites := make(map[string][]*importRewrite)
+	var pkgs []string
+	var files []string
+	var iterErr error
+	modimports.AllModuleFiles(os.DirFS(modRoot), ".")(func(f modimports.ModuleFile, err error) bool {
+		if err != nil {
+			iterErr = fmt.Errorf("cannot read %q: %v", f.FilePath, err)
+			return false
+		}
+		for _, imp := range f.Syntax.Imports {
+			p, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			parts := module.ParseImportPath(p)
+			if !inModule(mf, parts.Path, old) {
+				continue
+			}
+			if parts.Version != "" && parts.Version != oldMajor {
+				continue
+			}
+			pkg := module.ImportPath{
+				Path:              parts.Path,
+				Qualifier:         parts.Qualifier,
+				ExplicitQualifier: parts.ExplicitQualifier,
+			}.Canonical().String()
+			if !slices.Contains(pkgs, pkg) {
+				pkgs = append(pkgs, pkg)
+			}
+			if parts.Version == "" {
+				// The major version is taken from module.cue.
+				continue
+			}
+			parts.Version = newMajor
+			if _, ok := rewrites[f.FilePath]; !ok {
+				files = append(files, f.FilePath)
+			}
+			rewrites[f.FilePath] = append(rewrites[f.FilePath], &importRewrite{
+				offset: imp.Path.Pos().Offset(),
+				old:    imp.Path.Value,
+				new:    strconv.Quote(parts.String()),
+			})
+		}
+		return true
+	})
+	if iterErr != nil {
+		return nil, iterErr
+	}
+	for _, file := range files {
+		if err := rewriteFile(filepath.Join(modRoot, filepath.FromSlash(file)), rewrites[file]); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "updated imports in %s\n", file)
+	}
+	slices.Sort(pkgs)
+	return pkgs, nil
+}
+
+// inModule reports whether the package with the given path, without a
+// major version, is provided by the module m rather than by another
+// dependency of mf with a longer module path.
+func inModule(mf *modfile.File, pkgPath string, m module.Version) bool {
+	if !isPathPrefix(pkgPath, m.BasePath()) {
+		return false
+	}
+	for _, v := range mf.DepVersions() {
+		if len(v.BasePath()) > len(m.BasePath()) && isPathPrefix(pkgPath, v.BasePath()) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPathPrefix reports whether prefix is p or one of its parent paths.
+func isPathPrefix(p, prefix string) bool {
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+type importRewrite struct {
+	offset   int
+	old, new string
+}
+
+// rewriteFile replaces the import path literals in the given file.
+// Only the literals are replaced, so that the file is otherwise
+// left untouched.
+func rewriteFile(file string, rewrites []*importRewrite) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	// Replace from the end of the file so that earlier offsets
+	// remain valid.
+	for i := len(rewrites) - 1; i >= 0; i-- {
+		r := rewrites[i]
+		end := r.offset + len(r.old)
+		if end > len(data) || string(data[r.offset:end]) != r.old {
+			return fmt.Errorf("cannot rewrite import %s in %s", r.old, file)
+		}
+		data = append(data[:r.offset:r.offset], append([]byte(r.new), data[end:]...)...)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, info.Mode())
+}
+
+// reportAPIChanges reports the changes to the package pkg between the
+// module versions old and new that may break packages importing it.
+func reportAPIChanges(cmd *Command, cfg *load.Config, pkg string, old, new module.Version) error {
+	load := func(v module.Version) (cue.Value, error) {
+		parts := module.ParseImportPath(pkg)
+		parts.Version = semver.Major(v.Version())
+		b, err := loadRegistryPackage(parts.String(), v.Version(), cfg)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		val := cmd.ctx.BuildInstance(b)
+		return val, val.Err()
+	}
+	oldVal, err := load(old)
+	if err != nil {
+		return err
+	}
+	newVal, err := load(new)
+	if err != nil {
+		return err
+	}
+	var changes []string
+	compareAPI(cue.Path{}, oldVal, newVal, old.Version(), 0, func(p cue.Path, msg string) {
+		changes = append(changes, fmt.Sprintf("\t%s: %s\n", p, msg))
+	})
+	w := cmd.OutOrStdout()
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "no incompatible changes in %s\n", pkg)
+		return nil
+	}
+	fmt.Fprintf(w, "incompatible changes in %s:\n", pkg)
+	for _, c := range changes {
+		fmt.Fprint(w, c)
+	}
+	return nil
+}
+
+// maxAPIDepth limits the depth to which schemas are compared field by
+// field, which also guards against recursive schemas.
+const maxAPIDepth = 10
+
+// compareAPI calls report for each change from old to new at path p that
+// may break users of old: fields that were removed, new required fields,
+// and values that no longer accept all the values accepted by old.
+func compareAPI(p cue.Path, old, new cue.Value, oldVersion string, depth int, report func(cue.Path, string)) {
+	if old.IncompleteKind() == cue.StructKind && new.IncompleteKind() == cue.StructKind && depth < maxAPIDepth {
+		fieldOpts := []cue.Option{cue.Definitions(true), cue.Optional(true)}
+		iter, err := old.Fields(fieldOpts...)
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			sel := iter.Selector()
+			fp := cue.MakePath(append(p.Selectors(), sel)...)
+			nv := new.LookupPath(cue.MakePath(sel))
+			if !nv.Exists() {
+				report(fp, "removed")
+				continue
+			}
+			compareAPI(fp, iter.Value(), nv, oldVersion, depth+1, report)
+		}
+		iter, err = new.Fields(fieldOpts...)
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			sel := iter.Selector()
+			if iter.FieldType()&cue.RequiredConstraint == 0 || old.LookupPath(cue.MakePath(sel)).Exists() {
+				continue
+			}
+			report(cue.MakePath(append(p.Selectors(), sel)...), "new required field")
+		}
+		return
+	}
+	if err := new.Subsume(old, cue.Schema()); err != nil {
+		report(p, "no longer accepts all values accepted by "+oldVersion)
+	}
+}
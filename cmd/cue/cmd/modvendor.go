@@ -0,0 +1,80 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/modreplace"
+	"cuelang.org/go/internal/mod/modvendor"
+)
+
+func newModVendorCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		// TODO: this command is still experimental, don't show it in
+		// the documentation just yet.
+		Hidden: true,
+
+		Use:   "vendor",
+		Short: "copy dependencies into the cue.mod/vendor directory",
+		Long: `WARNING: THIS COMMAND IS EXPERIMENTAL.
+
+Vendor copies the dependencies of the current module, as listed in its
+cue.mod/module.cue file, into the cue.mod/vendor directory, replacing
+its previous contents. Run "cue mod tidy" first, so that the module file
+lists all the dependencies. Replaced dependencies are copied from their
+replacements.
+
+When cue.mod/vendor/modules.txt exists, the dependencies are loaded from
+the vendor directory rather than from a registry, so that a module with
+its vendor directory can be evaluated without network access. It is an
+error if the vendored modules differ from the dependencies in the module
+file; run "cue mod vendor" again after changing them.
+`,
+		RunE: mkRunE(c, runModVendor),
+		Args: cobra.ExactArgs(0),
+	}
+	return cmd
+}
+
+func runModVendor(cmd *Command, args []string) error {
+	reg, err := getCachedRegistry()
+	if err != nil {
+		return err
+	}
+	if reg == nil {
+		return fmt.Errorf("no module registry configured")
+	}
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		return err
+	}
+	modPath := filepath.Join(modRoot, "cue.mod", "module.cue")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return err
+	}
+	mf, err := modfile.ParseNonStrict(data, modPath)
+	if err != nil {
+		return err
+	}
+	return modvendor.Vendor(context.Background(), modreplace.NewRegistry(reg, mf, modRoot), mf, modRoot)
+}
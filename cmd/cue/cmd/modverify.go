@@ -0,0 +1,113 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/internal/mod/modcache"
+	"cuelang.org/go/internal/mod/modfile"
+)
+
+func newModVerifyCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		// TODO: this command is still experimental, don't show it in
+		// the documentation just yet.
+		Hidden: true,
+
+		Use:   "verify",
+		Short: "verify that cached dependencies have not been modified",
+		Long: `WARNING: THIS COMMAND IS EXPERIMENTAL.
+
+Verify checks that the dependencies of the current module, as listed in
+its cue.mod/module.cue file, have not been modified since they were
+downloaded to the module cache. The digest of each module's zip file is
+recomputed and compared with the digest recorded when it was downloaded,
+and the extracted files are compared with the contents of the zip file.
+
+The checksums of the zip file and of the cue.mod/module.cue file of each
+module are also checked against those recorded in the cue.mod/sums file
+of the current module or, for modules not listed there, against those
+published in the checksum database configured with CUE_SUMDB, if any.
+See "cue help environment".
+
+For each verified module, verify prints the module version and the digest
+of its zip file. Module zip files are reproducible, so the digest can be
+compared with the one printed by "cue mod publish". Dependencies that are
+not in the module cache are skipped.
+`,
+		RunE: mkRunE(c, runModVerify),
+		Args: cobra.ExactArgs(0),
+	}
+	return cmd
+}
+
+func runModVerify(cmd *Command, args []string) error {
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		return err
+	}
+	modPath := filepath.Join(modRoot, "cue.mod", "module.cue")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return err
+	}
+	mf, err := modfile.ParseNonStrict(data, modPath)
+	if err != nil {
+		return err
+	}
+	cacheDir, err := modCacheDir()
+	if err != nil {
+		return err
+	}
+	chk, err := getChecker(cacheDir)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	var errs []error
+	for _, v := range mf.DepVersions() {
+		zipDigest, err := modcache.Verify(cacheDir, v)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := chk.CheckZip(ctx, v, zipDigest); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if data, err := modcache.CachedModFile(cacheDir, v); err == nil {
+			if err := chk.CheckModFile(ctx, v, data); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", v, zipDigest)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "all modules verified")
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/modupdate"
+)
+
+func newModWatchCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		// TODO: this command is still experimental, don't show it in
+		// the documentation just yet.
+		Hidden: true,
+
+		Use:   "watch",
+		Short: "report new versions of dependencies",
+		Long: `WARNING: THIS COMMAND IS EXPERIMENTAL.
+
+Watch reports the dependencies of the current module, as listed in its
+cue.mod/module.cue file, for which a newer version with the same major
+version is available in the registry. Each available upgrade is printed
+as the module, its current version and its latest version:
+
+	example.com@v0 v0.1.0 => v0.3.0
+
+When the module file of the latest version has a changelog field, the
+URL of its changes is printed on the following line. A module records
+the URL of its changes in its module.cue file, for instance:
+
+	changelog: "https://example.com/releases/{version}"
+
+where {version} is replaced by the version of the module.
+
+Module registries provide no notifications of new versions, so watch
+polls the registry every --interval until it is interrupted, reporting
+each new version once. With --once, watch checks for upgrades once and
+exits.
+
+Use "cue mod upgrade" to upgrade a dependency.
+`,
+		RunE: mkRunE(c, runModWatch),
+		Args: cobra.ExactArgs(0),
+	}
+	cmd.Flags().Duration(string(flagInterval), time.Hour, "time between checks for new versions")
+	cmd.Flags().Bool(string(flagOnce), false, "check for new versions once and exit")
+	return cmd
+}
+
+func runModWatch(cmd *Command, args []string) error {
+	reg, err := getCachedRegistry()
+	if err != nil {
+		return err
+	}
+	if reg == nil {
+		return fmt.Errorf("no module registry configured")
+	}
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		return err
+	}
+	modPath := filepath.Join(modRoot, "cue.mod", "module.cue")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return err
+	}
+	mf, err := modfile.ParseNonStrict(data, modPath)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	report := func(updates []modupdate.Update) error {
+		w := cmd.OutOrStdout()
+		for _, u := range updates {
+			fmt.Fprintf(w, "%s %s => %s\n", u.Module.Path(), u.Module.Version(), u.Latest.Version())
+			if u.Changelog != "" {
+				fmt.Fprintf(w, "\tchangelog: %s\n", u.Changelog)
+			}
+		}
+		return nil
+	}
+	if flagOnce.Bool(cmd) {
+		updates, err := modupdate.Check(ctx, reg, mf)
+		if err != nil {
+			return err
+		}
+		return report(updates)
+	}
+	interval := flagInterval.Duration(cmd)
+	if interval <= 0 {
+		return fmt.Errorf("invalid interval %v: must be positive", interval)
+	}
+	return modupdate.Watch(ctx, reg, mf, interval, report)
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/modload"
+	"cuelang.org/go/internal/mod/modreplace"
+	"cuelang.org/go/internal/mod/module"
+)
+
+func newModWhyCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		// TODO: this command is still experimental, don't show it in
+		// the documentation just yet.
+		Hidden: true,
+
+		Use:   "why <module>...",
+		Short: "explain why modules are needed",
+		Long: `WARNING: THIS COMMAND IS EXPERIMENTAL.
+
+Why shows, for each given module, a shortest chain of imports from a
+package of the current module to a package of the given module. A module
+may be given with its major version, as in example.com@v1, or without,
+in which case any major version matches. All packages of the current
+module are considered, including their test and tool files.
+
+For example:
+
+	$ cue mod why example.com@v0
+	# example.com@v0
+	main.org@v0:main
+	other.com/y
+	example.com/x
+
+If no package of the current module imports a package of the module,
+but the module is required by another dependency, the chain of module
+requirements leading to it is shown instead. A module that is not needed
+at all is reported as such.
+`,
+		RunE: mkRunE(c, runModWhy),
+		Args: cobra.MinimumNArgs(1),
+	}
+	return cmd
+}
+
+func runModWhy(cmd *Command, args []string) error {
+	reg, err := getCachedRegistry()
+	if err != nil {
+		return err
+	}
+	if reg == nil {
+		return fmt.Errorf("no module registry configured")
+	}
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		return err
+	}
+	modPath := filepath.Join(modRoot, "cue.mod", "module.cue")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return err
+	}
+	mf, err := modfile.ParseNonStrict(data, modPath)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := defaultConfig()
+	if err != nil {
+		return err
+	}
+	cfg.loadCfg.Dir = modRoot
+	cfg.loadCfg.Tests = true
+	cfg.loadCfg.Tools = true
+	insts := load.Instances([]string{"./..."}, cfg.loadCfg)
+	for _, inst := range insts {
+		if inst.Err != nil {
+			return inst.Err
+		}
+	}
+	sort.Slice(insts, func(i, j int) bool {
+		return insts[i].ImportPath < insts[j].ImportPath
+	})
+
+	w := &modWhy{
+		ctx: context.Background(),
+		reg: modreplace.NewRegistry(reg, mf, modRoot),
+		mf:  mf,
+	}
+	out := cmd.OutOrStdout()
+	for i, arg := range args {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "# %s\n", arg)
+		if chain := w.importChain(insts, arg); chain != nil {
+			for _, p := range chain {
+				fmt.Fprintln(out, p)
+			}
+			continue
+		}
+		chain, err := w.requireChain(insts, arg)
+		if err != nil {
+			return err
+		}
+		if chain == nil {
+			fmt.Fprintf(out, "(main module does not need module %s)\n", arg)
+			continue
+		}
+		fmt.Fprintf(out, "(main module does not import packages of %s, but requires it through)\n", arg)
+		for _, m := range chain {
+			fmt.Fprintln(out, m)
+		}
+	}
+	return nil
+}
+
+// modWhy finds the reasons for which the main module needs other modules.
+type modWhy struct {
+	ctx context.Context
+	reg modload.Registry
+	mf  *modfile.File
+}
+
+// importChain returns a shortest chain of import paths from one of the
+// given packages of the main module to a package of the module matching
+// arg, or nil if there is none.
+func (w *modWhy) importChain(roots []*build.Instance, arg string) []string {
+	prev := map[*build.Instance]*build.Instance{}
+	queue := roots
+	for _, inst := range roots {
+		prev[inst] = nil
+	}
+	for len(queue) > 0 {
+		inst := queue[0]
+		queue = queue[1:]
+		if m := w.moduleOf(inst.ImportPath); m != "" && m != w.mf.Module && matchModule(arg, m) {
+			var chain []string
+			for p := inst; p != nil; p = prev[p] {
+				chain = append(chain, p.ImportPath)
+			}
+			for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+				chain[i], chain[j] = chain[j], chain[i]
+			}
+			return chain
+		}
+		for _, imp := range inst.Imports {
+			if _, ok := prev[imp]; !ok {
+				prev[imp] = inst
+				queue = append(queue, imp)
+			}
+		}
+	}
+	return nil
+}
+
+// moduleOf returns the path of the module, among the main module and its
+// dependencies, that holds the package with the given import path, or ""
+// if there is none, as for standard library packages.
+func (w *modWhy) moduleOf(importPath string) string {
+	ip := module.ParseImportPath(importPath)
+	best, bestBase := "", ""
+	for _, mpath := range append(w.depPaths(), w.mf.Module) {
+		base, major, ok := module.SplitPathVersion(mpath)
+		if !ok {
+			base = mpath
+		}
+		if ip.Path != base && !strings.HasPrefix(ip.Path, base+"/") {
+			continue
+		}
+		if ip.Version != "" && major != "" && ip.Version != major {
+			continue
+		}
+		if len(base) > len(bestBase) {
+			best, bestBase = mpath, base
+		}
+	}
+	return best
+}
+
+// requireChain returns a shortest chain of module requirements from the
+// main module to a module matching arg, or nil if there is none. As the
+// module file lists all the modules in the build, the chain starts at a
+// module holding a package imported, directly or indirectly, by one of
+// the given packages of the main module.
+func (w *modWhy) requireChain(roots []*build.Instance, arg string) ([]string, error) {
+	type node struct {
+		m    module.Version
+		prev *node
+	}
+	used := w.usedModules(roots)
+	seen := map[string]bool{}
+	var queue []*node
+	for _, m := range w.mf.DepVersions() {
+		if used[m.Path()] {
+			queue = append(queue, &node{m: m})
+			seen[m.Path()] = true
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if matchModule(arg, n.m.Path()) {
+			chain := []string{w.mf.Module}
+			var rev []string
+			for p := n; p != nil; p = p.prev {
+				rev = append(rev, p.m.String())
+			}
+			for i := len(rev) - 1; i >= 0; i-- {
+				chain = append(chain, rev[i])
+			}
+			return chain, nil
+		}
+		summary, err := w.reg.CUEModSummary(w.ctx, n.m)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range summary.Require {
+			if !seen[r.Path()] {
+				seen[r.Path()] = true
+				queue = append(queue, &node{m: r, prev: n})
+			}
+		}
+	}
+	return nil, nil
+}
+
+// usedModules returns the set of the dependencies of the main module
+// that hold a package imported, directly or indirectly, by one of the
+// given packages.
+func (w *modWhy) usedModules(roots []*build.Instance) map[string]bool {
+	used := map[string]bool{}
+	seen := map[*build.Instance]bool{}
+	var walk func(inst *build.Instance)
+	walk = func(inst *build.Instance) {
+		if seen[inst] {
+			return
+		}
+		seen[inst] = true
+		if m := w.moduleOf(inst.ImportPath); m != "" && m != w.mf.Module {
+			used[m] = true
+		}
+		for _, imp := range inst.Imports {
+			walk(imp)
+		}
+	}
+	for _, inst := range roots {
+		walk(inst)
+	}
+	return used
+}
+
+// depPaths returns the module paths of the dependencies of the main module.
+func (w *modWhy) depPaths() []string {
+	var paths []string
+	for mpath := range w.mf.Deps {
+		paths = append(paths, mpath)
+	}
+	return paths
+}
+
+// matchModule reports whether the module path mpath matches arg, which
+// holds a module path with or without a major version.
+func matchModule(arg, mpath string) bool {
+	if strings.Contains(arg, "@") {
+		return arg == mpath
+	}
+	base, _, ok := module.SplitPathVersion(mpath)
+	return ok && base == arg
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+)
+
+// A parallelIterator evaluates instances concurrently, yielding them in
+// the order in which they were given.
+//
+// As a cue.Context may not be used by more than one goroutine at a time,
+// each worker evaluates instances with its own context. The packages
+// imported by the instances of a worker are evaluated only once in its
+// context, so that their evaluation is shared by all those instances.
+//
+// A worker does not move on to its next instance until the value of the
+// previous one is no longer used, as using a value, for instance to
+// encode it, may evaluate it further within the context of the worker.
+type parallelIterator struct {
+	queue chan *parallelJob
+	stop  chan struct{}
+	cur   *parallelJob
+	e     error
+}
+
+type parallelJob struct {
+	binst *build.Instance
+	val   cue.Value
+	err   error
+
+	done    chan struct{} // closed once val is evaluated
+	release chan struct{} // closed once val is no longer used
+}
+
+func newParallelIterator(cmd *Command, insts []*build.Instance, n int) *parallelIterator {
+	n = min(n, len(insts))
+	i := &parallelIterator{
+		queue: make(chan *parallelJob, 2*n),
+		stop:  make(chan struct{}),
+	}
+	ignore := flagIgnore.Bool(cmd)
+
+	// Build instances share the syntax of the packages they import, which
+	// is modified when it is compiled. Compilation is therefore done by
+	// one worker at a time; it is evaluation that is done concurrently.
+	var compileMu sync.Mutex
+
+	jobs := make(chan *parallelJob)
+	for w := 0; w < n; w++ {
+		ctx := cmd.newContext()
+		go func() {
+			for j := range jobs {
+				compileMu.Lock()
+				j.val = ctx.BuildInstance(j.binst)
+				compileMu.Unlock()
+				j.err = j.val.Err()
+				if j.err == nil && !ignore {
+					j.err = j.val.Validate()
+				}
+				close(j.done)
+				select {
+				case <-j.release:
+				case <-i.stop:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		defer close(i.queue)
+		for _, b := range insts {
+			j := &parallelJob{
+				binst:   b,
+				done:    make(chan struct{}),
+				release: make(chan struct{}),
+			}
+			select {
+			case i.queue <- j:
+			case <-i.stop:
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-i.stop:
+				return
+			}
+		}
+	}()
+	return i
+}
+
+func (i *parallelIterator) scan() bool {
+	if i.cur != nil {
+		close(i.cur.release)
+		i.cur = nil
+	}
+	if i.e != nil {
+		return false
+	}
+	j, ok := <-i.queue
+	if !ok {
+		return false
+	}
+	<-j.done
+	i.cur = j
+	if j.err != nil {
+		i.e = j.err
+		return false
+	}
+	return true
+}
+
+func (i *parallelIterator) close() {
+	if i.cur != nil {
+		close(i.cur.release)
+		i.cur = nil
+	}
+	close(i.stop)
+}
+
+func (i *parallelIterator) err() error       { return i.e }
+func (i *parallelIterator) value() cue.Value { return i.cur.val }
+func (i *parallelIterator) file() *ast.File  { return nil }
+func (i *parallelIterator) id() string       { return i.cur.binst.ID() }
@@ -0,0 +1,165 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/tools/refactor"
+)
+
+func newRefactorCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refactor <cmd> [arguments]",
+		Short: "automated refactoring of CUE packages",
+		Long: `Refactor provides commands that restructure the CUE packages of a module,
+updating references throughout the module.
+`,
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			stderr := cmd.Stderr()
+			if len(args) == 0 {
+				fmt.Fprintln(stderr, "refactor must be run as one of its subcommands")
+			} else {
+				fmt.Fprintf(stderr, "refactor must be run as one of its subcommands: unknown subcommand %q\n", args[0])
+			}
+			fmt.Fprintln(stderr, "Run 'cue help refactor' for known subcommands.")
+			os.Exit(1) // TODO: get rid of this
+			return nil
+		}),
+	}
+
+	cmd.AddCommand(newRefactorMoveCmd(c))
+	return cmd
+}
+
+func newRefactorMoveCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "move <package> <definition> <destination>",
+		Short: "move a definition to another package or file",
+		Long: `Move moves all top-level declarations of a definition from a package
+to another package or file within the current module.
+
+The package is specified as a directory within the module. The destination
+is either a directory, in which case the definition is moved to the file
+with the same name as the file that declared it, or a .cue file. The
+destination package is created if it does not exist.
+
+All references to the definition within the module are updated to refer to
+its new location and imports are added or removed as needed. References
+from the moved definition to its original package are qualified with an
+import of that package. Comments of moved declarations are preserved.
+
+Example:
+
+	$ cue refactor move ./schema '#Service' ./schema/service
+`,
+		RunE: mkRunE(c, runRefactorMove),
+		Args: cobra.ExactArgs(3),
+	}
+	cmd.Flags().Bool(string(flagDryrun), false,
+		"only print the names of the files that would be modified")
+	return cmd
+}
+
+func runRefactorMove(cmd *Command, args []string) error {
+	modRoot, err := findModuleRoot()
+	if err != nil {
+		return err
+	}
+	binst := load.Instances([]string{"./..."}, &load.Config{
+		Dir:   modRoot,
+		Tests: true,
+		Tools: true,
+	})
+	for _, b := range binst {
+		if b.Err != nil {
+			return b.Err
+		}
+	}
+	if len(binst) == 0 {
+		return fmt.Errorf("no packages found in module")
+	}
+
+	srcDir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	from := ""
+	for _, b := range binst {
+		if b.Dir == srcDir {
+			from = b.ImportPath
+		}
+	}
+	if from == "" {
+		return fmt.Errorf("no package found in %s", args[0])
+	}
+
+	dst, err := filepath.Abs(args[2])
+	if err != nil {
+		return err
+	}
+	cfg := &refactor.MoveConfig{Dir: dst}
+	if strings.HasSuffix(dst, ".cue") {
+		cfg.File = dst
+		cfg.Dir = filepath.Dir(dst)
+	}
+	to := ""
+	for _, b := range binst {
+		if b.Dir == cfg.Dir {
+			to = b.ImportPath
+		}
+	}
+	if to == "" {
+		rel, err := filepath.Rel(modRoot, cfg.Dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("destination %s must be a subdirectory of the module root", args[2])
+		}
+		mod := binst[0].Module
+		if mod == "" {
+			return fmt.Errorf("cannot create package in module without a module path")
+		}
+		to = mod + "/" + filepath.ToSlash(rel)
+	}
+
+	files, err := refactor.MoveDefinition(binst, args[1], from, to, cfg)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if flagDryrun.Bool(cmd) {
+			rel, _ := filepath.Rel(modRoot, f.Filename)
+			fmt.Fprintln(cmd.OutOrStdout(), filepath.ToSlash(rel))
+			continue
+		}
+		b, err := format.Node(f)
+		if err != nil {
+			return fmt.Errorf("error formatting file: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(f.Filename), 0o777); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.Filename, b, 0o666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,20 +2,32 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"cuelabs.dev/go/oci/ociregistry"
 	"cuelabs.dev/go/oci/ociregistry/ociauth"
 	"cuelabs.dev/go/oci/ociregistry/ociclient"
+	"golang.org/x/oauth2"
 
+	"cuelang.org/go/cue/logging"
 	"cuelang.org/go/internal/cueexperiment"
 	"cuelang.org/go/internal/mod/modcache"
 	"cuelang.org/go/internal/mod/modload"
 	"cuelang.org/go/internal/mod/modmux"
+	"cuelang.org/go/internal/mod/modproxy"
 	"cuelang.org/go/internal/mod/modresolve"
+	"cuelang.org/go/internal/mod/modsum"
+	"cuelang.org/go/internal/mod/module"
 )
 
 // getRegistry returns the registry to pull modules from.
@@ -26,7 +38,7 @@ func getRegistry() (ociregistry.Interface, error) {
 	env := os.Getenv("CUE_REGISTRY")
 	if !cueexperiment.Flags.Modules {
 		if env != "" {
-			fmt.Fprintf(os.Stderr, "warning: ignoring CUE_REGISTRY because modules experiment is not enabled. Set CUE_EXPERIMENT=modules to enable it.\n")
+			registryLogger.Warn("ignoring CUE_REGISTRY because modules experiment is not enabled; set CUE_EXPERIMENT=modules to enable it")
 		}
 		return nil, nil
 	}
@@ -34,6 +46,7 @@ func getRegistry() (ociregistry.Interface, error) {
 	if err != nil {
 		return nil, fmt.Errorf("bad value for $CUE_REGISTRY: %v", err)
 	}
+	registryLogger.Debug("using registry configuration", "CUE_REGISTRY", env)
 	// If the user isn't doing anything that requires a registry, we
 	// shouldn't complain about reading a bad configuration file,
 	// so check only when required.
@@ -62,15 +75,23 @@ func getRegistry() (ociregistry.Interface, error) {
 				return
 			}
 			logins, err := readLogins(loginsPath)
-			if err != nil {
+			var unavailable *loginsUnavailableError
+			if errors.As(err, &unavailable) {
+				// Carry on without the logins, and only report the error
+				// if a registry turns out to require authentication.
+				registryLogger.Debug("cannot read registry logins", "err", err)
+			} else if err != nil {
 				authErr = fmt.Errorf("cannot load CUE registry logins: %v", err)
 				return
 			}
-			auth = &cueLoginsAuthorizer{
+			registryLogger.Debug("loaded registry logins", "path", loginsPath, "registries", len(logins.Registries))
+			auth = loggingAuthorizer{&cueLoginsAuthorizer{
 				logins:        logins,
+				loginsPath:    loginsPath,
+				loginsErr:     err,
 				cachedClients: make(map[string]*http.Client),
 				next:          auth,
-			}
+			}}
 		})
 		if authErr != nil {
 			return nil, authErr
@@ -82,8 +103,36 @@ func getRegistry() (ociregistry.Interface, error) {
 	}), nil
 }
 
+var registryLogger = logging.For(logging.Registry)
+
+// loggingAuthorizer logs the requests made through an authorizer.
+type loggingAuthorizer struct {
+	next ociauth.Authorizer
+}
+
+func (a loggingAuthorizer) DoRequest(req *http.Request, requiredScope ociauth.Scope) (*http.Response, error) {
+	start := time.Now()
+	resp, err := a.next.DoRequest(req, requiredScope)
+	attrs := []any{
+		"method", req.Method,
+		"url", req.URL.String(),
+		"scope", requiredScope.String(),
+		"duration", time.Since(start),
+	}
+	if err != nil {
+		registryLogger.Debug("registry request failed", append(attrs, "err", err)...)
+	} else {
+		registryLogger.Debug("registry request", append(attrs, "status", resp.StatusCode)...)
+	}
+	return resp, err
+}
+
 type cueLoginsAuthorizer struct {
-	logins        *cueLogins
+	logins     *cueLogins
+	loginsPath string
+	// loginsErr, if non-nil, is the reason why the logins could not be
+	// read. It is reported when a registry requires authentication.
+	loginsErr     error
 	cachedClients map[string]*http.Client
 	next          ociauth.Authorizer
 }
@@ -95,32 +144,190 @@ func (a *cueLoginsAuthorizer) DoRequest(req *http.Request, requiredScope ociauth
 	host := req.URL.Host
 	login, ok := a.logins.Registries[host]
 	if !ok {
-		return a.next.DoRequest(req, requiredScope)
+		registryLogger.Debug("no cue login for registry; falling back to docker configuration", "host", host)
+		resp, err := a.next.DoRequest(req, requiredScope)
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && a.loginsErr != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s requires authentication, but the CUE registry logins cannot be used: %v", host, a.loginsErr)
+		}
+		return resp, err
 	}
+	registryLogger.Debug("using cue login for registry", "host", host)
 
 	client := a.cachedClients[host]
 	if client == nil {
 		tok := tokenFromLogin(login)
 		oauthCfg := registryOAuthConfig(host)
-		// TODO: When this client refreshes an access token,
-		// we should store the refreshed token on disk.
-		client = oauthCfg.Client(context.Background(), tok)
+		if login.TokenURL != "" {
+			oauthCfg.Endpoint.TokenURL = login.TokenURL
+		}
+		ctx := context.Background()
+		client = oauth2.NewClient(ctx, &refreshingTokenSource{
+			host:       host,
+			loginsPath: a.loginsPath,
+			login:      login,
+			last:       tok,
+			base:       oauthCfg.TokenSource(ctx, tok),
+		})
 		a.cachedClients[host] = client
 	}
 	return client.Do(req)
 }
 
+// refreshingTokenSource stores access tokens refreshed by the underlying
+// token source in the logins file, so that they can be reused by later
+// invocations of cue.
+type refreshingTokenSource struct {
+	host       string
+	loginsPath string
+	login      cueRegistryLogin
+
+	mu   sync.Mutex
+	last *oauth2.Token
+	base oauth2.TokenSource
+}
+
+func (s *refreshingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, err := s.base.Token()
+	if err != nil {
+		if s.last.RefreshToken == "" {
+			return nil, fmt.Errorf("the login for %s has expired; run 'cue login %s' again", s.host, s.host)
+		}
+		return nil, fmt.Errorf("cannot refresh the login for %s; run 'cue login %s' again: %v", s.host, s.host, err)
+	}
+	if tok.AccessToken != s.last.AccessToken {
+		registryLogger.Debug("refreshed access token", "host", s.host)
+		login := loginFromToken(tok)
+		login.TokenURL = s.login.TokenURL
+		if _, err := updateLogin(s.loginsPath, s.host, login); err != nil {
+			// The refreshed token can still be used for this invocation,
+			// but later ones may fail, so warn the user; warnings are
+			// logged by default.
+			registryLogger.Warn(fmt.Sprintf("cannot store the refreshed login for %s; run 'cue login %s' if later commands fail to authenticate", s.host, s.host), "err", err)
+		}
+		s.last = tok
+	}
+	return tok, nil
+}
+
 func getCachedRegistry() (modload.Registry, error) {
+	reg, _, err := getCheckedRegistry()
+	return reg, err
+}
+
+// getCheckedRegistry is like getCachedRegistry, but also returns the
+// checker used to verify downloaded modules, which records their
+// checksums.
+func getCheckedRegistry() (modload.Registry, *modsum.Checker, error) {
 	reg, err := getRegistry()
 	if reg == nil {
-		return nil, err
+		return nil, nil, err
 	}
 	cacheDir, err := modCacheDir()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := os.MkdirAll(cacheDir, 0o777); err != nil {
-		return nil, fmt.Errorf("cannot create cache directory: %v", err)
+		return nil, nil, fmt.Errorf("cannot create cache directory: %v", err)
+	}
+	src, err := getModuleSource(reg)
+	if err != nil {
+		return nil, nil, err
+	}
+	chk, err := getChecker(cacheDir)
+	if err != nil {
+		return nil, nil, err
 	}
-	return modcache.New(reg, cacheDir)
+	cr, err := modcache.NewFromSource(src, cacheDir, chk)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cr, chk, nil
+}
+
+// getChecker returns the checker that verifies modules against the
+// cue.mod/sums file of the current module, if any, and against the
+// checksum database configured with $CUE_SUMDB.
+func getChecker(cacheDir string) (*modsum.Checker, error) {
+	var sums *modsum.File
+	if modRoot, err := findModuleRoot(); err == nil {
+		sums, err = readSums(modRoot)
+		if err != nil {
+			return nil, err
+		}
+	}
+	env := os.Getenv("CUE_SUMDB")
+	if env == "" || env == "off" {
+		return modsum.NewChecker(sums, nil), nil
+	}
+	registryLogger.Debug("using checksum database configuration", "CUE_SUMDB", env)
+	db, err := modsum.OpenDB(env, filepath.Join(cacheDir, "cache", "sumdb"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("bad value for $CUE_SUMDB: %v", err)
+	}
+	if noSumDB := os.Getenv("CUE_NOSUMDB"); noSumDB != "" {
+		db.SetGONOSUMDB(noSumDB)
+	}
+	return modsum.NewChecker(sums, db), nil
+}
+
+// readSums reads the cue.mod/sums file of the module rooted at modRoot.
+// A missing file holds no checksums.
+func readSums(modRoot string) (*modsum.File, error) {
+	sumsPath := filepath.Join(modRoot, "cue.mod", "sums")
+	data, err := os.ReadFile(sumsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return modsum.NewFile(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return modsum.ParseFile(data, sumsPath)
+}
+
+// getModuleSource returns the source to download modules from, as
+// configured by $CUE_PROXY. Modules are downloaded from the registry reg
+// if it is not set.
+func getModuleSource(reg ociregistry.Interface) (modcache.Source, error) {
+	env := os.Getenv("CUE_PROXY")
+	if env == "" {
+		return modcache.RegistrySource(reg), nil
+	}
+	registryLogger.Debug("using module proxy configuration", "CUE_PROXY", env)
+	var srcs []modcache.Source
+	for _, entry := range strings.Split(env, ",") {
+		switch entry = strings.TrimSpace(entry); entry {
+		case "direct":
+			srcs = append(srcs, modcache.RegistrySource(reg))
+		case "off":
+			srcs = append(srcs, offSource{})
+		default:
+			u, err := url.Parse(entry)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("bad value for $CUE_PROXY: %q is not an HTTP URL, direct or off", entry)
+			}
+			srcs = append(srcs, &modproxy.Client{URL: entry})
+		}
+	}
+	return modcache.Fallback(srcs...), nil
+}
+
+// offSource is the module source for "off" in $CUE_PROXY, which
+// disallows downloading modules.
+type offSource struct{}
+
+var errProxyOff = fmt.Errorf("module download disabled by CUE_PROXY=off")
+
+func (offSource) ModuleVersions(ctx context.Context, m string) ([]string, error) {
+	return nil, fmt.Errorf("module %s: %w", m, errProxyOff)
+}
+
+func (offSource) ModuleFile(ctx context.Context, mv module.Version) ([]byte, error) {
+	return nil, fmt.Errorf("module %v: %w", mv, errProxyOff)
+}
+
+func (offSource) GetZip(ctx context.Context, mv module.Version) (io.ReadCloser, ociregistry.Digest, error) {
+	return nil, "", fmt.Errorf("module %v: %w", mv, errProxyOff)
 }
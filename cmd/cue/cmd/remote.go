@@ -0,0 +1,166 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/internal/mod/modload"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+// remotePackageRE matches package arguments of the form
+// importpath@version[:pkgname], where the first element of importpath
+// contains a dot and version is either a major version, such as v1, or
+// a full semantic version, such as v1.2.3.
+var remotePackageRE = regexp.MustCompile(`^([^\s:@./][^\s:@/]*\.[^\s:@/]+(?:/[^\s:@]+)?)@(v[0-9]+(?:\.[0-9]+\.[0-9]+[^\s:]*)?)(:[\pL_][\pL\pN_]*)?$`)
+
+// splitRemotePackageArgs separates the arguments referring to packages
+// in a module registry from those referring to local packages and files.
+// An argument is only considered to refer to a registry if it does not
+// exist as a file.
+func splitRemotePackageArgs(args []string, cfg *load.Config) (local, remote []string) {
+	if cfg.Registry == nil {
+		return args, nil
+	}
+	for _, arg := range args {
+		if remotePackageRE.MatchString(arg) {
+			if _, err := os.Stat(filepath.Join(cfg.Dir, arg)); err != nil {
+				remote = append(remote, arg)
+				continue
+			}
+		}
+		local = append(local, arg)
+	}
+	return local, remote
+}
+
+// loadRemotePackage loads the package referred to by a remote package
+// argument, such as foo.com/bar/config@v1.2.3.
+func loadRemotePackage(arg string, cfg *load.Config) (*build.Instance, error) {
+	m := remotePackageRE.FindStringSubmatch(arg)
+	if m == nil {
+		return nil, fmt.Errorf("invalid package reference %q", arg)
+	}
+	pkgPath, version, qualifier := m[1], m[2], m[3]
+	importPath := pkgPath + "@" + semver.Major(version) + qualifier
+	if version == semver.Major(version) {
+		version = ""
+	}
+	return loadRegistryPackage(importPath, version, cfg)
+}
+
+// remoteModulePath is the path of the module synthesized to load packages
+// from a registry.
+const remoteModulePath = "cue.invalid/remote@v0"
+
+// loadRegistryPackage loads the package with the given import path from
+// the registry configured in cfg. If version is not empty, the package is
+// loaded from that exact version of its module; otherwise the latest
+// version is used.
+//
+// The package is loaded by synthesizing a module in a temporary directory
+// that imports the package, so that its dependencies are resolved in the
+// same way as those of any other module.
+func loadRegistryPackage(importPath, version string, cfg *load.Config) (*build.Instance, error) {
+	reg := cfg.Registry
+	if reg == nil {
+		return nil, fmt.Errorf("cannot load %s: no module registry configured", importPath)
+	}
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "cue-remote")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	modFile := fmt.Sprintf("module: %q\n", remoteModulePath)
+	if version != "" {
+		mpath, err := findModuleVersion(ctx, reg, importPath, version)
+		if err != nil {
+			return nil, err
+		}
+		modFile += fmt.Sprintf("deps: %q: v: %q\n", mpath, version)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "cue.mod"), 0o777); err != nil {
+		return nil, err
+	}
+	modFilePath := filepath.Join(dir, "cue.mod", "module.cue")
+	if err := os.WriteFile(modFilePath, []byte(modFile), 0o666); err != nil {
+		return nil, err
+	}
+	src := fmt.Sprintf("package remote\n\nimport p %s\n\np\n", strconv.Quote(importPath))
+	if err := os.WriteFile(filepath.Join(dir, "remote.cue"), []byte(src), 0o666); err != nil {
+		return nil, err
+	}
+
+	// Resolve the module providing the package and its dependencies.
+	// Dependencies that are already present, such as a module with an
+	// explicit version, are not upgraded.
+	mf, err := modload.Load(ctx, os.DirFS(dir), ".", reg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load %s: %v", importPath, err)
+	}
+	data, err := mf.Format()
+	if err != nil {
+		return nil, fmt.Errorf("internal error: invalid module.cue file generated: %v", err)
+	}
+	if err := os.WriteFile(modFilePath, data, 0o666); err != nil {
+		return nil, err
+	}
+
+	binsts := load.Instances([]string{"."}, &load.Config{
+		Dir:       dir,
+		ParseFile: cfg.ParseFile,
+		Registry:  reg,
+	})
+	b := binsts[0]
+	if b.Err != nil {
+		return nil, b.Err
+	}
+	if len(b.Imports) != 1 {
+		return nil, fmt.Errorf("cannot load %s: package not found", importPath)
+	}
+	return b.Imports[0], nil
+}
+
+// findModuleVersion returns the path of the module providing the package
+// with the given import path at the given version.
+func findModuleVersion(ctx context.Context, reg modload.Registry, importPath, version string) (string, error) {
+	pkgPath, _, _ := strings.Cut(importPath, ":")
+	pkgPath, major, _ := strings.Cut(pkgPath, "@")
+	// Try the longest candidate module path first.
+	for p := pkgPath; p != "." && p != "/"; p = path.Dir(p) {
+		mpath := p + "@" + major
+		versions, err := reg.ModuleVersions(ctx, mpath)
+		if err != nil {
+			continue
+		}
+		if slices.Contains(versions, version) {
+			return mpath, nil
+		}
+	}
+	return "", fmt.Errorf("cannot find module providing package %s at version %s", pkgPath, version)
+}
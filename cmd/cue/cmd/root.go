@@ -17,13 +17,18 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 
 	"github.com/spf13/cobra"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/interpreter/wasm"
@@ -31,6 +36,7 @@ import (
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/cueexperiment"
 	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/evaltrace"
 	"cuelang.org/go/internal/filetypes"
 )
 
@@ -77,6 +83,89 @@ type Stats struct {
 		AllocBytes   uint64
 		AllocObjects uint64
 	}
+
+	// Packages attributes the stats obtained from the CUE evaluator to the
+	// packages, and the files within them, that define the evaluated values.
+	// Only the packages loaded by the command and their dependencies are
+	// included.
+	Packages []PackageStats
+
+	// Experiments lists the evaluator experiments enabled for the
+	// evaluation, as they affect the stats obtained from the evaluator.
+	Experiments []string `json:",omitempty"`
+}
+
+// PackageStats holds the stats attributed to a package.
+type PackageStats struct {
+	ImportPath string
+	CUE        stats.Usage
+	Files      []FileStats
+}
+
+// FileStats holds the stats attributed to a file.
+type FileStats struct {
+	Filename string
+	CUE      stats.Usage
+}
+
+// statsPackages maps the names of loaded files to the import path of their
+// package. It is only set if stats are enabled.
+var statsPackages map[string]string
+
+// addStatsPackages records the package of the files of the given instances
+// and their dependencies.
+func addStatsPackages(insts []*build.Instance) {
+	if statsPackages == nil {
+		return
+	}
+	for _, inst := range insts {
+		for _, f := range inst.Files {
+			if _, ok := statsPackages[f.Filename]; ok {
+				continue
+			}
+			statsPackages[f.Filename] = inst.ImportPath
+		}
+		addStatsPackages(inst.Imports)
+	}
+}
+
+// packageStats groups the given file stats by package, leaving out files
+// that are not part of a loaded package. File names are made relative to
+// the current directory where possible.
+func packageStats(files map[string]stats.Usage) []PackageStats {
+	cwd, _ := os.Getwd()
+	byPath := map[string]*PackageStats{}
+	for file, u := range files {
+		path, ok := statsPackages[file]
+		if !ok {
+			continue
+		}
+		p := byPath[path]
+		if p == nil {
+			p = &PackageStats{ImportPath: path}
+			byPath[path] = p
+		}
+		if inTest {
+			// Wall times are not deterministic.
+			u.Duration = 0
+		}
+		if rel, err := filepath.Rel(cwd, file); err == nil && filepath.IsAbs(file) {
+			file = rel
+		}
+		p.CUE.Add(u)
+		p.Files = append(p.Files, FileStats{Filename: filepath.ToSlash(file), CUE: u})
+	}
+	a := []PackageStats{}
+	for _, p := range byPath {
+		sort.Slice(p.Files, func(i, j int) bool {
+			return p.Files[i].Filename < p.Files[j].Filename
+		})
+		a = append(a, *p)
+	}
+	sort.Slice(a, func(i, j int) bool {
+		return a[i].ImportPath < a[j].ImportPath
+	})
+	return a
 }
 
 func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
@@ -84,12 +173,39 @@ func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 		c.Command = cmd
 
 		statsEnc := statsEncoder(c)
+		if statsEnc != nil {
+			statsPackages = map[string]string{}
+			adt.EnableFileStats()
+		}
 
 		if err := cueexperiment.Init(); err != nil {
 			return err
 		}
+		if err := checkErrorFormat(c); err != nil {
+			return err
+		}
+		restoreLogging, err := setupLogging(c)
+		if err != nil {
+			return err
+		}
+		defer restoreLogging()
+
+		var trace *evaltrace.Recorder
+		traceOut := flagTraceOut.String(c)
+		if traceOut != "" {
+			cwd, _ := os.Getwd()
+			trace = evaltrace.New(c.ctx, &evaltrace.Config{Dir: cwd})
+			adt.SetObserver(trace)
+			defer adt.SetObserver(nil)
+		}
 
-		err := f(c, args)
+		err = f(c, args)
+
+		if trace != nil {
+			if werr := writeTrace(trace, traceOut); werr != nil && err == nil {
+				err = werr
+			}
+		}
 
 		if statsEnc != nil {
 			var stats Stats
@@ -114,6 +230,9 @@ func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 			stats.Go.AllocBytes = m.TotalAlloc
 			stats.Go.AllocObjects = m.Mallocs
 
+			stats.Packages = packageStats(adt.FileStats())
+			stats.Experiments = c.ctx.Experiments()
+
 			statsEnc.Encode(c.ctx.Encode(stats))
 			statsEnc.Close()
 		}
@@ -121,6 +240,19 @@ func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 	}
 }
 
+// writeTrace writes the HTML trace recorded by r to the named file.
+func writeTrace(r *evaltrace.Recorder, name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteHTML(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 // TODO(mvdan): remove this error return at some point.
 // The API could also be made clearer if we want to keep cmd public,
 // such as not leaking *cobra.Command via embedding.
@@ -169,7 +301,7 @@ For more information on writing CUE configuration files see cuelang.org.`,
 	c := &Command{
 		Command: cmd,
 		root:    cmd,
-		ctx:     cuecontext.New(cuecontext.Interpreter(wasm.New())),
+		ctx:     newContext(),
 	}
 
 	cmdCmd := newCmdCmd(c)
@@ -177,15 +309,25 @@ For more information on writing CUE configuration files see cuelang.org.`,
 
 	subCommands := []*cobra.Command{
 		cmdCmd,
+		newBugCmd(c),
+		newCacheCmd(c),
 		newCompletionCmd(c),
+		newDAPCmd(c),
 		newEvalCmd(c),
 		newDefCmd(c),
+		newDocCmd(c),
+		newEditCmd(c),
 		newExportCmd(c),
 		newFixCmd(c),
 		newFmtCmd(c),
+		newGenCmd(c),
 		newGetCmd(c),
+		newHelmCmd(c),
 		newImportCmd(c),
+		newInitCmd(c),
+		newKafkaCmd(c),
 		newModCmd(c),
+		newRefactorCmd(c),
 		newTrimCmd(c),
 		newVersionCmd(c),
 		newVetCmd(c),
@@ -219,6 +361,45 @@ For more information on writing CUE configuration files see cuelang.org.`,
 	return c, nil
 }
 
+// newContext returns a new context in which to build instances. The
+// evaluator experiments enabled by the module containing the current
+// directory are enabled in it, as they cannot be enabled once the
+// context is in use.
+// newContext returns a context for evaluating CUE with the evaluator
+// experiments with the given names enabled.
+func newContext(experiments ...string) *cue.Context {
+	return cuecontext.New(
+		cuecontext.Interpreter(wasm.New()),
+		cuecontext.Experiments(experiments...),
+	)
+}
+
+// newContext returns a new context with the same options as the context
+// of c, for evaluating values concurrently.
+func (c *Command) newContext() *cue.Context {
+	return newContext(c.ctx.Experiments()...)
+}
+
+// useExperiments makes sure that the context of c enables the evaluator
+// experiments enabled by the modules of insts, as read by cue/load from
+// their module.cue files. Experiments can only be enabled when a context
+// is created, so it must be called before any of insts is built.
+func (c *Command) useExperiments(insts ...*build.Instance) {
+	enabled := c.ctx.Experiments()
+	names := slices.Clone(enabled)
+	for _, b := range insts {
+		for _, name := range b.Experiments {
+			// Unknown experiments are reported when the instance is loaded.
+			if !slices.Contains(names, name) && cueexperiment.Check(name) == nil {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) > len(enabled) {
+		c.ctx = newContext(names...)
+	}
+}
+
 // MainTest is like Main, runs the cue tool and returns the code for passing to os.Exit.
 func MainTest() int {
 	// Setting inTest causes filenames printed in error messages
@@ -232,6 +413,13 @@ func MainTest() int {
 
 // Main runs the cue tool and returns the code for passing to os.Exit.
 func Main() int {
+	if startup, useJSON, ok := workerArgs(os.Args[1:]); ok {
+		if err := runWorker(os.Stdin, os.Stdout, startup, useJSON); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
 	cwd, _ := os.Getwd()
 	cmd, _ := New(os.Args[1:])
 	if err := cmd.Run(context.Background()); err != nil {
@@ -258,6 +446,9 @@ type Command struct {
 	ctx *cue.Context
 
 	hasErr bool
+
+	// gitlabIssues holds the errors reported with --error-format=gitlab.
+	gitlabIssues []gitlabIssue
 }
 
 type errWriter Command
@@ -304,8 +495,12 @@ func (c *Command) Run(ctx context.Context) (err error) {
 	// - user defined
 	// - help
 	// For the latter two, we need to use the default loading.
+	defer c.reportErrors(&err)
 	defer recoverError(&err)
 
+	if err := registerEnvQualifiers(); err != nil {
+		return err
+	}
 	if err := c.root.Execute(); err != nil {
 		return err
 	}
@@ -326,6 +521,34 @@ func recoverError(err *error) {
 	// We use panic to escape, instead of os.Exit
 }
 
+// reportErrors reports err in the format selected with --error-format,
+// if it is not the default text format, along with any errors recorded
+// while running the command.
+func (c *Command) reportErrors(err *error) {
+	if checkErrorFormat(c) != nil {
+		return
+	}
+	format := c.errorFormat()
+	if format == errorFormatText {
+		return
+	}
+	w := c.root.ErrOrStderr()
+	if *err != nil && *err != ErrPrintedError {
+		if format == errorFormatGitHub {
+			printGitHubDiagnostics(w, *err)
+		} else {
+			c.addGitLabDiagnostics(*err)
+		}
+		*err = ErrPrintedError
+	}
+	if format == errorFormatGitLab {
+		// The errors are not reported if the report cannot be written.
+		if werr := c.writeGitLabReport(c.errorReport()); werr != nil {
+			*err = werr
+		}
+	}
+}
+
 type panicError struct {
 	Err error
 }
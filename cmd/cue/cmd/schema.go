@@ -0,0 +1,79 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"regexp"
+
+	"cuelang.org/go/cue"
+)
+
+// schemaImportRE matches --schema values of the form
+// importpath@vN[:pkgname]:expression.
+var schemaImportRE = regexp.MustCompile(`^([^\s:@]+@v[0-9]+(?::[\pL_][\pL\pN_]*)?):(.+)$`)
+
+// splitSchemaImport splits a --schema flag value referring to a schema in a
+// module registry into the import path of the package holding the schema
+// and the expression selecting the schema within that package.
+//
+// A package qualifier may follow the major version, as in
+// foo.com/bar@v0:pkg:#Schema.
+func splitSchemaImport(s string) (importPath, expr string, ok bool) {
+	m := schemaImportRE.FindStringSubmatch(s)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// loadRegistrySchema loads the package holding the schema selected with the
+// --schema flag from the registry and returns the schema.
+func (p *buildPlan) loadRegistrySchema() (cue.Value, error) {
+	b, err := loadRegistryPackage(p.schemaImport, "", p.cfg.loadCfg)
+	if err != nil {
+		return cue.Value{}, err
+	}
+	inst := p.cmd.ctx.BuildInstance(b)
+	if err := inst.Err(); err != nil {
+		return cue.Value{}, err
+	}
+	p.schemaInst = b
+	v := p.cmd.ctx.BuildExpr(p.schema,
+		cue.InferBuiltins(true),
+		cue.Scope(inst))
+	// Note that we don't check v.Err as we don't care about
+	// incomplete errors.
+	if err := v.Validate(); err != nil {
+		return cue.Value{}, err
+	}
+	return v, nil
+}
+
+// buildSchema builds the schema against which data files are checked with
+// ctx, which allows data files to be checked with contexts other than that
+// of the command.
+func (p *buildPlan) buildSchema(ctx *cue.Context) (cue.Value, error) {
+	v := ctx.BuildInstance(p.schemaInst)
+	if p.schema == nil {
+		return v, nil
+	}
+	v = ctx.BuildExpr(p.schema,
+		cue.InferBuiltins(true),
+		cue.Scope(v))
+	if err := v.Validate(); err != nil {
+		return cue.Value{}, err
+	}
+	return v, nil
+}
@@ -18,8 +18,10 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/rand"
 	"fmt"
 	"io/fs"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
@@ -37,12 +39,15 @@ import (
 	"github.com/rogpeppe/go-internal/goproxytest"
 	"github.com/rogpeppe/go-internal/gotooltest"
 	"github.com/rogpeppe/go-internal/testscript"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
 	"golang.org/x/tools/txtar"
 
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/registrytest"
+	"cuelang.org/go/internal/schemaregistry/schemaregistrytest"
+	"cuelang.org/go/mod/modregistrytest"
 )
 
 const (
@@ -124,7 +129,7 @@ func TestScript(t *testing.T) {
 				if neg {
 					usage()
 				}
-				var auth *registrytest.AuthConfig
+				var auth *modregistrytest.AuthConfig
 				if len(args) > 0 && strings.HasPrefix(args[0], "-") {
 					userPass, ok := strings.CutPrefix(args[0], "-auth=")
 					if !ok {
@@ -134,7 +139,7 @@ func TestScript(t *testing.T) {
 					if !ok {
 						usage()
 					}
-					auth = &registrytest.AuthConfig{
+					auth = &modregistrytest.AuthConfig{
 						Username: user,
 						Password: pass,
 					}
@@ -144,11 +149,49 @@ func TestScript(t *testing.T) {
 					usage()
 				}
 
-				srv := httptest.NewServer(registrytest.AuthHandler(ociserver.New(ocimem.New(), nil), auth))
+				srv := httptest.NewServer(modregistrytest.AuthHandler(ociserver.New(ocimem.New(), nil), auth))
 				u, _ := url.Parse(srv.URL)
 				ts.Setenv(args[0], u.Host)
 				ts.Defer(srv.Close)
 			},
+			// memschemaregistry starts an in-memory Kafka schema registry and
+			// sets the argument environment variable name to its URL.
+			"memschemaregistry": func(ts *testscript.TestScript, neg bool, args []string) {
+				if neg || len(args) != 1 {
+					ts.Fatalf("usage: memschemaregistry <envvar-name>")
+				}
+				srv := httptest.NewServer(schemaregistrytest.New())
+				ts.Setenv(args[0], srv.URL)
+				ts.Defer(srv.Close)
+			},
+			// fileserver starts an HTTP server serving the files in the
+			// given directory and sets the argument environment variable
+			// name to its URL.
+			"fileserver": func(ts *testscript.TestScript, neg bool, args []string) {
+				if neg || len(args) != 2 {
+					ts.Fatalf("usage: fileserver <envvar-name> <dir>")
+				}
+				srv := httptest.NewServer(http.FileServer(http.Dir(ts.MkAbs(args[1]))))
+				ts.Setenv(args[0], srv.URL)
+				ts.Defer(srv.Close)
+			},
+			// sumdb starts a checksum database serving the records held in
+			// files named <module path>@<version> in the given directory,
+			// and sets the argument environment variable name to its
+			// configuration, as expected by $CUE_SUMDB.
+			"sumdb": func(ts *testscript.TestScript, neg bool, args []string) {
+				if neg || len(args) != 2 {
+					ts.Fatalf("usage: sumdb <envvar-name> <dir>")
+				}
+				dir := ts.MkAbs(args[1])
+				skey, vkey, err := note.GenerateKey(rand.Reader, "sum.example.com")
+				ts.Check(err)
+				srv := httptest.NewServer(sumdb.NewServer(sumdb.NewTestServer(skey, func(path, vers string) ([]byte, error) {
+					return os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)+"@"+vers))
+				})))
+				ts.Setenv(args[0], vkey+" "+srv.URL)
+				ts.Defer(srv.Close)
+			},
 		},
 		Setup: func(e *testscript.Env) error {
 			// If a testscript loads CUE packages but forgot to set up a cue.mod,
@@ -205,7 +248,7 @@ func TestScript(t *testing.T) {
 				if data, err := os.ReadFile(filepath.Join(e.WorkDir, "_registry"+regID+"_prefix")); err == nil {
 					prefix = strings.TrimSpace(string(data))
 				}
-				reg, err := registrytest.New(os.DirFS(registryDir), prefix)
+				reg, err := modregistrytest.New(os.DirFS(registryDir), prefix)
 				if err != nil {
 					return fmt.Errorf("cannot start test registry server: %v", err)
 				}
@@ -0,0 +1,159 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/tools/flow"
+)
+
+// writeTasks maps the kinds of tasks that write to the file system to the
+// field holding the path they write to.
+var writeTasks = map[string]string{
+	"tool/file.Append":    "filename",
+	"tool/file.Create":    "filename",
+	"tool/file.Mkdir":     "path",
+	"tool/file.MkdirTemp": "dir",
+	"tool/file.RemoveAll": "path",
+}
+
+// A taskPolicy restricts the tasks a custom command may run, as configured
+// by the --allow-tasks and --write-dir flags.
+type taskPolicy struct {
+	root cue.Path
+
+	// allow holds the patterns of allowed task kinds, or nil if all kinds
+	// are allowed.
+	allow []string
+
+	// writeDir is the directory, with symbolic links resolved, to which
+	// file writes are restricted, or "" if they are not restricted.
+	writeDir string
+	dirFlag  string
+}
+
+// newTaskPolicy returns the policy set by the flags of cmd for commands
+// defined under root, or nil if the flags set no restrictions.
+func newTaskPolicy(cmd *Command, root cue.Path) (*taskPolicy, error) {
+	p := &taskPolicy{root: root}
+	if cmd.Flags().Changed(string(flagAllowTasks)) {
+		p.allow = []string{}
+		for _, s := range flagAllowTasks.StringSlice(cmd) {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			pattern := s
+			if !strings.ContainsAny(s, "/.") {
+				pattern = "tool/" + s + ".*"
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid --%s pattern %q: %v", flagAllowTasks, s, err)
+			}
+			p.allow = append(p.allow, pattern)
+		}
+	}
+	if dir := flagWriteDir.String(cmd); dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %v", flagWriteDir, err)
+		}
+		p.writeDir = resolvePath(abs)
+		p.dirFlag = dir
+	}
+	if p.allow == nil && p.writeDir == "" {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// check reports an error if the policy does not allow t to run. Paths that
+// are not yet known are not checked, as t is checked again before it runs.
+func (p *taskPolicy) check(t *flow.Task) error {
+	v := t.Value()
+	kind, err := taskKind(v)
+	if err != nil {
+		return err
+	}
+	name := taskName(p.root, t)
+	if p.allow != nil && !p.allowed(kind) {
+		return errors.Newf(v.Pos(), "task %s: %s not allowed by --%s", name, kind, flagAllowTasks)
+	}
+	field, ok := writeTasks[kind]
+	if p.writeDir == "" || !ok {
+		return nil
+	}
+	f := v.LookupPath(cue.MakePath(cue.Str(field)))
+	file := ""
+	switch {
+	case f.Exists():
+		if file, err = f.String(); err != nil {
+			return nil
+		}
+	case kind != "tool/file.MkdirTemp":
+		return nil
+	}
+	if file == "" && kind == "tool/file.MkdirTemp" {
+		file = os.TempDir()
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return errors.Wrapf(err, f.Pos(), "task %s", name)
+	}
+	rel, err := filepath.Rel(p.writeDir, resolvePath(abs))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		pos := f.Pos()
+		if !pos.IsValid() {
+			pos = v.Pos()
+		}
+		return errors.Newf(pos, "task %s: cannot write to %q: outside of --%s %q", name, file, flagWriteDir, p.dirFlag)
+	}
+	return nil
+}
+
+func (p *taskPolicy) allowed(kind string) bool {
+	for _, pattern := range p.allow {
+		if ok, _ := path.Match(pattern, kind); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute path file with the symbolic links of its
+// longest existing prefix resolved, so that links cannot be used to escape
+// a directory.
+func resolvePath(file string) string {
+	file = filepath.Clean(file)
+	dir, rest := file, ""
+	for {
+		if r, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(r, rest)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return file
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+		dir = parent
+	}
+}
@@ -15,15 +15,20 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/load"
 	"cuelang.org/go/internal/diff"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
 	"cuelang.org/go/tools/trim"
 )
 
@@ -52,6 +57,15 @@ Removal is on a best effort basis. Some caveats:
   remove fields.
 - There is currently no verification step: manual verification is required.
 
+Data files
+
+JSON and YAML files that are passed on the command line along with a
+package are merged into that package and trimmed as well. Fields in these
+files that are implied by the package, including by packages it imports,
+are removed and the files are written back in their original format.
+Comments and formatting of trimmed data files are not preserved, and
+files containing multiple documents are not supported.
+
 Examples:
 
 	$ cat <<EOF > foo.cue
@@ -96,6 +110,10 @@ func runTrim(cmd *Command, args []string) error {
 	if binst == nil {
 		return nil
 	}
+	binst, data, err := mergeDataFiles(binst, nil)
+	if err != nil {
+		return err
+	}
 	instances := buildInstances(cmd, binst, false)
 
 	dst := flagOutFile.String(cmd)
@@ -119,7 +137,9 @@ func runTrim(cmd *Command, args []string) error {
 		}
 
 		for _, f := range inst.Files {
-			overlay[f.Filename] = load.FromFile(f)
+			if data[f] == nil {
+				overlay[f.Filename] = load.FromFile(f)
+			}
 		}
 
 	}
@@ -130,7 +150,11 @@ func runTrim(cmd *Command, args []string) error {
 	}
 	cfg := *defCfg.loadCfg
 	cfg.Overlay = overlay
-	tinsts := buildInstances(cmd, load.Instances(args, &cfg), false)
+	tbinst, _, err := mergeDataFiles(load.Instances(args, &cfg), data)
+	if err != nil {
+		return err
+	}
+	tinsts := buildInstances(cmd, tbinst, false)
 	if len(tinsts) != len(binst) {
 		return errors.New("unexpected number of new instances")
 	}
@@ -159,7 +183,12 @@ func runTrim(cmd *Command, args []string) error {
 				opts = append(opts, format.Simplify())
 			}
 
-			b, err := format.Node(f, opts...)
+			var b []byte
+			if df := data[f]; df != nil {
+				b, err = encodeDataFile(df, f)
+			} else {
+				b, err = format.Node(f, opts...)
+			}
 			if err != nil {
 				return fmt.Errorf("error formatting file: %v", err)
 			}
@@ -182,3 +211,88 @@ func runTrim(cmd *Command, args []string) error {
 	}
 	return nil
 }
+
+// mergeDataFiles adds the JSON and YAML files specified on the command line
+// to the package instance with which they are merged, so that they are
+// trimmed along with the CUE files of that package. It returns the
+// instances without the instance holding just the data files, and a map
+// from the syntax of each added data file to its original file.
+//
+// If prev is not nil, the previously decoded syntax for each file is reused
+// instead of decoding the file again.
+func mergeDataFiles(binst []*build.Instance, prev map[*ast.File]*build.File) ([]*build.Instance, map[*ast.File]*build.File, error) {
+	var pkgs []*build.Instance
+	var files []*build.File
+	for _, b := range binst {
+		if b.User {
+			files = append(files, b.OrphanedFiles...)
+			if len(b.Files) == 0 {
+				continue
+			}
+		}
+		pkgs = append(pkgs, b)
+	}
+	if len(files) == 0 {
+		return binst, nil, nil
+	}
+	if len(pkgs) != 1 {
+		return nil, nil, fmt.Errorf("data files can only be trimmed along with a single package")
+	}
+
+	reuse := map[string]*ast.File{}
+	for f, bf := range prev {
+		reuse[bf.Filename] = f
+	}
+
+	data := map[*ast.File]*build.File{}
+	for _, bf := range files {
+		switch bf.Encoding {
+		case build.JSON, build.YAML:
+		default:
+			return nil, nil, fmt.Errorf("cannot trim %s: unsupported encoding %q", bf.Filename, bf.Encoding)
+		}
+		f := reuse[bf.Filename]
+		if f == nil {
+			d := encoding.NewDecoder(bf, &encoding.Config{})
+			f = d.File()
+			f.Filename = bf.Filename
+			if d.Next(); !d.Done() {
+				d.Close()
+				return nil, nil, fmt.Errorf("cannot trim %s: multiple documents not supported", bf.Filename)
+			}
+			err := d.Err()
+			d.Close()
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := pkgs[0].AddSyntax(f); err != nil {
+			return nil, nil, err
+		}
+		data[f] = bf
+	}
+	return pkgs, data, nil
+}
+
+// encodeDataFile encodes the trimmed syntax f of data file bf in the
+// original encoding of bf.
+func encodeDataFile(bf *build.File, f *ast.File) ([]byte, error) {
+	var buf bytes.Buffer
+	e, err := encoding.NewEncoder(&build.File{
+		Filename: bf.Filename,
+		Encoding: bf.Encoding,
+	}, &encoding.Config{
+		Mode: filetypes.Export,
+		Out:  &buf,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := e.EncodeFile(f); err != nil {
+		return nil, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -15,11 +15,28 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"golang.org/x/text/message"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/tools/bulk"
+	"cuelang.org/go/tools/cachedir"
+	"cuelang.org/go/tools/compat"
+	"cuelang.org/go/tools/coverage"
+	"cuelang.org/go/tools/deprecation"
+	"cuelang.org/go/tools/examples"
+	"cuelang.org/go/tools/ignore"
 )
 
 const vetDoc = `vet validates CUE and other data files
@@ -59,6 +76,145 @@ Examples:
   cue vet translations/*.yaml foo.cue -d '#Translation'
 
 If more than one expression is given, all must match all values.
+
+
+Checking many documents
+
+Vet stops at the first document that does not match the schema. The
+--each flag instead validates every document of the data files
+independently, such as the records of an NDJSON file or the documents
+of a multi-document YAML file, and reports for each whether it passes,
+along with its errors. The documents are validated concurrently, but
+reported in order. A summary of the number of valid and invalid
+documents and of the most frequent errors follows. Vet exits with a
+non-zero status if any document is invalid.
+
+  # Check all events against #Event:
+  cue vet --each events.ndjson schema.cue -d '#Event'
+
+
+Reporting schema coverage
+
+When checking non-CUE files, the --coverage flag reports which parts of
+the schema were never exercised by any of the data: fields that are
+never set and branches of disjunctions that never match. This helps to
+find parts of a schema that are dead or lack test data. The report is
+written to standard output, either as text (the default) or, with
+--coverage=json, as JSON listing every field and disjunct along with
+the number of values that exercised it.
+
+  # Report the parts of #Config not covered by the test data:
+  cue vet testdata/*.yaml schema.cue -d '#Config' --coverage
+
+
+Checking schema compatibility
+
+With the --compat flag, vet compares two versions of a schema package,
+given as the old and the new version, instead of validating them. Each
+definition of either version is classified as
+
+  equivalent  both versions accept the same values
+  backward    the new version accepts all values of the old version
+  forward     the old version accepts all values of the new version
+  breaking    neither version accepts all values of the other
+
+A definition that was added is backward compatible, as no existing
+data uses it, and one that was removed is forward compatible. Closed
+structs and fields that became required are taken into account.
+
+The changes are written to standard output, as text (the default) or,
+with --compat=json, as JSON for use in release checks. Vet exits with
+a non-zero status if the new version is not backward compatible.
+
+  # Compare the schema in the working tree with a previous release:
+  cue vet --compat ./release/v1 ./schema
+
+
+Reporting incomplete values
+
+With -c, vet stops reporting values that are not concrete after the
+first few. The --report flag, which implies -c, instead lists every
+regular field and list element that is not concrete, along with the
+constraint that remains for it, which helps when filling out a large
+configuration against a schema. Other errors are reported first.
+
+  cue vet --report ./config
+
+
+Caching results
+
+With --cache, vet records the packages that pass, keyed by a hash of
+the contents of their files and those of the packages they import,
+the module file, the version of cue, and the flags that affect
+validation. Packages that have not changed since they last passed are
+skipped, which speeds up repeated runs over large repositories.
+Results are stored in the vet directory of $CUE_CACHE_DIR, which
+defaults to the cue directory of the user's cache directory. Use -v
+to list the packages that are skipped. The cache is not used with -T,
+as injected system variables may change between runs.
+
+  cue vet --cache ./...
+
+
+Checking examples
+
+The --examples flag additionally validates the examples that the
+loaded packages provide for their definitions. An example is either
+the contents of an @example attribute of a definition, or an element
+of the list in the examples field of a struct definition:
+
+  #Port: int & >0 & <65536 @example(8080)
+
+  #Endpoint: {
+      host: string
+      port: #Port
+
+      examples: [{host: "localhost", port: 8080}]
+  }
+
+Each example must be concrete when unified with its definition.
+
+
+Reporting deprecated fields
+
+Vet warns about fields that are set even though a schema marks them as
+deprecated with a @deprecated attribute. The attribute may hold a message
+and the path of the field that replaces the deprecated one:
+
+  #Service: {
+      replicas?: int @deprecated(replacement=scale.replicas)
+      scale?: replicas?: int
+      legacy?: bool @deprecated("no longer has any effect")
+  }
+
+Each deprecated field is reported once, listing all the places where it
+is set. Warnings do not cause vet to fail, unless --strict-deprecations is
+given, which turns them into errors.
+
+  cue vet --strict-deprecations ./...
+
+
+Ignoring checks
+
+An ignore directive exempts a declaration, and the declarations nested
+within it, from one of the checks of vet. It is a comment in the doc
+comment of the declaration, or on its line, of the form
+
+  //cue:ignore <rule> -- <reason>
+
+where rule is deprecated, to omit the warnings about the deprecated
+fields set within the declaration, or examples, to skip the validation
+of the examples given within it. The reason is required, so that each
+exemption is accounted for:
+
+  //cue:ignore deprecated -- needs scale.replicas in all clusters
+  replicas: 3
+
+Malformed directives and directives for other rules are errors. The
+--list-ignores flag lists the ignore directives of the loaded packages,
+along with their reasons, instead of validating them.
+
+  cue vet --list-ignores ./...
 `
 
 func newVetCmd(c *Command) *cobra.Command {
@@ -74,6 +230,24 @@ func newVetCmd(c *Command) *cobra.Command {
 
 	cmd.Flags().BoolP(string(flagConcrete), "c", false,
 		"require the evaluation to be concrete")
+	cmd.Flags().Bool(string(flagReport), false,
+		"report all values that are not concrete; implies -c")
+	cmd.Flags().Bool(string(flagExamples), false,
+		"validate the examples of definitions")
+	cmd.Flags().Bool(string(flagCache), false,
+		"skip packages whose inputs are unchanged since they last passed")
+	cmd.Flags().String(string(flagCoverage), "",
+		"report schema coverage of data files as text or json")
+	cmd.Flags().Lookup(string(flagCoverage)).NoOptDefVal = "text"
+	cmd.Flags().String(string(flagCompat), "",
+		"compare the compatibility of two schema packages, reported as text or json")
+	cmd.Flags().Lookup(string(flagCompat)).NoOptDefVal = "text"
+	cmd.Flags().Bool(string(flagStrictDeprecations), false,
+		"report uses of deprecated fields as errors instead of warnings")
+	cmd.Flags().Bool(string(flagEach), false,
+		"validate data documents concurrently and report the result of each")
+	cmd.Flags().Bool(string(flagListIgnores), false,
+		"list the ignore directives of the loaded packages instead of validating them")
 
 	return cmd
 }
@@ -87,64 +261,251 @@ func newVetCmd(c *Command) *cobra.Command {
 // TODO: allow unrooted schema, such as JSON schema to compare against
 // other values.
 func doVet(cmd *Command, args []string) error {
+	if format := flagCompat.String(cmd); format != "" {
+		return vetCompat(cmd, args, format)
+	}
+
 	b, err := parseArgs(cmd, args, &config{
 		noMerge: true,
 	})
 	exitOnErr(cmd, err, true)
 
+	ignores, ignoreErrs := loadIgnores(b)
+	if flagListIgnores.Bool(cmd) {
+		exitOnErr(cmd, ignoreErrs, true)
+		listIgnores(cmd, ignores)
+		return nil
+	}
+	exitOnErr(cmd, ignoreErrs, false)
+
 	// Go into a special vet mode if the user explicitly specified non-cue
 	// files on the command line.
 	// TODO: unify these two modes.
 	if len(b.orphaned) > 0 {
-		vetFiles(cmd, b)
+		if flagEach.Bool(cmd) {
+			return vetEach(cmd, b)
+		}
+		vetFiles(cmd, b, ignores)
 		return nil
 	}
+	if flagCoverage.String(cmd) != "" {
+		return errors.Newf(token.NoPos, "--coverage requires data files to check")
+	}
+	if flagEach.Bool(cmd) {
+		return errors.Newf(token.NoPos, "--each requires data files to check")
+	}
 
 	shown := false
+	concrete := true
+	hasFlag := false
+	if flag := cmd.Flag(string(flagConcrete)); flag != nil {
+		hasFlag = flag.Changed
+		if hasFlag {
+			concrete = flagConcrete.Bool(cmd)
+		}
+	}
+	report := flagReport.Bool(cmd)
+	if report {
+		concrete, hasFlag = true, true
+	}
+	showIncomplete := func() {
+		if !shown {
+			shown = true
+			p := message.NewPrinter(getLang())
+			_, _ = p.Fprintln(cmd.Stderr(),
+				"some instances are incomplete; use the -c flag to show errors or suppress this message")
+		}
+	}
 
+	// With --cache, skip the packages whose inputs did not change since
+	// they last passed. Injecting system variables makes the outcome
+	// depend on more than the inputs, so disables the cache.
+	var cache *resultCache
+	var keys []string
+	if flagCache.Bool(cmd) && b.instance == nil && !flagInjectVars.Bool(cmd) {
+		cache, err = newResultCache(cmd, cachedir.Vet)
+		exitOnErr(cmd, err, true)
+		var insts []*build.Instance
+		for _, inst := range b.insts {
+			key := cache.key(nil, inst)
+			data, ok := cache.lookup(key)
+			r := vetCacheResult(strings.TrimSpace(string(data)))
+			switch {
+			case !ok || (r != vetCacheOK && r != vetCacheIncomplete):
+				ok = false
+				insts = append(insts, inst)
+				keys = append(keys, key)
+			case r == vetCacheIncomplete && !hasFlag:
+				showIncomplete()
+			}
+			if ok && flagVerbose.Bool(cmd) {
+				fmt.Fprintf(cmd.OutOrStderr(), "skipping %s: unchanged\n", inst.ImportPath)
+			}
+		}
+		if len(insts) == 0 {
+			return nil
+		}
+		b.insts = insts
+	}
+
+	deprecated := deprecation.NewChecker()
 	iter := b.instances()
 	defer iter.close()
-	for iter.scan() {
+	for i := 0; iter.scan(); i++ {
 		v := iter.value()
 		// TODO: use ImportPath or some other sanitized path.
 
-		concrete := true
-		hasFlag := false
-		if flag := cmd.Flag(string(flagConcrete)); flag != nil {
-			hasFlag = flag.Changed
-			if hasFlag {
-				concrete = flagConcrete.Bool(cmd)
-			}
-		}
 		opt := []cue.Option{
 			cue.Attributes(true),
 			cue.Definitions(true),
 			cue.Hidden(true),
 		}
-		w := cmd.Stderr()
-		err := v.Validate(append(opt, cue.Concrete(concrete))...)
+		result := vetCacheOK
+		var err error
+		if report {
+			// Report errors first, and only then all values that are
+			// not concrete.
+			if err = v.Validate(append(opt, cue.Concrete(false))...); err == nil {
+				err = incompleteValues(v)
+			}
+		} else {
+			err = v.Validate(append(opt, cue.Concrete(concrete))...)
+		}
 		if err != nil && !hasFlag {
 			err = v.Validate(append(opt, cue.Concrete(false))...)
-			if !shown && err == nil {
-				shown = true
-				p := message.NewPrinter(getLang())
-				_, _ = p.Fprintln(w,
-					"some instances are incomplete; use the -c flag to show errors or suppress this message")
+			if err == nil {
+				result = vetCacheIncomplete
+				showIncomplete()
 			}
 		}
 		exitOnErr(cmd, err, false)
+
+		if flagExamples.Bool(cmd) {
+			exErr := filterIgnored(examples.Validate(v), ignores, "examples")
+			exitOnErr(cmd, exErr, false)
+			if err == nil {
+				err = exErr
+			}
+		}
+		// Packages that use deprecated fields are not cached, so that the
+		// warnings are shown on each run.
+		usesDeprecated := deprecated.Add(v)
+		if cache != nil && err == nil && !usesDeprecated {
+			cache.record(keys[i], []byte(result+"\n"))
+		}
 	}
 	exitOnErr(cmd, iter.err(), true)
+	reportDeprecations(cmd, deprecated, ignores)
 	return nil
 }
 
-func vetFiles(cmd *Command, b *buildPlan) {
+// reportDeprecations reports the uses of deprecated fields found by c, as
+// warnings or, with --strict-deprecations, as errors. Uses within
+// declarations exempt from the deprecated rule are not reported.
+func reportDeprecations(cmd *Command, c *deprecation.Checker, ignores *ignore.Set) {
+	strict := flagStrictDeprecations.Bool(cmd)
+	var errs errors.Error
+	for _, f := range filterIgnoredUses(c.Fields(), ignores) {
+		errs = errors.Append(errs, &deprecationError{f, strict})
+	}
+	if errs == nil {
+		return
+	}
+	if strict {
+		exitOnErr(cmd, errs, false)
+		return
+	}
+	cwd, _ := os.Getwd()
+	errors.Print(cmd.OutOrStderr(), errs, &errors.Config{
+		Cwd:     cwd,
+		ToSlash: inTest,
+	})
+}
+
+// deprecationError reports all the uses of a deprecated field.
+type deprecationError struct {
+	f      *deprecation.Field
+	strict bool
+}
+
+func (e *deprecationError) Position() token.Pos         { return e.f.UsePos[0] }
+func (e *deprecationError) InputPositions() []token.Pos { return e.f.UsePos[1:] }
+func (e *deprecationError) Path() []string              { return nil }
+
+func (e *deprecationError) Msg() (string, []interface{}) {
+	format := "%s"
+	if !e.strict {
+		format = "warning: %s"
+	}
+	if n := len(e.f.Uses); n > 1 {
+		return format + " (used %d times)", []interface{}{e.f.Summary(), n}
+	}
+	return format, []interface{}{e.f.Summary()}
+}
+
+func (e *deprecationError) Error() string {
+	format, args := e.Msg()
+	return fmt.Sprintf(format, args...)
+}
+
+// incompleteValues returns an error listing all values of v that are not
+// concrete, or nil if there are none.
+func incompleteValues(v cue.Value) error {
+	var errs errors.Error
+	for _, w := range v.IncompletePaths() {
+		errs = errors.Append(errs, &incompleteValueError{w})
+	}
+	return errs
+}
+
+// incompleteValueError reports a value that is not concrete, along with the
+// constraint that remains.
+type incompleteValueError struct {
+	v cue.Value
+}
+
+func (e *incompleteValueError) Position() token.Pos         { return e.v.Pos() }
+func (e *incompleteValueError) InputPositions() []token.Pos { return nil }
+
+func (e *incompleteValueError) Path() []string {
+	var path []string
+	for _, sel := range e.v.Path().Selectors() {
+		path = append(path, sel.String())
+	}
+	return path
+}
+
+func (e *incompleteValueError) Msg() (string, []interface{}) {
+	// Print the constraint on a single line.
+	s := strings.Join(strings.Fields(fmt.Sprint(e.v)), " ")
+	return "incomplete value %s", []interface{}{s}
+}
+
+func (e *incompleteValueError) Error() string {
+	format, args := e.Msg()
+	return fmt.Sprintf(format, args...)
+}
+
+func vetFiles(cmd *Command, b *buildPlan, ignores *ignore.Set) {
 	// Use -r type root, instead of -e
 
 	if !b.encConfig.Schema.Exists() {
 		exitOnErr(cmd, errors.New("data files specified without a schema"), true)
 	}
 
+	var cov *coverage.Coverage
+	format := flagCoverage.String(cmd)
+	switch format {
+	case "":
+	case "text", "json":
+		cov = coverage.New(b.encConfig.Schema)
+	default:
+		err := errors.Newf(token.NoPos,
+			"invalid value %q for --coverage: must be text or json", format)
+		exitOnErr(cmd, err, true)
+	}
+
+	deprecated := deprecation.NewChecker()
 	iter := b.instances()
 	defer iter.close()
 	for iter.scan() {
@@ -153,6 +514,173 @@ func vetFiles(cmd *Command, b *buildPlan) {
 		// Always concrete when checking against concrete files.
 		err := v.Validate(cue.Concrete(true))
 		exitOnErr(cmd, err, false)
+		deprecated.Add(v)
+
+		if s, ok := iter.(*streamingIterator); ok && cov != nil {
+			cov.Add(s.data)
+		}
 	}
 	exitOnErr(cmd, iter.err(), false)
+	reportDeprecations(cmd, deprecated, ignores)
+
+	if cov != nil {
+		cwd, _ := os.Getwd()
+		cfg := &coverage.Config{Cwd: cwd, ToSlash: inTest}
+		r := cov.Report()
+		w := cmd.OutOrStdout()
+		var err error
+		if format == "json" {
+			err = r.WriteJSON(w, cfg)
+		} else {
+			err = r.WriteText(w, cfg)
+		}
+		exitOnErr(cmd, err, true)
+	}
+}
+
+// maxTopErrors is the number of most frequent errors listed by vetEach.
+const maxTopErrors = 10
+
+// vetEach validates each document in the data files independently and
+// concurrently, reporting whether each passes, followed by a summary.
+func vetEach(cmd *Command, b *buildPlan) error {
+	if !b.encConfig.Schema.Exists() {
+		return errors.New("data files specified without a schema")
+	}
+	if flagCoverage.String(cmd) != "" {
+		return errors.Newf(token.NoPos, "--each cannot be combined with --coverage")
+	}
+
+	cwd, _ := os.Getwd()
+	w := cmd.OutOrStdout()
+	cfg := &bulk.Config{
+		Schema: b.buildSchema,
+		NewContext: func() *cue.Context {
+			return cmd.newContext()
+		},
+		Report: func(r bulk.Result) {
+			if r.Err == nil {
+				fmt.Fprintf(w, "ok   %s\n", r.Name)
+				return
+			}
+			fmt.Fprintf(w, "FAIL %s\n", r.Name)
+			var buf strings.Builder
+			errors.Print(&buf, r.Err, &errors.Config{
+				Cwd:     cwd,
+				ToSlash: inTest,
+			})
+			for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		},
+	}
+	stats, err := bulk.Validate(cfg, &decoderSource{cwd: cwd, b: b, a: b.orphaned})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\ndocuments: %d valid, %d invalid\n", stats.Valid, stats.Invalid)
+	if top := stats.TopErrors(maxTopErrors); len(top) > 0 {
+		fmt.Fprintln(w, "most frequent errors:")
+		for _, msg := range top {
+			fmt.Fprintf(w, "%6d  %s\n", stats.Errors[msg], msg)
+		}
+	}
+	if stats.Invalid > 0 {
+		return ErrPrintedError
+	}
+	return nil
+}
+
+// decoderSource provides the documents of the data files of a build plan
+// to bulk.Validate.
+type decoderSource struct {
+	cwd string
+	b   *buildPlan
+	a   []*decoderInfo
+	dec *encoding.Decoder
+	n   int // number of documents read from dec
+}
+
+func (s *decoderSource) Next() (bulk.Document, error) {
+	for s.dec == nil || s.dec.Done() {
+		if s.dec != nil {
+			err := s.dec.Err()
+			s.dec.Close()
+			s.dec = nil
+			if err != nil {
+				return bulk.Document{}, err
+			}
+		}
+		if len(s.a) == 0 {
+			return bulk.Document{}, io.EOF
+		}
+		s.dec = s.a[0].dec(s.b)
+		s.a = s.a[1:]
+		s.n = 0
+	}
+	s.n++
+	file := s.dec.Filename()
+	if rel, err := filepath.Rel(s.cwd, file); err == nil && filepath.IsAbs(file) {
+		file = rel
+	}
+	if inTest {
+		file = filepath.ToSlash(file)
+	}
+	doc := bulk.Document{
+		Name: fmt.Sprintf("%s:%d", file, s.n),
+		File: s.dec.File(),
+	}
+	s.dec.Next()
+	return doc, nil
+}
+
+// vetCompat compares the old and new versions of a schema package given
+// by args.
+func vetCompat(cmd *Command, args []string, format string) error {
+	if format != "text" && format != "json" {
+		return errors.Newf(token.NoPos,
+			"invalid value %q for --compat: must be text or json", format)
+	}
+	if len(args) != 2 {
+		return errors.Newf(token.NoPos,
+			"--compat requires two packages: the old and the new version of the schema")
+	}
+	cfg, err := defaultConfig()
+	if err != nil {
+		return err
+	}
+	var values [2]cue.Value
+	for i, arg := range args {
+		insts := load.Instances([]string{arg}, cfg.loadCfg)
+		if len(insts) != 1 {
+			return errors.Newf(token.NoPos, "%s: --compat requires a single package", arg)
+		}
+		if err := insts[0].Err; err != nil {
+			return err
+		}
+		// Both packages are in the same main module, so the context is
+		// not replaced once values[0] is built.
+		cmd.useExperiments(insts...)
+		values[i] = cmd.ctx.BuildInstance(insts[0])
+		if err := values[i].Err(); err != nil {
+			return err
+		}
+	}
+
+	r := compat.Compare(values[0], values[1])
+	w := cmd.OutOrStdout()
+	if format == "json" {
+		err = r.WriteJSON(w)
+	} else {
+		err = r.WriteText(w)
+	}
+	if err != nil {
+		return err
+	}
+	if !r.Compatibility().IsBackward() {
+		return errors.Newf(token.NoPos,
+			"%s is not backward compatible with %s", args[1], args[0])
+	}
+	return nil
 }
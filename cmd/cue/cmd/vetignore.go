@@ -0,0 +1,121 @@
+// Copyright 2026 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/tools/deprecation"
+	"cuelang.org/go/tools/ignore"
+)
+
+// vetRules lists the checks of vet from which ignore directives can
+// exempt declarations.
+var vetRules = []string{"deprecated", "examples"}
+
+// loadIgnores returns the ignore directives of the CUE files of the
+// instances of b and of the packages they import. Malformed directives
+// and directives for unknown rules are reported as errors.
+func loadIgnores(b *buildPlan) (*ignore.Set, errors.Error) {
+	s := ignore.NewSet()
+	var errs errors.Error
+	seen := map[*build.Instance]bool{}
+	var add func(inst *build.Instance)
+	add = func(inst *build.Instance) {
+		if seen[inst] {
+			return
+		}
+		seen[inst] = true
+		for _, f := range inst.Files {
+			errs = errors.Append(errs, s.AddFile(f))
+		}
+		for _, imp := range inst.Imports {
+			add(imp)
+		}
+	}
+	for _, inst := range b.insts {
+		add(inst)
+	}
+	for _, d := range s.Directives() {
+		if !slices.Contains(vetRules, d.Rule) {
+			errs = errors.Append(errs, errors.Newf(d.Pos,
+				"unknown rule %q in ignore directive; known rules are %s",
+				d.Rule, strings.Join(vetRules, ", ")))
+		}
+	}
+	return s, errs
+}
+
+// listIgnores writes the ignore directives of s to the standard output,
+// one per line, along with their reason.
+func listIgnores(cmd *Command, s *ignore.Set) {
+	cwd, _ := os.Getwd()
+	w := cmd.OutOrStdout()
+	for _, d := range s.Directives() {
+		pos := d.Pos.Position()
+		if rel, err := filepath.Rel(cwd, pos.Filename); err == nil {
+			pos.Filename = rel
+		}
+		if inTest {
+			pos.Filename = filepath.ToSlash(pos.Filename)
+		}
+		fmt.Fprintf(w, "%s: %s: %s\n", pos, d.Rule, d.Reason)
+	}
+}
+
+// filterIgnored returns err without the errors positioned within a
+// declaration that is exempt from the given rule.
+func filterIgnored(err error, s *ignore.Set, rule string) error {
+	if err == nil {
+		return nil
+	}
+	var errs errors.Error
+	for _, e := range errors.Errors(err) {
+		if !s.Ignored(rule, e.Position()) {
+			errs = errors.Append(errs, e)
+		}
+	}
+	if errs == nil {
+		return nil
+	}
+	return errs
+}
+
+// filterIgnoredUses returns the deprecated fields of fields with the uses
+// positioned within a declaration exempt from the deprecated rule
+// removed. Fields without any remaining use are omitted.
+func filterIgnoredUses(fields []*deprecation.Field, s *ignore.Set) []*deprecation.Field {
+	var result []*deprecation.Field
+	for _, f := range fields {
+		g := *f
+		g.Uses, g.UsePos = nil, nil
+		for i, pos := range f.UsePos {
+			if !s.Ignored("deprecated", pos) {
+				g.Uses = append(g.Uses, f.Uses[i])
+				g.UsePos = append(g.UsePos, pos)
+			}
+		}
+		if len(g.Uses) > 0 {
+			result = append(result, &g)
+		}
+	}
+	return result
+}
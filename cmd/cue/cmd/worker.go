@@ -0,0 +1,411 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"cuelang.org/go/cue/errors"
+)
+
+// This file implements the persistent worker protocol used by build
+// systems such as Bazel and Buck. When started with --persistent_worker,
+// cue reads work requests from stdin and writes a work response for each
+// of them to stdout, running each request as a regular cue invocation
+// within the same process. This avoids paying the start-up cost of cue
+// for every build action.
+//
+// See https://bazel.build/remote/persistent for a description of the
+// protocol.
+
+const (
+	workerFlag         = "--persistent_worker"
+	workerProtocolFlag = "--worker_protocol="
+)
+
+// workerArgs reports whether args request persistent worker mode. If so, it
+// returns the remaining startup arguments and whether the JSON variant of
+// the protocol should be used.
+func workerArgs(args []string) (startup []string, useJSON, ok bool) {
+	for _, a := range args {
+		switch {
+		case a == workerFlag:
+			ok = true
+		case strings.HasPrefix(a, workerProtocolFlag):
+			useJSON = strings.TrimPrefix(a, workerProtocolFlag) == "json"
+		default:
+			startup = append(startup, a)
+		}
+	}
+	return startup, useJSON, ok
+}
+
+// A workRequest corresponds to the WorkRequest message of the protocol.
+type workRequest struct {
+	Arguments []string    `json:"arguments,omitempty"`
+	Inputs    []workInput `json:"inputs,omitempty"`
+	RequestID int32       `json:"requestId,omitempty"`
+	Cancel    bool        `json:"cancel,omitempty"`
+}
+
+type workInput struct {
+	Path   string `json:"path,omitempty"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// A workResponse corresponds to the WorkResponse message of the protocol.
+type workResponse struct {
+	ExitCode     int32  `json:"exitCode"`
+	Output       string `json:"output,omitempty"`
+	RequestID    int32  `json:"requestId,omitempty"`
+	WasCancelled bool   `json:"wasCancelled,omitempty"`
+}
+
+// cacheableCommands lists the subcommands whose only effects are their
+// output and the file specified with --outfile, so that their results may
+// be replayed from the worker cache.
+var cacheableCommands = map[string]bool{
+	"def":    true,
+	"eval":   true,
+	"export": true,
+	"vet":    true,
+}
+
+// A worker runs work requests and caches their results by a hash of their
+// arguments and inputs.
+type worker struct {
+	startup []string
+	cache   map[string]*workResult
+}
+
+type workResult struct {
+	code    int32
+	output  string
+	outFile string
+	data    []byte
+}
+
+// runWorker serves work requests read from r until r is exhausted,
+// writing responses to w.
+func runWorker(r io.Reader, w io.Writer, startup []string, useJSON bool) error {
+	wk := &worker{
+		startup: startup,
+		cache:   map[string]*workResult{},
+	}
+	br := bufio.NewReader(r)
+	dec := json.NewDecoder(br)
+	enc := json.NewEncoder(w)
+	for {
+		var req workRequest
+		var err error
+		if useJSON {
+			err = dec.Decode(&req)
+		} else {
+			err = readWorkRequest(br, &req)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading work request: %v", err)
+		}
+		if req.Cancel {
+			// Requests are handled sequentially, so by the time a
+			// cancellation is read the request has already completed.
+			continue
+		}
+		resp := wk.run(&req)
+		if useJSON {
+			err = enc.Encode(resp)
+		} else {
+			_, err = w.Write(appendWorkResponse(nil, resp))
+		}
+		if err != nil {
+			return fmt.Errorf("writing work response: %v", err)
+		}
+	}
+}
+
+func (wk *worker) run(req *workRequest) *workResponse {
+	resp := &workResponse{RequestID: req.RequestID}
+	args, err := expandArgFiles(append(wk.startup[:len(wk.startup):len(wk.startup)], req.Arguments...))
+	if err != nil {
+		resp.ExitCode = 1
+		resp.Output = err.Error() + "\n"
+		return resp
+	}
+
+	key := wk.cacheKey(args, req.Inputs)
+	if res := wk.cache[key]; res != nil {
+		if res.outFile == "" || os.WriteFile(res.outFile, res.data, 0o666) == nil {
+			resp.ExitCode = res.code
+			resp.Output = res.output
+			return resp
+		}
+	}
+
+	var buf bytes.Buffer
+	resp.ExitCode = runWorkArgs(args, &buf)
+	resp.Output = buf.String()
+
+	if key != "" {
+		res := &workResult{code: resp.ExitCode, output: resp.Output}
+		if res.outFile = outFileArg(args); res.outFile != "" {
+			res.data, err = os.ReadFile(res.outFile)
+		}
+		if err == nil {
+			wk.cache[key] = res
+		}
+	}
+	return resp
+}
+
+// runWorkArgs runs a single cue invocation with the given arguments,
+// writing all output to w, and returns its exit code.
+func runWorkArgs(args []string, w io.Writer) int32 {
+	cwd, _ := os.Getwd()
+	cmd, _ := New(args)
+	cmd.SetOutput(w)
+	cmd.root.SetErr(w)
+	cmd.SetInput(strings.NewReader(""))
+	if err := cmd.Run(context.Background()); err != nil {
+		if err != ErrPrintedError {
+			errors.Print(w, err, &errors.Config{
+				Cwd:     cwd,
+				ToSlash: inTest,
+			})
+		}
+		return 1
+	}
+	return 0
+}
+
+// cacheKey returns a key identifying the result of running args with the
+// given inputs, or "" if the result may not be cached.
+func (wk *worker) cacheKey(args []string, inputs []workInput) string {
+	if len(inputs) == 0 || len(args) == 0 || !cacheableCommands[args[0]] {
+		return ""
+	}
+	inputs = append([]workInput(nil), inputs...)
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].Path < inputs[j].Path })
+
+	h := sha256.New()
+	cwd, _ := os.Getwd()
+	fmt.Fprintf(h, "%q\n", cwd)
+	for _, a := range args {
+		fmt.Fprintf(h, "%q\n", a)
+	}
+	for _, in := range inputs {
+		if in.Digest == "" {
+			return ""
+		}
+		fmt.Fprintf(h, "%q %q\n", in.Path, in.Digest)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// expandArgFiles replaces arguments of the form @file with the lines of
+// file, as build systems commonly pass long argument lists this way.
+func expandArgFiles(args []string) ([]string, error) {
+	var a []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "@") || arg == "@" {
+			a = append(a, arg)
+			continue
+		}
+		b, err := os.ReadFile(arg[1:])
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if line != "" {
+				a = append(a, line)
+			}
+		}
+	}
+	return a, nil
+}
+
+// outFileArg returns the file specified with the --outfile flag in args, if
+// any, ignoring output to stdout.
+func outFileArg(args []string) string {
+	for i, a := range args {
+		var file string
+		switch {
+		case a == "-o" || a == "--outfile":
+			if i+1 < len(args) {
+				file = args[i+1]
+			}
+		case strings.HasPrefix(a, "--outfile="):
+			file = strings.TrimPrefix(a, "--outfile=")
+		case strings.HasPrefix(a, "-o") && len(a) > 2:
+			file = a[2:]
+		}
+		if file != "" {
+			// Strip a file type qualifier, as in json:out.txt.
+			if i := strings.LastIndexByte(file, ':'); i >= 0 {
+				file = file[i+1:]
+			}
+			if file != "-" {
+				return file
+			}
+		}
+	}
+	return ""
+}
+
+// readWorkRequest reads a length-delimited WorkRequest protocol buffer
+// message from r.
+func readWorkRequest(r *bufio.Reader, req *workRequest) error {
+	n, err := binaryReadUvarint(r)
+	if err != nil {
+		return err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return err
+	}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch {
+		case num == 1 && typ == protowire.BytesType: // arguments
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			req.Arguments = append(req.Arguments, string(v))
+			b = b[n:]
+		case num == 2 && typ == protowire.BytesType: // inputs
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			in, err := parseWorkInput(v)
+			if err != nil {
+				return err
+			}
+			req.Inputs = append(req.Inputs, in)
+			b = b[n:]
+		case num == 3 && typ == protowire.VarintType: // request_id
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			req.RequestID = int32(v)
+			b = b[n:]
+		case num == 4 && typ == protowire.VarintType: // cancel
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			req.Cancel = v != 0
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
+
+func parseWorkInput(b []byte) (in workInput, err error) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return in, protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ != protowire.BytesType || (num != 1 && num != 2) {
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return in, protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return in, protowire.ParseError(n)
+		}
+		b = b[n:]
+		if num == 1 {
+			in.Path = string(v)
+		} else {
+			in.Digest = hex.EncodeToString(v)
+		}
+	}
+	return in, nil
+}
+
+// appendWorkResponse appends resp as a length-delimited WorkResponse
+// protocol buffer message to b.
+func appendWorkResponse(b []byte, resp *workResponse) []byte {
+	var m []byte
+	if resp.ExitCode != 0 {
+		m = protowire.AppendTag(m, 1, protowire.VarintType)
+		m = protowire.AppendVarint(m, uint64(resp.ExitCode))
+	}
+	if resp.Output != "" {
+		m = protowire.AppendTag(m, 2, protowire.BytesType)
+		m = protowire.AppendString(m, resp.Output)
+	}
+	if resp.RequestID != 0 {
+		m = protowire.AppendTag(m, 3, protowire.VarintType)
+		m = protowire.AppendVarint(m, uint64(resp.RequestID))
+	}
+	if resp.WasCancelled {
+		m = protowire.AppendTag(m, 4, protowire.VarintType)
+		m = protowire.AppendVarint(m, 1)
+	}
+	b = protowire.AppendVarint(b, uint64(len(m)))
+	return append(b, m...)
+}
+
+func binaryReadUvarint(r *bufio.Reader) (uint64, error) {
+	var x uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		c, err := r.ReadByte()
+		if err != nil {
+			if shift > 0 && err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		x |= uint64(c&0x7f) << shift
+		if c < 0x80 {
+			return x, nil
+		}
+	}
+	return 0, fmt.Errorf("varint overflow")
+}
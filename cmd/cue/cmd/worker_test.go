@@ -0,0 +1,156 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestWorkerProtocol(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "x.cue")
+	qt.Assert(t, qt.IsNil(os.WriteFile(file, []byte("a: 1\n"), 0o666)))
+
+	var in bytes.Buffer
+	in.Write(appendWorkRequest(nil, []string{"export", file}, file, "aa", 7))
+	in.Write(appendWorkRequest(nil, []string{"export", file, "--out", "yaml"}, "", "", 8))
+
+	var out bytes.Buffer
+	err := runWorker(&in, &out, nil, false)
+	qt.Assert(t, qt.IsNil(err))
+
+	r := bufio.NewReader(&out)
+	resp := readWorkResponse(t, r)
+	qt.Assert(t, qt.DeepEquals(resp, workResponse{
+		RequestID: 7,
+		Output:    "{\n    \"a\": 1\n}\n",
+	}))
+	resp = readWorkResponse(t, r)
+	qt.Assert(t, qt.DeepEquals(resp, workResponse{
+		RequestID: 8,
+		Output:    "a: 1\n",
+	}))
+	_, err = r.ReadByte()
+	qt.Assert(t, qt.IsNotNil(err))
+}
+
+func TestWorkerCache(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "x.cue")
+	outFile := filepath.Join(dir, "out.json")
+	write := func(s string) {
+		qt.Assert(t, qt.IsNil(os.WriteFile(file, []byte(s), 0o666)))
+	}
+	wk := &worker{cache: map[string]*workResult{}}
+	run := func(digest string, args ...string) *workResponse {
+		return wk.run(&workRequest{
+			Arguments: append([]string{"export", file}, args...),
+			Inputs:    []workInput{{Path: file, Digest: digest}},
+		})
+	}
+
+	write("a: 1\n")
+	qt.Assert(t, qt.Equals(run("aa").Output, "{\n    \"a\": 1\n}\n"))
+	qt.Assert(t, qt.Equals(run("aa", "-o", outFile).ExitCode, 0))
+
+	// The file changes without its digest changing, so the cached results
+	// are reported, and the output file is restored.
+	write("a: 2\n")
+	qt.Assert(t, qt.IsNil(os.Remove(outFile)))
+	qt.Assert(t, qt.Equals(run("aa").Output, "{\n    \"a\": 1\n}\n"))
+	qt.Assert(t, qt.Equals(run("aa", "-o", outFile).ExitCode, 0))
+	b, err := os.ReadFile(outFile)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(b), "{\n    \"a\": 1\n}\n"))
+
+	qt.Assert(t, qt.Equals(run("bb").Output, "{\n    \"a\": 2\n}\n"))
+
+	// Inputs without a digest are never cached.
+	write("a: 3\n")
+	qt.Assert(t, qt.Equals(run("").Output, "{\n    \"a\": 3\n}\n"))
+	write("a: 4\n")
+	qt.Assert(t, qt.Equals(run("").Output, "{\n    \"a\": 4\n}\n"))
+}
+
+func TestWorkerArgs(t *testing.T) {
+	startup, useJSON, ok := workerArgs([]string{"--persistent_worker", "--worker_protocol=json", "-v"})
+	qt.Assert(t, qt.IsTrue(ok))
+	qt.Assert(t, qt.IsTrue(useJSON))
+	qt.Assert(t, qt.DeepEquals(startup, []string{"-v"}))
+
+	_, _, ok = workerArgs([]string{"export", "x.cue"})
+	qt.Assert(t, qt.IsFalse(ok))
+
+	qt.Assert(t, qt.Equals(outFileArg([]string{"export", "-o", "json:x.json"}), "x.json"))
+	qt.Assert(t, qt.Equals(outFileArg([]string{"export", "--outfile=x.yaml"}), "x.yaml"))
+	qt.Assert(t, qt.Equals(outFileArg([]string{"export", "-o", "-"}), ""))
+}
+
+func appendWorkRequest(b []byte, args []string, path, digest string, id int32) []byte {
+	var m []byte
+	for _, a := range args {
+		m = protowire.AppendTag(m, 1, protowire.BytesType)
+		m = protowire.AppendString(m, a)
+	}
+	if path != "" {
+		var in []byte
+		in = protowire.AppendTag(in, 1, protowire.BytesType)
+		in = protowire.AppendString(in, path)
+		in = protowire.AppendTag(in, 2, protowire.BytesType)
+		in = protowire.AppendString(in, digest)
+		m = protowire.AppendTag(m, 2, protowire.BytesType)
+		m = protowire.AppendBytes(m, in)
+	}
+	m = protowire.AppendTag(m, 3, protowire.VarintType)
+	m = protowire.AppendVarint(m, uint64(id))
+	b = protowire.AppendVarint(b, uint64(len(m)))
+	return append(b, m...)
+}
+
+func readWorkResponse(t *testing.T, r *bufio.Reader) (resp workResponse) {
+	n, err := binaryReadUvarint(r)
+	qt.Assert(t, qt.IsNil(err))
+	b := make([]byte, n)
+	_, err = r.Read(b)
+	qt.Assert(t, qt.IsNil(err))
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		switch num {
+		case 1:
+			v, n := protowire.ConsumeVarint(b)
+			resp.ExitCode = int32(v)
+			b = b[n:]
+		case 2:
+			v, n := protowire.ConsumeString(b)
+			resp.Output = v
+			b = b[n:]
+		case 3:
+			v, n := protowire.ConsumeVarint(b)
+			resp.RequestID = int32(v)
+			b = b[n:]
+		default:
+			b = b[protowire.ConsumeFieldValue(num, typ, b):]
+		}
+	}
+	return resp
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js && wasm
+
+// Command cuewasm exposes the CUE evaluator to JavaScript when compiled
+// to WebAssembly:
+//
+//	GOOS=js GOARCH=wasm go build -o cue.wasm ./cmd/cuewasm
+//
+// Once loaded with wasm_exec.js, it defines a global object named cue
+// with the following methods, each of which returns a plain object with
+// the optional fields value (a handle), output (a string), and
+// diagnostics (a list of objects with fields message, filename, line,
+// and column):
+//
+//	cue.compile(source, filename)
+//	cue.compileJSON(data, filename)
+//	cue.unify(value1, value2)
+//	cue.lookupPath(value, path)
+//	cue.validate(value, concrete)
+//	cue.export(value, format) // format is "json", "yaml", or "cue"
+//	cue.free(value)
+//
+// The object also has a version field holding the version of the API,
+// which is incremented when methods are added.
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"cuelang.org/go/internal/hostapi"
+)
+
+const apiVersion = 1
+
+func main() {
+	s := hostapi.NewSession()
+	jsonParse := js.Global().Get("JSON").Get("parse")
+
+	// result converts r to a JavaScript object. Going through JSON keeps
+	// the representation identical to that of the other bindings.
+	result := func(r *hostapi.Result) any {
+		b, err := json.Marshal(r)
+		if err != nil {
+			panic(err)
+		}
+		return jsonParse.Invoke(string(b))
+	}
+	handle := func(v js.Value) hostapi.Handle {
+		if v.Type() != js.TypeNumber {
+			return 0
+		}
+		return hostapi.Handle(v.Int())
+	}
+	str := func(v js.Value) string {
+		if v.Type() != js.TypeString {
+			return ""
+		}
+		return v.String()
+	}
+	arg := func(args []js.Value, i int) js.Value {
+		if i < len(args) {
+			return args[i]
+		}
+		return js.Undefined()
+	}
+
+	funcs := map[string]func(args []js.Value) any{
+		"compile": func(args []js.Value) any {
+			return result(s.Compile(str(arg(args, 0)), str(arg(args, 1))))
+		},
+		"compileJSON": func(args []js.Value) any {
+			return result(s.CompileJSON(str(arg(args, 0)), str(arg(args, 1))))
+		},
+		"unify": func(args []js.Value) any {
+			return result(s.Unify(handle(arg(args, 0)), handle(arg(args, 1))))
+		},
+		"lookupPath": func(args []js.Value) any {
+			return result(s.LookupPath(handle(arg(args, 0)), str(arg(args, 1))))
+		},
+		"validate": func(args []js.Value) any {
+			return result(s.Validate(handle(arg(args, 0)), arg(args, 1).Truthy()))
+		},
+		"export": func(args []js.Value) any {
+			format := str(arg(args, 1))
+			if format == "" {
+				format = "json"
+			}
+			return result(s.Export(handle(arg(args, 0)), format))
+		},
+		"free": func(args []js.Value) any {
+			s.Free(handle(arg(args, 0)))
+			return js.Undefined()
+		},
+	}
+
+	obj := js.Global().Get("Object").New()
+	obj.Set("version", apiVersion)
+	for name, f := range funcs {
+		f := f
+		obj.Set(name, js.FuncOf(func(this js.Value, args []js.Value) any {
+			return f(args)
+		}))
+	}
+	js.Global().Set("cue", obj)
+
+	// Keep the functions available for as long as the page is loaded.
+	select {}
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo
+
+// Command libcue builds a C shared library that exposes the CUE evaluator
+// to programs written in other languages, such as Python, Rust, or Node,
+// without the cost of starting the cue tool for every evaluation:
+//
+//	go build -buildmode=c-shared -o libcue.so ./cmd/libcue
+//
+// This also writes libcue.h, which declares the following functions:
+//
+//	char *cue_compile(char *src, char *filename);
+//	char *cue_compile_json(char *data, char *filename);
+//	char *cue_unify(long long a, long long b);
+//	char *cue_lookup_path(long long v, char *path);
+//	char *cue_validate(long long v, int concrete);
+//	char *cue_export(long long v, char *format);
+//	void cue_free(long long v);
+//	void cue_free_string(char *s);
+//	int cue_api_version(void);
+//
+// Values are referred to by integer handles, which must be released with
+// cue_free. Each function returning a string returns a JSON object with
+// the optional fields "value" (a handle), "output" (a string), and
+// "diagnostics" (a list of objects with the fields "message", "filename",
+// "line", and "column"). The caller owns the returned string and must
+// release it with cue_free_string.
+//
+// The functions may be called from multiple threads; calls are
+// serialized.
+package main
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"encoding/json"
+	"sync"
+	"unsafe"
+
+	"cuelang.org/go/internal/hostapi"
+)
+
+const apiVersion = 1
+
+var (
+	mu      sync.Mutex
+	session = hostapi.NewSession()
+)
+
+// call runs f with the session locked and returns its result as a JSON
+// string allocated in C memory.
+func call(f func(s *hostapi.Session) *hostapi.Result) *C.char {
+	mu.Lock()
+	r := f(session)
+	mu.Unlock()
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		b, _ = json.Marshal(&hostapi.Result{
+			Diagnostics: []hostapi.Diagnostic{{Message: err.Error()}},
+		})
+	}
+	return C.CString(string(b))
+}
+
+//export cue_compile
+func cue_compile(src, filename *C.char) *C.char {
+	s, name := C.GoString(src), C.GoString(filename)
+	return call(func(sess *hostapi.Session) *hostapi.Result {
+		return sess.Compile(s, name)
+	})
+}
+
+//export cue_compile_json
+func cue_compile_json(data, filename *C.char) *C.char {
+	s, name := C.GoString(data), C.GoString(filename)
+	return call(func(sess *hostapi.Session) *hostapi.Result {
+		return sess.CompileJSON(s, name)
+	})
+}
+
+//export cue_unify
+func cue_unify(a, b C.longlong) *C.char {
+	return call(func(sess *hostapi.Session) *hostapi.Result {
+		return sess.Unify(hostapi.Handle(a), hostapi.Handle(b))
+	})
+}
+
+//export cue_lookup_path
+func cue_lookup_path(v C.longlong, path *C.char) *C.char {
+	p := C.GoString(path)
+	return call(func(sess *hostapi.Session) *hostapi.Result {
+		return sess.LookupPath(hostapi.Handle(v), p)
+	})
+}
+
+//export cue_validate
+func cue_validate(v C.longlong, concrete C.int) *C.char {
+	return call(func(sess *hostapi.Session) *hostapi.Result {
+		return sess.Validate(hostapi.Handle(v), concrete != 0)
+	})
+}
+
+//export cue_export
+func cue_export(v C.longlong, format *C.char) *C.char {
+	f := C.GoString(format)
+	if f == "" {
+		f = "json"
+	}
+	return call(func(sess *hostapi.Session) *hostapi.Result {
+		return sess.Export(hostapi.Handle(v), f)
+	})
+}
+
+//export cue_free
+func cue_free(v C.longlong) {
+	mu.Lock()
+	defer mu.Unlock()
+	session.Free(hostapi.Handle(v))
+}
+
+//export cue_free_string
+func cue_free_string(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+//export cue_api_version
+func cue_api_version() C.int {
+	return apiVersion
+}
+
+func main() {}
@@ -42,6 +42,9 @@ const (
 	Protobuf    Encoding = "proto"
 	TextProto   Encoding = "textproto"
 	BinaryProto Encoding = "pb"
+	Tree        Encoding = "tree"
+	Env         Encoding = "env"
+	Flags       Encoding = "flags"
 
 	// TODO:
 	// TOML
@@ -70,6 +73,17 @@ const (
 	JSONSchema   Interpretation = "jsonschema"
 	OpenAPI      Interpretation = "openapi"
 	ProtobufJSON Interpretation = "pb"
+
+	// KubernetesManifest interprets data as a set of Kubernetes objects,
+	// which are emitted as a stream of documents ordered by kind,
+	// namespace and name. Objects may be given as a single object, a list,
+	// a List object with items, or nested within structs.
+	KubernetesManifest Interpretation = "k8smanifest"
+
+	// Patch interprets data as a new version of a document, which is
+	// emitted as a patch that changes a previous version of the document
+	// into it.
+	Patch Interpretation = "patch"
 )
 
 // A Form specifies the form in which a program should be represented.
@@ -81,6 +81,12 @@ type Instance struct {
 	// imported by other packages, including those within the module.
 	Module string
 
+	// Experiments holds the names of the evaluator experiments enabled by
+	// the main module, in the language.experiments field of its module.cue
+	// file. They must be enabled in the context in which the instance is
+	// built, when it is created.
+	Experiments []string
+
 	// Root is the root of the directory hierarchy, it may be "" if this an
 	// instance has no imports.
 	// If Module != "", this corresponds to the module root.
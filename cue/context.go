@@ -54,6 +54,13 @@ func (c *Context) ctx() *adt.OpContext {
 	return newContext(c.runtime())
 }
 
+// Experiments returns the names of the experimental evaluator behaviors
+// enabled in c, in alphabetical order. Experiments are enabled when a
+// context is created, with [cuelang.org/go/cue/cuecontext.Experiments].
+func (c *Context) Experiments() []string {
+	return c.runtime().Experiments()
+}
+
 // Context reports the Context with which this value was created.
 func (v Value) Context() *Context {
 	return (*Context)(v.idx)
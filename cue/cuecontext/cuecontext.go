@@ -15,6 +15,8 @@
 package cuecontext
 
 import (
+	"fmt"
+
 	"cuelang.org/go/cue"
 	"cuelang.org/go/internal/core/runtime"
 
@@ -46,3 +48,55 @@ func Interpreter(i ExternInterpreter) Option {
 		r.SetInterpreter(i)
 	}}
 }
+
+// FilterBuiltins restricts the builtin packages, and the builtins within
+// them, that can be used by the values of the context to those for which
+// allow returns true. The import path of a package is passed as importPath
+// and the name of a builtin, such as "Repeat" for strings.Repeat, as name.
+// allow is first called with an empty name to decide whether a package can
+// be imported at all.
+//
+// Importing a package that is not allowed is an error, as is referring to
+// a builtin that is not allowed.
+//
+// This can be used by services that evaluate untrusted configurations to
+// limit their capabilities.
+func FilterBuiltins(allow func(importPath, name string) bool) Option {
+	return Option{func(r *runtime.Runtime) {
+		r.SetBuiltinFilter(allow)
+	}}
+}
+
+// DisableBuiltins disables the given builtin packages and builtins, which
+// are named by their import path, such as "tool/exec", or by their import
+// path and name separated by a dot, such as "strings.Repeat". See
+// [FilterBuiltins] for details.
+func DisableBuiltins(names ...string) Option {
+	disabled := map[string]bool{}
+	for _, name := range names {
+		disabled[name] = true
+	}
+	return FilterBuiltins(func(importPath, name string) bool {
+		if name == "" {
+			return !disabled[importPath]
+		}
+		return !disabled[importPath+"."+name]
+	})
+}
+
+// Experiments enables the experimental evaluator behaviors with the given
+// names, such as "evalv3", in the context. A module can require
+// experiments in the language.experiments field of its module.cue file,
+// which are listed in the Experiments field of its build instances; those
+// instances can only be built in a context in which the experiments are
+// enabled. Use [cue.Context.Experiments] to list the experiments enabled
+// in a context.
+//
+// Experiments panics if a name is not the name of a known experiment.
+func Experiments(names ...string) Option {
+	return Option{func(r *runtime.Runtime) {
+		if err := r.EnableExperiments(names...); err != nil {
+			panic(fmt.Sprintf("cuecontext: %v", err))
+		}
+	}}
+}
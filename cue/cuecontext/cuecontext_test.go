@@ -16,10 +16,12 @@ package cuecontext
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
 )
 
 func TestAPI(t *testing.T) {
@@ -72,3 +74,89 @@ func TestConcurrency(t *testing.T) {
 		`)
 	}()
 }
+
+func TestDisableBuiltins(t *testing.T) {
+	testCases := []struct {
+		name     string
+		disabled []string
+		src      string
+		want     string
+	}{{
+		name:     "allowed",
+		disabled: []string{"strings.Repeat"},
+		src: `
+			import "strings"
+			a: strings.ToUpper("a")
+			`,
+		want: `{
+	a: "A"
+}`,
+	}, {
+		name:     "func",
+		disabled: []string{"strings.Repeat"},
+		src: `
+			import "strings"
+			a: strings.Repeat("a", 1000000000)
+			`,
+		want: `a: builtin strings.Repeat is not allowed`,
+	}, {
+		name:     "constant",
+		disabled: []string{"math.Pi"},
+		src: `
+			import "math"
+			a: math.Pi
+			`,
+		want: `builtin math.Pi is not allowed`,
+	}, {
+		name:     "package",
+		disabled: []string{"regexp"},
+		src: `
+			import "regexp"
+			a: regexp.Match("a", "a")
+			`,
+		want: `builtin package "regexp" is not allowed`,
+	}, {
+		name:     "otherPackage",
+		disabled: []string{"tool/exec", "strings.Repeat"},
+		src: `
+			import "list"
+			a: list.Repeat([1], 2)
+			`,
+		want: `{
+	a: [1, 1]
+}`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := New(DisableBuiltins(tc.disabled...)).CompileString(tc.src)
+			got := fmt.Sprint(v)
+			if err := v.Validate(); err != nil {
+				got = errors.Details(err, nil)
+				got = strings.TrimSpace(got[:strings.Index(got+":\n", ":\n")])
+			}
+			if got != tc.want {
+				t.Errorf("got:\n%v;\nwant:\n%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExperiments(t *testing.T) {
+	if got := New().Experiments(); len(got) != 0 {
+		t.Errorf("got experiments %v in default context", got)
+	}
+	ctx := New(Experiments("evalv3", "evalv3"))
+	if got := fmt.Sprint(ctx.Experiments()); got != "[evalv3]" {
+		t.Errorf("got experiments %s", got)
+	}
+	if v := ctx.CompileString("a: 1 + 1"); fmt.Sprint(v) != "{\n\ta: 2\n}" {
+		t.Errorf("unexpected value %v", v)
+	}
+
+	defer func() {
+		if got, want := fmt.Sprint(recover()), `cuecontext: unknown evaluator experiment "nope"; known experiments are evalv3`; got != want {
+			t.Errorf("got panic %q; want %q", got, want)
+		}
+	}()
+	New(Experiments("nope"))
+}
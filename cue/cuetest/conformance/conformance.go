@@ -0,0 +1,157 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance provides a test suite that checks that evaluation
+// results are stable, for use by programs that embed CUE.
+//
+// The CUE API guarantees that, for the same inputs and the same version of
+// the module, the following do not change between runs, processes or
+// platforms:
+//
+//   - The order in which [cue.Value.Fields] iterates over the fields of a
+//     struct. The order is derived from the order of the declarations of
+//     the fields, where files are taken in the order in which they are
+//     given to the loader. Files in a directory are loaded in lexical
+//     order of their names.
+//   - The order of the elements of a list.
+//   - The output of [cue.Value.MarshalJSON] and [cue.Value.Syntax], which
+//     follow the order of iteration.
+//   - The errors reported by [errors.Errors] after [errors.Sanitize], and
+//     the output of [errors.Print] and [errors.Details]. Errors are ordered
+//     by position, path and message, so that the order in which they are
+//     found during evaluation does not matter.
+//
+// [Run] checks these guarantees on a set of test cases, each of which is
+// evaluated several times with a new context. Embedders that configure
+// contexts in their own way, for instance with options from package
+// cuecontext, can run the suite to check that their configuration
+// maintains the guarantees.
+package conformance
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuetest"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/internal"
+)
+
+// Runs is the number of times each test case is evaluated.
+const Runs = 5
+
+//go:embed testdata/*.txtar
+var testdata embed.FS
+
+// Run runs the conformance suite with contexts created by newContext.
+// A new context is created for each evaluation.
+func Run(t *testing.T, newContext func() *cue.Context) {
+	run(t, newContext, func(t *testing.T, name string) *cuetest.Fixture {
+		data, err := testdata.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cuetest.ParseFixture(t, data)
+	})
+}
+
+func run(t *testing.T, newContext func() *cue.Context, fixture func(t *testing.T, name string) *cuetest.Fixture) {
+	names, err := fs.Glob(testdata, "testdata/*.txtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		t.Run(path.Base(name), func(t *testing.T) {
+			f := fixture(t, name)
+
+			var first map[string]string
+			for i := 0; i < Runs; i++ {
+				out := evaluate(t, f, newContext())
+				if first == nil {
+					first = out
+					continue
+				}
+				for _, k := range outputNames(first) {
+					if out[k] != first[k] {
+						t.Fatalf("run %d: %s differs from first run:\n%s\nfirst run:\n%s", i, k, out[k], first[k])
+					}
+				}
+			}
+			for _, k := range outputNames(first) {
+				f.Golden(t, k, []byte(first[k]))
+			}
+		})
+	}
+}
+
+// evaluate returns the outputs for the instance in f, keyed by the names
+// of their golden files.
+func evaluate(t *testing.T, f *cuetest.Fixture, ctx *cue.Context) map[string]string {
+	v := f.Value(t, ctx)
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return map[string]string{
+			"out/errors": errors.Details(errors.Sanitize(errors.Promote(err, "")), &errors.Config{
+				Cwd:     f.Dir,
+				ToSlash: true,
+			}),
+		}
+	}
+
+	out := map[string]string{}
+	b, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out["out/json"] = string(b)
+
+	b, err = format.Node(internal.ToFile(v.Syntax(cue.Final(), cue.Concrete(true))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out["out/cue"] = string(b)
+
+	var fields []byte
+	appendFields(&fields, v, "")
+	out["out/fields"] = string(fields)
+	return out
+}
+
+// appendFields appends the paths of all fields and definitions within v in
+// the order in which they are iterated, one per line.
+func appendFields(b *[]byte, v cue.Value, indent string) {
+	iter, err := v.Fields(cue.Definitions(true), cue.Hidden(true), cue.Optional(true))
+	if err != nil {
+		return
+	}
+	for iter.Next() {
+		*b = append(*b, indent+iter.Selector().String()+"\n"...)
+		appendFields(b, iter.Value(), indent+"\t")
+	}
+}
+
+// outputNames returns the names of the outputs in m in a fixed order.
+func outputNames(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for _, k := range []string{"out/errors", "out/fields", "out/json", "out/cue"} {
+		if _, ok := m[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
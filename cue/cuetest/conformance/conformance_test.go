@@ -0,0 +1,38 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/cuetest"
+)
+
+func newContext() *cue.Context {
+	return cuecontext.New()
+}
+
+func TestConformance(t *testing.T) {
+	// Read the archives from disk, so that they can be updated.
+	run(t, newContext, func(t *testing.T, name string) *cuetest.Fixture {
+		return cuetest.ReadFixture(t, name)
+	})
+}
+
+func TestRun(t *testing.T) {
+	Run(t, newContext)
+}
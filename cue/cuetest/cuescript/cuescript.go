@@ -0,0 +1,267 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cuescript runs end-to-end tests written as txtar scripts in the
+// [testscript] language, with the cue command available to them.
+//
+// It provides the same environment that the cue command's own tests use,
+// so that authors of tools that wrap or extend cue can test them the same
+// way. A typical test file looks like:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(cuescript.RunMain(m, map[string]func() int{
+//			"mytool": mytool.Main,
+//		}))
+//	}
+//
+//	func TestScript(t *testing.T) {
+//		cuescript.Run(t, testscript.Params{
+//			Dir: "testdata/script",
+//		})
+//	}
+//
+// Scripts can then use both "exec cue" and "exec mytool".
+//
+// Each script runs in its own work directory with its own home directory,
+// so that user configuration and caches of the machine running the tests
+// are not used. See [Run] for the other features available to scripts.
+package cuescript
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"cuelabs.dev/go/oci/ociregistry/ocimem"
+	"cuelabs.dev/go/oci/ociregistry/ociserver"
+	"github.com/rogpeppe/go-internal/testscript"
+
+	"cuelang.org/go/cmd/cue/cmd"
+	"cuelang.org/go/cue/cuetest"
+	"cuelang.org/go/cue/errors"
+	internalcuetest "cuelang.org/go/internal/cuetest"
+	"cuelang.org/go/mod/modregistrytest"
+)
+
+// homeDirName is the name of the home directory created in the work
+// directory of each script. It has a . prefix so that the
+// pattern ./... does not descend into it.
+const homeDirName = ".user-home"
+
+// RunMain should be called from TestMain. It makes the given commands, as
+// well as the cue command, available to scripts as programs that can be
+// run with exec, and returns the code for passing to [os.Exit].
+//
+// A command in cmds named "cue" takes precedence over the cue command.
+func RunMain(m *testing.M, cmds map[string]func() int) int {
+	all := map[string]func() int{
+		"cue": cmd.MainTest,
+	}
+	for name, f := range cmds {
+		all[name] = f
+	}
+	return testscript.RunMain(m, all)
+}
+
+// Run runs the scripts in p.Dir as subtests of t. It requires that
+// [RunMain] was called from TestMain.
+//
+// Run adds the following to p:
+//
+//   - Archives are updated with the actual output of cmp commands when
+//     [cuetest.Update] is true, unless p.UpdateScripts is already set.
+//   - Programs can only be run with exec; p.RequireExplicitExec is
+//     always set.
+//   - Each script gets a separate home directory, set in $HOME or its
+//     equivalent on the current platform.
+//   - For each directory named _registry or _registryX at the root of the
+//     archive, a registry serving the modules in that directory is started
+//     and $CUE_REGISTRY (or $CUE_REGISTRYX) is set to refer to it.
+//     The prefix for module paths in the registry can be given in a file
+//     named _registry_prefix (or _registryX_prefix). The modules
+//     experiment is enabled when any registry is started.
+//   - The env-fill command rewrites its argument files, replacing any
+//     references to environment variables with their values.
+//   - The memregistry command starts an empty in-memory registry and sets
+//     the environment variable named by its argument to its host.
+//   - Conditions of the form [cuelang.org/issue/N] or [golang.org/issue/N]
+//     hold unless $CUE_NON_ISSUES is a regular expression matching them.
+//
+// Commands and conditions in p take precedence over the ones added by Run,
+// and p.Setup is called after the environment has been set up.
+func Run(t *testing.T, p testscript.Params) {
+	t.Helper()
+
+	if !p.UpdateScripts {
+		p.UpdateScripts = cuetest.Update
+	}
+	p.RequireExplicitExec = true
+
+	cmds := map[string]func(ts *testscript.TestScript, neg bool, args []string){
+		"env-fill":    envFill,
+		"memregistry": memRegistry,
+	}
+	for name, f := range p.Cmds {
+		cmds[name] = f
+	}
+	p.Cmds = cmds
+
+	if cond := p.Condition; cond != nil {
+		p.Condition = func(s string) (bool, error) {
+			if ok, err := internalcuetest.Condition(s); err == nil {
+				return ok, nil
+			}
+			return cond(s)
+		}
+	} else {
+		p.Condition = internalcuetest.Condition
+	}
+
+	userSetup := p.Setup
+	p.Setup = func(e *testscript.Env) error {
+		if err := setup(e); err != nil {
+			return err
+		}
+		if userSetup != nil {
+			return userSetup(e)
+		}
+		return nil
+	}
+
+	testscript.Run(t, p)
+}
+
+func setup(e *testscript.Env) error {
+	// If a script loads CUE packages but does not set up a cue.mod,
+	// the loader might walk up to the system's temporary directory looking
+	// for one. Make the parent of all work directories a module, ensuring
+	// consistent behavior no matter what its parent directories contain.
+	// Only the first script creates it.
+	workdirRoot := filepath.Dir(e.WorkDir)
+	if err := os.Mkdir(filepath.Join(workdirRoot, "cue.mod"), 0o777); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+
+	home := filepath.Join(e.WorkDir, homeDirName)
+	if err := os.Mkdir(home, 0o777); err != nil {
+		return err
+	}
+	e.Vars = append(e.Vars, homeEnvName()+"="+home)
+	if runtime.GOOS == "windows" {
+		// os.UserConfigDir on Windows requires %AppData% to be set,
+		// and it does not fall back to the home directory in any way.
+		e.Vars = append(e.Vars, "AppData="+filepath.Join(home, "appdata"))
+	}
+
+	entries, err := os.ReadDir(e.WorkDir)
+	if err != nil {
+		return fmt.Errorf("cannot read workdir: %v", err)
+	}
+	hasRegistry := false
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		regID, ok := strings.CutPrefix(entry.Name(), "_registry")
+		if !ok {
+			continue
+		}
+		hasRegistry = true
+		prefix := ""
+		if data, err := os.ReadFile(filepath.Join(e.WorkDir, "_registry"+regID+"_prefix")); err == nil {
+			prefix = strings.TrimSpace(string(data))
+		}
+		reg, err := modregistrytest.New(os.DirFS(filepath.Join(e.WorkDir, entry.Name())), prefix)
+		if err != nil {
+			return fmt.Errorf("cannot start test registry server: %v", err)
+		}
+		if prefix != "" {
+			prefix = "/" + prefix
+		}
+		e.Vars = append(e.Vars,
+			"CUE_REGISTRY"+regID+"="+reg.Host()+prefix+"+insecure",
+			"CUE_MODCACHE="+filepath.Join(e.WorkDir, "tmp/cache"),
+		)
+		e.Defer(reg.Close)
+	}
+	if hasRegistry {
+		e.Vars = append(e.Vars, "CUE_EXPERIMENT=modules")
+	}
+	return nil
+}
+
+// envFill implements the env-fill command.
+func envFill(ts *testscript.TestScript, neg bool, args []string) {
+	if neg || len(args) == 0 {
+		ts.Fatalf("usage: env-fill args...")
+	}
+	for _, arg := range args {
+		path := ts.MkAbs(arg)
+		data := os.Expand(ts.ReadFile(path), ts.Getenv)
+		ts.Check(os.WriteFile(path, []byte(data), 0o666))
+	}
+}
+
+// memRegistry implements the memregistry command.
+func memRegistry(ts *testscript.TestScript, neg bool, args []string) {
+	usage := func() {
+		ts.Fatalf("usage: memregistry [-auth=username:password] <envvar-name>")
+	}
+	if neg {
+		usage()
+	}
+	var auth *modregistrytest.AuthConfig
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		userPass, ok := strings.CutPrefix(args[0], "-auth=")
+		if !ok {
+			usage()
+		}
+		user, pass, ok := strings.Cut(userPass, ":")
+		if !ok {
+			usage()
+		}
+		auth = &modregistrytest.AuthConfig{
+			Username: user,
+			Password: pass,
+		}
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		usage()
+	}
+
+	srv := httptest.NewServer(modregistrytest.AuthHandler(ociserver.New(ocimem.New(), nil), auth))
+	u, _ := url.Parse(srv.URL)
+	ts.Setenv(args[0], u.Host)
+	ts.Defer(srv.Close)
+}
+
+// homeEnvName returns the name of the environment variable used by
+// os.UserHomeDir on the current platform.
+func homeEnvName() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "USERPROFILE"
+	case "plan9":
+		return "home"
+	default:
+		return "HOME"
+	}
+}
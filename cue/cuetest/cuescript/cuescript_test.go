@@ -0,0 +1,46 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuescript_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rogpeppe/go-internal/testscript"
+
+	"cuelang.org/go/cue/cuetest/cuescript"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(cuescript.RunMain(m, map[string]func() int{
+		// shout stands in for a tool built on top of cue.
+		"shout": func() int {
+			fmt.Println(strings.ToUpper(strings.Join(os.Args[1:], " ")))
+			return 0
+		},
+	}))
+}
+
+func TestScript(t *testing.T) {
+	cuescript.Run(t, testscript.Params{
+		Dir: "testdata/script",
+		Setup: func(e *testscript.Env) error {
+			e.Vars = append(e.Vars, "GREETING=hello")
+			return nil
+		},
+	})
+}
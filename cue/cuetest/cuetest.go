@@ -0,0 +1,102 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cuetest provides helpers for testing Go programs that use the
+// CUE API.
+//
+// It supports three kinds of checks that are common in such tests:
+//
+//   - comparing values against golden files, see [Golden] and [GoldenJSON];
+//   - matching error messages without depending on source positions,
+//     see [ErrorMessages] and [MatchErrors];
+//   - loading CUE packages from txtar archives, see [Fixture].
+//
+// Golden files are rewritten instead of compared when [Update] is true,
+// which is the case when the CUE_UPDATE environment variable is set
+// to a non-empty value.
+package cuetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/internal"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Update determines whether golden files are updated with the actual output
+// instead of being compared against it. It is initialized from the
+// CUE_UPDATE environment variable.
+var Update = os.Getenv("CUE_UPDATE") != ""
+
+// Golden compares the CUE syntax of v, as produced by [cue.Value.Syntax]
+// with the given options and formatted as a CUE file, against the
+// contents of the file filename.
+func Golden(t testing.TB, v cue.Value, filename string, opts ...cue.Option) {
+	t.Helper()
+
+	b, err := format.Node(internal.ToFile(v.Syntax(opts...)))
+	if err != nil {
+		t.Fatalf("formatting value: %v", err)
+	}
+	GoldenBytes(t, b, filename)
+}
+
+// GoldenJSON compares the indented JSON representation of v against the
+// contents of the file filename.
+func GoldenJSON(t testing.TB, v cue.Value, filename string) {
+	t.Helper()
+
+	b, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		t.Fatalf("marshaling value: %v", err)
+	}
+	GoldenBytes(t, append(b, '\n'), filename)
+}
+
+// GoldenBytes compares got against the contents of the file filename.
+// If [Update] is true, the file is written instead, creating any missing
+// parent directories.
+func GoldenBytes(t testing.TB, got []byte, filename string) {
+	t.Helper()
+
+	if Update {
+		if err := os.MkdirAll(filepath.Dir(filename), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, got, 0o666); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (set CUE_UPDATE=1 to create it)", err)
+	}
+	compare(t, filename, want, got)
+}
+
+func compare(t testing.TB, name string, want, got []byte) {
+	t.Helper()
+
+	if !bytes.Equal(want, got) {
+		t.Errorf("result for %s differs: (-want +got)\n%s",
+			name, cmp.Diff(string(want), string(got)))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuetest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/cuetest"
+	"github.com/go-quicktest/qt"
+)
+
+func TestFixture(t *testing.T) {
+	f := cuetest.ReadFixture(t, "testdata/fixture.txtar")
+	v := f.Value(t, cuecontext.New())
+
+	b, err := v.LookupPath(cue.ParsePath("y")).MarshalJSON()
+	qt.Assert(t, qt.IsNil(err))
+	f.Golden(t, "out/y", b)
+
+	a := f.Instances(t, "./sub")
+	qt.Assert(t, qt.HasLen(a, 1))
+	qt.Assert(t, qt.Equals(a[0].PkgName, "b"))
+}
+
+func TestGolden(t *testing.T) {
+	dir := t.TempDir()
+	v := cuecontext.New().CompileString(`a: 1, b: [1, 2]`)
+
+	update := cuetest.Update
+	defer func() { cuetest.Update = update }()
+
+	cuetest.Update = true
+	cuetest.Golden(t, v, filepath.Join(dir, "out", "value.cue"))
+	cuetest.GoldenJSON(t, v, filepath.Join(dir, "out", "value.json"))
+
+	b, err := os.ReadFile(filepath.Join(dir, "out", "value.json"))
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(b), "{\n    \"a\": 1,\n    \"b\": [\n        1,\n        2\n    ]\n}\n"))
+
+	cuetest.Update = false
+	cuetest.Golden(t, v, filepath.Join(dir, "out", "value.cue"))
+	cuetest.GoldenJSON(t, v, filepath.Join(dir, "out", "value.json"))
+}
+
+func TestErrorMessages(t *testing.T) {
+	f := cuetest.ParseFixture(t, []byte(`
+-- x.cue --
+a: 1 & 2
+b: c: string & 3
+`))
+	v := f.Value(t, cuecontext.New())
+	err := v.Validate()
+
+	qt.Assert(t, qt.DeepEquals(cuetest.ErrorMessages(err), []string{
+		"a: conflicting values 2 and 1",
+		"b.c: conflicting values string and 3 (mismatched types string and int)",
+	}))
+	cuetest.MatchErrors(t, err,
+		`a: conflicting values .*`,
+		`b\.c: .*mismatched types.*`,
+	)
+	cuetest.MatchErrors(t, nil)
+}
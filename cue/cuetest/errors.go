@@ -0,0 +1,74 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuetest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/errors"
+)
+
+// ErrorMessages returns the messages of the individual errors in err, in
+// the order reported by [errors.Errors]. Each message is prefixed by the
+// path of the value it applies to, if any, but contains no source
+// positions, so that the result does not change when unrelated lines are
+// added to or removed from the input.
+func ErrorMessages(err error) []string {
+	var msgs []string
+	for _, e := range errors.Errors(err) {
+		format, args := e.Msg()
+		msg := fmt.Sprintf(format, args...)
+		if p := e.Path(); len(p) > 0 {
+			msg = strings.Join(p, ".") + ": " + msg
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// MatchErrors checks that err consists of exactly one error for each of
+// the given patterns and that the message of the i'th error, as returned
+// by [ErrorMessages], matches the i'th pattern. Patterns are regular
+// expressions that must match the entire message.
+//
+// Calling MatchErrors without patterns checks that err is nil.
+func MatchErrors(t testing.TB, err error, patterns ...string) {
+	t.Helper()
+
+	msgs := ErrorMessages(err)
+	if len(msgs) != len(patterns) {
+		t.Errorf("got %d errors, want %d:\n%s", len(msgs), len(patterns), formatMessages(msgs))
+		return
+	}
+	for i, p := range patterns {
+		re, err := regexp.Compile("^(?:" + p + ")$")
+		if err != nil {
+			t.Fatalf("invalid pattern %q: %v", p, err)
+		}
+		if !re.MatchString(msgs[i]) {
+			t.Errorf("error %d does not match %q:\n\t%s", i, p, msgs[i])
+		}
+	}
+}
+
+func formatMessages(msgs []string) string {
+	if len(msgs) == 0 {
+		return "\t<no errors>"
+	}
+	return "\t" + strings.Join(msgs, "\n\t")
+}
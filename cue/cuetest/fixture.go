@@ -0,0 +1,197 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuetest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"golang.org/x/tools/txtar"
+)
+
+// A Fixture holds the files of a txtar archive used as test input.
+//
+// The files of the archive are made available to the loader as if they
+// were located in Dir, without being written to disk. Files in the
+// archive can also serve as golden files, see [Fixture.Golden].
+type Fixture struct {
+	// Archive holds the parsed archive.
+	Archive *txtar.Archive
+
+	// Dir is the absolute directory relative to which the files in the
+	// archive are placed when loading.
+	Dir string
+
+	// LoadConfig is passed to [load.Instances]. It is copied before use
+	// and its Dir and Overlay fields are overwritten.
+	LoadConfig load.Config
+
+	filename string // file the archive was read from, if any
+	modified bool
+}
+
+// ReadFixture reads the txtar archive in filename. The files in the
+// archive are placed in a directory named after filename without its
+// extension.
+//
+// If [Update] is true, golden files that differ are updated in the archive
+// and the archive is written back when the test completes.
+func ReadFixture(t testing.TB, filename string) *Fixture {
+	t.Helper()
+
+	a, err := txtar.ParseFile(filename)
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &Fixture{
+		Archive:  a,
+		Dir:      strings.TrimSuffix(abs, filepath.Ext(abs)),
+		filename: filename,
+	}
+	t.Cleanup(func() {
+		if !f.modified {
+			return
+		}
+		if err := os.WriteFile(f.filename, txtar.Format(f.Archive), 0o666); err != nil {
+			t.Errorf("updating fixture: %v", err)
+		}
+	})
+	return f
+}
+
+// ParseFixture parses data as a txtar archive. The files in the archive
+// are placed in a temporary directory.
+//
+// As the archive is not associated with a file, golden files in it are
+// never updated.
+func ParseFixture(t testing.TB, data []byte) *Fixture {
+	t.Helper()
+
+	return &Fixture{
+		Archive: txtar.Parse(data),
+		Dir:     t.TempDir(),
+	}
+}
+
+// File returns the contents of the file with the given name in the archive
+// and reports whether it exists.
+func (f *Fixture) File(name string) ([]byte, bool) {
+	for _, file := range f.Archive.Files {
+		if file.Name == name {
+			return file.Data, true
+		}
+	}
+	return nil, false
+}
+
+// Instances loads the instances for the given arguments, which are
+// interpreted as by [load.Instances]. Without arguments, the files in the
+// root directory of the archive are loaded as a single instance.
+// The test fails if any of the instances has an error.
+func (f *Fixture) Instances(t testing.TB, args ...string) []*build.Instance {
+	t.Helper()
+
+	a := f.RawInstances(args...)
+	for _, inst := range a {
+		if inst.Err != nil {
+			t.Fatalf("loading instances: %v", errors.Details(inst.Err, nil))
+		}
+	}
+	return a
+}
+
+// RawInstances is like [Fixture.Instances], but does not check the
+// returned instances for errors.
+func (f *Fixture) RawInstances(args ...string) []*build.Instance {
+	auto := len(args) == 0
+	overlay := map[string]load.Source{}
+	for _, file := range f.Archive.Files {
+		if auto && !strings.Contains(file.Name, "/") && strings.HasSuffix(file.Name, ".cue") {
+			args = append(args, file.Name)
+		}
+		overlay[filepath.Join(f.Dir, file.Name)] = load.FromBytes(file.Data)
+	}
+
+	cfg := f.LoadConfig
+	cfg.Dir = f.Dir
+	cfg.Overlay = overlay
+	return load.Instances(args, &cfg)
+}
+
+// Value loads the single instance selected by args, as for
+// [Fixture.Instances], and builds it with ctx. The test fails if the
+// arguments do not select exactly one instance.
+//
+// Errors in the returned value are not reported; use [cue.Value.Err] or
+// [cue.Value.Validate] to check for them.
+func (f *Fixture) Value(t testing.TB, ctx *cue.Context, args ...string) cue.Value {
+	t.Helper()
+
+	a := f.Instances(t, args...)
+	if len(a) != 1 {
+		t.Fatalf("got %d instances, want 1", len(a))
+	}
+	return ctx.BuildInstance(a[0])
+}
+
+// Golden compares got against the contents of the file with the given name
+// in the archive. If [Update] is true and the fixture was read with
+// [ReadFixture], the file in the archive is replaced, or added if it did
+// not exist, instead.
+//
+// As files in a txtar archive always end in a newline, one is added to got
+// if it is missing.
+func (f *Fixture) Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	if len(got) > 0 && got[len(got)-1] != '\n' {
+		got = append(got[:len(got):len(got)], '\n')
+	}
+
+	want, ok := f.File(name)
+	if Update && f.filename != "" {
+		if ok && string(want) == string(got) {
+			return
+		}
+		f.setFile(name, got)
+		return
+	}
+	if !ok {
+		t.Errorf("no golden file %s in archive (set CUE_UPDATE=1 to create it)", name)
+		return
+	}
+	compare(t, name, want, got)
+}
+
+func (f *Fixture) setFile(name string, data []byte) {
+	f.modified = true
+	for i, file := range f.Archive.Files {
+		if file.Name == name {
+			f.Archive.Files[i].Data = data
+			return
+		}
+	}
+	f.Archive.Files = append(f.Archive.Files, txtar.File{Name: name, Data: data})
+}
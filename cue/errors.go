@@ -51,6 +51,10 @@ func (e *valueError) Unwrap() error {
 
 func (e *valueError) Bottom() *adt.Bottom { return e.err }
 
+func (e *valueError) Suggestions() []errors.Suggestion {
+	return errors.Suggestions(e.err.Err)
+}
+
 func (e *valueError) Error() string {
 	return errors.String(e)
 }
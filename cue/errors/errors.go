@@ -381,7 +381,29 @@ func (p list) Less(i, j int) bool {
 	if !equalPath(p[i].Path(), p[j].Path()) {
 		return lessPath(p[i].Path(), p[j].Path())
 	}
-	return p[i].Error() < p[j].Error()
+	if a, b := p[i].Error(), p[j].Error(); a != b {
+		return a < b
+	}
+	// Errors that only differ in the positions that contributed to them
+	// are ordered by these positions, so that the error kept by
+	// RemoveMultiples does not depend on the order in which they were
+	// reported.
+	return comparePositions(p[i].InputPositions(), p[j].InputPositions()) == -1
+}
+
+func comparePositions(a, b []token.Pos) int {
+	for i, x := range a {
+		if i >= len(b) {
+			return 1
+		}
+		if c := comparePos(x, b[i]); c != 0 {
+			return c
+		}
+	}
+	if len(a) < len(b) {
+		return -1
+	}
+	return 0
 }
 
 func lessOrMore(isLess bool) int {
@@ -454,11 +476,11 @@ func (p list) sanitize() list {
 	return a
 }
 
-// Sort sorts an List. *posError entries are sorted by position,
-// other errors are sorted by error message, and before any *posError
-// entry.
+// Sort sorts an List by position, path, message and input positions, in
+// that order. Errors without a position sort before any others. The order
+// does not depend on the order in which the errors were added.
 func (p list) Sort() {
-	sort.Sort(p)
+	sort.Stable(p)
 }
 
 // RemoveMultiples sorts an List and removes all but the first error per line.
@@ -215,3 +215,64 @@ func TestPrintError(t *testing.T) {
 		})
 	}
 }
+
+// inputError is an error that differs from others only in its input
+// positions.
+type inputError struct {
+	posError
+	inputs []token.Pos
+}
+
+func (e *inputError) InputPositions() []token.Pos { return e.inputs }
+
+func TestPrintOrderIndependent(t *testing.T) {
+	f := token.NewFile("a.cue", -1, 100)
+	f.SetLinesForContent([]byte("a: 1\nb: 2\nc: 3\n"))
+	pos := f.Pos(0, token.NoRelPos)
+	newErr := func(input int) Error {
+		return &inputError{
+			posError: posError{pos: pos, Message: NewMessagef("conflict")},
+			inputs:   []token.Pos{f.Pos(input, token.NoRelPos)},
+		}
+	}
+	a := Append(newErr(5), newErr(10))
+	b := Append(newErr(10), newErr(5))
+	if got, want := Details(b, nil), Details(a, nil); got != want {
+		t.Errorf("output depends on order of errors\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	f := token.NewFile("input", -1, 10)
+	f.SetLinesForContent([]byte("a: 1\nb: 2\n"))
+	fix := Suggestion{
+		Message: "remove a",
+		Edits:   []Edit{{Pos: f.Pos(0, 0), End: f.Pos(5, 0)}},
+	}
+	plain := Newf(f.Pos(0, 0), "plain")
+	suggested := WithSuggestions(Newf(f.Pos(5, 0), "suggested"), fix)
+
+	if got := suggested.Error(); got != "suggested" {
+		t.Errorf("got message %q; want %q", got, "suggested")
+	}
+	if got := suggested.Position(); got != f.Pos(5, 0) {
+		t.Errorf("got position %v; want %v", got, f.Pos(5, 0))
+	}
+	if WithSuggestions(plain) != plain {
+		t.Errorf("WithSuggestions without suggestions should return the error")
+	}
+
+	for _, err := range []error{
+		suggested,
+		Append(plain, suggested),
+		fmt.Errorf("wrap: %w", suggested),
+	} {
+		got := Suggestions(err)
+		if len(got) != 1 || got[0].Message != fix.Message {
+			t.Errorf("Suggestions(%v) = %v; want [%v]", err, got, fix)
+		}
+	}
+	if got := Suggestions(plain); got != nil {
+		t.Errorf("Suggestions(%v) = %v; want none", plain, got)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"errors"
+
+	"cuelang.org/go/cue/token"
+)
+
+// A Suggestion is a fix for an error that can be applied mechanically.
+type Suggestion struct {
+	// Message describes the fix for human consumption.
+	Message string
+
+	// Edits holds the changes to the source that make up the fix. Edits
+	// of a single suggestion do not overlap.
+	Edits []Edit
+}
+
+// An Edit replaces the source text between Pos and End, which are in the
+// same file, with NewText. If Pos equals End, NewText is inserted at Pos.
+type Edit struct {
+	Pos     token.Pos
+	End     token.Pos
+	NewText string
+}
+
+// WithSuggestions returns an Error that is like err, but that suggests the
+// given fixes.
+func WithSuggestions(err Error, s ...Suggestion) Error {
+	if err == nil || len(s) == 0 {
+		return err
+	}
+	return &suggested{err, s}
+}
+
+// Suggestions returns the fixes suggested by the errors contained in err,
+// in the order of the errors. An error suggests fixes if it was created
+// with WithSuggestions or if it, or an error it wraps, has a method
+//
+//	Suggestions() []Suggestion
+func Suggestions(err error) []Suggestion {
+	var a []Suggestion
+	for _, e := range Errors(err) {
+		for x := error(e); x != nil; x = errors.Unwrap(x) {
+			if s, ok := x.(suggester); ok {
+				a = append(a, s.Suggestions()...)
+				break
+			}
+		}
+	}
+	return a
+}
+
+type suggester interface {
+	Suggestions() []Suggestion
+}
+
+type suggested struct {
+	err         Error
+	suggestions []Suggestion
+}
+
+func (e *suggested) Position() token.Pos                      { return e.err.Position() }
+func (e *suggested) InputPositions() []token.Pos              { return e.err.InputPositions() }
+func (e *suggested) Error() string                            { return e.err.Error() }
+func (e *suggested) Path() []string                           { return e.err.Path() }
+func (e *suggested) Msg() (format string, args []interface{}) { return e.err.Msg() }
+
+func (e *suggested) Suggestions() []Suggestion { return e.suggestions }
+
+// Unwrap skips e.err, which has the same message as e.
+func (e *suggested) Unwrap() error { return errors.Unwrap(e.err) }
+
+func (e *suggested) Is(target error) bool {
+	return Is(e.err, target)
+}
+
+func (e *suggested) As(target interface{}) bool {
+	return As(e.err, target)
+}
@@ -0,0 +1,219 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+)
+
+// normalizeComments moves comments that document a field, but that are
+// placed between its label and its value, to the field itself, so that
+// they are printed before the field.
+func normalizeComments(n ast.Node) {
+	ast.Walk(n, func(n ast.Node) bool {
+		if x, ok := n.(*ast.Field); ok {
+			normalizeFieldComments(x)
+		}
+		return true
+	}, nil)
+}
+
+func normalizeFieldComments(x *ast.Field) {
+	if x.Value == nil {
+		return
+	}
+	var moved []*ast.Comment
+	var keep []*ast.CommentGroup
+	for _, cg := range x.Comments() {
+		if cg.Position > 0 && isBefore(cg, x.Value) {
+			moved = append(moved, cg.List...)
+		} else {
+			keep = append(keep, cg)
+		}
+	}
+	var keepValue []*ast.CommentGroup
+	for _, cg := range ast.Comments(x.Value) {
+		if cg.Position == 0 && !cg.Line {
+			moved = append(moved, cg.List...)
+		} else {
+			keepValue = append(keepValue, cg)
+		}
+	}
+	if len(moved) == 0 {
+		return
+	}
+	ast.SetComments(x.Value, keepValue)
+	if rel := x.Value.Pos().RelPos(); rel == token.Newline || rel == token.NewSection {
+		ast.SetRelPos(x.Value, token.Blank)
+	}
+
+	// Merge the comments into the last doc comment of the field, if any.
+	var doc *ast.CommentGroup
+	for _, cg := range keep {
+		if cg.Position == 0 && cg.Doc {
+			doc = cg
+		}
+	}
+	if doc == nil {
+		doc = &ast.CommentGroup{Doc: true}
+		keep = append([]*ast.CommentGroup{doc}, keep...)
+
+		// The comment takes over the spacing before the field.
+		rel := x.Pos().RelPos()
+		if rel != token.NewSection {
+			rel = token.Newline
+		}
+		moved[0].Slash = moved[0].Slash.WithRel(rel)
+		if x.Pos().HasRelPos() {
+			ast.SetRelPos(x, token.Newline)
+		}
+	}
+	doc.List = append(doc.List, moved...)
+	ast.SetComments(x, keep)
+}
+
+func isBefore(cg *ast.CommentGroup, n ast.Node) bool {
+	a, b := cg.Pos(), n.Pos()
+	return a.IsValid() && b.IsValid() && a.Offset() < b.Offset()
+}
+
+// isStandalone reports whether cg is printed on lines of its own.
+func isStandalone(cg *ast.CommentGroup) bool {
+	if cg.Line {
+		return false
+	}
+	switch cg.Pos().RelPos() {
+	case token.Blank, token.Elided, token.NoSpace:
+		return cg.Doc
+	}
+	return true
+}
+
+// reflowComment returns the lines of the line comments in list, with
+// paragraphs rewrapped to fit within width columns and with a space
+// inserted after the comment marker where it is missing.
+//
+// A paragraph is a sequence of lines that are not blank and that do not
+// start with white space after the comment marker. List items, starting
+// with "-", "*", "+", or a number followed by "." or ")", each start a new
+// paragraph. Indented lines, such as code examples, and directives such as
+// "//cue:generate" are left unchanged.
+func reflowComment(list []*ast.Comment, width int) []string {
+	var lines []string
+	var para []string
+	flush := func() {
+		lines = append(lines, wrapWords(para, width)...)
+		para = para[:0]
+	}
+	for _, c := range list {
+		text := strings.TrimRight(c.Text, " \t")
+		t := strings.TrimPrefix(text, "//")
+		switch {
+		case t == "":
+			flush()
+			lines = append(lines, "//")
+			continue
+		case isDirective(t):
+			flush()
+			lines = append(lines, text)
+			continue
+		case t[0] == ' ':
+			t = t[1:]
+		}
+		switch {
+		case t == "":
+			flush()
+			lines = append(lines, "//")
+		case t[0] == ' ' || t[0] == '\t':
+			flush()
+			lines = append(lines, "// "+t)
+		default:
+			words := strings.Fields(t)
+			if isListItem(words) {
+				flush()
+			}
+			para = append(para, words...)
+		}
+	}
+	flush()
+	return lines
+}
+
+// wrapWords writes words into lines of at most width columns, if possible.
+// Words are never moved to the start of a line if doing so would make them
+// be interpreted as a list item.
+func wrapWords(words []string, width int) []string {
+	var lines []string
+	var b strings.Builder
+	for i, w := range words {
+		switch {
+		case i == 0:
+		case b.Len()+1+len(w) > width && !isListItem(words[i:]):
+			lines = append(lines, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(' ')
+		}
+		if b.Len() == 0 {
+			b.WriteString("// ")
+		}
+		b.WriteString(w)
+	}
+	if b.Len() > 0 {
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// isListItem reports whether a line starting with words is a list item.
+func isListItem(words []string) bool {
+	if len(words) < 2 {
+		return false
+	}
+	switch w := words[0]; w {
+	case "-", "*", "+":
+		return true
+	default:
+		n := len(w) - 1
+		if n < 1 || (w[n] != '.' && w[n] != ')') {
+			return false
+		}
+		for _, r := range w[:n] {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// isDirective reports whether the text t following a comment marker is a
+// directive, such as "cue:generate", which by convention has no space
+// after the marker.
+func isDirective(t string) bool {
+	i := strings.IndexByte(t, ':')
+	if i <= 0 || i == len(t)-1 || t[i+1] == ' ' {
+		return false
+	}
+	for _, r := range t[:i] {
+		if !('a' <= r && r <= 'z' || '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -29,6 +29,7 @@ import (
 	"text/tabwriter"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
 )
@@ -63,6 +64,28 @@ func IndentPrefix(n int) Option {
 	return func(c *config) { c.Indent = n }
 }
 
+// Strings converts double-quoted string literals, other than labels and
+// interpolations, to the form selected by style. See [literal.Style].
+func Strings(style literal.Style) Option {
+	return func(c *config) {
+		c.requote = true
+		c.stringStyle = style
+	}
+}
+
+// ReflowComments rewraps the paragraphs of comments that are on lines of
+// their own to fit within the given line width, counting tabs as the tab
+// width, and normalizes the placement of comments: comments between the
+// label and value of a field are moved before the field to become its doc
+// comment. Reflowing is skipped if width is zero. The result does not
+// change when formatted again.
+func ReflowComments(width int) Option {
+	return func(c *config) {
+		c.normalizeComments = true
+		c.commentWidth = width
+	}
+}
+
 // TODO: make public
 // sortImportsOption causes import declarations to be sorted.
 func sortImportsOption() Option {
@@ -124,6 +147,12 @@ type config struct {
 
 	simplify    bool
 	sortImports bool
+
+	requote     bool
+	stringStyle literal.Style
+
+	normalizeComments bool
+	commentWidth      int
 }
 
 func newConfig(opt []Option) *config {
@@ -321,9 +350,27 @@ func (f *formatter) visitComments(until int8) {
 func (f *formatter) printComment(cg *ast.CommentGroup) {
 	f.Print(cg)
 
-	printBlank := false
-	if cg.Doc && len(f.output) > 0 {
+	if f.cfg.commentWidth > 0 && isStandalone(cg) {
+		switch {
+		case !cg.Doc:
+			f.Print(vtab)
+		case len(f.output) > 0:
+			f.Print(newline)
+		}
+		f.Print(cg.List[0].Slash)
+		f.Print(&commentBlock{cg.List})
 		f.Print(newline)
+		if cg.Doc {
+			f.Print(nooverride)
+		}
+		return
+	}
+
+	printBlank := false
+	if cg.Doc {
+		if len(f.output) > 0 {
+			f.Print(newline)
+		}
 		printBlank = true
 	}
 	for _, c := range cg.List {
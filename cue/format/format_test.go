@@ -26,6 +26,7 @@ import (
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
@@ -469,6 +470,141 @@ func TestIncorrectIdent(t *testing.T) {
 	}
 }
 
+// TestLeadingDocComment checks that a doc comment at the start of a file
+// without a package clause is not indented.
+func TestLeadingDocComment(t *testing.T) {
+	const src = "// doc\na: 1\n"
+	b, err := Source([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != src {
+		t.Errorf("got %q; want %q", got, src)
+	}
+}
+
+func TestStrings(t *testing.T) {
+	src := `a: "\\d+\\.\\d+\\.\\d+"
+b: "x\\y"
+c: {
+	d: "echo \"$1\"\nexit 1\n"
+	e: "\(a)\\\\\\"
+}
+"f\\g\\h\\i": 1
+`
+	testCases := []struct {
+		style literal.Style
+		out   string
+	}{{
+		style: literal.ReadableStyle,
+		out: `a: #"\d+\.\d+\.\d+"#
+b: "x\\y"
+c: {
+	d: """
+		echo "$1"
+		exit 1
+
+		"""
+	e: "\(a)\\\\\\"
+}
+"f\\g\\h\\i": 1
+`,
+	}, {
+		style: literal.RawStyle,
+		out: `a: #"\d+\.\d+\.\d+"#
+b: #"x\y"#
+c: {
+	d: """
+		echo "$1"
+		exit 1
+
+		"""
+	e: "\(a)\\\\\\"
+}
+"f\\g\\h\\i": 1
+`,
+	}}
+	for _, tc := range testCases {
+		b, err := Source([]byte(src), Strings(tc.style))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b); got != tc.out {
+			t.Errorf("style %d:\ngot:\n%s\nwant:\n%s", tc.style, got, tc.out)
+		}
+	}
+}
+
+func TestReflowComments(t *testing.T) {
+	src := `package p
+
+a: // about a
+	1
+
+b:
+	// doc b
+	2
+
+//no space
+//    indented
+c: 3
+
+// This is a long comment that should be wrapped because it goes beyond the width.
+// This short line joins.
+//
+// - a list item that is also quite long and needs to be wrapped to fit - in
+// - second item
+//cue:directive foo
+d: {
+	// a nested comment that wraps at a narrower column because of indentation
+	x: 1
+}
+
+e: 4 // a line comment that is very long is not wrapped, as it follows a field
+`
+	want := `package p
+
+// about a
+a: 1
+
+// doc b
+b: 2
+
+// no space
+//    indented
+c: 3
+
+// This is a long comment that should be wrapped because it
+// goes beyond the width. This short line joins.
+//
+// - a list item that is also quite long and needs to be
+// wrapped to fit - in
+// - second item
+//cue:directive foo
+d: {
+	// a nested comment that wraps at a narrower column
+	// because of indentation
+	x: 1
+}
+
+e: 4 // a line comment that is very long is not wrapped, as it follows a field
+`
+	b, err := Source([]byte(src), ReflowComments(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	b2, err := Source(b, ReflowComments(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b2) != string(b) {
+		t.Errorf("not idempotent; formatted again:\n%s", b2)
+	}
+}
+
 // TextX is a skeleton test that can be filled in for debugging one-off cases.
 // Do not remove.
 func TestX(t *testing.T) {
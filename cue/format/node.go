@@ -29,6 +29,10 @@ func printNode(node interface{}, f *printer) error {
 
 	ls := labelSimplifier{scope: map[string]bool{}}
 
+	if n, ok := node.(ast.Node); ok && f.cfg.normalizeComments {
+		normalizeComments(n)
+	}
+
 	// format node
 	f.allowed = nooverride // gobble initial whitespace.
 	switch x := node.(type) {
@@ -563,6 +567,11 @@ func (f *formatter) exprRaw(expr ast.Expr, prec1, depth int) {
 		}
 
 	case *ast.BasicLit:
+		if f.cfg.requote && x.Kind == token.STRING {
+			lit := *x
+			lit.Value = literal.Requote(x.Value, f.cfg.stringStyle, f.cfg.Indent+f.indent+1)
+			x = &lit
+		}
 		f.print(x.ValuePos, x)
 
 	case *ast.Interpolation:
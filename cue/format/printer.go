@@ -79,6 +79,7 @@ func (p *printer) Print(v interface{}) {
 		isLit        bool
 		data         string
 		nextWS       whiteSpace
+		block        *commentBlock
 	)
 	switch x := v.(type) {
 	case *line:
@@ -208,6 +209,11 @@ func (p *printer) Print(v interface{}) {
 		data = x.Text
 		p.lastTok = token.COMMENT
 
+	case *commentBlock:
+		isLit = true
+		block = x
+		p.lastTok = token.COMMENT
+
 	case whiteSpace:
 		p.allowed |= x
 		return
@@ -246,11 +252,29 @@ func (p *printer) Print(v interface{}) {
 	p.writeWhitespace(p.allowed)
 	p.allowed = 0
 	p.requested = 0
+	if block != nil {
+		data = p.reflow(block)
+	}
 	p.writeString(data, isLit)
 	p.allowed = nextWS
 	_ = impliedComma // TODO: delay comment printings
 }
 
+// A commentBlock holds line comments that are reflowed as a whole when
+// printed.
+type commentBlock struct {
+	list []*ast.Comment
+}
+
+// reflow returns the lines of b reflowed to the configured width, taking
+// into account the indentation of the current line.
+func (p *printer) reflow(b *commentBlock) string {
+	indent := p.cfg.Indent + p.indent
+	width := p.cfg.commentWidth - indent*p.cfg.Tabwidth
+	lines := reflowComment(b.list, width)
+	return strings.Join(lines, "\n"+strings.Repeat("\t", indent))
+}
+
 func (p *printer) writeWhitespace(ws whiteSpace) {
 	if ws&comma != 0 {
 		switch {
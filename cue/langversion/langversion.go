@@ -0,0 +1,137 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package langversion describes the versions of the CUE language and the
+// language features introduced by each of them.
+//
+// A module declares the version of the language it is written in with
+// the language.version field in its cue.mod/module.cue file. Features
+// introduced after that version are rejected when loading the module's
+// packages, and deprecated features that were still supported at that
+// version remain accepted.
+//
+// Only the parser depends on the language version: the evaluator and the
+// formatter treat all code as written for the current version.
+package langversion
+
+import (
+	"fmt"
+
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+// Current is the most recent language version supported by this
+// implementation of CUE, which is also the version assumed by
+// [cuelang.org/go/cue/parser.Latest].
+var Current = fmt.Sprintf("v0.%d.0", internal.MinorCurrent)
+
+// A Feature is a language feature that is only available as of a given
+// language version.
+type Feature struct {
+	// Name identifies the feature, as in "required-fields".
+	Name string
+
+	// Since holds the language version that introduced the feature.
+	Since string
+
+	// Doc is a one-line description of the feature.
+	Doc string
+}
+
+// String returns a human-readable description of the feature for use in
+// error messages.
+func (f *Feature) String() string {
+	return f.Doc
+}
+
+// AvailableIn reports whether f can be used in code written for the
+// given language version. An empty version means the current version.
+func (f *Feature) AvailableIn(version string) bool {
+	if version == "" {
+		return true
+	}
+	return semver.Compare(version, f.Since) >= 0
+}
+
+var (
+	// Definitions covers definitions using the #Name syntax.
+	Definitions = &Feature{
+		Name:  "definitions",
+		Since: "v0.3.0",
+		Doc:   "definitions using #",
+	}
+
+	// LetDeclarations covers let declarations and let clauses.
+	LetDeclarations = &Feature{
+		Name:  "let",
+		Since: "v0.3.0",
+		Doc:   "let declarations",
+	}
+
+	// RequiredFields covers fields marked as required with !.
+	RequiredFields = &Feature{
+		Name:  "required-fields",
+		Since: "v0.6.0",
+		Doc:   "required fields",
+	}
+)
+
+var features = []*Feature{
+	Definitions,
+	LetDeclarations,
+	RequiredFields,
+}
+
+// Features returns all known language features, ordered by the version
+// that introduced them.
+func Features() []*Feature {
+	return append([]*Feature(nil), features...)
+}
+
+// Lookup returns the feature with the given name, or nil if there is none.
+func Lookup(name string) *Feature {
+	for _, f := range features {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Available reports whether the named feature can be used in code written
+// for the given language version. It returns an error if the version is
+// not supported or the feature is unknown.
+func Available(version, name string) (bool, error) {
+	if err := Check(version); err != nil {
+		return false, err
+	}
+	f := Lookup(name)
+	if f == nil {
+		return false, fmt.Errorf("unknown language feature %q", name)
+	}
+	return f.AvailableIn(version), nil
+}
+
+// Check reports whether version is a valid language version that is
+// supported by this implementation of CUE.
+func Check(version string) error {
+	if !semver.IsValid(version) {
+		return fmt.Errorf("invalid language version %q", version)
+	}
+	if semver.Compare(version, Current) > 0 {
+		return fmt.Errorf("language version %s is newer than the latest supported version %s; try upgrading cue", version, Current)
+	}
+	return nil
+}
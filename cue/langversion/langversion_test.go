@@ -0,0 +1,72 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langversion_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/langversion"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+func TestAvailable(t *testing.T) {
+	for _, tc := range []struct {
+		version, feature string
+		want             bool
+		err              string
+	}{
+		{version: "v0.6.0", feature: "required-fields", want: true},
+		{version: "v0.5.3", feature: "required-fields", want: false},
+		{version: langversion.Current, feature: "let", want: true},
+		{version: "v0.2.2", feature: "definitions", want: false},
+		{version: "v0.6.0", feature: "unknown", err: `unknown language feature "unknown"`},
+		{version: "0.6.0", feature: "let", err: `invalid language version "0.6.0"`},
+		{version: "v99.0.0", feature: "let", err: `language version v99.0.0 is newer than the latest supported version .*`},
+	} {
+		got, err := langversion.Available(tc.version, tc.feature)
+		if tc.err != "" {
+			qt.Check(t, qt.ErrorMatches(err, tc.err))
+			continue
+		}
+		qt.Check(t, qt.IsNil(err))
+		qt.Check(t, qt.Equals(got, tc.want), qt.Commentf("%s in %s", tc.feature, tc.version))
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	fs := langversion.Features()
+	for i, f := range fs {
+		qt.Check(t, qt.Equals(langversion.Lookup(f.Name), f))
+		qt.Check(t, qt.IsTrue(semver.IsValid(f.Since)))
+		qt.Check(t, qt.IsTrue(f.AvailableIn(langversion.Current)))
+		qt.Check(t, qt.IsTrue(f.AvailableIn("")))
+		if i > 0 {
+			qt.Check(t, qt.IsTrue(semver.Compare(fs[i-1].Since, f.Since) <= 0))
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	qt.Assert(t, qt.IsNil(langversion.Check(langversion.Current)))
+	var minor, patch int
+	_, err := fmt.Sscanf(langversion.Current, "v0.%d.%d", &minor, &patch)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(internal.Version(minor, patch), parser.Latest))
+}
@@ -29,6 +29,7 @@ type Form struct {
 	exact       bool
 	asciiOnly   bool
 	graphicOnly bool
+	raw         bool
 	indent      string
 	tripleQuote string
 }
@@ -88,6 +89,14 @@ func (f Form) WithGraphicOnly() Form {
 	return f
 }
 
+// WithRaw returns a new Form that uses as many # characters as needed to
+// write quotes and backslashes without escaping them, as in #"\d+"#. The
+// result is an ordinary quoted string if s contains neither.
+func (f Form) WithRaw() Form {
+	f.raw = true
+	return f
+}
+
 var (
 	// String defines the format of a CUE string. Conversions may be lossy.
 	String Form = stringForm
@@ -131,7 +140,10 @@ func (f Form) Append(buf []byte, s string) []byte {
 	if f.auto && strings.ContainsRune(s, '\n') {
 		f.multiline = true
 	}
-	if f.multiline {
+	switch {
+	case f.raw:
+		f.hashCount = f.rawHashCount(s)
+	case f.multiline:
 		f.hashCount = f.requiredHashCount(s)
 	}
 
@@ -223,6 +235,12 @@ func (f Form) appendEscaped(buf []byte, s string) []byte {
 }
 
 func (f *Form) appendEscapedRune(buf []byte, r rune) []byte {
+	if f.raw && f.hashCount > 0 && (r == rune(f.quote) || r == '\\') {
+		// The hash count ensures these do not terminate the string or
+		// start an escape sequence.
+		buf = utf8.AppendRune(buf, r)
+		return buf
+	}
 	if (!f.multiline && r == rune(f.quote)) || r == '\\' { // always backslashed
 		buf = f.appendEscape(buf)
 		buf = append(buf, byte(r))
@@ -319,6 +337,21 @@ func (f *Form) requiredHashCount(s string) int {
 	return hashCount
 }
 
+// rawHashCount returns the smallest number of # characters for which s
+// can be quoted without escaping any quotes or backslashes.
+func (f *Form) rawHashCount(s string) int {
+	end := string(f.quote)
+	if f.multiline {
+		end = f.tripleQuote
+	}
+	for n := 0; ; n++ {
+		hashes := strings.Repeat("#", n)
+		if !strings.Contains(s, `\`+hashes) && !strings.Contains(s, end+hashes) {
+			return n
+		}
+	}
+}
+
 // isInGraphicList reports whether the rune is in the isGraphic list. This separation
 // from IsGraphic allows quoteWith to avoid two calls to IsPrint.
 // Should be called only if IsPrint fails.
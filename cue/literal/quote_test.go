@@ -94,6 +94,15 @@ func TestQuote(t *testing.T) {
 			foo
 			"""\#r\#f\#\
 			"""#`},
+		{form: String.WithRaw(), in: "a", out: `"a"`},
+		{form: String.WithRaw(), in: `\d+"x"`, out: `#"\d+"x""#`},
+		{form: String.WithRaw(), in: `a"#`, out: `##"a"#"##`},
+		{form: String.WithRaw(), in: `a\#b`, out: `##"a\#b"##`},
+		{form: String.WithRaw(), in: "\t\\", out: `#"\#t\"#`},
+		{form: String.WithRaw().WithTabIndent(3), in: "a\n\\b", out: `#"""
+			a
+			\b
+			"""#`},
 		{form: Bytes.WithTabIndent(3), in: "foo'''\nhello", out: `#'''
 			foo'''
 			hello
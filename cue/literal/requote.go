@@ -0,0 +1,107 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package literal
+
+import "strings"
+
+// A Style selects the form in which [Requote] writes a string literal.
+type Style int
+
+const (
+	// ReadableStyle keeps the form of a literal, unless it needs at least
+	// [MinEscapes] escape sequences and another form needs fewer. Of the
+	// forms needing the fewest escapes, the first of quoted, multiline,
+	// raw, and raw multiline is chosen.
+	ReadableStyle Style = iota
+
+	// QuotedStyle writes strings on a single line, as in "a\nb".
+	QuotedStyle
+
+	// MultilineStyle writes strings containing newlines as multiline
+	// strings and other strings on a single line.
+	MultilineStyle
+
+	// RawStyle is like MultilineStyle, but additionally uses # characters
+	// to avoid escaping quotes and backslashes, as in #"\d+"#.
+	RawStyle
+)
+
+// MinEscapes is the number of escape sequences for which ReadableStyle
+// considers a literal unreadable.
+const MinEscapes = 3
+
+// Requote returns the double-quoted string literal lit in the form
+// selected by style. Multiline results are indented by the given number of
+// tabs. Literals that cannot be unquoted, such as the fragments of an
+// interpolation, and bytes literals are returned unchanged.
+func Requote(lit string, style Style, indent int) string {
+	info, _, _, err := ParseQuotes(lit, lit)
+	if err != nil || !info.IsDouble() {
+		return lit
+	}
+	s, err := Unquote(lit)
+	if err != nil {
+		return lit
+	}
+	multiline := strings.ContainsRune(s, '\n')
+	quoted := String
+	long := String.WithTabIndent(indent)
+	switch style {
+	case QuotedStyle:
+		return quoted.Quote(s)
+	case MultilineStyle:
+		if multiline {
+			return long.Quote(s)
+		}
+		return quoted.Quote(s)
+	case RawStyle:
+		if multiline {
+			return long.WithRaw().Quote(s)
+		}
+		return quoted.WithRaw().Quote(s)
+	}
+
+	current := countEscapes(lit)
+	if current < MinEscapes {
+		return lit
+	}
+	forms := []Form{quoted, quoted.WithRaw()}
+	if multiline {
+		forms = []Form{quoted, long, quoted.WithRaw(), long.WithRaw()}
+	}
+	best, min := lit, current
+	for _, f := range forms {
+		q := f.Quote(s)
+		if n := countEscapes(q); n < min {
+			best, min = q, n
+		}
+	}
+	return best
+}
+
+// countEscapes returns the number of escape sequences in the string
+// literal lit.
+func countEscapes(lit string) int {
+	esc := `\` + lit[:len(lit)-len(strings.TrimLeft(lit, "#"))]
+	n := 0
+	for {
+		i := strings.Index(lit, esc)
+		if i < 0 || i+len(esc) >= len(lit) {
+			return n
+		}
+		n++
+		lit = lit[i+len(esc)+1:]
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package literal
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRequote(t *testing.T) {
+	testCases := []struct {
+		style Style
+		in    string
+		out   string
+	}{
+		{style: ReadableStyle, in: `"a"`, out: `"a"`},
+		{style: ReadableStyle, in: `"a\nb"`, out: `"a\nb"`},
+		{style: ReadableStyle, in: `"\\d+\\."`, out: `"\\d+\\."`},
+		{style: ReadableStyle, in: `"\\d+\\.\\d+\\.\\d+"`, out: `#"\d+\.\d+\.\d+"#`},
+		{style: ReadableStyle, in: `"\"a\" \"b\""`, out: `#""a" "b""#`},
+		{style: ReadableStyle, in: `"a\nb\nc\n"`, out: `"""
+		a
+		b
+		c
+
+		"""`},
+		{style: ReadableStyle, in: `"a\\\n\"b\"\n"`, out: `#"""
+		a\
+		"b"
+
+		"""#`},
+		{style: ReadableStyle, in: `#"a\b\c\d"#`, out: `#"a\b\c\d"#`},
+		{style: QuotedStyle, in: `#"\d"#`, out: `"\\d"`},
+		{style: QuotedStyle, in: "\"\"\"\n\ta\n\tb\n\t\"\"\"", out: `"a\nb"`},
+		{style: MultilineStyle, in: `"a\nb"`, out: `"""
+		a
+		b
+		"""`},
+		{style: MultilineStyle, in: `"a\\b"`, out: `"a\\b"`},
+		{style: RawStyle, in: `"a\\b"`, out: `#"a\b"#`},
+		{style: RawStyle, in: `"ab"`, out: `"ab"`},
+
+		// Interpolation fragments and bytes are left alone.
+		{style: QuotedStyle, in: `"a\(`, out: `"a\(`},
+		{style: RawStyle, in: `'a\\b'`, out: `'a\\b'`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			got := Requote(tc.in, tc.style, 2)
+			if got != tc.out {
+				t.Errorf("Requote: %s", cmp.Diff(tc.out, got))
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/sops"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/mod/modfile"
 	"cuelang.org/go/internal/mod/modload"
@@ -159,8 +160,11 @@ type Config struct {
 	// Each string is of the form
 	//
 	//     key [ "=" value ]
+	//     key "@=" file
 	//
-	// where key is a valid CUE identifier and value valid CUE scalar.
+	// where key is a valid CUE identifier and value valid CUE scalar. The
+	// second form uses the contents of the given file as the value. Relative
+	// file paths are interpreted relative to Dir.
 	//
 	// The Tags values are used to both select which files get included in a
 	// build and to inject values into the AST.
@@ -200,7 +204,9 @@ type Config struct {
 	//
 	//    field: x & 2
 	//
-	// Valid values for type are "int", "number", "bool", and "string".
+	// Valid values for type are "int", "number", "bool", "string", and
+	// "json". A value of type "json" may be any JSON value, including
+	// objects and arrays.
 	//
 	// A @tag attribute can also define shorthand values, which can be injected
 	// into the fields without having to specify the key. For instance, for
@@ -216,6 +222,22 @@ type Config struct {
 	//    environment: "prod" | "staging" @tag(env,short=prod|staging)
 	//
 	// ensures the user may only specify "prod" or "staging".
+	//
+	// A @tag attribute with the "required" option, as in
+	//
+	//    environment: string @tag(env,required)
+	//
+	// requires the tag to be set. The tags that are required but not set
+	// are reported together in a MissingTagsError.
+	//
+	// The "values" option restricts the values a tag may be set to, the
+	// "default" option specifies a value that is injected if the tag is not
+	// set, and the "doc" option describes the tag. For instance, for
+	//
+	//    replicas: int @tag(replicas,type=int,values=1|3|5,default=1,doc="number of replicas")
+	//
+	// the Tags entry "replicas=2" results in an error, and the field is set
+	// to 1 if no Tags entry sets replicas.
 	Tags []string
 
 	// TagVars defines a set of key value pair the values of which may be
@@ -248,6 +270,11 @@ type Config struct {
 	// to CUE.
 	DataFiles bool
 
+	// If ExplainImportCycles is set, an import cycle is reported as an
+	// *ImportCycleError, which describes each import of the cycle along
+	// with the references that cross it.
+	ExplainImportCycles bool
+
 	// StdRoot specifies an alternative directory for standard libraries.
 	// This is mostly used for bootstrapping.
 	StdRoot string
@@ -255,7 +282,9 @@ type Config struct {
 	// ParseFile is called to read and parse each file when preparing a
 	// package's syntax tree. It must be safe to call ParseFile simultaneously
 	// from multiple goroutines. If ParseFile is nil, the loader will uses
-	// parser.ParseFile.
+	// parser.ParseFile, passing the language version declared in the
+	// module's module.cue file, if any, with
+	// [cuelang.org/go/cue/parser.LanguageVersion].
 	//
 	// ParseFile should parse the source from src and use filename only for
 	// recording position information.
@@ -282,9 +311,45 @@ type Config struct {
 	// THIS IS EXPERIMENTAL FOR NOW. DO NOT USE.
 	Registry modload.Registry
 
+	// ImportResolver, if non-nil, is consulted for each imported package
+	// before the file system and Registry. It allows packages to be
+	// served from other sources, such as a database.
+	ImportResolver ImportResolver
+
+	// Decrypter, if non-nil, is used to decrypt YAML and JSON data files
+	// that are encrypted with SOPS, so that they can be used like any
+	// other data file. Only the files given as arguments, or all data
+	// files if DataFiles is set, are checked. As SOPS records its metadata
+	// at the end of a file, each file is read completely to check it, and
+	// its contents are kept as the Source of its build.File so that they
+	// are not read again. The plaintext of an encrypted file is kept in
+	// memory in the same way and is never written to disk.
+	Decrypter sops.Decrypter
+
 	fileSystem fileSystem
 }
 
+// An ImportResolver provides the files of imported packages.
+type ImportResolver interface {
+	// ResolveImport returns the files of the package with the given
+	// import path, keyed by file name. The path does not include a
+	// package qualifier; the files may define several packages, from
+	// which the qualifier of the import selects as usual.
+	//
+	// ResolveImport returns ok == false if it does not serve the path,
+	// in which case the import is resolved as if there were no
+	// ImportResolver.
+	ResolveImport(path string) (files map[string]Source, ok bool, err error)
+}
+
+// ImportResolverFunc is an ImportResolver implemented by a function.
+type ImportResolverFunc func(path string) (files map[string]Source, ok bool, err error)
+
+// ResolveImport implements ImportResolver.
+func (f ImportResolverFunc) ResolveImport(path string) (map[string]Source, bool, error) {
+	return f(path)
+}
+
 func (c *Config) stdin() io.Reader {
 	if c.Stdin == nil {
 		return os.Stdin
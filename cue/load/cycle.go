@@ -0,0 +1,197 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+)
+
+// An ImportCycleError describes a cycle of package imports. It is reported
+// instead of a PackageError if [Config.ExplainImportCycles] is set.
+type ImportCycleError struct {
+	// Imports holds the imports that make up the cycle, in order: each
+	// import is by the package imported by its predecessor, and the last
+	// one imports the package importing the first.
+	Imports []*CycleImport
+}
+
+// A CycleImport describes an import of a package by another within an
+// import cycle.
+type CycleImport struct {
+	From, To string // import paths
+
+	// Pos is the position of the import declaration.
+	Pos token.Pos
+
+	// References holds the references to the imported package by the
+	// importing one. A cycle can be broken at an import by moving the
+	// referenced values into the importing package, or into a new
+	// package imported by both.
+	References []CycleReference
+}
+
+// A CycleReference is a reference to an imported package.
+type CycleReference struct {
+	Pos  token.Pos
+	Expr string // for instance "b.x"
+}
+
+func newImportCycleError(paths []string, pkgs []*build.Instance, pos token.Pos) *ImportCycleError {
+	e := &ImportCycleError{}
+	for i, from := range paths {
+		to := paths[0]
+		if i+1 < len(paths) {
+			to = paths[i+1]
+		}
+		imp := &CycleImport{From: from, To: to}
+		if i == len(paths)-1 {
+			imp.Pos = pos
+		}
+		findReferences(imp, pkgs[i].Files)
+		e.Imports = append(e.Imports, imp)
+	}
+	return e
+}
+
+// findReferences records the references to imp.To in files.
+func findReferences(imp *CycleImport, files []*ast.File) {
+	for _, f := range files {
+		specs := map[*ast.ImportSpec]bool{}
+		for _, spec := range f.Imports {
+			if path, err := strconv.Unquote(spec.Path.Value); err == nil && path == imp.To {
+				specs[spec] = true
+				if !imp.Pos.IsValid() {
+					imp.Pos = spec.Pos()
+				}
+			}
+		}
+		if len(specs) == 0 {
+			continue
+		}
+		ast.Walk(f, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.SelectorExpr:
+				if id, ok := x.X.(*ast.Ident); ok && specs[asImportSpec(id.Node)] {
+					imp.References = append(imp.References, CycleReference{x.Pos(), exprString(x)})
+					return false
+				}
+			case *ast.Ident:
+				if specs[asImportSpec(x.Node)] {
+					imp.References = append(imp.References, CycleReference{x.Pos(), x.Name})
+				}
+			}
+			return true
+		}, nil)
+	}
+}
+
+func asImportSpec(n ast.Node) *ast.ImportSpec {
+	spec, _ := n.(*ast.ImportSpec)
+	return spec
+}
+
+func exprString(x ast.Expr) string {
+	b, err := format.Node(x)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Suggest returns the import that is easiest to remove to break the cycle:
+// the one with the fewest references.
+func (e *ImportCycleError) Suggest() *CycleImport {
+	var best *CycleImport
+	for _, imp := range e.Imports {
+		if best == nil || len(imp.References) < len(best.References) {
+			best = imp
+		}
+	}
+	return best
+}
+
+func (e *ImportCycleError) Position() token.Pos {
+	if n := len(e.Imports); n > 0 {
+		return e.Imports[n-1].Pos
+	}
+	return token.NoPos
+}
+
+func (e *ImportCycleError) InputPositions() []token.Pos {
+	var a []token.Pos
+	for _, imp := range e.Imports[:max(len(e.Imports)-1, 0)] {
+		a = append(a, imp.Pos)
+	}
+	return a
+}
+
+func (e *ImportCycleError) Path() []string { return nil }
+
+func (e *ImportCycleError) Msg() (string, []interface{}) {
+	var b strings.Builder
+	var args []interface{}
+	b.WriteString("package import cycle not allowed:")
+	for _, imp := range e.Imports {
+		b.WriteString("\n\t%s imports %s")
+		args = append(args, imp.From, imp.To)
+		switch n := len(imp.References); {
+		case n == 0:
+		case n <= 3:
+			exprs := make([]string, n)
+			for i, r := range imp.References {
+				exprs[i] = r.Expr
+			}
+			b.WriteString(" (references %s)")
+			args = append(args, strings.Join(exprs, ", "))
+		default:
+			b.WriteString(" (%d references)")
+			args = append(args, n)
+		}
+	}
+	if imp := e.Suggest(); imp != nil {
+		b.WriteString("\n\tconsider removing the import of %s by %s")
+		args = append(args, imp.To, imp.From)
+		if n := len(imp.References); n > 0 {
+			b.WriteString(", moving the referenced values to %s or a new package")
+			args = append(args, imp.From)
+		}
+	}
+	return b.String(), args
+}
+
+func (e *ImportCycleError) Error() string {
+	format, args := e.Msg()
+	return fmt.Sprintf(format, args...)
+}
+
+// ImportCycle returns the first import cycle reported in err, or nil if
+// there is none.
+func ImportCycle(err error) *ImportCycleError {
+	for _, err := range errors.Errors(err) {
+		var e *ImportCycleError
+		if errors.As(err, &e) {
+			return e
+		}
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ package load
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"cuelang.org/go/cue/build"
@@ -140,3 +141,41 @@ func (e *MultiplePackageError) Error() string {
 	format, args := e.Msg()
 	return fmt.Sprintf(format, args...)
 }
+
+// A MissingTagsError reports required injection tags that were not set.
+type MissingTagsError struct {
+	// Tags holds the names of the missing tags, in sorted order.
+	Tags []string
+
+	positions []token.Pos
+}
+
+func newMissingTagsError(tags []*tag) *MissingTagsError {
+	e := &MissingTagsError{}
+	seen := map[string]bool{}
+	for _, t := range tags {
+		e.positions = append(e.positions, t.pos)
+		if !seen[t.key] {
+			seen[t.key] = true
+			e.Tags = append(e.Tags, t.key)
+		}
+	}
+	sort.Strings(e.Tags)
+	return e
+}
+
+func (e *MissingTagsError) Position() token.Pos         { return token.NoPos }
+func (e *MissingTagsError) InputPositions() []token.Pos { return e.positions }
+func (e *MissingTagsError) Path() []string              { return nil }
+
+func (e *MissingTagsError) Msg() (string, []interface{}) {
+	if len(e.Tags) == 1 {
+		return "required tag %q not set", []interface{}{e.Tags[0]}
+	}
+	return "required tags not set: %s", []interface{}{strings.Join(e.Tags, ", ")}
+}
+
+func (e *MissingTagsError) Error() string {
+	format, args := e.Msg()
+	return fmt.Sprintf(format, args...)
+}
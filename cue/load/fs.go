@@ -68,35 +68,44 @@ func (fs *fileSystem) init(c *Config) error {
 
 	// Organize overlay
 	for filename, src := range overlay {
-		// TODO: do we need to further clean the path or check that the
-		// specified files are within the root/ absolute files?
-		dir, base := filepath.Split(filename)
-		m := fs.getDir(dir, true)
-
-		b, file, err := src.contents()
-		if err != nil {
+		if err := fs.addOverlay(filename, src); err != nil {
 			return err
 		}
-		m[base] = &overlayFile{
-			basename: base,
-			contents: b,
-			file:     file,
-			modtime:  time.Now(),
-		}
+	}
+	return nil
+}
 
-		for {
-			prevdir := dir
-			dir, base = filepath.Split(filepath.Dir(dir))
-			if dir == prevdir || dir == "" {
-				break
-			}
-			m := fs.getDir(dir, true)
-			if m[base] == nil {
-				m[base] = &overlayFile{
-					basename: base,
-					modtime:  time.Now(),
-					isDir:    true,
-				}
+// addOverlay adds the file with the given absolute name and its parent
+// directories to the overlay.
+func (fs *fileSystem) addOverlay(filename string, src Source) error {
+	// TODO: do we need to further clean the path or check that the
+	// specified files are within the root/ absolute files?
+	dir, base := filepath.Split(filename)
+	m := fs.getDir(dir, true)
+
+	b, file, err := src.contents()
+	if err != nil {
+		return err
+	}
+	m[base] = &overlayFile{
+		basename: base,
+		contents: b,
+		file:     file,
+		modtime:  time.Now(),
+	}
+
+	for {
+		prevdir := dir
+		dir, base = filepath.Split(filepath.Dir(dir))
+		if dir == prevdir || dir == "" {
+			break
+		}
+		m := fs.getDir(dir, true)
+		if m[base] == nil {
+			m[base] = &overlayFile{
+				basename: base,
+				modtime:  time.Now(),
+				isDir:    true,
 			}
 		}
 	}
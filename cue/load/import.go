@@ -63,8 +63,11 @@ func (l *loader) importPkg(pos token.Pos, p *build.Instance) []*build.Instance {
 		return []*build.Instance{p}
 	}
 
-	for _, item := range l.stk {
+	for i, item := range l.stk {
 		if item == p.ImportPath {
+			if l.cfg.ExplainImportCycles && len(l.pkgs) == len(l.stk) {
+				return retErr(newImportCycleError(l.stk[i:], l.pkgs[i:], pos))
+			}
 			return retErr(&PackageError{Message: errors.NewMessagef("package import cycle not allowed")})
 		}
 	}
@@ -200,7 +203,9 @@ func (l *loader) importPkg(pos token.Pos, p *build.Instance) []*build.Instance {
 		}
 
 		l.addFiles(cfg.ModuleRoot, p)
+		l.pkgs = append(l.pkgs, p)
 		_ = p.Complete()
+		l.pkgs = l.pkgs[:len(l.pkgs)-1]
 	}
 	sort.Slice(all, func(i, j int) bool {
 		return all[i].Dir < all[j].Dir
@@ -215,6 +220,20 @@ func (l *loader) _loadFunc(pos token.Pos, path string) *build.Instance {
 		return l.cfg.newErrInstance(errors.Newf(pos, "relative import paths not allowed (%q)", path))
 	}
 
+	// Packages served by the ImportResolver take precedence over builtins.
+	if l.cfg.ImportResolver != nil {
+		parts := module.ParseImportPath(path)
+		_, ok, err := l.resolveImport(pos, importPath(parts.Unqualified().String()))
+		if err != nil {
+			return l.cfg.newErrInstance(err)
+		}
+		if ok {
+			p := l.newInstance(pos, impPath)
+			_ = l.importPkg(pos, p)
+			return p
+		}
+	}
+
 	// is it a builtin?
 	if strings.IndexByte(strings.Split(path, "/")[0], '.') == -1 {
 		if l.cfg.StdRoot != "" {
@@ -247,6 +266,7 @@ func (l *loader) newRelInstance(pos token.Pos, path, pkgName string) *build.Inst
 	// p.ImportPath = string(dir) // compute unique ID.
 	p.Root = l.cfg.ModuleRoot
 	p.Module = l.cfg.Module
+	p.Experiments = l.cfg.experiments()
 
 	dir = filepath.Join(l.cfg.Dir, filepath.FromSlash(path))
 
@@ -321,6 +341,7 @@ func (l *loader) newInstance(pos token.Pos, p importPath) *build.Instance {
 	i.ImportPath = string(p)
 	i.Root = l.cfg.ModuleRoot
 	i.Module = l.cfg.Module
+	i.Experiments = l.cfg.experiments()
 	i.Err = errors.Append(i.Err, err)
 
 	return i
@@ -331,6 +352,13 @@ func (l *loader) newInstance(pos token.Pos, p importPath) *build.Instance {
 //
 // The returned directory may not exist.
 func (l *loader) absDirFromImportPath(pos token.Pos, p importPath) (absDir, name string, err errors.Error) {
+	if l.cfg.ImportResolver != nil {
+		parts := module.ParseImportPath(string(p))
+		dir, ok, err := l.resolveImport(pos, importPath(parts.Unqualified().String()))
+		if ok || err != nil {
+			return dir, parts.Qualifier, err
+		}
+	}
 	if l.cfg.ModuleRoot == "" {
 		return "", "", errors.Newf(pos, "cannot import %q (root undefined)", p)
 	}
@@ -377,6 +405,41 @@ func (l *loader) absDirFromImportPath(pos token.Pos, p importPath) (absDir, name
 	return absDir, name, err
 }
 
+// resolveImport adds the files of the package with import path p, as
+// provided by the ImportResolver, to the overlay and returns their
+// directory. It returns ok == false if the resolver does not serve p.
+func (l *loader) resolveImport(pos token.Pos, p importPath) (dir string, ok bool, err errors.Error) {
+	if dir, ok := l.resolved[p]; ok {
+		return dir, dir != "", nil
+	}
+	if l.resolved == nil {
+		l.resolved = map[importPath]string{}
+	}
+	files, ok, rerr := l.cfg.ImportResolver.ResolveImport(string(p))
+	if rerr != nil {
+		return "", false, errors.Wrapf(rerr, pos, "cannot resolve import %q", p)
+	}
+	if !ok {
+		l.resolved[p] = ""
+		return "", false, nil
+	}
+
+	// The files are placed in a directory that is outside of any module,
+	// so that they are not merged with files from parent directories.
+	dir = filepath.Join(filepath.VolumeName(l.cfg.Dir)+string(filepath.Separator),
+		"_resolved", filepath.FromSlash(string(p)))
+	for name, src := range files {
+		if name != filepath.Base(name) || name == "." || name == ".." {
+			return "", false, errors.Newf(pos, "invalid file name %q for import %q", name, p)
+		}
+		if err := l.cfg.fileSystem.addOverlay(filepath.Join(dir, name), src); err != nil {
+			return "", false, errors.Wrapf(err, pos, "cannot resolve import %q", p)
+		}
+	}
+	l.resolved[p] = dir
+	return dir, true, nil
+}
+
 func (l *loader) externalPackageDir(p importPath) (dir string, err error) {
 	if l.deps == nil {
 		return "", fmt.Errorf("no dependency found for import path %q (no dependencies at all)", p)
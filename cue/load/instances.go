@@ -20,10 +20,13 @@ package load
 //    - go/build
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/logging"
 	"cuelang.org/go/internal/filetypes"
 
 	// Trigger the unconditional loading of all core builtin packages if load
@@ -33,6 +36,8 @@ import (
 	_ "cuelang.org/go/pkg"
 )
 
+var logger = logging.For(logging.Loader)
+
 // Instances returns the instances named by the command line arguments 'args'.
 // If errors occur trying to load an instance it is returned with Incomplete
 // set. Errors directly related to loading the instance are recorded in this
@@ -47,9 +52,10 @@ func Instances(args []string, c *Config) []*build.Instance {
 		return []*build.Instance{c.newErrInstance(err)}
 	}
 	c = newC
+	logger.Debug("loading instances", "args", args, "dir", c.Dir, "moduleRoot", c.ModuleRoot)
 	// TODO use predictable location
 	var deps *dependencies
-	if c.Registry != nil {
+	if c.Registry != nil && c.modFile != nil {
 		deps1, err := resolveDependencies(c.modFile, c.Registry)
 		if err != nil {
 			return []*build.Instance{c.newErrInstance(fmt.Errorf("cannot resolve dependencies: %v", err))}
@@ -94,6 +100,17 @@ func Instances(args []string, c *Config) []*build.Instance {
 		a = append(a, l.cueFilesPackage(files))
 	}
 
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		for _, p := range a {
+			logger.Debug("loaded instance",
+				"path", p.ImportPath,
+				"dir", p.Dir,
+				"files", len(p.BuildFiles),
+				"imports", len(p.ImportPaths),
+				"err", p.Err)
+		}
+	}
+
 	for _, p := range a {
 		tags, err := findTags(p)
 		if err != nil {
@@ -22,8 +22,10 @@ package load
 import (
 	"path/filepath"
 
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal/encoding"
 
@@ -38,8 +40,14 @@ type loader struct {
 	cfg      *Config
 	tagger   *tagger
 	stk      importStack
+	pkgs     []*build.Instance // the instances being completed, parallel to stk
 	loadFunc build.LoadFunc
 	deps     *dependencies
+
+	// resolved maps import paths served by the ImportResolver to the
+	// directories holding their files in the overlay, or to "" if they
+	// are not served by it.
+	resolved map[importPath]string
 }
 
 func newLoader(c *Config, tg *tagger, deps *dependencies) *loader {
@@ -79,6 +87,7 @@ func (l *loader) cueFilesPackage(files []*build.File) *build.Instance {
 	cfg.filesMode = true
 	// ModInit() // TODO: support modules
 	pkg := l.cfg.Context.NewInstance(cfg.Dir, l.loadFunc)
+	pkg.Experiments = cfg.experiments()
 
 	for _, bf := range files {
 		f := bf.Filename
@@ -122,7 +131,9 @@ func (l *loader) cueFilesPackage(files []*build.File) *build.Instance {
 
 	pkg.User = true
 	l.stk.Push("user")
+	l.pkgs = append(l.pkgs, pkg)
 	_ = pkg.Complete()
+	l.pkgs = l.pkgs[:len(l.pkgs)-1]
 	l.stk.Pop()
 	pkg.User = true
 	//pkg.LocalPrefix = dirToImportPath(dir)
@@ -132,10 +143,23 @@ func (l *loader) cueFilesPackage(files []*build.File) *build.Instance {
 }
 
 func (l *loader) addFiles(dir string, p *build.Instance) {
+	if p.Dir != "" {
+		dir = p.Dir
+	}
+	parseFile := l.cfg.ParseFile
+	if parseFile == nil {
+		// Files in the main module are parsed according to the language
+		// version it declares. Dependencies live in a different module root.
+		if v := l.cfg.languageVersion(); v != "" && l.cfg.findRoot(dir) == l.cfg.ModuleRoot {
+			parseFile = func(name string, src interface{}) (*ast.File, error) {
+				return parser.ParseFile(name, src, parser.ParseComments, parser.LanguageVersion(v))
+			}
+		}
+	}
 	for _, f := range p.BuildFiles {
 		d := encoding.NewDecoder(f, &encoding.Config{
 			Stdin:     l.cfg.stdin(),
-			ParseFile: l.cfg.ParseFile,
+			ParseFile: parseFile,
 		})
 		for ; !d.Done(); d.Next() {
 			_ = p.AddSyntax(d.File())
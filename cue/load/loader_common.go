@@ -16,6 +16,8 @@ package load
 
 import (
 	"bytes"
+	"context"
+	"io"
 	"path"
 	pathpkg "path"
 	"path/filepath"
@@ -30,6 +32,7 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/sops"
 	"cuelang.org/go/internal"
 )
 
@@ -195,6 +198,9 @@ func (fp *fileProcessor) add(pos token.Pos, root string, file *build.File, mode
 
 	case err == nil:
 		// Not a CUE file.
+		if err := fp.decrypt(file); err != nil {
+			return badFile(err)
+		}
 		p.OrphanedFiles = append(p.OrphanedFiles, file)
 		return false
 
@@ -616,3 +622,60 @@ func inDirLex(path, dir string) string {
 		return ""
 	}
 }
+
+// decrypt replaces the source of a data file encrypted with SOPS with its
+// plaintext if a Decrypter is configured.
+func (fp *fileProcessor) decrypt(file *build.File) errors.Error {
+	c := fp.c
+	if c.Decrypter == nil || !(c.filesMode || c.DataFiles) {
+		return nil
+	}
+	var format sops.Format
+	switch file.Encoding {
+	case build.YAML:
+		format = sops.YAML
+	case build.JSON:
+		format = sops.JSON
+	default:
+		return nil
+	}
+
+	var data []byte
+	switch src := file.Source.(type) {
+	case nil:
+		var r io.Reader
+		if file.Filename == "-" {
+			r = c.stdin()
+		} else {
+			f, err := c.fileSystem.openFile(file.Filename)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return errors.Newf(token.NoPos, "read %s: %v", file.Filename, err)
+		}
+		data = b
+		// Keep the contents, so that they are not read again when the
+		// file is decoded; stdin cannot be read again at all.
+		file.Source = data
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return nil
+	}
+	if !sops.IsEncrypted(data, format) {
+		return nil
+	}
+	plain, err := c.Decrypter.Decrypt(context.Background(), data, format)
+	if err != nil {
+		return errors.Newf(token.NoPos, "cannot decrypt %s: %v", file.Filename, err)
+	}
+	file.Source = plain
+	return nil
+}
@@ -16,8 +16,12 @@ package load
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -25,8 +29,10 @@ import (
 	"unicode"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
+	"cuelang.org/go/encoding/sops"
 	"cuelang.org/go/internal/str"
 	"cuelang.org/go/internal/tdtest"
 )
@@ -352,6 +358,181 @@ imports:
 {{- end -}}
 `))
 
+func TestImportCycle(t *testing.T) {
+	cfg := &Config{
+		Dir:                 testMod("testmod"),
+		ExplainImportCycles: true,
+	}
+	insts := Instances([]string{"./cycle"}, cfg)
+	e := ImportCycle(insts[0].Err)
+	if e == nil {
+		t.Fatalf("no import cycle reported: %v", insts[0].Err)
+	}
+
+	var got []string
+	for _, imp := range e.Imports {
+		var refs []string
+		for _, r := range imp.References {
+			refs = append(refs, r.Expr)
+		}
+		got = append(got, fmt.Sprintf("%s -> %s: %s", imp.From, imp.To, strings.Join(refs, ", ")))
+	}
+	want := []string{
+		"mod.test/cycle/foo -> mod.test/cycle/bar: bar.#Bar1, bar.#Bar2",
+		"mod.test/cycle/bar -> mod.test/cycle/foo: foo.#Foo1, foo.#Foo2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	// Both imports have two references; the first one is suggested.
+	if imp := e.Suggest(); imp != e.Imports[0] {
+		t.Errorf("suggested %s -> %s; want the import of bar by foo", imp.From, imp.To)
+	}
+}
+
+func TestImportResolver(t *testing.T) {
+	cwd, _ := os.Getwd()
+	var resolved []string
+	c := &Config{
+		Overlay: map[string]Source{
+			filepath.Join(cwd, "cue.mod/module.cue"): FromString(`module: "mod.test"`),
+			filepath.Join(cwd, "main.cue"): FromString(`
+				package main
+
+				import (
+					"db.test/schemas/user"
+					"platform/defaults"
+					"strings"
+				)
+
+				u: user.#User & {name: strings.ToUpper(defaults.name)}
+			`),
+		},
+		ImportResolver: ImportResolverFunc(func(path string) (map[string]Source, bool, error) {
+			resolved = append(resolved, path)
+			switch path {
+			case "db.test/schemas/user":
+				return map[string]Source{
+					"user.cue": FromString(`package user, #User: {name: string, admin: *false | bool}`),
+				}, true, nil
+			case "platform/defaults":
+				return map[string]Source{
+					"defaults.cue": FromString(`package defaults, name: "guest"`),
+				}, true, nil
+			}
+			return nil, false, nil
+		}),
+	}
+	insts := cue.Build(Instances([]string{"./main.cue"}, c))
+	if err := insts[0].Err; err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	b, err := format.Node(insts[0].Value().Syntax(cue.Final()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n\tu: {\n\t\tname:  \"GUEST\"\n\t\tadmin: false\n\t}\n}"
+	if got := string(b); got != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+	sort.Strings(resolved)
+	if want := []string{"db.test/schemas/user", "platform/defaults", "strings"}; !reflect.DeepEqual(resolved, want) {
+		t.Errorf("resolved %q; want %q", resolved, want)
+	}
+
+	c.ImportResolver = ImportResolverFunc(func(path string) (map[string]Source, bool, error) {
+		return nil, false, fmt.Errorf("database unavailable")
+	})
+	insts = cue.Build(Instances([]string{"./main.cue"}, c))
+	if err := insts[0].Err; err == nil || !strings.Contains(err.Error(), "database unavailable") {
+		t.Errorf("got error %v; want database unavailable", err)
+	}
+}
+
+type fakeDecrypter map[string]string
+
+func (d fakeDecrypter) Decrypt(ctx context.Context, data []byte, f sops.Format) ([]byte, error) {
+	if s, ok := d[string(data)]; ok {
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("no key")
+}
+
+func TestDecrypter(t *testing.T) {
+	dir := t.TempDir()
+	secret := "password: ENC[AES256_GCM,data:x]\nsops:\n    mac: ENC[AES256_GCM,data:y]\n"
+	for name, content := range map[string]string{
+		"secret.yaml": secret,
+		"plain.yaml":  "user: admin\n",
+		"other.yaml":  strings.Replace(secret, "x", "z", 1),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := &Config{
+		Dir:       dir,
+		Decrypter: fakeDecrypter{secret: "password: hunter2\n"},
+	}
+	insts := Instances([]string{"secret.yaml", "plain.yaml"}, c)
+	if err := insts[0].Err; err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	var got []string
+	for _, f := range insts[0].OrphanedFiles {
+		got = append(got, fmt.Sprintf("%s: %s", filepath.Base(f.Filename), f.Source))
+	}
+	// The contents of files that are not encrypted are kept, so that
+	// they are not read again.
+	want := []string{"plain.yaml: user: admin\n", "secret.yaml: password: hunter2\n"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	insts = Instances([]string{"other.yaml"}, c)
+	if err := insts[0].Err; err == nil || !strings.Contains(err.Error(), "cannot decrypt") {
+		t.Errorf("got error %v; want cannot decrypt", err)
+	}
+}
+
+func TestExperiments(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("cue.mod/module.cue", `module: "mod.test", language: experiments: ["evalv3"]`)
+	write("x.cue", "package x\n\na: 1\n")
+
+	insts := Instances([]string{"."}, &Config{Dir: dir})
+	if err := insts[0].Err; err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	if got := fmt.Sprint(insts[0].Experiments); got != "[evalv3]" {
+		t.Errorf("got instance experiments %s", got)
+	}
+	// The experiments must be enabled in the context.
+	err := cuecontext.New().BuildInstance(insts[0]).Err()
+	if err == nil || !strings.Contains(err.Error(), `module mod.test enables evaluator experiment "evalv3", which is not enabled in the context`) {
+		t.Errorf("got error %v; want experiment not enabled", err)
+	}
+	ctx := cuecontext.New(cuecontext.Experiments(insts[0].Experiments...))
+	if err := ctx.BuildInstance(insts[0]).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	write("cue.mod/module.cue", `module: "mod.test", language: experiments: ["nope"]`)
+	insts = Instances([]string{"."}, &Config{Dir: dir})
+	if err := insts[0].Err; err == nil || !strings.Contains(err.Error(), `unknown evaluator experiment "nope"`) {
+		t.Errorf("got error %v; want unknown experiment", err)
+	}
+}
+
 func TestOverlays(t *testing.T) {
 	cwd, _ := os.Getwd()
 	abs := func(path string) string {
@@ -8,10 +8,14 @@ import (
 	"strings"
 
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/langversion"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/cueexperiment"
 	"cuelang.org/go/internal/mod/modfile"
 	"cuelang.org/go/internal/mod/modload"
+	"cuelang.org/go/internal/mod/modreplace"
 	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/modvendor"
 	"cuelang.org/go/internal/mod/mvs"
 	"cuelang.org/go/internal/mod/semver"
 )
@@ -48,7 +52,27 @@ func (c *Config) loadModule() error {
 	if err != nil {
 		return err
 	}
+	if mf.Language != nil && mf.Language.Version != "" {
+		if err := langversion.Check(mf.Language.Version); err != nil {
+			return errors.Newf(token.NoPos, "%s: %v", mod, err)
+		}
+	}
+	if mf.Language != nil {
+		for _, name := range mf.Language.Experiments {
+			if err := cueexperiment.Check(name); err != nil {
+				return errors.Newf(token.NoPos, "%s: %v", mod, err)
+			}
+		}
+	}
 	c.modFile = mf
+	if c.Registry != nil {
+		// Vendored modules take precedence, as they are already replaced.
+		c.Registry = modreplace.NewRegistry(c.Registry, mf, c.ModuleRoot)
+		c.Registry, err = modvendor.NewRegistry(c.Registry, mf, c.ModuleRoot)
+		if err != nil {
+			return err
+		}
+	}
 	if mf.Module == "" {
 		// Backward compatibility: allow empty module.cue file.
 		// TODO maybe check that the rest of the fields are empty too?
@@ -61,6 +85,24 @@ func (c *Config) loadModule() error {
 	return nil
 }
 
+// languageVersion returns the language version declared by the main
+// module, or the empty string if there is none.
+func (c *Config) languageVersion() string {
+	if c.modFile == nil || c.modFile.Language == nil {
+		return ""
+	}
+	return c.modFile.Language.Version
+}
+
+// experiments returns the names of the evaluator experiments enabled by
+// the main module.
+func (c *Config) experiments() []string {
+	if c.modFile == nil || c.modFile.Language == nil {
+		return nil
+	}
+	return c.modFile.Language.Experiments
+}
+
 type dependencies struct {
 	mainModule *modfile.File
 	versions   []module.Version
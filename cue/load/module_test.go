@@ -11,8 +11,8 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/internal/cuetxtar"
 	"cuelang.org/go/internal/mod/modcache"
-	"cuelang.org/go/internal/registrytest"
 	"cuelang.org/go/internal/txtarfs"
+	"cuelang.org/go/mod/modregistrytest"
 )
 
 func TestModuleFetch(t *testing.T) {
@@ -25,7 +25,7 @@ func TestModuleFetch(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		r, err := registrytest.New(rfs, "")
+		r, err := modregistrytest.New(rfs, "")
 		if err != nil {
 			t.Fatal(err)
 		}
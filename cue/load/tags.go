@@ -19,6 +19,7 @@ import (
 	"encoding/hex"
 	"os"
 	"os/user"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -29,6 +30,7 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/json"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/cli"
 )
@@ -118,16 +120,70 @@ func varToString(s string, err error) (ast.Expr, error) {
 	return x, nil
 }
 
+// A Tag describes an injection tag declared by a @tag attribute.
+type Tag struct {
+	// Name is the name used to set the tag.
+	Name string
+
+	// Type is the type of the value of the tag: one of "string", "int",
+	// "number", "bool", or "json".
+	Type string
+
+	// Shorthands lists the values that may be used to set the tag without
+	// specifying its name.
+	Shorthands []string
+
+	// Values lists the values the tag may be set to. Any value is allowed
+	// if it is empty.
+	Values []string
+
+	// Default is the value injected if the tag is not set, if HasDefault
+	// is true.
+	Default    string
+	HasDefault bool
+
+	// Doc is a short description of the tag.
+	Doc string
+
+	// Var is the name of the tag variable injected if the tag is not set,
+	// if any.
+	Var string
+
+	// Required reports whether the tag must be set.
+	Required bool
+
+	// Pos is the position of the @tag attribute.
+	Pos token.Pos
+}
+
+// Tags returns the injection tags declared in the files of b, in the
+// order in which they appear.
+func Tags(b *build.Instance) ([]*Tag, error) {
+	tags, err := findTags(b)
+	a := make([]*Tag, len(tags))
+	for i, t := range tags {
+		a[i] = t.info()
+	}
+	if err != nil {
+		return a, err
+	}
+	return a, nil
+}
+
 // A tag binds an identifier to a field to allow passing command-line values.
 //
 // A tag is of the form
 //
-//	@tag(<name>,[type=(string|int|number|bool)][,short=<shorthand>+])
+//	@tag(<name>,[type=(string|int|number|bool|json)][,short=<shorthand>+][,values=<value>+]
+//		[,default=<value>][,doc=<description>][,required])
 //
 // The name is mandatory and type defaults to string. Tags are set using the -t
 // option on the command line. -t name=value will parse value for the type
 // defined for name and set the field for which this tag was defined to this
-// value. A tag may be associated with multiple fields.
+// value, and -t name@=file does the same with the contents of file. A tag may
+// be associated with multiple fields. It is an error for a required tag not
+// to be set. If values are given, it is an error to set the tag to any other
+// value. The default value is injected if the tag is not set.
 //
 // Tags also allow shorthands. If a shorthand bar is declared for a tag with
 // name foo, then -t bar is identical to -t foo=bar.
@@ -138,16 +194,24 @@ func varToString(s string, err error) (ast.Expr, error) {
 // same shorthand, duplicating functionality that is already available in CUE.
 type tag struct {
 	key            string
+	typ            string
 	kind           cue.Kind
 	shorthands     []string
+	values         []string
+	deflt          string
+	hasDefault     bool
+	doc            string
 	vars           string // -T flag
+	required       bool
 	hasReplacement bool
 
+	pos   token.Pos
 	field *ast.Field
 }
 
 func parseTag(pos token.Pos, body string) (t *tag, err errors.Error) {
-	t = &tag{}
+	t = &tag{pos: pos}
+	t.typ = "string"
 	t.kind = cue.StringKind
 
 	a := internal.ParseAttrBody(pos, body)
@@ -166,9 +230,12 @@ func parseTag(pos token.Pos, body string) (t *tag, err errors.Error) {
 			t.kind = cue.NumberKind
 		case "bool":
 			t.kind = cue.BoolKind
+		case "json":
+			t.kind = cue.TopKind
 		default:
 			return t, errors.Newf(pos, "invalid type %q", s)
 		}
+		t.typ = s
 	}
 
 	if s, ok, _ := a.Lookup(1, "short"); ok {
@@ -184,15 +251,133 @@ func parseTag(pos token.Pos, body string) (t *tag, err errors.Error) {
 		t.vars = s
 	}
 
+	if s, ok, _ := a.Lookup(1, "values"); ok {
+		if t.typ == "json" {
+			return t, errors.Newf(pos, "values not supported for type json")
+		}
+		t.values = strings.Split(s, "|")
+		for _, s := range t.shorthands {
+			if !t.allows(s) {
+				return t, errors.Newf(pos, "shorthand %q is not an allowed value", s)
+			}
+		}
+	}
+
+	if s, ok, _ := a.Lookup(1, "doc"); ok {
+		t.doc = s
+	}
+
+	t.required, _ = a.Flag(1, "required")
+
+	if s, ok, _ := a.Lookup(1, "default"); ok {
+		if t.required {
+			return t, errors.Newf(pos, "required tag %q cannot have a default", t.key)
+		}
+		if err := t.check(s); err != nil {
+			return t, err
+		}
+		if _, err := t.parseValue(t.key, s); err != nil {
+			return t, errors.Wrapf(err, pos, "invalid default for tag %q", t.key)
+		}
+		t.deflt, t.hasDefault = s, true
+	}
+
 	return t, nil
 }
 
-func (t *tag) inject(value string, tg *tagger) errors.Error {
-	e, err := cli.ParseValue(token.NoPos, t.key, value, t.kind)
+// allows reports whether value is one of the allowed values of t.
+func (t *tag) allows(value string) bool {
+	if t.values == nil {
+		return true
+	}
+	if t.kind != cue.StringKind {
+		value = strings.TrimSpace(value)
+	}
+	for _, v := range t.values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// check reports an error if value is not an allowed value of t.
+func (t *tag) check(value string) errors.Error {
+	if t.allows(value) {
+		return nil
+	}
+	return errors.Newf(t.pos, "invalid value %q for tag %q: must be one of %s",
+		value, t.key, strings.Join(t.values, ", "))
+}
+
+func (t *tag) info() *Tag {
+	return &Tag{
+		Name:       t.key,
+		Type:       t.typ,
+		Shorthands: t.shorthands,
+		Values:     t.values,
+		Default:    t.deflt,
+		HasDefault: t.hasDefault,
+		Doc:        t.doc,
+		Var:        t.vars,
+		Required:   t.required,
+		Pos:        t.pos,
+	}
+}
+
+// inject injects value, read from the source with the given name, into the
+// field of t.
+func (t *tag) inject(name, value string, tg *tagger) errors.Error {
+	if err := t.check(value); err != nil {
+		return err
+	}
+	e, err := t.parseValue(name, value)
 	t.injectValue(e, tg)
 	return err
 }
 
+func (t *tag) parseValue(name, value string) (ast.Expr, errors.Error) {
+	switch t.typ {
+	case "int", "number":
+		// ParseValue accepts any expression for numbers.
+		x, err := cli.ParseValue(token.NoPos, t.key, value, t.kind)
+		if err == nil && !isNumber(x, t.kind) {
+			err = errors.Newf(t.pos, "invalid %s value %q for tag %q", t.typ, value, t.key)
+		}
+		return x, err
+	case "json":
+	default:
+		return cli.ParseValue(token.NoPos, t.key, value, t.kind)
+	}
+	x, err := json.Extract(name, []byte(value))
+	if err != nil {
+		// Report the underlying error, as the error of Extract refers to
+		// name as a file, which it need not be.
+		if u := errors.Unwrap(err); u != nil {
+			err = u
+		}
+		return nil, errors.Newf(token.NoPos,
+			"invalid JSON for injection tag %q: %v", t.key, err)
+	}
+	return x, nil
+}
+
+// isNumber reports whether x is a number literal of the given kind.
+func isNumber(x ast.Expr, k cue.Kind) bool {
+	if u, ok := x.(*ast.UnaryExpr); ok && (u.Op == token.SUB || u.Op == token.ADD) {
+		x = u.X
+	}
+	lit, ok := x.(*ast.BasicLit)
+	switch {
+	case !ok:
+		return false
+	case lit.Kind == token.INT:
+		return true
+	default:
+		return lit.Kind == token.FLOAT && k == cue.NumberKind
+	}
+}
+
 func (t *tag) injectValue(x ast.Expr, tg *tagger) {
 	injected := ast.NewBinExpr(token.AND, t.field.Value, x)
 	if tg.replacements == nil {
@@ -265,23 +450,36 @@ func (tg *tagger) injectTags(tags []string) errors.Error {
 		p := strings.Index(s, "=")
 		found := tg.buildTags[s]
 		if p > 0 { // key-value
+			key, name, value := s[:p], s[:p], s[p+1:]
+			if k, ok := strings.CutSuffix(key, "@"); ok { // key@=file
+				key, name = k, value
+				if !filepath.IsAbs(name) {
+					name = filepath.Join(tg.cfg.Dir, name)
+				}
+				b, err := os.ReadFile(name)
+				if err != nil {
+					return errors.Wrapf(err, token.NoPos,
+						"cannot read file for tag %q", key)
+				}
+				value = string(b)
+			}
 			for _, t := range tg.tags {
-				if t.key == s[:p] {
+				if t.key == key {
 					found = true
-					if err := t.inject(s[p+1:], tg); err != nil {
+					if err := t.inject(name, value, tg); err != nil {
 						return err
 					}
 				}
 			}
 			if !found {
-				return errors.Newf(token.NoPos, "no tag for %q", s[:p])
+				return errors.Newf(token.NoPos, "no tag for %q", key)
 			}
 		} else { // shorthand
 			for _, t := range tg.tags {
 				for _, sh := range t.shorthands {
 					if sh == s {
 						found = true
-						if err := t.inject(s, tg); err != nil {
+						if err := t.inject(t.key, s, tg); err != nil {
 							return err
 						}
 					}
@@ -321,6 +519,25 @@ func (tg *tagger) injectTags(tags []string) errors.Error {
 			}
 		}
 	}
+
+	// Inject defaults if the tag wasn't otherwise set.
+	for _, t := range tg.tags {
+		if !t.hasReplacement && t.hasDefault {
+			if err := t.inject(t.key, t.deflt, tg); err != nil {
+				return err
+			}
+		}
+	}
+
+	var missing []*tag
+	for _, t := range tg.tags {
+		if t.required && !t.hasReplacement {
+			missing = append(missing, t)
+		}
+	}
+	if len(missing) > 0 {
+		return newMissingTagsError(missing)
+	}
 	return nil
 }
 
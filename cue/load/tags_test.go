@@ -16,6 +16,7 @@ package load
 
 import (
 	"bytes"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -42,11 +43,16 @@ func stringVar(s string) TagVar {
 
 func TestTags(t *testing.T) {
 	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"a": [1, 2]}`), 0o666)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	testCases := []struct {
-		in  string
-		out string
-		err string
+		in   string
+		tags []string
+		out  string
+		err  string
 	}{{
 		in: `
 		rand: int    @tag(foo,var=rand)
@@ -84,6 +90,85 @@ func TestTags(t *testing.T) {
 		u1: string @tag(bar,var=user)
 		`,
 		err: `tag variable 'user' not found`,
+	}, {
+		in: `
+		x: {a: [...int]} @tag(x,type=json)
+		y: _             @tag(y,type=json)
+		`,
+		tags: []string{`x={"a": [1]}`, `y="str"`},
+		out: `{
+			x: a: [1]
+			y: "str"
+		}`,
+	}, {
+		in: `
+		x: _ @tag(x,type=json)
+		`,
+		tags: []string{`x={"a": 1`},
+		err:  `invalid JSON for injection tag "x": unexpected end of JSON input`,
+	}, {
+		in: `
+		x: _   @tag(x,type=json)
+		s: string @tag(s)
+		`,
+		tags: []string{`x@=config.json`, `s@=` + filepath.Join(dir, "config.json")},
+		out: `{
+			x: a: [1, 2]
+			s: "{\"a\": [1, 2]}"
+		}`,
+	}, {
+		in: `
+		a: string @tag(a,required)
+		b: string @tag(b,required)
+		c: string @tag(c,required,short=cc)
+		d: string @tag(d,required,var=os)
+		`,
+		tags: []string{`cc`},
+		err:  `required tags not set: a, b`,
+	}, {
+		in: `
+		env:      string @tag(env,values=prod|staging,default=staging)
+		replicas: int    @tag(replicas,type=int,default=1)
+		debug:    bool   @tag(debug,type=bool,default=false)
+		`,
+		tags: []string{`replicas=3`},
+		out: `{
+			env:      "staging"
+			replicas: 3
+			debug:    false
+		}`,
+	}, {
+		in: `
+		env: string @tag(env,values=prod|staging)
+		`,
+		tags: []string{`env=dev`},
+		err:  `invalid value "dev" for tag "env": must be one of prod, staging`,
+	}, {
+		in: `
+		n: int @tag(n,type=int)
+		`,
+		tags: []string{`n=a + 1`},
+		err:  `invalid int value "a + 1" for tag "n"`,
+	}, {
+		in: `
+		env: string @tag(env,short=dev,values=prod|staging)
+		`,
+		err: `shorthand "dev" is not an allowed value`,
+	}, {
+		in: `
+		env: string @tag(env,values=prod|staging,default=dev)
+		`,
+		err: `invalid value "dev" for tag "env": must be one of prod, staging`,
+	}, {
+		in: `
+		env: string @tag(env,required,default=dev)
+		`,
+		err: `required tag "env" cannot have a default`,
+	}, {
+		in: `
+		n: number @tag(n,type=number,default=x)
+		`,
+		err: `invalid default for tag "n": invalid number value "x" for tag "n"`,
 	}}
 
 	for _, tc := range testCases {
@@ -93,6 +178,7 @@ func TestTags(t *testing.T) {
 				Overlay: map[string]Source{
 					filepath.Join(dir, "foo.cue"): FromString(tc.in),
 				},
+				Tags:    tc.tags,
 				TagVars: testTagVars,
 			}
 			b := Instances([]string{"foo.cue"}, cfg)[0]
@@ -0,0 +1,201 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging is an experimental package for obtaining diagnostic
+// logs from the CUE loader, module registry client, evaluator, and
+// tools/flow.
+//
+// Logging is disabled by default. Programs embedding CUE can enable it by
+// installing a [log/slog] handler with [SetHandler]. Each record carries a
+// "component" attribute naming the part of CUE that logged it, so that a
+// handler may filter or route records by component.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// A Component identifies a part of CUE that logs.
+type Component string
+
+const (
+	// Loader logs the resolution of packages and files by cue/load.
+	Loader Component = "loader"
+
+	// Registry logs module resolution and requests to module registries.
+	Registry Component = "registry"
+
+	// Evaluator logs the building and evaluation of instances.
+	Evaluator Component = "evaluator"
+
+	// Flow logs the scheduling and execution of tools/flow tasks.
+	Flow Component = "flow"
+)
+
+// ComponentKey is the attribute key under which the component of a
+// record is recorded.
+const ComponentKey = "component"
+
+type handlerBox struct{ h slog.Handler }
+
+var current atomic.Pointer[handlerBox]
+
+// SetHandler sets the handler to which all CUE components log. A nil
+// handler disables logging. Loggers obtained with [For] before calling
+// SetHandler log to the new handler.
+func SetHandler(h slog.Handler) {
+	if h == nil {
+		current.Store(nil)
+		return
+	}
+	current.Store(&handlerBox{h})
+}
+
+// Handler returns the handler set with [SetHandler], or nil if logging
+// is disabled.
+func Handler() slog.Handler {
+	if b := current.Load(); b != nil {
+		return b.h
+	}
+	return nil
+}
+
+// Enabled reports whether records of the given level logged by the given
+// component would be handled. It allows callers to avoid computing
+// expensive log messages.
+func Enabled(c Component, level slog.Level) bool {
+	return For(c).Enabled(context.Background(), level)
+}
+
+// For returns a logger for the given component.
+func For(c Component) *slog.Logger {
+	return slog.New(&handler{
+		ops: []op{{attrs: []slog.Attr{slog.String(ComponentKey, string(c))}}},
+	})
+}
+
+// handler forwards records to the handler set with SetHandler at the
+// time of logging.
+type handler struct {
+	ops []op
+}
+
+// An op is a call to WithAttrs or WithGroup, recorded so that it can be
+// applied to the handler that is current at the time of logging.
+type op struct {
+	group string
+	attrs []slog.Attr
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	cur := h.resolve()
+	return cur != nil && cur.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	cur := h.resolve()
+	if cur == nil {
+		return nil
+	}
+	return cur.Handle(ctx, r)
+}
+
+// resolve returns the current handler with the recorded operations
+// applied, or nil if logging is disabled.
+func (h *handler) resolve() slog.Handler {
+	cur := Handler()
+	if cur == nil {
+		return nil
+	}
+	for _, o := range h.ops {
+		if o.group != "" {
+			cur = cur.WithGroup(o.group)
+		} else {
+			cur = cur.WithAttrs(o.attrs)
+		}
+	}
+	return cur
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(op{attrs: attrs})
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(op{group: name})
+}
+
+func (h *handler) with(o op) *handler {
+	ops := make([]op, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &handler{ops: append(ops, o)}
+}
+
+// LevelFilter returns a handler that passes records to h if their level
+// is at least the level configured for their component in levels, or
+// defaultLevel if the component is not in levels.
+func LevelFilter(h slog.Handler, defaultLevel slog.Leveler, levels map[Component]slog.Leveler) slog.Handler {
+	return &levelFilter{
+		next:         h,
+		defaultLevel: defaultLevel,
+		levels:       levels,
+	}
+}
+
+type levelFilter struct {
+	next         slog.Handler
+	defaultLevel slog.Leveler
+	levels       map[Component]slog.Leveler
+	component    Component
+}
+
+func (h *levelFilter) minLevel() slog.Level {
+	if l, ok := h.levels[h.component]; ok {
+		return l.Level()
+	}
+	return h.defaultLevel.Level()
+}
+
+func (h *levelFilter) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.minLevel() && h.next.Enabled(ctx, level)
+}
+
+func (h *levelFilter) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.minLevel() {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *levelFilter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	for _, a := range attrs {
+		if a.Key == ComponentKey {
+			c.component = Component(a.Value.String())
+		}
+	}
+	c.next = h.next.WithAttrs(attrs)
+	return &c
+}
+
+func (h *levelFilter) WithGroup(name string) slog.Handler {
+	c := *h
+	c.next = h.next.WithGroup(name)
+	return &c
+}
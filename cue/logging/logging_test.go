@@ -0,0 +1,76 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging_test
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/logging"
+)
+
+func newHandler(b *strings.Builder, level slog.Level) slog.Handler {
+	return slog.NewTextHandler(b, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+}
+
+func TestSetHandler(t *testing.T) {
+	defer logging.SetHandler(nil)
+
+	// Loggers obtained before a handler is set use the handler that is
+	// current when logging.
+	logger := logging.For(logging.Loader).With("x", 1).WithGroup("g")
+	logger.Info("dropped")
+	qt.Assert(t, qt.IsFalse(logging.Enabled(logging.Loader, slog.LevelError)))
+
+	var b strings.Builder
+	logging.SetHandler(newHandler(&b, slog.LevelInfo))
+	logger.Info("hello", "y", 2)
+	logger.Debug("too low")
+	qt.Assert(t, qt.Equals(b.String(), "level=INFO msg=hello component=loader x=1 g.y=2\n"))
+	qt.Assert(t, qt.IsTrue(logging.Enabled(logging.Loader, slog.LevelInfo)))
+	qt.Assert(t, qt.IsFalse(logging.Enabled(logging.Loader, slog.LevelDebug)))
+
+	logging.SetHandler(nil)
+	logger.Info("dropped")
+	qt.Assert(t, qt.Equals(b.String(), "level=INFO msg=hello component=loader x=1 g.y=2\n"))
+}
+
+func TestLevelFilter(t *testing.T) {
+	defer logging.SetHandler(nil)
+
+	var b strings.Builder
+	logging.SetHandler(logging.LevelFilter(newHandler(&b, slog.LevelDebug), slog.LevelWarn, map[logging.Component]slog.Leveler{
+		logging.Registry: slog.LevelDebug,
+	}))
+	logging.For(logging.Registry).Debug("registry debug")
+	logging.For(logging.Loader).Info("loader info")
+	logging.For(logging.Loader).Warn("loader warn")
+	qt.Assert(t, qt.Equals(b.String(), `level=DEBUG msg="registry debug" component=registry
+level=WARN msg="loader warn" component=loader
+`))
+	qt.Assert(t, qt.IsTrue(logging.Enabled(logging.Registry, slog.LevelDebug)))
+	qt.Assert(t, qt.IsFalse(logging.Enabled(logging.Flow, slog.LevelInfo)))
+}
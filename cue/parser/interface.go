@@ -17,11 +17,14 @@
 package parser
 
 import (
+	"fmt"
+
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/mod/semver"
 	"cuelang.org/go/internal/source"
 )
 
@@ -93,6 +96,22 @@ func FromVersion(version int) Option {
 	return func(p *parser) { p.version = version }
 }
 
+// LanguageVersion specifies the version of the CUE language the source is
+// written in, as declared by the language.version field of a module.
+// Features introduced after that version are reported as errors, and
+// deprecated features that were still supported at that version are
+// accepted. See package [cuelang.org/go/cue/langversion].
+func LanguageVersion(version string) Option {
+	return func(p *parser) {
+		p.langVersion = version
+		if semver.Major(version) == "v0" && semver.IsValid(version) {
+			var minor, patch int
+			fmt.Sscanf(semver.Canonical(version), "v0.%d.%d", &minor, &patch)
+			p.version = internal.Version(minor, patch)
+		}
+	}
+}
+
 // DeprecationError is a sentinel error to indicate that an error is
 // related to an unsupported old CUE syntax.
 type DeprecationError struct {
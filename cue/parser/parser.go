@@ -21,6 +21,7 @@ import (
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/langversion"
 	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/scanner"
 	"cuelang.org/go/cue/token"
@@ -52,6 +53,8 @@ type parser struct {
 	tok token.Token // one token look-ahead
 	lit string      // token literal
 
+	prevEnd token.Pos // end position of the previous token
+
 	// Error recovery
 	// (used to limit the number of calls to syncXXX functions
 	// w/o making scanning progress - avoids potential endless
@@ -65,6 +68,10 @@ type parser struct {
 	imports []*ast.ImportSpec // list of imports
 
 	version int
+
+	// langVersion holds the language version the source is written in,
+	// if specified. Features introduced after it are reported as errors.
+	langVersion string
 }
 
 func (p *parser) init(filename string, src []byte, mode []Option) {
@@ -73,6 +80,11 @@ func (p *parser) init(filename string, src []byte, mode []Option) {
 		f(p)
 	}
 	p.file = token.NewFile(filename, p.offset, len(src))
+	if p.langVersion != "" {
+		if err := langversion.Check(p.langVersion); err != nil {
+			p.errors = errors.Append(p.errors, errors.Newf(token.NoPos, "%v", err))
+		}
+	}
 
 	var m scanner.Mode
 	if p.mode&parseCommentsMode != 0 {
@@ -348,6 +360,7 @@ func (p *parser) next() {
 	}
 	p.leadComment = nil
 	prev := p.pos
+	p.prevEnd = p.tokenEnd()
 	p.next0()
 	p.comments.pos++
 
@@ -406,9 +419,21 @@ func (p *parser) assertV0(pos token.Pos, minor, patch int, name string) {
 	}
 }
 
+// requireFeature reports an error if the feature f is not available in the
+// language version of the source.
+func (p *parser) requireFeature(pos token.Pos, f *langversion.Feature) {
+	if !f.AvailableIn(p.langVersion) {
+		p.errf(pos, "%s are only available as of language version %s (language version is %s)",
+			f, f.Since, p.langVersion)
+	}
+}
+
 func (p *parser) errf(pos token.Pos, msg string, args ...interface{}) {
-	// ePos := p.file.Position(pos)
-	ePos := pos
+	p.addError(errors.Newf(pos, msg, args...))
+}
+
+func (p *parser) addError(err errors.Error) {
+	ePos := err.Position()
 
 	// If AllErrors is not set, discard errors reported on the same line
 	// as the last recorded error and stop parsing if there are more than
@@ -425,7 +450,32 @@ func (p *parser) errf(pos token.Pos, msg string, args ...interface{}) {
 		}
 	}
 
-	p.errors = errors.Append(p.errors, errors.Newf(ePos, msg, args...))
+	p.errors = errors.Append(p.errors, err)
+}
+
+// tokenEnd returns the end position of the current token.
+func (p *parser) tokenEnd() token.Pos {
+	switch {
+	case p.tok == token.COMMA && p.lit == "\n":
+		// Inserted comma.
+		return p.pos
+	case p.lit != "":
+		return p.pos.Add(len(p.lit))
+	}
+	return p.pos.Add(len(p.tok.String()))
+}
+
+// errMissingComma reports a missing comma, suggesting to insert it after
+// the previous token.
+func (p *parser) errMissingComma(msg string, args ...interface{}) {
+	err := errors.Newf(p.pos, msg, args...)
+	if p.prevEnd.IsValid() {
+		err = errors.WithSuggestions(err, errors.Suggestion{
+			Message: "insert ','",
+			Edits:   []errors.Edit{{Pos: p.prevEnd, End: p.prevEnd, NewText: ","}},
+		})
+	}
+	p.addError(err)
 }
 
 func (p *parser) errorExpected(pos token.Pos, obj string) {
@@ -460,7 +510,7 @@ func (p *parser) expect(tok token.Token) token.Pos {
 // for the common case of a missing comma before a newline.
 func (p *parser) expectClosing(tok token.Token, context string) token.Pos {
 	if p.tok != tok && p.tok == token.COMMA && p.lit == "\n" {
-		p.errf(p.pos, "missing ',' before newline in %s", context)
+		p.errMissingComma("missing ',' before newline in %s", context)
 		p.next()
 	}
 	return p.expect(tok)
@@ -490,9 +540,9 @@ func (p *parser) atComma(context string, follow ...token.Token) bool {
 	}
 	// TODO: find a way to detect crossing lines now we don't have a semi.
 	if p.lit == "\n" {
-		p.errf(p.pos, "missing ',' before newline")
+		p.errMissingComma("missing ',' before newline")
 	} else {
-		p.errf(p.pos, "missing ',' in %s", context)
+		p.errMissingComma("missing ',' in %s", context)
 	}
 	return true // "insert" comma and continue
 }
@@ -559,6 +609,9 @@ func (p *parser) parseIdent() *ast.Ident {
 	name := "_"
 	if p.tok == token.IDENT {
 		name = p.lit
+		if strings.HasPrefix(name, "#") || strings.HasPrefix(name, "_#") {
+			p.requireFeature(pos, langversion.Definitions)
+		}
 		p.next()
 	} else {
 		p.expect(token.IDENT) // use expect() error handling
@@ -783,6 +836,7 @@ func (p *parser) parseLetDecl() (decl ast.Decl, ident *ast.Ident) {
 		}
 	}
 	defer func() { c.closeNode(p, decl) }()
+	p.requireFeature(letPos, langversion.LetDeclarations)
 
 	ident = p.parseIdent()
 	assign := p.expect(token.BIND)
@@ -862,6 +916,20 @@ func (p *parser) parseField() (decl ast.Decl) {
 			p.consumeDeclComma()
 			return a
 		}
+		if name, ok := unquotedLabel(expr); ok && p.tok == token.COLON {
+			// A label such as foo-bar that needs quoting.
+			quoted := literal.Label.Quote(name)
+			p.addError(errors.WithSuggestions(
+				errors.Newf(expr.Pos(), "invalid label %s; must be quoted as %s", name, quoted),
+				errors.Suggestion{
+					Message: "quote label " + name,
+					Edits:   []errors.Edit{{Pos: expr.Pos(), End: expr.End(), NewText: quoted}},
+				}))
+			p.next() // :
+			p.parseRHS()
+			p.consumeDeclComma()
+			return &ast.BadDecl{From: pos, To: p.pos}
+		}
 		e := &ast.EmbedDecl{Expr: expr}
 		p.consumeDeclComma()
 		return e
@@ -869,6 +937,9 @@ func (p *parser) parseField() (decl ast.Decl) {
 
 	switch p.tok {
 	case token.OPTION, token.NOT:
+		if p.tok == token.NOT {
+			p.requireFeature(p.pos, langversion.RequiredFields)
+		}
 		m.Optional = p.pos
 		m.Constraint = p.tok
 		p.next()
@@ -931,6 +1002,9 @@ func (p *parser) parseField() (decl ast.Decl) {
 
 		switch p.tok {
 		case token.OPTION, token.NOT:
+			if p.tok == token.NOT {
+				p.requireFeature(p.pos, langversion.RequiredFields)
+			}
 			m.Optional = p.pos
 			m.Constraint = p.tok
 			p.next()
@@ -958,6 +1032,39 @@ func (p *parser) parseField() (decl ast.Decl) {
 	return this
 }
 
+// unquotedLabel reports the text of x if it consists of identifiers and
+// integers joined by '-' or '.' without intervening spaces, as in foo-bar,
+// which are likely meant to be a quoted label.
+func unquotedLabel(x ast.Expr) (string, bool) {
+	adjacent := func(a, b token.Pos) bool {
+		return a.IsValid() && a.Offset() == b.Offset()
+	}
+	switch x := x.(type) {
+	case *ast.Ident:
+		return x.Name, true
+	case *ast.BasicLit:
+		return x.Value, x.Kind == token.INT
+	case *ast.BinaryExpr:
+		if x.Op != token.SUB || !adjacent(x.X.End(), x.OpPos) || !adjacent(x.OpPos.Add(1), x.Y.Pos()) {
+			return "", false
+		}
+		a, ok1 := unquotedLabel(x.X)
+		b, ok2 := unquotedLabel(x.Y)
+		return a + "-" + b, ok1 && ok2
+	case *ast.SelectorExpr:
+		if !adjacent(x.X.End().Add(1), x.Sel.Pos()) {
+			return "", false
+		}
+		sel, ok := x.Sel.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		a, ok := unquotedLabel(x.X)
+		return a + "." + sel.Name, ok
+	}
+	return "", false
+}
+
 func (p *parser) parseAttributes() (attrs []*ast.Attribute) {
 	p.openList()
 	for p.tok == token.ATTRIBUTE {
@@ -1144,6 +1251,7 @@ func (p *parser) parseComprehensionClauses(first bool) (clauses []ast.Clause, c
 		case token.LET:
 			c := p.openComments()
 			letPos := p.expect(token.LET)
+			p.requireFeature(letPos, langversion.LetDeclarations)
 
 			ident := p.parseIdent()
 			assign := p.expect(token.BIND)
@@ -1322,7 +1430,7 @@ func (p *parser) parseListElement() (expr ast.Expr, ok bool) {
 		if p.tok == token.RBRACK || p.tok == token.FOR || p.tok == token.IF {
 			return expr, false
 		}
-		p.errf(p.pos, "missing ',' before newline in list literal")
+		p.errMissingComma("missing ',' before newline in list literal")
 	} else if !p.atComma("list literal", token.RBRACK, token.FOR, token.IF) {
 		return expr, false
 	}
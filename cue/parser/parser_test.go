@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
 )
 
 func TestParse(t *testing.T) {
@@ -724,6 +725,71 @@ func TestStrict(t *testing.T) {
 	}
 }
 
+func TestLanguageVersion(t *testing.T) {
+	testCases := []struct {
+		desc, version, in, err string
+	}{{
+		desc:    "required field",
+		version: "v0.6.0",
+		in:      `a!: int`,
+	}, {
+		desc:    "required field before v0.6.0",
+		version: "v0.5.0",
+		in:      `a!: int`,
+		err:     `required fields are only available as of language version v0.6.0 (language version is v0.5.0)`,
+	}, {
+		desc:    "nested required field before v0.6.0",
+		version: "v0.5.0",
+		in:      `a: b!: int`,
+		err:     `required fields are only available as of language version v0.6.0 (language version is v0.5.0)`,
+	}, {
+		desc:    "optional field before v0.6.0",
+		version: "v0.5.0",
+		in:      `a?: int`,
+	}, {
+		desc:    "definition before v0.3.0",
+		version: "v0.2.2",
+		in:      `#A: int`,
+		err:     `definitions using # are only available as of language version v0.3.0 (language version is v0.2.2)`,
+	}, {
+		desc:    "let before v0.3.0",
+		version: "v0.2.2",
+		in:      "let x = 1\na: x",
+		err:     `let declarations are only available as of language version v0.3.0 (language version is v0.2.2)`,
+	}, {
+		desc:    "old-style alias retained",
+		version: "v0.1.2",
+		in:      `X=3`,
+	}, {
+		desc:    "old-style alias",
+		version: "v0.2.2",
+		in:      `X=3`,
+		err:     `use of deprecated old-style alias; use "let X = expr" instead (deprecated as of v0.1.4)`,
+	}, {
+		desc:    "newer than supported",
+		version: "v99.0.0",
+		in:      `a: 1`,
+		err:     `language version v99.0.0 is newer than the latest supported version`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := ParseFile("input", tc.in, LanguageVersion(tc.version))
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("unexpected success; want error %q", tc.err)
+			}
+			if !strings.HasPrefix(err.Error(), tc.err) {
+				t.Errorf("unexpected error:\ngot  %v\nwant %v", err, tc.err)
+			}
+		})
+	}
+}
+
 func TestParseExpr(t *testing.T) {
 	// just kicking the tires:
 	// a valid arithmetic expression
@@ -868,6 +934,48 @@ func TestIncompleteSelection(t *testing.T) {
 	}
 }
 
+// TestSuggestions checks the fixes suggested for syntax errors by applying
+// them to the source.
+func TestSuggestions(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{{
+		in:   "a: {b: 1 c: 2}",
+		want: "a: {b: 1, c: 2}",
+	}, {
+		in:   "a: [1 2]",
+		want: "a: [1, 2]",
+	}, {
+		in:   "a: 1\nfoo-bar: 2",
+		want: "a: 1\n\"foo-bar\": 2",
+	}, {
+		in:   "a: 1\n1: 2",
+		want: "a: 1\n\"1\": 2",
+	}, {
+		in:   "a: 1\nfoo.bar: 2",
+		want: "a: 1\n\"foo.bar\": 2",
+	}}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			_, err := ParseFile("input", tc.in)
+			s := errors.Suggestions(err)
+			if len(s) == 0 {
+				t.Fatalf("no suggestions for %q: %v", tc.in, err)
+			}
+			got := tc.in
+			edits := s[0].Edits
+			for i := len(edits) - 1; i >= 0; i-- {
+				e := edits[i]
+				got = got[:e.Pos.Offset()] + e.NewText + got[e.End.Offset():]
+			}
+			if got != tc.want {
+				t.Errorf("%s: got %q; want %q", s[0].Message, got, tc.want)
+			}
+		})
+	}
+}
+
 // For debugging, do not delete.
 func TestX(t *testing.T) {
 	t.Skip()
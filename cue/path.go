@@ -24,6 +24,7 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/scanner"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal/astinternal"
 	"cuelang.org/go/internal/core/adt"
@@ -142,7 +143,7 @@ func (sel Selector) String() string {
 // It panics unless sel.LabelType is StringLabel and has a concrete name.
 func (sel Selector) Unquoted() string {
 	if sel.LabelType() != StringLabel ||
-		sel.ConstraintType() >= PatternConstraint {
+		sel.ConstraintType() >= PatternConstraint || sel.IsWildcard() {
 		panic("Selector.Unquoted invoked on non-string label")
 	}
 	switch s := sel.sel.(type) {
@@ -167,7 +168,7 @@ func (sel Selector) IsString() bool {
 	// TODO: consider deprecating this method. It is a bit wonkey now.
 	t := sel.Type()
 	t &^= OptionalConstraint | RequiredConstraint
-	return t == StringLabel
+	return t == StringLabel && !sel.IsWildcard()
 }
 
 // IsDefinition reports whether sel is a non-hidden definition and non-constraint label type.
@@ -230,6 +231,23 @@ var (
 	anyString = Selector{sel: anySelector(adt.AnyString)}
 )
 
+var (
+	// AnyField is a wildcard that matches any regular field of a struct.
+	// It can only be used in patterns passed to [Value.MatchPaths].
+	AnyField = Selector{sel: wildcardSelector(StringLabel)}
+
+	// AnyElement is a wildcard that matches any element of a list.
+	// It can only be used in patterns passed to [Value.MatchPaths].
+	AnyElement = Selector{sel: wildcardSelector(IndexLabel)}
+)
+
+// IsWildcard reports whether sel is a wildcard selector, such as
+// [AnyField] or [AnyElement].
+func (sel Selector) IsWildcard() bool {
+	_, ok := sel.sel.(wildcardSelector)
+	return ok
+}
+
 // Optional converts sel into an optional constraint equivalent.
 // It's a no-op if the selector is already optional.
 //
@@ -283,10 +301,25 @@ func pathToStrings(p Path) (a []string) {
 //
 // A path may not contain hidden fields. To create a path with hidden fields,
 // use MakePath and Ident.
+//
+// In addition to regular CUE selectors, a path may contain the following:
+//
+//	foo?      the optional field foo (see [Selector.Optional])
+//	foo!      the required field foo (see [Selector.Required])
+//	[string]  the pattern constraint applying to all regular fields ([AnyString])
+//	[int]     the pattern constraint applying to all list elements ([AnyIndex])
+//	*         any regular field ([AnyField])
+//	[*]       any list element ([AnyElement])
+//
+// Paths containing wildcards can only be used as patterns with
+// [Value.MatchPaths].
 func ParsePath(s string) Path {
 	if s == "" {
 		return Path{}
 	}
+	if p, ok := parseExtendedPath(s); ok {
+		return p
+	}
 	expr, err := parser.ParseExpr("", s)
 	if err != nil {
 		return MakePath(Selector{pathError{errors.Promote(err, "invalid path")}})
@@ -302,6 +335,143 @@ func ParsePath(s string) Path {
 	return p
 }
 
+// parseExtendedPath parses paths consisting of a sequence of selectors
+// written using the syntax documented at ParsePath. It reports false if s
+// is not such a path, in which case ParsePath falls back to parsing it as
+// a CUE expression, which also produces the relevant error messages.
+func parseExtendedPath(s string) (p Path, ok bool) {
+	type item struct {
+		tok token.Token
+		lit string
+	}
+	var items []item
+	var sc scanner.Scanner
+	failed := false
+	src := []byte(s)
+	sc.Init(token.NewFile("", -1, len(src)), src, func(token.Pos, string, []interface{}) {
+		failed = true
+	}, scanner.DontInsertCommas)
+	for {
+		_, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			break
+		}
+		items = append(items, item{tok, lit})
+	}
+	if failed {
+		return Path{}, false
+	}
+
+	var a []Selector
+	i := 0
+	next := func() item {
+		if i < len(items) {
+			i++
+			return items[i-1]
+		}
+		return item{tok: token.EOF}
+	}
+	peek := func() token.Token {
+		if i < len(items) {
+			return items[i].tok
+		}
+		return token.EOF
+	}
+	// constraint applies a trailing ? or ! to the last selector.
+	constraint := func() bool {
+		switch peek() {
+		case token.OPTION:
+			next()
+			a[len(a)-1] = a[len(a)-1].Optional()
+		case token.NOT:
+			next()
+			a[len(a)-1] = a[len(a)-1].Required()
+		default:
+			return true
+		}
+		return !a[len(a)-1].Type().IsHidden()
+	}
+	label := func(x item) bool {
+		switch x.tok {
+		case token.MUL:
+			a = append(a, AnyField)
+			return true
+		case token.IDENT:
+			if strings.HasPrefix(x.lit, "_") {
+				return false
+			}
+			a = append(a, Label(ast.NewIdent(x.lit)))
+		case token.STRING:
+			sel := basicLitSelector(&ast.BasicLit{Kind: token.STRING, Value: x.lit})
+			if _, ok := sel.sel.(pathError); ok {
+				return false
+			}
+			a = append(a, sel)
+		default:
+			return false
+		}
+		return constraint()
+	}
+	index := func() bool {
+		x := next()
+		switch x.tok {
+		case token.MUL:
+			a = append(a, AnyElement)
+		case token.IDENT:
+			switch x.lit {
+			case "string":
+				a = append(a, AnyString)
+			case "int":
+				a = append(a, AnyIndex)
+			default:
+				return false
+			}
+		case token.INT, token.STRING:
+			sel := basicLitSelector(&ast.BasicLit{Kind: x.tok, Value: x.lit})
+			if _, ok := sel.sel.(pathError); ok {
+				return false
+			}
+			a = append(a, sel)
+		default:
+			return false
+		}
+		return next().tok == token.RBRACK
+	}
+
+	extended := false
+	for _, x := range items {
+		switch x.tok {
+		case token.OPTION, token.NOT, token.MUL:
+			extended = true
+		case token.IDENT:
+			extended = extended || x.lit == "string" || x.lit == "int"
+		}
+	}
+	if !extended {
+		return Path{}, false
+	}
+
+	for first := true; peek() != token.EOF; first = false {
+		switch x := next(); {
+		case x.tok == token.LBRACK && !first:
+			if !index() {
+				return Path{}, false
+			}
+		case x.tok == token.PERIOD && !first:
+			if !label(next()) {
+				return Path{}, false
+			}
+		case first:
+			if !label(x) {
+				return Path{}, false
+			}
+		default:
+			return Path{}, false
+		}
+	}
+	return Path{path: a}, true
+}
+
 // Selectors reports the individual selectors of a path.
 func (p Path) Selectors() []Selector {
 	return p.path
@@ -316,6 +486,14 @@ func (p Path) String() string {
 	b := &strings.Builder{}
 	for i, sel := range p.path {
 		switch {
+		case sel.ConstraintType() == PatternConstraint:
+			// Use the syntax accepted by ParsePath.
+			if sel.LabelType() == IndexLabel {
+				b.WriteString("[int]")
+			} else {
+				b.WriteString("[string]")
+			}
+			continue
 		case sel.Type() == IndexLabel:
 			// TODO: use '.' in all cases, once supported.
 			b.WriteByte('[')
@@ -602,6 +780,19 @@ func (s anySelector) feature(r adt.Runtime) adt.Feature {
 	return adt.Feature(s)
 }
 
+// A wildcardSelector matches any regular field or list element, depending
+// on its label type.
+type wildcardSelector SelectorType
+
+func (s wildcardSelector) String() string               { return "*" }
+func (s wildcardSelector) isConstraint() bool           { return false }
+func (s wildcardSelector) labelType() SelectorType      { return SelectorType(s) }
+func (s wildcardSelector) constraintType() SelectorType { return 0 }
+
+func (s wildcardSelector) feature(r adt.Runtime) adt.Feature {
+	return adt.InvalidLabel
+}
+
 // TODO: allow import paths to be represented?
 //
 // // ImportPath defines a lookup at the root of an instance. It must be the first
@@ -16,6 +16,7 @@ package cue
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -43,7 +44,7 @@ func TestPaths(t *testing.T) {
 	}{{
 		path: MakePath(Str("list"), AnyIndex),
 		out:  "int",
-		str:  "list.[_]",
+		str:  "list[int]",
 	}, {
 
 		path: MakePath(Def("#Foo"), Str("a"), Str("b")),
@@ -123,11 +124,23 @@ func TestPaths(t *testing.T) {
 	}, {
 		path: MakePath(Str("map"), AnyString),
 		out:  "int",
-		str:  "map.[_]",
+		str:  "map[string]",
 	}, {
 		path: MakePath(Str("list"), AnyIndex),
 		out:  "int",
-		str:  "list.[_]",
+		str:  "list[int]",
+	}, {
+		path: ParsePath("map[string]"),
+		out:  "int",
+		str:  "map[string]",
+	}, {
+		path: ParsePath("list[int]"),
+		out:  "int",
+		str:  "list[int]",
+	}, {
+		path: ParsePath("b[*]"),
+		out:  "_|_ // wildcard selector * not allowed in LookupPath; use MatchPaths",
+		str:  "b[*]",
 	}, {
 		path: ParsePath("x.y"),
 		out:  "{\n\tb: 0\n}",
@@ -241,6 +254,10 @@ var selectorTests = []struct {
 	stype:        IndexLabel | PatternConstraint,
 	string:       "[_]",
 	isConstraint: true,
+}, {
+	sel:    AnyField,
+	stype:  StringLabel,
+	string: "*",
 }, {
 	sel:      Hid("_foo", "example.com"),
 	stype:    HiddenLabel,
@@ -318,6 +335,134 @@ func TestSelectorTypeString(t *testing.T) {
 	}
 }
 
+func TestParsePathPatterns(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want []Selector
+	}{{
+		in:   "a?.b!",
+		want: []Selector{Str("a").Optional(), Str("b").Required()},
+	}, {
+		in:   `#a."b c"?`,
+		want: []Selector{Def("a"), Str("b c").Optional()},
+	}, {
+		in:   "a[string]",
+		want: []Selector{Str("a"), AnyString},
+	}, {
+		in:   "a[int]",
+		want: []Selector{Str("a"), AnyIndex},
+	}, {
+		in:   "a.*.b",
+		want: []Selector{Str("a"), AnyField, Str("b")},
+	}, {
+		in:   "*",
+		want: []Selector{AnyField},
+	}, {
+		in:   `c[*].image[0]["x"]`,
+		want: []Selector{Str("c"), AnyElement, Str("image"), Index(0), Str("x")},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			p := ParsePath(tc.in)
+			if err := p.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := p, MakePath(tc.want...); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+	for _, in := range []string{"a.*?", "a[foo]", "a.[*]", "_a?", "a**"} {
+		if ParsePath(in).Err() == nil {
+			t.Errorf("ParsePath(%q): expected error", in)
+		}
+	}
+}
+
+func TestPathStringRoundTrip(t *testing.T) {
+	for _, p := range []Path{
+		MakePath(Str("a"), AnyString),
+		MakePath(Str("a"), AnyIndex, Str("b")),
+		MakePath(Str("a"), AnyField, Str("b")),
+		MakePath(Str("a"), AnyElement),
+		MakePath(Str("a").Optional(), Str("b c").Required()),
+		MakePath(Def("a"), Index(2), Str("#b")),
+		ParsePath(`c[*].image[0]["x"]`),
+	} {
+		s := p.String()
+		if got := ParsePath(s); !reflect.DeepEqual(got, p) {
+			t.Errorf("ParsePath(%q) = %v; want %v", s, got.Selectors(), p.Selectors())
+		}
+	}
+}
+
+func TestMatchPaths(t *testing.T) {
+	var r Runtime
+	inst, _ := r.Compile("", `
+		spec: containers: [{
+			name:  "a"
+			image: "img-a"
+		}, {
+			name: "b"
+		}, {
+			name:  "c"
+			image: "img-c"
+		}]
+		env: {
+			x: value: 1
+			y: value: 2
+			z: other: 3
+			#d: value: 4
+		}
+	`)
+	v := inst.Value()
+	testCases := []struct {
+		pattern string
+		want    []string
+		err     bool
+	}{{
+		pattern: "spec.containers[*].image",
+		want:    []string{"spec.containers[0].image", "spec.containers[2].image"},
+	}, {
+		pattern: "env.*.value",
+		want:    []string{"env.x.value", "env.y.value"},
+	}, {
+		pattern: "*",
+		want:    []string{"spec", "env"},
+	}, {
+		pattern: "spec.containers[1]",
+		want:    []string{"spec.containers[1]"},
+	}, {
+		pattern: "spec.containers[*].name",
+		want:    []string{"spec.containers[0].name", "spec.containers[1].name", "spec.containers[2].name"},
+	}, {
+		pattern: "env[*]",
+	}, {
+		pattern: "missing.*",
+	}, {
+		pattern: "spec[*",
+		err:     true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			paths, err := v.MatchPaths(ParsePath(tc.pattern))
+			if gotErr := err != nil; gotErr != tc.err {
+				t.Fatalf("error: got %v; want %v", err, tc.err)
+			}
+			var got []string
+			for _, p := range paths {
+				got = append(got, p.String())
+				if !v.LookupPath(p).Exists() {
+					t.Errorf("path %v does not exist", p)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func checkPanic(t *testing.T, wantPanicStr string, f func()) {
 	gotPanicStr := ""
 	func() {
@@ -0,0 +1,128 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue
+
+import (
+	"cuelang.org/go/internal/core/adt"
+)
+
+// A Pattern is a constraint that applies to the fields of a struct whose
+// labels match a pattern, as in
+//
+//	[=~"^x-"]: string
+//
+// or to the elements of a list beyond its fixed elements, as in
+//
+//	[...int]
+type Pattern struct {
+	label Value
+	value Value
+}
+
+// Label returns the pattern against which the labels of fields are
+// matched, such as string or =~"^x-". For the element constraint of a
+// list, it is int.
+func (p Pattern) Label() Value {
+	return p.label
+}
+
+// Value returns the constraint unified with the values of the matching
+// fields or elements. As with [AnyString] in [Value.LookupPath],
+// references to the label of a field, as in [Name=string]: {name: Name},
+// resolve to the pattern.
+func (p Pattern) Value() Value {
+	return p.value
+}
+
+// Patterns returns the pattern constraints of struct v or the element
+// constraint of list v, in the order in which they are declared. This
+// allows code generators to represent such values as maps or typed lists.
+// It returns nil if v has no such constraints.
+//
+// To look up the constraint that applies to a given label, use
+// [Value.LookupPath] with an optional or [AnyString] selector instead.
+func (v Value) Patterns() []Pattern {
+	if v.v == nil {
+		return nil
+	}
+	v, _ = v.Default()
+	n := v.v
+	ctx := v.ctx()
+	isList := n.IsList()
+
+	var patterns []Pattern
+	if pcs := n.PatternConstraints; pcs != nil {
+		for _, pc := range pcs.Pairs {
+			p := Pattern{label: remakeValue(v, nil, pc.Pattern)}
+			if isList {
+				p.label = remakeValue(v, nil, &adt.BasicType{K: adt.IntKind})
+			}
+			p.value = v.patternValue(ctx, n, isList, pc.Constraint.Conjuncts...)
+			patterns = append(patterns, p)
+		}
+		return patterns
+	}
+
+	seen := map[adt.Decl]bool{}
+	for _, s := range n.Structs {
+		if s.Disable {
+			continue
+		}
+		for _, b := range s.Bulk {
+			if seen[b] {
+				continue
+			}
+			seen[b] = true
+			env := *s.Env
+			env.DynamicLabel = 0 // as for AnyString in LookupPath
+			patterns = append(patterns, Pattern{
+				label: remakeValue(v, s.Env, b.Filter),
+				value: v.patternValue(ctx, n, false, adt.MakeConjunct(&env, b, s.CloseInfo)),
+			})
+		}
+		if !isList {
+			continue
+		}
+		for _, x := range s.Additional {
+			if seen[x] {
+				continue
+			}
+			seen[x] = true
+			patterns = append(patterns, Pattern{
+				label: remakeValue(v, nil, &adt.BasicType{K: adt.IntKind}),
+				value: v.patternValue(ctx, n, true, adt.MakeConjunct(s.Env, x, s.CloseInfo)),
+			})
+		}
+	}
+	return patterns
+}
+
+// patternValue returns the value of a pattern constraint of n made up of
+// the given conjuncts.
+func (v Value) patternValue(ctx *adt.OpContext, n *adt.Vertex, isList bool, conjuncts ...adt.Conjunct) Value {
+	label := adt.AnyString
+	if isList {
+		label = adt.AnyIndex
+	}
+	x := &adt.Vertex{
+		Parent: n,
+		Label:  label,
+	}
+	for _, c := range conjuncts {
+		x.AddConjunct(c)
+	}
+	x.Finalize(ctx)
+	return makeValue(v.idx, x, linkParent(v.parent_, n, x))
+}
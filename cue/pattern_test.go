@@ -0,0 +1,73 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cue_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestPatterns(t *testing.T) {
+	const in = `
+labels: [string]: string
+headers: {
+	[=~"^x-"]: int
+	[=~"^y-"]: bool
+	"content-type": string
+}
+ports: [...int & >0]
+hosts: [string, ...string]
+tuple: [int, string]
+closed: close({a: int})
+named: [Name=string]: {name: Name}
+schema: #S & {a: 1}
+#S: {
+	a: int
+	[string]: int
+}
+`
+	want := map[string]string{
+		"labels":  "string: string",
+		"headers": `=~"^x-": int; =~"^y-": bool`,
+		"ports":   "int: >0 & int",
+		"hosts":   "int: string",
+		"tuple":   "",
+		"closed":  "",
+		"named":   "string: {\n\tname: string\n}",
+		"schema":  "string: int",
+	}
+	for _, experiments := range [][]string{nil, {"evalv3"}} {
+		ctx := cuecontext.New(cuecontext.Experiments(experiments...))
+		v := ctx.CompileString(in)
+		if err := v.Err(); err != nil {
+			t.Fatal(err)
+		}
+		for path, want := range want {
+			t.Run(fmt.Sprint(path, experiments), func(t *testing.T) {
+				var got []string
+				for _, p := range v.LookupPath(cue.ParsePath(path)).Patterns() {
+					got = append(got, fmt.Sprintf("%v: %v", p.Label(), p.Value()))
+				}
+				if got := strings.Join(got, "; "); got != want {
+					t.Errorf("got %q; want %q", got, want)
+				}
+			})
+		}
+	}
+}
@@ -15,6 +15,8 @@
 package cue
 
 import (
+	"slices"
+
 	"cuelang.org/go/internal/core/adt"
 )
 
@@ -73,6 +75,9 @@ outer:
 		var x *adt.Bottom
 		if err, ok := sel.sel.(pathError); ok {
 			x = &adt.Bottom{Err: err.Error}
+		} else if sel.IsWildcard() {
+			x = mkErr(v.idx, n, adt.EvalError,
+				"wildcard selector %v not allowed in LookupPath; use MatchPaths", sel.sel)
 		} else {
 			x = mkErr(v.idx, n, adt.EvalError, "field not found: %v", sel.sel)
 			if n.Accept(ctx, f) {
@@ -85,3 +90,53 @@ outer:
 	}
 	return makeValue(v.idx, n, parent)
 }
+
+// MatchPaths reports the paths, relative to v, of all values matching
+// pattern. In addition to regular selectors, the pattern may contain the
+// wildcards AnyField and AnyElement, which match any regular field or any
+// list element, respectively. For instance, the pattern
+//
+//	ParsePath("spec.containers[*].image")
+//
+// matches the image field of every container.
+//
+// Paths are reported in the order in which the values appear in v.
+// Paths of values that do not exist are not reported.
+func (v Value) MatchPaths(pattern Path) ([]Path, error) {
+	if err := pattern.Err(); err != nil {
+		return nil, err
+	}
+	var paths []Path
+	var match func(v Value, prefix, sels []Selector)
+	match = func(v Value, prefix, sels []Selector) {
+		if len(sels) == 0 {
+			paths = append(paths, Path{path: slices.Clone(prefix)})
+			return
+		}
+		sel, rest := sels[0], sels[1:]
+		switch sel.sel {
+		case AnyField.sel:
+			iter, err := v.Fields()
+			if err != nil {
+				return
+			}
+			for iter.Next() {
+				match(iter.Value(), append(prefix, iter.Selector()), rest)
+			}
+		case AnyElement.sel:
+			iter, err := v.List()
+			if err != nil {
+				return
+			}
+			for i := 0; iter.Next(); i++ {
+				match(iter.Value(), append(prefix, Index(i)), rest)
+			}
+		default:
+			if w := v.LookupPath(MakePath(sel)); w.Exists() {
+				match(w, append(prefix, sel), rest)
+			}
+		}
+	}
+	match(v, nil, pattern.path)
+	return paths, nil
+}
@@ -19,6 +19,7 @@ package stats
 import (
 	"strings"
 	"text/template"
+	"time"
 )
 
 // Counts holds counters for key events during a CUE evaluation.
@@ -100,6 +101,30 @@ func (s Counts) Leaks() int64 {
 	return s.Allocs + s.Reused - s.Freed
 }
 
+// Usage holds the counts of the key operations and the evaluation time that
+// are attributed to a part of a configuration, such as a file or a package.
+// An operation is attributed to the file that defines the value it operates
+// on.
+//
+// This is an experimental type and the contents may change without notice.
+type Usage struct {
+	Unifications int64
+	Disjuncts    int64
+	Conjuncts    int64
+
+	// Duration is the wall time spent unifying values attributed to this
+	// part, in nanoseconds. Time spent unifying values that are attributed
+	// to other parts, such as referenced values in other files, is excluded.
+	Duration time.Duration
+}
+
+func (u *Usage) Add(other Usage) {
+	u.Unifications += other.Unifications
+	u.Disjuncts += other.Disjuncts
+	u.Conjuncts += other.Conjuncts
+	u.Duration += other.Duration
+}
+
 var stats = template.Must(template.New("stats").Parse(`{{"" -}}
 
 Leaks:  {{.Leaks}}
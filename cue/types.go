@@ -33,6 +33,7 @@ import (
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/compile"
 	"cuelang.org/go/internal/core/convert"
+	"cuelang.org/go/internal/core/dep"
 	"cuelang.org/go/internal/core/eval"
 	"cuelang.org/go/internal/core/export"
 	"cuelang.org/go/internal/core/runtime"
@@ -2236,6 +2237,151 @@ func (v Value) Validate(opts ...Option) error {
 	return nil
 }
 
+// IncompletePaths returns all values within v that are not concrete, such
+// as fields that are constrained but not set, and required fields that are
+// not specified. Each value holds the constraint that remains for the path
+// reported by its Path method. Values with a default are considered
+// concrete. Definitions, hidden fields, optional fields, and values with
+// errors other than incompleteness, which are reported by Validate, are
+// not included.
+func (v Value) IncompletePaths() []Value {
+	var a []Value
+	v.incompletePaths(v.ctx(), &a)
+	return a
+}
+
+func (v Value) incompletePaths(ctx *adt.OpContext, a *[]Value) {
+	v, _ = v.Default()
+	kind := v.IncompleteKind()
+	opts := options{omitHidden: true, omitDefinitions: true}
+	if b, ok := v.v.BaseValue.(*adt.Bottom); ok && !b.IsIncomplete() {
+		// Errors are propagated to the enclosing values, so continue
+		// with the fields of a value that has any. Requesting hidden
+		// fields, which are skipped below, avoids failing on the error.
+		if len(v.v.Arcs) == 0 {
+			return
+		}
+		kind = StructKind
+		opts.omitHidden = false
+	}
+	switch kind {
+	case StructKind:
+		obj, err := v.structValOpts(ctx, opts)
+		if err != nil {
+			*a = append(*a, v)
+			return
+		}
+		for i := 0; i < obj.Len(); i++ {
+			_, w := obj.At(i)
+			if w.v.Label.IsHidden() {
+				continue
+			}
+			switch w.v.ArcType {
+			case adt.ArcMember:
+				w.incompletePaths(ctx, a)
+			case adt.ArcRequired:
+				*a = append(*a, w)
+			}
+		}
+
+	case ListKind:
+		iter, err := v.List()
+		if err != nil {
+			*a = append(*a, v)
+			return
+		}
+		for iter.Next() {
+			iter.Value().incompletePaths(ctx, a)
+		}
+
+	default:
+		if !v.IsConcrete() {
+			*a = append(*a, v)
+		}
+	}
+}
+
+// A Dependency describes a reference on which a value depends.
+type Dependency struct {
+	// Value is the referenced value. Its Path method reports the path of
+	// the referenced field.
+	Value Value
+
+	// Pos is the position of the reference.
+	Pos token.Pos
+
+	// Concrete reports whether the referenced value, or its default, is
+	// concrete.
+	Concrete bool
+
+	// Indirect reports whether the value passed to Dependencies depends on
+	// the referenced value only through another dependency.
+	Indirect bool
+}
+
+// Dependencies returns the values referenced by v, including those
+// referenced from the fields of v, and, transitively, the values they
+// depend on in turn. Each value is reported once, in the order in which
+// it is first found, with direct dependencies first. References to values
+// within v itself and to builtin packages are not reported.
+func (v Value) Dependencies() []Dependency {
+	if v.v == nil {
+		return nil
+	}
+	ctx := v.ctx()
+	cfg := &dep.Config{Descend: true}
+	seen := map[*adt.Vertex]bool{v.v: true}
+	var deps []Dependency
+	queue := []*adt.Vertex{v.v}
+	for indirect := false; len(queue) > 0; indirect = true {
+		var next []*adt.Vertex
+		for _, n := range queue {
+			dep.Visit(cfg, ctx, n, func(d dep.Dependency) error {
+				if seen[d.Node] || isDescendant(d.Node, v.v) || isBuiltinPackage(v.idx, d) {
+					return nil
+				}
+				seen[d.Node] = true
+				w := makeValue(v.idx, d.Node, nil)
+				def, _ := w.Default()
+				var pos token.Pos
+				if src := d.Reference.Source(); src != nil {
+					pos = src.Pos()
+				}
+				deps = append(deps, Dependency{
+					Value:    w,
+					Pos:      pos,
+					Concrete: def.IsConcrete(),
+					Indirect: indirect,
+				})
+				next = append(next, d.Node)
+				return nil
+			})
+		}
+		queue = next
+	}
+	return deps
+}
+
+// isDescendant reports whether n is, or is nested within, the vertex v.
+func isDescendant(n, v *adt.Vertex) bool {
+	for ; n != nil; n = n.Parent {
+		if n == v {
+			return true
+		}
+	}
+	return false
+}
+
+// isBuiltinPackage reports whether d refers to a value of a builtin package.
+func isBuiltinPackage(idx *runtime.Runtime, d dep.Dependency) bool {
+	imp := d.Import()
+	if imp == nil {
+		return false
+	}
+	first, _, _ := strings.Cut(imp.ImportPath.StringValue(idx), "/")
+	return !strings.Contains(first, ".")
+}
+
 // Walk descends into all values of v, calling f. If f returns false, Walk
 // will not descent further. It only visits values that are part of the data
 // model, so this excludes definitions and optional, required, and hidden
@@ -3045,6 +3045,105 @@ func TestWalk(t *testing.T) {
 	}
 }
 
+func TestIncompletePaths(t *testing.T) {
+	testCases := []struct {
+		value string
+		out   string
+	}{{
+		value: `{a: 1, b: [1, "x"]}`,
+		out:   ``,
+	}, {
+		value: `int`,
+		out:   `: int`,
+	}, {
+		value: `{a: int, b: c: string, d: *1 | int}`,
+		out:   `a: int; b.c: string`,
+	}, {
+		value: `{a: [1, int], b: a[1] + 1}`,
+		out:   `a[1]: int; b: a[1] + 1`,
+	}, {
+		value: `{#D: {x!: int, y?: int, z: int}, d: #D, _h: int}`,
+		out:   `d.x: int; d.z: int`,
+	}, {
+		// Errors are reported by Validate, but do not hide other values.
+		value: `{a: 1 & 2, b: {c: 1 & 2, d: int}}`,
+		out:   `b.d: int`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			v := getInstance(t, tc.value).Value()
+			var a []string
+			for _, w := range v.IncompletePaths() {
+				a = append(a, fmt.Sprintf("%v: %v", w.Path(), w))
+			}
+			if got := strings.Join(a, "; "); got != tc.out {
+				t.Errorf("\n got %v;\nwant %v", got, tc.out)
+			}
+		})
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	const src = `
+import "strings"
+
+#Base: {
+	replicas: int | *defaultReplicas
+	name:     string
+	port?:    int
+}
+defaultReplicas: 3
+
+app: #Base & {
+	name:     strings.ToUpper(prefix)
+	port:     other.port
+	replicas: 2
+}
+prefix: string
+other: {
+	port: 8080
+	host: prefix
+	self: port
+}
+`
+	testCases := []struct {
+		path string
+		out  string
+	}{{
+		path: "app",
+		out:  "#Base (11:6) concrete; prefix (12:28); other.port (13:12) concrete; defaultReplicas (5:19) concrete indirect",
+	}, {
+		path: "app.port",
+		out:  "other.port (13:12) concrete",
+	}, {
+		// References within a value are not reported.
+		path: "other",
+		out:  "prefix (19:8)",
+	}, {
+		path: "defaultReplicas",
+		out:  "",
+	}}
+	v := getInstance(t, src).Value()
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			var a []string
+			for _, d := range v.LookupPath(ParsePath(tc.path)).Dependencies() {
+				s := fmt.Sprintf("%v (%d:%d)", d.Value.Path(), d.Pos.Line(), d.Pos.Column())
+				if d.Concrete {
+					s += " concrete"
+				}
+				if d.Indirect {
+					s += " indirect"
+				}
+				a = append(a, s)
+			}
+			if got := strings.Join(a, "; "); got != tc.out {
+				t.Errorf("\n got %v;\nwant %v", got, tc.out)
+			}
+		})
+	}
+}
+
 func TestTrimZeros(t *testing.T) {
 	testCases := []struct {
 		in  string
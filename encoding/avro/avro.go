@@ -0,0 +1,393 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package avro generates Apache Avro schemas from CUE definitions.
+//
+// CUE types map to Avro types as follows:
+//
+//	CUE                          Avro
+//	null                         null
+//	bool                         boolean
+//	int                          long, or int if within the range of int32
+//	float, number                double, or float if within the range of float32
+//	string                       string
+//	bytes                        bytes
+//	[...T]                       array
+//	{[string]: T}                map
+//	struct                       record
+//	disjunction of strings       enum, if all strings are valid Avro names
+//	other disjunctions           union
+//
+// Optional fields are represented as a union with null that defaults to
+// null. Records and enums are named after the definition they refer to or,
+// for anonymous types, after the record that contains them and the name of
+// the field. Doc comments are included as documentation.
+package avro
+
+import (
+	"regexp"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+)
+
+// A Config configures the generation of Avro schemas.
+type Config struct {
+	// Name is the name of the generated record. It defaults to the name of
+	// the definition from which the schema is generated, or to which it
+	// refers, without the leading #.
+	Name string
+
+	// Namespace is the namespace of the generated record.
+	Namespace string
+
+	_ struct{} // prohibit casting from different type.
+}
+
+// Generate generates an Avro schema for the CUE definition v, which must be
+// a struct. The result is a record schema, which may be encoded as JSON.
+func Generate(v cue.Value, cfg *Config) (*ast.StructLit, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	name := cfg.Name
+	if name == "" {
+		name = definitionName(v.Path())
+	}
+	if name == "" {
+		_, ref := v.ReferencePath()
+		name = definitionName(ref)
+	}
+	if name == "" {
+		return nil, errors.Newf(v.Pos(), "avro: no name for schema")
+	}
+	if v.IncompleteKind() != cue.StructKind {
+		return nil, errors.Newf(v.Pos(), "avro: schema must be a struct, found %v", v.IncompleteKind())
+	}
+	g := &generator{
+		ctx:     v.Context(),
+		defined: map[string]bool{},
+	}
+	x, err := g.record(v, name)
+	if err != nil {
+		return nil, err
+	}
+	s := x.(*ast.StructLit)
+	if cfg.Namespace != "" {
+		s.Elts = append(s.Elts[:2:2], append([]ast.Decl{
+			&ast.Field{Label: ast.NewString("namespace"), Value: ast.NewString(cfg.Namespace)},
+		}, s.Elts[2:]...)...)
+	}
+	return s, nil
+}
+
+type generator struct {
+	ctx *cue.Context
+
+	// defined records the names of the named types that have been defined,
+	// so that later uses can refer to them by name.
+	defined map[string]bool
+
+	int32, float32 cue.Value
+}
+
+var nameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// definitionName returns the name of the definition at the end of p, without
+// the leading #, or "" if p does not end in a definition.
+func definitionName(p cue.Path) string {
+	sels := p.Selectors()
+	if len(sels) == 0 || !sels[len(sels)-1].IsDefinition() {
+		return ""
+	}
+	return strings.TrimPrefix(sels[len(sels)-1].String(), "#")
+}
+
+// typeName returns the name of a type nested within the type named parent
+// for the field with the given label.
+func typeName(parent, label string) string {
+	var b strings.Builder
+	b.WriteString(parent)
+	upper := true
+	for _, r := range label {
+		switch {
+		case r == '_' || r == '-' || r == '.' || r == ' ':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// typ returns the Avro type for v. Named types that are not defined by a
+// definition are given the name name.
+func (g *generator) typ(v cue.Value, name string) (ast.Expr, error) {
+	if _, ref := v.ReferencePath(); len(ref.Selectors()) > 0 {
+		if n := definitionName(ref); n != "" {
+			name = n
+		}
+	}
+	if op, args := v.Expr(); op == cue.OrOp {
+		return g.union(v, args, name)
+	}
+	switch k := v.IncompleteKind(); k {
+	case cue.NullKind:
+		return ast.NewString("null"), nil
+	case cue.BoolKind:
+		return ast.NewString("boolean"), nil
+	case cue.IntKind:
+		if g.within(v, &g.int32, "int32") {
+			return ast.NewString("int"), nil
+		}
+		return ast.NewString("long"), nil
+	case cue.FloatKind, cue.NumberKind:
+		if g.within(v, &g.float32, "float32") {
+			return ast.NewString("float"), nil
+		}
+		return ast.NewString("double"), nil
+	case cue.StringKind:
+		return ast.NewString("string"), nil
+	case cue.BytesKind:
+		return ast.NewString("bytes"), nil
+	case cue.ListKind:
+		elem := v.LookupPath(cue.MakePath(cue.AnyIndex))
+		if !elem.Exists() {
+			return nil, errors.Newf(v.Pos(), "avro: cannot represent list %v without element type", v)
+		}
+		items, err := g.typ(elem, name+"Item")
+		if err != nil {
+			return nil, err
+		}
+		return ast.NewStruct("type", ast.NewString("array"), "items", items), nil
+	case cue.StructKind:
+		if elem := v.LookupPath(cue.MakePath(cue.AnyString)); elem.Exists() && !hasFields(v) {
+			values, err := g.typ(elem, name+"Value")
+			if err != nil {
+				return nil, err
+			}
+			return ast.NewStruct("type", ast.NewString("map"), "values", values), nil
+		}
+		return g.record(v, name)
+	default:
+		return nil, errors.Newf(v.Pos(), "avro: cannot represent value of type %v", k)
+	}
+}
+
+// within reports whether all values of v are instances of the builtin type
+// with the given name, which is cached in *t.
+func (g *generator) within(v cue.Value, t *cue.Value, name string) bool {
+	if !t.Exists() {
+		*t = g.ctx.CompileString(name)
+	}
+	return t.Subsume(v, cue.Raw()) == nil
+}
+
+func hasFields(v cue.Value) bool {
+	iter, err := v.Fields(cue.Optional(true))
+	return err == nil && iter.Next()
+}
+
+// union returns the Avro type for the disjunction v with the given
+// disjuncts.
+func (g *generator) union(v cue.Value, disjuncts []cue.Value, name string) (ast.Expr, error) {
+	if symbols, ok := enumSymbols(disjuncts); ok {
+		if g.defined[name] {
+			return ast.NewString(name), nil
+		}
+		g.defined[name] = true
+		if !nameRE.MatchString(name) {
+			return nil, errors.Newf(v.Pos(), "avro: invalid enum name %q", name)
+		}
+		var list []ast.Expr
+		for _, s := range symbols {
+			list = append(list, ast.NewString(s))
+		}
+		return ast.NewStruct(
+			"type", ast.NewString("enum"),
+			"name", ast.NewString(name),
+			"symbols", ast.NewList(list...),
+		), nil
+	}
+
+	// Avro requires the default value of a field with a union type to match
+	// the first type of the union.
+	if d, ok := v.Default(); ok {
+		for i, x := range disjuncts {
+			if i > 0 && x.Unify(d).Err() == nil {
+				disjuncts = append(append([]cue.Value{x}, disjuncts[:i]...), disjuncts[i+1:]...)
+				break
+			}
+		}
+	}
+
+	var types []ast.Expr
+	seen := map[string]bool{}
+	for _, x := range disjuncts {
+		t, err := g.typ(x, name)
+		if err != nil {
+			return nil, err
+		}
+		// Literals of the same type, such as in 1 | 2, map to the same
+		// type. Nested unions are not allowed.
+		var elems []ast.Expr
+		if list, ok := t.(*ast.ListLit); ok {
+			elems = list.Elts
+		} else {
+			elems = []ast.Expr{t}
+		}
+		for _, t := range elems {
+			if lit, ok := t.(*ast.BasicLit); ok {
+				if seen[lit.Value] {
+					continue
+				}
+				seen[lit.Value] = true
+			}
+			types = append(types, t)
+		}
+	}
+	if len(types) == 1 {
+		return types[0], nil
+	}
+	return ast.NewList(types...), nil
+}
+
+// enumSymbols returns the strings of disjuncts if all of them are concrete
+// strings that are valid Avro enum symbols.
+func enumSymbols(disjuncts []cue.Value) ([]string, bool) {
+	var symbols []string
+	for _, x := range disjuncts {
+		s, err := x.String()
+		if err != nil || !x.IsConcrete() || !nameRE.MatchString(s) {
+			return nil, false
+		}
+		symbols = append(symbols, s)
+	}
+	return symbols, true
+}
+
+// record returns an Avro record type with the given name for the struct v,
+// or a reference to it if it was already defined.
+func (g *generator) record(v cue.Value, name string) (ast.Expr, error) {
+	if g.defined[name] {
+		return ast.NewString(name), nil
+	}
+	g.defined[name] = true
+
+	if !nameRE.MatchString(name) {
+		return nil, errors.Newf(v.Pos(), "avro: invalid record name %q", name)
+	}
+	var fields []ast.Expr
+	iter, err := v.Fields(cue.Optional(true))
+	if err != nil {
+		return nil, err
+	}
+	for iter.Next() {
+		label := iter.Selector().Unquoted()
+		f := iter.Value()
+		if !nameRE.MatchString(label) {
+			return nil, errors.Newf(f.Pos(), "avro: invalid field name %q", label)
+		}
+		t, err := g.typ(f, typeName(name, label))
+		if err != nil {
+			return nil, err
+		}
+		field := ast.NewStruct("name", ast.NewString(label))
+		if doc := docText(f); doc != "" {
+			field.Elts = append(field.Elts, &ast.Field{
+				Label: ast.NewString("doc"),
+				Value: ast.NewString(doc),
+			})
+		}
+		var def ast.Expr
+		switch {
+		case iter.IsOptional():
+			t = withNull(t)
+			def = ast.NewNull()
+		default:
+			def = defaultValue(f)
+		}
+		field.Elts = append(field.Elts, &ast.Field{Label: ast.NewString("type"), Value: t})
+		if def != nil {
+			field.Elts = append(field.Elts, &ast.Field{Label: ast.NewString("default"), Value: def})
+		}
+		fields = append(fields, field)
+	}
+
+	s := ast.NewStruct(
+		"type", ast.NewString("record"),
+		"name", ast.NewString(name),
+	)
+	if doc := docText(v); doc != "" {
+		s.Elts = append(s.Elts, &ast.Field{
+			Label: ast.NewString("doc"),
+			Value: ast.NewString(doc),
+		})
+	}
+	s.Elts = append(s.Elts, &ast.Field{
+		Label: ast.NewString("fields"),
+		Value: ast.NewList(fields...),
+	})
+	return s, nil
+}
+
+// withNull returns a union of null and t, with null first.
+func withNull(t ast.Expr) ast.Expr {
+	elems := []ast.Expr{t}
+	if list, ok := t.(*ast.ListLit); ok {
+		elems = list.Elts
+	}
+	types := []ast.Expr{ast.NewString("null")}
+	for _, t := range elems {
+		if lit, ok := t.(*ast.BasicLit); ok && lit.Value == `"null"` {
+			continue
+		}
+		types = append(types, t)
+	}
+	return ast.NewList(types...)
+}
+
+// defaultValue returns the default value of v if it is a concrete scalar,
+// or nil otherwise.
+func defaultValue(v cue.Value) ast.Expr {
+	d, ok := v.Default()
+	if !ok || !d.IsConcrete() {
+		return nil
+	}
+	switch d.Kind() {
+	case cue.NullKind, cue.BoolKind, cue.IntKind, cue.FloatKind, cue.StringKind:
+		x, _ := d.Syntax(cue.Final()).(ast.Expr)
+		return x
+	}
+	return nil
+}
+
+// docText returns the doc comments of v as a single string.
+func docText(v cue.Value) string {
+	var a []string
+	for _, c := range v.Doc() {
+		if s := strings.TrimSpace(c.Text()); s != "" {
+			a = append(a, s)
+		}
+	}
+	return strings.Join(a, "\n")
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/encoding/avro"
+	internaljson "cuelang.org/go/internal/encoding/json"
+)
+
+func TestGenerate(t *testing.T) {
+	v := cuecontext.New().CompileString(`
+	// An Event records a change to an item.
+	#Event: {
+		id:    int
+		seq:   int32
+		kind:  *"create" | "delete"
+		// The name of the item.
+		name:  string
+		score: float | *1.5
+		note:  null | *"none" | string
+		tags:  [...string]
+		attrs: [string]: bytes
+		owner: #User
+		prev?: #User
+		source: {
+			host: string
+			port: uint16
+		}
+	}
+	#User: {
+		name:  string
+		ratio: float32
+	}
+	`)
+	s, err := avro.Generate(v.LookupPath(cue.ParsePath("#Event")), &avro.Config{Namespace: "com.example"})
+	qt.Assert(t, qt.IsNil(err))
+	b, err := internaljson.Encode(s)
+	qt.Assert(t, qt.IsNil(err))
+	var buf bytes.Buffer
+	qt.Assert(t, qt.IsNil(json.Indent(&buf, b, "", "  ")))
+	qt.Assert(t, qt.Equals(buf.String(), `{
+  "type": "record",
+  "name": "Event",
+  "namespace": "com.example",
+  "doc": "An Event records a change to an item.",
+  "fields": [
+    {
+      "name": "id",
+      "type": "long"
+    },
+    {
+      "name": "seq",
+      "type": "int"
+    },
+    {
+      "name": "kind",
+      "type": {
+        "type": "enum",
+        "name": "EventKind",
+        "symbols": [
+          "create",
+          "delete"
+        ]
+      },
+      "default": "create"
+    },
+    {
+      "name": "name",
+      "doc": "The name of the item.",
+      "type": "string"
+    },
+    {
+      "name": "score",
+      "type": "double",
+      "default": 1.5
+    },
+    {
+      "name": "note",
+      "type": [
+        "string",
+        "null"
+      ],
+      "default": "none"
+    },
+    {
+      "name": "tags",
+      "type": {
+        "type": "array",
+        "items": "string"
+      }
+    },
+    {
+      "name": "attrs",
+      "type": {
+        "type": "map",
+        "values": "bytes"
+      }
+    },
+    {
+      "name": "owner",
+      "type": {
+        "type": "record",
+        "name": "User",
+        "fields": [
+          {
+            "name": "name",
+            "type": "string"
+          },
+          {
+            "name": "ratio",
+            "type": "float"
+          }
+        ]
+      }
+    },
+    {
+      "name": "prev",
+      "type": [
+        "null",
+        "User"
+      ],
+      "default": null
+    },
+    {
+      "name": "source",
+      "type": {
+        "type": "record",
+        "name": "EventSource",
+        "fields": [
+          {
+            "name": "host",
+            "type": "string"
+          },
+          {
+            "name": "port",
+            "type": "int"
+          }
+        ]
+      }
+    }
+  ]
+}`))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	ctx := cuecontext.New()
+	testCases := []struct {
+		name string
+		in   string
+		err  string
+	}{{
+		name: "notStruct",
+		in:   `#X: int`,
+		err:  `avro: schema must be a struct, found int`,
+	}, {
+		name: "fieldName",
+		in:   `#X: "a-b": int`,
+		err:  `avro: invalid field name "a-b"`,
+	}, {
+		name: "top",
+		in:   `#X: a: _`,
+		err:  `avro: cannot represent value of type _`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := ctx.CompileString(tc.in).LookupPath(cue.ParsePath("#X"))
+			_, err := avro.Generate(v, nil)
+			qt.Assert(t, qt.ErrorMatches(err, tc.err))
+		})
+	}
+}
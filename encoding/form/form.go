@@ -0,0 +1,420 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package form generates UI-oriented descriptions of CUE schemas, suitable
+// for building configuration forms.
+//
+// Unlike JSON Schema, a form description retains the order in which fields
+// are declared, the defaults of disjunctions, and labels and descriptions
+// derived from comments. Fields may be further annotated with a @form
+// attribute, which accepts the following arguments:
+//
+//	label="..."    the label of the field, overriding the one derived from
+//	               its doc comment or name
+//	group="..."    the name of the group in which the field is displayed
+//	message="..."  the message to display when validation of the field fails
+//	placeholder="..." a placeholder value displayed for empty inputs
+//	omit           omit the field from the form
+//
+// For example:
+//
+//	#Server: {
+//		// Host name of the server.
+//		host!: string @form(group="Network")
+//
+//		// Port to listen on.
+//		port: *8080 | int & >=1 & <=65535 @form(group="Network", message="invalid port")
+//	}
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// A Config defines options for generating forms.
+type Config struct {
+	// Attribute is the name of the attribute used to annotate fields.
+	// It defaults to "form".
+	Attribute string
+}
+
+// A Form describes the input fields for a CUE schema.
+type Form struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Groups lists the names of all groups in the order in which they first
+	// occur in the form.
+	Groups []string `json:"groups,omitempty"`
+
+	Fields []*Field `json:"fields"`
+}
+
+// A Field describes a single input of a form.
+type Field struct {
+	// Name is the name of the field within its parent.
+	Name string `json:"name"`
+
+	// Path is the path of the field relative to the schema, using the
+	// syntax of cue.ParsePath. The elements of lists are denoted by [*].
+	Path string `json:"path"`
+
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
+	Group       string `json:"group,omitempty"`
+
+	// Type is one of "string", "int", "number", "bool", "bytes", "null",
+	// "object", "list", or "any".
+	Type string `json:"type"`
+
+	// Required indicates that the field must be set by the user. A field is
+	// required if it is marked as required, or if it is a regular field
+	// without a default that is not an object.
+	Required bool `json:"required,omitempty"`
+
+	// Default holds the JSON encoding of the default value, if any.
+	Default json.RawMessage `json:"default,omitempty"`
+
+	// Enum holds the JSON encoding of the permitted values, if the field
+	// is a disjunction of concrete values.
+	Enum []json.RawMessage `json:"enum,omitempty"`
+
+	Validations []*Validation `json:"validations,omitempty"`
+
+	// Fields holds the fields of objects.
+	Fields []*Field `json:"fields,omitempty"`
+
+	// Items describes the elements of lists.
+	Items *Field `json:"items,omitempty"`
+}
+
+// A Validation describes a constraint on the value of a field.
+type Validation struct {
+	// Rule is one of "minimum", "maximum", "exclusiveMinimum",
+	// "exclusiveMaximum", "notEqual", "pattern", or "notPattern".
+	Rule    string          `json:"rule"`
+	Value   json.RawMessage `json:"value"`
+	Message string          `json:"message"`
+}
+
+// Generate returns a form description for the struct v, which is typically
+// a definition.
+func Generate(v cue.Value, cfg *Config) (*Form, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	g := &generator{attr: cfg.Attribute, groups: map[string]bool{}}
+	if g.attr == "" {
+		g.attr = "form"
+	}
+	if v.IncompleteKind() != cue.StructKind {
+		return nil, errors.Newf(v.Pos(), "form: value is not a struct")
+	}
+	f := &Form{}
+	f.Title, f.Description = docText(v)
+	if a := v.Attribute(g.attr); a.Err() == nil {
+		if s, ok, _ := a.Lookup(0, "label"); ok {
+			f.Title = s
+		}
+	}
+	f.Fields = g.fields(v, cue.Path{})
+	f.Groups = g.order
+	if g.err != nil {
+		return nil, g.err
+	}
+	return f, nil
+}
+
+// Marshal returns the JSON encoding of the form description for v.
+func Marshal(v cue.Value, cfg *Config) ([]byte, error) {
+	f, err := Generate(v, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(f, "", "    ")
+}
+
+type generator struct {
+	attr   string
+	groups map[string]bool
+	order  []string
+	err    errors.Error
+
+	// stack holds the references of the values being expanded, to avoid
+	// infinite recursion on recursive schemas.
+	stack []string
+}
+
+func (g *generator) addErr(err error) {
+	if err != nil {
+		g.err = errors.Append(g.err, errors.Promote(err, "form"))
+	}
+}
+
+func (g *generator) fields(v cue.Value, p cue.Path) (a []*Field) {
+	iter, err := v.Fields(cue.Optional(true))
+	if err != nil {
+		g.addErr(err)
+		return nil
+	}
+	for iter.Next() {
+		name := iter.Selector().Unquoted()
+		f := g.field(iter.Value(), name, cue.MakePath(append(p.Selectors(), cue.Str(name))...))
+		if f == nil {
+			continue
+		}
+		_, hasDefault := iter.Value().Default()
+		switch {
+		case iter.FieldType()&cue.RequiredConstraint != 0:
+			f.Required = true
+		case iter.IsOptional(), hasDefault, f.Type == "object":
+		default:
+			f.Required = true
+		}
+		a = append(a, f)
+	}
+	return a
+}
+
+// field returns the description of v, or nil if it is omitted from the form.
+func (g *generator) field(v cue.Value, name string, p cue.Path) *Field {
+	f := &Field{
+		Name: name,
+		Path: p.String(),
+		Type: kindString(v.IncompleteKind()),
+	}
+
+	f.Label, f.Description = docText(v)
+	if f.Label == "" {
+		f.Label = labelFromName(name)
+	}
+
+	var message string
+	if a := v.Attribute(g.attr); a.Err() == nil {
+		if omit, _ := a.Flag(0, "omit"); omit {
+			return nil
+		}
+		if s, ok, _ := a.Lookup(0, "label"); ok {
+			f.Label = s
+		}
+		if s, ok, _ := a.Lookup(0, "group"); ok {
+			f.Group = s
+			if !g.groups[s] {
+				g.groups[s] = true
+				g.order = append(g.order, s)
+			}
+		}
+		f.Placeholder, _, _ = a.Lookup(0, "placeholder")
+		message, _, _ = a.Lookup(0, "message")
+	}
+
+	if d, ok := v.Default(); ok && d.IsConcrete() && isScalar(d) {
+		f.Default = g.marshal(d)
+	}
+	f.Enum = g.enum(v)
+	g.validations(f, v, message)
+
+	switch f.Type {
+	case "object":
+		if g.push(v) {
+			f.Fields = g.fields(v, p)
+			g.pop()
+		}
+	case "list":
+		elem := v.LookupPath(cue.MakePath(cue.AnyIndex))
+		if elem.Exists() && g.push(v) {
+			f.Items = g.field(elem, "", cue.MakePath(append(p.Selectors(), cue.AnyElement)...))
+			g.pop()
+		}
+	}
+	return f
+}
+
+// push reports whether the schema of v may be expanded, recording it as
+// being expanded if so.
+func (g *generator) push(v cue.Value) bool {
+	ref := ""
+	if root, p := v.ReferencePath(); root.Exists() {
+		ref = p.String()
+	}
+	if ref != "" {
+		for _, r := range g.stack {
+			if r == ref {
+				return false
+			}
+		}
+	}
+	g.stack = append(g.stack, ref)
+	return true
+}
+
+func (g *generator) pop() {
+	g.stack = g.stack[:len(g.stack)-1]
+}
+
+// enum returns the values of v if v is a disjunction of concrete scalars.
+func (g *generator) enum(v cue.Value) (a []json.RawMessage) {
+	op, args := v.Expr()
+	if op != cue.OrOp {
+		return nil
+	}
+	for _, x := range args {
+		if !x.IsConcrete() || !isScalar(x) {
+			return nil
+		}
+		a = append(a, g.marshal(x))
+	}
+	return a
+}
+
+func (g *generator) validations(f *Field, v cue.Value, message string) {
+	op, args := v.Expr()
+	if op == cue.AndOp {
+		for _, x := range args {
+			g.validations(f, x, message)
+		}
+		return
+	}
+	if len(args) != 1 || !args[0].IsConcrete() {
+		return
+	}
+	var rule, format string
+	switch op {
+	case cue.GreaterThanEqualOp:
+		rule, format = "minimum", "must be at least %s"
+	case cue.LessThanEqualOp:
+		rule, format = "maximum", "must be at most %s"
+	case cue.GreaterThanOp:
+		rule, format = "exclusiveMinimum", "must be greater than %s"
+	case cue.LessThanOp:
+		rule, format = "exclusiveMaximum", "must be less than %s"
+	case cue.NotEqualOp:
+		rule, format = "notEqual", "must not be %s"
+	case cue.RegexMatchOp:
+		rule, format = "pattern", "must match %s"
+	case cue.NotRegexMatchOp:
+		rule, format = "notPattern", "must not match %s"
+	default:
+		return
+	}
+	val := g.marshal(args[0])
+	if message == "" {
+		message = fmt.Sprintf(format, val)
+	}
+	f.Validations = append(f.Validations, &Validation{
+		Rule:    rule,
+		Value:   val,
+		Message: message,
+	})
+}
+
+func (g *generator) marshal(v cue.Value) json.RawMessage {
+	b, err := v.MarshalJSON()
+	g.addErr(err)
+	return b
+}
+
+func isScalar(v cue.Value) bool {
+	switch v.Kind() {
+	case cue.StructKind, cue.ListKind:
+		return false
+	}
+	return true
+}
+
+func kindString(k cue.Kind) string {
+	switch k {
+	case cue.StringKind:
+		return "string"
+	case cue.IntKind:
+		return "int"
+	case cue.FloatKind, cue.NumberKind:
+		return "number"
+	case cue.BoolKind:
+		return "bool"
+	case cue.BytesKind:
+		return "bytes"
+	case cue.NullKind:
+		return "null"
+	case cue.StructKind:
+		return "object"
+	case cue.ListKind:
+		return "list"
+	}
+	return "any"
+}
+
+// docText returns the label and description derived from the doc comments
+// of v: the first line of the comment is used as the label and the
+// remainder as the description.
+func docText(v cue.Value) (label, description string) {
+	var docs []string
+	for _, cg := range v.Doc() {
+		docs = append(docs, strings.TrimSpace(cg.Text()))
+	}
+	text := strings.TrimSpace(strings.Join(docs, "\n\n"))
+	if text == "" {
+		return "", ""
+	}
+	label, description, _ = strings.Cut(text, "\n")
+	label = strings.TrimSuffix(strings.TrimSpace(label), ".")
+	return label, strings.TrimSpace(description)
+}
+
+// labelFromName derives a label from a field name such as maxReplicas or
+// max_replicas, resulting in "Max replicas".
+func labelFromName(name string) string {
+	name = strings.TrimLeft(name, "#")
+	var words []string
+	start := 0
+	rs := []rune(name)
+	for i, r := range rs {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			if start < i {
+				words = append(words, string(rs[start:i]))
+			}
+			start = i + 1
+		case unicode.IsUpper(r) && i > start && !unicode.IsUpper(rs[i-1]):
+			words = append(words, string(rs[start:i]))
+			start = i
+		}
+	}
+	if start < len(rs) {
+		words = append(words, string(rs[start:]))
+	}
+	for i, w := range words {
+		if i > 0 && !isAcronym(w) {
+			words[i] = strings.ToLower(w)
+		}
+	}
+	s := strings.Join(words, " ")
+	if s == "" {
+		return name
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func isAcronym(w string) bool {
+	return len(w) > 1 && strings.ToUpper(w) == w
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package form_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/encoding/form"
+	"cuelang.org/go/internal/cuetest"
+)
+
+// TestGenerate generates forms for the definition named in the comment
+// of each txtar archive in testdata, comparing the result to out.json.
+func TestGenerate(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.txtar")
+	qt.Assert(t, qt.IsNil(err))
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			a, err := txtar.ParseFile(file)
+			qt.Assert(t, qt.IsNil(err))
+
+			var in []byte
+			outIndex := -1
+			for i, f := range a.Files {
+				switch f.Name {
+				case "in.cue":
+					in = f.Data
+				case "out.json":
+					outIndex = i
+				}
+			}
+			qt.Assert(t, qt.Not(qt.Equals(outIndex, -1)))
+
+			v := cuecontext.New().CompileBytes(in)
+			qt.Assert(t, qt.IsNil(v.Err()))
+			v = v.LookupPath(cue.ParsePath(string(bytes.TrimSpace(a.Comment))))
+
+			got, err := form.Marshal(v, nil)
+			qt.Assert(t, qt.IsNil(err))
+			got = append(got, '\n')
+
+			if want := a.Files[outIndex].Data; !bytes.Equal(got, want) {
+				if cuetest.UpdateGoldenFiles {
+					a.Files[outIndex].Data = got
+					qt.Assert(t, qt.IsNil(os.WriteFile(file, txtar.Format(a), 0o666)))
+					return
+				}
+				t.Error(cmp.Diff(string(want), string(got)))
+			}
+		})
+	}
+}
+
+func TestGenerateNotStruct(t *testing.T) {
+	v := cuecontext.New().CompileString(`int`)
+	_, err := form.Generate(v, nil)
+	qt.Assert(t, qt.ErrorMatches(err, "form: value is not a struct"))
+}
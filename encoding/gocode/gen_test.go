@@ -18,6 +18,7 @@ package gocode
 
 import (
 	"bytes"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/encoding/gocode/testdata/pkg1"
 	"cuelang.org/go/encoding/gocode/testdata/pkg2"
+	"cuelang.org/go/encoding/gocode/testdata/pkg3"
 )
 
 type validator interface {
@@ -99,6 +101,36 @@ O.P: invalid value 4 (out of bound >5):
 			I: &pkg2.ImportMe{A: 1000, B: "a"},
 		},
 		want: "nil",
+	}, {
+		name:  "failing generated type",
+		value: &pkg3.Server{Port: 80, Timeout: "1s"},
+		want: `
+2 errors in empty disjunction:
+conflicting values null and {host:!="",port?:(int & >=-2147483648 & <=2147483647 & >0),tls?:(null|#TLS),aliases:[...string],labels?:{[string]:string},timeout:string,debugLevel:int,internal:bool} (mismatched types null and struct):
+    pkg3/instance.cue:x:x
+host: invalid value "" (out of bound !=""):
+    pkg3/instance.cue:x:x
+`,
+	}, {
+		name:  "failing nested generated type",
+		value: &pkg3.Backend{Servers: []pkg3.Server{{Host: "a", Port: -1}}},
+		want: `
+4 errors in empty disjunction:
+conflicting values null and {servers:[...#Server],weight:(*1|float)} (mismatched types null and struct):
+    pkg3/instance.cue:x:x
+servers: 2 errors in empty disjunction:
+servers: conflicting values null and [...#Server] (mismatched types null and list):
+    pkg3/instance.cue:x:x
+servers.0.port: invalid value -1 (out of bound >0):
+    pkg3/instance.cue:x:x
+`,
+	}, {
+		name: "generated type all good",
+		value: &pkg3.Server{
+			Host: "localhost",
+			TLS:  &pkg3.TLS{Cert: "a", Key: "b"},
+		},
+		want: "nil",
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -120,3 +152,31 @@ func errStr(err error) string {
 	r := regexp.MustCompile(`.cue:\d+:\d+`)
 	return r.ReplaceAllString(buf.String(), ".cue:x:x")
 }
+
+func TestGeneratedTags(t *testing.T) {
+	testCases := []struct {
+		field string
+		want  string
+	}{
+		{"Host", `json:"host"`},
+		{"Port", `json:"port,omitempty"`},
+		{"TLS", `json:"tls,omitempty"`},
+		{"Timeout", `json:"timeout,string"`},
+		{"Debug", `json:"debugLevel" yaml:"debug"`},
+	}
+	typ := reflect.TypeOf(pkg3.Server{})
+	for _, tc := range testCases {
+		t.Run(tc.field, func(t *testing.T) {
+			f, ok := typ.FieldByName(tc.field)
+			if !ok {
+				t.Fatalf("field %s not found", tc.field)
+			}
+			if got := string(f.Tag); got != tc.want {
+				t.Errorf("got %s; want %s", got, tc.want)
+			}
+		})
+	}
+	if _, ok := typ.FieldByName("Internal"); ok {
+		t.Errorf("field Internal should be omitted")
+	}
+}
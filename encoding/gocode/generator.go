@@ -20,11 +20,13 @@ import (
 	"go/ast"
 	"go/format"
 	"go/types"
+	"strings"
 	"text/template"
 
 	"golang.org/x/tools/go/packages"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/internal/value"
 )
@@ -47,10 +49,21 @@ type Config struct {
 	// The cue.Runtime variable name to use for initializing Codecs.
 	// A new Runtime is created by default.
 	RuntimeVar string
+
+	// Types enables generating Go struct types for exported top-level
+	// structs and definitions for which there is no Go type in the package.
+	Types bool
+
+	// Tags lists the struct tag keys to generate for the fields of
+	// generated types. It defaults to json.
+	Tags []string
 }
 
 const defaultPrefix = "cuegen"
 
+// generatedComment is the first line of the generated code.
+const generatedComment = "Code generated by gocode.Generate; DO NOT EDIT.\n"
+
 // Generate generates Go code for the given instance in the directory of the
 // given package.
 //
@@ -74,6 +87,24 @@ const defaultPrefix = "cuegen"
 //	                 Setting this to the empty string disables generation.
 //	func             Generate as a function instead of a method.
 //
+// # Generating Types
+//
+// If Config.Types is set, Generate also generates Go struct types for
+// exported structs and definitions that have no namesake Go type, along with
+// their validation and completion methods. The name of a definition is used
+// without its leading '#'. The fields of a generated struct are named after
+// the go attribute, if present, or the capitalized CUE label otherwise. A
+// type given in the go attribute is used if it does not refer to another
+// package. Otherwise the type is derived from the CUE value: references to
+// generated types are used by name and a struct that may be null becomes a
+// pointer.
+//
+// For each key in Config.Tags, a field is given a struct tag with the
+// contents of the attribute with the same name, such as @json(name,string),
+// or, if absent, the CUE label, followed by ",omitempty" for optional
+// fields. This is the reverse of the mapping used by cue get go, which
+// records such attributes only for tags that cannot be derived.
+//
 // # Selection and Naming
 //
 // Generate will not generate any code for fields that have no go attribute
@@ -91,7 +122,6 @@ const defaultPrefix = "cuegen"
 //
 // Caveats
 // Currently not supported:
-//   - option to generate Go structs (or automatically generate if undefined)
 //   - for type option to refer to types outside the package.
 func Generate(pkgPath string, inst cue.InstanceOrValue, c *Config) (b []byte, err error) {
 	// TODO: if inst is nil, the instance is loaded from CUE files in the same
@@ -101,11 +131,13 @@ func Generate(pkgPath string, inst cue.InstanceOrValue, c *Config) (b []byte, er
 	}
 
 	g := &generator{
-		Config:  *c,
-		typeMap: map[string]types.Type{},
+		Config:    *c,
+		typeMap:   map[string]types.Type{},
+		typeNames: map[string]string{},
 	}
 
 	val := inst.Value()
+	g.inst = val.BuildInstance()
 	pkgName := inst.Value().BuildInstance().PkgName
 	if pkgPath != "" {
 		loadCfg := &packages.Config{
@@ -132,10 +164,22 @@ func Generate(pkgPath string, inst cue.InstanceOrValue, c *Config) (b []byte, er
 
 		pkgName = g.pkg.Name
 
+		generated := map[string]bool{}
+		for _, f := range g.pkg.Syntax {
+			if len(f.Comments) > 0 && f.Comments[0].Text() == generatedComment {
+				generated[g.pkg.Fset.File(f.Pos()).Name()] = true
+			}
+		}
+
 		for _, obj := range g.pkg.TypesInfo.Defs {
 			if obj == nil || obj.Pkg() != g.pkg.Types || obj.Parent() == nil {
 				continue
 			}
+			// Skip declarations of previously generated code, as it will
+			// be replaced.
+			if generated[g.pkg.Fset.Position(obj.Pos()).Filename] {
+				continue
+			}
 			g.typeMap[obj.Name()] = obj.Type()
 		}
 	}
@@ -149,8 +193,17 @@ func Generate(pkgPath string, inst cue.InstanceOrValue, c *Config) (b []byte, er
 	iter, err := val.Fields(cue.Definitions(true))
 	g.addErr(err)
 
+	var decls []decl
 	for iter.Next() {
-		g.decl(iter.Label(), iter.Value())
+		d := decl{iter.Selector().String(), iter.Value()}
+		decls = append(decls, d)
+		if goName, ok := g.genTypeName(d.name, d.v); ok {
+			g.typeNames[d.name] = goName
+		}
+	}
+
+	for _, d := range decls {
+		g.decl(d.name, d.v)
 	}
 
 	r := value.ConvertToRuntime(val.Context())
@@ -179,8 +232,13 @@ func Generate(pkgPath string, inst cue.InstanceOrValue, c *Config) (b []byte, er
 type generator struct {
 	Config
 	pkg     *packages.Package
+	inst    *build.Instance
 	typeMap map[string]types.Type
 
+	// typeNames maps the names of top-level declarations for which a Go type
+	// is generated to the name of this type.
+	typeNames map[string]string
+
 	w   bytes.Buffer
 	err errors.Error
 }
@@ -195,9 +253,45 @@ func (g *generator) exec(t *template.Template, data interface{}) {
 	g.addErr(t.Execute(&g.w, data))
 }
 
+type decl struct {
+	name string
+	v    cue.Value
+}
+
+// genTypeName reports the name of the Go type to generate for the top-level
+// declaration with the given name and value, if any.
+func (g *generator) genTypeName(name string, v cue.Value) (string, bool) {
+	if !g.Types || v.IncompleteKind() != cue.StructKind {
+		return "", false
+	}
+	attr := v.Attribute("go")
+	goName := strings.TrimPrefix(name, "#")
+	switch s, _ := attr.String(0); s {
+	case "":
+	case "-":
+		return "", false
+	default:
+		goName = s
+	}
+	if _, ok, _ := attr.Lookup(1, "type"); ok {
+		return "", false
+	}
+	if !ast.IsExported(goName) || g.typeMap[goName] != nil {
+		return "", false
+	}
+	return goName, true
+}
+
 func (g *generator) decl(name string, v cue.Value) {
 	attr := v.Attribute("go")
 
+	if goName, ok := g.typeNames[name]; ok {
+		g.genType(goName, v)
+		isFunc, _ := attr.Flag(1, "func")
+		g.stub(name, "*"+goName, fmt.Sprintf("&%s{}", goName), isFunc, attr)
+		return
+	}
+
 	if !ast.IsExported(name) && attr.Err() != nil {
 		return
 	}
@@ -243,11 +337,18 @@ func (g *generator) decl(name string, v cue.Value) {
 		}
 	}
 
+	g.stub(name, goType, zero, isFunc, attr)
+}
+
+// stub generates the validate and complete functions or methods for the
+// top-level CUE value with the given name.
+func (g *generator) stub(name, goType, zero string, isFunc bool, attr cue.Attribute) {
 	g.exec(stubCode, map[string]interface{}{
 		"prefix":  strValue(g.Prefix, defaultPrefix),
-		"cueName": name,   // the field name of the CUE type
-		"goType":  goType, // the receiver or argument type
-		"zero":    zero,   // the zero value of the underlying type
+		"cueName": name,                          // the field name of the CUE type
+		"idName":  strings.TrimPrefix(name, "#"), // the name used in Go identifiers
+		"goType":  goType,                        // the receiver or argument type
+		"zero":    zero,                          // the zero value of the underlying type
 
 		// @go attribute options
 		"func":     isFunc,
@@ -38,26 +38,27 @@ import (
 // Inputs:
 // .prefix 	  prefix to all generated variable names
 // .cueName   name of the top-level CUE value
+// .idName    name of the top-level CUE value used in Go identifiers
 // .goType    Go type of the receiver or argument
 // .zero      zero value of the Go type; nil indicates no value
 // .validate  name of the validate function; "" means no validate
 // .complete  name of the complete function; "" means no complete
 var stubCode = template.Must(template.New("type").Parse(`
-var {{.prefix}}val{{.cueName}} = {{.prefix}}Make("{{.cueName}}", {{.zero}})
+var {{.prefix}}val{{.idName}} = {{.prefix}}Make("{{.cueName}}", {{.zero}})
 
 {{ $sig := .goType | printf "(x %s)" -}}
 {{if .validate}}
-// {{.validate}}{{if .func}}{{.cueName}}{{end}} validates x.
-func {{if .func}}{{.validate}}{{.cueName}}{{$sig}}
+// {{.validate}}{{if .func}}{{.idName}}{{end}} validates x.
+func {{if .func}}{{.validate}}{{.idName}}{{$sig}}
      {{- else -}}{{$sig}} {{.validate}}(){{end}} error {
-	return {{.prefix}}Codec.Validate({{.prefix}}val{{.cueName}}, x)
+	return {{.prefix}}Codec.Validate({{.prefix}}val{{.idName}}, x)
 }
 {{end}}
 {{if .complete}}
-// {{.complete}}{{if .func}}{{.cueName}}{{end}} completes x.
-func {{if .func}}{{.complete}}{{.cueName}}{{$sig}}
+// {{.complete}}{{if .func}}{{.idName}}{{end}} completes x.
+func {{if .func}}{{.complete}}{{.idName}}{{$sig}}
      {{- else -}}{{$sig}} {{.complete}}(){{end}} error {
-	return {{.prefix}}Codec.Complete({{.prefix}}val{{.cueName}}, x)
+	return {{.prefix}}Codec.Complete({{.prefix}}val{{.idName}}, x)
 }
 {{end}}
 `))
@@ -53,7 +53,7 @@ func main() {
 		}
 
 		goPkg := "./testdata/" + d.Name()
-		b, err := gocode.Generate(goPkg, inst, nil)
+		b, err := gocode.Generate(goPkg, inst, &gocode.Config{Types: true})
 		if err != nil {
 			log.Fatal(err)
 		}
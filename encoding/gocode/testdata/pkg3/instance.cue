@@ -0,0 +1,31 @@
+package pkg3
+
+// Server is the configuration of a server.
+#Server: {
+	// Host is the name of the host.
+	host: string & !=""
+
+	port?: int32 & >0
+
+	tls?: null | #TLS @go(TLS)
+
+	aliases: [...string]
+
+	labels?: {[string]: string}
+
+	timeout: string @go(,type=time.Duration) @json(timeout,string)
+
+	debugLevel: int @go(Debug) @yaml(debug)
+
+	internal: bool @go(-)
+}
+
+#TLS: {
+	cert!: string
+	key!:  string
+}
+
+Backend: {
+	servers: [...#Server]
+	weight:  *1 | float
+}
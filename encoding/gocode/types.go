@@ -0,0 +1,238 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gocode
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cuelang.org/go/cue"
+)
+
+// genType writes a Go struct type with the given name for the CUE struct v.
+func (g *generator) genType(name string, v cue.Value) {
+	b := &strings.Builder{}
+	b.WriteString("\n")
+	writeDoc(b, v)
+	fmt.Fprintf(b, "type %s ", name)
+	g.structType(b, v)
+	b.WriteString("\n")
+	g.w.WriteString(b.String())
+}
+
+func (g *generator) structType(b *strings.Builder, v cue.Value) {
+	iter, err := v.Fields(cue.Optional(true))
+	g.addErr(err)
+
+	b.WriteString("struct {\n")
+	for iter.Next() {
+		f := iter.Value()
+		attr := f.Attribute("go")
+		name := goName(iter.Selector().Unquoted())
+		switch s, _ := attr.String(0); s {
+		case "":
+		case "-":
+			continue
+		default:
+			name = s
+		}
+		writeDoc(b, f)
+		fmt.Fprintf(b, "%s %s", name, g.fieldType(f, attr))
+		if tag := g.structTag(iter.Selector(), f); tag != "" {
+			fmt.Fprintf(b, " `%s`", tag)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+}
+
+// fieldType returns the Go type for a field with value v. A type recorded
+// in the go attribute is used if it does not refer to another package.
+func (g *generator) fieldType(v cue.Value, attr cue.Attribute) string {
+	typ, ok, _ := attr.Lookup(1, "type")
+	if !ok {
+		if s, err := attr.String(1); err == nil && !strings.Contains(s, "=") {
+			typ = s
+		}
+	}
+	if typ != "" && !strings.Contains(typ, ".") {
+		return typ
+	}
+	b := &strings.Builder{}
+	g.goType(b, v)
+	return b.String()
+}
+
+// goType writes the Go type corresponding to the CUE value v.
+func (g *generator) goType(b *strings.Builder, v cue.Value) {
+	if name, ok := g.refName(v); ok {
+		b.WriteString(name)
+		return
+	}
+
+	op, args := v.Expr()
+	if op == cue.OrOp {
+		// A disjunction of null and a single other type maps to a pointer.
+		var other []cue.Value
+		for _, a := range args {
+			if a.IncompleteKind() != cue.NullKind {
+				other = append(other, a)
+			}
+		}
+		if len(other) == 1 && len(other) < len(args) {
+			if other[0].IncompleteKind() == cue.StructKind {
+				b.WriteString("*")
+			}
+			g.goType(b, other[0])
+			return
+		}
+	}
+
+	if op == cue.AndOp {
+		// Predeclared Go integer and float types, such as int32 or
+		// float64, are expanded to bounds by the evaluator.
+		for _, a := range args {
+			if s := fmt.Sprint(a); mappedGoTypes(s) {
+				b.WriteString(s)
+				return
+			}
+		}
+	}
+
+	switch k := v.IncompleteKind(); k {
+	case cue.BoolKind:
+		b.WriteString("bool")
+	case cue.StringKind:
+		b.WriteString("string")
+	case cue.BytesKind:
+		b.WriteString("[]byte")
+	case cue.IntKind:
+		b.WriteString("int")
+	case cue.FloatKind, cue.NumberKind:
+		b.WriteString("float64")
+	case cue.ListKind:
+		b.WriteString("[]")
+		if elem := v.LookupPath(cue.MakePath(cue.AnyIndex)); elem.Exists() {
+			g.goType(b, elem)
+		} else {
+			b.WriteString("interface{}")
+		}
+	case cue.StructKind:
+		iter, _ := v.Fields(cue.Optional(true))
+		if elem := v.LookupPath(cue.MakePath(cue.AnyString)); elem.Exists() && !iter.Next() {
+			b.WriteString("map[string]")
+			g.goType(b, elem)
+			return
+		}
+		g.structType(b, v)
+	default:
+		b.WriteString("interface{}")
+	}
+}
+
+// refName reports the Go name of the top-level declaration referred to by v,
+// if any.
+func (g *generator) refName(v cue.Value) (string, bool) {
+	root, path := v.ReferencePath()
+	sels := path.Selectors()
+	if len(sels) != 1 || root.BuildInstance() != g.inst {
+		return "", false
+	}
+	name, ok := g.typeNames[sels[0].String()]
+	return name, ok
+}
+
+// structTag returns the struct tag for a field with the given selector and
+// value. For each configured tag key, it uses the contents of the
+// corresponding attribute, if present, or derives the tag from the field
+// name and whether the field is optional. Attributes for keys that are not
+// configured are carried over as well.
+func (g *generator) structTag(sel cue.Selector, v cue.Value) string {
+	keys := g.Tags
+	if keys == nil {
+		keys = []string{"json"}
+	}
+	var tags []string
+	seen := map[string]bool{}
+	add := func(key, value string) {
+		seen[key] = true
+		tags = append(tags, key+":"+strconv.Quote(value))
+	}
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		if a := v.Attribute(key); a.Err() == nil {
+			add(key, a.Contents())
+			continue
+		}
+		tag := sel.Unquoted()
+		if sel.ConstraintType() == cue.OptionalConstraint {
+			tag += ",omitempty"
+		}
+		add(key, tag)
+	}
+	for _, a := range v.Attributes(cue.FieldAttr) {
+		switch key := a.Name(); key {
+		case "json", "yaml", "xml", "toml":
+			if !seen[key] {
+				add(key, a.Contents())
+			}
+		}
+	}
+	return strings.Join(tags, " ")
+}
+
+// goName converts a CUE label to an exported Go identifier by upper casing
+// the first letter of each word and dropping characters that are not valid
+// in an identifier.
+func goName(label string) string {
+	label = strings.TrimLeft(label, "#_")
+	b := &strings.Builder{}
+	upper := true
+	for _, r := range label {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			b.WriteRune(r)
+		default:
+			upper = true
+		}
+	}
+	s := b.String()
+	if !token.IsIdentifier(s) || !token.IsExported(s) {
+		s = "X" + s
+	}
+	return s
+}
+
+// writeDoc writes the documentation of v as Go comments.
+func writeDoc(b *strings.Builder, v cue.Value) {
+	for _, cg := range v.Doc() {
+		for _, line := range strings.Split(strings.TrimSpace(cg.Text()), "\n") {
+			if line == "" {
+				b.WriteString("//\n")
+				continue
+			}
+			fmt.Fprintf(b, "// %s\n", line)
+		}
+	}
+}
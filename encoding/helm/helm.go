@@ -0,0 +1,190 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package helm converts between CUE and the files that Helm charts use to
+// describe their values: the values.schema.json JSON Schema and the
+// default values in values.yaml.
+//
+// This allows CUE to be used as the source of truth for validating the
+// values of a chart, both by chart authors, who can generate
+// values.schema.json from a CUE definition, and by chart consumers, who
+// can import a chart's schema and defaults into CUE.
+package helm
+
+import (
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/jsonschema"
+	"cuelang.org/go/encoding/openapi"
+)
+
+// SchemaVersion is the JSON Schema draft used by generated
+// values.schema.json files.
+const SchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// A Config configures the conversion of Helm chart values.
+type Config struct {
+	// PkgName specifies the package name of files generated by Extract.
+	PkgName string
+
+	_ struct{} // prohibit casting from different type.
+}
+
+// Extract converts the values schema and default values of a Helm chart
+// into CUE. The schema holds the contents of values.schema.json and values
+// holds the contents of values.yaml. Either may be the zero Value if the
+// chart does not provide it.
+//
+// The fields of the resulting file describe the values of the chart. Each
+// value set in values.yaml is marked as the default for its field, so
+// that unifying the file with user-provided values yields the values Helm
+// would use. Extract returns an error if the defaults do not conform to
+// the schema.
+func Extract(schema, values cue.Value, cfg *Config) (*ast.File, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	f := &ast.File{}
+	if schema.Exists() {
+		var err error
+		f, err = jsonschema.Extract(schema, &jsonschema.Config{
+			PkgName: cfg.PkgName,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if cfg.PkgName != "" {
+		f.Decls = append(f.Decls, &ast.Package{Name: ast.NewIdent(cfg.PkgName)})
+	}
+	if schema.Exists() && values.Exists() {
+		v := values.Context().BuildFile(f).Unify(values)
+		if err := v.Validate(cue.Concrete(true)); err != nil {
+			return nil, errors.Wrapf(err, token.NoPos, "default values do not conform to the values schema")
+		}
+	}
+	if values.Exists() {
+		s, ok := values.Syntax(cue.Docs(true)).(*ast.StructLit)
+		if !ok {
+			return nil, errors.Newf(values.Pos(), "values must be a struct")
+		}
+		for _, d := range s.Elts {
+			if field, ok := d.(*ast.Field); ok {
+				field.Value = markDefaults(field.Value)
+			}
+		}
+		// Keep the ellipsis that marks the schema as open at the end.
+		decls := f.Decls
+		var tail []ast.Decl
+		if n := len(decls); n > 0 {
+			if _, ok := decls[n-1].(*ast.Ellipsis); ok {
+				decls, tail = decls[:n-1], decls[n-1:]
+			}
+		}
+		f.Decls = append(append(decls, s.Elts...), tail...)
+	}
+	return f, nil
+}
+
+// markDefaults marks the values in x as default values. Non-empty structs
+// are traversed so that each of their fields can be overridden separately.
+func markDefaults(x ast.Expr) ast.Expr {
+	if s, ok := x.(*ast.StructLit); ok && len(s.Elts) > 0 {
+		for _, d := range s.Elts {
+			if field, ok := d.(*ast.Field); ok {
+				field.Value = markDefaults(field.Value)
+			}
+		}
+		return s
+	}
+	return &ast.BinaryExpr{
+		X:  &ast.UnaryExpr{Op: token.MUL, X: x},
+		Op: token.OR,
+		Y:  ast.NewIdent("_"),
+	}
+}
+
+// Generate generates the contents of a values.schema.json file from the
+// CUE definition v. Definitions referred to by v are included in the
+// definitions section of the resulting JSON Schema.
+func Generate(v cue.Value, cfg *Config) (*ast.StructLit, error) {
+	wrapped := v.Context().CompileString("{}").FillPath(cue.MakePath(cue.Def("#"+valuesName)), v)
+	// OpenAPI 3.1 schemas are compatible with JSON Schema, in particular
+	// in their use of exclusiveMinimum and exclusiveMaximum.
+	g := &openapi.Generator{Version: "3.1.0"}
+	schemas, err := g.Schemas(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	var root *ast.StructLit
+	defs := &ast.StructLit{}
+	for _, d := range schemas.Elts {
+		field := d.(*ast.Field)
+		if name, _, _ := ast.LabelName(field.Label); name == valuesName {
+			root, _ = field.Value.(*ast.StructLit)
+			continue
+		}
+		defs.Elts = append(defs.Elts, field)
+	}
+	if root == nil {
+		return nil, errors.Newf(v.Pos(), "cannot generate schema for value")
+	}
+	s := ast.NewStruct("$schema", ast.NewString(SchemaVersion))
+	s.Elts = append(s.Elts, root.Elts...)
+	if len(defs.Elts) > 0 {
+		s.Elts = append(s.Elts, &ast.Field{
+			Label: ast.NewString("definitions"),
+			Value: defs,
+		})
+	}
+	rewriteRefs(s)
+	return s, nil
+}
+
+// valuesName is the name under which the values schema is generated.
+const valuesName = "Values"
+
+// rewriteRefs changes the references to OpenAPI component schemas in s to
+// refer to the corresponding JSON Schema definitions instead.
+func rewriteRefs(s *ast.StructLit) {
+	const prefix = "#/components/schemas/"
+	ast.Walk(s, func(n ast.Node) bool {
+		field, ok := n.(*ast.Field)
+		if !ok {
+			return true
+		}
+		if name, _, _ := ast.LabelName(field.Label); name != "$ref" {
+			return true
+		}
+		lit, ok := field.Value.(*ast.BasicLit)
+		if !ok {
+			return false
+		}
+		ref, err := strconv.Unquote(lit.Value)
+		if err != nil || !strings.HasPrefix(ref, prefix) {
+			return false
+		}
+		if ref == prefix+valuesName {
+			ref = "#"
+		} else {
+			ref = "#/definitions/" + strings.TrimPrefix(ref, prefix)
+		}
+		lit.Value = strconv.Quote(ref)
+		return false
+	}, nil)
+}
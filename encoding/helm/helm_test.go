@@ -0,0 +1,71 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/encoding/helm"
+	"cuelang.org/go/internal/encoding/json"
+)
+
+func TestExtract(t *testing.T) {
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(`{
+		type: "object"
+		properties: replicas: {type: "integer", minimum: 1}
+	}`)
+	values := ctx.CompileString(`{replicas: 2, name: "x"}`)
+
+	f, err := helm.Extract(schema, values, &helm.Config{PkgName: "values"})
+	qt.Assert(t, qt.IsNil(err))
+	b, err := format.Node(f, format.Simplify())
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(b), `package values
+
+replicas?: int & >=1
+replicas:  *2 | _
+name:      *"x" | _
+...
+`))
+
+	_, err = helm.Extract(schema, ctx.CompileString(`{replicas: 0}`), nil)
+	qt.Assert(t, qt.ErrorMatches(err, `default values do not conform to the values schema.*`))
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(`
+	#Values: {
+		replicas: int & >=1
+		image:    #Image
+	}
+	#Image: name: string
+	`)
+
+	s, err := helm.Generate(v.LookupPath(cue.ParsePath("#Values")), nil)
+	qt.Assert(t, qt.IsNil(err))
+	b, err := json.Encode(s)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(b), `{"$schema":"http://json-schema.org/draft-07/schema#",`+
+		`"type":"object","required":["replicas","image"],`+
+		`"properties":{"replicas":{"type":"integer","minimum":1},"image":{"$ref":"#/definitions/Image"}},`+
+		`"definitions":{"Image":{"type":"object","required":["name"],"properties":{"name":{"type":"string"}}}}}`))
+}
@@ -16,10 +16,12 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
@@ -98,12 +100,15 @@ func extract(path string, b []byte) (ast.Expr, error) {
 //
 // The runtime may be nil if Decode isn't used.
 func NewDecoder(r *cue.Runtime, path string, src io.Reader) *Decoder {
-	return &Decoder{
+	d := &Decoder{
 		r:      r,
 		path:   path,
-		dec:    json.NewDecoder(src),
 		offset: 1,
+		line:   1,
+		column: 1,
 	}
+	d.dec = json.NewDecoder(io.TeeReader(src, &d.buf))
+	return d
 }
 
 // A Decoder converts JSON values to CUE.
@@ -112,6 +117,15 @@ type Decoder struct {
 	path   string
 	dec    *json.Decoder
 	offset int
+
+	// buf holds the input read by dec from input offset read onwards.
+	// It is used to track the line and column of the values in the input,
+	// so that positions in the extracted values refer to the input, rather
+	// than to the value itself.
+	buf    bytes.Buffer
+	read   int64
+	line   int
+	column int
 }
 
 // Extract converts the current JSON value to a CUE ast. It returns io.EOF
@@ -132,18 +146,52 @@ func (d *Decoder) extract() (ast.Expr, error) {
 		return nil, err
 	}
 	offset := d.offset
-	d.offset += len(raw)
 	if err != nil {
+		d.offset += len(raw)
 		pos := token.NewFile(d.path, offset, len(raw)).Pos(0, 0)
 		return nil, errors.Wrapf(err, pos, "invalid JSON for file %q", d.path)
 	}
-	expr, err := parser.ParseExpr(d.path, []byte(raw), parser.FileOffset(offset))
+	line, column := d.advance(raw)
+
+	// Indent the value to its column in the input so that the columns of
+	// positions on its first line are correct, and register the line at
+	// which it starts so that line numbers are correct.
+	src := append(bytes.Repeat([]byte{' '}, column-1), raw...)
+	d.offset += len(src)
+	expr, err := parser.ParseExpr(d.path, src, parser.FileOffset(offset))
 	if err != nil {
 		return nil, err
 	}
+	if line > 1 {
+		if f := expr.Pos().File(); f != nil {
+			f.AddLineInfo(0, d.path, line)
+		}
+	}
 	return expr, nil
 }
 
+// advance records that raw was the last value decoded from the input and
+// returns the line and column at which it starts.
+func (d *Decoder) advance(raw []byte) (line, column int) {
+	end := d.dec.InputOffset()
+	start := end - int64(len(raw))
+	d.skip(d.buf.Next(int(start - d.read)))
+	line, column = d.line, d.column
+	d.skip(d.buf.Next(len(raw)))
+	d.read = end
+	return line, column
+}
+
+// skip advances the current line and column past b.
+func (d *Decoder) skip(b []byte) {
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		d.line += bytes.Count(b, []byte{'\n'})
+		d.column = 1
+		b = b[i+1:]
+	}
+	d.column += utf8.RuneCount(b)
+}
+
 // Decode converts the current JSON value to a CUE instance. It returns io.EOF
 // if the input has been exhausted.
 //
@@ -150,3 +150,33 @@ func toString(w *bytes.Buffer, e ast.Expr, err error) {
 	}
 	fmt.Fprint(w, string(b))
 }
+
+func TestDecoderPositions(t *testing.T) {
+	in := `{"a": 1}
+  {"a":
+"b"}  {"a": [true]}
+
+[null]
+`
+	d := NewDecoder(nil, "test.jsonl", strings.NewReader(in))
+	var got []string
+	for {
+		e, err := d.Extract()
+		if err == io.EOF {
+			break
+		}
+		qt.Assert(t, qt.IsNil(err))
+		ast.Walk(e, func(n ast.Node) bool {
+			if x, ok := n.(*ast.BasicLit); ok {
+				got = append(got, fmt.Sprintf("%s %s", x.Value, x.Pos()))
+			}
+			return true
+		}, nil)
+	}
+	qt.Assert(t, qt.DeepEquals(got, []string{
+		`1 test.jsonl:1:7`,
+		`"b" test.jsonl:3:1`,
+		`true test.jsonl:3:14`,
+		`null test.jsonl:5:2`,
+	}))
+}
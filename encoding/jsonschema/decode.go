@@ -43,6 +43,10 @@ type decoder struct {
 	cfg   *Config
 	errs  errors.Error
 	numID int // for creating unique numbers: increment on each use
+
+	// refs holds the URLs, without fragment, of the schemas referred to
+	// by imports.
+	refs []*url.URL
 }
 
 // addImport registers
@@ -101,6 +105,9 @@ func (d *decoder) decode(v cue.Value) *ast.File {
 
 func (d *decoder) schema(ref []ast.Label, v cue.Value) (a []ast.Decl) {
 	root := state{decoder: d}
+	if u, err := url.Parse(d.cfg.ID); err == nil && u.IsAbs() {
+		root.id = u
+	}
 
 	var name ast.Label
 	inner := len(ref) - 1
@@ -0,0 +1,206 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// An Extractor converts a collection of JSON Schema files that refer to
+// each other to CUE, generating a CUE package for each file. References
+// between the schemas are converted to imports of the corresponding
+// packages, of which the import paths are determined by
+// Config.ImportPaths.
+//
+// Schemas referred to by the added schemas are loaded with
+// Config.LoadFile and converted as well. A schema with a URL below the
+// $id of an added schema is looked for at the same location relative to
+// the file of that schema, so that suites of schemas that refer to each
+// other with relative references can be converted from local copies.
+type Extractor struct {
+	cfg *Config
+
+	schemas []*schemaFile
+	seen    map[string]bool // by URL without fragment
+
+	errs errors.Error
+	done bool
+}
+
+type schemaFile struct {
+	url      *url.URL
+	filename string
+	value    cue.Value
+}
+
+// NewExtractor creates an Extractor. The PkgName and ID fields of the
+// configuration are ignored, as they are determined for each schema.
+func NewExtractor(cfg *Config) *Extractor {
+	return &Extractor{
+		cfg:  cfg,
+		seen: map[string]bool{},
+	}
+}
+
+// AddFile adds the JSON Schema v, read from the given file, to be
+// converted. The URL of the schema is its $id, if it has one, or
+// otherwise the URL of the file.
+func (e *Extractor) AddFile(filename string, v cue.InstanceOrValue) error {
+	if e.done {
+		err := errors.Newf(token.NoPos,
+			"jsonschema: cannot call AddFile: Instances was already called")
+		e.errs = errors.Append(e.errs, err)
+		return err
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+	u := &url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+	if id, err := v.Value().LookupPath(cue.MakePath(cue.Str("$id"))).String(); err == nil {
+		ref, err := url.Parse(id)
+		if err != nil {
+			err := errors.Newf(token.NoPos, "invalid $id %q in %s: %v", id, filename, err)
+			e.errs = errors.Append(e.errs, err)
+			return err
+		}
+		u = u.ResolveReference(ref)
+		u.Fragment = ""
+	}
+	e.add(u, abs, v.Value())
+	return nil
+}
+
+func (e *Extractor) add(u *url.URL, filename string, v cue.Value) {
+	e.seen[u.String()] = true
+	e.schemas = append(e.schemas, &schemaFile{
+		url:      u,
+		filename: filename,
+		value:    v,
+	})
+}
+
+// Instances converts the added schemas, and the schemas they refer to,
+// recursively, and returns a build.Instance for each, sorted by import
+// path. Each instance holds a single file, named after the schema file,
+// and has no Dir set, as the location of a package depends on the
+// module into which it is generated.
+func (e *Extractor) Instances() (instances []*build.Instance, err error) {
+	e.done = true
+	byPath := map[string]*schemaFile{}
+	// Schemas referred to are appended to e.schemas while iterating.
+	for i := 0; i < len(e.schemas); i++ {
+		s := e.schemas[i]
+		d := &decoder{cfg: e.cfg}
+		importPath, pkgName, ok := d.importPath(s.url)
+		if !ok {
+			e.errs = errors.Append(e.errs, errors.Newf(token.NoPos,
+				"no import path for schema %s", s.url))
+			continue
+		}
+		importPath, _, _ = strings.Cut(importPath, ":")
+		if other := byPath[importPath]; other != nil {
+			e.errs = errors.Append(e.errs, errors.Newf(token.NoPos,
+				"schemas %s and %s map to the same package %s", other.url, s.url, importPath))
+			continue
+		}
+		byPath[importPath] = s
+
+		cfg := *e.cfg
+		cfg.ID = s.url.String()
+		cfg.PkgName = pkgName
+		d.cfg = &cfg
+		f := d.decode(s.value)
+		if d.errs != nil {
+			e.errs = errors.Append(e.errs, d.errs)
+			continue
+		}
+		base := path.Base(s.url.Path)
+		f.Filename = strings.TrimSuffix(base, path.Ext(base)) + ".cue"
+
+		displayPath := s.filename
+		if displayPath == "" {
+			displayPath = s.url.String()
+		}
+		inst := &build.Instance{
+			ImportPath:  importPath,
+			PkgName:     pkgName,
+			DisplayPath: displayPath,
+			Files:       []*ast.File{f},
+		}
+		instances = append(instances, inst)
+
+		for _, ref := range d.refs {
+			e.follow(ref)
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].ImportPath < instances[j].ImportPath
+	})
+	if e.errs != nil {
+		return nil, e.errs
+	}
+	return instances, nil
+}
+
+// follow loads the schema with URL u, if it has not been seen yet and
+// can be found locally.
+func (e *Extractor) follow(u *url.URL) {
+	if e.seen[u.String()] {
+		return
+	}
+	e.seen[u.String()] = true
+	filename := e.filename(u)
+	if filename == "" || e.cfg.LoadFile == nil {
+		return
+	}
+	v, err := e.cfg.LoadFile(filename)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		e.errs = errors.Append(e.errs, errors.Promote(err, "jsonschema"))
+	default:
+		e.add(u, filename, v)
+	}
+}
+
+// filename returns the name of the local file holding the schema with
+// URL u, or "" if there is none.
+func (e *Extractor) filename(u *url.URL) string {
+	if u.Scheme == "file" {
+		return filepath.FromSlash(u.Path)
+	}
+	for _, s := range e.schemas {
+		if s.filename == "" || s.url.Scheme != u.Scheme || s.url.Host != u.Host {
+			continue
+		}
+		dir := strings.TrimSuffix(path.Dir(s.url.Path), "/") + "/"
+		if rel, ok := strings.CutPrefix(u.Path, dir); ok {
+			return filepath.Join(filepath.Dir(s.filename), filepath.FromSlash(rel))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/jsonschema"
+)
+
+func TestExtractor(t *testing.T) {
+	archive := txtar.Parse([]byte(`
+-- schemas/person.json --
+{
+	"$id": "https://example.com/schemas/person.json",
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"address": {"$ref": "common/address.json"},
+		"age": {"$ref": "common/types.json#/definitions/age"},
+		"homepage": {"$ref": "https://other.com/url.json"}
+	}
+}
+-- schemas/common/address.json --
+{
+	"type": "object",
+	"properties": {
+		"city": {"type": "string"},
+		"zip": {"$ref": "types.json#/definitions/zip-code"}
+	}
+}
+-- schemas/common/types.json --
+{
+	"definitions": {
+		"age": {"type": "integer", "minimum": 0},
+		"zip-code": {"type": "string"}
+	}
+}
+-- acme.test/schemas/common/address:address --
+package address
+
+import "acme.test/schemas/common/types"
+
+city?: string
+zip?:  types.#["zip-code"]
+...
+-- acme.test/schemas/common/types:types --
+package types
+
+_
+
+#age: int & >=0
+
+#: "zip-code": string
+-- acme.test/schemas/person:person --
+package person
+
+import (
+	"acme.test/schemas/common/address"
+	"acme.test/schemas/common/types"
+	"other.com/url.json:url"
+)
+
+@jsonschema(id="https://example.com/schemas/person.json")
+name?:      string
+"address"?: address
+age?:       types.#age
+homepage?:  url
+...
+`))
+	dir := t.TempDir()
+	var want strings.Builder
+	for _, f := range archive.Files {
+		if !strings.HasPrefix(f.Name, "schemas/") {
+			want.WriteString("-- " + f.Name + " --\n")
+			want.Write(f.Data)
+			continue
+		}
+		path := filepath.Join(dir, f.Name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, f.Data, 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := cuecontext.New()
+	load := func(filename string) (cue.Value, error) {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		expr, err := json.Extract(filename, data)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		return ctx.BuildExpr(expr), nil
+	}
+	e := jsonschema.NewExtractor(&jsonschema.Config{
+		ImportPaths: map[string]string{
+			"example.com/schemas": "acme.test/schemas",
+		},
+		LoadFile: load,
+	})
+	filename := filepath.Join(dir, "schemas/person.json")
+	v, err := load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddFile(filename, v); err != nil {
+		t.Fatal(err)
+	}
+	insts, err := e.Instances()
+	if err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	var got strings.Builder
+	for _, inst := range insts {
+		b, err := format.Node(inst.Files[0], format.Simplify())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.WriteString("-- " + inst.ImportPath + ":" + inst.PkgName + " --\n")
+		got.Write(b)
+	}
+	if diff := cmp.Diff(want.String(), got.String()); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+}
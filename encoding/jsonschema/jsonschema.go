@@ -56,6 +56,7 @@ type Config struct {
 	PkgName string
 
 	// ID sets the URL of the original source, corresponding to the $id field.
+	// If it is an absolute URL, references are resolved relative to it.
 	ID string
 
 	// JSON reference of location containing schema. The empty string indicates
@@ -76,6 +77,34 @@ type Config struct {
 	//    {"$defs", foo}         {#foo} or {#, foo}
 	Map func(pos token.Pos, path []string) ([]ast.Label, error)
 
+	// ImportPaths maps the URLs of schemas to CUE import paths, which are
+	// used to convert references to other schemas to imports. A key
+	// matches the URL of the same host and path, ignoring the scheme,
+	// as in "schemas.example.com/v1/person.json", and, unless the value
+	// specifies a package name, all URLs below it, for which the
+	// remaining elements of the URL path are appended to the import
+	// path. This mirrors the hierarchy of the schemas in the CUE package
+	// hierarchy. The URL of a local file is its absolute path, as in
+	// "/home/user/schemas". The empty key matches all URLs. If multiple
+	// keys match, the longest one is used. A .json, .yaml or .yml
+	// extension is removed from the resulting import path.
+	//
+	// A value may specify a package name after a colon, as in
+	// "example.com/schemas/person:persons". Otherwise the package name
+	// is derived from the last element of the import path.
+	//
+	// References to schemas with a URL that does not match any key are
+	// converted to imports of the host and path of the URL.
+	ImportPaths map[string]string
+
+	// LoadFile loads the JSON Schema in the given file. An Extractor
+	// uses it to load the schemas referred to by the schemas added to it
+	// that are not added themselves. If LoadFile is nil, or returns an
+	// error satisfying errors.Is(err, fs.ErrNotExist), such schemas are
+	// not converted, but references to them are still converted to
+	// imports.
+	LoadFile func(filename string) (cue.Value, error)
+
 	// TODO: configurability to make it compatible with OpenAPI, such as
 	// - locations of definitions: #/components/schemas, for instance.
 	// - selection and definition of formats
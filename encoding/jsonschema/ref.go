@@ -19,6 +19,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
@@ -157,31 +158,24 @@ func (s *state) makeCUERef(n cue.Value, u *url.URL) ast.Expr {
 
 				ident, a = s.getNextIdent(n, a)
 
-			case u.Host != "":
+			case u.Host != "" || u.Scheme == "file":
 				// Reference not found within scope. Create an import reference.
 
-				// TODO: allow the configuration to specify a map from
-				// URI domain+paths to CUE packages.
-
 				// TODO: currently only $ids that are in scope can be
 				// referenced. We could consider doing an extra pass to record
 				// all '$id's in a file to be able to link to them even if they
 				// are not in scope.
-				p := u.Path
-
-				base := path.Base(p)
-				if !ast.IsValidIdent(base) {
-					base = strings.TrimSuffix(base, ".json")
-					if !ast.IsValidIdent(base) {
-						// Find something more clever to do there. For now just
-						// pick "schema" as the package name.
-						base = "schema"
-					}
-					p += ":" + base
+				importPath, name, ok := s.importPath(u)
+				if !ok {
+					s.errf(n, "no import path for schema %q", u)
+					return nil
 				}
+				schema := *u
+				schema.Fragment = ""
+				s.refs = append(s.refs, &schema)
 
-				ident = ast.NewIdent(base)
-				ident.Node = &ast.ImportSpec{Path: ast.NewString(u.Host + p)}
+				ident = ast.NewIdent(name)
+				ident.Node = &ast.ImportSpec{Path: ast.NewString(importPath)}
 
 			default:
 				// Just a path, not sure what that means.
@@ -241,6 +235,80 @@ func (s *state) makeCUERef(n cue.Value, u *url.URL) ast.Expr {
 	return s.newSel(ident, n, a)
 }
 
+// importPath returns the import path, with a qualifier if needed, and the
+// package name of the CUE package for the schema at u, as configured by
+// Config.ImportPaths. It reports false if there is none.
+func (d *decoder) importPath(u *url.URL) (importPath, pkgName string, ok bool) {
+	loc := u.Host + u.Path
+	longest := -1
+	for key, value := range d.cfg.ImportPaths {
+		key = strings.TrimSuffix(key, "/")
+		p, name, hasName := strings.Cut(value, ":")
+		switch {
+		case key == loc:
+		case hasName:
+			continue
+		case key == "":
+			p = path.Join(p, loc)
+		case strings.HasPrefix(loc, key+"/"):
+			p = path.Join(p, loc[len(key)+1:])
+		default:
+			continue
+		}
+		if len(key) > longest {
+			longest = len(key)
+			importPath, pkgName, ok = p, name, true
+		}
+	}
+	if ok {
+		switch ext := path.Ext(importPath); ext {
+		case ".json", ".yaml", ".yml":
+			importPath = strings.TrimSuffix(importPath, ext)
+		}
+		base := path.Base(importPath)
+		if pkgName == "" {
+			pkgName = toPkgName(base)
+		}
+		if pkgName != base {
+			importPath += ":" + pkgName
+		}
+		return importPath, pkgName, true
+	}
+	if u.Host == "" {
+		return "", "", false
+	}
+
+	p := u.Path
+	base := path.Base(p)
+	if !ast.IsValidIdent(base) {
+		base = strings.TrimSuffix(base, ".json")
+		if !ast.IsValidIdent(base) {
+			// Find something more clever to do there. For now just
+			// pick "schema" as the package name.
+			base = "schema"
+		}
+		p += ":" + base
+	}
+	return u.Host + p, base, true
+}
+
+// toPkgName converts the last element of an import path to a package
+// name by replacing the characters that are not allowed in identifiers
+// with underscores.
+func toPkgName(s string) string {
+	s = strings.TrimLeft(s, "_#0123456789")
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, s)
+	if name == "" {
+		return "schema"
+	}
+	return name
+}
+
 // getNextSelector translates a JSON Reference path into a CUE path by consuming
 // the first path elements and returning the corresponding CUE label.
 func (s *state) getNextSelector(v cue.Value, a []string) (l label, tail []string) {
@@ -25,9 +25,12 @@ import (
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/eval"
+	"cuelang.org/go/internal/core/walk"
 	internalvalue "cuelang.org/go/internal/value"
 )
 
@@ -41,10 +44,16 @@ type buildContext struct {
 	expandRefs    bool
 	structural    bool
 	exclusiveBool bool
+	jsonSchema    bool // full JSON Schema is supported, as in OpenAPI 3.1
 	nameFunc      func(inst cue.Value, path cue.Path) string
 	descFunc      func(v cue.Value) string
+	reportDropped func(d DroppedConstraint)
 	fieldFilter   *regexp.Regexp
 
+	// modes holds the representation modes in effect for the schema being
+	// built. They may be overridden by @openapi attributes.
+	modes modes
+
 	schemas *OrderedMap
 
 	// Track external schemas.
@@ -111,6 +120,7 @@ func schemas(g *Generator, inst cue.InstanceOrValue) (schemas *ast.StructLit, er
 		externalRefs: map[string]*externalType{},
 		fieldFilter:  fieldFilter,
 	}
+	c.reportDropped = g.ReportDropped
 	if g.ReferenceFunc != nil {
 		if !isInstance {
 			panic("cannot use ReferenceFunc along with cue.Value")
@@ -147,10 +157,29 @@ func schemas(g *Generator, inst cue.InstanceOrValue) (schemas *ast.StructLit, er
 	case "3.0.0":
 		c.exclusiveBool = true
 	case "3.1.0":
+		c.jsonSchema = true
 	default:
 		return nil, errors.Newf(token.NoPos, "unsupported version %s", g.Version)
 	}
 
+	c.modes = modes{
+		disjunctions: OneOf,
+		defaults:     DefaultsNonEmpty,
+		closed:       ClosedOpen,
+	}
+	for _, opt := range []struct{ key, value string }{
+		{"disjunctions", string(g.Disjunctions)},
+		{"defaults", string(g.Defaults)},
+		{"closed", string(g.ClosedStructs)},
+	} {
+		if opt.value == "" {
+			continue
+		}
+		if err := c.setMode(opt.key, opt.value); err != nil {
+			return nil, errors.Newf(token.NoPos, "openapi: %v", err)
+		}
+	}
+
 	defer func() {
 		switch x := recover().(type) {
 		case nil:
@@ -227,6 +256,70 @@ func (c *buildContext) isInternal(sel cue.Selector) bool {
 		strings.HasSuffix(sel.String(), "_value")
 }
 
+// modes holds the options that select how CUE constructs that have more
+// than one OpenAPI representation are represented.
+type modes struct {
+	disjunctions DisjunctionMode
+	defaults     DefaultMode
+	closed       ClosedMode
+}
+
+// setMode sets the mode for the option with the given key, as used in
+// @openapi attributes, to value.
+func (c *buildContext) setMode(key, value string) error {
+	switch key {
+	case "disjunctions":
+		switch m := DisjunctionMode(value); m {
+		case OneOf, AnyOf:
+			c.modes.disjunctions = m
+			return nil
+		}
+	case "defaults":
+		switch m := DefaultMode(value); m {
+		case DefaultsNonEmpty, DefaultsAll, DefaultsNone:
+			c.modes.defaults = m
+			return nil
+		}
+	case "closed":
+		switch m := ClosedMode(value); m {
+		case ClosedOpen, ClosedAdditionalProperties:
+			c.modes.closed = m
+			return nil
+		case ClosedUnevaluatedProperties:
+			if c.exclusiveBool {
+				return fmt.Errorf("closed=%s requires OpenAPI version 3.1.0", value)
+			}
+			c.modes.closed = m
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown option %q", key)
+	}
+	return fmt.Errorf("invalid value %q for option %s", value, key)
+}
+
+// applyModes applies the overrides of representation modes specified by
+// an @openapi attribute of v. It returns a function that restores the
+// previous modes.
+func (b *builder) applyModes(v cue.Value) (restore func()) {
+	old := b.ctx.modes
+	restore = func() { b.ctx.modes = old }
+
+	a := v.Attribute("openapi")
+	if a.Err() != nil {
+		return restore
+	}
+	for i := 0; i < a.NumArgs(); i++ {
+		switch key, value := a.Arg(i); key {
+		case "disjunctions", "defaults", "closed":
+			if err := b.ctx.setMode(key, value); err != nil {
+				b.failf(v, "openapi: invalid attribute: %v", err)
+			}
+		}
+	}
+	return restore
+}
+
 func (b *builder) failf(v cue.Value, format string, args ...interface{}) {
 	panic(&openapiError{
 		errors.NewMessagef(format, args...),
@@ -237,11 +330,112 @@ func (b *builder) failf(v cue.Value, format string, args ...interface{}) {
 
 func (b *builder) unsupported(v cue.Value) {
 	if b.format == "" {
-		// Not strictly an error, but consider listing it as a warning
-		// in strict mode.
+		b.dropped(v, "no corresponding keyword")
 	}
 }
 
+// dropped reports that the constraint v cannot be represented in the
+// generated schema.
+func (b *builder) dropped(v cue.Value, reason string) {
+	b.drop(v.Pos(), fmt.Sprint(v), reason)
+}
+
+func (b *builder) drop(pos token.Pos, constraint, reason string) {
+	if b.ctx.reportDropped == nil {
+		return
+	}
+	b.ctx.reportDropped(DroppedConstraint{
+		Path:       cue.MakePath(b.ctx.path...),
+		Pos:        pos,
+		Constraint: constraint,
+		Reason:     reason,
+	})
+}
+
+// droppedComprehensions reports the comprehensions of the struct v, which
+// cannot be represented if they depend on other fields.
+func (b *builder) droppedComprehensions(v cue.Value) {
+	_, n := internalvalue.ToInternal(v)
+	for _, c := range n.Conjuncts {
+		s, ok := c.Expr().(*adt.StructLit)
+		if !ok {
+			continue
+		}
+		for _, d := range s.Decls {
+			x, ok := d.(*adt.Comprehension)
+			if !ok {
+				continue
+			}
+			src, ok := x.Source().(*ast.Comprehension)
+			if !ok {
+				continue
+			}
+			var clauses []string
+			for _, c := range src.Clauses {
+				clauses = append(clauses, clauseString(c))
+			}
+			b.drop(src.Pos(), strings.Join(clauses, " "), "comprehension refers to other fields")
+		}
+	}
+}
+
+func clauseString(c ast.Clause) string {
+	str := func(x ast.Node) string {
+		b, _ := format.Node(x)
+		return string(b)
+	}
+	switch c := c.(type) {
+	case *ast.IfClause:
+		return "if " + str(c.Condition)
+	case *ast.ForClause:
+		if c.Key != nil {
+			return fmt.Sprintf("for %s, %s in %s", c.Key.Name, c.Value.Name, str(c.Source))
+		}
+		return fmt.Sprintf("for %s in %s", c.Value.Name, str(c.Source))
+	case *ast.LetClause:
+		return fmt.Sprintf("let %s = %s", c.Ident.Name, str(c.Expr))
+	}
+	return ""
+}
+
+// isIncomplete reports whether v cannot be evaluated as it depends on values
+// that are not known, such as the values of other fields.
+func isIncomplete(v cue.Value) bool {
+	_, n := internalvalue.ToInternal(v)
+	b, ok := n.BaseValue.(*adt.Bottom)
+	return ok && b.IsIncomplete()
+}
+
+// refersToFields reports whether v cannot be evaluated because it refers to
+// fields of which the value is not known.
+func refersToFields(v cue.Value) bool {
+	if !isIncomplete(v.Eval()) {
+		return false
+	}
+	found := false
+	w := &walk.Visitor{}
+	w.Before = func(n adt.Node) bool {
+		switch x := n.(type) {
+		case *adt.FieldReference, *adt.LabelReference, *adt.DynamicReference:
+			found = true
+		case *adt.Vertex:
+			for _, c := range x.Conjuncts {
+				w.Elem(c.Elem())
+			}
+		}
+		return !found
+	}
+	_, n := internalvalue.ToInternal(v)
+	w.Elem(n)
+	return found
+}
+
+// isStruct reports whether v is a struct, even if it cannot be evaluated.
+func isStruct(v cue.Value) bool {
+	_, n := internalvalue.ToInternal(v)
+	return v.IncompleteKind()&cue.StructKind != 0 || len(n.Structs) > 0
+}
+
 func (b *builder) checkArgs(a []cue.Value, n int) {
 	if len(a)-1 != n {
 		b.failf(a[0], "%v must be used with %d arguments", a[0], len(a)-1)
@@ -252,6 +446,7 @@ func (b *builder) schema(core *builder, name cue.Selector, v cue.Value) *ast.Str
 	oldPath := b.ctx.path
 	b.ctx.path = append(b.ctx.path, name)
 	defer func() { b.ctx.path = oldPath }()
+	defer b.applyModes(v)()
 
 	var c *builder
 	if core == nil && b.ctx.structural {
@@ -302,6 +497,7 @@ func (b *builder) fillSchema(v cue.Value) *ast.StructLit {
 			b.getDoc(v)
 		}
 	}
+	b.metadata(v)
 
 	schema := b.finish()
 	s := (*ast.StructLit)(schema)
@@ -338,6 +534,7 @@ func sortSchema(s *ast.StructLit) {
 }
 
 var fieldOrder = map[string]int{
+	"title":            32,
 	"description":      31,
 	"type":             30,
 	"format":           29,
@@ -404,11 +601,20 @@ func (b *builder) value(v cue.Value, f typeFunc) (isRef bool) {
 		// TODO: perhaps find optimal representation. For now we assume the
 		// representation as is already optimized for human consumption.
 		if values.IncompleteKind()&cue.StructKind != cue.StructKind && !isRef {
-			values = values.Eval()
+			// Constraints that depend on other fields cannot be evaluated
+			// as a whole. In that case, the conjuncts are handled
+			// individually.
+			if e := values.Eval(); !isIncomplete(e) {
+				values = e
+			}
 		}
 
 		conjuncts := appendSplit(nil, cue.AndOp, values)
 		for i, v := range conjuncts {
+			if !isStruct(v) && refersToFields(v) {
+				b.dropped(v, "refers to other fields")
+				continue
+			}
 			switch {
 			case isConcrete(v):
 				b.dispatch(f, v)
@@ -455,8 +661,16 @@ func (b *builder) value(v cue.Value, f typeFunc) (isRef bool) {
 				case 1:
 					v = a[0]
 					if err := v.Err(); err != nil {
-						b.failf(v, "openapi: %v", err)
-						return
+						if !isIncomplete(v) || !isStruct(v) {
+							b.failf(v, "openapi: %v", err)
+							return
+						}
+						// Generate the schema for the fields that can be
+						// evaluated.
+						b.droppedComprehensions(v)
+						b.setType("object", "")
+						b.object(v)
+						break
 					}
 					b.dispatch(f, v)
 				default:
@@ -466,11 +680,12 @@ func (b *builder) value(v cue.Value, f typeFunc) (isRef bool) {
 		}
 	}
 
-	if v, ok := v.Default(); ok && v.IsConcrete() && !disallowDefault {
-		// TODO: should we show the empty list default? This would be correct
-		// but perhaps a bit too pedantic and noisy.
+	if v, ok := v.Default(); ok && v.IsConcrete() && !disallowDefault &&
+		b.ctx.modes.defaults != DefaultsNone {
+		// Showing the empty list default is correct, but perhaps a bit too
+		// pedantic and noisy, so we only do so if explicitly requested.
 		switch {
-		case v.Kind() == cue.ListKind:
+		case v.Kind() == cue.ListKind && b.ctx.modes.defaults != DefaultsAll:
 			iter, _ := v.List()
 			if !iter.Next() {
 				// Don't show default for empty list.
@@ -592,6 +807,14 @@ func (b *builder) disjunction(a []cue.Value, f typeFunc) {
 		}
 	}
 
+	if b.ctx.modes.disjunctions == AnyOf {
+		for _, t := range schemas {
+			anyOf = append(anyOf, t)
+		}
+		b.set("anyOf", ast.NewList(anyOf...))
+		return
+	}
+
 	for i, v := range disjuncts {
 		// In OpenAPI schema are open by default. To ensure forward compatibility,
 		// we do not represent closed structs with additionalProperties: false
@@ -739,7 +962,7 @@ func (b *builder) object(v cue.Value) {
 			return
 
 		default:
-			b.unsupported(a[0])
+			b.unsupported(v)
 			return
 		}
 
@@ -801,12 +1024,69 @@ func (b *builder) object(v cue.Value) {
 		if len(schema.Elts) > 0 {
 			b.setSingle("additionalProperties", schema, true) // Not allowed in structural.
 		}
+	} else if !ok && !v.Allows(cue.AnyString) {
+		switch b.ctx.modes.closed {
+		case ClosedAdditionalProperties:
+			b.setSingle("additionalProperties", ast.NewBool(false), true)
+		case ClosedUnevaluatedProperties:
+			b.setSingle("unevaluatedProperties", ast.NewBool(false), true)
+		}
 	}
 
+	b.patternProperties(v)
+
 	// TODO: maxProperties, minProperties: can be done once we allow cap to
 	// unify with structs.
 }
 
+// patternProperties adds a schema for each pattern constraint of v that
+// matches labels with a regular expression. Other pattern constraints,
+// except those matching all labels, are reported as dropped.
+func (b *builder) patternProperties(v cue.Value) {
+	r, n := internalvalue.ToInternal(v)
+	ctx := eval.NewContext(r, n)
+	properties := &OrderedMap{}
+	seen := map[string]bool{}
+	for _, s := range n.Structs {
+		for _, d := range s.Decls {
+			x, ok := d.(*adt.BulkOptionalField)
+			if !ok {
+				continue
+			}
+			pattern := ctx.Str(x.Filter)
+			if seen[pattern] {
+				continue
+			}
+			seen[pattern] = true
+
+			switch f, _ := ctx.Evaluate(s.Env, x.Filter); f := f.(type) {
+			case *adt.BasicType, *adt.Top:
+				// Handled by additionalProperties.
+				continue
+
+			case *adt.BoundValue:
+				re, ok := f.Value.(*adt.String)
+				if !ok || f.Op != adt.MatchOp {
+					break
+				}
+				if !b.ctx.jsonSchema {
+					b.drop(x.Src.Pos(), "["+pattern+"]", "patternProperties requires OpenAPI 3.1.0")
+					continue
+				}
+				c := &adt.Vertex{}
+				c.AddConjunct(adt.MakeRootConjunct(s.Env, x.Value))
+				c.Finalize(ctx)
+				properties.Set(re.Str, b.schema(nil, cue.AnyString, internalvalue.Make(ctx, c)))
+				continue
+			}
+			b.drop(x.Src.Pos(), "["+pattern+"]", "pattern constraint on labels has no corresponding keyword")
+		}
+	}
+	if properties.len() > 0 {
+		b.setSingle("patternProperties", (*ast.StructLit)(properties), true)
+	}
+}
+
 // List constraints:
 //
 // Max and min items.
@@ -851,7 +1131,7 @@ func (b *builder) array(v cue.Value) {
 			return
 
 		default:
-			b.unsupported(a[0])
+			b.unsupported(v)
 			return
 		}
 
@@ -981,7 +1261,7 @@ func (b *builder) number(v cue.Value) {
 			b.setFilter("Schema", "multipleOf", b.int(a[1]))
 
 		default:
-			b.unsupported(a[0])
+			b.unsupported(v)
 			return
 		}
 
@@ -1066,7 +1346,7 @@ func (b *builder) string(v cue.Value) {
 			return
 
 		default:
-			b.unsupported(a[0])
+			b.unsupported(v)
 			return
 		}
 
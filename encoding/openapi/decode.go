@@ -41,27 +41,40 @@ func Extract(data cue.InstanceOrValue, c *Config) (*ast.File, error) {
 		}
 	}
 
-	js, err := jsonschema.Extract(data, &jsonschema.Config{
+	v := data.Value()
+
+	schemas, err := c.selectSchemas(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkDefinitionNames(schemas); err != nil {
+		return nil, err
+	}
+
+	js, err := jsonschema.Extract(schemas, &jsonschema.Config{
 		Root: oapiSchemas,
-		Map:  openAPIMapping,
+		Map:  c.openAPIMapping,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	v := data.Value()
-
-	doc, _ := v.Lookup("info", "title").String() // Required
-	if s, _ := v.Lookup("info", "description").String(); s != "" {
-		doc += "\n\n" + s
+	var cg *ast.CommentGroup
+	if !c.ComponentsOnly {
+		doc, _ := v.Lookup("info", "title").String() // Required
+		if s, _ := v.Lookup("info", "description").String(); s != "" {
+			doc += "\n\n" + s
+		}
+		cg = internal.NewComment(true, doc)
 	}
-	cg := internal.NewComment(true, doc)
 
 	if c.PkgName != "" {
 		p := &ast.Package{Name: ast.NewIdent(c.PkgName)}
-		p.AddComment(cg)
+		if cg != nil {
+			p.AddComment(cg)
+		}
 		add(p)
-	} else {
+	} else if cg != nil {
 		add(cg)
 	}
 
@@ -84,7 +97,7 @@ func Extract(data cue.InstanceOrValue, c *Config) (*ast.File, error) {
 	// 	add(internal.NewAttr("openapi", "version="+ version))
 	// }
 
-	if info := v.Lookup("info"); info.Exists() {
+	if info := v.Lookup("info"); info.Exists() && !c.ComponentsOnly {
 		decls := []interface{}{}
 		if st, ok := info.Syntax().(*ast.StructLit); ok {
 			// Remove title.
@@ -125,13 +138,13 @@ const oapiSchemas = "#/components/schemas/"
 // TODO: find something more principled.
 const rootDefs = "#SchemaMap"
 
-func openAPIMapping(pos token.Pos, a []string) ([]ast.Label, error) {
+func (c *Config) openAPIMapping(pos token.Pos, a []string) ([]ast.Label, error) {
 	if len(a) != 3 || a[0] != "components" || a[1] != "schemas" {
 		return nil, errors.Newf(pos,
 			`openapi: reference must be of the form %q; found "#/%s"`,
 			oapiSchemas, strings.Join(a, "/"))
 	}
-	name := a[2]
+	name := c.definitionName(a[2])
 	if ast.IsValidIdent(name) &&
 		name != rootDefs[1:] &&
 		!internal.IsDefOrHidden(name) {
@@ -139,3 +152,35 @@ func openAPIMapping(pos token.Pos, a []string) ([]ast.Label, error) {
 	}
 	return []ast.Label{ast.NewIdent(rootDefs), ast.NewString(name)}, nil
 }
+
+// definitionName returns the name of the definition for the schema with
+// the given name in #/components/schemas.
+func (c *Config) definitionName(schema string) string {
+	if c.DefinitionName != nil {
+		if name := c.DefinitionName(schema); name != "" {
+			return name
+		}
+	}
+	return schema
+}
+
+// checkDefinitionNames reports an error if two of the schemas in the
+// OpenAPI document v are mapped to the same definition.
+func (c *Config) checkDefinitionNames(v cue.Value) error {
+	if c.DefinitionName == nil {
+		return nil
+	}
+	iter, _ := v.LookupPath(cue.ParsePath("components.schemas")).Fields()
+	names := map[string]string{}
+	for iter.Next() {
+		schema := iter.Label()
+		name := c.definitionName(schema)
+		if other, ok := names[name]; ok {
+			return errors.Newf(iter.Value().Pos(),
+				"openapi: schemas %q and %q both map to definition name %q",
+				other, schema, name)
+		}
+		names[name] = schema
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -28,6 +29,7 @@ import (
 	"golang.org/x/tools/txtar"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/encoding/json"
@@ -124,3 +126,115 @@ func TestDecode(t *testing.T) {
 	})
 	qt.Assert(t, qt.IsNil(err))
 }
+
+func TestExtractSelect(t *testing.T) {
+	const doc = `
+openapi: "3.0.0"
+info: {title: "Pets", version: "v1"}
+paths: {
+	"/pets": get: {
+		operationId: "listPets"
+		tags: ["pets"]
+		responses: "200": $ref: "#/components/responses/Pets"
+	}
+	"/users": get: {
+		operationId: "listUsers"
+		responses: "200": content: "application/json": schema: $ref: "#/components/schemas/com.example.User"
+	}
+}
+components: {
+	responses: Pets: content: "application/json": schema: {
+		type: "array"
+		items: $ref: "#/components/schemas/com.example.Pet"
+	}
+	schemas: {
+		"com.example.Pet": {
+			type: "object"
+			properties: owner: $ref: "#/components/schemas/com.example.User"
+		}
+		"com.example.User": type:   "string"
+		"com.example.Unused": type: "integer"
+		"org.example.User": type:   "number"
+	}
+}
+`
+	shortName := func(s string) string { return s[strings.LastIndex(s, ".")+1:] }
+	testCases := []struct {
+		name string
+		cfg  *openapi.Config
+		out  string
+		err  string
+	}{{
+		name: "Schemas",
+		cfg: &openapi.Config{
+			SelectSchemas:  []string{"com.example.P*"},
+			ComponentsOnly: true,
+		},
+		out: `#SchemaMap: "com.example.Pet": {
+	owner?: #SchemaMap["com.example.User"]
+	...
+}
+#SchemaMap: {
+	"com.example.User": string
+}`,
+	}, {
+		name: "OperationsByTag",
+		cfg: &openapi.Config{
+			SelectOperations: []string{"pets"},
+			ComponentsOnly:   true,
+			DefinitionName:   shortName,
+		},
+		out: `#Pet: {
+	owner?: #User
+	...
+}
+#User: string`,
+	}, {
+		name: "OperationsById",
+		cfg: &openapi.Config{
+			PkgName:          "pets",
+			SelectOperations: []string{"list*"},
+			DefinitionName:   shortName,
+		},
+		out: `// Pets
+package pets
+
+info: {
+	title:   *"Pets" | string
+	version: *"v1" | string
+}
+
+#Pet: {
+	owner?: #User
+	...
+}
+#User: string`,
+	}, {
+		name: "NoMatch",
+		cfg:  &openapi.Config{SelectSchemas: []string{"Foo"}},
+		err:  "openapi: no schemas match the selected schemas or operations",
+	}, {
+		name: "BadPattern",
+		cfg:  &openapi.Config{SelectOperations: []string{"["}},
+		err:  `openapi: invalid pattern "[": syntax error in pattern`,
+	}, {
+		name: "NameClash",
+		cfg:  &openapi.Config{DefinitionName: shortName},
+		err:  `openapi: schemas "com.example.User" and "org.example.User" both map to definition name "User"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := cuecontext.New().CompileString(doc)
+			qt.Assert(t, qt.IsNil(v.Err()))
+			f, err := openapi.Extract(v, tc.cfg)
+			if tc.err != "" {
+				qt.Assert(t, qt.ErrorMatches(err, regexp.QuoteMeta(tc.err)))
+				return
+			}
+			qt.Assert(t, qt.IsNil(err))
+			b, err := format.Node(f, format.Simplify())
+			qt.Assert(t, qt.IsNil(err))
+			qt.Assert(t, qt.Equals(strings.TrimSpace(string(b)), tc.out))
+		})
+	}
+}
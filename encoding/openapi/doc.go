@@ -18,4 +18,29 @@
 // It currently handles OpenAPI Schema components only.
 //
 // See https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#schemaObject.
+//
+// # Metadata
+//
+// Generate adds metadata specified in @openapi attributes of fields and
+// definitions to the generated schemas:
+//
+//	title=<string>        sets the title of the schema
+//	description=<string>  sets the description, overriding doc comments
+//	example=<JSON>        sets an example value
+//	deprecated            marks the schema as deprecated
+//	readOnly              marks the schema as read only
+//	writeOnly             marks the schema as write only
+//	x-<name>=<JSON>       sets the specification extension x-<name>
+//	tag=<name>            adds a tag to the document; top-level definitions only
+//
+// Values that are not valid JSON are used as strings. Only one of readOnly
+// and writeOnly may be set. The document as a whole is configured with a
+// declaration attribute at the top level of the package, which accepts
+// summary=<string> to set the summary of the info object, as well as tag
+// and x-<name> entries:
+//
+//	@openapi(summary="Pet store",tag=pets,x-logo={"url": "logo.png"})
+//
+// Fields marked with a @deprecated attribute, which cue vet uses to report
+// uses of deprecated fields, are also marked as deprecated.
 package openapi
@@ -0,0 +1,213 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"path"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// operationMethods lists the fields of an OpenAPI path item that hold
+// operations.
+var operationMethods = []string{
+	"get", "put", "post", "delete", "options", "head", "patch", "trace",
+}
+
+// schemaSelector computes the set of component schemas selected by the
+// SelectSchemas and SelectOperations options of a Config.
+type schemaSelector struct {
+	doc      cue.Value
+	schemas  cue.Value
+	selected map[string]bool
+	visited  map[string]bool // non-schema components already traversed
+	errs     errors.Error
+}
+
+// selectSchemas returns the OpenAPI document v restricted to the schemas
+// in #/components/schemas that are selected by c, along with the schemas
+// they refer to. It returns v unchanged if c does not restrict the schemas.
+func (c *Config) selectSchemas(v cue.Value) (cue.Value, error) {
+	if len(c.SelectSchemas) == 0 && len(c.SelectOperations) == 0 {
+		return v, nil
+	}
+	for _, patterns := range [][]string{c.SelectSchemas, c.SelectOperations} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return v, errors.Newf(token.NoPos, "openapi: invalid pattern %q: %v", p, err)
+			}
+		}
+	}
+
+	s := &schemaSelector{
+		doc:      v,
+		schemas:  v.LookupPath(cue.ParsePath("components.schemas")),
+		selected: map[string]bool{},
+		visited:  map[string]bool{},
+	}
+
+	if len(c.SelectSchemas) > 0 {
+		iter, _ := s.schemas.Fields()
+		for iter.Next() {
+			if name := iter.Label(); matchAny(c.SelectSchemas, name) {
+				s.addSchema(name)
+			}
+		}
+	}
+
+	if len(c.SelectOperations) > 0 {
+		iter, _ := v.LookupPath(cue.MakePath(cue.Str("paths"))).Fields()
+		for iter.Next() {
+			item := iter.Value()
+			for _, m := range operationMethods {
+				op := item.LookupPath(cue.MakePath(cue.Str(m)))
+				if !op.Exists() || !matchOperation(c.SelectOperations, op) {
+					continue
+				}
+				s.addRefs(op)
+				s.addRefs(item.LookupPath(cue.MakePath(cue.Str("parameters"))))
+			}
+		}
+	}
+
+	if s.errs != nil {
+		return v, s.errs
+	}
+	if len(s.selected) == 0 {
+		return v, errors.Newf(token.NoPos, "openapi: no schemas match the selected schemas or operations")
+	}
+	return s.filter(), nil
+}
+
+// filter returns a copy of the document in which #/components/schemas
+// only holds the selected schemas.
+func (s *schemaSelector) filter() cue.Value {
+	w := s.doc.Context().CompileString("{}")
+	iter, _ := s.doc.Fields()
+	for iter.Next() {
+		if iter.Label() != "components" {
+			w = w.FillPath(cue.MakePath(cue.Str(iter.Label())), iter.Value())
+			continue
+		}
+		components, _ := iter.Value().Fields()
+		for components.Next() {
+			kind := cue.Str(components.Label())
+			if components.Label() != "schemas" {
+				w = w.FillPath(cue.MakePath(cue.Str("components"), kind), components.Value())
+				continue
+			}
+			schemas, _ := components.Value().Fields()
+			for schemas.Next() {
+				if s.selected[schemas.Label()] {
+					p := cue.MakePath(cue.Str("components"), kind, cue.Str(schemas.Label()))
+					w = w.FillPath(p, schemas.Value())
+				}
+			}
+		}
+	}
+	return w
+}
+
+func (s *schemaSelector) addSchema(name string) {
+	if s.selected[name] {
+		return
+	}
+	s.selected[name] = true
+	s.addRefs(s.schemas.LookupPath(cue.MakePath(cue.Str(name))))
+}
+
+// addRefs selects the schemas referred to by v, following references to
+// other components, such as parameters and responses, along the way.
+func (s *schemaSelector) addRefs(v cue.Value) {
+	switch v.Kind() {
+	case cue.StructKind:
+		iter, _ := v.Fields()
+		for iter.Next() {
+			if iter.Label() == "$ref" {
+				if ref, err := iter.Value().String(); err == nil {
+					s.addRef(iter.Value().Pos(), ref)
+				}
+				continue
+			}
+			s.addRefs(iter.Value())
+		}
+	case cue.ListKind:
+		iter, _ := v.List()
+		for iter.Next() {
+			s.addRefs(iter.Value())
+		}
+	}
+}
+
+func (s *schemaSelector) addRef(pos token.Pos, ref string) {
+	const components = "#/components/"
+	if !strings.HasPrefix(ref, components) {
+		return
+	}
+	a := strings.Split(ref[len(components):], "/")
+	for i, x := range a {
+		a[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(x)
+	}
+	if len(a) == 2 && a[0] == "schemas" {
+		if !s.schemas.LookupPath(cue.MakePath(cue.Str(a[1]))).Exists() {
+			s.errs = errors.Append(s.errs, errors.Newf(pos,
+				"openapi: reference to undefined schema %q", ref))
+			return
+		}
+		s.addSchema(a[1])
+		return
+	}
+	if s.visited[ref] {
+		return
+	}
+	s.visited[ref] = true
+	sels := []cue.Selector{cue.Str("components")}
+	for _, x := range a {
+		sels = append(sels, cue.Str(x))
+	}
+	s.addRefs(s.doc.LookupPath(cue.MakePath(sels...)))
+}
+
+// matchOperation reports whether the operationId or one of the tags of the
+// operation op matches any of the given patterns.
+func matchOperation(patterns []string, op cue.Value) bool {
+	if id, err := op.LookupPath(cue.MakePath(cue.Str("operationId"))).String(); err == nil {
+		if matchAny(patterns, id) {
+			return true
+		}
+	}
+	iter, err := op.LookupPath(cue.MakePath(cue.Str("tags"))).List()
+	if err != nil {
+		return false
+	}
+	for iter.Next() {
+		if tag, err := iter.Value().String(); err == nil && matchAny(patterns, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	cuejson "cuelang.org/go/encoding/json"
+)
+
+// metadata adds the schema metadata specified by an @openapi attribute of
+// v, such as
+//
+//	@openapi(title="Pet",example={"name": "Rex"},readOnly,x-internal=true)
+func (b *builder) metadata(v cue.Value) {
+	a := v.Attribute("openapi")
+	if a.Err() != nil {
+		return
+	}
+	var readOnly, writeOnly bool
+	for i := 0; i < a.NumArgs(); i++ {
+		key, value := a.Arg(i)
+		switch {
+		case key == "title", key == "description":
+			b.setSingle(key, ast.NewString(value), true)
+		case key == "example":
+			b.setSingle(key, metadataValue(value), true)
+		case key == "deprecated":
+			b.setSingle(key, ast.NewBool(true), true)
+		case key == "readOnly":
+			readOnly = true
+			b.setSingle(key, ast.NewBool(true), true)
+		case key == "writeOnly":
+			writeOnly = true
+			b.setSingle(key, ast.NewBool(true), true)
+		case strings.HasPrefix(key, "x-"):
+			b.setSingle(key, metadataValue(value), true)
+		case key == "tag":
+			// Tags of top-level definitions are added to the document.
+			if len(b.ctx.path) != 1 {
+				b.failf(v, "openapi: tag may only be specified for top-level definitions")
+			}
+		case key == "summary":
+			b.failf(v, "openapi: summary may only be specified for the document")
+		}
+	}
+	if readOnly && writeOnly {
+		b.failf(v, "openapi: only one of readOnly and writeOnly may be set")
+	}
+}
+
+// metadataValue interprets the value of an attribute argument as JSON,
+// or as a string if it is not valid JSON.
+func metadataValue(s string) ast.Expr {
+	if x, err := cuejson.Extract("attribute", []byte(s)); err == nil {
+		return x
+	}
+	return ast.NewString(s)
+}
+
+// documentMetadata holds the metadata for the OpenAPI document as a whole.
+type documentMetadata struct {
+	summary    string
+	tags       []string
+	extensions *OrderedMap
+}
+
+// addAttribute adds the metadata of a declaration attribute at the top
+// level of the instance, or of an attribute of a top-level definition.
+// For definitions, only tags are allowed.
+func (d *documentMetadata) addAttribute(a cue.Attribute, isDef bool) {
+	for i := 0; i < a.NumArgs(); i++ {
+		key, value := a.Arg(i)
+		switch {
+		case key == "tag":
+			d.addTag(value)
+		case isDef:
+		case key == "summary":
+			d.summary = value
+		case strings.HasPrefix(key, "x-"):
+			if d.extensions == nil {
+				d.extensions = &OrderedMap{}
+			}
+			d.extensions.Set(key, metadataValue(value))
+		}
+	}
+}
+
+func (d *documentMetadata) addTag(name string) {
+	for _, t := range d.tags {
+		if t == name {
+			return
+		}
+	}
+	d.tags = append(d.tags, name)
+}
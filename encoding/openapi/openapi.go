@@ -81,10 +81,136 @@ type Config struct {
 	// OpenAPI Schema. It is an error for an CUE value to refer to itself
 	// if this option is used.
 	ExpandReferences bool
+
+	// Disjunctions specifies how disjunctions are represented in the
+	// generated schema. The default is OneOf.
+	//
+	// It can be overridden for a definition or field with the attribute
+	// @openapi(disjunctions=<mode>).
+	Disjunctions DisjunctionMode
+
+	// Defaults specifies which default values are represented by the
+	// default keyword in the generated schema. The default is
+	// DefaultsNonEmpty.
+	//
+	// It can be overridden for a definition or field with the attribute
+	// @openapi(defaults=<mode>).
+	Defaults DefaultMode
+
+	// ClosedStructs specifies how closed structs, such as those defined
+	// by definitions, are represented in the generated schema. The default
+	// is ClosedOpen.
+	//
+	// It can be overridden for a definition or field with the attribute
+	// @openapi(closed=<mode>).
+	ClosedStructs ClosedMode
+
+	// SelectSchemas restricts Extract to the schemas in #/components/schemas
+	// of which the name matches one of these patterns, using the syntax of
+	// path.Match, and the schemas they refer to.
+	SelectSchemas []string
+
+	// SelectOperations restricts Extract to the schemas referred to,
+	// directly or indirectly, by operations of which the operationId or one
+	// of the tags matches one of these patterns, using the syntax of
+	// path.Match. If both SelectSchemas and SelectOperations are set, the
+	// schemas selected by either are extracted.
+	SelectOperations []string
+
+	// ComponentsOnly causes Extract to only generate definitions for the
+	// schemas, omitting the metadata in the info section of the document.
+	ComponentsOnly bool
+
+	// DefinitionName allows users to specify the name of the definition
+	// generated by Extract for a schema in #/components/schemas, without
+	// the leading #. Schemas for which it returns a name that is not a
+	// valid identifier are stored in #SchemaMap under that name. It is an
+	// error for two extracted schemas to map to the same name. If it returns
+	// the empty string, the name of the schema is used.
+	DefinitionName func(schema string) string
+
+	// ReportDropped, if non-nil, is called by Generate for each constraint
+	// that cannot be represented in the generated schema and is therefore
+	// omitted from it, such as a validator without a corresponding
+	// keyword or a constraint that refers to other fields.
+	ReportDropped func(d DroppedConstraint)
+}
+
+// A DroppedConstraint describes a constraint that Generate could not
+// represent in the generated schema.
+type DroppedConstraint struct {
+	// Path is the path of the value to which the constraint applies.
+	Path cue.Path
+
+	// Pos is the position of the constraint, if known.
+	Pos token.Pos
+
+	// Constraint is the constraint in CUE syntax.
+	Constraint string
+
+	// Reason explains why the constraint was dropped.
+	Reason string
+}
+
+func (d DroppedConstraint) String() string {
+	return fmt.Sprintf("%v: dropped %s: %s", d.Path, d.Constraint, d.Reason)
 }
 
 type Generator = Config
 
+// A DisjunctionMode specifies how CUE disjunctions are represented in
+// OpenAPI.
+type DisjunctionMode string
+
+const (
+	// OneOf represents disjunctions with oneOf. To ensure that exactly one
+	// of the schemas matches, disjuncts that accept values of other
+	// disjuncts are amended to exclude those values.
+	OneOf DisjunctionMode = "oneOf"
+
+	// AnyOf represents disjunctions with anyOf, without amending the
+	// disjuncts. This is closer to the CUE representation, but may accept
+	// values that are ambiguous in CUE.
+	AnyOf DisjunctionMode = "anyOf"
+)
+
+// A DefaultMode specifies which CUE default values are represented by the
+// OpenAPI default keyword.
+type DefaultMode string
+
+const (
+	// DefaultsNonEmpty includes all concrete default values except for
+	// empty lists.
+	DefaultsNonEmpty DefaultMode = "nonempty"
+
+	// DefaultsAll includes all concrete default values.
+	DefaultsAll DefaultMode = "all"
+
+	// DefaultsNone omits default values altogether.
+	DefaultsNone DefaultMode = "none"
+)
+
+// A ClosedMode specifies how closed CUE structs are represented in
+// OpenAPI.
+type ClosedMode string
+
+const (
+	// ClosedOpen does not restrict the properties of objects generated for
+	// closed structs. As schemas are open by default in OpenAPI, this keeps
+	// the schema forward compatible.
+	ClosedOpen ClosedMode = "open"
+
+	// ClosedAdditionalProperties sets additionalProperties to false for
+	// closed structs. Note that additionalProperties does not take into
+	// account properties defined in sibling schemas, such as those of an
+	// allOf.
+	ClosedAdditionalProperties ClosedMode = "additionalProperties"
+
+	// ClosedUnevaluatedProperties sets unevaluatedProperties to false for
+	// closed structs. It requires OpenAPI version 3.1.0.
+	ClosedUnevaluatedProperties ClosedMode = "unevaluatedProperties"
+)
+
 // Gen generates the set OpenAPI schema for all top-level types of the
 // given instance.
 func Gen(inst cue.InstanceOrValue, c *Config) ([]byte, error) {
@@ -147,8 +273,16 @@ func (c *Config) compose(inst cue.InstanceOrValue, schemas *ast.StructLit) (x *a
 	var title, version string
 	var info *ast.StructLit
 
+	var meta documentMetadata
+	for _, a := range val.Attributes(cue.DeclAttr) {
+		if a.Name() == "openapi" {
+			meta.addAttribute(a, false)
+		}
+	}
+
 	for i, _ := val.Fields(cue.Definitions(true)); i.Next(); {
 		if i.IsDefinition() {
+			meta.addAttribute(i.Value().Attribute("openapi"), true)
 			continue
 		}
 		label := i.Label()
@@ -222,12 +356,29 @@ func (c *Config) compose(inst cue.InstanceOrValue, schemas *ast.StructLit) (x *a
 		}
 	}
 
-	return ast.NewStruct(
-		"openapi", ast.NewString(c.Version),
-		"info", info,
-		"paths", ast.NewStruct(),
-		"components", ast.NewStruct("schemas", schemas),
-	), errs
+	if meta.summary != "" && info != nil {
+		// Copy info, as it may have been supplied by the user.
+		m := &OrderedMap{Elts: append([]ast.Decl(nil), info.Elts...)}
+		m.Set("summary", ast.NewString(meta.summary))
+		info = (*ast.StructLit)(m)
+	}
+
+	top := &OrderedMap{}
+	top.Set("openapi", ast.NewString(c.Version))
+	top.Set("info", info)
+	if len(meta.tags) > 0 {
+		var tags []ast.Expr
+		for _, t := range meta.tags {
+			tags = append(tags, ast.NewStruct("name", ast.NewString(t)))
+		}
+		top.Set("tags", ast.NewList(tags...))
+	}
+	top.Set("paths", ast.NewStruct())
+	top.Set("components", ast.NewStruct("schemas", schemas))
+	if meta.extensions != nil {
+		top.Elts = append(top.Elts, meta.extensions.Elts...)
+	}
+	return (*ast.StructLit)(top), errs
 }
 
 // Schemas extracts component/schemas from the CUE top-level types.
@@ -242,11 +393,7 @@ func (g *Generator) Schemas(inst cue.InstanceOrValue) (*OrderedMap, error) {
 var defaultConfig = &Config{}
 
 // TODO
-// The conversion interprets @openapi(<entry> {, <entry>}) attributes as follows:
+// Also interpret the following @openapi attribute entries:
 //
-//      readOnly        sets the readOnly flag for a property in the schema
-//                      only one of readOnly and writeOnly may be set.
-//      writeOnly       sets the writeOnly flag for a property in the schema
-//                      only one of readOnly and writeOnly may be set.
 //      discriminator   explicitly sets a field as the discriminator field
 //
@@ -224,6 +224,44 @@ func TestParseDefinitions(t *testing.T) {
 		in:     "omitvalue.cue",
 		out:    "omitvalue.json",
 		config: defaultConfig,
+	}, {
+		in:     "fidelity.cue",
+		out:    "fidelity.json",
+		config: defaultConfig,
+	}, {
+		in:  "fidelity.cue",
+		out: "fidelity-options.json",
+		config: &openapi.Config{
+			Info:          info,
+			Version:       "3.1.0",
+			Disjunctions:  openapi.AnyOf,
+			Defaults:      openapi.DefaultsAll,
+			ClosedStructs: openapi.ClosedUnevaluatedProperties,
+		},
+	}, {
+		in:     "fidelity.cue",
+		out:    "fidelity-additional.json",
+		config: &openapi.Config{Info: info, ClosedStructs: openapi.ClosedAdditionalProperties},
+	}, {
+		in:     "fidelity.cue",
+		config: &openapi.Config{Info: info, ClosedStructs: openapi.ClosedUnevaluatedProperties},
+		err:    "requires OpenAPI version 3.1.0",
+	}, {
+		in:     "fidelity.cue",
+		config: &openapi.Config{Info: info, Defaults: "some"},
+		err:    "invalid value",
+	}, {
+		in:     "metadata.cue",
+		out:    "metadata.json",
+		config: &openapi.Config{Version: "3.1.0"},
+	}, {
+		in:     "dropped.cue",
+		out:    "dropped.json",
+		config: defaultConfig,
+	}, {
+		in:     "dropped.cue",
+		out:    "dropped-v3.1.0.json",
+		config: &openapi.Config{Info: info, Version: "3.1.0"},
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.out+tc.variant, func(t *testing.T) {
@@ -308,6 +346,80 @@ func walk(om *openapi.OrderedMap) {
 	}
 }
 
+func TestReportDropped(t *testing.T) {
+	inst := load.Instances([]string{"dropped.cue"}, &load.Config{
+		Dir: "./testdata",
+	})[0]
+	v := cuecontext.New().BuildInstance(inst)
+	if err := v.Err(); err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+
+	testCases := []struct {
+		version string
+		want    []string
+	}{{
+		version: "3.0.0",
+		want: []string{
+			`#Range: dropped if min > 3: comprehension refers to other fields`,
+			`#Range.max: dropped >=min: refers to other fields`,
+			`#Range.name: dropped strings.ContainsAny("x"): no corresponding keyword`,
+			`#Labels: dropped [=~"^x-"]: patternProperties requires OpenAPI 3.1.0`,
+			`#Labels: dropped [!~"^b"]: pattern constraint on labels has no corresponding keyword`,
+		},
+	}, {
+		version: "3.1.0",
+		want: []string{
+			`#Range: dropped if min > 3: comprehension refers to other fields`,
+			`#Range.max: dropped >=min: refers to other fields`,
+			`#Range.name: dropped strings.ContainsAny("x"): no corresponding keyword`,
+			`#Labels: dropped [!~"^b"]: pattern constraint on labels has no corresponding keyword`,
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			var got []string
+			_, err := openapi.Gen(v, &openapi.Config{
+				Version: tc.version,
+				ReportDropped: func(d openapi.DroppedConstraint) {
+					got = append(got, d.Path.String()+": dropped "+d.Constraint+": "+d.Reason)
+				},
+			})
+			if err != nil {
+				t.Fatal(errors.Details(err, nil))
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("dropped constraints differ (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestMetadataErrors(t *testing.T) {
+	testCases := []struct {
+		in  string
+		err string
+	}{{
+		in:  `#A: {a: string @openapi(readOnly, writeOnly)}`,
+		err: "only one of readOnly and writeOnly may be set",
+	}, {
+		in:  `#A: {a: string @openapi(summary=foo)}`,
+		err: "summary may only be specified for the document",
+	}, {
+		in:  `#A: {a: string @openapi(tag=foo)}`,
+		err: "tag may only be specified for top-level definitions",
+	}}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			v := cuecontext.New().CompileString(tc.in)
+			_, err := openapi.Gen(v, nil)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("got error %v; want %q", err, tc.err)
+			}
+		})
+	}
+}
+
 // TODO: move OpenAPI testing to txtar and allow errors.
 func TestIssue1234(t *testing.T) {
 	var r cue.Runtime
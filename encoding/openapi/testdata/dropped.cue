@@ -0,0 +1,20 @@
+// Constraints without a corresponding keyword.
+
+import "strings"
+
+$version: "v1"
+
+#Range: {
+	min: int
+	max: int & >=min
+	name: strings.ContainsAny("x")
+	if min > 3 {
+		extra: string
+	}
+}
+
+#Labels: {
+	[=~"^x-"]: string
+	[!~"^b"]:  int
+	[string]:  int | string
+}
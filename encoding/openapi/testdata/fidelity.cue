@@ -0,0 +1,30 @@
+// Representation options.
+
+$version: "v1"
+
+#Shape: #Circle | #Rect
+
+#Circle: {
+	kind:   "circle"
+	radius: number
+}
+
+#Rect: {
+	kind:   "rect"
+	width:  number
+	height: number
+}
+
+#Overlap: {a: int} | {a: int, b: int}
+
+#Settings: {
+	tags: *[] | [...string]
+	mode: *"fast" | "slow"
+	labels: [string]: string
+}
+
+// Legacy always uses the default representations.
+#Legacy: {
+	tags: *[] | [...string]
+	item: {a: int} | {a: int, b: int}
+} @openapi(disjunctions=oneOf, defaults=nonempty, closed=open)
@@ -0,0 +1,26 @@
+// Pet store.
+
+@openapi(summary="Pets and their owners", x-logo={"url": "https://example.com/logo.png"})
+
+$version: "v1"
+
+// A pet.
+#Pet: {
+	id: int @openapi(readOnly)
+
+	// The name of the pet.
+	name: string @openapi(title=Name, example="Rex")
+
+	password?: string @openapi(writeOnly, x-sensitive=true)
+
+	owner?: #Owner @openapi(description="The owner of the pet, if any.")
+
+	legacyId?: string @openapi(deprecated)
+
+	legacyName?: string @deprecated(replacement=name)
+} @openapi(tag=pets, x-internal=true)
+
+#Owner: {
+	name: string
+	pets: [...#Pet]
+} @openapi(title="Pet owner", tag=owners, tag=pets, example={"name": "Jane", "pets": []})
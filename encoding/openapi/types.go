@@ -76,7 +76,11 @@ func extractFormat(v cue.Value) string {
 }
 
 func getDeprecated(v cue.Value) bool {
-	// only looking at protobuf attribute for now.
+	// A field marked with a @deprecated attribute, as reported by cue vet.
+	if a := v.Attribute("deprecated"); a.Err() == nil {
+		return true
+	}
+	// Otherwise, only looking at protobuf attribute for now.
 	a := v.Attribute("protobuf")
 	r, _ := a.Flag(1, "deprecated")
 	return r
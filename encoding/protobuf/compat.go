@@ -0,0 +1,303 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// maxFieldNumber is the largest valid protobuf field number, which is used
+// for reserved ranges ending in max.
+const maxFieldNumber = 1<<29 - 1
+
+// CheckCompatibility reports changes from old to new that break wire
+// compatibility of the messages and enums they define. Both values are
+// expected to be CUE as generated from .proto files, possibly edited, in
+// which fields are annotated with @protobuf attributes holding their number
+// and type, and in which messages record reserved numbers and names with
+// @protobuf(reserved,...) declaration attributes.
+//
+// The following changes are reported:
+//   - changing the number of a field or enum value;
+//   - changing the type of a field to one with a different encoding;
+//   - changing a field from singular to repeated or vice versa;
+//   - removing a field without reserving its number;
+//   - using a reserved number or name for a new field.
+//
+// Fields are identified by their protobuf name, which is the name option of
+// the attribute or the CUE label otherwise. Messages and enums that are
+// added or removed are not reported.
+func CheckCompatibility(old, new cue.Value) errors.Error {
+	c := &compatChecker{}
+	c.definitions("", old, new)
+	return c.errs
+}
+
+type compatChecker struct {
+	errs errors.Error
+}
+
+func (c *compatChecker) errf(v cue.Value, path, format string, args ...interface{}) {
+	err := errors.Newf(v.Pos(), "%s: "+format, append([]interface{}{path}, args...)...)
+	c.errs = errors.Append(c.errs, err)
+}
+
+// definitions compares the definitions of old and new that are defined
+// in both.
+func (c *compatChecker) definitions(path string, old, new cue.Value) {
+	oldDefs := collectDefs(old)
+	newDefs := collectDefs(new)
+	for _, name := range sortedKeys(oldDefs) {
+		n, ok := newDefs[name]
+		if !ok {
+			continue
+		}
+		o := oldDefs[name]
+		p := name
+		if path != "" {
+			p = path + "." + name
+		}
+		if strings.HasSuffix(name, "_value") {
+			c.enum(p, o, n)
+		} else {
+			c.message(p, o, n)
+		}
+		c.definitions(p, o, n)
+	}
+}
+
+// enum compares the numeric values of an enum, as recorded in the struct
+// generated for its values.
+func (c *compatChecker) enum(path string, old, new cue.Value) {
+	iter, _ := old.Fields()
+	for iter.Next() {
+		x, err := iter.Value().Int64()
+		if err != nil {
+			return // not an enum
+		}
+		name := iter.Selector().Unquoted()
+		v := new.LookupPath(cue.MakePath(iter.Selector()))
+		if y, err := v.Int64(); err == nil && x != y {
+			c.errf(v, path, "number of enum value %s changed from %d to %d", name, x, y)
+		}
+	}
+}
+
+// message compares the fields of a message.
+func (c *compatChecker) message(path string, old, new cue.Value) {
+	oldFields, oldReserved := collectFields(old)
+	newFields, newReserved := collectFields(new)
+	if len(oldFields) == 0 && len(newFields) == 0 {
+		return
+	}
+	reserved := reservations{
+		ranges: append(oldReserved.ranges, newReserved.ranges...),
+		names:  append(oldReserved.names, newReserved.names...),
+	}
+
+	oldByName := map[string]*protoField{}
+	for _, f := range oldFields {
+		oldByName[f.name] = f
+	}
+	newByNum := map[int]*protoField{}
+	for _, f := range newFields {
+		newByNum[f.num] = f
+	}
+	renumbered := map[int]bool{}
+	for _, f := range newFields {
+		o, ok := oldByName[f.name]
+		switch {
+		case ok && o.num != f.num:
+			c.errf(f.v, path, "number of field %s changed from %d to %d", f.name, o.num, f.num)
+			renumbered[o.num] = true
+		case reserved.hasNumber(f.num):
+			c.errf(f.v, path, "field %s uses reserved number %d", f.name, f.num)
+		case reserved.hasName(f.name) && !ok:
+			c.errf(f.v, path, "field %s uses a reserved name", f.name)
+		}
+	}
+
+	for _, o := range oldFields {
+		f, ok := newByNum[o.num]
+		switch {
+		case !ok:
+			if !renumbered[o.num] && !newReserved.hasNumber(o.num) {
+				c.errf(new, path, "field %s removed without reserving number %d", o.name, o.num)
+			}
+		case wireType(o.typ) != wireType(f.typ):
+			c.errf(f.v, path, "type of field %s (%d) changed from %s to %s", f.name, f.num, o.typ, f.typ)
+		case o.repeated != f.repeated:
+			c.errf(f.v, path, "field %s (%d) changed between singular and repeated", f.name, f.num)
+		}
+	}
+}
+
+type protoField struct {
+	v        cue.Value
+	name     string
+	num      int
+	typ      string
+	repeated bool
+}
+
+// collectFields returns the fields with a @protobuf attribute of the message
+// v, including those within oneofs, and the reserved numbers and names.
+func collectFields(v cue.Value) (fields []*protoField, r reservations) {
+	for _, a := range v.Attributes(cue.DeclAttr) {
+		if s, _ := a.String(0); a.Name() == "protobuf" && s == "reserved" {
+			r.parse(a)
+		}
+	}
+	collectMessageFields(v, &fields)
+	return fields, r
+}
+
+func collectMessageFields(v cue.Value, fields *[]*protoField) {
+	switch op, args := v.Expr(); op {
+	case cue.AndOp, cue.OrOp:
+		for _, a := range args {
+			collectMessageFields(a, fields)
+		}
+		return
+	}
+	iter, err := v.Fields(cue.Optional(true))
+	if err != nil {
+		return
+	}
+	for iter.Next() {
+		f := iter.Value()
+		a := f.Attribute("protobuf")
+		num, err := a.Int(0)
+		if err != nil {
+			continue
+		}
+		typ, _ := a.String(1)
+		name, ok, _ := a.Lookup(1, "name")
+		if !ok {
+			name = iter.Selector().Unquoted()
+		}
+		*fields = append(*fields, &protoField{
+			v:        f,
+			name:     name,
+			num:      int(num),
+			typ:      typ,
+			repeated: f.IncompleteKind() == cue.ListKind,
+		})
+	}
+}
+
+func collectDefs(v cue.Value) map[string]cue.Value {
+	defs := map[string]cue.Value{}
+	switch op, args := v.Expr(); op {
+	case cue.AndOp, cue.OrOp:
+		for _, a := range args {
+			for k, d := range collectDefs(a) {
+				defs[k] = d
+			}
+		}
+		return defs
+	}
+	iter, err := v.Fields(cue.Definitions(true))
+	if err != nil {
+		return defs
+	}
+	for iter.Next() {
+		if iter.Selector().IsDefinition() {
+			defs[iter.Selector().String()] = iter.Value()
+		}
+	}
+	return defs
+}
+
+func sortedKeys(m map[string]cue.Value) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// wireType returns a class of protobuf types that can be changed into each
+// other without breaking wire compatibility.
+func wireType(typ string) string {
+	switch typ {
+	case "int32", "int64", "uint32", "uint64", "bool":
+		return "varint"
+	case "sint32", "sint64":
+		return "zigzag"
+	case "fixed32", "sfixed32":
+		return "fixed32"
+	case "fixed64", "sfixed64":
+		return "fixed64"
+	case "string", "bytes":
+		return "bytes"
+	}
+	return typ
+}
+
+// reservations holds the reserved field numbers and names of a message.
+type reservations struct {
+	ranges [][2]int
+	names  []string
+}
+
+// parse adds the reservations of an attribute of the form
+//
+//	@protobuf(reserved,2,15,9 to 11,40 to max,foo,bar)
+func (r *reservations) parse(a cue.Attribute) {
+	for i := 1; i < a.NumArgs(); i++ {
+		s, _ := a.String(i)
+		s = strings.TrimSpace(s)
+		from, to, isRange := strings.Cut(s, " to ")
+		lo, err := strconv.Atoi(from)
+		if err != nil {
+			r.names = append(r.names, strings.Trim(s, `"`))
+			continue
+		}
+		hi := lo
+		switch to = strings.TrimSpace(to); {
+		case !isRange:
+		case to == "max":
+			hi = maxFieldNumber
+		default:
+			hi, _ = strconv.Atoi(to)
+		}
+		r.ranges = append(r.ranges, [2]int{lo, hi})
+	}
+}
+
+func (r reservations) hasNumber(n int) bool {
+	for _, x := range r.ranges {
+		if x[0] <= n && n <= x[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r reservations) hasName(name string) bool {
+	for _, s := range r.names {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	const old = `
+#Msg: {
+	@protobuf(reserved,5,10 to 12,legacy)
+
+	name?:  string @protobuf(1,string)
+	count?: int32  @protobuf(2,int32)
+	tags?: [...string] @protobuf(3,string)
+	{} | {
+		ref: string @protobuf(4,string)
+	}
+
+	#Nested: {
+		id?: int64 @protobuf(1,sint64)
+	}
+}
+
+#Kind_value: {
+	"A": 0
+	"B": 1
+}
+`
+	testCases := []struct {
+		name string
+		new  string
+		want string
+	}{{
+		name: "unchanged",
+		new:  old,
+	}, {
+		name: "compatible changes",
+		new: `
+#Msg: {
+	@protobuf(reserved,5,10 to 12,legacy)
+	@protobuf(reserved,4)
+
+	title?: bytes  @protobuf(1,bytes,name=name)
+	count?: int64  @protobuf(2,uint64)
+	tags?: [...string] @protobuf(3,string)
+	extra?: string @protobuf(6,string)
+
+	#Nested: {
+		id?: int64 @protobuf(1,sint32)
+	}
+}
+
+#Kind_value: {
+	"A": 0
+	"B": 1
+	"C": 2
+}
+`,
+	}, {
+		name: "incompatible changes",
+		new: `
+#Msg: {
+	name?:  string @protobuf(7,string)
+	count?: float64 @protobuf(2,double)
+	tags?:  string @protobuf(3,string)
+	foo?:   string @protobuf(11,string)
+	legacy?: string @protobuf(13,string)
+
+	#Nested: {
+		id?: int64 @protobuf(1,int64)
+	}
+}
+
+#Kind_value: {
+	"A": 0
+	"B": 2
+}
+`,
+		want: `
+#Kind_value: number of enum value B changed from 1 to 2
+#Msg: number of field name changed from 1 to 7
+#Msg: field foo uses reserved number 11
+#Msg: field legacy uses a reserved name
+#Msg: field ref removed without reserving number 4
+#Msg: type of field count (2) changed from int32 to double
+#Msg: field tags (3) changed between singular and repeated
+#Msg.#Nested: type of field id (1) changed from sint64 to int64
+`,
+	}}
+	ctx := cuecontext.New()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			old := ctx.CompileString(old)
+			new := ctx.CompileString(tc.new)
+			if err := new.Err(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, err := range errors.Errors(CheckCompatibility(old, new)) {
+				got = append(got, err.Error())
+			}
+			want := strings.TrimSpace(tc.want)
+			if s := strings.Join(got, "\n"); s != want {
+				t.Errorf("got:\n%s\nwant:\n%s", s, want)
+			}
+		})
+	}
+}
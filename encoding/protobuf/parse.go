@@ -40,11 +40,17 @@ func (s *Extractor) parse(filename string, src interface{}) (p *protoConverter,
 	if filename == "" {
 		return nil, errors.Newf(token.NoPos, "empty filename")
 	}
-	if r, ok := s.fileCache[filename]; ok {
+	// The same file may be added directly and be imported using a path
+	// relative to an include directory.
+	key := filename
+	if !filepath.IsAbs(key) {
+		key = filepath.Join(s.cwd, key)
+	}
+	if r, ok := s.fileCache[key]; ok {
 		return r.p, r.err
 	}
 	defer func() {
-		s.fileCache[filename] = result{p, err}
+		s.fileCache[key] = result{p, err}
 	}()
 
 	b, err := source.Read(filename, src)
@@ -126,6 +132,10 @@ func (s *Extractor) parse(filename string, src interface{}) (p *protoConverter,
 		}
 	}
 
+	if importPath, name, ok := s.mapImportPath(p.protoPkg); ok {
+		p.cuePkgPath, p.shortPkgName = importPath, name
+	}
+
 	if name := p.shortName(); name != "" {
 		p.file.Decls = append(p.file.Decls, &ast.Package{Name: ast.NewIdent(name)})
 	}
@@ -540,7 +550,22 @@ func (p *protoConverter) messageField(s *ast.StructLit, i int, v proto.Visitee)
 	case *proto.Oneof:
 		p.oneOf(x)
 
-	case *proto.Extensions, *proto.Reserved:
+	case *proto.Reserved:
+		// Record reserved numbers and names to allow checking the
+		// compatibility of later versions. See CheckCompatibility.
+		args := []string{"reserved"}
+		for _, r := range x.Ranges {
+			args = append(args, r.SourceRepresentation())
+		}
+		args = append(args, x.FieldNames...)
+		attr := &ast.Attribute{
+			At:   p.toCUEPos(x.Position),
+			Text: fmt.Sprintf("@protobuf(%s)", strings.Join(args, ",")),
+		}
+		addComments(attr, i, x.Comment, x.InlineComment)
+		s.Elts = append(s.Elts, attr)
+
+	case *proto.Extensions:
 		// no need to handle
 
 	case *proto.Option:
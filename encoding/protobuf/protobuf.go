@@ -74,6 +74,14 @@
 //	(cue.opt)     FieldOptions
 //	   required   bool          Defines the field is required. Use with
 //	                            caution.
+//
+// # Wire Compatibility
+//
+// The number and type of each field are recorded in a @protobuf attribute,
+// and reserved numbers and names of a message in a declaration attribute of
+// the form @protobuf(reserved,2,9 to 11,foo). This allows treating the
+// generated CUE as the canonical schema, while using CheckCompatibility to
+// verify that changes to it do not break wire compatibility.
 package protobuf
 
 // TODO mappings:
@@ -86,6 +94,7 @@ package protobuf
 
 import (
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -146,6 +155,20 @@ type Config struct {
 	//            disjunction of the enum to interpret strings.
 	//
 	EnumMode string
+
+	// ImportPaths maps proto packages to CUE import paths. A key matches
+	// the proto package of the same name and, unless the value specifies
+	// a package name, all proto packages nested within it, for which the
+	// remaining elements of the proto package are appended to the import
+	// path. This mirrors the proto package hierarchy in the CUE package
+	// hierarchy. The empty key matches all proto packages. If multiple
+	// keys match, the longest one is used.
+	//
+	// Like the go_package option, a value may specify a package name
+	// after a semicolon, as in "example.com/api/v1;apiv1". Otherwise the
+	// package name is the last element of the proto package. A mapping
+	// takes precedence over the go_package option.
+	ImportPaths map[string]string
 }
 
 // An Extractor converts a collection of proto files, typically belonging to one
@@ -165,6 +188,8 @@ type Extractor struct {
 	pkgName  string
 	enumMode string
 
+	importPaths map[string]string
+
 	fileCache map[string]result
 	imports   map[string]*build.Instance
 
@@ -190,7 +215,9 @@ func NewExtractor(c *Config) *Extractor {
 		module:    c.Module,
 		enumMode:  c.EnumMode,
 		fileCache: map[string]result{},
-		imports:   map[string]*build.Instance{},
+
+		importPaths: c.ImportPaths,
+		imports:     map[string]*build.Instance{},
 	}
 
 	if b.root == "" {
@@ -200,6 +227,32 @@ func NewExtractor(c *Config) *Extractor {
 	return b
 }
 
+// mapImportPath reports the import path and package name for the given
+// proto package as configured by Config.ImportPaths.
+func (b *Extractor) mapImportPath(protoPkg string) (importPath, pkgName string, ok bool) {
+	longest := -1
+	for key, value := range b.importPaths {
+		p, name, hasName := strings.Cut(value, ";")
+		switch {
+		case key == protoPkg:
+		case hasName:
+			continue
+		case key == "":
+			p = path.Join(p, strings.ReplaceAll(protoPkg, ".", "/"))
+		case strings.HasPrefix(protoPkg, key+"."):
+			rest := protoPkg[len(key)+1:]
+			p = path.Join(p, strings.ReplaceAll(rest, ".", "/"))
+		default:
+			continue
+		}
+		if len(key) > longest {
+			longest = len(key)
+			importPath, pkgName, ok = p, name, true
+		}
+	}
+	return importPath, pkgName, ok
+}
+
 // Err returns the errors accumulated during testing. The returned error may be
 // of type cuelang.org/go/cue/errors.List.
 func (b *Extractor) Err() error {
@@ -350,9 +403,14 @@ func (b *Extractor) getInst(p *protoConverter) *build.Instance {
 		// correspond with that of the proto package.
 		inPlace = false
 	}
-	if !inPlace {
+	switch rel, ok := inModule(path, b.module); {
+	case ok:
+		// Mirror the package hierarchy within the module, independently
+		// of the location of the proto file.
+		dir = filepath.Join(b.root, filepath.FromSlash(rel))
+	case !inPlace:
 		dir = filepath.Join(internal.GenPath(dir), path)
-	} else {
+	default:
 		dir = filepath.Dir(p.file.Filename)
 	}
 
@@ -385,6 +443,19 @@ func (b *Extractor) getInst(p *protoConverter) *build.Instance {
 	return inst
 }
 
+// inModule reports the path of the package with the given import path
+// relative to the root of the given module, if it is located within it.
+func inModule(importPath, module string) (rel string, ok bool) {
+	switch {
+	case module == "":
+		return "", false
+	case importPath == module:
+		return "", true
+	}
+	rel, ok = strings.CutPrefix(importPath, module+"/")
+	return rel, ok
+}
+
 // Extract parses a single proto file and returns its contents translated to a CUE
 // file. If src is not nil, it will use this as the contents of the file. It may
 // be a string, []byte or io.Reader. Otherwise Extract will open the given file
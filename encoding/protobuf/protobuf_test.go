@@ -153,3 +153,31 @@ func TestBuild(t *testing.T) {
 		t.Errorf("did not expect file %q", filename)
 	}
 }
+
+func TestMapImportPath(t *testing.T) {
+	b := NewExtractor(&Config{ImportPaths: map[string]string{
+		"":            "example.com/default",
+		"acme":        "example.com/acme",
+		"acme.mixer":  "example.com/mixer",
+		"acme.legacy": "example.com/old;legacy",
+	}})
+	testCases := []struct {
+		pkg, path, name string
+	}{
+		{"acme", "example.com/acme", ""},
+		{"acme.test.v1", "example.com/acme/test/v1", ""},
+		{"acme.mixer.v1", "example.com/mixer/v1", ""},
+		{"acme.mixerv2", "example.com/acme/mixerv2", ""},
+		{"acme.legacy", "example.com/old", "legacy"},
+		{"acme.legacy.v1", "example.com/acme/legacy/v1", ""},
+		{"google.protobuf", "example.com/default/google/protobuf", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pkg, func(t *testing.T) {
+			path, name, ok := b.mapImportPath(tc.pkg)
+			if !ok || path != tc.path || name != tc.name {
+				t.Errorf("got %q, %q, %v; want %q, %q", path, name, ok, tc.path, tc.name)
+			}
+		})
+	}
+}
@@ -447,6 +447,7 @@ package descriptor
 	// The parser stores options it doesn't recognize here.
 	// See the documentation for the "Options" section above.
 	uninterpretedOption?: [...#UninterpretedOption] @protobuf(999,UninterpretedOption,name=uninterpreted_option)
+	@protobuf(reserved,38)
 }
 
 #MessageOptions: {
@@ -503,6 +504,9 @@ package descriptor
 	// instead. The option should only be implicitly set by the proto compiler
 	// parser.
 	mapEntry?: bool @protobuf(7,bool,name=map_entry)
+	@protobuf(reserved,8) // javalite_serializable
+
+	@protobuf(reserved,9) // javanano_as_lite
 
 	// The parser stores options it doesn't recognize here. See above.
 	uninterpretedOption?: [...#UninterpretedOption] @protobuf(999,UninterpretedOption,name=uninterpreted_option)
@@ -609,6 +613,7 @@ package descriptor
 
 	// The parser stores options it doesn't recognize here. See above.
 	uninterpretedOption?: [...#UninterpretedOption] @protobuf(999,UninterpretedOption,name=uninterpreted_option)
+	@protobuf(reserved,4) // removed jtype
 }
 
 #OneofOptions: {
@@ -626,6 +631,7 @@ package descriptor
 	// for the enum, or it will be completely ignored; in the very least, this
 	// is a formalization for deprecating enums.
 	deprecated?: bool @protobuf(3,bool,"default=false")
+	@protobuf(reserved,5) // javanano_as_lite
 
 	// The parser stores options it doesn't recognize here. See above.
 	uninterpretedOption?: [...#UninterpretedOption] @protobuf(999,UninterpretedOption,name=uninterpreted_option)
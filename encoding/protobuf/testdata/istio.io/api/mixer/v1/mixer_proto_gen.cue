@@ -57,6 +57,8 @@ import (
 #CheckResponse: {
 	// Expresses the result of a precondition check.
 	#PreconditionResult: {
+		@protobuf(reserved,4)
+
 		// A status code of OK indicates all preconditions were satisfied. Any other code indicates not
 		// all preconditions were satisfied and details describe why.
 		status?: status_1.#Status @protobuf(1,google.rpc.Status,"(gogoproto.nullable)=false")
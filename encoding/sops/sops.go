@@ -0,0 +1,113 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sops recognizes and decrypts YAML and JSON documents encrypted
+// with SOPS (https://getsops.io).
+//
+// SOPS encrypts the values of a document, leaving its structure intact,
+// and records how to decrypt it in a top-level sops field. The actual
+// decryption is delegated to a Decrypter, such as Command, which runs the
+// sops command, so that the keys used to encrypt documents are managed by
+// the tools that are already used for them.
+package sops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// A Format is the format of a document.
+type Format string
+
+const (
+	YAML Format = "yaml"
+	JSON Format = "json"
+)
+
+// A Decrypter decrypts documents encrypted with SOPS.
+type Decrypter interface {
+	// Decrypt returns the plaintext of the encrypted document data, in
+	// the given format and without the sops metadata field.
+	Decrypt(ctx context.Context, data []byte, f Format) ([]byte, error)
+}
+
+// The metadata of an encrypted document is recorded in a top-level sops
+// field, which always contains a message authentication code. The code is
+// itself encrypted, which distinguishes it from plain data that happens to
+// have fields of the same names.
+var (
+	yamlMetadata = regexp.MustCompile(`(?m)^sops:[ \t]*\r?$`)
+	yamlMAC      = regexp.MustCompile(`(?m)^[ \t]+mac:[ \t]+['"]?ENC\[AES256_GCM,`)
+	jsonMetadata = regexp.MustCompile(`"sops"\s*:\s*\{`)
+	jsonMAC      = regexp.MustCompile(`"mac"\s*:\s*"ENC\[AES256_GCM,`)
+)
+
+// IsEncrypted reports whether data is a document in the given format that
+// is encrypted with SOPS.
+func IsEncrypted(data []byte, f Format) bool {
+	switch f {
+	case YAML:
+		return yamlMetadata.Match(data) && yamlMAC.Match(data)
+	case JSON:
+		return jsonMetadata.Match(data) && jsonMAC.Match(data)
+	}
+	return false
+}
+
+// Command is a Decrypter that runs the sops command, version 3.9 or
+// later. Documents are passed to sops on its standard input and their
+// plaintext is read from its standard output, so that neither is written
+// to disk. Keys are looked up by sops as usual, for instance from the
+// SOPS_AGE_KEY_FILE environment variable or a cloud key management
+// service.
+type Command struct {
+	// Path is the path of the sops command. It defaults to the sops
+	// command found in the directories listed in the PATH environment
+	// variable.
+	Path string
+
+	// Env holds additional environment variables for the sops command, of
+	// the form key=value.
+	Env []string
+}
+
+// Decrypt implements Decrypter.
+func (c *Command) Decrypt(ctx context.Context, data []byte, f Format) ([]byte, error) {
+	path := c.Path
+	if path == "" {
+		path = "sops"
+	}
+	cmd := exec.CommandContext(ctx, path, "decrypt",
+		"--input-type", string(f),
+		"--output-type", string(f))
+	cmd.Stdin = bytes.NewReader(data)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("sops: %s", msg)
+		}
+		return nil, fmt.Errorf("sops: %v", err)
+	}
+	return stdout.Bytes(), nil
+}
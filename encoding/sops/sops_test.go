@@ -0,0 +1,88 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sops
+
+import "testing"
+
+func TestIsEncrypted(t *testing.T) {
+	testCases := []struct {
+		name   string
+		format Format
+		data   string
+		want   bool
+	}{{
+		name:   "yaml",
+		format: YAML,
+		data: `password: ENC[AES256_GCM,data:Tr7o=,iv:1=,tag:2=,type:str]
+sops:
+    age:
+        - recipient: age1abc
+    lastmodified: "2024-01-01T00:00:00Z"
+    mac: ENC[AES256_GCM,data:abc=,iv:1=,tag:2=,type:str]
+    version: 3.9.0
+`,
+		want: true,
+	}, {
+		name:   "yamlPlain",
+		format: YAML,
+		data: `password: secret
+sops: true
+`,
+	}, {
+		name:   "yamlNested",
+		format: YAML,
+		data: `config:
+  sops:
+    mac: x
+`,
+	}, {
+		name:   "yamlPlainMAC",
+		format: YAML,
+		data: `host: example.com
+sops:
+    mac: 00:11:22:33:44:55
+`,
+	}, {
+		name:   "json",
+		format: JSON,
+		data: `{
+	"password": "ENC[AES256_GCM,data:Tr7o=,iv:1=,tag:2=,type:str]",
+	"sops": {
+		"mac": "ENC[AES256_GCM,data:abc=,iv:1=,tag:2=,type:str]",
+		"version": "3.9.0"
+	}
+}`,
+		want: true,
+	}, {
+		name:   "jsonPlain",
+		format: JSON,
+		data:   `{"sops": "tool"}`,
+	}, {
+		name:   "jsonPlainMAC",
+		format: JSON,
+		data:   `{"sops": {"mac": "00:11:22:33:44:55"}}`,
+	}, {
+		name:   "otherFormat",
+		format: "dotenv",
+		data:   "sops_mac=x\n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsEncrypted([]byte(tc.data), tc.format); got != tc.want {
+				t.Errorf("got %v; want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -33,8 +33,37 @@ import (
 // src is nil, the result of reading the file specified by filename will
 // be used.
 func Extract(filename string, src interface{}) (*ast.File, error) {
+	return ExtractWithConfig(filename, src, nil)
+}
+
+// A Config holds options for decoding YAML. The zero value decodes
+// YAML as Extract does.
+//
+// Some plain scalars, such as no, 1:30 and 2001-12-14, have a type that
+// differs between YAML 1.1 and the YAML 1.2 core schema. By default,
+// those are decoded as strings, as in YAML 1.2, except for integers with
+// a leading zero, such as 0755, which are decoded as octal numbers, as in
+// YAML 1.1.
+type Config = yaml.Config
+
+// A Coercion describes how a plain scalar with a type that differs
+// between YAML 1.1 and YAML 1.2 was decoded. It is passed to
+// Config.Report.
+type Coercion = yaml.Coercion
+
+// The kinds of plain scalars reported in a Coercion.
+const (
+	KindBool        = yaml.KindBool
+	KindSexagesimal = yaml.KindSexagesimal
+	KindTimestamp   = yaml.KindTimestamp
+	KindOctal       = yaml.KindOctal
+)
+
+// ExtractWithConfig is like Extract, but decodes the YAML as configured
+// by cfg, which may be nil.
+func ExtractWithConfig(filename string, src interface{}, cfg *Config) (*ast.File, error) {
 	a := []ast.Expr{}
-	d, err := yaml.NewDecoder(filename, src)
+	d, err := yaml.NewDecoderConfig(filename, src, cfg)
 	if err != nil {
 		return nil, err
 	}
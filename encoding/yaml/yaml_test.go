@@ -248,3 +248,32 @@ func TestYAMLValues(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractWithConfig(t *testing.T) {
+	const src = "a: yes\nb: 1:30\nc: 0755\n"
+	var kinds []string
+	f, err := ExtractWithConfig("test.yaml", src, &Config{
+		YAML11Bools: true,
+		Report: func(c Coercion) {
+			kinds = append(kinds, c.Kind)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := format.Node(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(b)), "a: true\nb: \"1:30\"\nc: 0o755"; got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := strings.Join(kinds, " "), "bool sexagesimal octal"; got != want {
+		t.Errorf("got coercions %s; want %s", got, want)
+	}
+
+	_, err = ExtractWithConfig("test.yaml", src, &Config{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "ambiguous plain scalar yes") {
+		t.Errorf("got error %v; want ambiguous plain scalar", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bug turns configurations that trigger evaluator bugs into
+// reproducers that can be attached to bug reports.
+//
+// A reproducer is a txtar archive holding a module. Its comment section
+// records how to run it and the errors it produces.
+package bug
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+)
+
+// Config holds the options common to all operations.
+type Config struct {
+	// Concrete requires values to be concrete when checking for errors,
+	// as for cue vet -c.
+	Concrete bool
+}
+
+// evaluate builds the given instances and returns the errors found when
+// validating them.
+func evaluate(insts []*build.Instance, cfg *Config) errors.Error {
+	var errs errors.Error
+	ctx := cuecontext.New()
+	for _, inst := range insts {
+		if inst.Err != nil {
+			errs = errors.Append(errs, inst.Err)
+			continue
+		}
+		v := ctx.BuildInstance(inst)
+		if err := v.Validate(cue.Concrete(cfg.Concrete)); err != nil {
+			errs = errors.Append(errs, errors.Promote(err, ""))
+		}
+	}
+	return errs
+}
+
+// signature returns a summary of err that does not depend on positions or
+// messages: the sorted paths of its errors, each passed through mapPath.
+func signature(err error, mapPath func(string) string) []string {
+	var sig []string
+	for _, e := range errors.Errors(err) {
+		path := e.Path()
+		elems := make([]string, len(path))
+		for i, p := range path {
+			elems[i] = mapPath(p)
+		}
+		sig = append(sig, strings.Join(elems, "."))
+	}
+	sort.Strings(sig)
+	return sig
+}
+
+// run loads the given packages from the module in the archive and returns
+// the errors found when evaluating them. The archive is written to a
+// temporary directory, which is removed before returning; dir is set to its
+// name so that errors can be printed relative to it.
+func run(a *txtar.Archive, args []string, cfg *Config, report func(dir string, err errors.Error)) error {
+	dir, err := os.MkdirTemp("", "cue-bug-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, f := range a.Files {
+		name := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o777); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, f.Data, 0o666); err != nil {
+			return err
+		}
+	}
+	insts := load.Instances(args, &load.Config{Dir: dir})
+	report(dir, evaluate(insts, cfg))
+	return nil
+}
+
+// command returns the cue command that reproduces the evaluation done for
+// cfg on the given packages.
+func command(args []string, cfg *Config) string {
+	cmd := "cue vet"
+	if !cfg.Concrete {
+		cmd += " -c=false"
+	}
+	return cmd + " " + strings.Join(args, " ")
+}
+
+// describe sets the comment of a to a description of how to reproduce the
+// problem and the errors it produces.
+func describe(a *txtar.Archive, title string, args []string, cfg *Config, dir string, err errors.Error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", title)
+	fmt.Fprintf(&b, "exec %s\n", command(args, cfg))
+	if err == nil {
+		b.WriteString("\nThe configuration evaluates without errors.\n")
+	} else {
+		b.WriteString("\nThis reports:\n\n")
+		for _, line := range strings.Split(strings.TrimRight(errors.Details(err, &errors.Config{
+			Cwd:     dir,
+			ToSlash: true,
+		}), "\n"), "\n") {
+			fmt.Fprintf(&b, "\t%s\n", line)
+		}
+	}
+	a.Comment = []byte(b.String())
+}
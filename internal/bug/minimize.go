@@ -0,0 +1,378 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bug
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+)
+
+// Minimize returns the smallest reproducer it can find for the given
+// instances that still reports an error matching match. If match is nil,
+// the first error reported for the instances is used as the target.
+//
+// Errors are matched by their messages, prefixed with their path and
+// without positions, as in
+//
+//	a.b: conflicting values 1 and 2
+//
+// Minimization repeatedly removes files, declarations, list elements and
+// imports, and replaces operations by one of their operands, keeping
+// every change after which the target error is still reported, until no
+// more changes can be made.
+func Minimize(insts []*build.Instance, cfg *Config, match *regexp.Regexp) (*txtar.Archive, error) {
+	if len(insts) == 0 {
+		return nil, fmt.Errorf("no instances to minimize")
+	}
+	for _, inst := range insts {
+		if inst.Err != nil {
+			return nil, inst.Err
+		}
+	}
+	msgs := messages(evaluate(insts, cfg))
+	if match == nil {
+		if len(msgs) == 0 {
+			return nil, fmt.Errorf("configuration reports no errors")
+		}
+		match = regexp.MustCompile("^" + regexp.QuoteMeta(msgs[0]) + "$")
+	}
+	if !slices.ContainsFunc(msgs, match.MatchString) {
+		return nil, fmt.Errorf("configuration reports no error matching %q", match)
+	}
+
+	m, err := newMinimizer(insts, cfg, match)
+	if err != nil {
+		return nil, err
+	}
+	if !m.test() {
+		// This can happen if the configuration depends on files that are
+		// not part of the packages, such as embedded files.
+		return nil, fmt.Errorf("the packages do not reproduce the error outside of their module")
+	}
+	for m.pass() {
+	}
+
+	a, err := m.archive()
+	if err != nil {
+		return nil, err
+	}
+	err = run(a, m.args, cfg, func(dir string, err errors.Error) {
+		describe(a, "Reproducer generated by cue bug minimize.", m.args, cfg, dir, err)
+	})
+	return a, err
+}
+
+// messages returns the messages of the errors in err, prefixed by their
+// path.
+func messages(err error) []string {
+	var msgs []string
+	for _, e := range errors.Errors(err) {
+		format, args := e.Msg()
+		msg := fmt.Sprintf(format, args...)
+		if p := e.Path(); len(p) > 0 {
+			msg = strings.Join(p, ".") + ": " + msg
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+type minimizer struct {
+	cfg   *Config
+	match *regexp.Regexp
+
+	module []byte // contents of cue.mod/module.cue
+	files  []*minFile
+	args   []string
+}
+
+type minFile struct {
+	name    string
+	f       *ast.File
+	removed bool
+}
+
+func newMinimizer(insts []*build.Instance, cfg *Config, match *regexp.Regexp) (*minimizer, error) {
+	m := &minimizer{cfg: cfg, match: match}
+	root := insts[0].Root
+	if root == "" {
+		root = insts[0].Dir
+	}
+	mod := insts[0].Module
+	if mod == "" {
+		mod = reproModule
+	}
+	m.module = []byte(fmt.Sprintf("module: %q\n", mod))
+	if b, err := os.ReadFile(filepath.Join(root, "cue.mod", "module.cue")); err == nil {
+		m.module = b
+	}
+
+	rel := func(dir string) string {
+		r, err := filepath.Rel(root, dir)
+		if err != nil || strings.HasPrefix(r, "..") {
+			return "."
+		}
+		return filepath.ToSlash(r)
+	}
+	for _, p := range localPackages(insts) {
+		dir := rel(p.Dir)
+		for _, f := range p.Files {
+			f, err := copyFile(f)
+			if err != nil {
+				return nil, err
+			}
+			m.files = append(m.files, &minFile{
+				name: path.Join(dir, filepath.Base(f.Filename)),
+				f:    f,
+			})
+		}
+	}
+	for _, inst := range insts {
+		if d := rel(inst.Dir); d != "." {
+			m.args = append(m.args, "./"+d)
+		} else {
+			m.args = append(m.args, ".")
+		}
+	}
+	return m, nil
+}
+
+// archive returns the current state of the reproducer.
+func (m *minimizer) archive() (*txtar.Archive, error) {
+	a := &txtar.Archive{}
+	a.Files = append(a.Files, txtar.File{Name: "cue.mod/module.cue", Data: m.module})
+	for _, f := range m.files {
+		if f.removed {
+			continue
+		}
+		b, err := format.Node(withoutUnusedImports(f.f))
+		if err != nil {
+			return nil, err
+		}
+		a.Files = append(a.Files, txtar.File{Name: f.name, Data: b})
+	}
+	return a, nil
+}
+
+// withoutUnusedImports returns a shallow copy of f without the imports
+// that are no longer referenced. Unused imports are an error, so without
+// this declarations could not be removed independently of the imports
+// they use.
+func withoutUnusedImports(f *ast.File) *ast.File {
+	used := map[ast.Node]bool{}
+	ast.Walk(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.ImportDecl:
+			return false
+		case *ast.Ident:
+			if x.Node != nil {
+				used[x.Node] = true
+			}
+		}
+		return true
+	}, nil)
+
+	c := *f
+	c.Decls = nil
+	for _, d := range f.Decls {
+		if imp, ok := d.(*ast.ImportDecl); ok {
+			var specs []*ast.ImportSpec
+			for _, s := range imp.Specs {
+				if used[s] {
+					specs = append(specs, s)
+				}
+			}
+			if len(specs) == 0 {
+				continue
+			}
+			cp := *imp
+			cp.Specs = specs
+			d = &cp
+		}
+		c.Decls = append(c.Decls, d)
+	}
+	return &c
+}
+
+// test reports whether the current state of the reproducer still reports
+// the target error.
+func (m *minimizer) test() bool {
+	a, err := m.archive()
+	if err != nil {
+		return false
+	}
+	found := false
+	err = run(a, m.args, m.cfg, func(dir string, err errors.Error) {
+		found = slices.ContainsFunc(messages(err), m.match.MatchString)
+	})
+	return err == nil && found
+}
+
+// pass makes a single pass over the reproducer, applying all reductions,
+// and reports whether any of them succeeded.
+func (m *minimizer) pass() bool {
+	progress := false
+	for _, f := range m.files {
+		if f.removed {
+			continue
+		}
+		if f.removed = true; m.test() {
+			progress = true
+			continue
+		}
+		f.removed = false
+	}
+	for _, f := range m.files {
+		if f.removed {
+			continue
+		}
+		if m.reduceLists(f.f) {
+			progress = true
+		}
+		if m.simplify(f.f) {
+			progress = true
+		}
+	}
+	return progress
+}
+
+// reduceLists removes elements from the lists of declarations, imports and
+// list elements in f.
+func (m *minimizer) reduceLists(f *ast.File) bool {
+	var reducers []func() bool
+	ast.Walk(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.File:
+			reducers = append(reducers, func() bool {
+				return reduce(&x.Decls, func(d ast.Decl) bool {
+					_, ok := d.(*ast.Package)
+					return ok
+				}, m.test)
+			})
+		case *ast.StructLit:
+			reducers = append(reducers, func() bool { return reduce(&x.Elts, nil, m.test) })
+		case *ast.ListLit:
+			reducers = append(reducers, func() bool { return reduce(&x.Elts, nil, m.test) })
+		case *ast.ImportDecl:
+			reducers = append(reducers, func() bool { return reduce(&x.Specs, nil, m.test) })
+		}
+		return true
+	}, nil)
+
+	progress := false
+	for _, r := range reducers {
+		if r() {
+			progress = true
+		}
+	}
+	return progress
+}
+
+// reduce removes elements from list for which keep does not report true,
+// as long as test reports true after the removal. It first tries to
+// remove large chunks of the list and then progressively smaller ones.
+func reduce[T any](list *[]T, keep func(T) bool, test func() bool) bool {
+	progress := false
+	for n := len(*list); n >= 1; n /= 2 {
+		for i := 0; i < len(*list); {
+			orig := *list
+			j := min(i+n, len(orig))
+			if keep != nil && slices.ContainsFunc(orig[i:j], keep) {
+				i = j
+				continue
+			}
+			*list = append(slices.Clone(orig[:i]), orig[j:]...)
+			if test() {
+				progress = true
+				continue
+			}
+			*list = orig
+			i = j
+		}
+	}
+	return progress
+}
+
+// simplify replaces operations in f by one of their operands.
+func (m *minimizer) simplify(f *ast.File) bool {
+	var slots []*ast.Expr
+	ast.Walk(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Field:
+			slots = append(slots, &x.Value)
+		case *ast.EmbedDecl:
+			slots = append(slots, &x.Expr)
+		case *ast.LetClause:
+			slots = append(slots, &x.Expr)
+		case *ast.BinaryExpr:
+			slots = append(slots, &x.X, &x.Y)
+		case *ast.ParenExpr:
+			slots = append(slots, &x.X)
+		case *ast.UnaryExpr:
+			slots = append(slots, &x.X)
+		case *ast.ListLit:
+			for i := range x.Elts {
+				slots = append(slots, &x.Elts[i])
+			}
+		case *ast.CallExpr:
+			for i := range x.Args {
+				slots = append(slots, &x.Args[i])
+			}
+		}
+		return true
+	}, nil)
+
+	progress := false
+	for _, p := range slots {
+		for m.simplifyExpr(p) {
+			progress = true
+		}
+	}
+	return progress
+}
+
+// simplifyExpr tries to replace the expression in p by one of its
+// operands and reports whether it succeeded.
+func (m *minimizer) simplifyExpr(p *ast.Expr) bool {
+	var operands []ast.Expr
+	switch x := (*p).(type) {
+	case *ast.BinaryExpr:
+		operands = []ast.Expr{x.X, x.Y}
+	case *ast.ParenExpr:
+		operands = []ast.Expr{x.X}
+	case *ast.UnaryExpr:
+		operands = []ast.Expr{x.X}
+	}
+	orig := *p
+	for _, e := range operands {
+		if *p = e; m.test() {
+			return true
+		}
+	}
+	*p = orig
+	return false
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bug_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/bug"
+	"cuelang.org/go/internal/cuetxtar"
+)
+
+func TestMinimize(t *testing.T) {
+	test := cuetxtar.TxTarTest{
+		Root: "./testdata/minimize",
+		Name: "minimize",
+	}
+
+	test.Run(t, func(t *cuetxtar.Test) {
+		var args []string
+		if s, ok := t.Value("args"); ok {
+			args = strings.Fields(s)
+		}
+		var match *regexp.Regexp
+		if s, ok := t.Value("match"); ok {
+			match = regexp.MustCompile(s)
+		}
+		a, err := bug.Minimize(t.Instances(args...), &bug.Config{
+			Concrete: t.Bool("concrete"),
+		}, match)
+		if err != nil {
+			t.WriteErrors(errors.Promote(err, ""))
+			return
+		}
+		writeArchive(t, a)
+	})
+}
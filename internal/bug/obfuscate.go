@@ -0,0 +1,539 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bug
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/compile"
+)
+
+// reproModule is the module path used for reproducers.
+const reproModule = "example.com/repro"
+
+// Obfuscate returns a reproducer for the given instances in which all
+// identifiers, labels, package names, file names and strings are
+// consistently replaced with generated names. Comments and attributes are
+// removed. The structure of the configuration, numbers, and references to
+// builtins and packages outside the main module are retained.
+//
+// Packages of the main module imported by the instances are included in
+// the reproducer. Packages from other modules are imported from their
+// original location.
+//
+// Obfuscate checks that the reproducer reports errors for the same paths,
+// after renaming, as the original instances. If replacing all strings
+// changes the errors, for instance because strings are used as regular
+// expressions, only strings that are also used as labels are replaced.
+// The returned boolean reports whether the errors were preserved.
+func Obfuscate(insts []*build.Instance, cfg *Config) (a *txtar.Archive, preserved bool, err error) {
+	if len(insts) == 0 {
+		return nil, false, fmt.Errorf("no instances to obfuscate")
+	}
+	for _, inst := range insts {
+		if inst.Err != nil {
+			return nil, false, inst.Err
+		}
+	}
+	pkgs := localPackages(insts)
+	orig := evaluate(insts, cfg)
+
+	for _, allStrings := range []bool{true, false} {
+		o := newObfuscator(insts[0], pkgs, allStrings)
+		a, args, err := o.archive(insts, pkgs)
+		if err != nil {
+			return nil, false, err
+		}
+		want := signature(orig, o.mapLabel)
+		var got []string
+		err = run(a, args, cfg, func(dir string, err errors.Error) {
+			got = signature(err, unquoteLabel)
+			describe(a, "Reproducer generated by cue bug obfuscate.", args, cfg, dir, err)
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		if slices.Equal(got, want) {
+			return a, true, nil
+		}
+		if !allStrings {
+			return a, false, nil
+		}
+	}
+	panic("unreachable")
+}
+
+// localPackages returns the given instances and the packages of the same
+// module they import, directly or indirectly, sorted by import path.
+func localPackages(insts []*build.Instance) []*build.Instance {
+	seen := map[*build.Instance]bool{}
+	var pkgs []*build.Instance
+	var add func(inst *build.Instance)
+	add = func(inst *build.Instance) {
+		if seen[inst] {
+			return
+		}
+		seen[inst] = true
+		pkgs = append(pkgs, inst)
+		for _, imp := range inst.Imports {
+			if isLocal(insts[0], imp) {
+				add(imp)
+			}
+		}
+	}
+	for _, inst := range insts {
+		add(inst)
+	}
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return pkgs[i].ImportPath < pkgs[j].ImportPath
+	})
+	return pkgs
+}
+
+// isLocal reports whether imp is a package in the main module of root.
+func isLocal(root, imp *build.Instance) bool {
+	mod := modulePath(root.Module)
+	return mod != "" && imp.Root == root.Root &&
+		(imp.ImportPath == mod || strings.HasPrefix(imp.ImportPath, mod+"/"))
+}
+
+// modulePath returns m without its major version suffix.
+func modulePath(m string) string {
+	p, _, _ := strings.Cut(m, "@")
+	return p
+}
+
+type obfuscator struct {
+	root       *build.Instance
+	allStrings bool
+
+	names map[string]string
+	n     int
+
+	// local holds the import paths of the packages of the main module.
+	local map[string]bool
+
+	// localSpecs holds the imports of the current file that refer to
+	// packages of the main module.
+	localSpecs map[*ast.ImportSpec]bool
+	// done holds identifiers that have already been renamed.
+	done map[*ast.Ident]bool
+	// skip holds literals that should not be treated as strings.
+	skip map[*ast.BasicLit]bool
+	// lits holds the string literals that are not labels, in the order
+	// in which they were encountered.
+	lits []*ast.BasicLit
+}
+
+func newObfuscator(root *build.Instance, pkgs []*build.Instance, allStrings bool) *obfuscator {
+	o := &obfuscator{
+		root:       root,
+		allStrings: allStrings,
+		names:      map[string]string{},
+		local:      map[string]bool{},
+	}
+	for _, p := range pkgs {
+		o.local[p.ImportPath] = true
+	}
+	return o
+}
+
+// splitName splits a label or identifier into its definition or hidden
+// prefix and the remainder.
+func splitName(s string) (prefix, base string) {
+	for _, p := range []string{"_#", "#", "_"} {
+		if b, ok := strings.CutPrefix(s, p); ok && b != "" {
+			return p, b
+		}
+	}
+	return "", s
+}
+
+// name returns the replacement for the identifier, label or string s,
+// allocating a new one if needed.
+func (o *obfuscator) name(s string) string {
+	if s == "" || s == "_" {
+		return s
+	}
+	prefix, base := splitName(s)
+	r, ok := o.names[base]
+	if !ok {
+		o.n++
+		r = fmt.Sprintf("x%d", o.n)
+		o.names[base] = r
+	}
+	return prefix + r
+}
+
+// mapLabel returns the replacement for the path element s of an error
+// reported for the original configuration.
+func (o *obfuscator) mapLabel(s string) string {
+	s = unquoteLabel(s)
+	if isIndex(s) {
+		return s
+	}
+	prefix, base := splitName(s)
+	if r, ok := o.names[base]; ok {
+		return prefix + r
+	}
+	return s
+}
+
+func unquoteLabel(s string) string {
+	if strings.HasPrefix(s, `"`) {
+		if u, err := literal.Unquote(s); err == nil {
+			return u
+		}
+	}
+	return s
+}
+
+func isIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// archive creates the reproducer for pkgs. It returns the archive and the
+// arguments to load the instances corresponding to roots.
+func (o *obfuscator) archive(roots, pkgs []*build.Instance) (*txtar.Archive, []string, error) {
+	a := &txtar.Archive{}
+	root := o.rootDir()
+	mod := reproModule
+	if _, v, ok := strings.Cut(o.root.Module, "@"); ok {
+		mod += "@" + v
+	}
+	a.Files = append(a.Files, txtar.File{
+		Name: "cue.mod/module.cue",
+		Data: []byte(fmt.Sprintf("module: %q\n", mod)),
+	})
+
+	var args []string
+	dirs := map[*build.Instance]string{}
+	for _, p := range pkgs {
+		dir := o.dir(root, p.Dir)
+		dirs[p] = dir
+		for _, f := range p.Files {
+			f, err := copyFile(f)
+			if err != nil {
+				return nil, nil, err
+			}
+			o.file(f)
+			o.replaceStrings()
+			b, err := format.Node(f)
+			if err != nil {
+				return nil, nil, err
+			}
+			name := o.name(strings.TrimSuffix(filepath.Base(f.Filename), ".cue")) + ".cue"
+			a.Files = append(a.Files, txtar.File{
+				Name: path.Join(dir, name),
+				Data: b,
+			})
+		}
+	}
+	for _, r := range roots {
+		if d := dirs[r]; d != "." {
+			args = append(args, "./"+d)
+		} else {
+			args = append(args, ".")
+		}
+	}
+	return a, args, nil
+}
+
+func (o *obfuscator) rootDir() string {
+	if o.root.Root != "" {
+		return o.root.Root
+	}
+	return o.root.Dir
+}
+
+// dir returns the obfuscated directory for dir, relative to root.
+func (o *obfuscator) dir(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "."
+	}
+	var elems []string
+	for _, e := range strings.Split(filepath.ToSlash(rel), "/") {
+		elems = append(elems, o.name(e))
+	}
+	return path.Join(elems...)
+}
+
+// copyFile returns a fresh copy of f that can be modified without
+// affecting the instance it belongs to.
+func copyFile(f *ast.File) (*ast.File, error) {
+	b, err := format.Node(f)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseFile(f.Filename, b)
+}
+
+// importPath returns the obfuscated version of the import path p.
+func (o *obfuscator) importPath(p string) string {
+	base, qual, hasQual := strings.Cut(p, ":")
+	if !o.local[base] && !o.local[p] {
+		return p
+	}
+	rest := strings.TrimPrefix(strings.TrimPrefix(base, modulePath(o.root.Module)), "/")
+	elems := []string{reproModule}
+	if rest != "" {
+		for _, e := range strings.Split(rest, "/") {
+			elems = append(elems, o.name(e))
+		}
+	}
+	p = strings.Join(elems, "/")
+	if hasQual {
+		p += ":" + o.name(qual)
+	}
+	return p
+}
+
+func (o *obfuscator) isLocalImport(spec *ast.ImportSpec) bool {
+	if local, ok := o.localSpecs[spec]; ok {
+		return local
+	}
+	p, err := literal.Unquote(spec.Path.Value)
+	if err != nil {
+		return false
+	}
+	base, _, _ := strings.Cut(p, ":")
+	local := o.local[base] || o.local[p]
+	o.localSpecs[spec] = local
+	return local
+}
+
+func (o *obfuscator) rename(id *ast.Ident) {
+	if id == nil || o.done[id] {
+		return
+	}
+	o.done[id] = true
+	id.Name = o.name(id.Name)
+}
+
+// file obfuscates f in place.
+func (o *obfuscator) file(f *ast.File) {
+	o.localSpecs = map[*ast.ImportSpec]bool{}
+	o.done = map[*ast.Ident]bool{}
+	o.skip = map[*ast.BasicLit]bool{}
+	o.lits = nil
+
+	f.Decls = filterDecls(f.Decls)
+	astutil.Apply(f, func(c astutil.Cursor) bool {
+		n := c.Node()
+		ast.SetComments(n, nil)
+
+		switch x := n.(type) {
+		case *ast.Package:
+			o.rename(x.Name)
+
+		case *ast.ImportSpec:
+			local := o.isLocalImport(x)
+			if local && x.Name != nil {
+				o.rename(x.Name)
+			}
+			if p, err := literal.Unquote(x.Path.Value); err == nil {
+				x.Path = ast.NewString(o.importPath(p))
+			}
+			return false
+
+		case *ast.StructLit:
+			x.Elts = filterDecls(x.Elts)
+
+		case *ast.Field:
+			x.Attrs = nil
+			switch l := x.Label.(type) {
+			case *ast.Ident:
+				o.rename(l)
+			case *ast.BasicLit:
+				o.label(l)
+			case *ast.Alias:
+				o.rename(l.Ident)
+				switch e := l.Expr.(type) {
+				case *ast.Ident:
+					o.rename(e)
+				case *ast.BasicLit:
+					o.label(e)
+				}
+			}
+
+		case *ast.Alias:
+			o.rename(x.Ident)
+
+		case *ast.LetClause:
+			o.rename(x.Ident)
+
+		case *ast.ForClause:
+			o.rename(x.Key)
+			o.rename(x.Value)
+
+		case *ast.SelectorExpr:
+			if id, ok := x.X.(*ast.Ident); ok {
+				if spec, ok := id.Node.(*ast.ImportSpec); ok && !o.isLocalImport(spec) {
+					// A reference to a builtin or an external package.
+					return false
+				}
+			}
+			if id, ok := x.Sel.(*ast.Ident); ok {
+				o.rename(id)
+			}
+
+		case *ast.Interpolation:
+			for _, e := range x.Elts {
+				if lit, ok := e.(*ast.BasicLit); ok {
+					o.skip[lit] = true
+					if o.allStrings {
+						lit.Value = scrub(lit.Value)
+					}
+				}
+			}
+
+		case *ast.Ident:
+			o.ident(x)
+
+		case *ast.BasicLit:
+			if x.Kind == token.STRING && !o.skip[x] {
+				o.lits = append(o.lits, x)
+			}
+		}
+		return true
+	}, nil)
+}
+
+// filterDecls removes comments and attributes from decls.
+func filterDecls(decls []ast.Decl) []ast.Decl {
+	var a []ast.Decl
+	for _, d := range decls {
+		switch d.(type) {
+		case *ast.CommentGroup, *ast.Attribute:
+			continue
+		}
+		a = append(a, d)
+	}
+	return a
+}
+
+// ident renames an identifier that is not declaring a name.
+func (o *obfuscator) ident(x *ast.Ident) {
+	if o.done[x] {
+		return
+	}
+	switch n := x.Node.(type) {
+	case *ast.ImportSpec:
+		if !o.isLocalImport(n) {
+			return
+		}
+	case nil:
+		// Unresolved identifiers refer to predeclared identifiers or to
+		// fields declared in other files of the package.
+		if x.Name == "_" || compile.IsPredeclared(x.Name) {
+			return
+		}
+	}
+	o.rename(x)
+}
+
+// label renames a quoted label.
+func (o *obfuscator) label(x *ast.BasicLit) {
+	o.skip[x] = true
+	s, err := literal.Unquote(x.Value)
+	if err != nil {
+		return
+	}
+	*x = *ast.NewString(o.name(s))
+}
+
+// replaceStrings replaces the string literals recorded while walking a file.
+// Unless all strings are replaced, only strings that are also used as
+// names are replaced.
+func (o *obfuscator) replaceStrings() {
+	for _, x := range o.lits {
+		s, err := literal.Unquote(x.Value)
+		if err != nil {
+			continue
+		}
+		prefix, base := splitName(s)
+		if _, ok := o.names[base]; !ok && !o.allStrings {
+			continue
+		}
+		r := o.name(prefix + base)
+		if strings.HasPrefix(x.Value, "'") || strings.HasPrefix(x.Value, "#'") {
+			x.Value = literal.Bytes.Quote(r)
+		} else {
+			x.Value = literal.String.Quote(r)
+		}
+	}
+	o.lits = nil
+}
+
+// scrub replaces all letters and digits in the fragment of an
+// interpolated string, leaving escape sequences intact.
+func scrub(s string) string {
+	var b strings.Builder
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		if r == '\\' && i+1 < len(rs) {
+			n := 1
+			switch rs[i+1] {
+			case 'u':
+				n = 5
+			case 'U':
+				n = 9
+			case 'x':
+				n = 3
+			}
+			for j := 0; j <= n && i < len(rs); j++ {
+				b.WriteRune(rs[i])
+				i++
+			}
+			i--
+			continue
+		}
+		switch {
+		case unicode.IsLetter(r):
+			b.WriteRune('x')
+		case unicode.IsDigit(r):
+			b.WriteRune('0')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bug_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/internal/bug"
+	"cuelang.org/go/internal/cuetxtar"
+)
+
+func TestObfuscate(t *testing.T) {
+	test := cuetxtar.TxTarTest{
+		Root: "./testdata/obfuscate",
+		Name: "obfuscate",
+	}
+
+	test.Run(t, func(t *cuetxtar.Test) {
+		var args []string
+		if s, ok := t.Value("args"); ok {
+			args = strings.Fields(s)
+		}
+		insts := t.Instances(args...)
+		a, preserved, err := bug.Obfuscate(insts, &bug.Config{
+			Concrete: t.Bool("concrete"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(t, "preserved: %v\n", preserved)
+		writeArchive(t, a)
+	})
+}
+
+// writeArchive writes a to the test output. Files are separated by lines
+// starting with "==" so that the output can be stored in a txtar file.
+func writeArchive(t *cuetxtar.Test, a *txtar.Archive) {
+	t.Write(a.Comment)
+	for _, f := range a.Files {
+		fmt.Fprintf(t, "== %s\n", f.Name)
+		t.Write(f.Data)
+	}
+}
@@ -77,6 +77,8 @@ workflows: trybot: _repo.bashWorkflow & {
 				},
 				for v in _e2eTestSteps {v},
 				_goCheck,
+				_goBuildWasm,
+				_goBuildCShared,
 				_repo.checkGitClean,
 			]
 		}
@@ -171,6 +173,25 @@ workflows: trybot: _repo.bashWorkflow & {
 			"""
 	}
 
+	_goBuildWasm: json.#step & {
+		// Ensure that the evaluator and the cue tool keep building for
+		// WebAssembly, as used by playgrounds and browser-based editors.
+		if:   "\(_isLatestLinux)"
+		name: "Build for WebAssembly"
+		run: """
+			GOOS=js GOARCH=wasm go build -o /dev/null ./cmd/cuewasm
+			GOOS=wasip1 GOARCH=wasm go build -o /dev/null ./cmd/cue
+			"""
+	}
+
+	_goBuildCShared: json.#step & {
+		// Ensure that the C shared library used to embed CUE in other
+		// languages keeps building.
+		if:   "\(_isLatestLinux)"
+		name: "Build C shared library"
+		run:  "go build -buildmode=c-shared -o /tmp/libcue.so ./cmd/libcue"
+	}
+
 	_goTestRace: json.#step & {
 		name: "Test with -race"
 		env: GORACE: "atexit_sleep_ms=10" // Otherwise every Go package being tested sleeps for 1s; see https://go.dev/issues/20364.
@@ -28,11 +28,11 @@ linuxMachine:   "ubuntu-22.04"
 macosMachine:   "macos-11"
 windowsMachine: "windows-2022"
 
-previousStableGo: "1.20.x"
+previousStableGo: "1.21.x"
 
 // Use the latest Go version for extra checks,
 // such as running tests with the data race detector.
-latestStableGo: "1.21.x"
+latestStableGo: "1.22.x"
 
 // Use a specific latest version for release builds.
 // Note that we don't want ".x" for the sake of reproducibility,
@@ -14,6 +14,11 @@
 
 package adt
 
+import (
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+)
+
 // CloseDef defines how individual fieldSets (corresponding to conjuncts)
 // combine to determine whether a field is contained in a closed set.
 //
@@ -63,5 +68,48 @@ func verifyArc2(ctx *OpContext, f Feature, v *Vertex, isClosed bool) (found bool
 		s.AddPositions(ctx)
 	}
 
-	return false, ctx.NewErrf("field not allowed")
+	b := ctx.NewErrf("field not allowed")
+	if s, ok := transposedField(ctx, f, v); ok {
+		b.Err = errors.WithSuggestions(b.Err, s)
+	}
+	return false, b
+}
+
+// transposedField returns a suggestion to rename the field v with label f
+// to an allowed field of its parent if their names differ only in two
+// adjacent characters that are swapped, as in nmae and name.
+func transposedField(ctx *OpContext, f Feature, v *Vertex) (s errors.Suggestion, ok bool) {
+	name := []rune(f.StringValue(ctx))
+	for _, a := range v.Parent.Arcs {
+		if a == v || !a.Label.IsString() || !isTransposition(name, []rune(a.Label.StringValue(ctx))) {
+			continue
+		}
+		if ok, _ := Accept(ctx, v.Parent, a.Label); !ok {
+			continue
+		}
+		s.Message = "rename field to " + a.Label.SelectorString(ctx)
+		for _, c := range v.Conjuncts {
+			if x, ok := c.Field().Source().(*ast.Field); ok {
+				s.Edits = append(s.Edits, errors.Edit{
+					Pos:     x.Label.Pos(),
+					End:     x.Label.End(),
+					NewText: a.Label.SelectorString(ctx),
+				})
+			}
+		}
+		return s, len(s.Edits) > 0
+	}
+	return s, false
+}
+
+func isTransposition(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i+1 < len(a); i++ {
+		if a[i] != b[i] {
+			return a[i] == b[i+1] && a[i+1] == b[i] && string(a[i+2:]) == string(b[i+2:])
+		}
+	}
+	return false
 }
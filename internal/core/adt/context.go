@@ -198,6 +198,10 @@ func New(v *Vertex, cfg *Config) *OpContext {
 	if v != nil {
 		ctx.e = &Environment{Up: nil, Vertex: v}
 	}
+	if fileStatsEnabled.Load() {
+		ctx.fileUsage = map[string]*stats.Usage{}
+	}
+	ctx.observer = currentObserver()
 	return ctx
 }
 
@@ -222,6 +226,13 @@ type OpContext struct {
 	stats        stats.Counts
 	freeListNode *nodeContext
 
+	// fileUsage and timers hold the stats per file, if enabled.
+	fileUsage map[string]*stats.Usage
+	timers    []fileTimer
+
+	// observer is notified of the progress of unification, if set.
+	observer Observer
+
 	e         *Environment
 	ci        CloseInfo
 	src       ast.Node
@@ -125,6 +125,9 @@ func (n *nodeContext) expandDisjuncts(
 	recursive, last bool) {
 
 	n.ctx.stats.Disjuncts++
+	if u := n.ctx.usage(n.node); u != nil {
+		u.Disjuncts++
+	}
 
 	// refNode is used to collect cyclicReferences for all disjuncts to be
 	// passed up to the parent node. Note that because the node in the parent
@@ -161,6 +161,16 @@ func (c *OpContext) unify(v *Vertex, flags combinedFlags) {
 		}()
 	}
 
+	if c.fileUsage != nil {
+		c.startTimer(v)
+		defer c.stopTimer()
+	}
+
+	if c.observer != nil && v.status != finalized {
+		c.observer.EnterUnify(c, v)
+		defer c.observer.ExitUnify(c, v)
+	}
+
 	// Ensure a node will always have a nodeContext after calling Unify if it is
 	// not yet Finalized.
 	n := v.getNodeContext(c, 1)
@@ -214,6 +224,9 @@ func (c *OpContext) unify(v *Vertex, flags combinedFlags) {
 		}
 
 		c.stats.Unifications++
+		if u := c.usage(v); u != nil {
+			u.Unifications++
+		}
 
 		// Set the cache to a cycle error to ensure a cyclic reference will result
 		// in an error if applicable. A cyclic error may be ignored for
@@ -295,6 +308,10 @@ func (c *OpContext) unify(v *Vertex, flags combinedFlags) {
 
 		n.finalizeDisjuncts()
 
+		if c.observer != nil && len(n.disjunctions) > 0 {
+			n.observeDisjunctions()
+		}
+
 		switch len(n.disjuncts) {
 		case 0:
 		case 1:
@@ -442,6 +459,17 @@ func (n *nodeContext) finalizeDisjuncts() {
 	n.disjuncts = a[:k]
 }
 
+// observeDisjunctions reports the result of finalizeDisjuncts to the
+// observer of n.
+func (n *nodeContext) observeDisjunctions() {
+	r := &DisjunctionResult{Errs: n.disjunctErrs}
+	for _, d := range n.disjuncts {
+		r.Disjuncts = append(r.Disjuncts, &d.result)
+		r.Default = append(r.Default, d.defaultMode == isDefault)
+	}
+	n.ctx.observer.Disjunctions(n.ctx, n.node, r)
+}
+
 func (n *nodeContext) doNotify() {
 	if n.errs == nil || len(n.notify) == 0 {
 		return
@@ -1530,6 +1558,9 @@ func (n *nodeContext) addExprConjunct(v Conjunct, state vertexStatus) {
 		n.evalExpr(v, state)
 	}
 	n.ctx.stats.Conjuncts++
+	if u := n.ctx.usage(n.node); u != nil {
+		u.Conjuncts++
+	}
 }
 
 // evalExpr is only called by addExprConjunct. If an error occurs, it records
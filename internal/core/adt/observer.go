@@ -0,0 +1,69 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adt
+
+import "sync/atomic"
+
+// An Observer is notified of the progress of unification. It is intended
+// for debuggers that step through evaluation.
+//
+// The methods of an Observer are called on the goroutine that runs the
+// evaluation and may block to pause it. While paused, the observer may
+// inspect, but not modify or evaluate, the vertices passed to it and
+// their parents.
+type Observer interface {
+	// EnterUnify is called when c starts processing v. Calls for vertices
+	// that are already finalized are omitted.
+	EnterUnify(c *OpContext, v *Vertex)
+
+	// ExitUnify is called for each call to EnterUnify when c stops
+	// processing v, which need not have been finalized by then.
+	ExitUnify(c *OpContext, v *Vertex)
+
+	// Disjunctions is called when c has resolved the disjunctions of v,
+	// before v is updated with the result. The values referenced by d are
+	// only valid during the call.
+	Disjunctions(c *OpContext, v *Vertex, d *DisjunctionResult)
+}
+
+// A DisjunctionResult describes the outcome of resolving the disjunctions of
+// a vertex.
+type DisjunctionResult struct {
+	// Disjuncts holds the disjuncts that remain after eliminating erroneous
+	// and duplicate ones.
+	Disjuncts []*Vertex
+
+	// Default reports for each of the remaining disjuncts whether it is
+	// marked as a default.
+	Default []bool
+
+	// Errs holds the errors of the eliminated disjuncts.
+	Errs []*Bottom
+}
+
+type observerHolder struct{ o Observer }
+
+var observer atomic.Value // observerHolder
+
+// SetObserver sets the Observer of OpContexts created after this call. Use
+// nil to disable observing.
+func SetObserver(o Observer) {
+	observer.Store(observerHolder{o})
+}
+
+func currentObserver() Observer {
+	h, _ := observer.Load().(observerHolder)
+	return h.o
+}
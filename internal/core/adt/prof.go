@@ -16,6 +16,8 @@ package adt
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"cuelang.org/go/cue/stats"
 )
@@ -26,13 +28,33 @@ var (
 	// counts is a temporary and internal solution for collecting global stats. It is protected with a mutex.
 	counts   stats.Counts
 	countsMu sync.Mutex
+
+	// fileUsage holds the global stats per file if fileStatsEnabled is set.
+	// It is protected by countsMu.
+	fileUsage        map[string]*stats.Usage
+	fileStatsEnabled atomic.Bool
 )
 
+// EnableFileStats causes OpContexts created after this call to also record
+// their stats per source file. See FileStats.
+func EnableFileStats() {
+	fileStatsEnabled.Store(true)
+}
+
 // AddStats adds the stats of the given OpContext to the global
 // counters.
 func AddStats(ctx *OpContext) {
 	countsMu.Lock()
 	counts.Add(ctx.stats)
+	if len(ctx.fileUsage) > 0 && fileUsage == nil {
+		fileUsage = map[string]*stats.Usage{}
+	}
+	for file, u := range ctx.fileUsage {
+		if fileUsage[file] == nil {
+			fileUsage[file] = &stats.Usage{}
+		}
+		fileUsage[file].Add(*u)
+	}
 	countsMu.Unlock()
 }
 
@@ -45,3 +67,77 @@ func TotalStats() stats.Counts {
 	countsMu.Unlock()
 	return s
 }
+
+// FileStats returns the aggregate stats per source file of all operations
+// calling AddStats, keyed by file name. Operations on values that are not
+// defined in a file, such as those of builtin packages, are not included.
+func FileStats() map[string]stats.Usage {
+	countsMu.Lock()
+	defer countsMu.Unlock()
+	m := make(map[string]stats.Usage, len(fileUsage))
+	for file, u := range fileUsage {
+		m[file] = *u
+	}
+	return m
+}
+
+// usage returns the per-file stats for the file defining v, or nil if file
+// stats are not enabled or v is not defined in a file.
+func (c *OpContext) usage(v *Vertex) *stats.Usage {
+	if c.fileUsage == nil {
+		return nil
+	}
+	file := vertexFile(v)
+	if file == "" {
+		return nil
+	}
+	u := c.fileUsage[file]
+	if u == nil {
+		u = &stats.Usage{}
+		c.fileUsage[file] = u
+	}
+	return u
+}
+
+// vertexFile returns the name of the first file that defines a conjunct of
+// v, or "" if there is no such file.
+func vertexFile(v *Vertex) string {
+	for _, c := range v.Conjuncts {
+		if src := c.Source(); src != nil {
+			if file := src.Pos().Filename(); file != "" {
+				return file
+			}
+		}
+	}
+	return ""
+}
+
+// A fileTimer records the time spent unifying a vertex for the file stats.
+type fileTimer struct {
+	usage *stats.Usage
+	start time.Time
+	inner time.Duration // time spent in nested timers
+}
+
+// startTimer starts measuring the time spent unifying v. When stopped, the
+// time is attributed to the file defining v, excluding the time of nested
+// timers that is attributed to other files.
+func (c *OpContext) startTimer(v *Vertex) {
+	c.timers = append(c.timers, fileTimer{usage: c.usage(v), start: time.Now()})
+}
+
+func (c *OpContext) stopTimer() {
+	i := len(c.timers) - 1
+	t := c.timers[i]
+	c.timers = c.timers[:i]
+	d := time.Since(t.start)
+	if t.usage != nil {
+		t.usage.Duration += d - t.inner
+	} else {
+		// Attribute the time to the enclosing timer instead.
+		d = t.inner
+	}
+	if i > 0 {
+		c.timers[i-1].inner += d
+	}
+}
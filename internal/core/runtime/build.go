@@ -15,12 +15,15 @@
 package runtime
 
 import (
+	"slices"
 	"strings"
+	"time"
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/logging"
 	"cuelang.org/go/cue/stats"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
@@ -28,6 +31,8 @@ import (
 	"cuelang.org/go/internal/core/compile"
 )
 
+var logger = logging.For(logging.Evaluator)
+
 type Config struct {
 	Runtime    *Runtime
 	Filename   string
@@ -47,6 +52,25 @@ func (x *Runtime) Build(cfg *Config, b *build.Instance) (v *adt.Vertex, errs err
 	if v := x.getNodeFromInstance(b); v != nil {
 		return v, b.Err
 	}
+	// The experiments enabled by the module of an instance change how the
+	// whole runtime evaluates values, so they cannot be enabled once it is
+	// in use.
+	for _, name := range b.Experiments {
+		if _, ok := slices.BinarySearch(x.experiments, name); !ok {
+			return nil, errors.Newf(token.NoPos,
+				"module %s enables evaluator experiment %q, which is not enabled in the context",
+				b.Module, name)
+		}
+	}
+	start := time.Now()
+	defer func() {
+		logger.Debug("built instance",
+			"path", b.ImportPath,
+			"files", len(b.Files),
+			"duration", time.Since(start),
+			"err", errs)
+	}()
+
 	// TODO: clear cache of old implementation.
 	// if s := b.ImportPath; s != "" {
 	// 	// Use cached result, if available.
@@ -139,6 +163,9 @@ func (x *Runtime) buildSpec(cfg *Config, b *build.Instance, spec *ast.ImportSpec
 		} else if x.index.builtinPaths[info.ID] == nil {
 			return errors.Newf(spec.Pos(),
 				"builtin package %q undefined", info.ID)
+		} else if !x.BuiltinAllowed(info.ID, "") {
+			return errors.Newf(spec.Pos(),
+				"builtin package %q is not allowed", info.ID)
 		}
 		return nil
 	}
@@ -15,11 +15,13 @@
 package runtime
 
 import (
+	"fmt"
 	"path"
 	"sync"
 
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal/core/adt"
 )
 
@@ -142,10 +144,17 @@ func (r *Runtime) LoadImport(importPath string) *adt.Vertex {
 
 	if x.builtinPaths != nil {
 		if f := x.builtinPaths[importPath]; f != nil {
+			if !r.BuiltinAllowed(importPath, "") {
+				return adt.ToVertex(&adt.Bottom{
+					Code: adt.EvalError,
+					Err:  errors.Newf(token.NoPos, "builtin package %q is not allowed", importPath),
+				})
+			}
 			p, err := f(r)
 			if err != nil {
 				return adt.ToVertex(&adt.Bottom{Err: err})
 			}
+			r.restrictBuiltins(importPath, p)
 			inst := &build.Instance{
 				ImportPath: importPath,
 				PkgName:    path.Base(importPath),
@@ -159,3 +168,54 @@ func (r *Runtime) LoadImport(importPath string) *adt.Vertex {
 
 	return key
 }
+
+// SetBuiltinFilter restricts the builtin packages, and the builtins within
+// them, that may be used to those for which allow returns true. The name
+// passed to allow is empty for a package itself.
+//
+// It must be called before any builtin packages are loaded.
+func (r *Runtime) SetBuiltinFilter(allow func(importPath, name string) bool) {
+	r.allowBuiltin = allow
+}
+
+// BuiltinAllowed reports whether the builtin with the given name, or the
+// package itself if name is empty, may be used.
+func (r *Runtime) BuiltinAllowed(importPath, name string) bool {
+	if r.allowBuiltin == nil {
+		return true
+	}
+	if !r.allowBuiltin(importPath, "") {
+		return false
+	}
+	return name == "" || r.allowBuiltin(importPath, name)
+}
+
+// restrictBuiltins replaces the builtins of the package p that are not
+// allowed, so that using them results in an error. Functions remain
+// functions, so that the error is reported where they are called.
+func (r *Runtime) restrictBuiltins(importPath string, p *adt.Vertex) {
+	if r.allowBuiltin == nil {
+		return
+	}
+	for i, a := range p.Arcs {
+		name := a.Label.SelectorString(r)
+		if r.allowBuiltin(importPath, name) {
+			continue
+		}
+		msg := fmt.Sprintf("builtin %s.%s is not allowed", path.Base(importPath), name)
+		var v adt.Value = &adt.Bottom{
+			Code: adt.EvalError,
+			Err:  errors.Newf(token.NoPos, "%s", msg),
+		}
+		if b, ok := a.BaseValue.(*adt.Builtin); ok {
+			disabled := *b
+			disabled.Func = func(c *adt.OpContext, args []adt.Value) adt.Expr {
+				return c.NewErrf("%s", msg)
+			}
+			v = &disabled
+		}
+		arc := &adt.Vertex{Label: a.Label, Parent: p}
+		arc.AddConjunct(adt.MakeRootConjunct(nil, v))
+		p.Arcs[i] = arc
+	}
+}
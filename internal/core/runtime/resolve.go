@@ -132,13 +132,15 @@ func resolveFile(
 			if spec == nil {
 				continue
 			}
+			var err errors.Error
 			if spec.Name == nil {
-				errs = errors.Append(errs, nodeErrorf(spec,
-					"imported and not used: %s", spec.Path.Value))
+				err = nodeErrorf(spec,
+					"imported and not used: %s", spec.Path.Value)
 			} else {
-				errs = errors.Append(errs, nodeErrorf(spec,
-					"imported and not used: %s as %s", spec.Path.Value, spec.Name))
+				err = nodeErrorf(spec,
+					"imported and not used: %s as %s", spec.Path.Value, spec.Name)
 			}
+			errs = errors.Append(errs, errors.WithSuggestions(err, removeImport(f, spec)))
 		}
 	}
 
@@ -167,3 +169,18 @@ func resolveFile(
 func lineStr(idx *index, n ast.Node) string {
 	return n.Pos().String()
 }
+
+// removeImport returns a suggestion to remove the import spec from f,
+// including its import declaration if it is the only spec.
+func removeImport(f *ast.File, spec *ast.ImportSpec) errors.Suggestion {
+	var n ast.Node = spec
+	for _, d := range f.Decls {
+		if d, ok := d.(*ast.ImportDecl); ok && len(d.Specs) == 1 && d.Specs[0] == spec {
+			n = d
+		}
+	}
+	return errors.Suggestion{
+		Message: "remove unused import " + spec.Path.Value,
+		Edits:   []errors.Edit{{Pos: n.Pos(), End: n.End()}},
+	}
+}
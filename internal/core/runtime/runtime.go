@@ -15,8 +15,11 @@
 package runtime
 
 import (
+	"slices"
+
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/cueexperiment"
 )
 
 // A Runtime maintains data structures for indexing and reuse for evaluation.
@@ -29,13 +32,50 @@ type Runtime struct {
 	// the kind in a file-level @extern(kind) attribute.
 	interpreters map[string]Interpreter
 
+	// allowBuiltin, if non-nil, reports whether a builtin package, or a
+	// builtin of such a package, may be used.
+	allowBuiltin func(importPath, name string) bool
+
 	version internal.EvaluatorVersion
+
+	// experiments holds the names of the enabled evaluator experiments,
+	// in alphabetical order.
+	experiments []string
 }
 
 func (r *Runtime) EvaluatorVersion() internal.EvaluatorVersion {
 	return r.version
 }
 
+// EnableExperiments enables the evaluator experiments with the given
+// names. See [cueexperiment.Experiments] for the known experiments.
+//
+// As experiments change how values are evaluated, they must be enabled
+// before the runtime is used, when it is created.
+func (r *Runtime) EnableExperiments(names ...string) error {
+	for _, name := range names {
+		if err := cueexperiment.Check(name); err != nil {
+			return err
+		}
+		i, found := slices.BinarySearch(r.experiments, name)
+		if found {
+			continue
+		}
+		r.experiments = slices.Insert(r.experiments, i, name)
+		switch name {
+		case "evalv3":
+			r.version = internal.DevVersion
+		}
+	}
+	return nil
+}
+
+// Experiments returns the names of the enabled evaluator experiments, in
+// alphabetical order.
+func (r *Runtime) Experiments() []string {
+	return slices.Clone(r.experiments)
+}
+
 func (r *Runtime) SetBuildData(b *build.Instance, x interface{}) {
 	r.loaded[b] = x
 }
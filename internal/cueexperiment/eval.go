@@ -0,0 +1,51 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cueexperiment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An Experiment is an experimental behavior of the evaluator. Unlike the
+// process-wide CUE_EXPERIMENT flags, experiments are enabled per module,
+// in the language.experiments field of its cue.mod/module.cue file, or
+// per context with [cuelang.org/go/cue/cuecontext.Experiments].
+type Experiment struct {
+	// Name is the name used to enable the experiment.
+	Name string
+
+	// Doc describes the experimental behavior.
+	Doc string
+}
+
+// Experiments lists the known evaluator experiments.
+var Experiments = []Experiment{{
+	Name: "evalv3",
+	Doc:  "use the new implementation of the evaluator, which does not cover all of the language yet",
+}}
+
+// Check reports an error if name is not the name of a known evaluator
+// experiment.
+func Check(name string) error {
+	var names []string
+	for _, e := range Experiments {
+		if e.Name == name {
+			return nil
+		}
+		names = append(names, e.Name)
+	}
+	return fmt.Errorf("unknown evaluator experiment %q; known experiments are %s", name, strings.Join(names, ", "))
+}
@@ -58,3 +58,8 @@ func TestInit(t *testing.T) {
 func setZero[T any](x *T) {
 	*x = *new(T)
 }
+
+func TestCheck(t *testing.T) {
+	qt.Assert(t, qt.IsNil(Check("evalv3")))
+	qt.Assert(t, qt.ErrorMatches(Check("foo"), `unknown evaluator experiment "foo"; known experiments are evalv3`))
+}
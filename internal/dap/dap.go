@@ -0,0 +1,776 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dap implements a server for the Debug Adapter Protocol that steps
+// through the evaluation of a CUE package.
+//
+// The evaluation is presented as a single thread. Its stack consists of the
+// vertices, identified by their paths, that are being unified, the
+// innermost first. Evaluation can be paused whenever unification of a
+// vertex starts:
+//
+//   - function breakpoints stop at vertices whose path matches the
+//     breakpoint's name, which is a CUE path that may contain wildcards,
+//     such as a.*.b;
+//   - source breakpoints stop at vertices with a conjunct on the line of
+//     the breakpoint;
+//   - the "errors" exception filter stops when a vertex becomes an error;
+//   - stepping stops at the next vertex, skipping nested ones when stepping
+//     over and stopping at an enclosing one when stepping out.
+//
+// While paused, the variables of a stack frame show the status, partial
+// value, conjuncts and arcs of its vertex.
+//
+// See https://microsoft.github.io/debug-adapter-protocol/ for the protocol.
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/debug"
+	"cuelang.org/go/internal/core/runtime"
+)
+
+// Config configures a server.
+type Config struct {
+	// Dir is the directory relative to which programs are resolved if a
+	// launch request does not specify a working directory.
+	Dir string
+}
+
+// A message is a request, response, or event of the protocol.
+type message struct {
+	Seq     int    `json:"seq"`
+	Type    string `json:"type"`
+	Command string `json:"command,omitempty"`
+	Event   string `json:"event,omitempty"`
+
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+
+	RequestSeq int    `json:"request_seq,omitempty"`
+	Success    *bool  `json:"success,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Body       any    `json:"body,omitempty"`
+}
+
+// launchArgs holds the arguments of a launch request.
+type launchArgs struct {
+	// Program is the package directory or file to evaluate.
+	Program     string   `json:"program"`
+	Cwd         string   `json:"cwd"`
+	Tags        []string `json:"tags"`
+	StopOnEntry bool     `json:"stopOnEntry"`
+}
+
+type source struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+type breakpoint struct {
+	Verified bool   `json:"verified"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+type variable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+// A stepMode determines where evaluation pauses next, in addition to
+// breakpoints.
+type stepMode int
+
+const (
+	run      stepMode = iota
+	stepIn            // at the next vertex
+	stepOver          // at the next vertex not nested in the current one
+	stepOut           // at the next vertex enclosing the current one
+	pause             // at the next vertex, because the client asked
+	entry             // at the first vertex
+)
+
+const threadID = 1
+
+// A server serves a single debugging session.
+type server struct {
+	cfg Config
+	r   *bufio.Reader
+
+	wmu sync.Mutex // protects w and seq
+	w   io.Writer
+	seq int
+
+	mu           sync.Mutex // protects the fields below
+	launch       *launchArgs
+	configured   bool
+	started      bool
+	paused       bool
+	disconnected bool
+	lineBPs      map[string]map[int]bool // file name to lines
+	pathBPs      []cue.Path
+	breakOnError bool
+	mode         stepMode
+	modeDepth    int
+
+	// resume is signaled to continue a paused evaluation. The server does
+	// so after responding to the request that resumes it, as indicated by
+	// resumeNow, so that the response precedes any subsequent events.
+	resume    chan struct{}
+	resumeNow bool
+
+	// The following fields are owned by the goroutine running the
+	// evaluation, and by the server while the evaluation is paused.
+	// The server only accesses them when paused is set.
+	runtime  adt.Runtime
+	ctx      *adt.OpContext
+	stack    []*adt.Vertex
+	reported map[string]bool // errors reported by path
+	vars     map[int]func() []variable
+}
+
+// Serve serves a debugging session, reading requests from r and writing
+// responses and events to w, until the client disconnects or r is
+// exhausted. Only one session may be served at a time.
+func Serve(r io.Reader, w io.Writer, cfg *Config) error {
+	s := &server{
+		r:        bufio.NewReader(r),
+		w:        w,
+		lineBPs:  map[string]map[int]bool{},
+		resume:   make(chan struct{}),
+		reported: map[string]bool{},
+	}
+	if cfg != nil {
+		s.cfg = *cfg
+	}
+	defer adt.SetObserver(nil)
+	defer s.disconnect()
+
+	for {
+		req, err := readMessage(s.r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		body, err := s.handle(req)
+		s.respond(req, body, err)
+		switch req.Command {
+		case "initialize":
+			s.event("initialized", nil)
+		case "disconnect", "terminate":
+			return nil
+		}
+		if s.resumeNow {
+			s.resumeNow = false
+			s.resume <- struct{}{}
+		}
+	}
+}
+
+func readMessage(r *bufio.Reader) (*message, error) {
+	tp := textproto.NewReader(r)
+	h, err := tp.ReadMIMEHeader()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading message header: %v", err)
+	}
+	n, err := strconv.Atoi(h.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", h.Get("Content-Length"))
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("reading message: %v", err)
+	}
+	m := &message{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("invalid message: %v", err)
+	}
+	return m, nil
+}
+
+func (s *server) send(m *message) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	s.seq++
+	m.Seq = s.seq
+	b, err := json.Marshal(m)
+	if err != nil {
+		panic(err) // all messages are encodable
+	}
+	fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(b), b)
+}
+
+func (s *server) respond(req *message, body any, err error) {
+	ok := err == nil
+	m := &message{
+		Type:       "response",
+		Command:    req.Command,
+		RequestSeq: req.Seq,
+		Success:    &ok,
+		Body:       body,
+	}
+	if err != nil {
+		m.Message = err.Error()
+	}
+	s.send(m)
+}
+
+func (s *server) event(name string, body any) {
+	s.send(&message{Type: "event", Event: name, Body: body})
+}
+
+func (s *server) handle(req *message) (any, error) {
+	args := func(x any) error {
+		if len(req.Arguments) == 0 {
+			return nil
+		}
+		return json.Unmarshal(req.Arguments, x)
+	}
+	switch req.Command {
+	case "initialize":
+		return map[string]any{
+			"supportsConfigurationDoneRequest": true,
+			"supportsFunctionBreakpoints":      true,
+			"supportsTerminateRequest":         true,
+			"exceptionBreakpointFilters": []map[string]any{{
+				"filter":      "errors",
+				"label":       "Errors",
+				"description": "Break when a value becomes an error.",
+			}},
+		}, nil
+
+	case "launch":
+		var a launchArgs
+		if err := args(&a); err != nil {
+			return nil, err
+		}
+		if a.Program == "" {
+			return nil, fmt.Errorf("launch: missing program")
+		}
+		s.mu.Lock()
+		s.launch = &a
+		s.mu.Unlock()
+		s.maybeStart()
+		return nil, nil
+
+	case "configurationDone":
+		s.mu.Lock()
+		s.configured = true
+		s.mu.Unlock()
+		s.maybeStart()
+		return nil, nil
+
+	case "setBreakpoints":
+		var a struct {
+			Source      source `json:"source"`
+			Breakpoints []struct {
+				Line int `json:"line"`
+			} `json:"breakpoints"`
+		}
+		if err := args(&a); err != nil {
+			return nil, err
+		}
+		lines := map[int]bool{}
+		bps := []breakpoint{}
+		for _, bp := range a.Breakpoints {
+			lines[bp.Line] = true
+			bps = append(bps, breakpoint{Verified: true, Line: bp.Line})
+		}
+		s.mu.Lock()
+		s.lineBPs[filepath.Clean(a.Source.Path)] = lines
+		s.mu.Unlock()
+		return map[string]any{"breakpoints": bps}, nil
+
+	case "setFunctionBreakpoints":
+		var a struct {
+			Breakpoints []struct {
+				Name string `json:"name"`
+			} `json:"breakpoints"`
+		}
+		if err := args(&a); err != nil {
+			return nil, err
+		}
+		var paths []cue.Path
+		bps := []breakpoint{}
+		for _, bp := range a.Breakpoints {
+			p := cue.ParsePath(bp.Name)
+			if err := p.Err(); err != nil {
+				bps = append(bps, breakpoint{Message: err.Error()})
+				continue
+			}
+			paths = append(paths, p)
+			bps = append(bps, breakpoint{Verified: true})
+		}
+		s.mu.Lock()
+		s.pathBPs = paths
+		s.mu.Unlock()
+		return map[string]any{"breakpoints": bps}, nil
+
+	case "setExceptionBreakpoints":
+		var a struct {
+			Filters []string `json:"filters"`
+		}
+		if err := args(&a); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.breakOnError = false
+		for _, f := range a.Filters {
+			if f == "errors" {
+				s.breakOnError = true
+			}
+		}
+		s.mu.Unlock()
+		return nil, nil
+
+	case "threads":
+		return map[string]any{"threads": []map[string]any{
+			{"id": threadID, "name": "evaluation"},
+		}}, nil
+
+	case "stackTrace":
+		return s.stackTrace()
+
+	case "scopes":
+		var a struct {
+			FrameID int `json:"frameId"`
+		}
+		if err := args(&a); err != nil {
+			return nil, err
+		}
+		return s.scopes(a.FrameID)
+
+	case "variables":
+		var a struct {
+			VariablesReference int `json:"variablesReference"`
+		}
+		if err := args(&a); err != nil {
+			return nil, err
+		}
+		return s.variables(a.VariablesReference)
+
+	case "continue":
+		return map[string]any{"allThreadsContinued": true}, s.continueWith(run)
+	case "next":
+		return nil, s.continueWith(stepOver)
+	case "stepIn":
+		return nil, s.continueWith(stepIn)
+	case "stepOut":
+		return nil, s.continueWith(stepOut)
+
+	case "pause":
+		s.mu.Lock()
+		s.mode = pause
+		s.mu.Unlock()
+		return nil, nil
+
+	case "disconnect", "terminate":
+		s.disconnect()
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported request %q", req.Command)
+}
+
+// maybeStart starts the evaluation once the program is known and the client
+// has finished configuring breakpoints.
+func (s *server) maybeStart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.launch == nil || !s.configured {
+		return
+	}
+	s.started = true
+	if s.launch.StopOnEntry {
+		s.mode = entry
+	}
+	go s.evaluate(*s.launch)
+}
+
+// evaluate loads and evaluates the program of a launch request and reports
+// its errors.
+func (s *server) evaluate(a launchArgs) {
+	dir := a.Cwd
+	if dir == "" {
+		dir = s.cfg.Dir
+	}
+	arg := a.Program
+	if !filepath.IsAbs(arg) && !strings.HasPrefix(arg, ".") {
+		arg = "./" + arg
+	}
+	insts := load.Instances([]string{arg}, &load.Config{Dir: dir, Tags: a.Tags})
+	var errs errors.Error
+	ctx := cuecontext.New()
+	// Only observe the evaluation of the program, and not, for instance,
+	// the evaluation done by the loader.
+	s.runtime = (*runtime.Runtime)(ctx)
+	adt.SetObserver(s)
+	for _, inst := range insts {
+		if inst.Err != nil {
+			errs = errors.Append(errs, inst.Err)
+			continue
+		}
+		v := ctx.BuildInstance(inst)
+		if err := v.Validate(); err != nil {
+			errs = errors.Append(errs, errors.Promote(err, ""))
+		}
+	}
+	code := 0
+	if errs != nil {
+		code = 1
+		s.event("output", map[string]any{
+			"category": "stderr",
+			"output":   errors.Details(errs, nil),
+		})
+	}
+	s.event("exited", map[string]any{"exitCode": code})
+	s.event("terminated", nil)
+}
+
+// disconnect stops pausing the evaluation and releases it if it is paused.
+func (s *server) disconnect() {
+	s.mu.Lock()
+	s.disconnected = true
+	paused := s.paused
+	s.paused = false
+	s.mu.Unlock()
+	if paused {
+		s.resume <- struct{}{}
+	}
+}
+
+func (s *server) continueWith(mode stepMode) error {
+	s.mu.Lock()
+	if !s.paused {
+		s.mu.Unlock()
+		return errNotPaused
+	}
+	s.paused = false
+	s.mode = mode
+	s.modeDepth = len(s.stack)
+	s.mu.Unlock()
+	s.resumeNow = true
+	return nil
+}
+
+var errNotPaused = fmt.Errorf("evaluation is not paused")
+
+func (s *server) isPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// EnterUnify implements adt.Observer.
+func (s *server) EnterUnify(c *adt.OpContext, v *adt.Vertex) {
+	if c.Runtime != s.runtime {
+		return
+	}
+	s.ctx = c
+	s.stack = append(s.stack, v)
+	if reason := s.stopReason(v); reason != "" {
+		s.stop(reason, "")
+	}
+}
+
+// ExitUnify implements adt.Observer.
+func (s *server) ExitUnify(c *adt.OpContext, v *adt.Vertex) {
+	if c.Runtime != s.runtime {
+		return
+	}
+	s.ctx = c
+	if b, ok := v.BaseValue.(*adt.Bottom); ok && b.Code < adt.IncompleteError && !b.ChildError {
+		s.mu.Lock()
+		brk := s.breakOnError && !s.disconnected
+		s.mu.Unlock()
+		// Vertices may be copied during evaluation, so report each error
+		// only once per path.
+		msg := errorString(b)
+		key := s.pathString(v) + "\x00" + msg
+		if brk && !s.reported[key] {
+			s.reported[key] = true
+			s.stop("exception", msg)
+		}
+	}
+	s.stack = s.stack[:len(s.stack)-1]
+}
+
+// Disjunctions implements adt.Observer.
+func (s *server) Disjunctions(c *adt.OpContext, v *adt.Vertex, d *adt.DisjunctionResult) {}
+
+// stopReason reports why evaluation should pause when starting to unify v,
+// or "" if it should not.
+func (s *server) stopReason(v *adt.Vertex) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.disconnected {
+		return ""
+	}
+	depth := len(s.stack)
+	switch s.mode {
+	case entry:
+		return "entry"
+	case pause:
+		return "pause"
+	case stepIn:
+		return "step"
+	case stepOver:
+		if depth <= s.modeDepth {
+			return "step"
+		}
+	case stepOut:
+		if depth < s.modeDepth {
+			return "step"
+		}
+	}
+	if len(s.pathBPs) > 0 {
+		labels := v.Path()
+		for _, p := range s.pathBPs {
+			if s.matchPath(p, labels) {
+				return "function breakpoint"
+			}
+		}
+	}
+	if len(s.lineBPs) > 0 {
+		for _, c := range v.Conjuncts {
+			src := c.Source()
+			if src == nil {
+				continue
+			}
+			pos := src.Pos()
+			if s.lineBPs[filepath.Clean(pos.Filename())][pos.Line()] {
+				return "breakpoint"
+			}
+		}
+	}
+	return ""
+}
+
+func (s *server) matchPath(p cue.Path, labels []adt.Feature) bool {
+	sels := p.Selectors()
+	if len(sels) != len(labels) {
+		return false
+	}
+	for i, sel := range sels {
+		if !sel.IsWildcard() && sel.String() != labels[i].SelectorString(s.ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// stop pauses the evaluation until the client resumes it.
+func (s *server) stop(reason, text string) {
+	s.vars = map[int]func() []variable{}
+	s.mu.Lock()
+	if s.disconnected {
+		s.mu.Unlock()
+		return
+	}
+	s.mode = run
+	s.paused = true
+	s.mu.Unlock()
+	body := map[string]any{
+		"reason":            reason,
+		"threadId":          threadID,
+		"allThreadsStopped": true,
+	}
+	if text != "" {
+		body["text"] = text
+	}
+	s.event("stopped", body)
+	<-s.resume
+}
+
+// frame returns the vertex of the stack frame with the given id, where 1 is
+// the innermost frame.
+func (s *server) frame(id int) (*adt.Vertex, error) {
+	if !s.isPaused() {
+		return nil, errNotPaused
+	}
+	i := len(s.stack) - id
+	if id < 1 || i < 0 {
+		return nil, fmt.Errorf("invalid frame %d", id)
+	}
+	return s.stack[i], nil
+}
+
+func (s *server) stackTrace() (any, error) {
+	if !s.isPaused() {
+		return nil, errNotPaused
+	}
+	frames := []map[string]any{}
+	for id := 1; id <= len(s.stack); id++ {
+		v := s.stack[len(s.stack)-id]
+		f := map[string]any{
+			"id":     id,
+			"name":   s.pathString(v),
+			"line":   0,
+			"column": 0,
+		}
+		if pos := vertexPos(v); pos.IsValid() {
+			f["source"] = source{Name: filepath.Base(pos.Filename()), Path: pos.Filename()}
+			f["line"] = pos.Line()
+			f["column"] = pos.Column()
+		}
+		frames = append(frames, f)
+	}
+	return map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, nil
+}
+
+func (s *server) scopes(frameID int) (any, error) {
+	v, err := s.frame(frameID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"scopes": []map[string]any{{
+		"name":               "Vertex",
+		"variablesReference": s.ref(func() []variable { return s.vertexVars(v) }),
+		"expensive":          false,
+	}}}, nil
+}
+
+func (s *server) variables(ref int) (any, error) {
+	if !s.isPaused() {
+		return nil, errNotPaused
+	}
+	f, ok := s.vars[ref]
+	if !ok {
+		return nil, fmt.Errorf("invalid variables reference %d", ref)
+	}
+	return map[string]any{"variables": f()}, nil
+}
+
+// ref registers f as the variables of a new reference.
+func (s *server) ref(f func() []variable) int {
+	n := len(s.vars) + 1
+	s.vars[n] = f
+	return n
+}
+
+// vertexVars returns the variables describing v.
+func (s *server) vertexVars(v *adt.Vertex) []variable {
+	vars := []variable{
+		{Name: "path", Value: s.pathString(v), Type: "string"},
+		{Name: "status", Value: v.Status().String(), Type: "string"},
+		s.valueVar("value", v),
+	}
+	conjuncts := v.Conjuncts
+	vars = append(vars, variable{
+		Name:  "conjuncts",
+		Value: fmt.Sprintf("%d conjuncts", len(conjuncts)),
+		VariablesReference: s.ref(func() []variable {
+			var a []variable
+			for i, c := range conjuncts {
+				x := s.nodeString(c.Expr())
+				if src := c.Source(); src != nil && src.Pos().IsValid() {
+					x += " // " + src.Pos().String()
+				}
+				a = append(a, variable{Name: fmt.Sprintf("[%d]", i), Value: x})
+			}
+			return a
+		}),
+	})
+	arcs := v.Arcs
+	vars = append(vars, variable{
+		Name:  "arcs",
+		Value: fmt.Sprintf("%d arcs", len(arcs)),
+		VariablesReference: s.ref(func() []variable {
+			var a []variable
+			for _, arc := range arcs {
+				arc := arc
+				x := s.valueVar(arc.Label.SelectorString(s.ctx), arc)
+				x.VariablesReference = s.ref(func() []variable { return s.vertexVars(arc) })
+				a = append(a, x)
+			}
+			return a
+		}),
+	})
+	return vars
+}
+
+func (s *server) valueVar(name string, v *adt.Vertex) variable {
+	if b, ok := v.BaseValue.(*adt.Bottom); ok {
+		return variable{Name: name, Value: errorString(b), Type: "error"}
+	}
+	return variable{Name: name, Value: s.nodeString(v), Type: v.Kind().String()}
+}
+
+// nodeString formats n compactly. The value of a vertex that is being
+// evaluated may be inconsistent, so failures to format are reported as
+// such rather than ending the session.
+func (s *server) nodeString(n adt.Node) (str string) {
+	defer func() {
+		if recover() != nil {
+			str = "<unavailable>"
+		}
+	}()
+	return debug.NodeString(s.ctx, n, &debug.Config{Compact: true})
+}
+
+func (s *server) pathString(v *adt.Vertex) string {
+	var b strings.Builder
+	for _, f := range v.Path() {
+		if f.IsInt() {
+			fmt.Fprintf(&b, "[%d]", f.Index())
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(f.SelectorString(s.ctx))
+	}
+	if b.Len() == 0 {
+		return "<root>"
+	}
+	return b.String()
+}
+
+func errorString(b *adt.Bottom) string {
+	if b.Err == nil {
+		return "_|_"
+	}
+	return "_|_ // " + b.Err.Error()
+}
+
+// vertexPos returns the position of the first conjunct of v that has one.
+func vertexPos(v *adt.Vertex) (pos token.Pos) {
+	for _, c := range v.Conjuncts {
+		if src := c.Source(); src != nil && src.Pos().IsValid() {
+			return src.Pos()
+		}
+	}
+	return pos
+}
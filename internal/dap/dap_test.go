@@ -0,0 +1,250 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+const program = `package p
+
+a: {
+	b: 1
+	c: b + 1
+}
+d: a.c & 3
+`
+
+// A client drives a server in tests.
+type client struct {
+	t      *testing.T
+	w      io.Writer
+	r      *bufio.Reader
+	seq    int
+	events []*message
+	done   chan error
+}
+
+func newClient(t *testing.T) (*client, string) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "x.cue"), []byte(program), 0o666)
+	qt.Assert(t, qt.IsNil(err))
+
+	sr, cw := io.Pipe()
+	cr, sw := io.Pipe()
+	c := &client{t: t, w: cw, r: bufio.NewReader(cr), done: make(chan error, 1)}
+	go func() {
+		c.done <- Serve(sr, sw, &Config{Dir: dir})
+		sw.Close()
+	}()
+	return c, dir
+}
+
+func (c *client) request(command string, args any) *message {
+	c.t.Helper()
+	c.seq++
+	b, err := json.Marshal(map[string]any{
+		"seq":       c.seq,
+		"type":      "request",
+		"command":   command,
+		"arguments": args,
+	})
+	qt.Assert(c.t, qt.IsNil(err))
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	qt.Assert(c.t, qt.IsNil(err))
+	for {
+		m := c.read()
+		if m.Type == "event" {
+			c.events = append(c.events, m)
+			continue
+		}
+		qt.Assert(c.t, qt.Equals(m.RequestSeq, c.seq))
+		qt.Assert(c.t, qt.Equals(m.Command, command))
+		return m
+	}
+}
+
+// call sends a request that must succeed and decodes the body of the
+// response into body, if not nil.
+func (c *client) call(command string, args, body any) {
+	c.t.Helper()
+	m := c.request(command, args)
+	qt.Assert(c.t, qt.IsTrue(*m.Success), qt.Commentf("%s: %s", command, m.Message))
+	if body != nil {
+		b, err := json.Marshal(m.Body)
+		qt.Assert(c.t, qt.IsNil(err))
+		qt.Assert(c.t, qt.IsNil(json.Unmarshal(b, body)))
+	}
+}
+
+func (c *client) read() *message {
+	c.t.Helper()
+	m, err := readMessage(c.r)
+	qt.Assert(c.t, qt.IsNil(err))
+	return m
+}
+
+// event returns the body of the next event, which must have the given name.
+func (c *client) event(name string) map[string]any {
+	c.t.Helper()
+	var m *message
+	if len(c.events) > 0 {
+		m, c.events = c.events[0], c.events[1:]
+	} else {
+		m = c.read()
+	}
+	qt.Assert(c.t, qt.Equals(m.Event, name), qt.Commentf("body: %v", m.Body))
+	body, _ := m.Body.(map[string]any)
+	return body
+}
+
+type frame struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Line int    `json:"line"`
+}
+
+func (c *client) stack() []frame {
+	c.t.Helper()
+	var st struct {
+		StackFrames []frame `json:"stackFrames"`
+	}
+	c.call("stackTrace", map[string]any{"threadId": threadID}, &st)
+	return st.StackFrames
+}
+
+func (c *client) variables(ref int) map[string]variable {
+	c.t.Helper()
+	var vs struct {
+		Variables []variable `json:"variables"`
+	}
+	c.call("variables", map[string]any{"variablesReference": ref}, &vs)
+	m := map[string]variable{}
+	for _, v := range vs.Variables {
+		m[v.Name] = v
+	}
+	return m
+}
+
+func (c *client) frameVars(id int) map[string]variable {
+	c.t.Helper()
+	var sc struct {
+		Scopes []struct {
+			VariablesReference int `json:"variablesReference"`
+		} `json:"scopes"`
+	}
+	c.call("scopes", map[string]any{"frameId": id}, &sc)
+	qt.Assert(c.t, qt.HasLen(sc.Scopes, 1))
+	return c.variables(sc.Scopes[0].VariablesReference)
+}
+
+func TestBreakpoints(t *testing.T) {
+	c, dir := newClient(t)
+	c.call("initialize", map[string]any{"adapterID": "cue"}, nil)
+	c.event("initialized")
+	c.call("launch", map[string]any{"program": "."}, nil)
+	c.call("setBreakpoints", map[string]any{
+		"source":      map[string]any{"path": filepath.Join(dir, "x.cue")},
+		"breakpoints": []map[string]any{{"line": 4}},
+	}, nil)
+	c.call("setFunctionBreakpoints", map[string]any{
+		"breakpoints": []map[string]any{{"name": "a.c"}},
+	}, nil)
+	c.call("setExceptionBreakpoints", map[string]any{"filters": []string{"errors"}}, nil)
+
+	m := c.request("continue", nil)
+	qt.Assert(t, qt.IsFalse(*m.Success))
+	qt.Assert(t, qt.Equals(m.Message, "evaluation is not paused"))
+
+	c.call("configurationDone", nil, nil)
+
+	// Collect the stops until the error is reported.
+	stops := map[string]string{}
+	for {
+		ev := c.event("stopped")
+		reason := ev["reason"].(string)
+		st := c.stack()
+		qt.Assert(t, qt.Equals(st[len(st)-1].Name, "<root>"))
+		stops[st[0].Name] = reason
+		if reason == "exception" {
+			qt.Assert(t, qt.Matches(ev["text"].(string), `_\|_ // d: conflicting values 3 and 2.*`))
+			vars := c.frameVars(1)
+			qt.Assert(t, qt.Equals(vars["path"].Value, "d"))
+			qt.Assert(t, qt.Equals(vars["value"].Type, "error"))
+			c.call("continue", nil, nil)
+			break
+		}
+		if st[0].Name == "a.c" {
+			vars := c.frameVars(1)
+			qt.Assert(t, qt.Equals(vars["conjuncts"].Value, "1 conjuncts"))
+			conj := c.variables(vars["conjuncts"].VariablesReference)
+			qt.Assert(t, qt.Matches(conj["[0]"].Value, `\(b \+ 1\) // .*x.cue:5:2`))
+			qt.Assert(t, qt.Equals(st[0].Line, 5))
+		}
+		c.call("continue", nil, nil)
+	}
+	qt.Assert(t, qt.DeepEquals(stops, map[string]string{
+		"a.b": "breakpoint",
+		"a.c": "function breakpoint",
+		"d":   "exception",
+	}))
+
+	out := c.event("output")
+	qt.Assert(t, qt.Matches(out["output"].(string), `d: conflicting values 3 and 2:\n(?s).*`))
+	qt.Assert(t, qt.Equals(c.event("exited")["exitCode"], any(1.0)))
+	c.event("terminated")
+	c.call("disconnect", nil, nil)
+	qt.Assert(t, qt.IsNil(<-c.done))
+}
+
+func TestStep(t *testing.T) {
+	c, _ := newClient(t)
+	c.call("initialize", nil, nil)
+	c.event("initialized")
+	c.call("launch", map[string]any{"program": ".", "stopOnEntry": true}, nil)
+	c.call("configurationDone", nil, nil)
+
+	qt.Assert(t, qt.Equals(c.event("stopped")["reason"], any("entry")))
+	st := c.stack()
+	qt.Assert(t, qt.HasLen(st, 1))
+	qt.Assert(t, qt.Equals(st[0].Name, "<root>"))
+
+	vars := c.frameVars(1)
+	qt.Assert(t, qt.Equals(vars["status"].Value, "unprocessed"))
+
+	c.call("stepIn", nil, nil)
+	qt.Assert(t, qt.Equals(c.event("stopped")["reason"], any("step")))
+	qt.Assert(t, qt.HasLen(c.stack(), 2))
+
+	// The root is now being evaluated and its arcs are known.
+	vars = c.frameVars(2)
+	qt.Assert(t, qt.Equals(vars["status"].Value, "evaluatingArcs"))
+	arcs := c.variables(vars["arcs"].VariablesReference)
+	qt.Assert(t, qt.Not(qt.Equals(arcs["a"].VariablesReference, 0)))
+	a := c.variables(arcs["a"].VariablesReference)
+	qt.Assert(t, qt.Equals(a["path"].Value, "a"))
+
+	// Disconnecting releases the paused evaluation.
+	c.call("disconnect", nil, nil)
+	qt.Assert(t, qt.IsNil(<-c.done))
+}
@@ -1,6 +1,6 @@
 module test/e2e
 
-go 1.20
+go 1.21
 
 require (
 	cuelang.org/go v0.0.0-00010101000000-000000000000
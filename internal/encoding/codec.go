@@ -0,0 +1,58 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"io"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// A Codec implements a custom encoding. The options given in the qualifier
+// selecting the encoding, such as indent=2 in "mycodec+indent=2", are
+// passed in the Tags field of the file.
+type Codec struct {
+	// Decode decodes the contents of r, read from the file f, as a single
+	// value. It is nil if the encoding cannot be read.
+	Decode func(f *build.File, r io.Reader) (ast.Expr, error)
+
+	// Encode writes v to w, to be written to the file f. It is nil if the
+	// encoding cannot be written.
+	Encode func(f *build.File, w io.Writer, v cue.Value) error
+}
+
+var codecs sync.Map // map[build.Encoding]Codec
+
+// RegisterCodec registers c as the codec of the custom encoding with the
+// given name, which can then be selected with a qualifier of the same name.
+func RegisterCodec(name string, c Codec) error {
+	if err := filetypes.RegisterEncoding(name); err != nil {
+		return err
+	}
+	codecs.Store(build.Encoding(name), c)
+	return nil
+}
+
+func lookupCodec(enc build.Encoding) (Codec, bool) {
+	c, ok := codecs.Load(enc)
+	if !ok {
+		return Codec{}, false
+	}
+	return c.(Codec), true
+}
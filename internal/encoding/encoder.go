@@ -22,6 +22,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
@@ -33,8 +35,11 @@ import (
 	"cuelang.org/go/encoding/protobuf/jsonpb"
 	"cuelang.org/go/encoding/protobuf/textproto"
 	"cuelang.org/go/internal"
+	cueyaml "cuelang.org/go/internal/encoding/yaml"
 	"cuelang.org/go/internal/filetypes"
 	"cuelang.org/go/pkg/encoding/yaml"
+	"cuelang.org/go/tools/incomplete"
+	"cuelang.org/go/tools/redact"
 )
 
 // An Encoder converts CUE to various file formats, including CUE itself.
@@ -48,6 +53,14 @@ type Encoder struct {
 	autoSimplify bool
 	concrete     bool
 	instance     *cue.Instance
+
+	// sources is set while encoding a value for which provenance comments
+	// are written.
+	sources *sources
+
+	// manifest is set for the k8smanifest interpretation, in which case
+	// values are collected and only written when the Encoder is closed.
+	manifest *k8sManifest
 }
 
 // IsConcrete reports whether the output is required to be concrete.
@@ -60,6 +73,13 @@ func (e *Encoder) IsConcrete() bool {
 }
 
 func (e Encoder) Close() error {
+	if e.manifest != nil {
+		for _, o := range e.manifest.sorted() {
+			if err := e.encValue(o.v); err != nil {
+				return err
+			}
+		}
+	}
 	if e.close == nil {
 		return nil
 	}
@@ -82,12 +102,32 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 	case build.OpenAPI:
 		// TODO: get encoding options
 		cfg := &openapi.Config{}
+		var dropped errors.Error
+		if e.cfg.Strict {
+			// Constraints that cannot be represented make the mapping
+			// lossy, which is an error in strict mode.
+			cfg.ReportDropped = func(d openapi.DroppedConstraint) {
+				dropped = errors.Append(dropped, errors.Newf(d.Pos,
+					"%v: cannot represent %s in OpenAPI: %s", d.Path, d.Constraint, d.Reason))
+			}
+		}
 		e.interpret = func(v cue.Value) (*ast.File, error) {
 			i := e.instance
 			if i == nil {
 				i = internal.MakeInstance(v).(*cue.Instance)
 			}
-			return openapi.Generate(i, cfg)
+			dropped = nil
+			f, err := openapi.Generate(i, cfg)
+			if err == nil && dropped != nil {
+				err = dropped
+			}
+			return f, err
+		}
+	case build.KubernetesManifest:
+		e.manifest = &k8sManifest{}
+	case build.Patch:
+		if e.interpret, err = patchInterpreter(cfg); err != nil {
+			return nil, err
 		}
 	case build.ProtobufJSON:
 		e.interpret = func(v cue.Value) (*ast.File, error) {
@@ -105,6 +145,10 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 		return nil, fmt.Errorf("unsupported interpretation %q", f.Interpretation)
 	}
 
+	if cfg.Provenance && (f.Interpretation != "" || f.Encoding != build.CUE && f.Encoding != build.YAML) {
+		return nil, fmt.Errorf("provenance comments are only supported for cue and yaml output")
+	}
+
 	switch f.Encoding {
 	case build.CUE:
 		fi, err := filetypes.FromFile(f, cfg.Mode)
@@ -157,14 +201,33 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			return err
 		}
 		e.encValue = func(v cue.Value) error {
-			return format("", v.Syntax(synOpts...))
+			n := v.Syntax(synOpts...)
+			if cfg.Redact != nil {
+				var err error
+				if n, err = redact.Syntax(v, cfg.Redact, synOpts...); err != nil {
+					return err
+				}
+			}
+			sortFields(n, v, cfg.FieldOrder)
+			if e.sources != nil {
+				e.sources.annotate(n, nil, false)
+			}
+			return format("", n)
 		}
 		e.encFile = func(f *ast.File) error { return format(f.Filename, f) }
 
 	case build.JSON, build.JSONL:
 		e.concrete = true
+		indent := "    "
+		if s, ok := f.Tags["indent"]; ok {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid indent %q: must be a non-negative number", s)
+			}
+			indent = strings.Repeat(" ", n)
+		}
 		d := json.NewEncoder(w)
-		d.SetIndent("", "    ")
+		d.SetIndent("", indent)
 		d.SetEscapeHTML(cfg.EscapeHTML)
 		e.encValue = func(v cue.Value) error {
 			err := d.Encode(v)
@@ -183,6 +246,17 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			}
 			streamed = true
 
+			if e.sources != nil {
+				n := v.Syntax(cue.Final(), cue.Concrete(true))
+				e.sources.annotate(n, nil, true)
+				b, err := cueyaml.Encode(n)
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(b)
+				return err
+			}
+
 			str, err := yaml.Marshal(v)
 			if err != nil {
 				return err
@@ -205,6 +279,17 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			return err
 		}
 
+	case build.Tree:
+		e.encValue = func(v cue.Value) error {
+			return writeTree(w, v, cfg.TreeDepth)
+		}
+
+	case build.Env, build.Flags:
+		e.concrete = true
+		e.encValue = func(v cue.Value) error {
+			return writeKeyValues(w, v, f.Encoding, cfg.Keys)
+		}
+
 	case build.Text:
 		e.concrete = true
 		e.encValue = func(v cue.Value) error {
@@ -232,7 +317,14 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 		}
 
 	default:
-		return nil, fmt.Errorf("unsupported encoding %q", f.Encoding)
+		c, ok := lookupCodec(f.Encoding)
+		if !ok || c.Encode == nil {
+			return nil, fmt.Errorf("unsupported encoding %q", f.Encoding)
+		}
+		e.concrete = true
+		e.encValue = func(v cue.Value) error {
+			return c.Encode(f, w, v)
+		}
 	}
 
 	return e, nil
@@ -254,9 +346,40 @@ func (e *Encoder) EncodeInstance(v *cue.Instance) error {
 
 func (e *Encoder) Encode(v cue.Value) error {
 	e.autoSimplify = true
-	if err := v.Validate(cue.Concrete(e.concrete)); err != nil {
+	var omit []cue.Path
+	if e.concrete && e.cfg.Incomplete != nil {
+		var err error
+		if omit, err = incomplete.Check(v, e.cfg.Incomplete); err != nil {
+			return err
+		}
+	} else if err := v.Validate(cue.Concrete(e.concrete)); err != nil {
 		return err
 	}
+	if e.cfg.Provenance {
+		// Positions are collected before v is rewritten below.
+		e.sources = collectSources(v)
+		defer func() { e.sources = nil }()
+	}
+	if e.encFile == nil || e.interpret != nil || e.manifest != nil {
+		// CUE output is ordered and redacted when generating its syntax,
+		// which preserves values that are not concrete. Fields are ordered
+		// first, as doing so relies on the schema of v.
+		var err error
+		if v, err = orderValue(v, e.cfg.FieldOrder); err != nil {
+			return err
+		}
+		if e.cfg.Redact != nil {
+			if v, err = redact.Value(v, e.cfg.Redact); err != nil {
+				return err
+			}
+		}
+	}
+	if len(omit) > 0 {
+		var err error
+		if v, err = incomplete.Remove(v, omit); err != nil {
+			return err
+		}
+	}
 	if e.interpret != nil {
 		f, err := e.interpret(v)
 		if err != nil {
@@ -264,6 +387,9 @@ func (e *Encoder) Encode(v cue.Value) error {
 		}
 		return e.encodeFile(f, nil)
 	}
+	if e.manifest != nil {
+		return e.manifest.add(v)
+	}
 	if e.encValue != nil {
 		return e.encValue(v)
 	}
@@ -287,6 +413,9 @@ func (e *Encoder) encodeFile(f *ast.File, interpret func(cue.Value) (*ast.File,
 	if err := v.Validate(cue.Concrete(e.concrete)); err != nil {
 		return err
 	}
+	if e.manifest != nil {
+		return e.manifest.add(v)
+	}
 	return e.encValue(v)
 }
 
@@ -39,9 +39,12 @@ import (
 	"cuelang.org/go/encoding/protobuf"
 	"cuelang.org/go/encoding/protobuf/jsonpb"
 	"cuelang.org/go/encoding/protobuf/textproto"
+	"cuelang.org/go/encoding/sops"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/filetypes"
 	"cuelang.org/go/internal/third_party/yaml"
+	"cuelang.org/go/tools/incomplete"
+	"cuelang.org/go/tools/redact"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
@@ -162,6 +165,50 @@ type Config struct {
 	ProtoPath     []string
 	Format        []format.Option
 	ParseFile     func(name string, src interface{}) (*ast.File, error)
+
+	// Options for extracting OpenAPI; see the fields of [openapi.Config]
+	// of the same name.
+	OpenAPISelectSchemas    []string
+	OpenAPISelectOperations []string
+	OpenAPIComponentsOnly   bool
+	OpenAPIDefinitionName   func(schema string) string
+
+	// TreeDepth limits the depth of tree output, if positive.
+	TreeDepth int
+
+	// Keys configures how values are flattened for the env and flags
+	// encodings.
+	Keys *KeyConfig
+
+	// FieldOrder determines the order in which fields are written.
+	FieldOrder FieldOrder
+
+	// Redact, if non-nil, replaces sensitive values with a placeholder
+	// after validation. See package [redact].
+	Redact *redact.Config
+
+	// Incomplete, if non-nil, allows the values it selects to be incomplete
+	// when the output is required to be concrete. Such values are omitted
+	// from the output. See package [incomplete].
+	Incomplete *incomplete.Config
+
+	// Provenance adds a comment to each field and list element of CUE and
+	// YAML output listing the files and lines that declare it. Files
+	// within the current directory are listed relative to it.
+	Provenance bool
+
+	// Patch configures the patch interpretation.
+	Patch *PatchConfig
+
+	// YAML, if non-nil, configures how plain YAML scalars with a type
+	// that differs between YAML 1.1 and YAML 1.2 are decoded.
+	YAML *yaml.Config
+
+	// RejectEncrypted reports an error when decoding a JSON or YAML file
+	// that is encrypted with SOPS, instead of decoding its ciphertext.
+	// As SOPS records its metadata at the end of a file, such files are
+	// read completely before they are decoded.
+	RejectEncrypted bool
 }
 
 // NewDecoder returns a stream of non-rooted data expressions. The encoding
@@ -199,7 +246,7 @@ func NewDecoder(f *build.File, cfg *Config) *Decoder {
 	// TODO: this code also allows UTF16, which is too permissive for some
 	// encodings. Switch to unicode.UTF8Sig once available.
 	t := unicode.BOMOverride(unicode.UTF8.NewDecoder())
-	r := transform.NewReader(rc, t)
+	var r io.Reader = transform.NewReader(rc, t)
 
 	switch f.Interpretation {
 	case "":
@@ -241,10 +288,20 @@ func NewDecoder(f *build.File, cfg *Config) *Decoder {
 			i.doInterpret()
 		}
 	case build.JSON, build.JSONL:
+		if f.Encoding == build.JSON && cfg.RejectEncrypted {
+			if r, i.err = rejectEncrypted(path, r, sops.JSON); i.err != nil {
+				break
+			}
+		}
 		i.next = json.NewDecoder(nil, path, r).Extract
 		i.Next()
 	case build.YAML:
-		d, err := yaml.NewDecoder(path, r)
+		if cfg.RejectEncrypted {
+			if r, i.err = rejectEncrypted(path, r, sops.YAML); i.err != nil {
+				break
+			}
+		}
+		d, err := yaml.NewDecoderConfig(path, r, cfg.YAML)
 		i.err = err
 		i.next = d.Decode
 		i.Next()
@@ -271,7 +328,12 @@ func NewDecoder(f *build.File, cfg *Config) *Decoder {
 			i.expr, i.err = d.Parse(cfg.Schema, path, b)
 		}
 	default:
-		i.err = fmt.Errorf("unsupported encoding %q", f.Encoding)
+		c, ok := lookupCodec(f.Encoding)
+		if !ok || c.Decode == nil {
+			i.err = fmt.Errorf("unsupported encoding %q", f.Encoding)
+			break
+		}
+		i.expr, i.err = c.Decode(f, r)
 	}
 
 	return i
@@ -305,7 +367,13 @@ func jsonSchemaFunc(cfg *Config, f *build.File) interpretFunc {
 }
 
 func openAPIFunc(c *Config, f *build.File) interpretFunc {
-	cfg := &openapi.Config{PkgName: c.PkgName}
+	cfg := &openapi.Config{
+		PkgName:          c.PkgName,
+		SelectSchemas:    c.OpenAPISelectSchemas,
+		SelectOperations: c.OpenAPISelectOperations,
+		ComponentsOnly:   c.OpenAPIComponentsOnly,
+		DefinitionName:   c.OpenAPIDefinitionName,
+	}
 	return func(i *cue.Instance) (file *ast.File, id string, err error) {
 		file, err = openapi.Extract(i, cfg)
 		// TODO: simplify currently erases file line info. Reintroduce after fix.
@@ -324,6 +392,20 @@ func protobufJSONFunc(cfg *Config, file *build.File) rewriteFunc {
 	}
 }
 
+// rejectEncrypted reads all of r and reports an error if it is a document
+// encrypted with SOPS. Such documents must be decrypted when they are
+// loaded, as decoding them would yield their ciphertext.
+func rejectEncrypted(path string, r io.Reader, format sops.Format) (io.Reader, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if sops.IsEncrypted(b, format) {
+		return nil, fmt.Errorf("%s: file is encrypted with SOPS and cannot be decoded without decrypting it", path)
+	}
+	return bytes.NewReader(b), nil
+}
+
 func reader(f *build.File, stdin io.Reader) (io.ReadCloser, error) {
 	switch s := f.Source.(type) {
 	case nil:
@@ -0,0 +1,287 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+)
+
+// A KeyCase determines how the labels of flattened keys are cased.
+type KeyCase string
+
+const (
+	// PreserveCase keeps labels as they are.
+	PreserveCase KeyCase = "preserve"
+
+	// UpperCase converts labels to upper case.
+	UpperCase KeyCase = "upper"
+
+	// LowerCase converts labels to lower case.
+	LowerCase KeyCase = "lower"
+)
+
+// A Quoting determines how values are quoted in env and flags output.
+type Quoting string
+
+const (
+	// ShellQuoting quotes values with single quotes if they contain
+	// characters that are special to a POSIX shell. It is the default.
+	ShellQuoting Quoting = "shell"
+
+	// DoubleQuoting always quotes values with double quotes, escaping
+	// backslashes, double quotes, dollar signs, backquotes, and
+	// newlines, as expected by most dotenv loaders.
+	DoubleQuoting Quoting = "double"
+
+	// NoQuoting writes values as is. Values may not contain newlines.
+	NoQuoting Quoting = "none"
+)
+
+// KeyConfig configures how values are flattened into key-value pairs for
+// the env and flags encodings.
+type KeyConfig struct {
+	// Prefix is prepended to all keys.
+	Prefix string
+
+	// Separator joins the labels of a path. It defaults to "_" for env
+	// and "." for flags.
+	Separator string
+
+	// Case determines the case of labels. It defaults to UpperCase for
+	// env and PreserveCase for flags.
+	Case KeyCase
+
+	// Quote determines how values are quoted. It defaults to
+	// ShellQuoting.
+	Quote Quoting
+}
+
+// ParseKeyCase returns the KeyCase named by s.
+func ParseKeyCase(s string) (KeyCase, error) {
+	switch c := KeyCase(s); c {
+	case PreserveCase, UpperCase, LowerCase:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid key case %q: must be preserve, upper, or lower", s)
+}
+
+// ParseQuoting returns the Quoting named by s.
+func ParseQuoting(s string) (Quoting, error) {
+	switch q := Quoting(s); q {
+	case ShellQuoting, DoubleQuoting, NoQuoting:
+		return q, nil
+	}
+	return "", fmt.Errorf("invalid quoting %q: must be shell, double, or none", s)
+}
+
+// keyValueWriter writes a value as flattened key-value pairs, one per
+// line.
+type keyValueWriter struct {
+	w        io.Writer
+	encoding build.Encoding
+	cfg      KeyConfig
+	err      error
+}
+
+// writeKeyValues writes the fields of the struct v to w as KEY=VALUE
+// pairs for the env encoding, or as --key=value arguments for the flags
+// encoding. Nested structs and lists are flattened by joining their
+// labels and indices with the configured separator. For flags, a list of
+// scalars is written as a repeated argument.
+func writeKeyValues(w io.Writer, v cue.Value, enc build.Encoding, cfg *KeyConfig) error {
+	kv := &keyValueWriter{w: w, encoding: enc}
+	if cfg != nil {
+		kv.cfg = *cfg
+	}
+	if kv.cfg.Separator == "" {
+		kv.cfg.Separator = "_"
+		if enc == build.Flags {
+			kv.cfg.Separator = "."
+		}
+	}
+	if kv.cfg.Case == "" {
+		kv.cfg.Case = UpperCase
+		if enc == build.Flags {
+			kv.cfg.Case = PreserveCase
+		}
+	}
+	if kv.cfg.Quote == "" {
+		kv.cfg.Quote = ShellQuoting
+	}
+	if v.IncompleteKind() != cue.StructKind {
+		return fmt.Errorf("%s output requires a struct, found %v", enc, v.IncompleteKind())
+	}
+	kv.value(kv.cfg.Prefix, v)
+	return kv.err
+}
+
+func (kv *keyValueWriter) join(key, label string) string {
+	switch kv.cfg.Case {
+	case UpperCase:
+		label = strings.ToUpper(label)
+	case LowerCase:
+		label = strings.ToLower(label)
+	}
+	if kv.encoding == build.Env {
+		label = strings.Map(func(r rune) rune {
+			switch {
+			case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '_':
+				return r
+			}
+			return '_'
+		}, label)
+	}
+	if key == "" {
+		return label
+	}
+	return key + kv.cfg.Separator + label
+}
+
+func (kv *keyValueWriter) value(key string, v cue.Value) {
+	if kv.err != nil {
+		return
+	}
+	switch v.Kind() {
+	case cue.StructKind:
+		iter, err := v.Fields()
+		if err != nil {
+			kv.err = err
+			return
+		}
+		for iter.Next() {
+			kv.value(kv.join(key, iter.Selector().Unquoted()), iter.Value())
+		}
+
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			kv.err = err
+			return
+		}
+		if kv.encoding == build.Flags && isScalarList(v) {
+			for iter.Next() {
+				kv.pair(key, iter.Value())
+			}
+			return
+		}
+		for i := 0; iter.Next(); i++ {
+			kv.value(kv.join(key, strconv.Itoa(i)), iter.Value())
+		}
+
+	default:
+		kv.pair(key, v)
+	}
+}
+
+func isScalarList(v cue.Value) bool {
+	iter, _ := v.List()
+	for iter.Next() {
+		switch iter.Value().Kind() {
+		case cue.StructKind, cue.ListKind:
+			return false
+		}
+	}
+	return true
+}
+
+// pair writes a line for the scalar v at key.
+func (kv *keyValueWriter) pair(key string, v cue.Value) {
+	s, err := scalarString(v)
+	if err != nil {
+		kv.err = err
+		return
+	}
+	switch kv.cfg.Quote {
+	case ShellQuoting:
+		s = shellQuote(s)
+	case DoubleQuoting:
+		s = doubleQuote(s)
+	default:
+		if strings.ContainsAny(s, "\r\n") {
+			kv.err = fmt.Errorf("value of %s contains a newline, which requires quoting", key)
+			return
+		}
+	}
+	if kv.encoding == build.Flags {
+		key = "--" + key
+	}
+	_, kv.err = fmt.Fprintf(kv.w, "%s=%s\n", key, s)
+}
+
+// scalarString returns the string representation of v. Strings are
+// written as is, null as the empty string, and other values as in JSON.
+func scalarString(v cue.Value) (string, error) {
+	switch v.Kind() {
+	case cue.StringKind:
+		return v.String()
+	case cue.NullKind:
+		return "", nil
+	}
+	b, err := v.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	if v.Kind() == cue.BytesKind {
+		var s string
+		err := json.Unmarshal(b, &s)
+		return s, err
+	}
+	return string(b), nil
+}
+
+// shellQuote quotes s with single quotes if it contains characters other
+// than those that are never special to a POSIX shell.
+func shellQuote(s string) string {
+	safe := s != ""
+	for _, r := range s {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		case strings.ContainsRune("@%+=:,./_-", r):
+		default:
+			safe = false
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func doubleQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\', '"', '$', '`':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
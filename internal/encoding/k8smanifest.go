@@ -0,0 +1,156 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"sort"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// k8sKindOrder lists the order in which Kubernetes objects of a given
+// kind are emitted, so that objects are created before the objects that
+// depend on them. Kinds not listed here are emitted after those that are,
+// ordered by name. The order follows the one used by Helm.
+var k8sKindOrder = map[string]int{}
+
+func init() {
+	for i, kind := range []string{
+		"Namespace",
+		"NetworkPolicy",
+		"ResourceQuota",
+		"LimitRange",
+		"PodSecurityPolicy",
+		"PodDisruptionBudget",
+		"ServiceAccount",
+		"Secret",
+		"SecretList",
+		"ConfigMap",
+		"StorageClass",
+		"PersistentVolume",
+		"PersistentVolumeClaim",
+		"CustomResourceDefinition",
+		"ClusterRole",
+		"ClusterRoleList",
+		"ClusterRoleBinding",
+		"ClusterRoleBindingList",
+		"Role",
+		"RoleList",
+		"RoleBinding",
+		"RoleBindingList",
+		"Service",
+		"DaemonSet",
+		"Pod",
+		"ReplicationController",
+		"ReplicaSet",
+		"Deployment",
+		"HorizontalPodAutoscaler",
+		"StatefulSet",
+		"Job",
+		"CronJob",
+		"IngressClass",
+		"Ingress",
+		"APIService",
+	} {
+		k8sKindOrder[kind] = i
+	}
+}
+
+// k8sManifest collects the Kubernetes objects passed to an Encoder so that
+// they can be emitted in a stable order when the Encoder is closed.
+type k8sManifest struct {
+	objects []k8sObject
+}
+
+type k8sObject struct {
+	kind      string
+	namespace string
+	name      string
+	v         cue.Value
+}
+
+// add adds the Kubernetes objects in v. The value may be an object, a list
+// of values, a List object, or a struct of which the fields are values.
+func (m *k8sManifest) add(v cue.Value) error {
+	switch v.IncompleteKind() {
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			if err := m.add(iter.Value()); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case cue.StructKind:
+		kind, err := v.LookupPath(cue.MakePath(cue.Str("kind"))).String()
+		if err != nil || !v.LookupPath(cue.MakePath(cue.Str("apiVersion"))).Exists() {
+			// Not an object: collect the objects in its fields.
+			iter, err := v.Fields()
+			if err != nil {
+				return err
+			}
+			for iter.Next() {
+				if err := m.add(iter.Value()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if items := v.LookupPath(cue.MakePath(cue.Str("items"))); kind == "List" && items.Exists() {
+			return m.add(items)
+		}
+		meta := v.LookupPath(cue.MakePath(cue.Str("metadata")))
+		name, _ := meta.LookupPath(cue.MakePath(cue.Str("name"))).String()
+		namespace, _ := meta.LookupPath(cue.MakePath(cue.Str("namespace"))).String()
+		m.objects = append(m.objects, k8sObject{
+			kind:      kind,
+			namespace: namespace,
+			name:      name,
+			v:         v,
+		})
+		return nil
+	}
+	return errors.Newf(v.Pos(), "%v: value is not a Kubernetes object", v.Path())
+}
+
+// sorted returns the collected objects ordered by kind, namespace and name.
+// Objects that compare equal retain the order in which they were added.
+func (m *k8sManifest) sorted() []k8sObject {
+	a := m.objects
+	sort.SliceStable(a, func(i, j int) bool {
+		x, y := a[i], a[j]
+		if x.kind != y.kind {
+			ox, okx := k8sKindOrder[x.kind]
+			oy, oky := k8sKindOrder[y.kind]
+			switch {
+			case okx && oky:
+				return ox < oy
+			case okx != oky:
+				return okx
+			}
+			return x.kind < y.kind
+		}
+		if x.namespace != y.namespace {
+			return x.namespace < y.namespace
+		}
+		return x.name < y.name
+	})
+	return a
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+)
+
+// A FieldOrder determines the order in which the fields of a struct are
+// written.
+type FieldOrder string
+
+const (
+	// SourceOrder writes fields in the order in which they are first
+	// declared, which is the order of the evaluated value. It is the
+	// default.
+	SourceOrder FieldOrder = "source"
+
+	// AlphabeticalOrder writes fields sorted by their label.
+	AlphabeticalOrder FieldOrder = "alpha"
+
+	// SchemaOrder writes the fields declared by the definitions a struct
+	// was unified with in the order of these definitions, followed by any
+	// other fields in source order.
+	SchemaOrder FieldOrder = "schema"
+)
+
+// ParseFieldOrder returns the FieldOrder named by s.
+func ParseFieldOrder(s string) (FieldOrder, error) {
+	switch o := FieldOrder(s); o {
+	case SourceOrder, AlphabeticalOrder, SchemaOrder:
+		return o, nil
+	}
+	return "", fmt.Errorf("invalid field order %q: must be source, alpha, or schema", s)
+}
+
+// orderValue returns a concrete copy of v with its fields ordered as
+// specified by order.
+func orderValue(v cue.Value, order FieldOrder) (cue.Value, error) {
+	if order == "" || order == SourceOrder {
+		return v, nil
+	}
+	n := v.Syntax(cue.Final(), cue.Concrete(true))
+	sortFields(n, v, order)
+	var w cue.Value
+	switch x := n.(type) {
+	case *ast.File:
+		w = v.Context().BuildFile(x)
+	case ast.Expr:
+		w = v.Context().BuildExpr(x)
+	default:
+		return v, fmt.Errorf("unexpected syntax %T", n)
+	}
+	return w, w.Err()
+}
+
+// sortFields reorders the fields of the structs within n, which is the
+// syntax of v, as specified by order.
+func sortFields(n ast.Node, v cue.Value, order FieldOrder) {
+	if order == "" || order == SourceOrder {
+		return
+	}
+	switch x := n.(type) {
+	case *ast.File:
+		sortDecls(x.Decls, v, order)
+	case *ast.StructLit:
+		sortDecls(x.Elts, v, order)
+	case *ast.EmbedDecl:
+		sortFields(x.Expr, v, order)
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			sortFields(e, v.LookupPath(cue.MakePath(cue.Index(i))), order)
+		}
+	}
+}
+
+func sortDecls(decls []ast.Decl, v cue.Value, order FieldOrder) {
+	type field struct {
+		name string
+		decl ast.Decl
+	}
+	var fields []field
+	var slots []int
+	for i, d := range decls {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			sortFields(d, v, order)
+			continue
+		}
+		name, _, err := ast.LabelName(f.Label)
+		if err != nil {
+			continue
+		}
+		switch {
+		case f.Constraint != token.ILLEGAL, strings.HasPrefix(name, "_"):
+		case strings.HasPrefix(name, "#"):
+			sortFields(f.Value, v.LookupPath(cue.MakePath(cue.Def(name))), order)
+		default:
+			sortFields(f.Value, v.LookupPath(cue.MakePath(cue.Str(name))), order)
+		}
+		fields = append(fields, field{name, d})
+		slots = append(slots, i)
+	}
+
+	switch order {
+	case AlphabeticalOrder:
+		sort.SliceStable(fields, func(i, j int) bool {
+			return fields[i].name < fields[j].name
+		})
+	case SchemaOrder:
+		rank := schemaOrder(v)
+		pos := func(name string) int {
+			if r, ok := rank[name]; ok {
+				return r
+			}
+			return len(rank)
+		}
+		sort.SliceStable(fields, func(i, j int) bool {
+			return pos(fields[i].name) < pos(fields[j].name)
+		})
+	}
+	for i, f := range fields {
+		decls[slots[i]] = f.decl
+	}
+}
+
+// schemaOrder returns the position of each field declared by the closed
+// structs that make up v.
+func schemaOrder(v cue.Value) map[string]int {
+	conjuncts := []cue.Value{v}
+	if op, args := v.Expr(); op == cue.AndOp {
+		conjuncts = args
+	}
+	rank := map[string]int{}
+	for _, c := range conjuncts {
+		if !c.IsClosed() {
+			continue
+		}
+		iter, err := c.Fields(cue.Optional(true))
+		if err != nil {
+			continue
+		}
+		for iter.Next() {
+			name := iter.Selector().Unquoted()
+			if _, ok := rank[name]; !ok {
+				rank[name] = len(rank)
+			}
+		}
+	}
+	return rank
+}
@@ -0,0 +1,347 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+)
+
+// A PatchType selects the kind of patch written for the patch
+// interpretation.
+type PatchType string
+
+const (
+	// MergePatch is a JSON Merge Patch, as defined by RFC 7386.
+	MergePatch PatchType = "merge"
+
+	// StrategicMergePatch is a Kubernetes strategic merge patch, which
+	// merges lists of objects by the value of a key field rather than
+	// replacing them.
+	StrategicMergePatch PatchType = "strategic"
+)
+
+// ParsePatchType parses the name of a patch type.
+func ParsePatchType(s string) (PatchType, error) {
+	switch t := PatchType(s); t {
+	case MergePatch, StrategicMergePatch:
+		return t, nil
+	}
+	return "", fmt.Errorf("invalid patch type %q: must be merge or strategic", s)
+}
+
+// A PatchConfig configures the patch interpretation, which writes the
+// changes from a previous version of a document to the encoded value.
+type PatchConfig struct {
+	// Base is the file holding the previous version of the document.
+	Base *build.File
+
+	// Type is the kind of patch to write. It defaults to MergePatch.
+	Type PatchType
+
+	// MergeKey is the field that identifies the elements of lists of
+	// objects in a strategic merge patch. It defaults to "name".
+	MergeKey string
+}
+
+// patchInterpreter returns a function that converts a value to a patch
+// against the document in cfg.Patch.Base. Only a single value may be
+// converted, as there is only one base document.
+func patchInterpreter(cfg *Config) (func(cue.Value) (*ast.File, error), error) {
+	pc := cfg.Patch
+	if pc == nil || pc.Base == nil {
+		return nil, fmt.Errorf("patch output requires a previous version of the document")
+	}
+	p := &patcher{
+		strategic: pc.Type == StrategicMergePatch,
+		key:       pc.MergeKey,
+	}
+	if p.key == "" {
+		p.key = "name"
+	}
+	done := false
+	return func(v cue.Value) (*ast.File, error) {
+		if done {
+			return nil, fmt.Errorf("patch output requires a single value")
+		}
+		done = true
+		base, err := decodeBase(v.Context(), pc.Base, cfg)
+		if err != nil {
+			return nil, err
+		}
+		x, err := p.patch(base, v)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.File{Decls: []ast.Decl{&ast.EmbedDecl{Expr: x}}}, nil
+	}, nil
+}
+
+// decodeBase decodes the single document in f.
+func decodeBase(ctx *cue.Context, f *build.File, cfg *Config) (cue.Value, error) {
+	d := NewDecoder(f, &Config{Mode: cfg.Mode, Stdin: cfg.Stdin})
+	defer d.Close()
+	if err := d.Err(); err != nil {
+		return cue.Value{}, err
+	}
+	file := d.File()
+	d.Next()
+	if !d.Done() {
+		return cue.Value{}, fmt.Errorf("%s: previous version must be a single document", f.Filename)
+	}
+	if err := d.Err(); err != nil {
+		return cue.Value{}, err
+	}
+	v := ctx.BuildFile(file)
+	return v, v.Validate(cue.Concrete(true))
+}
+
+type patcher struct {
+	strategic bool
+	key       string
+}
+
+// patch returns the patch that changes old into v.
+func (p *patcher) patch(old, v cue.Value) (ast.Expr, error) {
+	if v.Kind() != cue.StructKind || old.Kind() != cue.StructKind {
+		// A patch that is not an object replaces the document.
+		return p.value(v)
+	}
+	x, err := p.diffStruct(old, v)
+	if x == nil && err == nil {
+		x = ast.NewStruct()
+	}
+	return x, err
+}
+
+// diff returns the patch that changes old into v, or nil if they are equal.
+func (p *patcher) diff(old, v cue.Value) (ast.Expr, error) {
+	if old.Kind() == cue.StructKind && v.Kind() == cue.StructKind {
+		return p.diffStruct(old, v)
+	}
+	if old.Equals(v) {
+		return nil, nil
+	}
+	return p.value(v)
+}
+
+// diffStruct returns the patch that changes the struct old into the struct
+// v, or nil if they are equal.
+func (p *patcher) diffStruct(old, v cue.Value) (ast.Expr, error) {
+	var fields []interface{}
+	iter, err := v.Fields()
+	if err != nil {
+		return nil, err
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		label := sel.Unquoted()
+		f := iter.Value()
+		o := old.LookupPath(cue.MakePath(sel))
+		if f.Kind() == cue.NullKind {
+			if o.Exists() && o.Kind() == cue.NullKind {
+				continue
+			}
+			return nil, nullFieldError(f)
+		}
+		if !o.Exists() {
+			x, err := p.value(f)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, ast.NewString(label), x)
+			continue
+		}
+		if p.strategic {
+			x, order, ok, err := p.diffList(o, f)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				if x != nil {
+					fields = append(fields,
+						ast.NewString("$setElementOrder/"+label), order,
+						ast.NewString(label), x)
+				}
+				continue
+			}
+		}
+		x, err := p.diff(o, f)
+		if err != nil {
+			return nil, err
+		}
+		if x != nil {
+			fields = append(fields, ast.NewString(label), x)
+		}
+	}
+
+	iter, err = old.Fields()
+	if err != nil {
+		return nil, err
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		if !v.LookupPath(cue.MakePath(sel)).Exists() {
+			fields = append(fields, ast.NewString(sel.Unquoted()), ast.NewNull())
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return ast.NewStruct(fields...), nil
+}
+
+// diffList returns the strategic merge patch that changes the list old into
+// the list v, along with the order of the elements of v, or nil if they are
+// equal. It reports false if the elements of both lists are not objects
+// identified by a unique merge key, in which case v replaces old.
+func (p *patcher) diffList(old, v cue.Value) (x, order ast.Expr, ok bool, err error) {
+	oldKeys, oldElems, ok := p.keyed(old)
+	if !ok {
+		return nil, nil, false, nil
+	}
+	keys, elems, ok := p.keyed(v)
+	if !ok {
+		return nil, nil, false, nil
+	}
+
+	byKey := map[string]cue.Value{}
+	for i, k := range oldKeys {
+		byKey[k] = oldElems[i]
+	}
+	var patches, orderElems []ast.Expr
+	changed := len(keys) != len(oldKeys)
+	seen := map[string]bool{}
+	for i, k := range keys {
+		seen[k] = true
+		e := elems[i]
+		keyExpr, err := p.value(e.LookupPath(cue.MakePath(cue.Str(p.key))))
+		if err != nil {
+			return nil, nil, false, err
+		}
+		orderElems = append(orderElems, ast.NewStruct(ast.NewString(p.key), keyExpr))
+		if !changed && oldKeys[i] != k {
+			changed = true
+		}
+
+		o, ok := byKey[k]
+		if !ok {
+			x, err := p.value(e)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			patches = append(patches, x)
+			continue
+		}
+		x, err := p.diffStruct(o, e)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if x != nil {
+			s := x.(*ast.StructLit)
+			s.Elts = append([]ast.Decl{&ast.Field{Label: ast.NewString(p.key), Value: keyExpr}}, s.Elts...)
+			patches = append(patches, s)
+		}
+	}
+	for i, k := range oldKeys {
+		if seen[k] {
+			continue
+		}
+		keyExpr, err := p.value(oldElems[i].LookupPath(cue.MakePath(cue.Str(p.key))))
+		if err != nil {
+			return nil, nil, false, err
+		}
+		patches = append(patches, ast.NewStruct(
+			ast.NewString(p.key), keyExpr,
+			ast.NewString("$patch"), ast.NewString("delete"),
+		))
+	}
+	if len(patches) == 0 && !changed {
+		return nil, nil, true, nil
+	}
+	return ast.NewList(patches...), ast.NewList(orderElems...), true, nil
+}
+
+// keyed returns the elements of the list v and the values of their merge
+// keys. It reports false if v is not a list of objects with unique, scalar
+// merge keys.
+func (p *patcher) keyed(v cue.Value) (keys []string, elems []cue.Value, ok bool) {
+	if v.Kind() != cue.ListKind {
+		return nil, nil, false
+	}
+	iter, err := v.List()
+	if err != nil {
+		return nil, nil, false
+	}
+	seen := map[string]bool{}
+	for iter.Next() {
+		e := iter.Value()
+		if e.Kind() != cue.StructKind {
+			return nil, nil, false
+		}
+		k := e.LookupPath(cue.MakePath(cue.Str(p.key)))
+		switch k.Kind() {
+		case cue.StringKind, cue.IntKind, cue.FloatKind, cue.BoolKind:
+		default:
+			return nil, nil, false
+		}
+		s := fmt.Sprint(k)
+		if seen[s] {
+			return nil, nil, false
+		}
+		seen[s] = true
+		keys = append(keys, s)
+		elems = append(elems, e)
+	}
+	return keys, elems, len(elems) > 0
+}
+
+// value returns the syntax for v as part of a patch. Fields with a null
+// value cannot be represented, as null deletes a field in a patch.
+func (p *patcher) value(v cue.Value) (ast.Expr, error) {
+	if err := checkNoNullFields(v); err != nil {
+		return nil, err
+	}
+	x, _ := v.Syntax(cue.Final(), cue.Concrete(true)).(ast.Expr)
+	return x, nil
+}
+
+func checkNoNullFields(v cue.Value) error {
+	if v.Kind() != cue.StructKind {
+		return nil
+	}
+	iter, err := v.Fields()
+	if err != nil {
+		return err
+	}
+	for iter.Next() {
+		f := iter.Value()
+		if f.Kind() == cue.NullKind {
+			return nullFieldError(f)
+		}
+		if err := checkNoNullFields(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func nullFieldError(v cue.Value) error {
+	return errors.Newf(v.Pos(), "%v: cannot set a field to null in a patch", v.Path())
+}
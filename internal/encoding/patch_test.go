@@ -0,0 +1,103 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+)
+
+func TestPatch(t *testing.T) {
+	testCases := []struct {
+		name      string
+		old, new  string
+		strategic bool
+		want      string
+		err       string
+	}{{
+		name: "equal",
+		old:  `{"a": 1, "b": [1, 2]}`,
+		new:  `{a: 1, b: [1, 2]}`,
+		want: `{}`,
+	}, {
+		name: "changed",
+		old:  `{"a": 1, "b": {"c": 1, "d": 2}, "e": "x"}`,
+		new:  `{a: 2, b: {c: 1, d: 3, f: 4}}`,
+		want: `{"a":2,"b":{"d":3,"f":4},"e":null}`,
+	}, {
+		name: "listReplaced",
+		old:  `{"l": [{"name": "a", "v": 1}]}`,
+		new:  `{l: [{name: "a", v: 2}]}`,
+		want: `{"l":[{"name":"a","v":2}]}`,
+	}, {
+		name:      "listMerged",
+		old:       `{"l": [{"name": "a", "v": 1}, {"name": "b", "v": 1}]}`,
+		new:       `{l: [{name: "a", v: 2}, {name: "c", v: 1}]}`,
+		strategic: true,
+		want:      `{"$setElementOrder/l":[{"name":"a"},{"name":"c"}],"l":[{"name":"a","v":2},{"name":"c","v":1},{"name":"b","$patch":"delete"}]}`,
+	}, {
+		name:      "listReordered",
+		old:       `{"l": [{"name": "a"}, {"name": "b"}]}`,
+		new:       `{l: [{name: "b"}, {name: "a"}]}`,
+		strategic: true,
+		want:      `{"$setElementOrder/l":[{"name":"b"},{"name":"a"}],"l":[]}`,
+	}, {
+		name:      "listWithoutKeys",
+		old:       `{"l": [1, 2]}`,
+		new:       `{l: [1, 3]}`,
+		strategic: true,
+		want:      `{"l":[1,3]}`,
+	}, {
+		name: "scalar",
+		old:  `{"a": 1}`,
+		new:  `"x"`,
+		want: `"x"`,
+	}, {
+		name: "null",
+		old:  `{"a": 1}`,
+		new:  `{a: {b: null}}`,
+		err:  "a.b: cannot set a field to null in a patch",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			p := &patcher{strategic: tc.strategic, key: "name"}
+			x, err := p.patch(ctx.CompileString(tc.old), ctx.CompileString(tc.new))
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("got error %v; want %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := format.Node(x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(ctx.CompileBytes(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got  %s\nwant %s", got, tc.want)
+			}
+		})
+	}
+}
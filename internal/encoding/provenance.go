@@ -0,0 +1,191 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/value"
+)
+
+// sources records where the fields of a value are declared, so that the
+// output can be annotated with them after the value has been rewritten,
+// for instance by ordering or redacting its fields, which loses the
+// original positions.
+type sources struct {
+	cwd string
+
+	// lines maps paths, relative to the collected value, to the locations
+	// of the declarations of their fields, formatted as file:line.
+	lines map[string][]string
+}
+
+// collectSources records the locations of the declarations of all regular
+// fields and definitions within v, as well as of list elements.
+func collectSources(v cue.Value) *sources {
+	s := &sources{lines: map[string][]string{}}
+	s.cwd, _ = os.Getwd()
+	s.collect(v, nil)
+	return s
+}
+
+func (s *sources) collect(v cue.Value, path []cue.Selector) {
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := v.Fields(cue.Definitions(true))
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			s.add(iter.Value(), append(path, iter.Selector()))
+		}
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return
+		}
+		for i := 0; iter.Next(); i++ {
+			s.add(iter.Value(), append(path, cue.Index(i)))
+		}
+	}
+}
+
+func (s *sources) add(v cue.Value, path []cue.Selector) {
+	path = path[:len(path):len(path)]
+	_, vertex := value.ToInternal(v)
+	pos := appendConjunctPositions(nil, vertex.Conjuncts)
+	sort.Slice(pos, func(i, j int) bool {
+		if pos[i].Filename() != pos[j].Filename() {
+			return pos[i].Filename() < pos[j].Filename()
+		}
+		return pos[i].Line() < pos[j].Line()
+	})
+
+	var lines []string
+	for _, p := range pos {
+		line := fmt.Sprintf("%s:%d", s.filename(p.Filename()), p.Line())
+		if len(lines) == 0 || lines[len(lines)-1] != line {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > 0 {
+		s.lines[cue.MakePath(path...).String()] = lines
+	}
+	s.collect(v, path)
+}
+
+// appendConjunctPositions appends the positions of the declarations of
+// the given conjuncts.
+func appendConjunctPositions(a []token.Pos, conjuncts []adt.Conjunct) []token.Pos {
+	for _, c := range conjuncts {
+		if g, ok := c.Elem().(*adt.ConjunctGroup); ok {
+			a = appendConjunctPositions(a, *g)
+			continue
+		}
+		if src := c.Source(); src != nil && src.Pos().IsValid() && src.Pos().Filename() != "" {
+			a = append(a, src.Pos())
+		}
+	}
+	return a
+}
+
+// filename returns name relative to the current directory, if it is within
+// it.
+func (s *sources) filename(name string) string {
+	if s.cwd == "" || !filepath.IsAbs(name) {
+		return name
+	}
+	if rel, err := filepath.Rel(s.cwd, name); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return name
+}
+
+// annotate adds a line comment listing the locations of its declarations to
+// each field and list element within n, which is the syntax of the value
+// for which s was collected.
+//
+// If onLabel is set, the comments of fields with a struct or list value are
+// attached to their labels, so that they are written on the same line in
+// YAML.
+func (s *sources) annotate(n ast.Node, path []cue.Selector, onLabel bool) {
+	switch x := n.(type) {
+	case *ast.File:
+		s.annotateDecls(x.Decls, path, onLabel)
+	case *ast.StructLit:
+		s.annotateDecls(x.Elts, path, onLabel)
+	case *ast.EmbedDecl:
+		s.annotate(x.Expr, path, onLabel)
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			p := append(path[:len(path):len(path)], cue.Index(i))
+			if s.addComment(e, p) {
+				// Line comments would otherwise be followed by the next
+				// element on the same line.
+				ast.SetRelPos(e, token.Newline)
+			}
+			s.annotate(e, p, onLabel)
+		}
+	}
+}
+
+func (s *sources) annotateDecls(decls []ast.Decl, path []cue.Selector, onLabel bool) {
+	for _, d := range decls {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			s.annotate(d, path, onLabel)
+			continue
+		}
+		name, _, err := ast.LabelName(f.Label)
+		if err != nil || f.Constraint != token.ILLEGAL || strings.HasPrefix(name, "_") {
+			continue
+		}
+		sel := cue.Str(name)
+		if strings.HasPrefix(name, "#") {
+			sel = cue.Def(name)
+		}
+		p := append(path[:len(path):len(path)], sel)
+		var target ast.Node = f
+		switch f.Value.(type) {
+		case *ast.StructLit, *ast.ListLit:
+			if onLabel {
+				target = f.Label
+			}
+		}
+		s.addComment(target, p)
+		s.annotate(f.Value, p, onLabel)
+	}
+}
+
+func (s *sources) addComment(n ast.Node, path []cue.Selector) bool {
+	lines := s.lines[cue.MakePath(path...).String()]
+	if len(lines) == 0 {
+		return false
+	}
+	ast.AddComment(n, &ast.CommentGroup{
+		Line:     true,
+		Position: 10,
+		List:     []*ast.Comment{{Text: "// " + strings.Join(lines, ", ")}},
+	})
+	return true
+}
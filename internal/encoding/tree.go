@@ -0,0 +1,187 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// treeWriter renders a value as an indented tree, with a line for each
+// field and list element describing its kind and, if concrete, its value.
+type treeWriter struct {
+	w     io.Writer
+	depth int // maximum depth; 0 means unlimited
+	err   error
+}
+
+// writeTree writes v as a tree to w, descending at most depth levels if
+// depth is positive.
+func writeTree(w io.Writer, v cue.Value, depth int) error {
+	t := &treeWriter{w: w, depth: depth}
+	if children := t.children(v); children != nil {
+		for _, c := range children {
+			t.node("", "", c, 1)
+		}
+	} else {
+		t.printf("%s\n", describe(v, 0))
+	}
+	return t.err
+}
+
+type treeNode struct {
+	label string
+	v     cue.Value
+}
+
+func (t *treeWriter) printf(format string, args ...interface{}) {
+	if t.err == nil {
+		_, t.err = fmt.Fprintf(t.w, format, args...)
+	}
+}
+
+// node writes the line for n, which is preceded by prefix and connector,
+// followed by its children.
+func (t *treeWriter) node(prefix, connector string, n treeNode, depth int) {
+	children := t.children(n.v)
+	hidden := 0
+	if t.depth > 0 && depth >= t.depth {
+		hidden, children = len(children), nil
+	}
+	t.printf("%s%s%s: %s\n", prefix, connector, n.label, describe(n.v, hidden))
+
+	switch connector {
+	case "├── ":
+		prefix += "│   "
+	case "└── ":
+		prefix += "    "
+	}
+	for i, c := range children {
+		connector := "├── "
+		if i == len(children)-1 {
+			connector = "└── "
+		}
+		t.node(prefix, connector, c, depth+1)
+	}
+}
+
+// children returns the fields of a struct, including definitions and
+// optional fields, or the elements of a list.
+func (t *treeWriter) children(v cue.Value) []treeNode {
+	if v.Err() != nil {
+		return nil
+	}
+	var a []treeNode
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := v.Fields(cue.Definitions(true), cue.Optional(true))
+		if err != nil {
+			return nil
+		}
+		for iter.Next() {
+			a = append(a, treeNode{iter.Selector().String(), iter.Value()})
+		}
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return nil
+		}
+		for i := 0; iter.Next(); i++ {
+			a = append(a, treeNode{fmt.Sprint(i), iter.Value()})
+		}
+	}
+	return a
+}
+
+// describe returns a one-line description of v. Structs that are not
+// closed and lists that are open are marked with an ellipsis. If hidden
+// is positive, the children of v are not shown.
+func describe(v cue.Value, hidden int) string {
+	if err := v.Err(); err != nil {
+		msg := errors.Details(err, nil)
+		if strings.Contains(msg, "structural cycle") {
+			// Recursive definitions can only be expanded this far.
+			return "(recursive)"
+		}
+		msg, _, _ = strings.Cut(strings.TrimSpace(msg), "\n")
+		return "_|_ // " + msg
+	}
+
+	kind := v.IncompleteKind()
+	var b strings.Builder
+	b.WriteString(kind.String())
+	switch kind {
+	case cue.StructKind:
+		if !v.IsClosed() {
+			b.WriteString(" {...}")
+		}
+		switch {
+		case hidden == 1:
+			b.WriteString(" (1 field)")
+		case hidden > 1:
+			fmt.Fprintf(&b, " (%d fields)", hidden)
+		}
+		return b.String()
+
+	case cue.ListKind:
+		n, err := v.Len().Int64()
+		if err != nil {
+			n, _ = countElems(v)
+			fmt.Fprintf(&b, " [%d, ...]", n)
+		} else {
+			fmt.Fprintf(&b, " [%d]", n)
+		}
+		return b.String()
+	}
+
+	if d, ok := v.Default(); ok && d.IsConcrete() {
+		fmt.Fprintf(&b, " = %s (default)", oneLine(d))
+		return b.String()
+	}
+	if v.IsConcrete() {
+		fmt.Fprintf(&b, " = %s", oneLine(v))
+		return b.String()
+	}
+	b.WriteString(" (incomplete)")
+	if s := oneLine(v); s != kind.String() {
+		fmt.Fprintf(&b, " %s", s)
+	}
+	return b.String()
+}
+
+func countElems(v cue.Value) (int64, error) {
+	iter, err := v.List()
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for iter.Next() {
+		n++
+	}
+	return n, nil
+}
+
+// oneLine formats v on a single line.
+func oneLine(v cue.Value) string {
+	s := fmt.Sprint(v)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " ..."
+	}
+	return s
+}
@@ -0,0 +1,373 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package evaltrace records the evaluation of CUE values and renders it as a
+// self-contained HTML page for offline inspection.
+//
+// The trace is organized as a tree of vertices, identified by their paths.
+// Vertices that are not part of a larger value, such as the root of a
+// package, an imported package, or a struct literal within an expression,
+// each start a tree of their own. For each vertex, the trace holds
+//
+//   - the times it was unified, with their durations and the vertex whose
+//     evaluation triggered it;
+//   - its conjuncts and their positions;
+//   - the outcome of resolving its disjunctions, including the errors of
+//     the eliminated disjuncts;
+//   - its final value.
+package evaltrace
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/debug"
+	"cuelang.org/go/internal/core/runtime"
+)
+
+// Config configures a Recorder.
+type Config struct {
+	// Dir, if set, is the directory relative to which the names of files
+	// are shown.
+	Dir string
+}
+
+// A Recorder records the evaluation of values of a single cue.Context. It
+// implements adt.Observer and should be installed with adt.SetObserver.
+type Recorder struct {
+	runtime adt.Runtime
+	dir     string
+	start   time.Time
+
+	mu     sync.Mutex
+	roots  []*node
+	trees  map[*adt.Vertex]*node
+	stacks map[*adt.OpContext][]frame
+	stats  stats
+}
+
+// New returns a Recorder for the evaluation of values of ctx.
+func New(ctx *cue.Context, cfg *Config) *Recorder {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	return &Recorder{
+		runtime: (*runtime.Runtime)(ctx),
+		dir:     cfg.Dir,
+		start:   time.Now(),
+		trees:   map[*adt.Vertex]*node{},
+		stacks:  map[*adt.OpContext][]frame{},
+	}
+}
+
+// stats summarizes a trace.
+type stats struct {
+	Vertices     int   `json:"vertices"`
+	Evaluations  int   `json:"evaluations"`
+	Disjunctions int   `json:"disjunctions"`
+	Duration     int64 `json:"duration"`
+}
+
+// A node holds the trace of the vertices with a given path. Durations are
+// in nanoseconds and start times are relative to the creation of the
+// Recorder.
+type node struct {
+	Label        string        `json:"label"`
+	Path         string        `json:"path"`
+	Children     []*node       `json:"children,omitempty"`
+	Evals        []evaluation  `json:"evals,omitempty"`
+	Conjuncts    []conjunct    `json:"conjuncts,omitempty"`
+	Disjunctions []disjunction `json:"disjunctions,omitempty"`
+	Value        string        `json:"value,omitempty"`
+	Error        bool          `json:"error,omitempty"`
+
+	// Total is the time spent unifying the vertices of the node, including
+	// the evaluation of other vertices triggered by it. Self excludes the
+	// latter.
+	Total int64 `json:"total"`
+	Self  int64 `json:"self"`
+
+	children  map[string]*node
+	conjuncts map[conjunct]bool
+}
+
+type evaluation struct {
+	Start    int64  `json:"start"`
+	Duration int64  `json:"duration"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+
+	// By is the path of the vertex whose unification led to this one, if
+	// any.
+	By string `json:"by,omitempty"`
+}
+
+type conjunct struct {
+	Expr string `json:"expr"`
+	Pos  string `json:"pos,omitempty"`
+}
+
+type disjunction struct {
+	Start     int64    `json:"start"`
+	Disjuncts []string `json:"disjuncts"`
+	Default   []bool   `json:"default"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// A frame is an unfinished unification of a vertex.
+type frame struct {
+	node  *node
+	eval  int // index in node.Evals
+	start time.Time
+	child time.Duration // time spent in nested frames
+}
+
+// EnterUnify implements adt.Observer.
+func (r *Recorder) EnterUnify(c *adt.OpContext, v *adt.Vertex) {
+	if c.Runtime != r.runtime {
+		return
+	}
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.node(c, v)
+	stack := r.stacks[c]
+	e := evaluation{
+		Start: int64(now.Sub(r.start)),
+		From:  v.Status().String(),
+	}
+	if len(stack) > 0 {
+		e.By = stack[len(stack)-1].node.Path
+	}
+	n.Evals = append(n.Evals, e)
+	r.stacks[c] = append(stack, frame{node: n, eval: len(n.Evals) - 1, start: now})
+	r.stats.Evaluations++
+}
+
+// ExitUnify implements adt.Observer.
+func (r *Recorder) ExitUnify(c *adt.OpContext, v *adt.Vertex) {
+	if c.Runtime != r.runtime {
+		return
+	}
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stack := r.stacks[c]
+	f := stack[len(stack)-1]
+	stack = stack[:len(stack)-1]
+	if len(stack) == 0 {
+		delete(r.stacks, c)
+	} else {
+		r.stacks[c] = stack
+	}
+
+	d := now.Sub(f.start)
+	n := f.node
+	e := &n.Evals[f.eval]
+	e.Duration = int64(d)
+	e.To = v.Status().String()
+
+	// Recursive unification of the same path is already accounted for by
+	// the outer frame.
+	recursive := false
+	for _, g := range stack {
+		recursive = recursive || g.node == n
+	}
+	if !recursive {
+		n.Total += int64(d)
+	}
+	n.Self += int64(d - f.child)
+	if len(stack) > 0 {
+		stack[len(stack)-1].child += d
+	} else {
+		r.stats.Duration += int64(d)
+	}
+
+	for _, x := range v.Conjuncts {
+		cj := conjunct{Expr: abbrev(nodeString(c, x.Expr()))}
+		if src := x.Source(); src != nil {
+			cj.Pos = r.posString(src.Pos())
+		}
+		if !n.conjuncts[cj] {
+			n.conjuncts[cj] = true
+			n.Conjuncts = append(n.Conjuncts, cj)
+		}
+	}
+	n.Value, n.Error = valueString(c, v)
+}
+
+// Disjunctions implements adt.Observer.
+func (r *Recorder) Disjunctions(c *adt.OpContext, v *adt.Vertex, d *adt.DisjunctionResult) {
+	if c.Runtime != r.runtime {
+		return
+	}
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	x := disjunction{
+		Start:     int64(now.Sub(r.start)),
+		Disjuncts: []string{},
+		Default:   d.Default,
+	}
+	for _, v := range d.Disjuncts {
+		x.Disjuncts = append(x.Disjuncts, abbrev(nodeString(c, v)))
+	}
+	for _, b := range d.Errs {
+		x.Errors = append(x.Errors, errorString(b))
+	}
+	n := r.node(c, v)
+	n.Disjunctions = append(n.Disjunctions, x)
+	r.stats.Disjunctions++
+}
+
+// node returns the node for the path of v, creating it if needed.
+func (r *Recorder) node(c *adt.OpContext, v *adt.Vertex) *node {
+	var path []*adt.Vertex
+	top := v
+	for ; top.Parent != nil; top = top.Parent {
+		path = append(path, top)
+	}
+	n := r.trees[top]
+	if n == nil {
+		n = r.newNode(r.treeLabel(top), "")
+		r.trees[top] = n
+		r.roots = append(r.roots, n)
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		f := path[i].Label
+		label := f.SelectorString(c)
+		child := n.children[label]
+		if child == nil {
+			p := n.Path
+			switch {
+			case f.IsInt():
+				p += "[" + label + "]"
+			case p == "":
+				p = label
+			default:
+				p += "." + label
+			}
+			child = r.newNode(label, p)
+			n.children[label] = child
+			n.Children = append(n.Children, child)
+		}
+		n = child
+	}
+	return n
+}
+
+func (r *Recorder) newNode(label, path string) *node {
+	r.stats.Vertices++
+	return &node{
+		Label:     label,
+		Path:      path,
+		children:  map[string]*node{},
+		conjuncts: map[conjunct]bool{},
+	}
+}
+
+// treeLabel names the tree rooted at v after the origin of its first
+// conjunct.
+func (r *Recorder) treeLabel(v *adt.Vertex) string {
+	for _, c := range v.Conjuncts {
+		src := c.Source()
+		if src == nil || !src.Pos().IsValid() {
+			continue
+		}
+		if _, ok := src.(*ast.File); ok {
+			return r.fileName(src.Pos().Filename())
+		}
+		return r.posString(src.Pos())
+	}
+	return "<anonymous>"
+}
+
+func (r *Recorder) posString(pos token.Pos) string {
+	if !pos.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", r.fileName(pos.Filename()), pos.Line(), pos.Column())
+}
+
+func (r *Recorder) fileName(name string) string {
+	if r.dir == "" || !filepath.IsAbs(name) {
+		return name
+	}
+	if rel, err := filepath.Rel(r.dir, name); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return name
+}
+
+// valueString describes the value of v and reports whether it is an error.
+// The values of structs and lists are not shown, as those of their elements
+// are part of the trace.
+func valueString(c *adt.OpContext, v *adt.Vertex) (string, bool) {
+	switch x := v.BaseValue.(type) {
+	case *adt.Bottom:
+		// Errors of children are reported with the children.
+		return errorString(x), !x.ChildError
+	case *adt.StructMarker:
+		return fmt.Sprintf("struct with %d fields", len(v.Arcs)), false
+	case *adt.ListMarker:
+		return fmt.Sprintf("list with %d elements", len(v.Arcs)), false
+	case adt.Node:
+		return abbrev(nodeString(c, x)), false
+	}
+	return "", false
+}
+
+func errorString(b *adt.Bottom) string {
+	if b.Err == nil {
+		return "_|_"
+	}
+	return b.Err.Error()
+}
+
+func nodeString(c *adt.OpContext, n adt.Node) (str string) {
+	defer func() {
+		if recover() != nil {
+			str = "<unavailable>"
+		}
+	}()
+	return debug.NodeString(c, n, &debug.Config{Compact: true})
+}
+
+// maxValueLen limits the length of values shown in the trace.
+const maxValueLen = 200
+
+func abbrev(s string) string {
+	if len(s) <= maxValueLen {
+		return s
+	}
+	i := maxValueLen
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return strings.TrimSpace(s[:i]) + "…"
+}
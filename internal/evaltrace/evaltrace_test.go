@@ -0,0 +1,108 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaltrace_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/evaltrace"
+)
+
+type node struct {
+	Label        string
+	Path         string
+	Children     []*node
+	Evals        []struct{ From, To, By string }
+	Conjuncts    []struct{ Expr, Pos string }
+	Disjunctions []struct {
+		Disjuncts []string
+		Default   []bool
+		Errors    []string
+	}
+	Value string
+	Error bool
+}
+
+func (n *node) find(path string) *node {
+	if n.Path == path {
+		return n
+	}
+	for _, c := range n.Children {
+		if x := c.find(path); x != nil {
+			return x
+		}
+	}
+	return nil
+}
+
+func TestWriteHTML(t *testing.T) {
+	ctx := cuecontext.New()
+	r := evaltrace.New(ctx, nil)
+	adt.SetObserver(r)
+	v := ctx.CompileString(`
+a: {b: 1, c: b + 1}
+d: *"x" | "y" | int
+e: d & string
+f: [1, a.c]
+g: int & "s"
+`, cue.Filename("x.cue"))
+	adt.SetObserver(nil)
+	qt.Assert(t, qt.IsNotNil(v.Err()))
+
+	var b strings.Builder
+	qt.Assert(t, qt.IsNil(r.WriteHTML(&b)))
+	_, s, _ := strings.Cut(b.String(), `<script type="application/json" id="data">`)
+	s, _, _ = strings.Cut(s, "</script>")
+	var trace struct {
+		Stats struct{ Vertices, Disjunctions int }
+		Roots []*node
+	}
+	qt.Assert(t, qt.IsNil(json.Unmarshal([]byte(s), &trace)))
+
+	qt.Assert(t, qt.HasLen(trace.Roots, 1))
+	root := trace.Roots[0]
+	qt.Assert(t, qt.Equals(root.Label, "x.cue"))
+	qt.Assert(t, qt.Equals(trace.Stats.Vertices, 10))
+	qt.Assert(t, qt.Equals(trace.Stats.Disjunctions, 2))
+
+	c := root.find("a.c")
+	qt.Assert(t, qt.DeepEquals(c.Conjuncts, []struct{ Expr, Pos string }{
+		{"(b + 1)", "x.cue:2:11"},
+	}))
+	qt.Assert(t, qt.Equals(c.Value, "2"))
+	qt.Assert(t, qt.Equals(c.Evals[0].By, "a"))
+	qt.Assert(t, qt.Equals(c.Evals[0].To, "finalized"))
+
+	e := root.find("e")
+	qt.Assert(t, qt.HasLen(e.Disjunctions, 1))
+	qt.Assert(t, qt.DeepEquals(e.Disjunctions[0].Disjuncts, []string{`"x"`, `"y"`}))
+	qt.Assert(t, qt.DeepEquals(e.Disjunctions[0].Default, []bool{true, false}))
+	qt.Assert(t, qt.DeepEquals(e.Disjunctions[0].Errors, []string{
+		"e: conflicting values string and int (mismatched types string and int)",
+	}))
+
+	qt.Assert(t, qt.IsNotNil(root.find("f[1]")))
+
+	g := root.find("g")
+	qt.Assert(t, qt.IsTrue(g.Error))
+	qt.Assert(t, qt.IsFalse(root.Error))
+}
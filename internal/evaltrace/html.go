@@ -0,0 +1,271 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaltrace
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+)
+
+// trace is the data embedded in the HTML page.
+type trace struct {
+	Stats stats   `json:"stats"`
+	Roots []*node `json:"roots"`
+}
+
+// WriteHTML writes the trace recorded so far to w as an HTML page that
+// contains all data, styles, and scripts needed to view it.
+func (r *Recorder) WriteHTML(w io.Writer) error {
+	r.mu.Lock()
+	// json.Marshal escapes '<', '>' and '&', so the result can be embedded
+	// in a script element as is.
+	b, err := json.Marshal(trace{Stats: r.stats, Roots: r.roots})
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return page.Execute(w, template.JS(b))
+}
+
+var page = template.Must(template.New("trace").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>CUE evaluation trace</title>
+<style>
+body { margin: 0; font: 13px sans-serif; color: #222; display: flex; flex-direction: column; height: 100vh; }
+header { padding: 6px 12px; background: #f0f0f0; border-bottom: 1px solid #ccc; }
+header h1 { font-size: 15px; display: inline; margin-right: 16px; }
+main { flex: 1; display: flex; min-height: 0; }
+#left { width: 45%; display: flex; flex-direction: column; border-right: 1px solid #ccc; }
+#search { margin: 6px; padding: 4px; }
+#results { max-height: 30%; overflow: auto; border-bottom: 1px solid #ccc; }
+#tree { flex: 1; overflow: auto; padding: 4px 0; }
+#details { flex: 1; overflow: auto; padding: 8px 12px; }
+.row { white-space: nowrap; cursor: pointer; padding: 1px 0; position: relative; }
+.row:hover { background: #eef; }
+.row.selected { background: #cde; }
+.toggle { display: inline-block; width: 14px; text-align: center; color: #666; }
+.bar { position: absolute; left: 0; bottom: 0; height: 2px; background: #e8a33d; }
+.badge { color: #777; font-size: 11px; margin-left: 6px; }
+.error { color: #b00; }
+.link { color: #05c; cursor: pointer; }
+code, pre, td.mono { font-family: monospace; }
+pre { white-space: pre-wrap; margin: 0; }
+table { border-collapse: collapse; margin: 4px 0 12px; }
+th, td { text-align: left; padding: 2px 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+h2 { font-size: 14px; margin: 12px 0 4px; }
+h3 { font-size: 13px; margin: 8px 0 2px; }
+.default { font-weight: bold; }
+</style>
+</head>
+<body>
+<header><h1>CUE evaluation trace</h1><span id="summary"></span></header>
+<main>
+<div id="left">
+<input id="search" placeholder="Search paths (press Enter)">
+<div id="results"></div>
+<div id="tree"></div>
+</div>
+<div id="details"></div>
+</main>
+<script type="application/json" id="data">{{.}}</script>
+<script>
+"use strict";
+const data = JSON.parse(document.getElementById("data").textContent);
+const nodes = [];
+let maxTotal = 1;
+let selected = null;
+
+function index(n, parent) {
+	n.parent = parent;
+	n.id = nodes.length;
+	nodes.push(n);
+	n.children = n.children || [];
+	maxTotal = Math.max(maxTotal, n.total);
+	for (const c of n.children) index(c, n);
+}
+data.roots.forEach(r => index(r, null));
+
+function el(tag, props, ...children) {
+	const e = document.createElement(tag);
+	Object.assign(e, props || {});
+	for (const c of children) e.append(c);
+	return e;
+}
+
+function dur(ns) {
+	if (ns < 1e3) return ns + "ns";
+	if (ns < 1e6) return (ns / 1e3).toFixed(1) + "µs";
+	if (ns < 1e9) return (ns / 1e6).toFixed(1) + "ms";
+	return (ns / 1e9).toFixed(2) + "s";
+}
+
+function displayPath(n) {
+	return n.path === "" ? n.label : n.path;
+}
+
+function row(n, depth) {
+	const toggle = el("span", {className: "toggle", textContent: n.children.length ? "▸" : ""});
+	const label = el("span", {textContent: n.label, className: n.error ? "error" : ""});
+	const badge = el("span", {className: "badge",
+		textContent: n.evals ? n.evals.length + "× " + dur(n.total) : ""});
+	const bar = el("div", {className: "bar"});
+	bar.style.width = (100 * n.total / maxTotal) + "%";
+	const r = el("div", {className: "row"}, toggle, label, badge, bar);
+	r.style.paddingLeft = (depth * 14 + 4) + "px";
+	const box = el("div", {}, r);
+	n.row = r;
+	n.box = box;
+	n.depth = depth;
+	toggle.onclick = e => { e.stopPropagation(); setExpanded(n, !n.expanded); };
+	r.onclick = () => select(n);
+	return box;
+}
+
+function setExpanded(n, expanded) {
+	if (!n.children.length) return;
+	n.expanded = expanded;
+	n.row.firstChild.textContent = expanded ? "▾" : "▸";
+	if (expanded && !n.list) {
+		n.list = el("div");
+		for (const c of n.children) n.list.append(row(c, n.depth + 1));
+		n.box.append(n.list);
+	}
+	if (n.list) n.list.style.display = expanded ? "" : "none";
+}
+
+function reveal(n) {
+	const chain = [];
+	for (let p = n.parent; p; p = p.parent) chain.unshift(p);
+	for (const p of chain) setExpanded(p, true);
+	select(n);
+	n.row.scrollIntoView({block: "nearest"});
+}
+
+function nodeLink(n) {
+	const a = el("span", {className: "link", textContent: displayPath(n)});
+	a.onclick = () => reveal(n);
+	return a;
+}
+
+function pathLink(root, path) {
+	if (path === undefined) return "";
+	const n = nodes.find(x => x.path === path && rootOf(x) === root) ||
+		nodes.find(x => x.path === path);
+	return n ? nodeLink(n) : path;
+}
+
+function rootOf(n) {
+	while (n.parent) n = n.parent;
+	return n;
+}
+
+function table(headers, rows) {
+	const t = el("table", {}, el("tr", {}, ...headers.map(h => el("th", {textContent: h}))));
+	for (const r of rows) t.append(el("tr", {}, ...r.map(c => el("td", {}, c))));
+	return t;
+}
+
+function select(n) {
+	if (selected) selected.row.classList.remove("selected");
+	selected = n;
+	n.row.classList.add("selected");
+	const root = rootOf(n);
+	const d = document.getElementById("details");
+	d.replaceChildren();
+	d.append(el("h2", {textContent: displayPath(n)}));
+	if (n.path !== "") d.append(el("div", {textContent: "in " + root.label}));
+	if (n.value !== undefined) {
+		d.append(el("h3", {textContent: "Value"}),
+			el("pre", {textContent: n.value, className: n.error ? "error" : ""}));
+	}
+	d.append(el("h3", {textContent: "Time"}),
+		el("div", {textContent: "total " + dur(n.total) + ", self " + dur(n.self)}));
+	if (n.conjuncts) {
+		d.append(el("h3", {textContent: "Conjuncts"}), table(["expression", "position"],
+			n.conjuncts.map(c => [el("pre", {textContent: c.expr}), c.pos || ""])));
+	}
+	if (n.disjunctions) {
+		d.append(el("h3", {textContent: "Disjunctions"}));
+		n.disjunctions.forEach((x, i) => {
+			const rows = x.disjuncts.map((v, j) => [
+				el("pre", {textContent: v, className: x.default && x.default[j] ? "default" : ""}),
+				x.default && x.default[j] ? "default" : ""]);
+			d.append(el("div", {textContent: "at " + dur(x.start) + ": " +
+				x.disjuncts.length + " remaining, " + (x.errors || []).length + " eliminated"}));
+			d.append(table(["remaining disjunct", ""], rows));
+			if (x.errors) {
+				d.append(table(["eliminated disjunct error"],
+					x.errors.map(e => [el("pre", {textContent: e, className: "error"})])));
+			}
+		});
+	}
+	if (n.evals) {
+		d.append(el("h3", {textContent: "Evaluations"}), table(
+			["start", "duration", "status", "triggered by"],
+			n.evals.map(e => [dur(e.start), dur(e.duration), e.from + " → " + e.to,
+				e.by === n.path ? "" : pathLink(root, e.by)])));
+	}
+	if (n.children.length) {
+		d.append(el("h3", {textContent: "Fields"}));
+		const ul = el("div");
+		for (const c of n.children) ul.append(el("div", {}, nodeLink(c)));
+		d.append(ul);
+	}
+}
+
+function list(title, ns, info) {
+	const r = document.getElementById("results");
+	r.replaceChildren(el("h3", {textContent: title, style: "margin: 4px 6px"}));
+	for (const n of ns) {
+		const line = el("div", {className: "row"}, nodeLink(n), el("span", {className: "badge", textContent: info(n)}));
+		line.style.paddingLeft = "8px";
+		r.append(line);
+	}
+}
+
+function summary() {
+	const s = data.stats;
+	const errors = nodes.filter(n => n.error);
+	const h = document.getElementById("summary");
+	const slow = el("span", {className: "link", textContent: "slowest"});
+	slow.onclick = () => list("Slowest vertices (self time)",
+		nodes.slice().sort((a, b) => b.self - a.self).slice(0, 50), n => dur(n.self));
+	const errs = el("span", {className: "link", textContent: errors.length + " errors"});
+	errs.onclick = () => list("Errors", errors, n => n.value);
+	h.append(s.vertices + " vertices, " + s.evaluations + " evaluations, " +
+		s.disjunctions + " disjunctions, " + dur(s.duration) + " — ", slow, ", ", errs);
+}
+
+document.getElementById("search").onkeydown = e => {
+	if (e.key !== "Enter") return;
+	const q = e.target.value.toLowerCase();
+	list("Matching paths", nodes.filter(n => displayPath(n).toLowerCase().includes(q)).slice(0, 500),
+		n => n.evals ? dur(n.total) : "");
+};
+
+const tree = document.getElementById("tree");
+for (const r of data.roots) tree.append(row(r, 0));
+summary();
+if (data.roots.length) {
+	setExpanded(data.roots[0], true);
+	select(data.roots[0]);
+}
+</script>
+</body>
+</html>
+`))
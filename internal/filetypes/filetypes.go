@@ -120,7 +120,11 @@ func FromFile(b *build.File, mode Mode) (*FileInfo, error) {
 		if err != nil {
 			return nil, err
 		}
-		v, errs = update(errs, v, i, "encodings", s)
+		if IsCustomEncoding(s) {
+			v, errs = update(errs, v, i, "forms", "data")
+		} else {
+			v, errs = update(errs, v, i, "encodings", s)
+		}
 	}
 
 	fi := &FileInfo{}
@@ -290,13 +294,18 @@ func parseType(s string, mode Mode) (inst, val cue.Value, err error) {
 		for _, t := range strings.Split(s, "+") {
 			if p := strings.IndexByte(t, '='); p >= 0 {
 				v = v.Fill(t[p+1:], "tags", t[:p])
+			} else if info := i.Lookup("tags", t); info.Exists() {
+				v = v.Unify(info)
 			} else {
-				info := i.Lookup("tags", t)
-				if !info.Exists() {
+				var ok bool
+				v, ok, err = lookupRegistered(v, t, mode)
+				if err != nil {
+					return inst, val, err
+				}
+				if !ok {
 					return inst, val, errors.Newf(token.NoPos,
 						"unknown filetype %s", t)
 				}
-				v = v.Unify(info)
 			}
 		}
 	}
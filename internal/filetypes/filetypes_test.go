@@ -399,3 +399,67 @@ func TestParseArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisterQualifier(t *testing.T) {
+	if err := RegisterQualifier("compactjson", "json+indent=0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterQualifier("compactjson", "json+indent=0"); err != nil {
+		t.Errorf("re-registering the same qualifier: %v", err)
+	}
+	if err := RegisterEncoding("testcodec"); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		run  func() (interface{}, error)
+		out  interface{}
+	}{{
+		name: "qualifier",
+		run: func() (interface{}, error) {
+			return ParseFile("compactjson:file.bin", Export)
+		},
+		out: &build.File{
+			Filename: "file.bin",
+			Encoding: "json",
+			Tags:     map[string]string{"indent": "0"},
+		},
+	}, {
+		name: "encoding",
+		run: func() (interface{}, error) {
+			return ParseFile("testcodec:file.bin", Input)
+		},
+		out: &build.File{
+			Filename: "file.bin",
+			Encoding: "testcodec",
+			Form:     "data",
+		},
+	}, {
+		name: "redefine tag",
+		run:  func() (interface{}, error) { return nil, RegisterQualifier("json", "yaml") },
+		out:  `qualifier "json" already defined`,
+	}, {
+		name: "redefine qualifier",
+		run:  func() (interface{}, error) { return nil, RegisterQualifier("compactjson", "yaml") },
+		out:  `qualifier "compactjson" already defined`,
+	}, {
+		name: "redefine encoding",
+		run:  func() (interface{}, error) { return nil, RegisterEncoding("yaml") },
+		out:  `encoding "yaml" already defined`,
+	}, {
+		name: "unknown tag",
+		run:  func() (interface{}, error) { return nil, RegisterQualifier("foo", "json+bar") },
+		out:  `invalid qualifier foo=json+bar: unknown filetype bar`,
+	}, {
+		name: "invalid name",
+		run:  func() (interface{}, error) { return nil, RegisterQualifier("Foo:", "json") },
+		out:  `invalid qualifier name "Foo:"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			x, err := tc.run()
+			check(t, tc.out, x, err)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filetypes
+
+import (
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// registry holds the qualifiers and encodings registered in addition to
+// the ones defined in types.cue.
+var registry struct {
+	mu sync.RWMutex
+
+	// qualifiers maps qualifier names to the qualifiers they expand to.
+	qualifiers map[string]string
+
+	// encodings holds the names of custom encodings.
+	encodings map[string]bool
+}
+
+// RegisterQualifier registers name as a qualifier that is equivalent to
+// spec, which is a qualifier that may combine tags and set options, such
+// as "json+indent=0" or "jsonschema+yaml". The tags in spec may refer to
+// qualifiers registered earlier.
+//
+// It is an error to register a name that is already a tag, or to register
+// a name twice with a different spec.
+func RegisterQualifier(name, spec string) error {
+	if !isQualifierName(name) {
+		return errors.Newf(token.NoPos, "invalid qualifier name %q", name)
+	}
+	if _, _, err := parseType(spec, Input); err != nil {
+		return errors.Wrapf(err, token.NoPos, "invalid qualifier %s=%s", name, spec)
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if old, ok := registry.qualifiers[name]; ok && old == spec {
+		return nil
+	}
+	if isDefined(name) {
+		return errors.Newf(token.NoPos, "qualifier %q already defined", name)
+	}
+	if registry.qualifiers == nil {
+		registry.qualifiers = map[string]string{}
+	}
+	registry.qualifiers[name] = spec
+	return nil
+}
+
+// RegisterEncoding registers a custom encoding with the given name, along
+// with a qualifier of the same name that selects it. Files in a custom
+// encoding are interpreted as data.
+func RegisterEncoding(name string) error {
+	if !isQualifierName(name) {
+		return errors.Newf(token.NoPos, "invalid encoding name %q", name)
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.encodings[name] {
+		return nil
+	}
+	if isDefined(name) || cuegenValue.Lookup("encodings", name).Exists() {
+		return errors.Newf(token.NoPos, "encoding %q already defined", name)
+	}
+	if registry.encodings == nil {
+		registry.encodings = map[string]bool{}
+	}
+	registry.encodings[name] = true
+	return nil
+}
+
+// IsCustomEncoding reports whether name was registered with
+// [RegisterEncoding].
+func IsCustomEncoding(name string) bool {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.encodings[name]
+}
+
+// isDefined reports whether name is already a tag, registered qualifier,
+// or custom encoding. It must be called with registry.mu held.
+func isDefined(name string) bool {
+	_, ok := registry.qualifiers[name]
+	return ok || registry.encodings[name] || cuegenValue.Lookup("tags", name).Exists()
+}
+
+// lookupRegistered returns the value of the registered qualifier or custom
+// encoding t, to be unified with the file value v, if it exists.
+func lookupRegistered(v cue.Value, t string, mode Mode) (cue.Value, bool, error) {
+	registry.mu.RLock()
+	spec, isQualifier := registry.qualifiers[t]
+	isEncoding := registry.encodings[t]
+	registry.mu.RUnlock()
+
+	switch {
+	case isQualifier:
+		_, w, err := parseType(spec, mode)
+		if err != nil {
+			return v, false, err
+		}
+		return v.Unify(w), true, nil
+	case isEncoding:
+		return v.Fill(t, "encoding").Fill("data", "form"), true, nil
+	}
+	return v, false, nil
+}
+
+func isQualifierName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case 'a' <= r && r <= 'z':
+		case i > 0 && ('0' <= r && r <= '9' || r == '-' || r == '_'):
+		default:
+			return false
+		}
+	}
+	return true
+}
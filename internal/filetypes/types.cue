@@ -205,6 +205,15 @@ tags: {
 		encoding: "binary"
 		form:     "data"
 	}
+	tree: encoding: "tree"
+	env: {
+		encoding: "env"
+		form:     "data"
+	}
+	flags: {
+		encoding: "flags"
+		form:     "data"
+	}
 	go: {
 		encoding:       "code"
 		interpretation: ""
@@ -228,6 +237,14 @@ tags: {
 		interpretation: "openapi"
 		encoding:       *"json" | _
 	}
+	k8smanifest: {
+		interpretation: "k8smanifest"
+		encoding:       *"yaml" | _
+	}
+	patch: {
+		interpretation: "patch"
+		encoding:       *"json" | _
+	}
 }
 
 // forms defines schema for all forms. It does not include the form ID.
@@ -319,6 +336,21 @@ encodings: binary: {
 	stream: false
 }
 
+encodings: tree: {
+	forms.schema
+	stream: false
+}
+
+encodings: env: {
+	forms.data
+	stream: false
+}
+
+encodings: flags: {
+	forms.data
+	stream: false
+}
+
 encodings: toml: {
 	forms.data
 	stream: false
@@ -369,6 +401,15 @@ interpretations: openapi: {
 	encoding: *"json" | _
 }
 
+interpretations: k8smanifest: {
+	forms.data
+	stream: true
+}
+
+interpretations: patch: {
+	forms.data
+}
+
 interpretations: pb: {
 	forms.data
 	stream: true
@@ -44,5 +44,5 @@ func cuegenMake(name string, x interface{}) cue.Value {
 	return v
 }
 
-// Data size: 1703 bytes.
-var cuegenInstanceData = []byte("\x01\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xc4X\u074b\xe4\xc6\x11\x97\xf6.\x105N\x1e\ry\b\xd4\xe9\xc08\xcbE\x8b?\xc8\xc3\xc0r\x84\xdc]\xb8\x978\x04\xe7\u0258\xa1G*\xcdt,u+\xdd-{\x17\xef\x90\xc4q\xf2g{Cu\xb7\u0512F\xbb{\v\x0e\u0797\x9d\xa9_WuUu}\xce/n\xffs\x96\x9e\xdd\xfe7Io\xff\x99$\xbf\xfb\u01d34}OHc\xb9,\xf1\x15\xb7\x9c\xc8\xe9\x93\xf4\xe9_\x94\xb2\xe9Y\x92>\xfd3\xb7\x87\xf4\xbd$\xfd\xd9\x1b\u0460Io\xbfO\x92\xe4\u05f7\xff>K\xd3_~\xf1e\xd9cQ\x8b&p~\x9f\xa4\xb7\xdf%\u0247\xb7\xffz\x92\xa6?\x8f\xf4\xef\x92\xf4,}\xfa'\xde\"\tz\xea\x88,I\x92\x1f\xde\xff\x15)\x92\xa6gi\x9a\xd9\xeb\x0eMQ\xf6\x98\xfe\xf0~\xd2\xf1\xf2+\xbeG\xd8\xf5\xa2\xa9\x18\xbb\xb8\x80\xdf\x03\xdd\x0f\xa5\xd2\x1aM\xa7de\xc0*\xe0\xf0G\xe5\x0f\x15\x04\x17\xec9\xfd\xdb\xc0\xb7,\xa3\xeb%oq\x03\xe1\xcfX-\xe4\x9ee(KU\t\xb9\x1f\x81\xe7\xaf\x03\x85eBZ\u051dF\u02edP\xf2\xe5\x06\x9e\xbf\x9dQXV+\u077e\x1cY\x89\xfb\x8d\xd2-\xcb,\u07db\x97\xee\xe2\xec\v\x7f\u04d7\x9b\xf1\xca#;:#^a\xcd\xfb\u01820`\x0f\b\xa4\"\xf4\x06+\xa8\x95\x06c+!\x81\u02ca>\xa9\xde\x16\xf0\xf9\x01\xc1\xa0\xb5B\xee\rT\u0621\xacH\x8a\x92\x91\xbbU\x15Y\x1d\x04o\xc0\xd9\x0f\x1f\xcc\x1dp\x9e\xff6\x87\x9bA\x9b\xe3\u011foe\xad\xa0\xc2ZH4pP\xdf\x00\xf7b\x85\x01\xe7&\xac\x9cB\xa3[\xb0\n.&Fg\xad\xfb\u01b2\x8a[\x1e\xbdrnu\x8fp\x035o\f\xb2Lc\x8d\x1ae\x89fs\n\x96\xd7e\xe3\x81\x15N\xa7\x9a \xcf\u04c9\x9dR\r\xcbTG\xdfy\xe3Y<\xadT\xd2X\u0345\xb4\xf1\xdcW\x88]\xf0\x8b\xd9\x04\x9a\x90\xa5j\xbb\x06\xad\v\x8b@k;\xa5\xed\xa0\x81\xa7\x19\xab\x91\xb7\x83R\x9eV\xa9\xd2D\x13=\x8d[\xab\u016e\xb7\xde\x00G\xf3\xee\xa5w1\xf4x\xf4p^\a\xf7\u0215\xa8\x9d/,\xa8\x0e5\xf7\x96\xf8\xd3\x05\xbb\xb8 \xd6\xcf\x0fh\x10,\xb6]\xc3-\x1a\xe0\x1a\xdd\x03Hz\r\xab`\x87\xd0KQ\v\xa4w\x01n]0h\xa5,\xa8\x1a\xecA\x18\x12R*Y\x8b}\xefo(\x98\xbb\xc0\xbd\x97\x90]o}\x9c6h\xe1\n.\xdd\xe7\x99u\x8bG\xc8ff.\xc1#\u02f2\x18\x7fNV\u0330\xf3\xbc\xec\x91boK\xf4\xa2(\x06\x86\x18CW,2\x98 \xa0\xec)j)\xd5La\xca\x03\xb6<\x88 ^\xbc\xb2(\x8d\x0f\tw:/\xfef\x94\xcc\u00f7E\x0e\x93\x0e\xbc\xb7jT\xe2\xe8Y\xaey\xdb<\x96\xe5q\x1cG\xca\xfb\f\xaf(\xba&\x0e\xdf~\xb4\xe6\xf2\xe0\xd4\xf3U\x97/\xc1\a\\\xee\xbcq\xbf\u03f7\x1f=\xe0u\xca\xe7\xe8\xf3#\xcbT\xdf\xd9Y\xe0l?\xfeq\xec\x98j\xf5\xf1c\xb5\u00af\xa9\x0eD\x9d>\xf9\x7f\xfb\xf6\xe1p\xde~\xf2\x80\x11\xb5\xa0\x94\x9fZQa=5\xe2\u04df>'\xb7\x9f>2+\x87\x0e\xf7zHNhyg|3\x89\tK\xe5+\x94C\x0fu\x9a\u02a0\x15T\xfd\x16y\x9d\xe7\xd3.\xbbeYN\xc3\xc1H\xa4~K\x04\x16\xd3?\u04890\x00M@F\xa0!\xa4\xa9\"\xd3\x1c\x91w\"\xa1dDiD`caX\x01\uc55d\x03\x16\xaf,\x01{\x15\xads\xc0^\x11\xb9\xd3\u02aa\xa9\xbe\x8e\xe0$\xe1\x95\x1d\xd0Q\xd2\x1c\xddMt\x8e(\u02e8\xa5|\xf6\xea\xb3\r\x90!\x06\xff\xfe\u0091\xf2b`\x18\x99vBv;\xb8\xb8\x80\x9d\x90\\_w\xbbqT\x18\x06$\x10\xb2\x12\xa5\xefJ\xfe\x01)\x1a\xb8u\xadMc\xa7\u0460\xa4q\x058=\xed^\xf3\xb6`\xe3x\xb5\x81g\x97y\xeeEJ\x98\x0fVP\xa1E\xddN\xe6\x90\x12\xb5\xe5B\x0er\xc0\x1cT\xdfT\xd4\xfdf\xd3\xc8\xc5\x05\xbcQ\x1a\x86\x11\xf6\x05\xb8\x1a\xd1\xf2\xeb\xc5I\xe0\u0509M\xa9\xc5\xce\xeb\xe7#\xf8\x05|s\x10\xe5\x01\x845\xd8\u052esrI\xac\xa5\x92_\xa3\xb6\xbe\xe5r\xf8\xc3__\a\x8e\x82-f\xc2q\xccs\x93\xe04h\x03\xbdv#\xe9\x98^\xcb\xe9,\xaf\x95\xf2!\xec\xa7K/!\xf7\xb7\xe5\xe1\r\xe8\x81|J\x95\xaami&k\x84DO\xb6\xea4\x99\bpi\xe4\xc5\xf8\f\xf6\xd2G\u0254\xb7{\u037b\xc3\fu\x14\x0fV|?\x83*\xbe\x1f\x00\xcb\x17\x88\r\x02]\x91\xf8\x96M\v\x8e\xab7\x0e$+O\xd0`z\x80\x9bU\xbc\xf1\a(\xafNp\x97\x96\x0ev\x11\x7f\x82\xfb\xb4q\a\u01b489\x14\xf3\x8b\x0e\xba\f\xe9v4\x06\xbb\xf1\x1c\x85=\xa0&G\x0f\t\x10r\x04\x06\x11/@\xcdp\x96u\xbb\r\x9c\xcfo\xf1\x7f\xf9\x90^9;\x9d#r\xba\x1fn`\x8d\xf1\xd9\xe5\xfd\xac\x8e\x1c\xac\\50\x1f\x1f\xcc\xe9\x11\x1f\u034b=\xe1\xf1\xe4;\xb9\xf6'n\f\x06\xd2\xe2p\x97q\xd9\x18\x99Y\xd6pw\u035e\x9c\x1eZ!\xb1\xfe(R\x87\xd5+\u0225\xe9\xcc\xe3'\xecnp[\xb9p6H\x85\xe8\x9cf\u04c9\xa0x\xe0]\u0129\x0e%\xef\xc4\x1d\xb2\x02\xfa\x0e\x82|}p]y\xdc\xe4Bw\xa6\xaa\u031b\u0183\x05\xbc\xb5P)4 \x95\x05!\u02e6\xaf\xd0/\x92J\xb7\xf0\xf6U\xc1\xdc9\xa7\x90[cia\xbf\x1cw\u0671~9\xed\xa9;o\u05ea\v\x8cZ\x06W\xc0\r\xe4n\xe4q\x9f\x86\xea\xb2\u0630\x96S\xd8|O[\x8e7\xf3\xadp\x89\xce\xf7\xc3\x0fg\xf0o\xe0\x83%\x85e\x8b\xedq)o\xbeG.\xd1\xf9\xf6\xb8@\x8fT\xe7\xe50\xa2N'\xa7\x13\x7f\x05\x1f\x9d\u0737nU\x94\x7fR\xc0\xe3\x03x_\x93\u05e9p\xfb\xff.w\x17\xdb:\xe9|\xe2\xf3u_\u07eb\xcd\u008f\xeb\xfe[\xf7[\xb4g\xd6sL\xe1l\x98\xd8\xf6\xec2\x86\xd0\xf0\xcb\xc1\x94y\u0697h_\xd8/\xfd\xf2\xec2\xb4\xb1\xb9\xb6\x83Z\xb3\x9f*F\xbb\xa6?Q\xac\x1a\xb0\xea\x97Q\xaf#\x9b\x8f\xd2c\x8f\x1c\x92 Z\x10;d\xdcx\x16\xd9\xe2\x93\x04n\x86w\x9bn\t\x83\x1e\xd3\xe5 \n\x8f\xeds\xee\u0719\x1a\x94\x86^\xf2\xbc#\xaf\xea3\x1e\x8c=g\xf5\\\xd4a\xdaj\x1e8jU{\xdf\xdd\xf1\u0925/r\xec\x9d\u0180\x99\xf4;f\x82\xc9\v,m\xa1F\x7f\x9f\x98i\xcf^\x93\x12[\xdeB\xf9\x13C\x8fl\xde'\x1eQ\xab\xdd\x06\xe5\x9b\xe0\xfc\x96eW\xbb\u04c1\xf7\xf6\xafw\xe6Zu\xd62\x9a\x8e,I\xfe\x17\x00\x00\xff\xffj\xd7\xe3\u00e8\x16\x00\x00")
+// Data size: 1810 bytes.
+var cuegenInstanceData = []byte("\x01\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xc4X_\x8f\u0736\x11\x97\xce.P\ti_\xf3T`\"\x03A\xbapu\xc8\x1f\x14\xc5\x02\x86Q\xd4v\u15e6(\xd2'#8p\xa5\xd1.k\x89TI\u02b9C\xee\xd06M\xfb\x19\xfb5\xfa\x05r\u0150\xd4\x1fJ\xda\xdb;\xc0E\xec\x87\u06dd\x1f\xe7\u01d9\xe1\fg\xb8?\xbb\xfd\xd7Y|v\xfb\xef(\xbe\xfd{\x14\xfd\xfao\x8f\xe2\xf8\x03.\xb4a\xa2\xc0\x17\xcc0\x12\u01cf\xe2\xc7\x7f\x92\xd2\xc4gQ\xfc\xf8\x8f\xcc\x1c\xe2\x0f\xa2\xf8'\xafx\x8d:\xbe\xfd>\x8a\xa2_\xdc\xfe\xf3,\x8e\x7f\xfe\xe6\xeb\xa2\u00fc\xe2\xb5\xd7\xfc>\x8ao\xbf\x8b\xa2On\xff\xf1(\x8e\x7f:\u02bf\x8b\xe2\xb3\xf8\xf1\x1fX\x83D\xf4\xd8\n\xd3(\x8a~\xf8\xf0\xbfdH\x1c\x9f\xc5qb\xaeZ\xd4y\xd1a\xfc\u00c7\xffiY\xf1\x96\xed\x11v\x1d\xaf\xcb4=?\x87\xdf\x02\xed\x0f\x85T\nu+E\xa9\xc1H`\xf0{\xe9\x16\xe5\x04\xe7\xe9\x13\xfa\xb3\x85o\u04c4\xb6\x17\xac\xc1-\xf8\x7f\xda(.\xf6i\x82\xa2\x90%\x17\xfb\x01x\xf2\xd2K\u0484\v\x83\xaaUh\x98\xe1R<\xdf\u0093\u05c1$M*\xa9\x9a\xe7\x83*i\xbf\x92\xaaI\x13\xc3\xf6\xfa\xb9\xdd8y\xe3v\xfaz;ly\x93\xdeX'^`\u017a\xda\x00\xd7`\x0e\bd\"t\x1aK\xa8\xa4\x02mJ.\x80\x89\x92>\xc9\xce\xe4\xf0\xd5\x01A\xa31\\\xec5\x94\u0622(\x89E\x8aQ\xbb\x91%\xe6\xe9\x13O\xbc\x05\xeb?|\x1c\x06`\x93\xfd*\x83\xeb\u079a\x9bI<_\x8bJB\x89\x15\x17\xa8\xe1 \xbf\x01\xe6h\xb9\x06\x1b&,\xadACX\xb0\xf4!&E\xeb\xad\xfd\x96&%3l\x8c\xca\u01a8\x0e\xe1\x1a*VkL\x13\x85\x15*\x14\x05\xea\xed\x12,\xae\x8a\xda\x01+\x9a\xd64NgA+vR\xd6i\"[\xfa\xcej\xa7\xe2d\x85\x14\xda(\u0185\x19\u05fdEl}\\\xf4\xd6\u02f8(d\xd3\xd6hlZxY\xd3Jez\v\x9cL\x1b\x85\xac\xe9\x8dr\xb2R\x16\x83\x99\xbd\x8c\x19\xa3\xf8\xae3\xce\x01+s\xe1\xa5s\xd1txtp\xce\x06{\xc8%\xafl,\f\xc8\x16\x95\xcd)V\xbb\xd5yz~N\xaa_\x1dP#\x18l\u069a\x19\xd4\xc0\x14\xda\x03\x10%\x96\x94\xf3;\x84N\xf0\x8ac\t\x94/\xc6&\x83\x92\u0480\xac\xc0\x1c\xb8&\x92B\x8a\x8a\xef;\xb7C\x9e\xda\r\xecyq\xd1v\xc6~Jj4p\t\xcf\xec\xe7\xc0\xbb\xd9!$\x81\x9bs\xf0&M\x921\xff,\xd7Xa\x9b\xac\xe8\x90r\xef\x82\xe4y\x9e\xf7\nc\x0e]\xa6\xa3\x82\xf6\x04E\x87[\xd8P\xa9\xe9\\\x17\al\x98\xa7\xa0\xcd\xf0\u04a0\xd0.%\xec\xea,\xff\x8b\x96\"\xf3\xdff5L6\xb0\xce\xc8\xc1\b\xa2H\xb2\xfc\x8a5\xf5CU\x1e\xa6qCu\x9f\xe0%e\xd7$\xe0\x17\x9f\xae\x85\xdc\au\xb3\x1a\xf29x\"\xe46\x1aw\xc7\xfc\xe2\xd3\x13Q\xa7z\xf6\x14\xce\x0f\u0675&H\x9c\x8b\xcf\u078f\x1fS\xab>{\xa8U\xf8\x8e\xd5S\x9b>\xff\x7f\xc7\xf6t:_|~\u0089\x8a\vV\a^\x94XM\x9d\xf8\xe2\u01ef\u024b/\x1eX\x95}\x87{\xd9\x17'4\xac\u056e\x99\x8c\x05K\u05d7\xbf\x0e\x1d\xd4*\xba\x06\rG\x9d\xa7\xb3\xba\u03b2\xdeu\xfa\x7f\x91&\x19\r\a\x83\x90\xfa-\t\u04b1\xfcG9\tz\xa0\u03b6!P\x13R\x97\xa3R\x88\x88\xa3\x88\xbf2F6\x12\xa4\xc3\u0170\x02\x98K\x13\x02\x06/\ri\xec\xe5 w\xc0^\x92\xb8U\xd2\xf4\x88\x15[\x01!\xa4\u0623\x03S\x88\xee&6\x8fh\x9aPK\xf9\xf2\u0157[ G4\xfe\xf5\xa9\x15ey\xaf0(\xed\xb8hwp~\x0e;.\x98\xbajw\u00e8\xd0\x0fH\xc0E\xc9\v\u05d5\xdc\x01\xd2\x1d\u034cmm\n[\x85\x1a\x05\x8d+\xc0\xa0Ur\xafX\x93\xa7\xc3x\xb5\x85\x8f\x9ee\x99\xa3\x14\x10\x0eVP\xa2A\xd5L\xe6\x90\x02\x95a\\\xf4<\xa0\x0f\xb2\xabK\xd8a8\x8d\x9c\x9f\xc3+\xa9\xa0\x1fa\x9f\x82\xbd\xb9\x1av5[\t\x8c:\xb1.\x14\xdf9\xfb\\_y\n\xdf\x1cxq\x00n4\xd6\x15\x99V0A\xaa\x85\x14\xefP\x91\xa2\x1d3\x7f\xf7\xe7\x97^#Og3\xe10\xe6\xd9Ip\b\xe98qR\xa0\xfcdF\x17\xdd|:\xcb*)m\xfaen\xbat\f\x99\xdb-\xf3g@\a\xe4J\xaa\x90MC3Y\xcd\x05\xda\u0721\xa2Z\x14\x13\x01\xb6\x8c\x1c\x8d\xfd\xe8\xd9\af\xba&\xf6\x8a\xb5\x87\x00\xb5\x92\xcc\xddKl\x1f@%\xdb\xf7\x80\t)I\xe0 \u06fa\xbf\x9d\xdc\x1e[\xb03\x80\x05\xc9\xcb\x05\xea]\xf7p\xbd\x8a\xd7n\xc1\x15k\x968\t\x1dl3~\x81[\xa9[0\x94\xc5b\u0440\u0605\xb6B\xda\x1d\xcd\xe9v<Gn\x0e\xa8(\xd0}\x01\xf8\x1a\x81\x9e\xe2)\xc8\x00O\x93v\xb7\x85M\xb8\v\x9d+@\u0597W\x96.\u720c\xf6\x87\xeb\x99y\xa4\x06T=w\xaa\xb6\xbb\xd1\xcbU\a\xb3\xe1\xc0\x88nrh\x8ev\xa1\xe3\xc4G\xb5\x8c\xc2\xe5Y\x93\u0411\xa2x\xb7@Q\xbc;JW\xd5}\xceN5\xac\xf4\xa8\xce^na5\xc6\xf4v9\x16\xdfd(\x8e$\xa9\x19)e{\x99\r\u0758T\xdf\v\xab/\xff\x9e\x97\xa6P\x87/\xd4\t\xcaV6\ff9_ \u04c2^\x10\x8d\v\xeeC'[\x14\xac\xe5G\xb8<z\x1f\xa2\xb7\xbf\xd1\r\x13\xbcBm\x8e\x90MV\xac\x13\xda*\x1e\b[f\x8a\xc3\x11*\x8b\xdd\xc3*wqR\xda\xe8\xe1\x89\xeb\xc7\x16jW\xac\xae\xa9m5:\x87\xd7\x06J\x89\x1a\x844\xc0EQw%\xdaG\x15\xc1\xf0\xfaE\x9e\xd2\a\x971\x14\xa97\xf4K\u01b3\xe1\x91?\\\xec\xd6r\x1a[.\u05ae\xdd\xfe\u07e6\xbf\x7f\xe1\x1a2;\v\x92\xc5\u00f5;{z\xce\xc7\xd3\xf0\x01;\x9f\xfb\xc2\xe7\xf2\x1c\r\x1f\u039f\x04\xf0/\xe1\xe3\xb9$Mf\xcf\xea\x00N\x93\xd9\x03{\x8e\x86\xcf\xea\x19J\xe5N\xae\x8f\x01\xeaG\xcaE\xbc|\x8c\x16\xfb\xad{5\xf2/:[O\xb8\U000719a8SGs\x7f\xedU8\xfb\x19\x83l^\xc4|=\xd6wZ3\x8b\xe3z\xfc\xd6\xe3\xe6\xa5\xf3f\xacs\xeb\xc3\u0137\x8f\x9e\x8d)\xd4\xff\xa42U\x9e6l\x9d\x97l?\xd1\xed\xbb\vEcn\xad\xe7\b\x7f\xc3\xe9\x85\xfdF\x81\xb3\x81\x03\xabq\xf1Bz3\xf45\xec\xaak\x18\x1e\xfa\"\x18VNF\x87\xf1)8\xab\x96\x8d]\r\xd7\xfd\xb9M\x9fO\x9e(x5\x8d\xe4\xe3\\\x11\x0670\x83\xca\xd01{s\xea;\xec\x19\x16\x8e\xcdxu\xddh\u00f4\a\x9fX:6\xdeY\xe5,\x97\x0eM\xf8\x04\xe5\xa4\xf9\x9e\xda\\6\xf5\xbd\x16N\x06\xad\x99\x99\xe3\xc5}\xc7p\x16\xb0\x1f\x99\u0506]a\x11\xc8vw7\xcdt\x92Zc\x19\xa7\x80S1\xbeI\xc3\x16\xf5\x80Fa\u07f5\xd4\xfc\xb7\x10\xee2o\xf43\x1bF?\xe6\x9d8h\xe9\xf7\xd6Z\xf4\xef j\x8b\x9c\xf6\xddy\xba\xa8\xdd\xddC\xf1&\x8d\xa2\xff\r\x00\xa9\xb4.H\x80\x18\x00\x00")
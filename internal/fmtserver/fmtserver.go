@@ -0,0 +1,281 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fmtserver implements a long-running server that formats CUE
+// files on request, as cue fmt does, so that editors can format on save
+// without paying the startup cost of the cue command each time.
+//
+// Requests and responses are JSON objects, one per line. A request names
+// a file and optionally holds its contents and the formatting options:
+//
+//	{"id": 1, "path": "/src/x.cue", "source": "a:   1\n"}
+//
+// If the source is omitted, the file is read from disk. If the options are
+// omitted, those of the server are used. If write is true, the formatted
+// file is written back to the path when it changed; this is only allowed
+// for existing .cue files whose source is read from disk.
+//
+// The response echoes the id of the request and holds either the
+// formatted file and whether it differs from the source, or an error:
+//
+//	{"id": 1, "source": "a: 1\n", "changed": true}
+//	{"id": 2, "error": "expected operand, found '}':\n    /src/x.cue:1:4"}
+//
+// A line that is not a valid request ends the session, after an error
+// response without an id, as the client is not speaking the protocol.
+//
+// The server remembers the last source and result for each file and
+// options, so that formatting a file that has not changed since it was last
+// formatted, which is the common case for format on save, does not parse it
+// again.
+package fmtserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/tools/fix"
+)
+
+// Options holds the formatting options of cue fmt.
+type Options struct {
+	// Simplify simplifies the output, as with the -s flag.
+	Simplify bool `json:"simplify,omitempty"`
+
+	// Strings is the form of string literals: keep, readable, quoted,
+	// multiline, or raw. The empty string means keep.
+	Strings string `json:"strings,omitempty"`
+
+	// CommentWidth reflows comments to the given width, if positive.
+	CommentWidth int `json:"commentWidth,omitempty"`
+}
+
+// FormatOptions returns the options for [format.Node] corresponding to o.
+func (o Options) FormatOptions() ([]format.Option, error) {
+	var opts []format.Option
+	if o.Simplify {
+		opts = append(opts, format.Simplify())
+	}
+	switch o.Strings {
+	case "", "keep":
+	case "readable":
+		opts = append(opts, format.Strings(literal.ReadableStyle))
+	case "quoted":
+		opts = append(opts, format.Strings(literal.QuotedStyle))
+	case "multiline":
+		opts = append(opts, format.Strings(literal.MultilineStyle))
+	case "raw":
+		opts = append(opts, format.Strings(literal.RawStyle))
+	default:
+		return nil, fmt.Errorf("invalid strings value %q: must be keep, readable, quoted, multiline, or raw", o.Strings)
+	}
+	if o.CommentWidth > 0 {
+		opts = append(opts, format.ReflowComments(o.CommentWidth))
+	}
+	return opts, nil
+}
+
+// request is a request to format a file.
+type request struct {
+	ID      json.RawMessage `json:"id,omitempty"`
+	Path    string          `json:"path"`
+	Source  *string         `json:"source,omitempty"`
+	Options *Options        `json:"options,omitempty"`
+	Write   bool            `json:"write,omitempty"`
+}
+
+// response is the response to a request.
+type response struct {
+	ID      json.RawMessage `json:"id,omitempty"`
+	Source  string          `json:"source,omitempty"`
+	Changed bool            `json:"changed,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// maxCached bounds the number of files whose results are remembered.
+const maxCached = 10000
+
+// A cacheKey identifies the formatting of a file with some options.
+type cacheKey struct {
+	path string
+	opts Options
+}
+
+// A Server formats files on request. Its methods may be called
+// concurrently.
+type Server struct {
+	opts Options
+
+	mu    sync.Mutex
+	cache map[cacheKey]cacheEntry
+}
+
+// A cacheEntry holds the last file formatted for a cacheKey.
+type cacheEntry struct {
+	in, out []byte
+}
+
+// New returns a server that formats files with the options opts, unless
+// a request specifies other options.
+func New(opts Options) (*Server, error) {
+	if _, err := opts.FormatOptions(); err != nil {
+		return nil, err
+	}
+	return &Server{opts: opts, cache: map[cacheKey]cacheEntry{}}, nil
+}
+
+// Serve reads requests from r and writes responses to w, until r is
+// exhausted or a line is not a valid request.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	// Requests hold whole files.
+	sc.Buffer(nil, 256<<20)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			err = fmt.Errorf("invalid request: %v", err)
+			enc.Encode(&response{Error: err.Error()})
+			return err
+		}
+		if err := enc.Encode(s.handle(&req)); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// ServeListener serves each connection accepted by l, until l is closed.
+// As requests can write files, l should only accept connections from the
+// user running the server, as with a unix socket that only they can access.
+func (s *Server) ServeListener(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			s.Serve(conn, conn)
+		}()
+	}
+}
+
+func (s *Server) handle(req *request) *response {
+	resp := &response{ID: req.ID}
+	out, changed, err := s.format(req)
+	if err != nil {
+		resp.Error = strings.TrimSuffix(errors.Details(err, nil), "\n")
+		return resp
+	}
+	resp.Source = string(out)
+	resp.Changed = changed
+	return resp
+}
+
+func (s *Server) format(req *request) (out []byte, changed bool, err error) {
+	if req.Path == "" {
+		return nil, false, fmt.Errorf("no path in request")
+	}
+	if req.Write {
+		// Only files that cue fmt would format are written, and only with
+		// their own formatted contents.
+		if req.Source != nil {
+			return nil, false, fmt.Errorf("write cannot be combined with source")
+		}
+		if filepath.Ext(req.Path) != ".cue" {
+			return nil, false, fmt.Errorf("cannot write %s: not a .cue file", req.Path)
+		}
+		if fi, err := os.Stat(req.Path); err != nil {
+			return nil, false, err
+		} else if !fi.Mode().IsRegular() {
+			return nil, false, fmt.Errorf("cannot write %s: not a regular file", req.Path)
+		}
+	}
+	var src []byte
+	if req.Source != nil {
+		src = []byte(*req.Source)
+	} else if src, err = os.ReadFile(req.Path); err != nil {
+		return nil, false, err
+	}
+	key := cacheKey{path: req.Path, opts: s.opts}
+	if req.Options != nil {
+		key.opts = *req.Options
+	}
+
+	s.mu.Lock()
+	e, ok := s.cache[key]
+	s.mu.Unlock()
+	// Formatting is idempotent, so a file that is the result of the last
+	// request for it is already formatted.
+	switch {
+	case ok && bytes.Equal(src, e.out):
+		return src, false, nil
+	case ok && bytes.Equal(src, e.in):
+		out = e.out
+	default:
+		if out, err = formatFile(req.Path, src, key.opts); err != nil {
+			return nil, false, err
+		}
+	}
+
+	changed = !bytes.Equal(src, out)
+	if changed && req.Write {
+		if err := os.WriteFile(req.Path, out, 0o666); err != nil {
+			return nil, false, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.cache) >= maxCached {
+		clear(s.cache)
+	}
+	s.cache[key] = cacheEntry{in: src, out: out}
+	return out, changed, nil
+}
+
+// formatFile formats the CUE file with the given name and contents as
+// cue fmt does.
+func formatFile(name string, src []byte, o Options) ([]byte, error) {
+	opts, err := o.FormatOptions()
+	if err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile(name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return format.Node(fix.File(f), opts...)
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fmtserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+func serve(t *testing.T, s *Server, reqs ...any) []response {
+	var in strings.Builder
+	for _, req := range reqs {
+		b, err := json.Marshal(req)
+		qt.Assert(t, qt.IsNil(err))
+		in.Write(b)
+		in.WriteString("\n")
+	}
+	var out strings.Builder
+	err := s.Serve(strings.NewReader(in.String()), &out)
+	qt.Assert(t, qt.IsNil(err))
+
+	var resps []response
+	dec := json.NewDecoder(strings.NewReader(out.String()))
+	for dec.More() {
+		var resp response
+		qt.Assert(t, qt.IsNil(dec.Decode(&resp)))
+		resps = append(resps, resp)
+	}
+	qt.Assert(t, qt.HasLen(resps, len(reqs)))
+	return resps
+}
+
+func ptr[T any](x T) *T { return &x }
+
+func TestServe(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "x.cue")
+	err := os.WriteFile(path, []byte("a:   {b: 1}\n"), 0o666)
+	qt.Assert(t, qt.IsNil(err))
+
+	s, err := New(Options{})
+	qt.Assert(t, qt.IsNil(err))
+	resps := serve(t, s,
+		request{ID: json.RawMessage(`1`), Path: path},
+		request{ID: json.RawMessage(`"two"`), Path: path, Source: ptr("a: {b: 1}\n")},
+		request{ID: json.RawMessage(`3`), Path: path, Source: ptr("a: {b: 1}\n"), Options: &Options{Simplify: true}},
+		request{ID: json.RawMessage(`4`), Path: path, Source: ptr(`a: "\"\"\""` + "\n"), Options: &Options{Strings: "raw"}},
+		request{ID: json.RawMessage(`5`), Path: path, Source: ptr("a: }\n")},
+		request{ID: json.RawMessage(`6`), Path: path, Options: &Options{Strings: "fancy"}},
+		request{ID: json.RawMessage(`7`)},
+	)
+	qt.Check(t, qt.DeepEquals(resps[0], response{ID: json.RawMessage(`1`), Source: "a: {b: 1}\n", Changed: true}))
+	qt.Check(t, qt.DeepEquals(resps[1], response{ID: json.RawMessage(`"two"`), Source: "a: {b: 1}\n"}))
+	qt.Check(t, qt.DeepEquals(resps[2], response{ID: json.RawMessage(`3`), Source: "a: b: 1\n", Changed: true}))
+	qt.Check(t, qt.DeepEquals(resps[3], response{ID: json.RawMessage(`4`), Source: `a: #"""""#` + "\n", Changed: true}))
+	qt.Check(t, qt.StringContains(resps[4].Error, "expected operand"))
+	qt.Check(t, qt.StringContains(resps[5].Error, `invalid strings value "fancy"`))
+	qt.Check(t, qt.Equals(resps[6].Error, "no path in request"))
+
+	// Without write, the file is left alone.
+	data, err := os.ReadFile(path)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(string(data), "a:   {b: 1}\n"))
+
+	resps = serve(t, s, request{Path: path, Write: true})
+	qt.Check(t, qt.IsTrue(resps[0].Changed))
+	data, err = os.ReadFile(path)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(string(data), "a: {b: 1}\n"))
+}
+
+func TestServeWrite(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "x.txt")
+	err := os.WriteFile(other, []byte("a:   1\n"), 0o666)
+	qt.Assert(t, qt.IsNil(err))
+	sub := filepath.Join(dir, "sub.cue")
+	qt.Assert(t, qt.IsNil(os.Mkdir(sub, 0o777)))
+
+	s, err := New(Options{})
+	qt.Assert(t, qt.IsNil(err))
+	resps := serve(t, s,
+		request{Path: filepath.Join(dir, "x.cue"), Source: ptr("a:   1\n"), Write: true},
+		request{Path: other, Write: true},
+		request{Path: filepath.Join(dir, "missing.cue"), Write: true},
+		request{Path: sub, Write: true},
+	)
+	qt.Check(t, qt.Equals(resps[0].Error, "write cannot be combined with source"))
+	qt.Check(t, qt.Equals(resps[1].Error, "cannot write "+other+": not a .cue file"))
+	qt.Check(t, qt.StringContains(resps[2].Error, "no such file or directory"))
+	qt.Check(t, qt.Equals(resps[3].Error, "cannot write "+sub+": not a regular file"))
+
+	// Nothing was written.
+	_, err = os.Stat(filepath.Join(dir, "x.cue"))
+	qt.Check(t, qt.ErrorIs(err, os.ErrNotExist))
+	data, err := os.ReadFile(other)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(string(data), "a:   1\n"))
+}
+
+func TestServeInvalid(t *testing.T) {
+	s, err := New(Options{})
+	qt.Assert(t, qt.IsNil(err))
+	// The session ends at the first line that is not a request, such as
+	// one of an HTTP request, so that the requests after it are ignored.
+	in := "POST / HTTP/1.1\n\n" + `{"path": "x.cue", "source": "a:   1"}` + "\n"
+	var out strings.Builder
+	err = s.Serve(strings.NewReader(in), &out)
+	qt.Check(t, qt.ErrorMatches(err, "invalid request: .*"))
+	qt.Check(t, qt.Equals(out.String(), `{"error":"invalid request: invalid character 'P' looking for beginning of value"}`+"\n"))
+}
+
+func TestServeListener(t *testing.T) {
+	// Socket paths are limited in length, so avoid long temporary paths.
+	dir, err := os.MkdirTemp("", "fmtserver")
+	qt.Assert(t, qt.IsNil(err))
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	l, err := net.Listen("unix", filepath.Join(dir, "sock"))
+	qt.Assert(t, qt.IsNil(err))
+	s, err := New(Options{Simplify: true})
+	qt.Assert(t, qt.IsNil(err))
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ServeListener(l)
+	}()
+
+	conn, err := net.Dial("unix", l.Addr().String())
+	qt.Assert(t, qt.IsNil(err))
+	defer conn.Close()
+	_, err = conn.Write([]byte(`{"id": 1, "path": "x.cue", "source": "a: {b: 1}"}` + "\n"))
+	qt.Assert(t, qt.IsNil(err))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	qt.Assert(t, qt.IsNil(err))
+	qt.Check(t, qt.Equals(line, `{"id":1,"source":"a: b: 1\n","changed":true}`+"\n"))
+
+	l.Close()
+	qt.Check(t, qt.IsNil(<-done))
+}
@@ -0,0 +1,225 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hostapi implements an evaluation API for embedding CUE.
+//
+// It is meant for programs written in other languages, such as JavaScript
+// running in a browser. Values are referred to by integer handles so that
+// they can be passed across language boundaries, and all results are plain
+// data that can be converted to JSON.
+//
+// The API is shared by the bindings in cmd/cuewasm and cmd/libcue and is kept
+// stable across releases: fields may be added to results, but not removed or
+// changed.
+package hostapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/encoding/yaml"
+	"cuelang.org/go/internal"
+)
+
+// A Handle refers to a value held by a [Session]. The zero handle never
+// refers to a value.
+type Handle int
+
+// A Diagnostic describes an error at a single location.
+type Diagnostic struct {
+	Message  string `json:"message"`
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+// A Result is the outcome of an operation. Value is set by operations that
+// create values, and Output by those that produce text. Diagnostics is
+// empty if the operation succeeded.
+type Result struct {
+	Value       Handle       `json:"value,omitempty"`
+	Output      string       `json:"output,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// OK reports whether the operation succeeded.
+func (r *Result) OK() bool { return len(r.Diagnostics) == 0 }
+
+// A Session holds values created by compiling or unifying CUE. Values
+// created by one session cannot be used with another.
+//
+// A Session is not safe for concurrent use.
+type Session struct {
+	ctx    *cue.Context
+	values map[Handle]cue.Value
+	last   Handle
+}
+
+// NewSession returns a new, empty session.
+func NewSession() *Session {
+	return &Session{
+		ctx:    cuecontext.New(),
+		values: map[Handle]cue.Value{},
+	}
+}
+
+func (s *Session) add(v cue.Value) Handle {
+	s.last++
+	s.values[s.last] = v
+	return s.last
+}
+
+func (s *Session) value(h Handle) (cue.Value, *Result) {
+	v, ok := s.values[h]
+	if !ok {
+		return v, errResult(fmt.Errorf("invalid value handle %d", h))
+	}
+	return v, nil
+}
+
+// Compile compiles CUE source. The filename is used in diagnostics.
+func (s *Session) Compile(src, filename string) *Result {
+	v := s.ctx.CompileString(src, cue.Filename(filename))
+	if err := v.Err(); err != nil {
+		return errResult(err)
+	}
+	return &Result{Value: s.add(v)}
+}
+
+// CompileJSON compiles JSON data. The filename is used in diagnostics.
+func (s *Session) CompileJSON(data, filename string) *Result {
+	if !json.Valid([]byte(data)) {
+		return errResult(fmt.Errorf("%s: invalid JSON", filename))
+	}
+	// JSON is valid CUE.
+	return s.Compile(data, filename)
+}
+
+// Unify unifies the values referred to by a and b. Conflicts are reported
+// as diagnostics; the resulting value is returned even then, so that it
+// can be inspected further.
+func (s *Session) Unify(a, b Handle) *Result {
+	x, r := s.value(a)
+	if r != nil {
+		return r
+	}
+	y, r := s.value(b)
+	if r != nil {
+		return r
+	}
+	v := x.Unify(y)
+	r = &Result{Value: s.add(v)}
+	if err := v.Validate(); err != nil {
+		r.Diagnostics = diagnostics(err)
+	}
+	return r
+}
+
+// LookupPath returns the value at the given CUE path within h.
+func (s *Session) LookupPath(h Handle, path string) *Result {
+	v, r := s.value(h)
+	if r != nil {
+		return r
+	}
+	p := cue.ParsePath(path)
+	if err := p.Err(); err != nil {
+		return errResult(err)
+	}
+	v = v.LookupPath(p)
+	if !v.Exists() {
+		return errResult(fmt.Errorf("path %q not found", path))
+	}
+	return &Result{Value: s.add(v)}
+}
+
+// Validate reports errors in h. If concrete is true, it also reports
+// values that are not concrete.
+func (s *Session) Validate(h Handle, concrete bool) *Result {
+	v, r := s.value(h)
+	if r != nil {
+		return r
+	}
+	return &Result{Diagnostics: diagnostics(v.Validate(cue.Concrete(concrete)))}
+}
+
+// Export renders h in the given format, which is one of "json", "yaml",
+// or "cue". Values must be concrete to be exported as JSON or YAML.
+func (s *Session) Export(h Handle, format string) *Result {
+	v, r := s.value(h)
+	if r != nil {
+		return r
+	}
+	switch format {
+	case "json", "yaml":
+		if err := v.Validate(cue.Concrete(true)); err != nil {
+			return errResult(err)
+		}
+	}
+	var b []byte
+	var err error
+	switch format {
+	case "json":
+		b, err = json.MarshalIndent(v, "", "    ")
+		b = append(b, '\n')
+	case "yaml":
+		b, err = yaml.Encode(v)
+	case "cue":
+		b, err = formatCUE(v)
+	default:
+		err = fmt.Errorf("unknown export format %q", format)
+	}
+	if err != nil {
+		return errResult(err)
+	}
+	return &Result{Output: string(b)}
+}
+
+func formatCUE(v cue.Value) ([]byte, error) {
+	f := internal.ToFile(v.Syntax(cue.Final(), cue.Docs(true)))
+	return format.Node(f, format.Simplify())
+}
+
+// Free releases the value referred to by h. Handles of values derived
+// from h remain valid.
+func (s *Session) Free(h Handle) {
+	delete(s.values, h)
+}
+
+func errResult(err error) *Result {
+	return &Result{Diagnostics: diagnostics(err)}
+}
+
+// diagnostics converts err to diagnostics, one for each of its errors.
+// Only the most relevant position of each error is reported.
+func diagnostics(err error) []Diagnostic {
+	var a []Diagnostic
+	for _, e := range errors.Errors(err) {
+		d := Diagnostic{Message: errors.String(e)}
+		if ps := errors.Positions(e); len(ps) > 0 {
+			pos := ps[0].Position()
+			d.Filename = pos.Filename
+			d.Line = pos.Line
+			d.Column = pos.Column
+		}
+		a = append(a, d)
+	}
+	if len(a) == 0 && err != nil {
+		a = append(a, Diagnostic{Message: err.Error()})
+	}
+	return a
+}
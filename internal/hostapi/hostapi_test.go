@@ -0,0 +1,84 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestSession(t *testing.T) {
+	s := NewSession()
+
+	schema := s.Compile(`
+#Person: {
+	name: string
+	age:  int & >=0
+}
+p: #Person
+`, "schema.cue")
+	qt.Assert(t, qt.IsTrue(schema.OK()))
+
+	person := s.LookupPath(schema.Value, "p")
+	qt.Assert(t, qt.IsTrue(person.OK()))
+
+	r := s.Validate(person.Value, true)
+	qt.Assert(t, qt.IsFalse(r.OK()))
+
+	data := s.CompileJSON(`{"name": "Ann", "age": 42}`, "data.json")
+	qt.Assert(t, qt.IsTrue(data.OK()))
+
+	u := s.Unify(person.Value, data.Value)
+	qt.Assert(t, qt.IsTrue(u.OK()))
+	qt.Assert(t, qt.IsTrue(s.Validate(u.Value, true).OK()))
+
+	r = s.Export(u.Value, "json")
+	qt.Assert(t, qt.DeepEquals(r, &Result{Output: "{\n    \"name\": \"Ann\",\n    \"age\": 42\n}\n"}))
+	r = s.Export(u.Value, "yaml")
+	qt.Assert(t, qt.DeepEquals(r, &Result{Output: "name: Ann\nage: 42\n"}))
+	r = s.Export(u.Value, "cue")
+	qt.Assert(t, qt.DeepEquals(r, &Result{Output: "name: \"Ann\"\nage:  42\n"}))
+
+	bad := s.CompileJSON(`{"age": -1}`, "bad.json")
+	u = s.Unify(person.Value, bad.Value)
+	qt.Assert(t, qt.DeepEquals(u.Diagnostics, []Diagnostic{{
+		Message:  "p.age: invalid value -1 (out of bound >=0)",
+		Filename: "schema.cue",
+		Line:     4,
+		Column:   14,
+	}}))
+
+	r = s.Export(person.Value, "json")
+	qt.Assert(t, qt.IsFalse(r.OK()))
+
+	s.Free(u.Value)
+	r = s.Export(u.Value, "json")
+	qt.Assert(t, qt.DeepEquals(r.Diagnostics, []Diagnostic{{
+		Message: "invalid value handle 6",
+	}}))
+}
+
+func TestCompileError(t *testing.T) {
+	s := NewSession()
+	r := s.Compile("a: {", "x.cue")
+	b, err := json.Marshal(r)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(b), `{"diagnostics":[{"message":"expected '}', found 'EOF'","filename":"x.cue","line":1,"column":5}]}`))
+
+	r = s.CompileJSON("{a: 1}", "x.json")
+	qt.Assert(t, qt.DeepEquals(r.Diagnostics, []Diagnostic{{Message: "x.json: invalid JSON"}}))
+}
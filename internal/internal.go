@@ -101,7 +101,9 @@ var BaseContext = Context{*apd.BaseContext.WithPrecision(34)}
 var APIVersionSupported = Version(MinorSupported, PatchSupported)
 
 const (
-	MinorCurrent   = 5
+	// MinorCurrent is the minor version of the most recent language
+	// version supported by this implementation, v0.MinorCurrent.0.
+	MinorCurrent   = 8
 	MinorSupported = 4
 	PatchSupported = 0
 )
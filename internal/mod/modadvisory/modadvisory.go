@@ -0,0 +1,174 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modadvisory checks module versions against a database of
+// advisories, which describe published versions of modules that are known
+// to be bad, such as versions with insecure schema defaults.
+//
+// An advisory database served over HTTP, as accessed by [HTTPDatabase],
+// has one document per module, at the URL formed by appending a slash,
+// the module path including its major version, and ".json" to the URL of
+// the database. For instance, the advisories of example.com/foo@v1 in the
+// database https://advisories.example.com are found at
+// https://advisories.example.com/example.com/foo@v1.json. The documents
+// have the following schema:
+//
+//	advisories: [...{
+//		// id identifies the advisory, such as "CUE-2026-0001".
+//		id!: string
+//		// summary describes the problem in a sentence.
+//		summary!: string
+//		// url optionally links to more details.
+//		url?: string
+//		// affected lists the ranges of versions affected by the
+//		// advisory. A missing introduced version means that all
+//		// versions before fixed are affected, and a missing fixed
+//		// version that no version fixes the problem.
+//		affected!: [...{
+//			introduced?: string
+//			fixed?:      string
+//		}]
+//	}]
+//
+// A module without any advisory has no document; a missing document is
+// not an error.
+package modadvisory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+// A Database provides the advisories published about modules.
+type Database interface {
+	// Advisories returns the advisories about the module with the given
+	// path, which includes a major version.
+	Advisories(ctx context.Context, mpath string) ([]Advisory, error)
+}
+
+// An Advisory describes a problem with some versions of a module.
+type Advisory struct {
+	ID       string  `json:"id"`
+	Summary  string  `json:"summary"`
+	URL      string  `json:"url,omitempty"`
+	Affected []Range `json:"affected"`
+}
+
+// A Range is a range of affected versions, from Introduced included to
+// Fixed excluded.
+type Range struct {
+	// Introduced holds the first affected version. If empty, all the
+	// versions before Fixed are affected.
+	Introduced string `json:"introduced,omitempty"`
+
+	// Fixed holds the first version that is not affected after
+	// Introduced. If empty, all the versions from Introduced on are
+	// affected.
+	Fixed string `json:"fixed,omitempty"`
+}
+
+// Affects reports whether the given version is affected by a.
+func (a *Advisory) Affects(version string) bool {
+	for _, r := range a.Affected {
+		if r.Introduced != "" && semver.Compare(version, r.Introduced) < 0 {
+			continue
+		}
+		if r.Fixed != "" && semver.Compare(version, r.Fixed) >= 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// A Finding records that a module version is affected by an advisory.
+type Finding struct {
+	Module   module.Version
+	Advisory Advisory
+}
+
+func (f Finding) String() string {
+	s := fmt.Sprintf("%v: %s: %s", f.Module, f.Advisory.ID, f.Advisory.Summary)
+	if f.Advisory.URL != "" {
+		s += " (" + f.Advisory.URL + ")"
+	}
+	return s
+}
+
+// Check returns the advisories of db affecting any of the given module
+// versions, in the order of the versions.
+func Check(ctx context.Context, db Database, versions []module.Version) ([]Finding, error) {
+	var findings []Finding
+	for _, m := range versions {
+		advisories, err := db.Advisories(ctx, m.Path())
+		if err != nil {
+			return nil, fmt.Errorf("cannot get advisories for %s: %v", m.Path(), err)
+		}
+		for _, a := range advisories {
+			if a.Affects(m.Version()) {
+				findings = append(findings, Finding{Module: m, Advisory: a})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// HTTPDatabase is a Database served over HTTP, as described in the
+// package documentation.
+type HTTPDatabase struct {
+	// URL holds the URL of the database.
+	URL string
+
+	// Client is used to make requests to the database. If it is nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Advisories implements Database.
+func (db *HTTPDatabase) Advisories(ctx context.Context, mpath string) ([]Advisory, error) {
+	u := strings.TrimSuffix(db.URL, "/") + "/" + (&url.URL{Path: mpath}).EscapedPath() + ".json"
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := db.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	var doc struct {
+		Advisories []Advisory `json:"advisories"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid advisories at %s: %v", u, err)
+	}
+	return doc.Advisories, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modadvisory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/internal/mod/module"
+)
+
+func TestAffects(t *testing.T) {
+	a := &Advisory{Affected: []Range{
+		{Fixed: "v0.2.0"},
+		{Introduced: "v0.5.0", Fixed: "v0.5.3"},
+		{Introduced: "v1.0.0"},
+	}}
+	for v, want := range map[string]bool{
+		"v0.1.0":       true,
+		"v0.2.0":       false,
+		"v0.5.0-alpha": false,
+		"v0.5.0":       true,
+		"v0.5.2":       true,
+		"v0.5.3":       false,
+		"v1.0.0":       true,
+		"v1.3.0":       true,
+	} {
+		if got := a.Affects(v); got != want {
+			t.Errorf("Affects(%s) = %v; want %v", v, got, want)
+		}
+	}
+}
+
+func TestHTTPDatabase(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/db/example.com/foo@v0.json":
+			w.Write([]byte(`{"advisories": [{
+				"id": "CUE-2026-0001",
+				"summary": "insecure default for tls",
+				"url": "https://example.com/CUE-2026-0001",
+				"affected": [{"fixed": "v0.3.0"}]
+			}, {
+				"id": "CUE-2026-0002",
+				"summary": "port defaults to 0",
+				"affected": [{"introduced": "v0.5.0"}]
+			}]}`))
+		case "/db/bad.com@v0.json":
+			w.Write([]byte(`{"advisories": 1}`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+	db := &HTTPDatabase{URL: srv.URL + "/db/"}
+	versions := []module.Version{
+		module.MustNewVersion("example.com/foo@v0", "v0.2.0"),
+		module.MustNewVersion("other.com@v1", "v1.0.0"),
+	}
+	findings, err := Check(context.Background(), db, versions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	want := "example.com/foo@v0.2.0: CUE-2026-0001: insecure default for tls (https://example.com/CUE-2026-0001)"
+	if g := strings.Join(got, "\n"); g != want {
+		t.Errorf("got:\n%s\nwant:\n%s", g, want)
+	}
+
+	_, err = Check(context.Background(), db, []module.Version{module.MustNewVersion("bad.com@v0", "v0.1.0")})
+	if err == nil || !strings.Contains(err.Error(), "invalid advisories") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/rogpeppe/go-internal/lockedfile"
 	"github.com/rogpeppe/go-internal/robustio"
 
 	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+	"cuelang.org/go/tools/cachedir"
 )
 
 var errNotCached = fmt.Errorf("not in cache")
@@ -143,6 +148,41 @@ func (c *cache) cachePath(ctx context.Context, m module.Version, suffix string)
 	return filepath.Join(c.dir, "cache/download", esc, "/@v", encVer+"."+suffix), nil
 }
 
+// addToList adds the version of m to the list of versions of its module
+// held in the download cache, as served by a module proxy, so that the
+// download cache can be used as a module proxy.
+func (c *cache) addToList(m module.Version) error {
+	esc, err := module.EscapePath(m.BasePath())
+	if err != nil {
+		return err
+	}
+	f, err := lockedfile.Edit(filepath.Join(c.dir, "cache/download", esc, "@v", "list"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	versions := strings.Fields(string(data))
+	if slices.Contains(versions, m.Version()) {
+		return nil
+	}
+	versions = append(versions, m.Version())
+	semver.Sort(versions)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, strings.Join(versions, "\n")+"\n"); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
 // downloadDirPartialError is returned by DownloadDir if a module directory
 // exists but was not completely populated.
 //
@@ -165,5 +205,20 @@ func (c *cache) lockVersion(ctx context.Context, mod module.Version) (unlock fun
 	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
 		return nil, err
 	}
-	return lockedfile.MutexAt(path).Lock()
+	// Writes to the cache are done while holding this lock, so also hold
+	// a shared lock on the whole cache, so that it is not cleaned from
+	// under them.
+	unlockCache, err := cachedir.RLockDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	unlockVersion, err := lockedfile.MutexAt(path).Lock()
+	if err != nil {
+		unlockCache()
+		return nil, err
+	}
+	return func() {
+		unlockVersion()
+		unlockCache()
+	}, nil
 }
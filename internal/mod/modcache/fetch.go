@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"log"
+	"log/slog"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -14,20 +14,19 @@ import (
 	"strings"
 
 	"cuelabs.dev/go/oci/ociregistry"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/rogpeppe/go-internal/robustio"
 
+	"cuelang.org/go/cue/logging"
 	"cuelang.org/go/internal/mod/internal/par"
 	"cuelang.org/go/internal/mod/modfile"
 	"cuelang.org/go/internal/mod/modload"
 	"cuelang.org/go/internal/mod/modpkgload"
-	"cuelang.org/go/internal/mod/modregistry"
 	"cuelang.org/go/internal/mod/modrequirements"
 	"cuelang.org/go/internal/mod/module"
 	"cuelang.org/go/internal/mod/modzip"
 )
 
-const logging = false // TODO hook this up to CUE_DEBUG
-
 // New returns r wrapped inside a caching layer that
 // stores persistent cached content inside the given
 // OS directory.
@@ -37,6 +36,26 @@ const logging = false // TODO hook this up to CUE_DEBUG
 // allowing a caller to find the native OS filepath where modules
 // are stored.
 func New(registry ociregistry.Interface, dir string) (modload.Registry, error) {
+	return NewFromSource(RegistrySource(registry), dir, nil)
+}
+
+// A Checker checks the contents of module versions before they are
+// used, such as against the checksums in a checksum database.
+type Checker interface {
+	// CheckModFile checks the contents of the cue.mod/module.cue file
+	// of the given module version.
+	CheckModFile(ctx context.Context, mv module.Version, data []byte) error
+
+	// CheckZip checks the digest of the zip file of the given
+	// module version.
+	CheckZip(ctx context.Context, mv module.Version, zipDigest ociregistry.Digest) error
+}
+
+// NewFromSource is like [New] but downloads modules from the given
+// source. If chk is not nil, the contents of each module version are
+// checked with it before being used, whether they were just downloaded
+// or already held in the cache.
+func NewFromSource(src Source, dir string, chk Checker) (modload.Registry, error) {
 	info, err := os.Stat(dir)
 	if err != nil {
 		return nil, err
@@ -45,14 +64,16 @@ func New(registry ociregistry.Interface, dir string) (modload.Registry, error) {
 		return nil, fmt.Errorf("%q is not a directory", dir)
 	}
 	return &cache{
-		dir: dir,
-		reg: modregistry.NewClient(registry),
+		dir:     dir,
+		src:     src,
+		checker: chk,
 	}, nil
 }
 
 type cache struct {
 	dir              string
-	reg              *modregistry.Client
+	src              Source
+	checker          Checker
 	downloadZipCache par.ErrCache[module.Version, string]
 	modFileCache     par.ErrCache[string, []byte]
 }
@@ -62,6 +83,11 @@ func (c *cache) CUEModSummary(ctx context.Context, mv module.Version) (*modrequi
 	if err != nil {
 		return nil, err
 	}
+	if c.checker != nil {
+		if err := c.checker.CheckModFile(ctx, mv, data); err != nil {
+			return nil, err
+		}
+	}
 	mf, err := modfile.Parse(data, mv.String())
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse module file from %v: %v", mv, err)
@@ -78,6 +104,9 @@ func (c *cache) Fetch(ctx context.Context, mv module.Version) (modpkgload.Source
 	dir, err := c.downloadDir(ctx, mv)
 	if err == nil {
 		// The directory has already been completely extracted (no .partial file exists).
+		if err := c.checkZip(ctx, mv); err != nil {
+			return modpkgload.SourceLoc{}, err
+		}
 		return c.dirToLocation(dir), nil
 	}
 	if dir == "" || !errors.Is(err, fs.ErrNotExist) {
@@ -91,6 +120,9 @@ func (c *cache) Fetch(ctx context.Context, mv module.Version) (modpkgload.Source
 	if err != nil {
 		return modpkgload.SourceLoc{}, err
 	}
+	if err := c.checkZip(ctx, mv); err != nil {
+		return modpkgload.SourceLoc{}, err
+	}
 
 	unlock, err := c.lockVersion(ctx, mv)
 	if err != nil {
@@ -161,7 +193,7 @@ func (c *cache) Fetch(ctx context.Context, mv module.Version) (modpkgload.Source
 // ModuleVersions implements [modload.Registry.ModuleVersions].
 func (c *cache) ModuleVersions(ctx context.Context, mpath string) ([]string, error) {
 	// TODO should this do any kind of short-term caching?
-	return c.reg.ModuleVersions(ctx, mpath)
+	return c.src.ModuleVersions(ctx, mpath)
 }
 
 func (c *cache) downloadZip(ctx context.Context, mv module.Version) (zipfile string, err error) {
@@ -226,26 +258,42 @@ func (c *cache) downloadZip1(ctx context.Context, mod module.Version, zipfile st
 		}
 	}()
 
-	// TODO cache the result of GetModule so we don't have to do
-	// an extra round trip when we've already fetched the module file.
-	m, err := c.reg.GetModule(ctx, mod)
-	if err != nil {
-		return err
-	}
-	r, err := m.GetZip(ctx)
+	r, wantDigest, err := c.src.GetZip(ctx, mod)
 	if err != nil {
 		return err
 	}
 	defer r.Close()
-	if _, err := io.Copy(f, r); err != nil {
+	digester := digest.Canonical.Digester()
+	if _, err := io.Copy(io.MultiWriter(f, digester.Hash()), r); err != nil {
 		return fmt.Errorf("failed to get module zip contents: %v", err)
 	}
+	zipDigest := digester.Digest()
+	if wantDigest != "" && zipDigest != wantDigest {
+		return fmt.Errorf("module %v: zip digest mismatch: downloaded %s, manifest has %s", mod, zipDigest, wantDigest)
+	}
+	if c.checker != nil {
+		if err := c.checker.CheckZip(ctx, mod, zipDigest); err != nil {
+			return err
+		}
+	}
 	if err := f.Close(); err != nil {
 		return err
 	}
+	// Record the digest before the zip file is put in place, so that
+	// there is always a digest to verify a zip file against.
+	hashfile, err := c.cachePath(ctx, mod, "ziphash")
+	if err != nil {
+		return err
+	}
+	if err := c.writeDiskCache(ctx, hashfile, []byte(zipDigest.String()+"\n")); err != nil {
+		return err
+	}
 	if err := os.Rename(f.Name(), zipfile); err != nil {
 		return err
 	}
+	if err := c.addToList(mod); err != nil {
+		return err
+	}
 	// TODO should we check the zip file for well-formedness?
 	// TODO: Should we make the .zip file read-only to discourage tampering?
 	return nil
@@ -274,13 +322,15 @@ func (c *cache) downloadModFile(ctx context.Context, mod module.Version) ([]byte
 }
 
 func (c *cache) downloadModFile1(ctx context.Context, mod module.Version, modfile string) ([]byte, error) {
-	m, err := c.reg.GetModule(ctx, mod)
+	data, err := c.src.ModuleFile(ctx, mod)
 	if err != nil {
 		return nil, err
 	}
-	data, err := m.ModuleFile(ctx)
-	if err != nil {
-		return nil, err
+	if c.checker != nil {
+		// Check before writing, so that a bad module file is not cached.
+		if err := c.checker.CheckModFile(ctx, mod, data); err != nil {
+			return nil, err
+		}
 	}
 	if err := c.writeDiskModFile(ctx, modfile, data); err != nil {
 		return nil, err
@@ -288,6 +338,23 @@ func (c *cache) downloadModFile1(ctx context.Context, mod module.Version, modfil
 	return data, nil
 }
 
+// checkZip checks the digest recorded for the zip file of mv in the
+// cache with the checker, if any.
+func (c *cache) checkZip(ctx context.Context, mv module.Version) error {
+	if c.checker == nil {
+		return nil
+	}
+	_, data, err := c.readDiskCache(ctx, mv, "ziphash")
+	if err != nil {
+		return fmt.Errorf("%v: no digest recorded for the module zip file; remove the module cache and download the module again", mv)
+	}
+	zipDigest, err := digest.Parse(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("%v: invalid digest recorded for the module zip file: %v", mv, err)
+	}
+	return c.checker.CheckZip(ctx, mv, zipDigest)
+}
+
 func (c *cache) dirToLocation(fpath string) modpkgload.SourceLoc {
 	return modpkgload.SourceLoc{
 		FS:  dirFS(fpath),
@@ -369,8 +436,10 @@ func tempFile(ctx context.Context, dir, prefix string, perm fs.FileMode) (f *os.
 	return
 }
 
+var logger = logging.For(logging.Registry)
+
 func logf(f string, a ...any) {
-	if logging {
-		log.Printf(f, a...)
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		logger.Debug(fmt.Sprintf(f, a...))
 	}
 }
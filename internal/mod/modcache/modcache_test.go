@@ -18,8 +18,8 @@ import (
 	"cuelang.org/go/internal/mod/modpkgload"
 	"cuelang.org/go/internal/mod/modrequirements"
 	"cuelang.org/go/internal/mod/module"
-	"cuelang.org/go/internal/registrytest"
 	"cuelang.org/go/internal/txtarfs"
+	"cuelang.org/go/mod/modregistrytest"
 )
 
 func TestCUEModSummary(t *testing.T) {
@@ -148,6 +148,122 @@ package x
 	fetch(nil)
 }
 
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() {
+		RemoveAll(dir)
+	})
+	ctx := context.Background()
+	r := newRegistry(t, `
+-- example.com_foo_v0.0.1/cue.mod/module.cue --
+module: "example.com/foo@v0"
+-- example.com_foo_v0.0.1/example.cue --
+package example
+`)
+	mv := module.MustNewVersion("example.com/foo", "v0.0.1")
+
+	_, err := Verify(dir, mv)
+	qt.Assert(t, qt.ErrorIs(err, fs.ErrNotExist))
+
+	cr, err := New(r, dir)
+	qt.Assert(t, qt.IsNil(err))
+	loc, err := cr.Fetch(ctx, mv)
+	qt.Assert(t, qt.IsNil(err))
+	zipDigest, err := Verify(dir, mv)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Matches(string(zipDigest), `sha256:[0-9a-f]{64}`))
+
+	// Modifying an extracted file is detected.
+	srcPath := filepath.Join(loc.FS.(OSRootFS).OSRoot(), loc.Dir, "example.cue")
+	qt.Assert(t, qt.IsNil(os.Chmod(srcPath, 0o666)))
+	qt.Assert(t, qt.IsNil(os.WriteFile(srcPath, []byte("package other\n"), 0o666)))
+	_, err = Verify(dir, mv)
+	qt.Assert(t, qt.ErrorMatches(err, `example.com/foo@v0.0.1: dir has been modified \(.*\): file example.cue has changed`))
+
+	// As is modifying the zip file.
+	zipPath := filepath.Join(dir, "cache/download/example.com/foo/@v/v0.0.1.zip")
+	qt.Assert(t, qt.IsNil(os.WriteFile(zipPath, []byte("corrupt"), 0o666)))
+	_, err = Verify(dir, mv)
+	qt.Assert(t, qt.ErrorMatches(err, `example.com/foo@v0.0.1: zip has been modified \(.*\): digest sha256:.*, recorded `+string(zipDigest)))
+}
+
+func TestFetchChecker(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() {
+		RemoveAll(dir)
+	})
+	ctx := context.Background()
+	r := newRegistry(t, `
+-- example.com_foo_v0.0.1/cue.mod/module.cue --
+module: "example.com/foo@v0"
+-- example.com_foo_v0.0.1/example.cue --
+package example
+`)
+	mv := module.MustNewVersion("example.com/foo", "v0.0.1")
+
+	// A rejected module is not added to the cache.
+	cr, err := NewFromSource(RegistrySource(r), dir, &testChecker{reject: true})
+	qt.Assert(t, qt.IsNil(err))
+	_, err = cr.CUEModSummary(ctx, mv)
+	qt.Assert(t, qt.ErrorMatches(err, `rejected mod example.com/foo@v0.0.1`))
+	_, err = cr.Fetch(ctx, mv)
+	qt.Assert(t, qt.ErrorMatches(err, `rejected zip example.com/foo@v0.0.1`))
+	_, err = CachedModFile(dir, mv)
+	qt.Assert(t, qt.ErrorIs(err, fs.ErrNotExist))
+	_, err = Verify(dir, mv)
+	qt.Assert(t, qt.ErrorIs(err, fs.ErrNotExist))
+
+	chk := &testChecker{}
+	cr, err = NewFromSource(RegistrySource(r), dir, chk)
+	qt.Assert(t, qt.IsNil(err))
+	_, err = cr.CUEModSummary(ctx, mv)
+	qt.Assert(t, qt.IsNil(err))
+	_, err = cr.Fetch(ctx, mv)
+	qt.Assert(t, qt.IsNil(err))
+	zipDigest, err := Verify(dir, mv)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(chk.zipDigest, zipDigest))
+	data, err := CachedModFile(dir, mv)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(data), string(chk.modData)))
+
+	// Modules already in the cache are checked too.
+	cr, err = NewFromSource(RegistrySource(nil), dir, &testChecker{reject: true})
+	qt.Assert(t, qt.IsNil(err))
+	_, err = cr.CUEModSummary(ctx, mv)
+	qt.Assert(t, qt.ErrorMatches(err, `rejected mod example.com/foo@v0.0.1`))
+	_, err = cr.Fetch(ctx, mv)
+	qt.Assert(t, qt.ErrorMatches(err, `rejected zip example.com/foo@v0.0.1`))
+}
+
+type testChecker struct {
+	reject bool
+
+	mu        sync.Mutex
+	modData   []byte
+	zipDigest ociregistry.Digest
+}
+
+func (c *testChecker) CheckModFile(ctx context.Context, mv module.Version, data []byte) error {
+	if c.reject {
+		return fmt.Errorf("rejected mod %v", mv)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.modData = data
+	return nil
+}
+
+func (c *testChecker) CheckZip(ctx context.Context, mv module.Version, zipDigest ociregistry.Digest) error {
+	if c.reject {
+		return fmt.Errorf("rejected zip %v", mv)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.zipDigest = zipDigest
+	return nil
+}
+
 func fsSub(fsys fs.FS, sub string) fs.FS {
 	fsys, err := fs.Sub(fsys, sub)
 	if err != nil {
@@ -180,7 +296,7 @@ func txtarContents(fsys fs.FS) ([]byte, error) {
 }
 
 func newRegistry(t *testing.T, registryContents string) ociregistry.Interface {
-	regSrv, err := registrytest.New(txtarfs.FS(txtar.Parse([]byte(registryContents))), "")
+	regSrv, err := modregistrytest.New(txtarfs.FS(txtar.Parse([]byte(registryContents))), "")
 	qt.Assert(t, qt.IsNil(err))
 	t.Cleanup(regSrv.Close)
 	regOCI, err := ociclient.New(regSrv.Host(), &ociclient.Options{
@@ -0,0 +1,134 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modcache
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"cuelabs.dev/go/oci/ociregistry"
+
+	"cuelang.org/go/internal/mod/modregistry"
+	"cuelang.org/go/internal/mod/module"
+)
+
+// A Source is a store from which the cache downloads modules, such as
+// an OCI registry or a module proxy.
+//
+// Errors about modules or versions missing from a source satisfy
+// errors.Is(err, modregistry.ErrNotFound).
+type Source interface {
+	// ModuleVersions returns all the versions for the module with the
+	// given path sorted in semver order. If m has a major version
+	// suffix, only versions with that major version are returned.
+	ModuleVersions(ctx context.Context, m string) ([]string, error)
+
+	// ModuleFile returns the contents of the cue.mod/module.cue file
+	// of the given module version.
+	ModuleFile(ctx context.Context, mv module.Version) ([]byte, error)
+
+	// GetZip returns a reader for the zip archive holding the files of
+	// the given module version, and the digest that the archive is
+	// expected to have, or the empty digest if the source records none.
+	GetZip(ctx context.Context, mv module.Version) (io.ReadCloser, ociregistry.Digest, error)
+}
+
+// RegistrySource returns a source that downloads modules from an OCI
+// registry.
+func RegistrySource(registry ociregistry.Interface) Source {
+	return registrySource{modregistry.NewClient(registry)}
+}
+
+type registrySource struct {
+	client *modregistry.Client
+}
+
+func (s registrySource) ModuleVersions(ctx context.Context, m string) ([]string, error) {
+	return s.client.ModuleVersions(ctx, m)
+}
+
+func (s registrySource) ModuleFile(ctx context.Context, mv module.Version) ([]byte, error) {
+	// TODO cache the result of GetModule so we don't have to do
+	// an extra round trip when the zip is fetched too.
+	m, err := s.client.GetModule(ctx, mv)
+	if err != nil {
+		return nil, err
+	}
+	return m.ModuleFile(ctx)
+}
+
+func (s registrySource) GetZip(ctx context.Context, mv module.Version) (io.ReadCloser, ociregistry.Digest, error) {
+	m, err := s.client.GetModule(ctx, mv)
+	if err != nil {
+		return nil, "", err
+	}
+	r, err := m.GetZip(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return r, m.ZipDigest(), nil
+}
+
+// Fallback returns a source that consults each of the given sources in
+// turn, moving on to the next one when a module or version is missing
+// from a source. Its ModuleVersions method returns the versions of the
+// first source that has any.
+func Fallback(srcs ...Source) Source {
+	if len(srcs) == 1 {
+		return srcs[0]
+	}
+	return fallbackSource(srcs)
+}
+
+type fallbackSource []Source
+
+func (s fallbackSource) ModuleVersions(ctx context.Context, m string) ([]string, error) {
+	for _, src := range s {
+		versions, err := src.ModuleVersions(ctx, m)
+		if err != nil && !errors.Is(err, modregistry.ErrNotFound) {
+			return nil, err
+		}
+		if len(versions) > 0 {
+			return versions, nil
+		}
+	}
+	return []string{}, nil
+}
+
+func (s fallbackSource) ModuleFile(ctx context.Context, mv module.Version) ([]byte, error) {
+	var err error
+	for _, src := range s {
+		var data []byte
+		data, err = src.ModuleFile(ctx, mv)
+		if !errors.Is(err, modregistry.ErrNotFound) {
+			return data, err
+		}
+	}
+	return nil, err
+}
+
+func (s fallbackSource) GetZip(ctx context.Context, mv module.Version) (io.ReadCloser, ociregistry.Digest, error) {
+	var err error
+	for _, src := range s {
+		var r io.ReadCloser
+		var zipDigest ociregistry.Digest
+		r, zipDigest, err = src.GetZip(ctx, mv)
+		if !errors.Is(err, modregistry.ErrNotFound) {
+			return r, zipDigest, err
+		}
+	}
+	return nil, "", err
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modcache
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelabs.dev/go/oci/ociregistry"
+	digest "github.com/opencontainers/go-digest"
+
+	"cuelang.org/go/internal/mod/module"
+)
+
+// Verify checks that the copy of module version mv held in the cache
+// directory dir has not been modified since it was downloaded:
+// the digest of the module's zip file must match the one recorded when
+// it was downloaded, and the extracted files, if any, must match the
+// contents of the zip file. It returns the digest of the zip file.
+//
+// If mv is not in the cache, Verify returns an error satisfying
+// errors.Is(err, fs.ErrNotExist).
+func Verify(dir string, mv module.Version) (ociregistry.Digest, error) {
+	ctx := context.Background()
+	c := &cache{dir: dir}
+	zipfile, err := c.cachePath(ctx, mv, "zip")
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(zipfile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%v: %w", mv, fs.ErrNotExist)
+		}
+		return "", err
+	}
+	defer f.Close()
+	zipDigest, err := digest.Canonical.FromReader(f)
+	if err != nil {
+		return "", err
+	}
+	_, data, err := c.readDiskCache(ctx, mv, "ziphash")
+	if err != nil {
+		return "", fmt.Errorf("%v: no digest recorded for %s; remove the module cache and download the module again", mv, zipfile)
+	}
+	if want := digest.Digest(strings.TrimSpace(string(data))); zipDigest != want {
+		return "", fmt.Errorf("%v: zip has been modified (%s): digest %s, recorded %s", mv, zipfile, zipDigest, want)
+	}
+
+	modDir, err := c.downloadDir(ctx, mv)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// The module has not been extracted.
+			return zipDigest, nil
+		}
+		return "", err
+	}
+	if err := verifyDir(modDir, zipfile); err != nil {
+		return "", fmt.Errorf("%v: dir has been modified (%s): %v", mv, modDir, err)
+	}
+	return zipDigest, nil
+}
+
+// CachedModFile returns the contents of the cue.mod/module.cue file of
+// module version mv held in the cache directory dir. If the file is not
+// in the cache, it returns an error satisfying
+// errors.Is(err, fs.ErrNotExist).
+func CachedModFile(dir string, mv module.Version) ([]byte, error) {
+	c := &cache{dir: dir}
+	_, data, err := c.readDiskModFile(context.Background(), mv)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", mv, fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+// verifyDir checks that the regular files in dir are exactly those
+// in the given zip file.
+func verifyDir(dir, zipfile string) error {
+	zr, err := zip.OpenReader(zipfile)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	want := make(map[string]*zip.File)
+	for _, zf := range zr.File {
+		want[zf.Name] = zf
+	}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		zf := want[name]
+		if zf == nil {
+			return fmt.Errorf("unexpected file %s", name)
+		}
+		delete(want, name)
+		got, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		r, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		wantData, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(got, wantData) {
+			return fmt.Errorf("file %s has changed", name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, zf := range zr.File {
+		if want[zf.Name] != nil {
+			return fmt.Errorf("missing file %s", zf.Name)
+		}
+	}
+	return nil
+}
@@ -16,7 +16,10 @@ package modfile
 
 import (
 	_ "embed"
+	"encoding/json"
 	"fmt"
+	"path"
+	"strings"
 	"sync"
 
 	"cuelang.org/go/internal/mod/semver"
@@ -40,6 +43,12 @@ type File struct {
 	Module   string          `json:"module"`
 	Language *Language       `json:"language,omitempty"`
 	Deps     map[string]*Dep `json:"deps,omitempty"`
+	Files    *Files          `json:"files,omitempty"`
+
+	// Changelog holds the URL of the changes made in each version of
+	// the module. See [File.ChangelogURL].
+	Changelog string `json:"changelog,omitempty"`
+
 	versions []module.Version
 	// defaultMajorVersions maps from module base path to the
 	// major version default for that path.
@@ -82,16 +91,147 @@ func (f *File) Format() ([]byte, error) {
 }
 
 type Language struct {
-	Version string `json:"version,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Experiments []string `json:"experiments,omitempty"`
 }
 
 type Dep struct {
 	Version string `json:"v"`
 	Default bool   `json:"default,omitempty"`
+
+	// Replace maps versions of the module to their replacements.
+	Replace map[string]*Replacement `json:"replace,omitempty"`
+
+	// ReplaceAll holds the replacement of all versions of the module.
+	ReplaceAll *Replacement `json:"replaceAll,omitempty"`
+}
+
+// A Replacement is the replacement of a dependency: either a local
+// directory or a version of another module. In CUE, it is represented as
+// the directory name or as a struct {m: module, v: version}.
+type Replacement struct {
+	// Dir holds the directory of the replacement. A relative directory
+	// is relative to the root of the main module.
+	Dir string
+
+	// Module and Version hold the module path and version of the
+	// replacement if Dir is empty.
+	Module  string
+	Version string
+}
+
+// String returns the directory or module version of r.
+func (r *Replacement) String() string {
+	if r.Dir != "" {
+		return r.Dir
+	}
+	return r.Module + "@" + r.Version
+}
+
+type replacementModule struct {
+	Module  string `json:"m"`
+	Version string `json:"v"`
+}
+
+// MarshalJSON implements [json.Marshaler].
+func (r *Replacement) MarshalJSON() ([]byte, error) {
+	if r.Dir != "" {
+		return json.Marshal(r.Dir)
+	}
+	return json.Marshal(replacementModule{r.Module, r.Version})
+}
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (r *Replacement) UnmarshalJSON(data []byte) error {
+	*r = Replacement{}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &r.Dir)
+	}
+	var m replacementModule
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	r.Module, r.Version = m.Module, m.Version
+	return nil
+}
+
+// check reports whether r is a valid replacement.
+func (r *Replacement) check() error {
+	if r.Dir != "" {
+		if !strings.HasPrefix(r.Dir, "./") && !strings.HasPrefix(r.Dir, "../") && !strings.HasPrefix(r.Dir, "/") {
+			return fmt.Errorf("replacement directory %q must start with ./, ../ or /", r.Dir)
+		}
+		return nil
+	}
+	if _, err := module.NewVersion(r.Module, r.Version); err != nil {
+		return fmt.Errorf("invalid replacement module: %v", err)
+	}
+	return nil
+}
+
+// Files specifies the files included in a published module.
+type Files struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Includes reports whether the file with the given slash-separated path,
+// relative to the module root, is included in the module when it is
+// published. A nil Files includes all files.
+func (fs *Files) Includes(p string) bool {
+	if p == "cue.mod/module.cue" {
+		return true
+	}
+	if fs == nil {
+		return true
+	}
+	if len(fs.Include) > 0 && !matchAny(fs.Include, p) {
+		return false
+	}
+	return !matchAny(fs.Exclude, p)
+}
+
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if MatchFile(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchFile reports whether the file pattern matches the slash-separated
+// file path p or any of its parent directories. A pattern without a slash
+// is also matched against the base name of p.
+func MatchFile(pattern, p string) bool {
+	if !strings.Contains(pattern, "/") {
+		if ok, _ := path.Match(pattern, path.Base(p)); ok {
+			return true
+		}
+	}
+	pattern = strings.TrimSuffix(pattern, "/")
+	for ; p != "."; p = path.Dir(p) {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPattern checks that pattern is a valid file pattern.
+func checkPattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid file pattern %q: %v", pattern, err)
+	}
+	if path.IsAbs(pattern) || pattern == ".." || strings.HasPrefix(pattern, "../") {
+		return fmt.Errorf("invalid file pattern %q: must be relative to the module root", pattern)
+	}
+	return nil
 }
 
 type noDepsFile struct {
-	Module string `json:"module"`
+	Module   string    `json:"module"`
+	Language *Language `json:"language,omitempty"`
 }
 
 var (
@@ -144,7 +284,8 @@ func ParseLegacy(modfile []byte, filename string) (*File, error) {
 			return nil, newCUEError(err, filename)
 		}
 		return &File{
-			Module: f.Module,
+			Module:   f.Module,
+			Language: f.Language,
 		}, nil
 	})
 }
@@ -197,6 +338,15 @@ func parse(modfile []byte, filename string, strict bool) (*File, error) {
 			return nil, fmt.Errorf("language version %q in %s is not well formed", vers, filename)
 		}
 	}
+	if mf.Files != nil {
+		for _, patterns := range [][]string{mf.Files.Include, mf.Files.Exclude} {
+			for _, pattern := range patterns {
+				if err := checkPattern(pattern); err != nil {
+					return nil, fmt.Errorf("invalid module.cue file %s: %v", filename, err)
+				}
+			}
+		}
+	}
 	var versions []module.Version
 	defaultMajorVersions := make(map[string]string)
 	// Check that major versions match dependency versions.
@@ -209,6 +359,9 @@ func parse(modfile []byte, filename string, strict bool) (*File, error) {
 		if strict && vers.Path() != m {
 			return nil, fmt.Errorf("invalid module.cue file %s: no major version in %q", filename, m)
 		}
+		if err := checkReplacements(m, dep); err != nil {
+			return nil, fmt.Errorf("invalid module.cue file %s: %v", filename, err)
+		}
 		if dep.Default {
 			mp := vers.BasePath()
 			if _, ok := defaultMajorVersions[mp]; ok {
@@ -226,11 +379,66 @@ func parse(modfile []byte, filename string, strict bool) (*File, error) {
 	return mf, nil
 }
 
+// checkReplacements checks the replacements of the dependency dep on
+// module m.
+func checkReplacements(m string, dep *Dep) error {
+	if len(dep.Replace) > 0 && dep.ReplaceAll != nil {
+		return fmt.Errorf("dependency %q has both replace and replaceAll", m)
+	}
+	if dep.ReplaceAll != nil {
+		if err := dep.ReplaceAll.check(); err != nil {
+			return fmt.Errorf("dependency %q: %v", m, err)
+		}
+	}
+	_, major, _ := module.SplitPathVersion(m)
+	for vers, r := range dep.Replace {
+		if semver.Canonical(vers) != vers {
+			return fmt.Errorf("dependency %q: replaced version %q is not a canonical semantic version", m, vers)
+		}
+		if major != "" && semver.Major(vers) != major {
+			return fmt.Errorf("dependency %q: replaced version %q does not match the major version of the module", m, vers)
+		}
+		if err := r.check(); err != nil {
+			return fmt.Errorf("dependency %q: %v", m, err)
+		}
+	}
+	return nil
+}
+
 func newCUEError(err error, filename string) error {
 	// TODO we have some potential to improve error messages here.
 	return err
 }
 
+// ChangelogURL returns the URL of the changes made in the given version
+// of the module, or the empty string if the module does not record one.
+func (f *File) ChangelogURL(version string) string {
+	return strings.ReplaceAll(f.Changelog, "{version}", version)
+}
+
+// Replacement returns the replacement of module version m, or nil if it
+// is not replaced. Only the replacements of the main module apply.
+func (f *File) Replacement(m module.Version) *Replacement {
+	dep := f.Deps[m.Path()]
+	if dep == nil {
+		return nil
+	}
+	if dep.ReplaceAll != nil {
+		return dep.ReplaceAll
+	}
+	return dep.Replace[m.Version()]
+}
+
+// HasReplacements reports whether any dependency of f is replaced.
+func (f *File) HasReplacements() bool {
+	for _, dep := range f.Deps {
+		if dep.ReplaceAll != nil || len(dep.Replace) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // DepVersions returns the versions of all the modules depended on by the
 // file. The caller should not modify the returned slice.
 //
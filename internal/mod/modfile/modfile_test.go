@@ -146,6 +146,103 @@ module: "foo.com/bar@v1"
 deps: "example.com@v1": v: "v0.1.2"
 `,
 	wantError: `invalid module.cue file module.cue: cannot make version from module "example.com@v1", version "v0.1.2": mismatched major version suffix in "example.com@v1" \(version v0.1.2\)`,
+}, {
+	testName: "WithFiles",
+	parse:    Parse,
+	data: `
+module: "foo.com/bar@v0"
+files: {
+	include: ["*.cue", "LICENSE"]
+	exclude: ["testdata"]
+}
+`,
+	want: &File{
+		Module: "foo.com/bar@v0",
+		Files: &Files{
+			Include: []string{"*.cue", "LICENSE"},
+			Exclude: []string{"testdata"},
+		},
+	},
+}, {
+	testName: "InvalidFilePattern",
+	parse:    Parse,
+	data: `
+module: "foo.com/bar@v0"
+files: include: ["[a-"]
+`,
+	wantError: `invalid module.cue file module.cue: invalid file pattern "\[a-": syntax error in pattern`,
+}, {
+	testName: "FilePatternOutsideModule",
+	parse:    Parse,
+	data: `
+module: "foo.com/bar@v0"
+files: exclude: ["../x"]
+`,
+	wantError: `invalid module.cue file module.cue: invalid file pattern "../x": must be relative to the module root`,
+}, {
+	testName: "WithReplacements",
+	parse:    Parse,
+	data: `
+module: "foo.com/bar@v0"
+deps: "example.com@v1": {
+	v: "v1.2.3"
+	replaceAll: "../example"
+}
+deps: "other.com/something@v0": {
+	v: "v0.2.3"
+	replace: "v0.2.3": {m: "fork.com/something@v0", v: "v0.2.4"}
+}
+`,
+	want: &File{
+		Module: "foo.com/bar@v0",
+		Deps: map[string]*Dep{
+			"example.com@v1": {
+				Version:    "v1.2.3",
+				ReplaceAll: &Replacement{Dir: "../example"},
+			},
+			"other.com/something@v0": {
+				Version: "v0.2.3",
+				Replace: map[string]*Replacement{
+					"v0.2.3": {Module: "fork.com/something@v0", Version: "v0.2.4"},
+				},
+			},
+		},
+	},
+	wantVersions: parseVersions("example.com@v1.2.3", "other.com/something@v0.2.3"),
+}, {
+	testName: "ReplaceAndReplaceAll",
+	parse:    Parse,
+	data: `
+module: "foo.com/bar@v0"
+deps: "example.com@v1": {
+	v: "v1.2.3"
+	replace: "v1.2.3": "./a"
+	replaceAll: "./b"
+}
+`,
+	wantError: `invalid module.cue file module.cue: dependency "example.com@v1" has both replace and replaceAll`,
+}, {
+	testName: "InvalidReplacementDir",
+	parse:    Parse,
+	data: `
+module: "foo.com/bar@v0"
+deps: "example.com@v1": {
+	v: "v1.2.3"
+	replaceAll: "example"
+}
+`,
+	wantError: `invalid module.cue file module.cue: dependency "example.com@v1": replacement directory "example" must start with ./, ../ or /`,
+}, {
+	testName: "ReplacedVersionOfOtherMajor",
+	parse:    Parse,
+	data: `
+module: "foo.com/bar@v0"
+deps: "example.com@v1": {
+	v: "v1.2.3"
+	replace: "v2.0.0": "./a"
+}
+`,
+	wantError: `invalid module.cue file module.cue: dependency "example.com@v1": replaced version "v2.0.0" does not match the major version of the module`,
 }, {
 	testName: "NonStrictNoMajorVersions",
 	parse:    ParseNonStrict,
@@ -248,6 +345,53 @@ language: {
 			},
 		},
 		wantError: `cannot round-trip module file: language version "badversion--" in - is not well formed`,
+	}, {
+		name: "WithFiles",
+		file: &File{
+			Module: "foo.com/bar@v0",
+			Files: &Files{
+				Include: []string{"*.cue"},
+			},
+		},
+		want: `module: "foo.com/bar@v0"
+files: {
+	include: ["*.cue"]
+}
+`,
+	}, {
+		name: "WithReplacements",
+		file: &File{
+			Module: "foo.com/bar@v0",
+			Deps: map[string]*Dep{
+				"example.com@v1": {
+					Version:    "v1.2.3",
+					ReplaceAll: &Replacement{Dir: "../example"},
+				},
+				"other.com/something@v0": {
+					Version: "v0.2.3",
+					Replace: map[string]*Replacement{
+						"v0.2.3": {Module: "fork.com/something@v0", Version: "v0.2.4"},
+					},
+				},
+			},
+		},
+		want: `module: "foo.com/bar@v0"
+deps: {
+	"example.com@v1": {
+		v:          "v1.2.3"
+		replaceAll: "../example"
+	}
+	"other.com/something@v0": {
+		v: "v0.2.3"
+		replace: {
+			"v0.2.3": {
+				m: "fork.com/something@v0"
+				v: "v0.2.4"
+			}
+		}
+	}
+}
+`,
 	}, {
 		name: "WithNonNilEmptyDeps",
 		file: &File{
@@ -280,3 +424,31 @@ func parseVersions(vs ...string) []module.Version {
 	}
 	return vvs
 }
+
+func TestFilesIncludes(t *testing.T) {
+	files := &Files{
+		Include: []string{"*.cue", "data/", "LICENSE"},
+		Exclude: []string{"*_test.cue", "internal/x"},
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"cue.mod/module.cue", true},
+		{"foo.cue", true},
+		{"a/b/foo.cue", true},
+		{"foo_test.cue", false},
+		{"data/x.json", true},
+		{"data/sub/y.yaml", true},
+		{"other/data/x.json", false},
+		{"LICENSE", true},
+		{"README.md", false},
+		{"internal/x/foo.cue", false},
+		{"internal/y/foo.cue", true},
+	}
+	for _, test := range tests {
+		qt.Check(t, qt.Equals(files.Includes(test.path), test.want), qt.Commentf("path %q", test.path))
+	}
+	var nilFiles *Files
+	qt.Check(t, qt.IsTrue(nilFiles.Includes("README.md")))
+}
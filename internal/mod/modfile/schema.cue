@@ -32,9 +32,7 @@ let unimplemented = 1&2
 		retract?:    unimplemented
 		publish?:    unimplemented
 		#Dep: {
-			exclude?:    unimplemented
-			replace?:    unimplemented
-			replaceAll?: unimplemented
+			exclude?: unimplemented
 		}
 	}
 	// module indicates the module's path.
@@ -46,11 +44,25 @@ let unimplemented = 1&2
 	// is evaluating code in this module, this will be used to
 	// choose version-specific behavior. If an earlier version of CUE
 	// is used, an error will be given.
-	language?: version?: #Semver
+	language?: {
+		version?: #Semver
+
+		// experiments lists the names of the experimental evaluator
+		// behaviors to enable when evaluating the module, such as
+		// "evalv3". They only apply when the module is the main module.
+		experiments?: [...string]
+	}
 
 	// description describes the purpose of this module.
 	description?: string
 
+	// changelog holds the URL of a document describing the changes
+	// made in each version of the module, such as a CHANGELOG file or
+	// a releases page. The placeholder {version} is replaced by the
+	// version of the module, so that the URL can refer to the notes of
+	// a given release.
+	changelog?: string
+
 	// When present, deprecated indicates that the module
 	// is deprecated and includes information about that deprecation, ideally
 	// mentioning an alternative that can be used instead.
@@ -86,15 +98,42 @@ let unimplemented = 1&2
 
 		// replace specifies replacements for specific versions of
 		// the module. This field is exclusive with replaceAll.
-		// TODO implement this.
+		// Replacements only apply when the module is the main module.
 		replace?: [#Semver]: #Replacement
 
 		// replaceAll specifies a replacement for all versions of the module.
 		// This field is exclusive with replace.
-		// TODO implement this.
+		// Replacements only apply when the module is the main module.
 		replaceAll?: #Replacement
 	}
 
+	// files specifies which files are included in the module
+	// when it is published. Patterns use the syntax of path.Match
+	// and are matched against slash-separated paths relative to the
+	// module root. A pattern matching a directory matches all the files
+	// within it, and a pattern without a slash is also matched against
+	// the base name of each file. For example:
+	//
+	//	files: {
+	//		include: ["*.cue", "data", "LICENSE"]
+	//		exclude: ["*_test.cue"]
+	//	}
+	//
+	// The cue.mod/module.cue file is always included.
+	files?: {
+		// include holds the patterns of the files to include.
+		// When present, only files matching at least one
+		// of the patterns are included.
+		include?: [... #FilePattern]
+
+		// exclude holds the patterns of the files to exclude,
+		// even when they match an include pattern.
+		exclude?: [... #FilePattern]
+	}
+
+	// #FilePattern constrains a file pattern.
+	#FilePattern: string & !=""
+
 	// retract specifies a set of previously published versions to retract.
 	// TODO implement this.
 	retract?: [... #RetractedVersion]
@@ -114,7 +153,8 @@ let unimplemented = 1&2
 
 	// #Replacement specifies a replacement for a module. It can either
 	// be a reference to a local directory or an alternative module with associated
-	// version.
+	// version. A local directory starts with ./, ../ or / and is relative to
+	// the root of the main module.
 	#Replacement: string | {
 		m!: #Module
 		v!: #Semver
@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"log"
+	"log/slog"
 	"path"
 	"runtime"
 
+	"cuelang.org/go/cue/logging"
 	"cuelang.org/go/internal/maps"
 	"cuelang.org/go/internal/mod/internal/par"
 	"cuelang.org/go/internal/mod/modfile"
@@ -20,8 +21,6 @@ import (
 	"cuelang.org/go/internal/slices"
 )
 
-const logging = false // TODO hook this up to CUE_DEBUG
-
 // Registry is modload's view of a module registry.
 type Registry interface {
 	modrequirements.Registry
@@ -39,6 +38,9 @@ type loader struct {
 
 // Load evaluates all the requirements of the given main module, using the given
 // registry to download requirements and returns a resolved and tidied module file.
+// The replacements declared by the module file are kept in the result, but
+// are not applied by Load: to apply them, reg should be wrapped with
+// [cuelang.org/go/internal/mod/modreplace.NewRegistry].
 func Load(ctx context.Context, fsys fs.FS, modRoot string, reg Registry) (*modfile.File, error) {
 	modFilePath := path.Join(modRoot, "cue.mod/module.cue")
 	data, err := fs.ReadFile(fsys, modFilePath)
@@ -69,6 +71,9 @@ func Load(ctx context.Context, fsys fs.FS, modRoot string, reg Registry) (*modfi
 	}
 
 	rs, pkgs, err := ld.resolveDependencies(ctx, rootPkgPaths, rs)
+	if err != nil {
+		return nil, err
+	}
 	for _, pkg := range pkgs.All() {
 		if pkg.Error() != nil {
 			return nil, fmt.Errorf("failed to resolve %q: %v", pkg.ImportPath(), pkg.Error())
@@ -84,16 +89,22 @@ func Load(ctx context.Context, fsys fs.FS, modRoot string, reg Registry) (*modfi
 
 func modfileFromRequirements(old *modfile.File, rs *modrequirements.Requirements) *modfile.File {
 	mf := &modfile.File{
-		Module:   old.Module,
-		Language: old.Language,
-		Deps:     make(map[string]*modfile.Dep),
+		Module:    old.Module,
+		Language:  old.Language,
+		Files:     old.Files,
+		Changelog: old.Changelog,
+		Deps:      make(map[string]*modfile.Dep),
 	}
 	defaults := rs.DefaultMajorVersions()
 	for _, v := range rs.RootModules() {
-		mf.Deps[v.Path()] = &modfile.Dep{
+		dep := &modfile.Dep{
 			Version: v.Version(),
 			Default: defaults[v.BasePath()] == semver.Major(v.Version()),
 		}
+		if oldDep := old.Deps[v.Path()]; oldDep != nil {
+			dep.Replace, dep.ReplaceAll = oldDep.Replace, oldDep.ReplaceAll
+		}
+		mf.Deps[v.Path()] = dep
 	}
 	return mf
 }
@@ -451,7 +462,6 @@ func (ld *loader) resolveMissingImports(ctx context.Context, pkgs *modpkgload.Pa
 	for _, pm := range pkgMods {
 		pkg, mods, needsDefault := pm.pkg, *pm.mods, *pm.needsDefault
 		for _, mod := range mods {
-			// TODO support logging progress messages like this but without printing to stderr?
 			logf("cue: found potential %s in %v", pkg.ImportPath(), mod)
 			if modAddedBy[mod] == nil {
 				modAddedBy[mod] = pkg
@@ -598,8 +608,10 @@ func (ld *loader) spotCheckRoots(ctx context.Context, rs *modrequirements.Requir
 	return true
 }
 
+var logger = logging.For(logging.Loader)
+
 func logf(f string, a ...any) {
-	if logging {
-		log.Printf(f, a...)
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		logger.Debug(fmt.Sprintf(f, a...))
 	}
 }
@@ -22,8 +22,8 @@ import (
 	"cuelang.org/go/internal/mod/modregistry"
 	"cuelang.org/go/internal/mod/modrequirements"
 	"cuelang.org/go/internal/mod/module"
-	"cuelang.org/go/internal/registrytest"
 	"cuelang.org/go/internal/txtarfs"
+	"cuelang.org/go/mod/modregistrytest"
 )
 
 func TestLoad(t *testing.T) {
@@ -59,7 +59,7 @@ func TestLoad(t *testing.T) {
 func newRegistry(t *testing.T, fsys fs.FS, root string) Registry {
 	fsys, err := fs.Sub(fsys, "_registry")
 	qt.Assert(t, qt.IsNil(err))
-	regSrv, err := registrytest.New(fsys, "")
+	regSrv, err := modregistrytest.New(fsys, "")
 	qt.Assert(t, qt.IsNil(err))
 	t.Cleanup(regSrv.Close)
 	regOCI, err := ociclient.New(regSrv.Host(), &ociclient.Options{
@@ -22,8 +22,8 @@ import (
 	"cuelang.org/go/internal/mod/modresolve"
 	"cuelang.org/go/internal/mod/module"
 	"cuelang.org/go/internal/mod/modzip"
-	"cuelang.org/go/internal/registrytest"
 	"cuelang.org/go/internal/txtarfs"
+	"cuelang.org/go/mod/modregistrytest"
 )
 
 const contents = `
@@ -59,11 +59,11 @@ package x
 func TestMux(t *testing.T) {
 	rfs := txtarfs.FS(txtar.Parse([]byte(contents)))
 	const numRegistries = 2
-	registries := make([]*registrytest.Registry, numRegistries)
+	registries := make([]*modregistrytest.Registry, numRegistries)
 	for i := 0; i < numRegistries; i++ {
 		rfs1, _ := fs.Sub(rfs, fmt.Sprintf("r%d", i))
 		// TODO non-empty prefixes.
-		r, err := registrytest.New(rfs1, "")
+		r, err := modregistrytest.New(rfs1, "")
 		qt.Assert(t, qt.IsNil(err), qt.Commentf("r%d", i))
 		registries[i] = r
 		defer r.Close()
@@ -0,0 +1,161 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modproxy implements a client for the module proxy protocol,
+// a simple HTTP protocol for downloading CUE modules modeled after the
+// Go module proxy protocol. A module proxy can be served by any static
+// file server, such as one serving the download directory of a module
+// cache.
+//
+// For a module path such as example.com/foo@v1 and version v1.2.3, a
+// proxy serves the following files, relative to its URL:
+//
+//	example.com/foo/@v/list          the known versions of the module, one per line
+//	example.com/foo/@v/v1.2.3.mod    the cue.mod/module.cue file of the module
+//	example.com/foo/@v/v1.2.3.zip    the zip archive holding the module files
+//
+// The module path does not include the major version, so the list holds
+// the versions of all its major versions. Module paths and versions are
+// escaped as with [module.EscapePath] and [module.EscapeVersion].
+package modproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"cuelabs.dev/go/oci/ociregistry"
+
+	"cuelang.org/go/internal/mod/modregistry"
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+// Client downloads modules from a module proxy. It implements
+// [cuelang.org/go/internal/mod/modcache.Source].
+type Client struct {
+	// URL holds the URL of the proxy.
+	URL string
+
+	// Client is used to make requests to the proxy. If it is nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+}
+
+// ModuleVersions returns all the versions for the module with the given
+// path sorted in semver order. If m has a major version suffix, only
+// versions with that major version are returned.
+func (c *Client) ModuleVersions(ctx context.Context, m string) ([]string, error) {
+	mpath, major, hasMajor := module.SplitPathVersion(m)
+	if !hasMajor {
+		mpath = m
+	}
+	r, err := c.get(ctx, mpath, "list")
+	if errors.Is(err, modregistry.ErrNotFound) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for _, v := range strings.Fields(string(data)) {
+		if !semver.IsValid(v) {
+			continue
+		}
+		if !hasMajor || semver.Major(v) == major {
+			versions = append(versions, v)
+		}
+	}
+	semver.Sort(versions)
+	return versions, nil
+}
+
+// ModuleFile returns the contents of the cue.mod/module.cue file of the
+// given module version.
+func (c *Client) ModuleFile(ctx context.Context, mv module.Version) ([]byte, error) {
+	r, err := c.getVersion(ctx, mv, ".mod")
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// GetZip returns a reader for the zip archive holding the files of the
+// given module version. As a proxy does not record the digests of the
+// archives it serves, the returned digest is always empty.
+func (c *Client) GetZip(ctx context.Context, mv module.Version) (io.ReadCloser, ociregistry.Digest, error) {
+	r, err := c.getVersion(ctx, mv, ".zip")
+	if err != nil {
+		return nil, "", err
+	}
+	return r, "", nil
+}
+
+func (c *Client) getVersion(ctx context.Context, mv module.Version, suffix string) (io.ReadCloser, error) {
+	if !mv.IsCanonical() {
+		return nil, fmt.Errorf("non-semver module version %q", mv.Version())
+	}
+	encVer, err := module.EscapeVersion(mv.Version())
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.get(ctx, mv.BasePath(), encVer+suffix)
+	if err != nil {
+		return nil, fmt.Errorf("module %v: %w", mv, err)
+	}
+	return r, nil
+}
+
+// get returns the contents of the given file in the @v directory of the
+// module with the given path, without a major version. If the proxy has
+// no such file, it returns an error satisfying
+// errors.Is(err, modregistry.ErrNotFound).
+func (c *Client) get(ctx context.Context, mpath, file string) (io.ReadCloser, error) {
+	esc, err := module.EscapePath(mpath)
+	if err != nil {
+		return nil, err
+	}
+	u := strings.TrimSuffix(c.URL, "/") + "/" + esc + "/@v/" + file
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound, http.StatusGone:
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s: %w", u, resp.Status, modregistry.ErrNotFound)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modproxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"cuelang.org/go/internal/mod/modregistry"
+	"cuelang.org/go/internal/mod/module"
+)
+
+func TestClient(t *testing.T) {
+	fsys := fstest.MapFS{
+		"example.com/foo/@v/list":       {Data: []byte("v1.0.0\nv0.2.0\nv0.10.0\nbad\n")},
+		"example.com/foo/@v/v0.2.0.mod": {Data: []byte(`module: "example.com/foo@v0"`)},
+		"example.com/foo/@v/v0.2.0.zip": {Data: []byte("zip contents")},
+	}
+	srv := httptest.NewServer(http.StripPrefix("/proxy/", http.FileServer(http.FS(fsys))))
+	defer srv.Close()
+	c := &Client{URL: srv.URL + "/proxy/"}
+	ctx := context.Background()
+
+	for m, want := range map[string]string{
+		"example.com/foo":    "v0.2.0 v0.10.0 v1.0.0",
+		"example.com/foo@v0": "v0.2.0 v0.10.0",
+		"example.com/foo@v2": "",
+		"other.com@v0":       "",
+	} {
+		versions, err := c.ModuleVersions(ctx, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(versions, " "); got != want {
+			t.Errorf("ModuleVersions(%q) = %q; want %q", m, got, want)
+		}
+	}
+
+	mv := module.MustNewVersion("example.com/foo@v0", "v0.2.0")
+	data, err := c.ModuleFile(ctx, mv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `module: "example.com/foo@v0"`; got != want {
+		t.Errorf("ModuleFile = %q; want %q", got, want)
+	}
+	r, zipDigest, err := c.GetZip(ctx, mv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "zip contents"; got != want || zipDigest != "" {
+		t.Errorf("GetZip = %q, %q; want %q, \"\"", got, zipDigest, want)
+	}
+
+	_, err = c.ModuleFile(ctx, module.MustNewVersion("example.com/foo@v0", "v0.3.0"))
+	if !errors.Is(err, modregistry.ErrNotFound) {
+		t.Errorf("ModuleFile of missing version: got error %v; want ErrNotFound", err)
+	}
+}
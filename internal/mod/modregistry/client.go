@@ -22,7 +22,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"time"
 
 	"cuelabs.dev/go/oci/ociregistry"
 	"cuelang.org/go/internal/mod/semver"
@@ -185,6 +187,13 @@ func (m *CheckedModule) Zip() *zip.Reader {
 // PutCheckedModule is like [Client.PutModule] except that it allows the
 // caller to do some additional checks (see [CheckModule] for more info).
 func (c *Client) PutCheckedModule(ctx context.Context, m *CheckedModule) error {
+	return c.PutCheckedModuleWithMetadata(ctx, m, nil)
+}
+
+// PutCheckedModuleWithMetadata is like [Client.PutCheckedModule] except
+// that it also records the given metadata in the module's manifest.
+// If meta is nil, no metadata is recorded.
+func (c *Client) PutCheckedModuleWithMetadata(ctx context.Context, m *CheckedModule, meta *Metadata) error {
 	repoName := c.repoName(m.mv.Path())
 	selfDigest, err := digest.FromReader(io.NewSectionReader(m.blobr, 0, m.size))
 	if err != nil {
@@ -212,6 +221,7 @@ func (c *Client) PutCheckedModule(ctx context.Context, m *CheckedModule) error {
 			MediaType: moduleFileMediaType,
 			Size:      int64(len(m.modFileContent)),
 		}},
+		Annotations: meta.annotations(),
 	}
 
 	if _, err := c.registry.PushBlob(ctx, repoName, manifest.Layers[0], io.NewSectionReader(m.blobr, 0, m.size)); err != nil {
@@ -237,11 +247,18 @@ func (c *Client) PutCheckedModule(ctx context.Context, m *CheckedModule) error {
 // TODO check deps are resolved correctly? Or is that too domain-specific for this package?
 // Is it a problem to call zip.CheckZip twice?
 func (c *Client) PutModule(ctx context.Context, m module.Version, r io.ReaderAt, size int64) error {
+	return c.PutModuleWithMetadata(ctx, m, r, size, nil)
+}
+
+// PutModuleWithMetadata is like [Client.PutModule] except that it also
+// records the given metadata in the module's manifest.
+// If meta is nil, no metadata is recorded.
+func (c *Client) PutModuleWithMetadata(ctx context.Context, m module.Version, r io.ReaderAt, size int64, meta *Metadata) error {
 	cm, err := CheckModule(m, r, size)
 	if err != nil {
 		return err
 	}
-	return c.PutCheckedModule(ctx, cm)
+	return c.PutCheckedModuleWithMetadata(ctx, cm, meta)
 }
 
 // CheckModule checks a module's zip file before uploading it.
@@ -341,12 +358,92 @@ func (m *Module) GetZip(ctx context.Context) (io.ReadCloser, error) {
 	return m.client.registry.GetBlob(ctx, m.repo, m.manifest.Layers[0].Digest)
 }
 
+// ZipDigest returns the digest of the zip archive containing
+// the module files, as recorded in the module's manifest.
+func (m *Module) ZipDigest() ociregistry.Digest {
+	return m.manifest.Layers[0].Digest
+}
+
 // ManifestDigest returns the digest of the manifest representing
 // the module.
 func (m *Module) ManifestDigest() ociregistry.Digest {
 	return m.manifestDigest
 }
 
+// Metadata returns the metadata recorded when the module was published.
+// It returns nil if the module has no metadata.
+func (m *Module) Metadata() (*Metadata, error) {
+	return parseMetadata(m.manifest.Annotations)
+}
+
+// Metadata holds information about the source of a module that is
+// recorded in the module's manifest when it is published.
+type Metadata struct {
+	// VCSType holds the kind of version control system that the module
+	// was published from, such as "git".
+	VCSType string
+
+	// VCSCommit holds the revision of the commit that the module was
+	// published from.
+	VCSCommit string
+
+	// VCSCommitTime holds the time of that commit.
+	VCSCommitTime time.Time
+
+	// VCSDirty reports whether the module directory had changes that
+	// were not committed when the module was published.
+	VCSDirty bool
+}
+
+// Annotation keys used to record [Metadata] in a module's manifest.
+const (
+	vcsTypeAnnotation       = "org.cuelang.vcs-type"
+	vcsCommitAnnotation     = "org.cuelang.vcs-commit"
+	vcsCommitTimeAnnotation = "org.cuelang.vcs-commit-time"
+	vcsDirtyAnnotation      = "org.cuelang.vcs-dirty"
+)
+
+func (meta *Metadata) annotations() map[string]string {
+	if meta == nil || meta.VCSType == "" {
+		return nil
+	}
+	a := map[string]string{
+		vcsTypeAnnotation:   meta.VCSType,
+		vcsCommitAnnotation: meta.VCSCommit,
+		vcsDirtyAnnotation:  strconv.FormatBool(meta.VCSDirty),
+	}
+	if !meta.VCSCommitTime.IsZero() {
+		a[vcsCommitTimeAnnotation] = meta.VCSCommitTime.UTC().Format(time.RFC3339)
+	}
+	return a
+}
+
+func parseMetadata(a map[string]string) (*Metadata, error) {
+	vcsType := a[vcsTypeAnnotation]
+	if vcsType == "" {
+		return nil, nil
+	}
+	meta := &Metadata{
+		VCSType:   vcsType,
+		VCSCommit: a[vcsCommitAnnotation],
+	}
+	if s, ok := a[vcsCommitTimeAnnotation]; ok {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %v", vcsCommitTimeAnnotation, err)
+		}
+		meta.VCSCommitTime = t
+	}
+	if s, ok := a[vcsDirtyAnnotation]; ok {
+		dirty, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %v", vcsDirtyAnnotation, err)
+		}
+		meta.VCSDirty = dirty
+	}
+	return meta, nil
+}
+
 func unmarshalManifest(ctx context.Context, data []byte, mediaType string) (*ociregistry.Manifest, error) {
 	if !isJSON(mediaType) {
 		return nil, fmt.Errorf("expected JSON media type but %q does not look like JSON", mediaType)
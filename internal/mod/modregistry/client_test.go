@@ -66,6 +66,43 @@ x: 42
 	qt.Assert(t, qt.DeepEquals(tags, []string{"v1.2.3"}))
 }
 
+func TestPutGetModuleWithMetadata(t *testing.T) {
+	const testMod = `
+-- cue.mod/module.cue --
+module: "example.com/module@v1"
+
+-- x.cue --
+x: 42
+`
+	ctx := context.Background()
+	mv := module.MustParseVersion("example.com/module@v1.2.3")
+	c := newTestClient(t)
+	zipData := createZip(t, mv, testMod)
+	meta := &Metadata{
+		VCSType:       "git",
+		VCSCommit:     "2ff5afa7cda41bf030654ab03caeba3fadf241ae",
+		VCSCommitTime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		VCSDirty:      true,
+	}
+	err := c.PutModuleWithMetadata(ctx, mv, bytes.NewReader(zipData), int64(len(zipData)), meta)
+	qt.Assert(t, qt.IsNil(err))
+
+	m, err := c.GetModule(ctx, mv)
+	qt.Assert(t, qt.IsNil(err))
+	gotMeta, err := m.Metadata()
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(gotMeta, meta))
+
+	// A module published without metadata has none.
+	mv = module.MustParseVersion("example.com/module@v1.2.4")
+	putModule(t, c, mv, testMod)
+	m, err = c.GetModule(ctx, mv)
+	qt.Assert(t, qt.IsNil(err))
+	gotMeta, err = m.Metadata()
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsNil(gotMeta))
+}
+
 func TestModuleVersions(t *testing.T) {
 	ctx := context.Background()
 	c := newTestClient(t)
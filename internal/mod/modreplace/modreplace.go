@@ -0,0 +1,180 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modreplace applies the replace and replaceAll directives of the
+// module file of a main module to a registry.
+//
+// A replaced module keeps its path and version in the dependency graph,
+// but its contents and requirements are taken from its replacement:
+// either a local directory, which need not be published anywhere, or a
+// version of another module, such as a fork.
+package modreplace
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/modload"
+	"cuelang.org/go/internal/mod/modpkgload"
+	"cuelang.org/go/internal/mod/modrequirements"
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+// NewRegistry returns a registry that serves the modules replaced in mf,
+// the module file of the main module with root directory modRoot, from
+// their replacements, and all other modules from reg. It returns reg
+// itself if mf has no replacements.
+func NewRegistry(reg modload.Registry, mf *modfile.File, modRoot string) modload.Registry {
+	if !mf.HasReplacements() {
+		return reg
+	}
+	return &registry{
+		reg:     reg,
+		mf:      mf,
+		modRoot: modRoot,
+	}
+}
+
+type registry struct {
+	reg     modload.Registry
+	mf      *modfile.File
+	modRoot string
+}
+
+// CUEModSummary implements [modrequirements.Registry.CUEModSummary].
+func (r *registry) CUEModSummary(ctx context.Context, m module.Version) (*modrequirements.ModFileSummary, error) {
+	repl := r.mf.Replacement(m)
+	if repl == nil {
+		return r.reg.CUEModSummary(ctx, m)
+	}
+	if repl.Dir == "" {
+		rv, err := module.NewVersion(repl.Module, repl.Version)
+		if err != nil {
+			return nil, err
+		}
+		summary, err := r.reg.CUEModSummary(ctx, rv)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get requirements of %v, replacement of %v: %v", rv, m, err)
+		}
+		return &modrequirements.ModFileSummary{
+			Module:  m,
+			Require: summary.Require,
+		}, nil
+	}
+	modPath := filepath.Join(r.dir(repl), "cue.mod", "module.cue")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return nil, fmt.Errorf("replacement directory %s of %v is not a module: %v", repl.Dir, m, err)
+	}
+	mf, err := modfile.ParseNonStrict(data, modPath)
+	if err != nil {
+		return nil, err
+	}
+	return &modrequirements.ModFileSummary{
+		Module:  m,
+		Require: mf.DepVersions(),
+	}, nil
+}
+
+// Fetch implements [modpkgload.Registry.Fetch].
+func (r *registry) Fetch(ctx context.Context, m module.Version) (modpkgload.SourceLoc, error) {
+	repl := r.mf.Replacement(m)
+	if repl == nil {
+		return r.reg.Fetch(ctx, m)
+	}
+	if repl.Dir == "" {
+		rv, err := module.NewVersion(repl.Module, repl.Version)
+		if err != nil {
+			return modpkgload.SourceLoc{}, err
+		}
+		return r.reg.Fetch(ctx, rv)
+	}
+	dir := r.dir(repl)
+	if _, err := os.Stat(filepath.Join(dir, "cue.mod")); err != nil {
+		return modpkgload.SourceLoc{}, fmt.Errorf("replacement directory %s of %v is not a module: %v", repl.Dir, m, err)
+	}
+	return modpkgload.SourceLoc{
+		FS:  dirFS{os.DirFS(dir), dir},
+		Dir: ".",
+	}, nil
+}
+
+// ModuleVersions implements [modload.Registry.ModuleVersions]. The
+// versions replaced by the main module are included even if they are not
+// published, so that they can be selected.
+func (r *registry) ModuleVersions(ctx context.Context, mpath string) ([]string, error) {
+	versions, err := r.reg.ModuleVersions(ctx, mpath)
+	dep := r.mf.Deps[mpath]
+	if dep == nil {
+		return versions, err
+	}
+	var replaced []string
+	for v := range dep.Replace {
+		replaced = append(replaced, v)
+	}
+	if dep.ReplaceAll != nil && dep.Version != "" {
+		replaced = append(replaced, dep.Version)
+	}
+	if err != nil {
+		if len(replaced) == 0 {
+			return nil, err
+		}
+		versions = nil
+	}
+	for _, v := range replaced {
+		if !contains(versions, v) {
+			versions = append(versions, v)
+		}
+	}
+	semver.Sort(versions)
+	return versions, nil
+}
+
+// dir returns the absolute directory of the local replacement repl.
+func (r *registry) dir(repl *modfile.Replacement) string {
+	dir := filepath.FromSlash(repl.Dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.modRoot, dir)
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return dir
+}
+
+func contains(versions []string, v string) bool {
+	for _, w := range versions {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
+
+// dirFS is the file system of a local replacement. It implements the
+// OSRootFS interface of the modcache package, so that the loader can find
+// the directories of its packages.
+type dirFS struct {
+	fs.FS
+	root string
+}
+
+func (fsys dirFS) OSRoot() string {
+	return fsys.root
+}
@@ -13,8 +13,8 @@ import (
 	"cuelang.org/go/internal/mod/modregistry"
 	"cuelang.org/go/internal/mod/module"
 	"cuelang.org/go/internal/mod/mvs"
-	"cuelang.org/go/internal/registrytest"
 	"cuelang.org/go/internal/txtarfs"
+	"cuelang.org/go/mod/modregistrytest"
 )
 
 func TestRequirements(t *testing.T) {
@@ -208,7 +208,7 @@ func mustParseVersion(s string) module.Version {
 }
 
 func newRegistry(t *testing.T, registryContents string) Registry {
-	regSrv, err := registrytest.New(txtarfs.FS(txtar.Parse([]byte(registryContents))), "")
+	regSrv, err := modregistrytest.New(txtarfs.FS(txtar.Parse([]byte(registryContents))), "")
 	qt.Assert(t, qt.IsNil(err))
 	t.Cleanup(regSrv.Close)
 	regOCI, err := ociclient.New(regSrv.Host(), &ociclient.Options{
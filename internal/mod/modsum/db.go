@@ -0,0 +1,163 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modsum
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rogpeppe/go-internal/lockedfile"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+
+	"cuelang.org/go/cue/logging"
+)
+
+var logger = logging.For(logging.Registry)
+
+// OpenDB returns a client for the checksum database described by
+// config, which holds the verifier key of the database, optionally
+// followed by a space and the URL of the database. The URL defaults to
+// https:// followed by the name of the key.
+//
+// The client caches the records and tiles it downloads, as well as the
+// latest signed tree head it has seen, which protects against the
+// database rolling back its log, in the directory dir.
+func OpenDB(config, dir string, client *http.Client) (*sumdb.Client, error) {
+	vkey, rawURL, _ := strings.Cut(strings.TrimSpace(config), " ")
+	verifier, err := note.NewVerifier(vkey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum database key %q: %v", vkey, err)
+	}
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		rawURL = "https://" + verifier.Name()
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid checksum database URL %q", rawURL)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return sumdb.NewClient(&dbOps{
+		key:    []byte(vkey),
+		url:    strings.TrimSuffix(rawURL, "/"),
+		dir:    dir,
+		client: client,
+	}), nil
+}
+
+// dbOps implements [sumdb.ClientOps].
+type dbOps struct {
+	key    []byte
+	url    string
+	dir    string
+	client *http.Client
+}
+
+func (ops *dbOps) ReadRemote(path string) ([]byte, error) {
+	u := ops.url + path
+	resp, err := ops.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (ops *dbOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return ops.key, nil
+	}
+	data, err := lockedfile.Read(ops.configPath(file))
+	if errors.Is(err, fs.ErrNotExist) {
+		// Start with an empty tree.
+		return []byte{}, nil
+	}
+	return data, err
+}
+
+func (ops *dbOps) WriteConfig(file string, old, new []byte) error {
+	path := ops.configPath(file)
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return err
+	}
+	f, err := lockedfile.Edit(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, old) {
+		return sumdb.ErrWriteConflict
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Write(new); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (ops *dbOps) configPath(file string) string {
+	return filepath.Join(ops.dir, "config", filepath.FromSlash(file))
+}
+
+func (ops *dbOps) ReadCache(file string) ([]byte, error) {
+	return os.ReadFile(ops.cachePath(file))
+}
+
+func (ops *dbOps) WriteCache(file string, data []byte) {
+	path := ops.cachePath(file)
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return
+	}
+	// Caching is best effort, and cached files are verified
+	// when they are read.
+	lockedfile.Write(path, bytes.NewReader(data), 0o666)
+}
+
+func (ops *dbOps) cachePath(file string) string {
+	return filepath.Join(ops.dir, "cache", filepath.FromSlash(file))
+}
+
+func (ops *dbOps) Log(msg string) {
+	logger.Debug(msg)
+}
+
+func (ops *dbOps) SecurityError(msg string) {
+	// The client returns sumdb.ErrSecurity from the operation that
+	// found the problem; make sure the details are not lost.
+	fmt.Fprintln(os.Stderr, msg)
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modsum verifies the contents of downloaded module versions
+// against known checksums: those recorded in the cue.mod/sums file of the
+// main module, and those published in a checksum database, a transparency
+// log of checksums served with the protocol of the Go checksum database.
+//
+// Checksums are held as lines of text, both in cue.mod/sums files and in
+// the records of a checksum database. Each line holds the module path
+// without its major version, the version, the kind of the checksum and
+// the checksum itself, which is the digest of the module's zip file for
+// the "zip" kind and the digest of its cue.mod/module.cue file for the
+// "mod" kind:
+//
+//	example.com/foo v1.2.3 mod sha256:a6c0...
+//	example.com/foo v1.2.3 zip sha256:4e1b...
+package modsum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"cuelabs.dev/go/oci/ociregistry"
+	digest "github.com/opencontainers/go-digest"
+	"golang.org/x/mod/sumdb"
+
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+// The kinds of checksums.
+const (
+	KindMod = "mod"
+	KindZip = "zip"
+)
+
+type key struct {
+	path    string // without major version
+	version string
+	kind    string
+}
+
+// File holds the checksums in a cue.mod/sums file.
+type File struct {
+	sums map[key]string
+}
+
+// NewFile returns an empty File.
+func NewFile() *File {
+	return &File{sums: map[key]string{}}
+}
+
+// ParseFile parses the contents of a cue.mod/sums file. The filename
+// is used in error messages.
+func ParseFile(data []byte, filename string) (*File, error) {
+	f := NewFile()
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		k, sum, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", filename, i+1, err)
+		}
+		if old, ok := f.sums[k]; ok && old != sum {
+			return nil, fmt.Errorf("%s:%d: conflicting %s checksums for %s %s", filename, i+1, k.kind, k.path, k.version)
+		}
+		f.sums[k] = sum
+	}
+	return f, nil
+}
+
+func parseLine(line string) (key, string, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 4 {
+		return key{}, "", fmt.Errorf("malformed checksum line %q", line)
+	}
+	k := key{path: fields[0], version: fields[1], kind: fields[2]}
+	if !semver.IsValid(k.version) || semver.Canonical(k.version) != k.version {
+		return key{}, "", fmt.Errorf("invalid version %q", k.version)
+	}
+	if k.kind != KindMod && k.kind != KindZip {
+		return key{}, "", fmt.Errorf("unknown checksum kind %q", k.kind)
+	}
+	if _, err := digest.Parse(fields[3]); err != nil {
+		return key{}, "", fmt.Errorf("invalid checksum %q: %v", fields[3], err)
+	}
+	return k, fields[3], nil
+}
+
+// Lookup returns the checksum of the given kind recorded for mv.
+func (f *File) Lookup(mv module.Version, kind string) (string, bool) {
+	sum, ok := f.sums[key{mv.BasePath(), mv.Version(), kind}]
+	return sum, ok
+}
+
+// Add records the checksum of the given kind for mv.
+func (f *File) Add(mv module.Version, kind, sum string) {
+	f.sums[key{mv.BasePath(), mv.Version(), kind}] = sum
+}
+
+// Filter returns a File holding only the checksums of the
+// given module versions.
+func (f *File) Filter(versions []module.Version) *File {
+	f1 := NewFile()
+	for _, mv := range versions {
+		for _, kind := range []string{KindMod, KindZip} {
+			if sum, ok := f.Lookup(mv, kind); ok {
+				f1.Add(mv, kind, sum)
+			}
+		}
+	}
+	return f1
+}
+
+// Format returns the contents of the file, sorted by module path and
+// version.
+func (f *File) Format() []byte {
+	keys := make([]key, 0, len(f.sums))
+	for k := range f.sums {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ki, kj := keys[i], keys[j]
+		if ki.path != kj.path {
+			return ki.path < kj.path
+		}
+		if c := semver.Compare(ki.version, kj.version); c != 0 {
+			return c < 0
+		}
+		return ki.kind < kj.kind
+	})
+	var buf bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s %s %s %s\n", k.path, k.version, k.kind, f.sums[k])
+	}
+	return buf.Bytes()
+}
+
+// A DB provides the checksums published in a checksum database.
+// It is implemented by [sumdb.Client].
+type DB interface {
+	// Lookup returns the checksum lines of the module with the given
+	// path, without major version, at the given version. It returns
+	// an error satisfying errors.Is(err, sumdb.ErrGONOSUMDB) if the
+	// module is not to be looked up in the database.
+	Lookup(path, vers string) (lines []string, err error)
+}
+
+// A Checker checks the contents of module versions against the
+// checksums in a cue.mod/sums file or, for module versions not
+// listed in that file, against a checksum database, if any. A module
+// version that is found in neither is trusted on its first use.
+//
+// The checksums of all the checked module versions are recorded, so
+// that they can be written to the cue.mod/sums file.
+//
+// The methods of a Checker are safe for concurrent use.
+type Checker struct {
+	sums *File
+	db   DB
+
+	mu      sync.Mutex
+	checked *File
+}
+
+// NewChecker returns a Checker for the checksums in sums, which may be
+// nil, and the checksum database db, which may also be nil.
+func NewChecker(sums *File, db DB) *Checker {
+	if sums == nil {
+		sums = NewFile()
+	}
+	return &Checker{
+		sums:    sums,
+		db:      db,
+		checked: NewFile(),
+	}
+}
+
+// CheckModFile checks the contents of the cue.mod/module.cue file of mv.
+// It implements [cuelang.org/go/internal/mod/modcache.Checker].
+func (c *Checker) CheckModFile(ctx context.Context, mv module.Version, data []byte) error {
+	return c.check(mv, KindMod, digest.Canonical.FromBytes(data).String())
+}
+
+// CheckZip checks the digest of the zip file of mv.
+// It implements [cuelang.org/go/internal/mod/modcache.Checker].
+func (c *Checker) CheckZip(ctx context.Context, mv module.Version, zipDigest ociregistry.Digest) error {
+	return c.check(mv, KindZip, zipDigest.String())
+}
+
+func (c *Checker) check(mv module.Version, kind, sum string) error {
+	if want, ok := c.sums.Lookup(mv, kind); ok {
+		if want != sum {
+			return mismatchError(mv, kind, sum, "cue.mod/sums", want)
+		}
+		return nil
+	}
+	c.mu.Lock()
+	checked, ok := c.checked.Lookup(mv, kind)
+	c.mu.Unlock()
+	if ok {
+		if checked != sum {
+			return mismatchError(mv, kind, sum, "earlier download", checked)
+		}
+		return nil
+	}
+	if c.db != nil {
+		want, err := c.lookup(mv, kind)
+		if err != nil {
+			return err
+		}
+		if want != "" && want != sum {
+			return mismatchError(mv, kind, sum, "checksum database", want)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked.Add(mv, kind, sum)
+	return nil
+}
+
+// lookup returns the checksum of the given kind published for mv in the
+// checksum database, or "" if the module is not to be looked up.
+func (c *Checker) lookup(mv module.Version, kind string) (string, error) {
+	lines, err := c.db.Lookup(mv.BasePath(), mv.Version())
+	if errors.Is(err, sumdb.ErrGONOSUMDB) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("verifying %v: checksum database: %v", mv, err)
+	}
+	for _, line := range lines {
+		k, sum, err := parseLine(line)
+		if err != nil {
+			return "", fmt.Errorf("verifying %v: checksum database: %v", mv, err)
+		}
+		if k.kind == kind {
+			return sum, nil
+		}
+	}
+	return "", fmt.Errorf("verifying %v: checksum database has no %s checksum", mv, kind)
+}
+
+// Sums returns the checksums of cue.mod/sums along with those of all the
+// module versions checked so far.
+func (c *Checker) Sums() *File {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f := NewFile()
+	for k, sum := range c.sums.sums {
+		f.sums[k] = sum
+	}
+	for k, sum := range c.checked.sums {
+		f.sums[k] = sum
+	}
+	return f
+}
+
+func mismatchError(mv module.Version, kind, got, source, want string) error {
+	return fmt.Errorf(`verifying %v: %s checksum mismatch
+	downloaded: %s
+	%s: %s
+
+SECURITY ERROR
+The downloaded module does not match the checksum above, which means
+that the module has been modified since that checksum was recorded.`,
+		mv, kind, got, source, want)
+}
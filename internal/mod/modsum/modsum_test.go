@@ -0,0 +1,148 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modsum
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"cuelabs.dev/go/oci/ociregistry"
+	digest "github.com/opencontainers/go-digest"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+
+	"cuelang.org/go/internal/mod/module"
+)
+
+func TestFile(t *testing.T) {
+	const data = `
+example.com v0.10.0 zip sha256:0000000000000000000000000000000000000000000000000000000000000002
+example.com v0.2.0 zip sha256:0000000000000000000000000000000000000000000000000000000000000001
+example.com v0.2.0 mod sha256:0000000000000000000000000000000000000000000000000000000000000000
+`
+	f, err := ParseFile([]byte(data), "sums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.TrimPrefix(`
+example.com v0.2.0 mod sha256:0000000000000000000000000000000000000000000000000000000000000000
+example.com v0.2.0 zip sha256:0000000000000000000000000000000000000000000000000000000000000001
+example.com v0.10.0 zip sha256:0000000000000000000000000000000000000000000000000000000000000002
+`, "\n")
+	if got := string(f.Format()); got != want {
+		t.Errorf("unexpected Format result; got\n%s\nwant\n%s", got, want)
+	}
+	mv := module.MustNewVersion("example.com@v0", "v0.2.0")
+	if sum, ok := f.Lookup(mv, KindZip); !ok || !strings.HasSuffix(sum, "1") {
+		t.Errorf("Lookup(%v, zip) = %q, %v", mv, sum, ok)
+	}
+	f = f.Filter([]module.Version{mv})
+	if got := strings.Count(string(f.Format()), "\n"); got != 2 {
+		t.Errorf("Filter kept %d lines; want 2", got)
+	}
+
+	for _, bad := range []string{
+		"example.com v0.2.0 zip",
+		"example.com v0.2 zip sha256:0000000000000000000000000000000000000000000000000000000000000000",
+		"example.com v0.2.0 tar sha256:0000000000000000000000000000000000000000000000000000000000000000",
+		"example.com v0.2.0 zip sha256:00",
+	} {
+		if _, err := ParseFile([]byte(bad), "sums"); err == nil {
+			t.Errorf("ParseFile(%q) succeeded unexpectedly", bad)
+		}
+	}
+}
+
+func TestChecker(t *testing.T) {
+	modData := []byte(`module: "example.com@v0"`)
+	modSum := digest.Canonical.FromBytes(modData)
+	zipSum := digest.Canonical.FromString("zip contents")
+	otherSum := digest.Canonical.FromString("other")
+	records := map[string]string{
+		"example.com@v0.1.0": fmt.Sprintf("example.com v0.1.0 mod %s\nexample.com v0.1.0 zip %s\n", modSum, zipSum),
+		"example.com@v0.2.0": fmt.Sprintf("example.com v0.2.0 mod %s\nexample.com v0.2.0 zip %s\n", modSum, otherSum),
+	}
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sum.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(sumdb.NewServer(sumdb.NewTestServer(skey, func(path, vers string) ([]byte, error) {
+		r, ok := records[path+"@"+vers]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(r), nil
+	})))
+	defer srv.Close()
+	db, err := OpenDB(vkey+" "+srv.URL, t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	sums := NewFile()
+	v3 := module.MustNewVersion("example.com@v0", "v0.3.0")
+	sums.Add(v3, KindZip, zipSum.String())
+	c := NewChecker(sums, db)
+
+	v1 := module.MustNewVersion("example.com@v0", "v0.1.0")
+	if err := c.CheckModFile(ctx, v1, modData); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CheckZip(ctx, v1, zipSum); err != nil {
+		t.Fatal(err)
+	}
+
+	v2 := module.MustNewVersion("example.com@v0", "v0.2.0")
+	checkMismatch(t, c.CheckZip(ctx, v2, zipSum), "checksum database: "+otherSum.String())
+
+	// cue.mod/sums takes precedence over the database, which does
+	// not know about v0.3.0.
+	if err := c.CheckZip(ctx, v3, zipSum); err != nil {
+		t.Fatal(err)
+	}
+	checkMismatch(t, c.CheckZip(ctx, v3, otherSum), "cue.mod/sums: "+zipSum.String())
+	if err := c.CheckModFile(ctx, v3, modData); err == nil {
+		t.Errorf("unexpected success for a version missing from the database")
+	}
+
+	got := string(c.Sums().Filter([]module.Version{v1, v3}).Format())
+	want := fmt.Sprintf("example.com v0.1.0 mod %s\nexample.com v0.1.0 zip %s\nexample.com v0.3.0 zip %s\n", modSum, zipSum, zipSum)
+	if got != want {
+		t.Errorf("unexpected sums; got\n%s\nwant\n%s", got, want)
+	}
+
+	// Without a database, unknown versions are trusted on first use.
+	c = NewChecker(nil, nil)
+	if err := c.CheckZip(ctx, v2, zipSum); err != nil {
+		t.Fatal(err)
+	}
+	checkMismatch(t, c.CheckZip(ctx, v2, ociregistry.Digest(otherSum)), "earlier download: "+zipSum.String())
+}
+
+func checkMismatch(t *testing.T, err error, want string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("unexpected success; want checksum mismatch")
+	}
+	if !strings.Contains(err.Error(), "checksum mismatch") || !strings.Contains(err.Error(), want) {
+		t.Errorf("unexpected error %q; want mismatch with %q", err, want)
+	}
+}
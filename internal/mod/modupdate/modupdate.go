@@ -0,0 +1,145 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modupdate finds the versions of the dependencies of a module
+// that are newer than the ones it requires.
+//
+// Module registries are OCI registries, which provide no way to be
+// notified of new versions of a module, so Watch polls the registry
+// instead.
+package modupdate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/modload"
+	"cuelang.org/go/internal/mod/modpkgload"
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+// Registry is modupdate's view of a module registry.
+type Registry interface {
+	// ModuleVersions returns all the versions for the module with the
+	// given path, which should contain a major version.
+	ModuleVersions(ctx context.Context, mpath string) ([]string, error)
+
+	// Fetch returns the location of the contents of the given module
+	// version.
+	Fetch(ctx context.Context, m module.Version) (modpkgload.SourceLoc, error)
+}
+
+// An Update describes a newer version of a dependency.
+type Update struct {
+	// Module holds the version of the dependency that is required.
+	Module module.Version
+
+	// Latest holds the latest version of the dependency with the same
+	// major version.
+	Latest module.Version
+
+	// Changelog holds the URL of the changes made in the latest
+	// version, as recorded in its module file, or the empty string if
+	// there is none.
+	Changelog string
+}
+
+// Check returns the available updates of the dependencies of mf, sorted
+// by module path.
+func Check(ctx context.Context, reg Registry, mf *modfile.File) ([]Update, error) {
+	var updates []Update
+	for _, m := range mf.DepVersions() {
+		versions, err := reg.ModuleVersions(ctx, m.Path())
+		if err != nil {
+			return nil, fmt.Errorf("cannot list versions of %s: %v", m.Path(), err)
+		}
+		latest := modload.LatestVersion(versions)
+		if latest == "" || semver.Compare(latest, m.Version()) <= 0 {
+			continue
+		}
+		lv, err := module.NewVersion(m.Path(), latest)
+		if err != nil {
+			return nil, err
+		}
+		changelog, err := changelogURL(ctx, reg, lv)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, Update{
+			Module:    m,
+			Latest:    lv,
+			Changelog: changelog,
+		})
+	}
+	return updates, nil
+}
+
+// changelogURL returns the URL of the changes made in the module version
+// m, as recorded in its module file.
+func changelogURL(ctx context.Context, reg Registry, m module.Version) (string, error) {
+	loc, err := reg.Fetch(ctx, m)
+	if err != nil {
+		return "", fmt.Errorf("cannot fetch %v: %v", m, err)
+	}
+	file := path.Join(loc.Dir, "cue.mod/module.cue")
+	data, err := fs.ReadFile(loc.FS, file)
+	if err != nil {
+		return "", fmt.Errorf("cannot read module file of %v: %v", m, err)
+	}
+	mf, err := modfile.Parse(data, m.String())
+	if err != nil {
+		return "", fmt.Errorf("cannot parse module file of %v: %v", m, err)
+	}
+	return mf.ChangelogURL(m.Version()), nil
+}
+
+// Watch checks for updates of the dependencies of mf every interval
+// until ctx is done, calling f with the updates that have not been
+// reported before, if any. The first check is made immediately.
+//
+// Watch stops when f or a check returns an error, and returns that error.
+// Otherwise it returns the error of ctx once it is done.
+func Watch(ctx context.Context, reg Registry, mf *modfile.File, interval time.Duration, f func([]Update) error) error {
+	reported := make(map[module.Version]bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		updates, err := Check(ctx, reg, mf)
+		if err != nil {
+			return err
+		}
+		var news []Update
+		for _, u := range updates {
+			if !reported[u.Latest] {
+				reported[u.Latest] = true
+				news = append(news, u)
+			}
+		}
+		if len(news) > 0 {
+			if err := f(news); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
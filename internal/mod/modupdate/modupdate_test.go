@@ -0,0 +1,128 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/modpkgload"
+	"cuelang.org/go/internal/mod/module"
+)
+
+// fakeRegistry holds the contents of the module files of each module
+// version, keyed by module@version.
+type fakeRegistry struct {
+	modFiles map[string]string
+}
+
+func (r *fakeRegistry) ModuleVersions(ctx context.Context, mpath string) ([]string, error) {
+	var versions []string
+	for mv := range r.modFiles {
+		p, v, _ := strings.Cut(mv, " ")
+		if p == mpath {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (r *fakeRegistry) Fetch(ctx context.Context, m module.Version) (modpkgload.SourceLoc, error) {
+	data, ok := r.modFiles[m.Path()+" "+m.Version()]
+	if !ok {
+		return modpkgload.SourceLoc{}, fmt.Errorf("%v not found", m)
+	}
+	return modpkgload.SourceLoc{
+		FS:  fstest.MapFS{"m/cue.mod/module.cue": {Data: []byte(data)}},
+		Dir: "m",
+	}, nil
+}
+
+const mainModFile = `
+module: "main.org@v0"
+deps: {
+	"a.com@v0": v: "v0.1.0"
+	"b.com@v1": v: "v1.2.0"
+	"c.com@v0": v: "v0.1.0"
+}
+`
+
+func TestCheck(t *testing.T) {
+	reg := &fakeRegistry{modFiles: map[string]string{
+		"a.com@v0 v0.1.0":       `module: "a.com@v0"`,
+		"a.com@v0 v0.3.0":       `module: "a.com@v0", changelog: "https://a.com/releases/{version}"`,
+		"a.com@v0 v0.4.0-alpha": `module: "a.com@v0"`,
+		"b.com@v1 v1.2.0":       `module: "b.com@v1"`,
+		"b.com@v2 v2.0.0":       `module: "b.com@v2"`,
+		"c.com@v0 v0.1.0":       `module: "c.com@v0"`,
+		"c.com@v0 v0.2.0":       `module: "c.com@v0"`,
+	}}
+	mf, err := modfile.Parse([]byte(mainModFile), "module.cue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, err := Check(context.Background(), reg, mf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, u := range updates {
+		got = append(got, fmt.Sprintf("%v => %s %q", u.Module, u.Latest.Version(), u.Changelog))
+	}
+	want := []string{
+		`a.com@v0.1.0 => v0.3.0 "https://a.com/releases/v0.3.0"`,
+		`c.com@v0.1.0 => v0.2.0 ""`,
+	}
+	if g, w := strings.Join(got, "\n"), strings.Join(want, "\n"); g != w {
+		t.Errorf("got:\n%s\nwant:\n%s", g, w)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	reg := &fakeRegistry{modFiles: map[string]string{
+		"a.com@v0 v0.1.0": `module: "a.com@v0"`,
+		"a.com@v0 v0.2.0": `module: "a.com@v0"`,
+	}}
+	mf, err := modfile.Parse([]byte(`module: "main.org@v0", deps: "a.com@v0": v: "v0.1.0"`), "module.cue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	errDone := errors.New("done")
+	err = Watch(context.Background(), reg, mf, time.Millisecond, func(updates []Update) error {
+		for _, u := range updates {
+			got = append(got, u.Latest.Version())
+		}
+		if len(got) == 2 {
+			return errDone
+		}
+		// A new version is published after the first report; the
+		// version already reported is not reported again.
+		reg.modFiles["a.com@v0 v0.3.0"] = `module: "a.com@v0"`
+		return nil
+	})
+	if err != errDone {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if g := strings.Join(got, " "); g != "v0.2.0 v0.3.0" {
+		t.Errorf("got reported versions %s", g)
+	}
+}
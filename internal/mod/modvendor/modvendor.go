@@ -0,0 +1,279 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modvendor copies the dependencies of a main module into its
+// cue.mod/vendor directory, and serves them from there, so that the
+// module can be loaded without access to a registry.
+//
+// Each dependency is copied into a directory named after its escaped
+// path and version, as in the module cache:
+//
+//	cue.mod/vendor/
+//		modules.txt
+//		example.com@v0.1.0/
+//			cue.mod/module.cue
+//			x.cue
+//
+// The modules.txt file lists the vendored module versions, one per line.
+package modvendor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/internal/mod/modfile"
+	"cuelang.org/go/internal/mod/modload"
+	"cuelang.org/go/internal/mod/modpkgload"
+	"cuelang.org/go/internal/mod/modrequirements"
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+// manifestHeader starts the modules.txt file.
+const manifestHeader = "# generated by cue mod vendor; DO NOT EDIT\n"
+
+// Dir returns the vendor directory of the module with root directory
+// modRoot.
+func Dir(modRoot string) string {
+	return filepath.Join(modRoot, "cue.mod", "vendor")
+}
+
+// Vendor replaces the vendor directory of the main module with root
+// directory modRoot and module file mf by a copy of the dependencies of
+// mf, fetched from reg. The module file must be tidy, so that it lists
+// all the dependencies of the main module.
+func Vendor(ctx context.Context, reg modload.Registry, mf *modfile.File, modRoot string) error {
+	vendorDir := Dir(modRoot)
+	tmpDir, err := os.MkdirTemp(filepath.Dir(vendorDir), "vendor.tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	deps := mf.DepVersions()
+	module.Sort(deps)
+	var manifest bytes.Buffer
+	manifest.WriteString(manifestHeader)
+	for _, m := range deps {
+		loc, err := reg.Fetch(ctx, m)
+		if err != nil {
+			return fmt.Errorf("cannot fetch %v: %v", m, err)
+		}
+		dir, err := moduleDir(tmpDir, m)
+		if err != nil {
+			return err
+		}
+		if err := copyFS(dir, loc.FS, loc.Dir); err != nil {
+			return fmt.Errorf("cannot vendor %v: %v", m, err)
+		}
+		fmt.Fprintln(&manifest, m)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "modules.txt"), manifest.Bytes(), 0o666); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(vendorDir); err != nil {
+		return err
+	}
+	return os.Rename(tmpDir, vendorDir)
+}
+
+// moduleDir returns the directory of module m in vendor directory dir.
+func moduleDir(dir string, m module.Version) (string, error) {
+	enc, err := module.EscapePath(m.BasePath())
+	if err != nil {
+		return "", err
+	}
+	encVer, err := module.EscapeVersion(m.Version())
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(enc+"@"+encVer)), nil
+}
+
+// copyFS copies the files of fsys within root to directory dst.
+func copyFS(dst string, fsys fs.FS, root string) error {
+	return fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+		if root == "." {
+			rel = p
+		}
+		target := filepath.Join(dst, filepath.FromSlash(rel))
+		if d.IsDir() {
+			if rel == path.Join("cue.mod", "vendor") {
+				return fs.SkipDir
+			}
+			return os.MkdirAll(target, 0o777)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o666)
+	})
+}
+
+// NewRegistry returns a registry that serves the modules vendored in the
+// module with root directory modRoot and module file mf, and all other
+// modules from reg, which may be nil. It returns reg itself if the
+// module has no vendor directory, and an error if the vendored modules
+// are not the dependencies of mf.
+func NewRegistry(reg modload.Registry, mf *modfile.File, modRoot string) (modload.Registry, error) {
+	manifest := filepath.Join(Dir(modRoot), "modules.txt")
+	data, err := os.ReadFile(manifest)
+	if os.IsNotExist(err) {
+		return reg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := &registry{
+		reg:      reg,
+		dir:      Dir(modRoot),
+		versions: map[module.Version]bool{},
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m, err := module.ParseVersion(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", manifest, err)
+		}
+		r.versions[m] = true
+	}
+	deps := mf.DepVersions()
+	inconsistent := len(deps) != len(r.versions)
+	for _, m := range deps {
+		if !r.versions[m] {
+			inconsistent = true
+		}
+	}
+	if inconsistent {
+		return nil, fmt.Errorf("vendored modules in %s do not match the dependencies in the module file; run cue mod vendor", manifest)
+	}
+	return r, nil
+}
+
+type registry struct {
+	reg      modload.Registry
+	dir      string
+	versions map[module.Version]bool
+}
+
+// CUEModSummary implements [modrequirements.Registry.CUEModSummary].
+func (r *registry) CUEModSummary(ctx context.Context, m module.Version) (*modrequirements.ModFileSummary, error) {
+	if !r.versions[m] {
+		if r.reg == nil {
+			return nil, fmt.Errorf("module %v is not vendored", m)
+		}
+		return r.reg.CUEModSummary(ctx, m)
+	}
+	dir, err := moduleDir(r.dir, m)
+	if err != nil {
+		return nil, err
+	}
+	modPath := filepath.Join(dir, "cue.mod", "module.cue")
+	data, err := os.ReadFile(modPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read vendored module file of %v: %v", m, err)
+	}
+	mf, err := modfile.ParseNonStrict(data, modPath)
+	if err != nil {
+		return nil, err
+	}
+	return &modrequirements.ModFileSummary{
+		Module:  m,
+		Require: mf.DepVersions(),
+	}, nil
+}
+
+// Fetch implements [modpkgload.Registry.Fetch].
+func (r *registry) Fetch(ctx context.Context, m module.Version) (modpkgload.SourceLoc, error) {
+	if !r.versions[m] {
+		if r.reg == nil {
+			return modpkgload.SourceLoc{}, fmt.Errorf("module %v is not vendored", m)
+		}
+		return r.reg.Fetch(ctx, m)
+	}
+	dir, err := moduleDir(r.dir, m)
+	if err != nil {
+		return modpkgload.SourceLoc{}, err
+	}
+	return modpkgload.SourceLoc{
+		FS:  dirFS{os.DirFS(dir), dir},
+		Dir: ".",
+	}, nil
+}
+
+// ModuleVersions implements [modload.Registry.ModuleVersions]. The
+// vendored versions are included even if the registry cannot be
+// reached.
+func (r *registry) ModuleVersions(ctx context.Context, mpath string) ([]string, error) {
+	var versions []string
+	var err error
+	if r.reg != nil {
+		versions, err = r.reg.ModuleVersions(ctx, mpath)
+	}
+	found := false
+	for m := range r.versions {
+		if m.Path() != mpath {
+			continue
+		}
+		found = true
+		if !contains(versions, m.Version()) {
+			versions = append(versions, m.Version())
+		}
+	}
+	if err != nil && !found {
+		return nil, err
+	}
+	semver.Sort(versions)
+	return versions, nil
+}
+
+func contains(versions []string, v string) bool {
+	for _, w := range versions {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
+
+// dirFS is the file system of a vendored module. It implements the
+// OSRootFS interface of the modcache package, so that the loader can find
+// the directories of its packages.
+type dirFS struct {
+	fs.FS
+	root string
+}
+
+func (fsys dirFS) OSRoot() string {
+	return fsys.root
+}
@@ -33,6 +33,13 @@
 //
 // • Symbolic links and other irregular files are not allowed.
 //
+// Module zip files created by this package are reproducible: the same
+// set of files always results in the same bytes. Entries are sorted by
+// path, and their modification times and permissions are normalized.
+// Entries are stored uncompressed, as the output of compress/flate may
+// change between Go releases, which would change the digests of module
+// zip files depending on the toolchain that created them.
+//
 // Note that this package does not provide hashing functionality. See
 // golang.org/x/mod/sumdb/dirhash.
 package modzip
@@ -46,7 +53,9 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
@@ -529,7 +538,15 @@ func Create[F any](w io.Writer, m module.Version, files []F, fio FileIO[F]) (err
 			return err
 		}
 		defer rc.Close()
-		w, err := zw.Create(path)
+		// Normalize the metadata so that the resulting zip
+		// only depends on the contents of the files.
+		fh := &zip.FileHeader{
+			Name:     path,
+			Method:   zip.Store,
+			Modified: zipModTime,
+		}
+		fh.SetMode(0o644)
+		w, err := zw.CreateHeader(fh)
 		if err != nil {
 			return err
 		}
@@ -543,10 +560,17 @@ func Create[F any](w io.Writer, m module.Version, files []F, fio FileIO[F]) (err
 		return nil
 	}
 
-	for i, f := range validFiles {
-		p := fio.Path(f)
-		size := validSizes[i]
-		if err := addFile(f, p, size); err != nil {
+	// Add the files in a deterministic order.
+	order := make([]int, len(validFiles))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(i, j int) int {
+		return strings.Compare(fio.Path(validFiles[i]), fio.Path(validFiles[j]))
+	})
+	for _, i := range order {
+		f := validFiles[i]
+		if err := addFile(f, fio.Path(f), validSizes[i]); err != nil {
 			return err
 		}
 	}
@@ -554,6 +578,10 @@ func Create[F any](w io.Writer, m module.Version, files []F, fio FileIO[F]) (err
 	return zw.Close()
 }
 
+// zipModTime is the modification time recorded for all files in
+// module zip files: the earliest time representable in a zip file.
+var zipModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
 // CreateFromDir creates a module zip file for module m from the contents of
 // a directory, dir. The zip content is written to w.
 //
@@ -565,7 +593,15 @@ func Create[F any](w io.Writer, m module.Version, files []F, fio FileIO[F]) (err
 // or irregular files (such as symbolic links) in the output archive.
 // Additionally, unlike Create, CreateFromDir will not include directories
 // named ".bzr", ".git", ".hg", or ".svn".
-func CreateFromDir(w io.Writer, m module.Version, dir string) (err error) {
+func CreateFromDir(w io.Writer, m module.Version, dir string) error {
+	return CreateFromDirFiltered(w, m, dir, nil)
+}
+
+// CreateFromDirFiltered is like CreateFromDir, except that it only includes
+// the files for which include returns true. The include function is called
+// with the slash-separated path of each file relative to dir.
+// If include is nil, all files are included.
+func CreateFromDirFiltered(w io.Writer, m module.Version, dir string, include func(path string) bool) (err error) {
 	defer func() {
 		if zerr, ok := err.(*zipError); ok {
 			zerr.path = dir
@@ -578,6 +614,11 @@ func CreateFromDir(w io.Writer, m module.Version, dir string) (err error) {
 	if err != nil {
 		return err
 	}
+	if include != nil {
+		files = slices.DeleteFunc(files, func(f dirFile) bool {
+			return !include(f.slashPath)
+		})
+	}
 
 	return Create[dirFile](w, m, files, dirFileIO{})
 }
@@ -520,6 +520,76 @@ func TestCreateFromDir(t *testing.T) {
 	}
 }
 
+func TestCreateReproducible(t *testing.T) {
+	t.Parallel()
+	m := module.MustNewVersion("example.com/m@v1", "v1.0.0")
+	files := []fakeFile{
+		{name: "cue.mod/module.cue", data: []byte(`module: "example.com/m@v1"`)},
+		{name: "a.cue", data: []byte("package m\n")},
+		{name: "b/c.cue", data: []byte("package c\n")},
+		{name: "LICENSE", data: []byte("license\n")},
+	}
+	create := func(files []fakeFile) []byte {
+		var buf bytes.Buffer
+		if err := modzip.Create[fakeFile](&buf, m, files, fakeFileIO{}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	want := create(files)
+
+	// The order in which the files are given must not matter.
+	reversed := make([]fakeFile, len(files))
+	for i, f := range files {
+		reversed[len(files)-1-i] = f
+	}
+	if got := create(reversed); !bytes.Equal(got, want) {
+		t.Fatalf("zip depends on the order of files")
+	}
+
+	// Nor do the modification times or permissions of files on disk.
+	dir := t.TempDir()
+	for i, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, f.data, 0o600+os.FileMode(i%2)*0o155); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := modzip.CreateFromDir(&buf, m, dir); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("zip created from directory differs")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(want), int64(len(want)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Mode() != 0o644 {
+			t.Errorf("%s: got mode %v; want 0644", f.Name, f.Mode())
+		}
+		// Compressed entries would depend on the Go release.
+		if f.Method != zip.Store {
+			t.Errorf("%s: got method %d; want zip.Store", f.Name, f.Method)
+		}
+	}
+	if diff := cmp.Diff([]string{"LICENSE", "a.cue", "b/c.cue", "cue.mod/module.cue"}, names); diff != "" {
+		t.Errorf("unexpected file order (-want +got):\n%s", diff)
+	}
+}
+
 func TestCreateFromDirSpecial(t *testing.T) {
 	t.Parallel()
 	for _, test := range []struct {
@@ -0,0 +1,246 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemaregistry
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+)
+
+// jsonSchemaVersion is the JSON Schema draft of generated schemas, which
+// is the latest draft supported by the Confluent Schema Registry.
+const jsonSchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// generateJSONSchema generates a JSON Schema for the CUE definition v.
+// Definitions referred to by v are included in the definitions section of
+// the schema.
+//
+// The schema describes the types, fields, and literal values of v, but not
+// other constraints, such as bounds, which do not affect the compatibility
+// of schemas as checked by a registry.
+//
+// This does not use encoding/openapi, as the builtin packages, which
+// include tool/kafka, may not depend on encoding/jsonschema.
+func generateJSONSchema(v cue.Value) (*ast.StructLit, error) {
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	g := &jsonSchemaGenerator{
+		root:    v,
+		defined: map[string]bool{},
+		defs:    &ast.StructLit{},
+	}
+	root, err := g.schema(v, true)
+	if err != nil {
+		return nil, err
+	}
+	s := ast.NewStruct("$schema", ast.NewString(jsonSchemaVersion))
+	s.Elts = append(s.Elts, root.Elts...)
+	if len(g.defs.Elts) > 0 {
+		s.Elts = append(s.Elts, &ast.Field{
+			Label: ast.NewString("definitions"),
+			Value: g.defs,
+		})
+	}
+	return s, nil
+}
+
+type jsonSchemaGenerator struct {
+	root cue.Value
+
+	// defined records the definitions that have been added to defs.
+	defined map[string]bool
+	defs    *ast.StructLit
+}
+
+// schema returns the schema for v. Unless top is set, a reference to a
+// definition other than the root is generated as a $ref to that
+// definition.
+func (g *jsonSchemaGenerator) schema(v cue.Value, top bool) (*ast.StructLit, error) {
+	if !top {
+		if _, ref := v.ReferencePath(); len(ref.Selectors()) > 0 {
+			if name := defName(ref); name != "" {
+				return g.ref(v, ref, name)
+			}
+		}
+	}
+	s := &ast.StructLit{}
+	if doc := docString(v); doc != "" {
+		s.Elts = append(s.Elts, &ast.Field{
+			Label: ast.NewString("description"),
+			Value: ast.NewString(doc),
+		})
+	}
+	add := func(label string, x ast.Expr) {
+		s.Elts = append(s.Elts, &ast.Field{Label: ast.NewString(label), Value: x})
+	}
+
+	if op, args := v.Expr(); op == cue.OrOp {
+		if lits, ok := literals(args); ok {
+			add("enum", ast.NewList(lits...))
+			return s, nil
+		}
+		var list []ast.Expr
+		for _, x := range args {
+			t, err := g.schema(x, false)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, t)
+		}
+		add("anyOf", ast.NewList(list...))
+		return s, nil
+	}
+	if k := v.Kind(); v.IsConcrete() && k != cue.NullKind && k != cue.StructKind && k != cue.ListKind {
+		x, _ := v.Syntax(cue.Final()).(ast.Expr)
+		add("const", x)
+		return s, nil
+	}
+
+	switch k := v.IncompleteKind(); k {
+	case cue.TopKind:
+	case cue.NullKind:
+		add("type", ast.NewString("null"))
+	case cue.BoolKind:
+		add("type", ast.NewString("boolean"))
+	case cue.IntKind:
+		add("type", ast.NewString("integer"))
+	case cue.FloatKind, cue.NumberKind:
+		add("type", ast.NewString("number"))
+	case cue.StringKind:
+		add("type", ast.NewString("string"))
+	case cue.BytesKind:
+		add("type", ast.NewString("string"))
+		add("contentEncoding", ast.NewString("base64"))
+	case cue.ListKind:
+		add("type", ast.NewString("array"))
+		if elem := v.LookupPath(cue.MakePath(cue.AnyIndex)); elem.Exists() {
+			items, err := g.schema(elem, false)
+			if err != nil {
+				return nil, err
+			}
+			add("items", items)
+		}
+	case cue.StructKind:
+		add("type", ast.NewString("object"))
+		if err := g.object(s, v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Newf(v.Pos(), "cannot generate JSON Schema for value of type %v", k)
+	}
+	return s, nil
+}
+
+// object adds the properties of the struct v to the object schema s.
+func (g *jsonSchemaGenerator) object(s *ast.StructLit, v cue.Value) error {
+	props := &ast.StructLit{}
+	var required []ast.Expr
+	iter, err := v.Fields(cue.Optional(true))
+	if err != nil {
+		return err
+	}
+	for iter.Next() {
+		label := iter.Selector().Unquoted()
+		p, err := g.schema(iter.Value(), false)
+		if err != nil {
+			return err
+		}
+		props.Elts = append(props.Elts, &ast.Field{Label: ast.NewString(label), Value: p})
+		if !iter.IsOptional() {
+			required = append(required, ast.NewString(label))
+		}
+	}
+	if len(props.Elts) > 0 {
+		s.Elts = append(s.Elts, &ast.Field{Label: ast.NewString("properties"), Value: props})
+	}
+	if len(required) > 0 {
+		s.Elts = append(s.Elts, &ast.Field{Label: ast.NewString("required"), Value: ast.NewList(required...)})
+	}
+	var additional ast.Expr
+	if elem := v.LookupPath(cue.MakePath(cue.AnyString)); elem.Exists() {
+		x, err := g.schema(elem, false)
+		if err != nil {
+			return err
+		}
+		additional = x
+	} else if !v.Allows(cue.AnyString) {
+		additional = ast.NewBool(false)
+	}
+	if additional != nil {
+		s.Elts = append(s.Elts, &ast.Field{Label: ast.NewString("additionalProperties"), Value: additional})
+	}
+	return nil
+}
+
+// ref returns a reference to the definition with the given name at path p,
+// adding it to the definitions if needed.
+func (g *jsonSchemaGenerator) ref(v cue.Value, p cue.Path, name string) (*ast.StructLit, error) {
+	if p.String() == g.root.Path().String() {
+		return ast.NewStruct("$ref", ast.NewString("#")), nil
+	}
+	if !g.defined[name] {
+		g.defined[name] = true
+		def, err := g.schema(v, true)
+		if err != nil {
+			return nil, err
+		}
+		g.defs.Elts = append(g.defs.Elts, &ast.Field{Label: ast.NewString(name), Value: def})
+	}
+	return ast.NewStruct("$ref", ast.NewString("#/definitions/"+name)), nil
+}
+
+// literals returns the syntax of the values if they are all concrete
+// scalars.
+func literals(values []cue.Value) ([]ast.Expr, bool) {
+	var a []ast.Expr
+	for _, x := range values {
+		switch x.Kind() {
+		case cue.NullKind, cue.BoolKind, cue.IntKind, cue.FloatKind, cue.StringKind:
+		default:
+			return nil, false
+		}
+		lit, ok := x.Syntax(cue.Final()).(ast.Expr)
+		if !ok {
+			return nil, false
+		}
+		a = append(a, lit)
+	}
+	return a, true
+}
+
+// defName returns the name of the definition at the end of p, without the
+// leading #, or "" if p does not end in a definition.
+func defName(p cue.Path) string {
+	sels := p.Selectors()
+	if len(sels) == 0 || !sels[len(sels)-1].IsDefinition() {
+		return ""
+	}
+	return strings.TrimPrefix(sels[len(sels)-1].String(), "#")
+}
+
+// docString returns the doc comments of v as a single string.
+func docString(v cue.Value) string {
+	var a []string
+	for _, c := range v.Doc() {
+		if s := strings.TrimSpace(c.Text()); s != "" {
+			a = append(a, s)
+		}
+	}
+	return strings.Join(a, "\n")
+}
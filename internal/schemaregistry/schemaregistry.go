@@ -0,0 +1,342 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schemaregistry implements a client for schema registries that
+// implement the API of the Confluent Schema Registry, which Kafka producers
+// and consumers use to agree on the schemas of messages, and generates the
+// schemas to register from CUE definitions.
+//
+// Schemas are registered under a subject, which usually derives from the
+// name of a topic, such as "orders-value" for the values of the messages
+// of the orders topic. Each distinct schema registered under a subject
+// is a new version of the subject.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/encoding/avro"
+	internaljson "cuelang.org/go/internal/encoding/json"
+)
+
+// A Format is the format of a schema, as named by the registry.
+type Format string
+
+const (
+	Avro Format = "AVRO"
+	JSON Format = "JSON"
+)
+
+// ParseFormat parses a format given by the user, such as "avro" or "json".
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToUpper(s)); f {
+	case Avro, JSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported schema format %q: must be avro or json", s)
+}
+
+// A Schema is a schema in one of the formats supported by the registry.
+type Schema struct {
+	Format Format
+
+	// Text holds the schema, which is JSON for both Avro and JSON Schema.
+	Text string
+}
+
+// Generate generates a schema in the given format from the CUE definition
+// v. The name, if not empty, names the record of an Avro schema.
+func Generate(v cue.Value, f Format, name string) (Schema, error) {
+	var b []byte
+	switch f {
+	case Avro:
+		s, err := avro.Generate(v, &avro.Config{Name: name})
+		if err != nil {
+			return Schema{}, err
+		}
+		if b, err = internaljson.Encode(s); err != nil {
+			return Schema{}, err
+		}
+	case JSON:
+		s, err := generateJSONSchema(v)
+		if err != nil {
+			return Schema{}, err
+		}
+		if b, err = internaljson.Encode(s); err != nil {
+			return Schema{}, err
+		}
+	default:
+		return Schema{}, fmt.Errorf("unsupported schema format %q", f)
+	}
+	return Schema{Format: f, Text: string(b)}, nil
+}
+
+// Equal reports whether s and t are the same schema, disregarding the
+// formatting of their JSON.
+func (s Schema) Equal(t Schema) bool {
+	if s.Format != t.Format {
+		return false
+	}
+	var a, b bytes.Buffer
+	if json.Compact(&a, []byte(s.Text)) != nil || json.Compact(&b, []byte(t.Text)) != nil {
+		return s.Text == t.Text
+	}
+	return a.String() == b.String()
+}
+
+// A Version is a schema registered under a subject.
+type Version struct {
+	Subject string
+	Version int
+
+	// ID identifies the schema across all subjects.
+	ID int
+
+	Schema
+}
+
+// A Compatibility reports whether a schema is compatible with the latest
+// version of a subject, according to the compatibility level configured
+// for the subject in the registry.
+type Compatibility struct {
+	Compatible bool
+
+	// Messages explains why a schema is not compatible.
+	Messages []string
+}
+
+// ErrNotFound is returned when a subject or version does not exist.
+var ErrNotFound = errors.New("not found")
+
+// An Error is an error returned by the registry.
+type Error struct {
+	StatusCode int
+	Code       int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("schema registry: %s (error code %d)", e.Message, e.Code)
+}
+
+// Is reports whether the error indicates that a subject or version does not
+// exist, which allows it to be matched against ErrNotFound.
+func (e *Error) Is(err error) bool {
+	return err == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// A Config configures a Client.
+type Config struct {
+	// URL is the base URL of the registry. Credentials for basic
+	// authentication may be given as its user information.
+	URL string
+
+	// Username and Password, if set, are used for basic authentication,
+	// overriding any credentials given in URL.
+	Username string
+	Password string
+
+	// HTTPClient is used to make requests. It defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// A Client makes requests to a schema registry.
+type Client struct {
+	base     *url.URL
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewClient returns a client for the registry configured by cfg.
+func NewClient(cfg *Config) (*Client, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid schema registry URL %q: must be http or https", cfg.URL)
+	}
+	c := &Client{
+		base:     u,
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   cfg.HTTPClient,
+	}
+	if u.User != nil {
+		if c.username == "" {
+			c.username = u.User.Username()
+			c.password, _ = u.User.Password()
+		}
+		u.User = nil
+	}
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	return c, nil
+}
+
+// schemaRequest is the body of requests that submit a schema.
+type schemaRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+// schemaResponse describes a version of a subject.
+type schemaResponse struct {
+	Subject    string `json:"subject"`
+	ID         int    `json:"id"`
+	Version    int    `json:"version"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+}
+
+func newSchemaRequest(s Schema) *schemaRequest {
+	r := &schemaRequest{Schema: s.Text}
+	// Avro is the default and may not be named explicitly by older
+	// registries.
+	if s.Format != Avro {
+		r.SchemaType = string(s.Format)
+	}
+	return r
+}
+
+func (r *schemaResponse) version() Version {
+	f := Format(r.SchemaType)
+	if f == "" {
+		f = Avro
+	}
+	return Version{
+		Subject: r.Subject,
+		Version: r.Version,
+		ID:      r.ID,
+		Schema:  Schema{Format: f, Text: r.Schema},
+	}
+}
+
+// Register registers s under subject and returns the resulting version. If
+// the subject already has a version with the same schema, that version is
+// returned instead of creating a new one.
+func (c *Client) Register(ctx context.Context, subject string, s Schema) (Version, error) {
+	var reg struct {
+		ID int `json:"id"`
+	}
+	err := c.do(ctx, "POST", []string{"subjects", subject, "versions"}, nil, newSchemaRequest(s), &reg)
+	if err != nil {
+		return Version{}, err
+	}
+	// The response only holds the ID of the schema. Look it up to find
+	// its version.
+	var r schemaResponse
+	if err := c.do(ctx, "POST", []string{"subjects", subject}, nil, newSchemaRequest(s), &r); err != nil {
+		return Version{}, err
+	}
+	v := r.version()
+	v.Schema = s
+	return v, nil
+}
+
+// Latest returns the latest version of subject. It returns an error
+// matching ErrNotFound if the subject does not exist.
+func (c *Client) Latest(ctx context.Context, subject string) (Version, error) {
+	var r schemaResponse
+	if err := c.do(ctx, "GET", []string{"subjects", subject, "versions", "latest"}, nil, nil, &r); err != nil {
+		return Version{}, err
+	}
+	return r.version(), nil
+}
+
+// CheckCompatibility reports whether s is compatible with the latest
+// version of subject. A schema is compatible with a subject that does not
+// exist yet.
+func (c *Client) CheckCompatibility(ctx context.Context, subject string, s Schema) (Compatibility, error) {
+	var r struct {
+		IsCompatible bool     `json:"is_compatible"`
+		Messages     []string `json:"messages"`
+	}
+	query := url.Values{"verbose": {"true"}}
+	err := c.do(ctx, "POST", []string{"compatibility", "subjects", subject, "versions", "latest"}, query, newSchemaRequest(s), &r)
+	if errors.Is(err, ErrNotFound) {
+		return Compatibility{Compatible: true}, nil
+	}
+	if err != nil {
+		return Compatibility{}, err
+	}
+	return Compatibility{Compatible: r.IsCompatible, Messages: r.Messages}, nil
+}
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// do sends a request to the endpoint with the given path elements and
+// decodes the JSON response into resp.
+func (c *Client) do(ctx context.Context, method string, path []string, query url.Values, req, resp any) error {
+	u := c.base.JoinPath(path...)
+	u.RawQuery = query.Encode()
+
+	var body io.Reader
+	if req != nil {
+		b, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	r, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Accept", contentType)
+	if req != nil {
+		r.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" || c.password != "" {
+		r.SetBasicAuth(c.username, c.password)
+	}
+	res, err := c.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode/100 != 2 {
+		e := &Error{StatusCode: res.StatusCode}
+		var msg struct {
+			Code    int    `json:"error_code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &msg) == nil && msg.Message != "" {
+			e.Code, e.Message = msg.Code, msg.Message
+		} else {
+			e.Code, e.Message = res.StatusCode, strings.TrimSpace(res.Status)
+		}
+		return e
+	}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("schema registry: invalid response from %s: %v", u, err)
+	}
+	return nil
+}
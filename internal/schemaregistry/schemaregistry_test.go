@@ -0,0 +1,148 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemaregistry_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/internal/schemaregistry"
+	"cuelang.org/go/internal/schemaregistry/schemaregistrytest"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	var auth string
+	reg := schemaregistrytest.New()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		auth = user + ":" + pass
+		reg.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	c, err := schemaregistry.NewClient(&schemaregistry.Config{
+		URL: "http://key:secret@" + srv.Listener.Addr().String(),
+	})
+	qt.Assert(t, qt.IsNil(err))
+
+	_, err = c.Latest(ctx, "orders-value")
+	qt.Assert(t, qt.ErrorIs(err, schemaregistry.ErrNotFound))
+	qt.Assert(t, qt.Equals(auth, "key:secret"))
+
+	v := cuecontext.New().CompileString(`
+	#Order: {
+		id:     string
+		amount: int
+		note?:  string
+	}
+	#OrderV2: {
+		id:   string
+		note: string
+	}
+	`)
+	order, err := schemaregistry.Generate(v.LookupPath(cue.ParsePath("#Order")), schemaregistry.Avro, "")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(order.Text, `{"type":"record","name":"Order","fields":[`+
+		`{"name":"id","type":"string"},{"name":"amount","type":"long"},`+
+		`{"name":"note","type":["null","string"],"default":null}]}`))
+
+	compat, err := c.CheckCompatibility(ctx, "orders-value", order)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsTrue(compat.Compatible))
+
+	got, err := c.Register(ctx, "orders-value", order)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(got, schemaregistry.Version{
+		Subject: "orders-value",
+		Version: 1,
+		ID:      1,
+		Schema:  order,
+	}))
+
+	// Registering the same schema again does not create a new version.
+	got, err = c.Register(ctx, "orders-value", order)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(got.Version, 1))
+
+	latest, err := c.Latest(ctx, "orders-value")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsTrue(latest.Equal(order)))
+
+	v2, err := schemaregistry.Generate(v.LookupPath(cue.ParsePath("#OrderV2")), schemaregistry.Avro, "Order")
+	qt.Assert(t, qt.IsNil(err))
+	compat, err = c.CheckCompatibility(ctx, "orders-value", v2)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.DeepEquals(compat, schemaregistry.Compatibility{
+		Messages: []string{`field "amount" was removed`},
+	}))
+
+	_, err = c.Register(ctx, "orders-value", v2)
+	qt.Assert(t, qt.ErrorMatches(err, `schema registry: Schema being registered is incompatible .* \(error code 409\)`))
+
+	json, err := schemaregistry.Generate(v.LookupPath(cue.ParsePath("#OrderV2")), schemaregistry.JSON, "")
+	qt.Assert(t, qt.IsNil(err))
+	got, err = c.Register(ctx, "orders-json-value", json)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(got.ID, 2))
+	latest, err = c.Latest(ctx, "orders-json-value")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(latest.Format, schemaregistry.JSON))
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := schemaregistry.ParseFormat("avro")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(f, schemaregistry.Avro))
+
+	_, err = schemaregistry.ParseFormat("protobuf")
+	qt.Assert(t, qt.ErrorMatches(err, `unsupported schema format "protobuf": must be avro or json`))
+}
+
+func TestGenerateJSON(t *testing.T) {
+	v := cuecontext.New().CompileString(`
+	// An Order is placed by a customer.
+	#Order: {
+		id:     string
+		status: "new" | "paid"
+		lines: [...#Line]
+		tags?: {[string]: string}
+		parent?: #Order
+	}
+	#Line: {
+		sku:   string
+		count: int & >0
+		price: number | null
+	}
+	`)
+	s, err := schemaregistry.Generate(v.LookupPath(cue.ParsePath("#Order")), schemaregistry.JSON, "")
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(s.Text, `{"$schema":"http://json-schema.org/draft-07/schema#",`+
+		`"description":"An Order is placed by a customer.","type":"object","properties":{`+
+		`"id":{"type":"string"},"status":{"enum":["new","paid"]},`+
+		`"lines":{"type":"array","items":{"$ref":"#/definitions/Line"}},`+
+		`"tags":{"type":"object","additionalProperties":{"type":"string"}},`+
+		`"parent":{"$ref":"#"}},`+
+		`"required":["id","status","lines"],"additionalProperties":false,`+
+		`"definitions":{"Line":{"type":"object","properties":{`+
+		`"sku":{"type":"string"},"count":{"type":"integer"},"price":{"anyOf":[{"type":"number"},{"type":"null"}]}},`+
+		`"required":["sku","count","price"],"additionalProperties":false}}}`))
+}
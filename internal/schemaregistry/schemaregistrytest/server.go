@@ -0,0 +1,210 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schemaregistrytest provides an in-memory schema registry for
+// testing clients of the Confluent Schema Registry API.
+package schemaregistrytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Registry is an in-memory schema registry that implements the parts of
+// the API used by package schemaregistry.
+//
+// Its compatibility check is a simplified form of backward compatibility:
+// a schema is incompatible with the latest version of a subject if that
+// version has a field, or a JSON Schema property, that the schema does not
+// have.
+type Registry struct {
+	mu       sync.Mutex
+	subjects map[string][]*version
+	nextID   int
+}
+
+type version struct {
+	ID         int    `json:"id"`
+	Subject    string `json:"subject"`
+	Version    int    `json:"version"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+// New returns an empty registry.
+func New() *Registry {
+	return &Registry{subjects: map[string][]*version{}}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status, code int, format string, args ...any) {
+	writeJSON(w, status, map[string]any{
+		"error_code": code,
+		"message":    fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case req.Method == "GET" && len(path) == 4 && path[0] == "subjects" && path[2] == "versions" && path[3] == "latest":
+		versions := r.subjects[path[1]]
+		if len(versions) == 0 {
+			writeError(w, http.StatusNotFound, 40401, "Subject '%s' not found.", path[1])
+			return
+		}
+		writeJSON(w, http.StatusOK, versions[len(versions)-1])
+
+	case req.Method == "POST" && len(path) == 3 && path[0] == "subjects" && path[2] == "versions":
+		s, ok := readSchema(w, req)
+		if !ok {
+			return
+		}
+		if v := r.lookup(path[1], s); v != nil {
+			writeJSON(w, http.StatusOK, map[string]int{"id": v.ID})
+			return
+		}
+		if versions := r.subjects[path[1]]; len(versions) > 0 {
+			if msgs := incompatibilities(versions[len(versions)-1], s); len(msgs) > 0 {
+				writeError(w, http.StatusConflict, 409, "Schema being registered is incompatible with an earlier schema: %s", strings.Join(msgs, "; "))
+				return
+			}
+		}
+		r.nextID++
+		s.ID = r.nextID
+		s.Subject = path[1]
+		s.Version = len(r.subjects[path[1]]) + 1
+		r.subjects[path[1]] = append(r.subjects[path[1]], s)
+		writeJSON(w, http.StatusOK, map[string]int{"id": s.ID})
+
+	case req.Method == "POST" && len(path) == 2 && path[0] == "subjects":
+		s, ok := readSchema(w, req)
+		if !ok {
+			return
+		}
+		if len(r.subjects[path[1]]) == 0 {
+			writeError(w, http.StatusNotFound, 40401, "Subject '%s' not found.", path[1])
+			return
+		}
+		v := r.lookup(path[1], s)
+		if v == nil {
+			writeError(w, http.StatusNotFound, 40403, "Schema not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+
+	case req.Method == "POST" && len(path) == 5 && path[0] == "compatibility" && path[1] == "subjects" && path[3] == "versions" && path[4] == "latest":
+		s, ok := readSchema(w, req)
+		if !ok {
+			return
+		}
+		versions := r.subjects[path[2]]
+		if len(versions) == 0 {
+			writeError(w, http.StatusNotFound, 40401, "Subject '%s' not found.", path[2])
+			return
+		}
+		msgs := incompatibilities(versions[len(versions)-1], s)
+		resp := map[string]any{"is_compatible": len(msgs) == 0}
+		if req.URL.Query().Get("verbose") == "true" {
+			resp["messages"] = append([]string{}, msgs...)
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	default:
+		writeError(w, http.StatusNotFound, 404, "HTTP 404 Not Found")
+	}
+}
+
+func readSchema(w http.ResponseWriter, req *http.Request) (*version, bool) {
+	var s version
+	if err := json.NewDecoder(req.Body).Decode(&s); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, 42201, "Invalid schema: %v", err)
+		return nil, false
+	}
+	if _, err := fields(&s); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, 42201, "Invalid schema: %v", err)
+		return nil, false
+	}
+	return &s, true
+}
+
+// lookup returns the version of subject with the same schema as s.
+func (r *Registry) lookup(subject string, s *version) *version {
+	for _, v := range r.subjects[subject] {
+		if v.SchemaType == s.SchemaType && compact(v.Schema) == compact(s.Schema) {
+			return v
+		}
+	}
+	return nil
+}
+
+func compact(s string) string {
+	var b bytes.Buffer
+	if json.Compact(&b, []byte(s)) != nil {
+		return s
+	}
+	return b.String()
+}
+
+// incompatibilities returns the fields of old that are missing in s.
+func incompatibilities(old, s *version) []string {
+	if old.SchemaType != s.SchemaType {
+		return []string{"schema type changed"}
+	}
+	oldFields, _ := fields(old)
+	newFields, _ := fields(s)
+	var msgs []string
+	for f := range oldFields {
+		if !newFields[f] {
+			msgs = append(msgs, fmt.Sprintf("field %q was removed", f))
+		}
+	}
+	sort.Strings(msgs)
+	return msgs
+}
+
+// fields returns the names of the top-level fields of an Avro record or the
+// properties of a JSON Schema.
+func fields(s *version) (map[string]bool, error) {
+	var schema struct {
+		Fields []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(s.Schema), &schema); err != nil {
+		return nil, err
+	}
+	m := map[string]bool{}
+	for _, f := range schema.Fields {
+		m[f.Name] = true
+	}
+	for name := range schema.Properties {
+		m[name] = true
+	}
+	return m, nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yaml
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+)
+
+// A Config holds options for decoding YAML.
+//
+// Some plain scalars have a type that differs between YAML 1.1 and the
+// YAML 1.2 core schema. By default, such booleans, base 60 numbers and
+// timestamps are decoded as strings, as in YAML 1.2, while integers with
+// a leading zero are decoded as octal numbers, as in YAML 1.1.
+type Config struct {
+	// YAML11Bools decodes the YAML 1.1 booleans y, yes, on, n, no and
+	// off, in lower, title or upper case, as booleans.
+	YAML11Bools bool
+
+	// Sexagesimal decodes the YAML 1.1 base 60 numbers, such as
+	// 1:30:00, as numbers.
+	Sexagesimal bool
+
+	// Timestamps decodes timestamps, such as 2001-12-14 21:59:43.10,
+	// as strings in RFC 3339 format, such as "2001-12-14T21:59:43.1Z",
+	// instead of as written.
+	Timestamps bool
+
+	// Strict reports an error for each plain scalar with a type that
+	// differs between YAML 1.1 and YAML 1.2, which must then be quoted
+	// or tagged.
+	Strict bool
+
+	// Report, if not nil, is called for each plain scalar with a type
+	// that differs between YAML 1.1 and YAML 1.2.
+	Report func(Coercion)
+}
+
+// The kinds of ambiguous plain scalars.
+const (
+	KindBool        = "bool"        // YAML 1.1 boolean, such as no or on
+	KindSexagesimal = "sexagesimal" // YAML 1.1 base 60 number, such as 1:30
+	KindTimestamp   = "timestamp"   // timestamp, such as 2001-12-14
+	KindOctal       = "octal"       // YAML 1.1 octal integer, such as 0755
+)
+
+// A Coercion describes how a plain scalar with a type that differs
+// between YAML 1.1 and YAML 1.2 was decoded.
+type Coercion struct {
+	Pos token.Pos
+
+	// Value holds the scalar as written.
+	Value string
+
+	// Kind holds the kind of the scalar, such as KindBool.
+	Kind string
+
+	// Result holds the decoded value in CUE syntax.
+	Result string
+}
+
+func (c Coercion) String() string {
+	return fmt.Sprintf("%s: %s decoded as %s (%s)", c.Pos, c.Value, c.Result, kindDescriptions[c.Kind])
+}
+
+var kindDescriptions = map[string]string{
+	KindBool:        "a boolean in YAML 1.1, a string in YAML 1.2",
+	KindSexagesimal: "a base 60 number in YAML 1.1, a string in YAML 1.2",
+	KindTimestamp:   "a timestamp in YAML 1.1, a string in YAML 1.2",
+	KindOctal:       "an octal integer in YAML 1.1, a decimal integer in YAML 1.2",
+}
+
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"on": true, "On": true, "ON": true,
+	"n": false, "N": false, "no": false, "No": false, "NO": false,
+	"off": false, "Off": false, "OFF": false,
+}
+
+var (
+	sexagesimalRE = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+	octalRE       = regexp.MustCompile(`^[-+]?0[0-7_]+$`)
+)
+
+// ambiguousKind returns the kind of the plain scalar s if its type
+// differs between YAML 1.1 and YAML 1.2, or "" otherwise.
+func ambiguousKind(s string) string {
+	if _, ok := yaml11Bools[s]; ok {
+		return KindBool
+	}
+	if sexagesimalRE.MatchString(s) {
+		return KindSexagesimal
+	}
+	if _, ok := parseTimestamp(s); ok {
+		return KindTimestamp
+	}
+	if octalRE.MatchString(s) {
+		return KindOctal
+	}
+	return ""
+}
+
+// ambiguous checks whether n is an ambiguous plain scalar. If it is, it
+// records an error in strict mode, and returns its kind and, if the
+// configuration selects a YAML 1.1 interpretation of it, its decoded
+// value. It returns a nil expression if n is to be decoded as usual.
+func (d *decoder) ambiguous(n *node) (kind string, expr ast.Expr) {
+	if n.tag != "" || !n.implicit {
+		return "", nil
+	}
+	kind = ambiguousKind(n.value)
+	if kind == "" {
+		return "", nil
+	}
+	if d.cfg.Strict {
+		// Decoding continues, so that all ambiguous scalars are reported.
+		d.terrors = append(d.terrors, fmt.Sprintf("%s:%d: ambiguous plain scalar %s: %s; quote it or add an explicit tag",
+			d.p.parser.filename, n.startPos.line+1, n.value, kindDescriptions[kind]))
+	}
+	switch kind {
+	case KindBool:
+		if d.cfg.YAML11Bools {
+			b := yaml11Bools[n.value]
+			return kind, &ast.BasicLit{
+				ValuePos: d.start(n),
+				Kind:     boolToken(b),
+				Value:    strconv.FormatBool(b),
+			}
+		}
+	case KindSexagesimal:
+		if d.cfg.Sexagesimal {
+			val, tok := parseSexagesimal(n.value)
+			return kind, d.makeNum(n, val, tok)
+		}
+	case KindTimestamp:
+		if d.cfg.Timestamps {
+			t, _ := parseTimestamp(n.value)
+			return kind, &ast.BasicLit{
+				ValuePos: d.start(n),
+				Kind:     token.STRING,
+				Value:    literal.String.Quote(t.Format(time.RFC3339Nano)),
+			}
+		}
+	}
+	return kind, nil
+}
+
+// report calls the Report function of the configuration, if any, for the
+// ambiguous plain scalar n of the given kind, which was decoded as expr.
+func (d *decoder) report(n *node, kind string, expr ast.Expr) {
+	if d.cfg.Report == nil {
+		return
+	}
+	result, err := format.Node(expr)
+	if err != nil {
+		result = []byte(fmt.Sprint(expr))
+	}
+	d.cfg.Report(Coercion{
+		Pos:    d.pos(n.startPos),
+		Value:  n.value,
+		Kind:   kind,
+		Result: string(result),
+	})
+}
+
+func boolToken(b bool) token.Token {
+	if b {
+		return token.TRUE
+	}
+	return token.FALSE
+}
+
+// parseSexagesimal returns the value of the base 60 number s in CUE
+// syntax, and whether it is an integer or a float.
+func parseSexagesimal(s string) (string, token.Token) {
+	s = strings.ReplaceAll(s, "_", "")
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+	parts := strings.Split(s, ":")
+	last := parts[len(parts)-1]
+	isFloat := strings.Contains(last, ".")
+	var ival int64
+	for _, p := range parts[:len(parts)-1] {
+		v, _ := strconv.ParseInt(p, 10, 64)
+		ival = ival*60 + v
+	}
+	sign := ""
+	if neg {
+		sign = "-"
+	}
+	if !isFloat {
+		v, _ := strconv.ParseInt(last, 10, 64)
+		return sign + strconv.FormatInt(ival*60+v, 10), token.INT
+	}
+	v, _ := strconv.ParseFloat(last, 64)
+	f := strconv.FormatFloat(float64(ival)*60+v, 'f', -1, 64)
+	if !strings.Contains(f, ".") {
+		f += ".0"
+	}
+	return sign + f, token.FLOAT
+}
@@ -262,6 +262,7 @@ func (p *parser) mapping() *node {
 
 type decoder struct {
 	p            *parser
+	cfg          *Config
 	doc          *node
 	aliases      map[*node]bool
 	terrors      []string
@@ -277,8 +278,11 @@ var (
 	ptrTimeType    = reflect.TypeOf(&time.Time{})
 )
 
-func newDecoder(p *parser) *decoder {
-	d := &decoder{p: p}
+func newDecoder(p *parser, cfg *Config) *decoder {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	d := &decoder{p: p, cfg: cfg}
 	d.aliases = make(map[*node]bool)
 	return d
 }
@@ -423,7 +427,13 @@ func (d *decoder) alias(n *node) ast.Expr {
 		d.p.failf(n.startPos.line, "anchor '%s' value contains itself", n.value)
 	}
 	d.aliases[n] = true
+	// The expanded value keeps the positions of the anchored value, so
+	// that errors point to where it is defined. As these precede the
+	// alias, they are not related to the positions decoded so far.
+	prev := d.prev
+	d.prev = token.NoPos
 	node := d.unmarshal(n.alias)
+	d.prev = prev
 	delete(d.aliases, n)
 	return node
 }
@@ -431,6 +441,17 @@ func (d *decoder) alias(n *node) ast.Expr {
 var zeroValue reflect.Value
 
 func (d *decoder) scalar(n *node) ast.Expr {
+	kind, expr := d.ambiguous(n)
+	if expr == nil {
+		expr = d.resolveScalar(n)
+	}
+	if kind != "" {
+		d.report(n, kind, expr)
+	}
+	return expr
+}
+
+func (d *decoder) resolveScalar(n *node) ast.Expr {
 	var tag string
 	var resolved interface{}
 	if n.tag == "" && !n.implicit {
@@ -628,6 +649,9 @@ func (d *decoder) sequence(n *node) ast.Expr {
 	}
 	list.Rbrack = d.pos(n.endPos)
 
+	// Decode the elements relative to the opening bracket, rather than to
+	// the closing one, so that they keep their positions.
+	d.prev = list.Lbrack
 	noNewline := true
 	single := d.isOneLiner(n.startPos, n.endPos)
 	for _, c := range n.children {
@@ -636,6 +660,7 @@ func (d *decoder) sequence(n *node) ast.Expr {
 		list.Elts = append(list.Elts, elem)
 		_, noNewline = elem.(*ast.StructLit)
 	}
+	d.prev = list.Rbrack
 	if !single && !noNewline {
 		list.Rbrack = list.Rbrack.WithRel(token.Newline)
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -762,6 +763,41 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+func TestAliasPositions(t *testing.T) {
+	data := `
+a: &a
+  b: 1
+  c: [x, y]
+d: *a
+e: &e "str"
+f: *e
+`
+	expr, err := callUnmarshal(t, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	ast.Walk(expr, func(n ast.Node) bool {
+		if x, ok := n.(*ast.BasicLit); ok {
+			got = append(got, fmt.Sprintf("%s %s", x.Value, x.Pos()))
+		}
+		return true
+	}, nil)
+	want := []string{
+		`1 test.yaml:3:6`,
+		`"x" test.yaml:4:7`,
+		`"y" test.yaml:4:10`,
+		`1 test.yaml:3:6`,
+		`"x" test.yaml:4:7`,
+		`"y" test.yaml:4:10`,
+		`"str" test.yaml:6:4`,
+		`"str" test.yaml:6:4`,
+	}
+	if g, w := strings.Join(got, "\n"), strings.Join(want, "\n"); g != w {
+		t.Errorf("\n got:\n%v\nwant:\n%v", g, w)
+	}
+}
+
 // For debug purposes: do not delete.
 func TestX(t *testing.T) {
 	y := `
@@ -910,6 +946,138 @@ func TestDecoderErrors(t *testing.T) {
 	}
 }
 
+func TestDecoderConfig(t *testing.T) {
+	const data = `
+a: on
+b: 1:30
+c: 1:30.5
+d: 2001-12-14t21:59:43.10-05:00
+e: 0755
+f: "on"
+g: !!str 1:30
+h: hello
+`
+	tests := []struct {
+		name   string
+		cfg    yaml.Config
+		want   string
+		report []string
+	}{{
+		name: "Default",
+		want: `a: "on"
+b: "1:30"
+c: "1:30.5"
+d: "2001-12-14t21:59:43.10-05:00"
+e: 0o755
+f: "on"
+g: "1:30"
+h: "hello"`,
+		report: []string{
+			`test.yaml:2:4: on decoded as "on" (a boolean in YAML 1.1, a string in YAML 1.2)`,
+			`test.yaml:3:4: 1:30 decoded as "1:30" (a base 60 number in YAML 1.1, a string in YAML 1.2)`,
+			`test.yaml:4:4: 1:30.5 decoded as "1:30.5" (a base 60 number in YAML 1.1, a string in YAML 1.2)`,
+			`test.yaml:5:4: 2001-12-14t21:59:43.10-05:00 decoded as "2001-12-14t21:59:43.10-05:00" (a timestamp in YAML 1.1, a string in YAML 1.2)`,
+			`test.yaml:6:4: 0755 decoded as 0o755 (an octal integer in YAML 1.1, a decimal integer in YAML 1.2)`,
+		},
+	}, {
+		name: "YAML11",
+		cfg: yaml.Config{
+			YAML11Bools: true,
+			Sexagesimal: true,
+			Timestamps:  true,
+		},
+		want: `a: true
+b: 90
+c: 90.5
+d: "2001-12-14T21:59:43.1-05:00"
+e: 0o755
+f: "on"
+g: "1:30"
+h: "hello"`,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var report []string
+			cfg := test.cfg
+			cfg.Report = func(c yaml.Coercion) {
+				report = append(report, c.String())
+			}
+			dec, err := yaml.NewDecoderConfig("test.yaml", data, &cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expr, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cueStr(expr); got != test.want {
+				t.Errorf("\n got:\n%v\nwant:\n%v", got, test.want)
+			}
+			if len(report) != 5 {
+				t.Errorf("got %d coercions; want 5:\n%s", len(report), strings.Join(report, "\n"))
+			}
+			for i, want := range test.report {
+				if i < len(report) && report[i] != want {
+					t.Errorf("coercion %d:\n got: %s\nwant: %s", i, report[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecoderConfigStrict(t *testing.T) {
+	for _, data := range []string{
+		"a: no",
+		"a: 1:30",
+		"a: 2001-12-14",
+		"a: [0755]",
+	} {
+		dec, err := yaml.NewDecoderConfig("test.yaml", data, &yaml.Config{Strict: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = dec.Decode()
+		if err == nil || !strings.Contains(err.Error(), "ambiguous plain scalar") {
+			t.Errorf("%q: got error %v; want ambiguous plain scalar", data, err)
+		}
+	}
+	for _, data := range []string{
+		`a: "no"`,
+		"a: !!bool false",
+		"a: '1:30'",
+		"a: !!timestamp 2001-12-14",
+		"a: 0o755",
+		"a: 755",
+	} {
+		dec, err := yaml.NewDecoderConfig("test.yaml", data, &yaml.Config{Strict: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := dec.Decode(); err != nil {
+			t.Errorf("%q: unexpected error: %v", data, err)
+		}
+	}
+
+	// All ambiguous scalars are reported.
+	dec, err := yaml.NewDecoderConfig("test.yaml", "a: no\nb: [1:30, ok, 0755]\n", &yaml.Config{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = dec.Decode()
+	var terr *yaml.TypeError
+	if !errors.As(err, &terr) {
+		t.Fatalf("got error %v; want a TypeError", err)
+	}
+	want := []string{
+		"test.yaml:1: ambiguous plain scalar no: a boolean in YAML 1.1, a string in YAML 1.2; quote it or add an explicit tag",
+		"test.yaml:2: ambiguous plain scalar 1:30: a base 60 number in YAML 1.1, a string in YAML 1.2; quote it or add an explicit tag",
+		"test.yaml:2: ambiguous plain scalar 0755: an octal integer in YAML 1.1, a decimal integer in YAML 1.2; quote it or add an explicit tag",
+	}
+	if !reflect.DeepEqual(terr.Errors, want) {
+		t.Errorf("got errors\n%s\nwant\n%s", strings.Join(terr.Errors, "\n"), strings.Join(want, "\n"))
+	}
+}
+
 func TestFiles(t *testing.T) {
 	files := []string{"merge"}
 	for _, test := range files {
@@ -25,6 +25,7 @@ func Unmarshal(filename string, in []byte) (expr ast.Expr, err error) {
 
 // A Decoder reads and decodes YAML values from an input stream.
 type Decoder struct {
+	cfg       *Config
 	strict    bool
 	firstDone bool
 	parser    *parser
@@ -42,11 +43,22 @@ func NewDecoder(filename string, src interface{}) (*Decoder, error) {
 	return &Decoder{parser: d}, nil
 }
 
+// NewDecoderConfig is like NewDecoder, but decodes plain scalars as
+// configured by cfg, which may be nil.
+func NewDecoderConfig(filename string, src interface{}, cfg *Config) (*Decoder, error) {
+	d, err := NewDecoder(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	d.cfg = cfg
+	return d, nil
+}
+
 // Decode reads the next YAML-encoded value from its input and returns
 // it as CUE syntax. It returns io.EOF if there are no more value in the
 // stream.
 func (dec *Decoder) Decode() (expr ast.Expr, err error) {
-	d := newDecoder(dec.parser)
+	d := newDecoder(dec.parser, dec.cfg)
 	defer handleErr(&err)
 	node := dec.parser.parse()
 	if node == nil {
@@ -71,7 +83,7 @@ func unmarshal(filename string, in []byte) (expr ast.Expr, err error) {
 	}
 	defer p.destroy()
 	node := p.parse()
-	d := newDecoder(p)
+	d := newDecoder(p, nil)
 	if node != nil {
 		expr = d.unmarshal(node)
 	}
@@ -0,0 +1,148 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vcs provides access to information about the version control
+// system that a directory is managed by, so that it can be recorded
+// when a module is published.
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoVCS is returned by [Detect] when a directory is not inside
+// a supported version control system.
+var ErrNoVCS = errors.New("no VCS found")
+
+// VCS represents a version control system checkout.
+type VCS interface {
+	// Type returns the kind of the version control system, such as "git".
+	Type() string
+
+	// Root returns the root directory of the checkout.
+	Root() string
+
+	// Status returns the status of the checkout. Only changes to files
+	// within the given directories, which must be inside the checkout, are
+	// taken into account when determining whether the checkout is dirty.
+	// If no directories are given, the whole checkout is considered.
+	Status(ctx context.Context, dirs ...string) (Status, error)
+}
+
+// Status describes the state of a checkout.
+type Status struct {
+	// Revision holds the revision of the current commit.
+	Revision string
+
+	// CommitTime holds the time of the current commit.
+	CommitTime time.Time
+
+	// Uncommitted reports whether there are changes that have not been
+	// committed, including untracked files.
+	Uncommitted bool
+}
+
+// Detect returns the version control system that manages dir, searching
+// dir and its parent directories. It returns an error that satisfies
+// errors.Is(err, ErrNoVCS) if there is none.
+func Detect(dir string) (VCS, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for d := dir; ; {
+		// A .git entry may be a file when dir is inside a worktree
+		// or a submodule.
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return newGit(d)
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return nil, fmt.Errorf("%s: %w", dir, ErrNoVCS)
+		}
+		d = parent
+	}
+}
+
+type gitVCS struct {
+	root string
+}
+
+func newGit(root string) (VCS, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git repository found at %s but cannot run git: %v", root, err)
+	}
+	return gitVCS{root: root}, nil
+}
+
+func (v gitVCS) Type() string { return "git" }
+
+func (v gitVCS) Root() string { return v.root }
+
+func (v gitVCS) Status(ctx context.Context, dirs ...string) (Status, error) {
+	out, err := v.run(ctx, "log", "-1", "--format=%H:%ct")
+	if err != nil {
+		return Status{}, err
+	}
+	revision, ctime, ok := strings.Cut(strings.TrimSpace(out), ":")
+	if !ok {
+		return Status{}, fmt.Errorf("unexpected output from git log: %q", out)
+	}
+	secs, err := strconv.ParseInt(ctime, 10, 64)
+	if err != nil {
+		return Status{}, fmt.Errorf("unexpected commit time from git log: %q", ctime)
+	}
+
+	args := []string{"status", "--porcelain", "--"}
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(v.root, dir)
+		if err != nil {
+			return Status{}, err
+		}
+		args = append(args, filepath.ToSlash(rel))
+	}
+	out, err = v.run(ctx, args...)
+	if err != nil {
+		return Status{}, err
+	}
+	return Status{
+		Revision:    revision,
+		CommitTime:  time.Unix(secs, 0).UTC(),
+		Uncommitted: strings.TrimSpace(out) != "",
+	}, nil
+}
+
+func (v gitVCS) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = v.root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vcs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-quicktest/qt"
+)
+
+func TestGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	writeFile(t, filepath.Join(sub, "x.cue"), "x: 1\n")
+	writeFile(t, filepath.Join(dir, "other.cue"), "y: 1\n")
+
+	_, err := Detect(sub)
+	qt.Assert(t, qt.ErrorIs(err, ErrNoVCS))
+
+	commitTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	runGit(t, dir, commitTime, "init")
+	runGit(t, dir, commitTime, "add", ".")
+	runGit(t, dir, commitTime, "commit", "-m", "initial")
+
+	v, err := Detect(sub)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(v.Type(), "git"))
+	qt.Assert(t, qt.Equals(v.Root(), dir))
+
+	status, err := v.Status(ctx, sub)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.HasLen(status.Revision, 40))
+	qt.Assert(t, qt.Equals(status.CommitTime, commitTime))
+	qt.Assert(t, qt.IsFalse(status.Uncommitted))
+
+	// Changes outside the given directory are ignored.
+	writeFile(t, filepath.Join(dir, "other.cue"), "y: 2\n")
+	status, err = v.Status(ctx, sub)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsFalse(status.Uncommitted))
+
+	status, err = v.Status(ctx)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsTrue(status.Uncommitted))
+
+	// Untracked files make the checkout dirty.
+	writeFile(t, filepath.Join(sub, "new.cue"), "z: 1\n")
+	status, err = v.Status(ctx, sub)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.IsTrue(status.Uncommitted))
+}
+
+func writeFile(t *testing.T, name, content string) {
+	err := os.MkdirAll(filepath.Dir(name), 0o777)
+	qt.Assert(t, qt.IsNil(err))
+	err = os.WriteFile(name, []byte(content), 0o666)
+	qt.Assert(t, qt.IsNil(err))
+}
+
+func runGit(t *testing.T, dir string, commitTime time.Time, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	date := commitTime.Format(time.RFC3339)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_AUTHOR_NAME=cue",
+		"GIT_AUTHOR_EMAIL=cue@example.com",
+		"GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=cue",
+		"GIT_COMMITTER_EMAIL=cue@example.com",
+		"GIT_COMMITTER_DATE="+date,
+	)
+	out, err := cmd.CombinedOutput()
+	qt.Assert(t, qt.IsNil(err), qt.Commentf("git %v: %s", args, out))
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modregistrytest_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing/fstest"
+
+	"cuelabs.dev/go/oci/ociregistry"
+	"cuelabs.dev/go/oci/ociregistry/ociclient"
+
+	"cuelang.org/go/mod/modregistrytest"
+)
+
+func Example() {
+	// Each module lives in a directory named after its path and version.
+	fsys := fstest.MapFS{
+		"example.com_foo_v0.1.0/cue.mod/module.cue": {Data: []byte(`module: "example.com/foo@v0"`)},
+		"example.com_foo_v0.1.0/foo.cue":            {Data: []byte(`package foo` + "\n" + `x: 1`)},
+		"example.com_foo_v0.2.0/cue.mod/module.cue": {Data: []byte(`module: "example.com/foo@v0"`)},
+		"example.com_foo_v0.2.0/foo.cue":            {Data: []byte(`package foo` + "\n" + `x: 2`)},
+	}
+	r, err := modregistrytest.New(fsys, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Close()
+
+	// The registry can be used by setting CUE_REGISTRY to r.Host()+"+insecure",
+	// or with any OCI client.
+	client, err := ociclient.New(r.Host(), &ociclient.Options{
+		Insecure: true,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	tags, err := ociregistry.All(client.Tags(context.Background(), "example.com/foo"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(tags)
+	// Output:
+	// [v0.1.0 v0.2.0]
+}
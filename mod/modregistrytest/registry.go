@@ -1,4 +1,25 @@
-package registrytest
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modregistrytest provides an in-process fake CUE module registry
+// for use in tests.
+//
+// The registry serves the OCI distribution protocol over HTTP from memory,
+// so that module resolution can be exercised hermetically, without
+// running a separate registry such as zot. Its contents are read from an
+// [fs.FS], such as an [testing/fstest.MapFS] or a txtar archive.
+package modregistrytest
 
 import (
 	"bytes"
@@ -162,11 +183,13 @@ func visitDepthFirst(mods map[module.Version]*moduleContent, v module.Version, f
 	return f(v, m)
 }
 
+// Registry is a running fake registry, as started by [New].
 type Registry struct {
 	srv  *httptest.Server
 	host string
 }
 
+// Close shuts down the registry server.
 func (r *Registry) Close() {
 	r.srv.Close()
 }
@@ -0,0 +1,272 @@
+// Copyright 2024 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmp compares and hashes arbitrary concrete values.
+//
+// Unlike the comparison operators, which are only defined for scalars, the
+// functions in this package apply to any concrete value, including structs
+// and lists. Only the regular fields of structs are considered: definitions,
+// hidden fields and optional fields are ignored.
+//
+// The functions define a total order on values. Values of different kinds
+// are ordered as
+//
+//	null < bool < number < string < bytes < list < struct
+//
+// Numbers are ordered by their numeric value, so 1 and 1.0 are equal.
+// Strings and bytes are ordered lexicographically by byte. Lists are
+// ordered lexicographically by their elements. Structs are ordered by the
+// list of their fields sorted by label, where fields are compared first by
+// label and then by value.
+//
+// For instance, a list of structs can be sorted and deduplicated as
+//
+//	import (
+//		"cmp"
+//		"list"
+//	)
+//
+//	sorted: list.Sort(items, {x: _, y: _, less: cmp.Less(x, y)})
+//	unique: [for k, v in {for x in items {(cmp.Hash(x)): x}} {v}]
+package cmp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/apd/v3"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/value"
+)
+
+// Equal reports whether x and y are equal.
+func Equal(x, y cue.Value) (bool, error) {
+	c, err := Compare(x, y)
+	return c == 0, err
+}
+
+// Less reports whether x is ordered before y.
+func Less(x, y cue.Value) (bool, error) {
+	c, err := Compare(x, y)
+	return c < 0, err
+}
+
+// Compare returns -1 if x is ordered before y, 0 if x equals y, and +1 if
+// x is ordered after y.
+func Compare(x, y cue.Value) (int, error) {
+	if err := concrete(x); err != nil {
+		return 0, err
+	}
+	if err := concrete(y); err != nil {
+		return 0, err
+	}
+	return compare(x, y), nil
+}
+
+// Hash returns the SHA-256 hash, in hexadecimal, of a canonical encoding of
+// x. Values that are equal have the same hash.
+func Hash(x cue.Value) (string, error) {
+	if err := concrete(x); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	writeHash(h, x)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// concrete reports an error if v is not concrete. The error is incomplete
+// if v has no other errors, so that a call is retried once more is known
+// about v.
+func concrete(v cue.Value) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	if v.Validate(cue.Concrete(true)) != nil {
+		return internal.ErrIncomplete
+	}
+	return nil
+}
+
+// rank reports the position of the kind of v in the order of kinds.
+func rank(v cue.Value) int {
+	switch v.Kind() {
+	case cue.NullKind:
+		return 0
+	case cue.BoolKind:
+		return 1
+	case cue.IntKind, cue.FloatKind, cue.NumberKind:
+		return 2
+	case cue.StringKind:
+		return 3
+	case cue.BytesKind:
+		return 4
+	case cue.ListKind:
+		return 5
+	default:
+		return 6
+	}
+}
+
+func compare(x, y cue.Value) int {
+	if rx, ry := rank(x), rank(y); rx != ry {
+		return cmpInt(rx, ry)
+	}
+	switch rank(x) {
+	case 1:
+		a, _ := x.Bool()
+		b, _ := y.Bool()
+		switch {
+		case a == b:
+			return 0
+		case b:
+			return -1
+		}
+		return 1
+	case 2:
+		return num(x).Cmp(num(y))
+	case 3:
+		a, _ := x.String()
+		b, _ := y.String()
+		return strings.Compare(a, b)
+	case 4:
+		a, _ := x.Bytes()
+		b, _ := y.Bytes()
+		return bytes.Compare(a, b)
+	case 5:
+		a, b := elems(x), elems(y)
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if c := compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return cmpInt(len(a), len(b))
+	case 6:
+		a, b := fields(x), fields(y)
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if c := strings.Compare(a[i].label, b[i].label); c != 0 {
+				return c
+			}
+			if c := compare(a[i].value, b[i].value); c != 0 {
+				return c
+			}
+		}
+		return cmpInt(len(a), len(b))
+	}
+	return 0
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// num returns the numeric value of v.
+func num(v cue.Value) *apd.Decimal {
+	_, x := value.ToInternal(v)
+	if n, ok := x.Value().(*adt.Num); ok {
+		return &n.X
+	}
+	return &apd.Decimal{}
+}
+
+func elems(v cue.Value) []cue.Value {
+	var a []cue.Value
+	iter, _ := v.List()
+	for iter.Next() {
+		a = append(a, iter.Value())
+	}
+	return a
+}
+
+type field struct {
+	label string
+	value cue.Value
+}
+
+// fields returns the regular fields of v sorted by label.
+func fields(v cue.Value) []field {
+	var a []field
+	iter, _ := v.Fields()
+	for iter.Next() {
+		a = append(a, field{iter.Selector().Unquoted(), iter.Value()})
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i].label < a[j].label })
+	return a
+}
+
+// writeHash writes the canonical encoding of v to h. Each value is written
+// as a byte identifying its kind, followed by its contents, where strings
+// and sequences are prefixed by their length.
+func writeHash(h hash.Hash, v cue.Value) {
+	r := rank(v)
+	h.Write([]byte{byte(r)})
+	writeString := func(s string) {
+		writeLen(h, len(s))
+		h.Write([]byte(s))
+	}
+	switch r {
+	case 1:
+		b, _ := v.Bool()
+		if b {
+			h.Write([]byte{1})
+		} else {
+			h.Write([]byte{0})
+		}
+	case 2:
+		// Reduce the number so that equal numbers have the same encoding.
+		var d apd.Decimal
+		d.Reduce(num(v))
+		if d.IsZero() {
+			d.Negative = false
+		}
+		writeString(d.Text('e'))
+	case 3:
+		s, _ := v.String()
+		writeString(s)
+	case 4:
+		b, _ := v.Bytes()
+		writeString(string(b))
+	case 5:
+		a := elems(v)
+		writeLen(h, len(a))
+		for _, e := range a {
+			writeHash(h, e)
+		}
+	case 6:
+		a := fields(v)
+		writeLen(h, len(a))
+		for _, f := range a {
+			writeString(f.label)
+			writeHash(h, f.value)
+		}
+	}
+}
+
+func writeLen(h hash.Hash, n int) {
+	var buf [binary.MaxVarintLen64]byte
+	h.Write(buf[:binary.PutUvarint(buf[:], uint64(n))])
+}
@@ -16,6 +16,7 @@ package list
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/cockroachdb/apd/v3"
 
@@ -60,6 +61,84 @@ func Max(xs []*internal.Decimal) (*internal.Decimal, error) {
 	return max, nil
 }
 
+// Median returns the median of a non empty list xs. If xs has an even number
+// of elements, it returns the average of the two middle values.
+func Median(xs []*internal.Decimal) (*internal.Decimal, error) {
+	return percentile(xs, apd.New(50, 0))
+}
+
+// Percentile returns the p-th percentile of a non empty list xs, where p is
+// between 0 and 100 inclusive. Values between two elements are interpolated
+// linearly, so that Percentile(xs, 50) equals Median(xs).
+//
+// For instance:
+//
+//	Percentile([1, 2, 3, 4, 5], 90)
+//
+// results in
+//
+//	4.6
+func Percentile(xs []*internal.Decimal, p *internal.Decimal) (*internal.Decimal, error) {
+	if p.Negative && !p.IsZero() || p.Cmp(apd.New(100, 0)) > 0 {
+		return nil, fmt.Errorf("percentile %s not between 0 and 100", p)
+	}
+	return percentile(xs, p)
+}
+
+func percentile(xs []*internal.Decimal, p *internal.Decimal) (*internal.Decimal, error) {
+	if 0 == len(xs) {
+		return nil, fmt.Errorf("empty list")
+	}
+
+	a := make([]*internal.Decimal, len(xs))
+	copy(a, xs)
+	sort.SliceStable(a, func(i, j int) bool { return a[i].Cmp(a[j]) < 0 })
+
+	// The rank of p is p/100 * (len(xs)-1), split into an integral index and
+	// a fraction used for interpolating with the next element.
+	var rank, index, frac apd.Decimal
+	ctx := internal.BaseContext
+	if _, err := ctx.Mul(&rank, p, apd.New(int64(len(a)-1), 0)); err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Quo(&rank, &rank, apd.New(100, 0)); err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Floor(&index, &rank); err != nil {
+		return nil, err
+	}
+	i, err := index.Int64()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Sub(&frac, &rank, &index); err != nil {
+		return nil, err
+	}
+	if frac.IsZero() {
+		return a[i], nil
+	}
+
+	var d apd.Decimal
+	if _, err := ctx.Sub(&d, a[i+1], a[i]); err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Mul(&d, &d, &frac); err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Add(&d, &d, a[i]); err != nil {
+		return nil, err
+	}
+	// Drop trailing zeros introduced by the interpolation, but keep the
+	// result in plain notation.
+	d.Reduce(&d)
+	if d.Exponent > 0 {
+		if _, err := ctx.Quantize(&d, &d, 0); err != nil {
+			return nil, err
+		}
+	}
+	return &d, nil
+}
+
 // Min returns the minimum value of a non empty list xs.
 func Min(xs []*internal.Decimal) (*internal.Decimal, error) {
 	if 0 == len(xs) {
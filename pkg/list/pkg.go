@@ -167,6 +167,31 @@ var p = &pkg.Package{
 				c.Ret, c.Err = Max(xs)
 			}
 		},
+	}, {
+		Name: "Median",
+		Params: []pkg.Param{
+			{Kind: adt.ListKind},
+		},
+		Result: adt.NumKind,
+		Func: func(c *pkg.CallCtxt) {
+			xs := c.DecimalList(0)
+			if c.Do() {
+				c.Ret, c.Err = Median(xs)
+			}
+		},
+	}, {
+		Name: "Percentile",
+		Params: []pkg.Param{
+			{Kind: adt.ListKind},
+			{Kind: adt.NumKind},
+		},
+		Result: adt.NumKind,
+		Func: func(c *pkg.CallCtxt) {
+			xs, p := c.DecimalList(0), c.Decimal(1)
+			if c.Do() {
+				c.Ret, c.Err = Percentile(xs, p)
+			}
+		},
 	}, {
 		Name: "Min",
 		Params: []pkg.Param{
@@ -15,6 +15,7 @@
 package math
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/cockroachdb/apd/v3"
@@ -114,6 +115,100 @@ func RoundToEven(x *internal.Decimal) (*big.Int, error) {
 	return toInt(&d), err
 }
 
+var (
+	roundFloorContext   = roundContext(apd.RoundFloor)
+	roundCeilingContext = roundContext(apd.RoundCeiling)
+)
+
+// roundPlaces rounds x to n decimal places using the rounding mode of c.
+// A negative n rounds to a multiple of a power of ten, resulting in an
+// integer.
+func roundPlaces(c internal.Context, x *internal.Decimal, n int) (*internal.Decimal, error) {
+	if n > -apd.MinExponent || n < -apd.MaxExponent {
+		return nil, fmt.Errorf("number of decimal places %d out of range", n)
+	}
+	var d internal.Decimal
+	_, err := c.Quantize(&d, x, int32(-n))
+	if err == nil && n < 0 {
+		_, err = c.Quantize(&d, &d, 0)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot represent %s with %d decimal places", x, n)
+	}
+	return &d, nil
+}
+
+// RoundPlaces returns x rounded to n decimal places, rounding half away from
+// zero. A negative n rounds to the left of the decimal point.
+//
+// For instance:
+//
+//	RoundPlaces(2.345, 2)  // 2.35
+//	RoundPlaces(-2.345, 2) // -2.35
+//	RoundPlaces(1250, -2)  // 1300
+func RoundPlaces(x *internal.Decimal, n int) (*internal.Decimal, error) {
+	return roundPlaces(roundUpContext, x, n)
+}
+
+// RoundToEvenPlaces returns x rounded to n decimal places, rounding ties to
+// even. A negative n rounds to the left of the decimal point.
+//
+// For instance:
+//
+//	RoundToEvenPlaces(2.345, 2) // 2.34
+//	RoundToEvenPlaces(2.355, 2) // 2.36
+//	RoundToEvenPlaces(1250, -2) // 1200
+func RoundToEvenPlaces(x *internal.Decimal, n int) (*internal.Decimal, error) {
+	return roundPlaces(roundEvenContext, x, n)
+}
+
+// FloorPlaces returns the greatest value less than or equal to x with n
+// decimal places. A negative n rounds to the left of the decimal point.
+//
+// For instance:
+//
+//	FloorPlaces(2.349, 2)  // 2.34
+//	FloorPlaces(-2.341, 2) // -2.35
+func FloorPlaces(x *internal.Decimal, n int) (*internal.Decimal, error) {
+	return roundPlaces(roundFloorContext, x, n)
+}
+
+// CeilPlaces returns the least value greater than or equal to x with n
+// decimal places. A negative n rounds to the left of the decimal point.
+//
+// For instance:
+//
+//	CeilPlaces(2.341, 2)  // 2.35
+//	CeilPlaces(-2.349, 2) // -2.34
+func CeilPlaces(x *internal.Decimal, n int) (*internal.Decimal, error) {
+	return roundPlaces(roundCeilingContext, x, n)
+}
+
+// FormatFixed returns x formatted with exactly n decimal places, rounding
+// half away from zero, as is common for amounts of money. The result has no
+// exponent and a zero result is never negative. The number of decimal places
+// must not be negative.
+//
+// For instance:
+//
+//	FormatFixed(1234.5, 2) // "1234.50"
+//	FormatFixed(0.125, 2)  // "0.13"
+//	FormatFixed(-0.001, 2) // "0.00"
+//	FormatFixed(1e3, 0)    // "1000"
+func FormatFixed(x *internal.Decimal, n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("number of decimal places must not be negative, found %d", n)
+	}
+	d, err := roundPlaces(roundUpContext, x, n)
+	if err != nil {
+		return "", err
+	}
+	if d.IsZero() {
+		d.Negative = false
+	}
+	return d.Text('f'), nil
+}
+
 var mulContext = internal.BaseContext.WithPrecision(1)
 
 // MultipleOf reports whether x is a multiple of y.
@@ -126,6 +126,71 @@ var p = &pkg.Package{
 				c.Ret, c.Err = RoundToEven(x)
 			}
 		},
+	}, {
+		Name: "RoundPlaces",
+		Params: []pkg.Param{
+			{Kind: adt.NumKind},
+			{Kind: adt.IntKind},
+		},
+		Result: adt.NumKind,
+		Func: func(c *pkg.CallCtxt) {
+			x, n := c.Decimal(0), c.Int(1)
+			if c.Do() {
+				c.Ret, c.Err = RoundPlaces(x, n)
+			}
+		},
+	}, {
+		Name: "RoundToEvenPlaces",
+		Params: []pkg.Param{
+			{Kind: adt.NumKind},
+			{Kind: adt.IntKind},
+		},
+		Result: adt.NumKind,
+		Func: func(c *pkg.CallCtxt) {
+			x, n := c.Decimal(0), c.Int(1)
+			if c.Do() {
+				c.Ret, c.Err = RoundToEvenPlaces(x, n)
+			}
+		},
+	}, {
+		Name: "FloorPlaces",
+		Params: []pkg.Param{
+			{Kind: adt.NumKind},
+			{Kind: adt.IntKind},
+		},
+		Result: adt.NumKind,
+		Func: func(c *pkg.CallCtxt) {
+			x, n := c.Decimal(0), c.Int(1)
+			if c.Do() {
+				c.Ret, c.Err = FloorPlaces(x, n)
+			}
+		},
+	}, {
+		Name: "CeilPlaces",
+		Params: []pkg.Param{
+			{Kind: adt.NumKind},
+			{Kind: adt.IntKind},
+		},
+		Result: adt.NumKind,
+		Func: func(c *pkg.CallCtxt) {
+			x, n := c.Decimal(0), c.Int(1)
+			if c.Do() {
+				c.Ret, c.Err = CeilPlaces(x, n)
+			}
+		},
+	}, {
+		Name: "FormatFixed",
+		Params: []pkg.Param{
+			{Kind: adt.NumKind},
+			{Kind: adt.IntKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			x, n := c.Decimal(0), c.Int(1)
+			if c.Do() {
+				c.Ret, c.Err = FormatFixed(x, n)
+			}
+		},
 	}, {
 		Name: "MultipleOf",
 		Params: []pkg.Param{
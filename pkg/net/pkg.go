@@ -285,5 +285,53 @@ var p = &pkg.Package{
 				c.Ret, c.Err = QueryUnescape(s)
 			}
 		},
+	}, {
+		Name: "URI",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.BoolKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret, c.Err = URI(s)
+			}
+		},
+	}, {
+		Name: "IRI",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.BoolKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret, c.Err = IRI(s)
+			}
+		},
+	}, {
+		Name: "ParseURL",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.TopKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret, c.Err = ParseURL(s)
+			}
+		},
+	}, {
+		Name: "FormatURL",
+		Params: []pkg.Param{
+			{Kind: adt.TopKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			parts := c.Value(0)
+			if c.Do() {
+				c.Ret, c.Err = FormatURL(parts)
+			}
+		},
 	}},
 }
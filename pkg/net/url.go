@@ -15,7 +15,15 @@
 package net
 
 import (
+	"fmt"
+	"net"
 	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue"
 )
 
 // PathEscape escapes the string so it can be safely placed inside a URL path
@@ -48,3 +56,261 @@ func QueryEscape(s string) string {
 func QueryUnescape(s string) (string, error) {
 	return url.QueryUnescape(s)
 }
+
+// URI reports whether s is an absolute URI as defined by RFC 3986, that is,
+// a URI with a scheme.
+func URI(s string) (bool, error) {
+	return checkURI(s, false)
+}
+
+// IRI reports whether s is an absolute internationalized resource identifier
+// as defined by RFC 3987. An IRI is like a URI, but may also contain
+// non-ASCII characters, such as in "https://例え.jp/パス".
+func IRI(s string) (bool, error) {
+	return checkURI(s, true)
+}
+
+func checkURI(s string, iri bool) (bool, error) {
+	kind := "URI"
+	if iri {
+		kind = "IRI"
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return false, fmt.Errorf("invalid %s %q: invalid escape %q", kind, s, s[i:min(i+3, len(s))])
+			}
+		case r < utf8.RuneSelf:
+			if !isURIChar(byte(r)) {
+				return false, fmt.Errorf("invalid %s %q: invalid character %q", kind, s, r)
+			}
+		case !iri || unicode.IsSpace(r) || unicode.IsControl(r) || r == utf8.RuneError:
+			return false, fmt.Errorf("invalid %s %q: invalid character %q", kind, s, r)
+		}
+		i += size
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %v", kind, s, urlError(err))
+	}
+	if u.Scheme == "" {
+		return false, fmt.Errorf("invalid %s %q: missing scheme", kind, s)
+	}
+	return true, nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// isURIChar reports whether c may appear unescaped in a URI: it is either
+// an unreserved or a reserved character.
+func isURIChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-._~:/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+// urlError returns the underlying error of a url.Error, which repeats the
+// URL.
+func urlError(err error) error {
+	if uerr, ok := err.(*url.Error); ok {
+		return uerr.Err
+	}
+	return err
+}
+
+// URLParts holds the components of a URL.
+type URLParts struct {
+	Scheme string `json:"scheme,omitempty"`
+
+	// Opaque holds the part after the scheme of URLs that do not start with
+	// a slash after the scheme, such as "mailto:user@example.com".
+	Opaque string `json:"opaque,omitempty"`
+
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Host holds the host name or IP address. IPv6 addresses are not
+	// enclosed in square brackets.
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+
+	// Path holds the unescaped path.
+	Path string `json:"path,omitempty"`
+
+	// Query holds the values of each query parameter.
+	Query map[string][]string `json:"query,omitempty"`
+
+	Fragment string `json:"fragment,omitempty"`
+}
+
+// ParseURL parses a URL into its components. The URL may be relative.
+// Components that are absent or empty are omitted from the result.
+//
+// For instance:
+//
+//	ParseURL("https://example.com:8443/api?v=1&tag=a&tag=b")
+//
+// results in
+//
+//	{
+//		scheme: "https"
+//		host:   "example.com"
+//		port:   8443
+//		path:   "/api"
+//		query: {
+//			tag: ["a", "b"]
+//			v: ["1"]
+//		}
+//	}
+func ParseURL(s string) (*URLParts, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %v", s, urlError(err))
+	}
+	p := &URLParts{
+		Scheme:   u.Scheme,
+		Opaque:   u.Opaque,
+		Host:     u.Hostname(),
+		Path:     u.Path,
+		Fragment: u.Fragment,
+	}
+	if u.User != nil {
+		p.User = u.User.Username()
+		p.Password, _ = u.User.Password()
+	}
+	if port := u.Port(); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil || n > 65535 {
+			return nil, fmt.Errorf("invalid URL %q: invalid port %q", s, port)
+		}
+		p.Port = n
+	}
+	if u.RawQuery != "" {
+		q, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %q: %v", s, err)
+		}
+		p.Query = q
+	}
+	return p, nil
+}
+
+// FormatURL returns the URL with the given components, which are defined as
+// for the result of ParseURL. All components are optional. The port may be
+// given as an integer or a string, and the value of a query parameter as a
+// string or a list of strings. Query parameters are sorted by name.
+//
+// For instance:
+//
+//	FormatURL({
+//		scheme: "https"
+//		host:   "example.com"
+//		path:   "/search"
+//		query: q: "cue lang"
+//	})
+//
+// results in
+//
+//	"https://example.com/search?q=cue+lang"
+func FormatURL(parts cue.Value) (string, error) {
+	iter, err := parts.Fields()
+	if err != nil {
+		return "", err
+	}
+	var u url.URL
+	var user, password, host, port string
+	var hasUser, hasPassword bool
+	for iter.Next() {
+		v := iter.Value()
+		name := iter.Selector().Unquoted()
+		switch name {
+		case "port":
+			if v.Kind() == cue.IntKind {
+				var n int64
+				n, err = v.Int64()
+				port = strconv.FormatInt(n, 10)
+			} else {
+				port, err = v.String()
+			}
+		case "query":
+			u.RawQuery, err = formatQuery(v)
+		default:
+			var str string
+			str, err = v.String()
+			switch name {
+			case "scheme":
+				u.Scheme = str
+			case "opaque":
+				u.Opaque = str
+			case "user":
+				user, hasUser = str, true
+			case "password":
+				password, hasPassword = str, true
+			case "host":
+				host = str
+			case "path":
+				u.Path = str
+			case "fragment":
+				u.Fragment = str
+			default:
+				return "", fmt.Errorf("unknown URL component %q", name)
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	switch {
+	case hasPassword:
+		u.User = url.UserPassword(user, password)
+	case hasUser:
+		u.User = url.User(user)
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+	return u.String(), nil
+}
+
+func formatQuery(v cue.Value) (string, error) {
+	iter, err := v.Fields()
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{}
+	for iter.Next() {
+		name := iter.Selector().Unquoted()
+		w := iter.Value()
+		if w.Kind() != cue.ListKind {
+			s, err := w.String()
+			if err != nil {
+				return "", err
+			}
+			q.Add(name, s)
+			continue
+		}
+		list, err := w.List()
+		if err != nil {
+			return "", err
+		}
+		for list.Next() {
+			s, err := list.Value().String()
+			if err != nil {
+				return "", err
+			}
+			q.Add(name, s)
+		}
+	}
+	return q.Encode(), nil
+}
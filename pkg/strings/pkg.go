@@ -455,5 +455,154 @@ var p = &pkg.Package{
 				c.Ret = Index(s, substr)
 			}
 		},
+	}, {
+		Name: "NFC",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret = NFC(s)
+			}
+		},
+	}, {
+		Name: "NFD",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret = NFD(s)
+			}
+		},
+	}, {
+		Name: "NFKC",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret = NFKC(s)
+			}
+		},
+	}, {
+		Name: "NFKD",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret = NFKD(s)
+			}
+		},
+	}, {
+		Name: "Fold",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret = Fold(s)
+			}
+		},
+	}, {
+		Name: "ToUpperLocale",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			s, locale := c.String(0), c.String(1)
+			if c.Do() {
+				c.Ret, c.Err = ToUpperLocale(s, locale)
+			}
+		},
+	}, {
+		Name: "ToLowerLocale",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			s, locale := c.String(0), c.String(1)
+			if c.Do() {
+				c.Ret, c.Err = ToLowerLocale(s, locale)
+			}
+		},
+	}, {
+		Name: "Width",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.IntKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret = Width(s)
+			}
+		},
+	}, {
+		Name: "TruncateWidth",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+			{Kind: adt.IntKind},
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			s, n, tail := c.String(0), c.Int(1), c.String(2)
+			if c.Do() {
+				c.Ret, c.Err = TruncateWidth(s, n, tail)
+			}
+		},
+	}, {
+		Name: "SingleScript",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.BoolKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret = SingleScript(s)
+			}
+		},
+	}, {
+		Name: "Skeleton",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret = Skeleton(s)
+			}
+		},
+	}, {
+		Name: "Confusable",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+			{Kind: adt.StringKind},
+		},
+		Result: adt.BoolKind,
+		Func: func(c *pkg.CallCtxt) {
+			s, t := c.String(0), c.String(1)
+			if c.Do() {
+				c.Ret = Confusable(s, t)
+			}
+		},
 	}},
 }
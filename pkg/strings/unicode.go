@@ -0,0 +1,301 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strings
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// NFC returns s in Unicode Normalization Form C, canonical composition.
+func NFC(s string) string {
+	return norm.NFC.String(s)
+}
+
+// NFD returns s in Unicode Normalization Form D, canonical decomposition.
+func NFD(s string) string {
+	return norm.NFD.String(s)
+}
+
+// NFKC returns s in Unicode Normalization Form KC, compatibility
+// composition. Unlike NFC, it maps characters such as ligatures and
+// full-width letters to their plain equivalents.
+func NFKC(s string) string {
+	return norm.NFKC.String(s)
+}
+
+// NFKD returns s in Unicode Normalization Form KD, compatibility
+// decomposition.
+func NFKD(s string) string {
+	return norm.NFKD.String(s)
+}
+
+// Fold returns s with Unicode full case folding applied. Two strings are
+// equal under case-insensitive comparison if their folded forms are equal.
+// Unlike ToLower, Fold maps for instance "ß" to "ss".
+func Fold(s string) string {
+	return cases.Fold().String(s)
+}
+
+func caser(locale string, f func(language.Tag, ...cases.Option) cases.Caser) (cases.Caser, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return cases.Caser{}, fmt.Errorf("invalid locale %q", locale)
+	}
+	return f(tag), nil
+}
+
+// ToUpperLocale returns s with all Unicode letters mapped to their upper
+// case using the rules of the given BCP 47 locale, such as "tr" or "de-CH".
+func ToUpperLocale(s, locale string) (string, error) {
+	c, err := caser(locale, cases.Upper)
+	if err != nil {
+		return "", err
+	}
+	return c.String(s), nil
+}
+
+// ToLowerLocale returns s with all Unicode letters mapped to their lower
+// case using the rules of the given BCP 47 locale, such as "tr" or "de-CH".
+func ToLowerLocale(s, locale string) (string, error) {
+	c, err := caser(locale, cases.Lower)
+	if err != nil {
+		return "", err
+	}
+	return c.String(s), nil
+}
+
+// runeWidth returns the number of columns r occupies in a monospaced
+// font: 0 for control characters and combining marks, 2 for wide East Asian
+// characters, and 1 otherwise.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.IsControl(r),
+		unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// Width returns the number of columns s occupies when displayed in a
+// monospaced font. Wide East Asian characters count as two columns,
+// combining marks and control characters as none.
+func Width(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// TruncateWidth returns s shortened to at most the given number of columns,
+// as computed by Width. If s is shortened, tail is appended to it, for
+// instance "…", and the width of tail is included in the limit. Characters
+// are not split from the combining marks following them.
+func TruncateWidth(s string, n int, tail string) (string, error) {
+	if Width(s) <= n {
+		return s, nil
+	}
+	n -= Width(tail)
+	if n < 0 {
+		return "", fmt.Errorf("tail %q is wider than %d columns", tail, n+Width(tail))
+	}
+	w := 0
+	for i, r := range s {
+		rw := runeWidth(r)
+		if rw > 0 && w+rw > n {
+			return s[:i] + tail, nil
+		}
+		w += rw
+	}
+	return s + tail, nil
+}
+
+// Script groups for which scripts commonly mixed in a single writing
+// system are considered to be the same, following the resolved script sets
+// of Unicode Technical Standard #39.
+const (
+	scriptJapanese = "Jpan"
+	scriptKorean   = "Kore"
+	scriptHanBopo  = "Hanb"
+)
+
+// scriptSet returns the scripts r may be written in, or nil if r is used in
+// any script, like digits and punctuation.
+func scriptSet(r rune) []string {
+	if unicode.In(r, unicode.Common, unicode.Inherited) {
+		return nil
+	}
+	switch {
+	case unicode.Is(unicode.Han, r):
+		return []string{scriptJapanese, scriptKorean, scriptHanBopo}
+	case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+		return []string{scriptJapanese}
+	case unicode.Is(unicode.Hangul, r):
+		return []string{scriptKorean}
+	case unicode.Is(unicode.Bopomofo, r):
+		return []string{scriptHanBopo}
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return []string{name}
+		}
+	}
+	return []string{"Zzzz"}
+}
+
+// SingleScript reports whether all letters of s are written in a single
+// script, like Latin, Cyrillic, or Greek. Characters that are shared by all
+// scripts, such as digits and punctuation, are ignored. The scripts commonly
+// used together to write Japanese, Korean, or Chinese count as a single
+// script. SingleScript can be used as a field constraint to reject
+// identifiers that mix scripts to spoof others, as in "pаypal" with a
+// Cyrillic "а".
+func SingleScript(s string) bool {
+	var set []string
+	for _, r := range s {
+		rs := scriptSet(r)
+		switch {
+		case rs == nil:
+			continue
+		case set == nil:
+			set = rs
+			continue
+		}
+		k := 0
+		for _, name := range set {
+			for _, x := range rs {
+				if name == x {
+					set[k] = name
+					k++
+					break
+				}
+			}
+		}
+		if k == 0 {
+			return false
+		}
+		set = set[:k:k]
+	}
+	return true
+}
+
+// confusables maps characters to the character sequences they are commonly
+// mistaken for. It is a small subset of the confusables defined by Unicode
+// Technical Standard #39, focused on characters that look like ASCII.
+var confusables = map[rune]string{
+	'0': "O",
+	'1': "l",
+	'I': "l",
+	'|': "l",
+	'm': "rn",
+
+	// Latin
+	'ı': "i",
+	'ǀ': "l",
+	'ɑ': "a",
+	'ɡ': "g",
+
+	// Greek
+	'Α': "A",
+	'Β': "B",
+	'Ε': "E",
+	'Ζ': "Z",
+	'Η': "H",
+	'Ι': "l",
+	'Κ': "K",
+	'Μ': "M",
+	'Ν': "N",
+	'Ο': "O",
+	'Ρ': "P",
+	'Τ': "T",
+	'Υ': "Y",
+	'Χ': "X",
+	'α': "a",
+	'ι': "i",
+	'ν': "v",
+	'ο': "o",
+	'ρ': "p",
+
+	// Cyrillic
+	'Ѕ': "S",
+	'І': "l",
+	'Ј': "J",
+	'А': "A",
+	'В': "B",
+	'Е': "E",
+	'К': "K",
+	'М': "M",
+	'Н': "H",
+	'О': "O",
+	'Р': "P",
+	'С': "C",
+	'Т': "T",
+	'У': "Y",
+	'Х': "X",
+	'а': "a",
+	'е': "e",
+	'о': "o",
+	'р': "p",
+	'с': "c",
+	'у': "y",
+	'х': "x",
+	'ѕ': "s",
+	'і': "i",
+	'ј': "j",
+	'һ': "h",
+	'ү': "y",
+	'ӏ': "l",
+	'ԁ': "d",
+	'ԛ': "q",
+	'ԝ': "w",
+}
+
+// Skeleton returns a form of s in which characters that look alike are
+// mapped to the same characters, similar to the skeleton defined by Unicode
+// Technical Standard #39. Two strings that have the same skeleton are likely
+// to be mistaken for each other. The skeleton is intended for comparison
+// only and should not be displayed.
+//
+// The result is in normalization form NFKD and is case sensitive. Only a
+// subset of the confusable characters of the standard is recognized, most
+// notably Cyrillic and Greek letters that look like Latin letters.
+func Skeleton(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(s) {
+		if x, ok := confusables[r]; ok {
+			b.WriteString(x)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFD.String(b.String())
+}
+
+// Confusable reports whether s and t are likely to be mistaken for each
+// other, that is, whether they have the same Skeleton.
+func Confusable(s, t string) bool {
+	return Skeleton(s) == Skeleton(t)
+}
@@ -0,0 +1,267 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package time
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A cronField describes the range and names of the values of a field of a
+// cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names for the values starting at min
+}
+
+var cronFields = [...]cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{
+		"jan", "feb", "mar", "apr", "may", "jun",
+		"jul", "aug", "sep", "oct", "nov", "dec",
+	}},
+	// Both 0 and 7 denote Sunday.
+	{name: "day of week", min: 0, max: 7, names: []string{
+		"sun", "mon", "tue", "wed", "thu", "fri", "sat",
+	}},
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// A schedule is a parsed cron expression. Each field holds a bit for every
+// value it matches.
+type schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar report whether the day fields start with a '*'.
+	// If neither does, a day matches if either of the fields matches.
+	domStar, dowStar bool
+}
+
+// daysInMonth holds the maximum number of days of each month.
+var daysInMonth = [...]int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+func parseCron(s string) (*schedule, error) {
+	expr := strings.TrimSpace(s)
+	if strings.HasPrefix(expr, "@") {
+		m, ok := cronMacros[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("invalid cron expression %q: unknown macro", s)
+		}
+		expr = m
+	}
+	f := strings.Fields(expr)
+	if len(f) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected %d fields, found %d", s, len(cronFields), len(f))
+	}
+	var bits [len(cronFields)]uint64
+	for i, field := range f {
+		b, err := cronFields[i].parse(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %v", s, err)
+		}
+		bits[i] = b
+	}
+	sc := &schedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(f[2], "*"),
+		dowStar: strings.HasPrefix(f[4], "*"),
+	}
+	if sc.dow&(1<<7) != 0 {
+		sc.dow |= 1
+	}
+	if sc.dowStar && !sc.domStar && !sc.possible() {
+		return nil, fmt.Errorf("invalid cron expression %q: day of month never occurs in the given months", s)
+	}
+	return sc, nil
+}
+
+// possible reports whether the days of the month of sc occur in any of
+// its months.
+func (sc *schedule) possible() bool {
+	for m := 1; m <= 12; m++ {
+		if sc.month&(1<<m) != 0 && sc.dom&(1<<(daysInMonth[m]+1)-1) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// parse parses a comma-separated list of values, ranges, and steps.
+func (c *cronField) parse(s string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		lo, hi := c.min, c.max
+		switch {
+		case rng == "*":
+		default:
+			first, last, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = c.value(first); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = c.value(last); err != nil {
+					return 0, err
+				}
+			case !hasStep:
+				hi = lo
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid %s range %q", c.name, rng)
+			}
+		}
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", c.name, stepStr)
+			}
+			step = n
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (c *cronField) value(s string) (int, error) {
+	for i, name := range c.names {
+		if strings.EqualFold(s, name) {
+			return c.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < c.min || v > c.max {
+		return 0, fmt.Errorf("invalid %s %q: must be between %d and %d", c.name, s, c.min, c.max)
+	}
+	return v, nil
+}
+
+func (sc *schedule) matchDay(t time.Time) bool {
+	dom := sc.dom&(1<<t.Day()) != 0
+	dow := sc.dow&(1<<int(t.Weekday())) != 0
+	switch {
+	case sc.domStar || sc.dowStar:
+		return dom && dow
+	default:
+		return dom || dow
+	}
+}
+
+// cronSearchYears limits the search for the next occurrence of a schedule.
+// Any schedule that occurs at all does so at least every eight years.
+const cronSearchYears = 9
+
+// next returns the first time after t matched by sc.
+func (sc *schedule) next(t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + cronSearchYears
+	for t.Year() <= limit {
+		switch {
+		case sc.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !sc.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case sc.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case sc.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Cron validates a cron expression.
+//
+// A cron expression consists of five fields separated by white space:
+//
+//	minute        0-59
+//	hour          0-23
+//	day of month  1-31
+//	month         1-12 or JAN-DEC
+//	day of week   0-7 or SUN-SAT, where both 0 and 7 are Sunday
+//
+// Each field is a comma-separated list of values, ranges such as 1-5,
+// or *, each of which may be followed by a step such as */15 or 10-50/20.
+// A value followed by a step, such as 5/15, stands for the range from that
+// value to the maximum. Names are not case sensitive.
+//
+// If neither the day of month nor the day of week field starts with *, a
+// day matches if either field matches. Otherwise both must match.
+//
+// The macros @yearly (or @annually), @monthly, @weekly, @daily (or
+// @midnight) and @hourly may be used instead of the five fields.
+//
+// Expressions that can never occur, such as "0 0 30 2 *", are rejected.
+func Cron(s string) (bool, error) {
+	if _, err := parseCron(s); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CronNext returns the next n times matched by the cron expression expr
+// after the time t, formatted as RFC3339 date-times.
+//
+// The times are computed in the time zone offset of t, which also applies
+// to the results.
+//
+// See Cron for the syntax of cron expressions.
+func CronNext(expr, t string, n int) ([]string, error) {
+	sc, err := parseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("number of times must not be negative, found %d", n)
+	}
+	tm, err := time.Parse(time.RFC3339Nano, t)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q", t)
+	}
+	a := []string{}
+	for i := 0; i < n; i++ {
+		var ok bool
+		if tm, ok = sc.next(tm); !ok {
+			return nil, fmt.Errorf("cron expression %q does not occur after %s", expr, t)
+		}
+		a = append(a, tm.Format(time.RFC3339))
+	}
+	return a, nil
+}
@@ -15,6 +15,32 @@ var _ = adt.TopKind // in case the adt package isn't used
 
 var p = &pkg.Package{
 	Native: []*pkg.Builtin{{
+		Name: "Cron",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.BoolKind,
+		Func: func(c *pkg.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret, c.Err = Cron(s)
+			}
+		},
+	}, {
+		Name: "CronNext",
+		Params: []pkg.Param{
+			{Kind: adt.StringKind},
+			{Kind: adt.StringKind},
+			{Kind: adt.IntKind},
+		},
+		Result: adt.ListKind,
+		Func: func(c *pkg.CallCtxt) {
+			expr, t, n := c.String(0), c.String(1), c.Int(2)
+			if c.Do() {
+				c.Ret, c.Err = CronNext(expr, t, n)
+			}
+		},
+	}, {
 		Name:  "Nanosecond",
 		Const: "1",
 	}, {
@@ -0,0 +1,111 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+// Registry describes how to connect to a schema registry that implements
+// the API of the Confluent Schema Registry.
+Registry: {
+	// url is the base URL of the registry.
+	url: string
+
+	// username and password are used for basic authentication, such as
+	// with the API key and secret of a hosted registry.
+	username?: string
+	password?: string
+}
+
+// Schema describes a schema generated from a CUE definition.
+Schema: {
+	registry: Registry
+
+	// subject is the subject of the schema, such as "orders-value" for the
+	// values of the messages of the orders topic.
+	subject: string
+
+	// schema is the CUE definition from which the schema is generated.
+	schema: _
+
+	// format is the format of the generated schema.
+	format: *"avro" | "json"
+
+	// name is the name of the record of an Avro schema. It is required
+	// for Avro, as the name of the definition of schema is not known.
+	name?: string
+}
+
+// RegisterSchema registers a schema under a subject, creating a new
+// version of the subject unless it already has one with the same schema.
+//
+// Example:
+//     task: publish: kafka.RegisterSchema & {
+//         registry: url: "http://localhost:8081"
+//         subject: "orders-value"
+//         schema:  #Order
+//     }
+RegisterSchema: {
+	$id: "tool/kafka.RegisterSchema"
+
+	Schema
+
+	// id is the ID of the registered schema, which identifies it across
+	// subjects.
+	id: int
+
+	// version is the version of the subject with the schema.
+	version: int
+}
+
+// CheckSchema checks whether a schema is compatible with the latest
+// version of a subject, according to the compatibility level configured
+// for the subject in the registry. A schema is compatible with a subject
+// that does not exist yet.
+CheckSchema: {
+	$id: "tool/kafka.CheckSchema"
+
+	Schema
+
+	// compatible reports whether the schema is compatible.
+	compatible: bool
+
+	// messages explains why the schema is not compatible.
+	messages: [...string]
+
+	// changed reports whether the schema differs from the latest version
+	// of the subject.
+	changed: bool
+
+	// mustBeCompatible indicates whether the task fails if the schema is
+	// not compatible.
+	mustBeCompatible: bool | *true
+}
+
+// LatestSchema retrieves the latest version of a subject.
+LatestSchema: {
+	$id: "tool/kafka.LatestSchema"
+
+	registry: Registry
+	subject:  string
+
+	// found reports whether the subject exists. If it does not, the
+	// fields describing the latest version are not set.
+	found: bool
+
+	id?:      int
+	version?: int
+	format?:  "avro" | "json"
+
+	// text holds the schema as JSON.
+	text?: string
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"errors"
+	"strings"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/schemaregistry"
+	"cuelang.org/go/internal/task"
+)
+
+func init() {
+	task.Register("tool/kafka.RegisterSchema", newRegisterCmd)
+	task.Register("tool/kafka.CheckSchema", newCheckCmd)
+	task.Register("tool/kafka.LatestSchema", newLatestCmd)
+}
+
+func newRegisterCmd(v cue.Value) (task.Runner, error) { return &cmdRegister{}, nil }
+func newCheckCmd(v cue.Value) (task.Runner, error)    { return &cmdCheck{}, nil }
+func newLatestCmd(v cue.Value) (task.Runner, error)   { return &cmdLatest{}, nil }
+
+type cmdRegister struct{}
+type cmdCheck struct{}
+type cmdLatest struct{}
+
+func (c *cmdRegister) Run(ctx *task.Context) (res interface{}, err error) {
+	client, subject := newClient(ctx)
+	s := generate(ctx)
+	if ctx.Err != nil {
+		return nil, ctx.Err
+	}
+	v, err := client.Register(ctx.Context, subject, s)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"id":      v.ID,
+		"version": v.Version,
+	}, nil
+}
+
+func (c *cmdCheck) Run(ctx *task.Context) (res interface{}, err error) {
+	client, subject := newClient(ctx)
+	s := generate(ctx)
+	mustBeCompatible, err := ctx.Lookup("mustBeCompatible").Bool()
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err != nil {
+		return nil, ctx.Err
+	}
+	compat, err := client.CheckCompatibility(ctx.Context, subject, s)
+	if err != nil {
+		return nil, err
+	}
+	if !compat.Compatible && mustBeCompatible {
+		msg := "schema is not compatible with the latest version of subject " + subject
+		if len(compat.Messages) > 0 {
+			msg += ": " + strings.Join(compat.Messages, "; ")
+		}
+		return nil, cueerrors.Newf(ctx.Obj.Pos(), "%s", msg)
+	}
+	changed := true
+	latest, err := client.Latest(ctx.Context, subject)
+	switch {
+	case err == nil:
+		changed = !latest.Equal(s)
+	case !errors.Is(err, schemaregistry.ErrNotFound):
+		return nil, err
+	}
+	messages := compat.Messages
+	if messages == nil {
+		messages = []string{}
+	}
+	return map[string]interface{}{
+		"compatible": compat.Compatible,
+		"messages":   messages,
+		"changed":    changed,
+	}, nil
+}
+
+func (c *cmdLatest) Run(ctx *task.Context) (res interface{}, err error) {
+	client, subject := newClient(ctx)
+	if ctx.Err != nil {
+		return nil, ctx.Err
+	}
+	v, err := client.Latest(ctx.Context, subject)
+	if errors.Is(err, schemaregistry.ErrNotFound) {
+		return map[string]interface{}{"found": false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"found":   true,
+		"id":      v.ID,
+		"version": v.Version,
+		"format":  strings.ToLower(string(v.Format)),
+		"text":    v.Text,
+	}, nil
+}
+
+// newClient returns a client for the registry described by the task, along
+// with the subject of the task.
+func newClient(ctx *task.Context) (*schemaregistry.Client, string) {
+	subject := ctx.String("subject")
+	reg := ctx.Lookup("registry")
+	cfg := &schemaregistry.Config{}
+	if v := reg.LookupPath(cue.ParsePath("url")); v.Exists() {
+		cfg.URL, _ = v.String()
+	}
+	if v := reg.LookupPath(cue.ParsePath("username")); v.Exists() {
+		cfg.Username, _ = v.String()
+	}
+	if v := reg.LookupPath(cue.ParsePath("password")); v.Exists() {
+		cfg.Password, _ = v.String()
+	}
+	client, err := schemaregistry.NewClient(cfg)
+	if err != nil {
+		ctx.Err = cueerrors.Append(ctx.Err, cueerrors.Promote(err, "registry"))
+	}
+	return client, subject
+}
+
+// generate generates the schema described by the task.
+func generate(ctx *task.Context) schemaregistry.Schema {
+	f, err := schemaregistry.ParseFormat(ctx.String("format"))
+	if err != nil {
+		ctx.Err = cueerrors.Append(ctx.Err, cueerrors.Promote(err, "format"))
+		return schemaregistry.Schema{}
+	}
+	var name string
+	if v := ctx.Obj.LookupPath(cue.ParsePath("name")); v.Exists() {
+		name = ctx.String("name")
+	}
+	s, err := schemaregistry.Generate(ctx.Lookup("schema"), f, name)
+	if err != nil {
+		ctx.Err = cueerrors.Append(ctx.Err, cueerrors.Promote(err, "schema"))
+	}
+	return s
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sops
+
+// Decrypt decrypts a YAML or JSON document and decodes its plaintext.
+//
+// Example:
+//     task: read: file.Read & {
+//         filename: "secrets.enc.yaml"
+//     }
+//     task: decrypt: sops.Decrypt & {
+//         contents: task.read.contents
+//     }
+//     task: login: exec.Run & {
+//         cmd: ["login", "--user", task.decrypt.value.user]
+//         stdin: task.decrypt.value.password
+//     }
+Decrypt: {
+	$id: "tool/sops.Decrypt"
+
+	// contents holds the encrypted document.
+	contents: bytes | string
+
+	// format is the format of the document.
+	format: *"yaml" | "json"
+
+	// value holds the decrypted document.
+	value: _
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sops
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/sops"
+	"cuelang.org/go/internal/task"
+	"cuelang.org/go/internal/third_party/yaml"
+)
+
+func init() {
+	task.Register("tool/sops.Decrypt", newDecryptCmd)
+}
+
+func newDecryptCmd(v cue.Value) (task.Runner, error) { return &cmdDecrypt{}, nil }
+
+type cmdDecrypt struct{}
+
+func (c *cmdDecrypt) Run(ctx *task.Context) (res interface{}, err error) {
+	data, err := ctx.Lookup("contents").Bytes()
+	if err != nil {
+		return nil, err
+	}
+	format := sops.Format(ctx.String("format"))
+	if ctx.Err != nil {
+		return nil, ctx.Err
+	}
+	if !sops.IsEncrypted(data, format) {
+		return nil, errors.Newf(ctx.Obj.Pos(), "contents is not a %s document encrypted with SOPS", format)
+	}
+	plain, err := (&sops.Command{}).Decrypt(ctx.Context, data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// The name of the document is only used in positions.
+	const name = "plaintext"
+	var value interface{}
+	switch format {
+	case sops.YAML:
+		value, err = yaml.Unmarshal(name, plain)
+	case sops.JSON:
+		value, err = json.Extract(name, plain)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		// Errors may quote the plaintext, so they are not returned.
+		return nil, errors.Newf(ctx.Obj.Pos(), "cannot decode decrypted %s document", format)
+	}
+	return map[string]interface{}{"value": value}, nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bulk validates large numbers of independent documents, such as
+// the records of an NDJSON file, against a schema.
+//
+// Documents are validated concurrently. As a cue.Context may not be used
+// by more than one goroutine at a time, each worker evaluates documents
+// with its own context and its own copy of the schema. Results are
+// nevertheless reported in the order of the documents.
+package bulk
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+)
+
+// A Config configures a validation run.
+type Config struct {
+	// Schema returns the schema that documents are validated against,
+	// built with ctx. It is called once for each worker, before any
+	// documents are validated and never concurrently.
+	Schema func(ctx *cue.Context) (cue.Value, error)
+
+	// NewContext returns the context used by a worker. It defaults to
+	// cuecontext.New. A context that is returned more than once limits
+	// the run to a single worker.
+	NewContext func() *cue.Context
+
+	// Concurrency is the number of documents that are validated in
+	// parallel. It defaults to the number of CPUs that may be used.
+	Concurrency int
+
+	// Report, if non-nil, is called with the result of each document, in
+	// the order in which the documents are read.
+	Report func(Result)
+}
+
+// A Document is a single document to validate.
+type Document struct {
+	// Name identifies the document in results, for instance by its file
+	// name and index within the file.
+	Name string
+
+	// File holds the syntax of the document.
+	File *ast.File
+}
+
+// A Source provides the documents to validate.
+type Source interface {
+	// Next returns the next document, or io.EOF if there are no more.
+	Next() (Document, error)
+}
+
+// A Result holds the outcome of validating a single document.
+type Result struct {
+	// Index is the number of documents read before this one.
+	Index int
+
+	// Name is the name of the document.
+	Name string
+
+	// Err holds the errors found in the document, or nil if it is valid.
+	Err errors.Error
+}
+
+// Stats aggregates the results of a run.
+type Stats struct {
+	// Documents is the number of documents validated.
+	Documents int
+
+	// Valid and Invalid count the documents without and with errors,
+	// respectively.
+	Valid   int
+	Invalid int
+
+	// Errors maps each error, identified by its path and message without
+	// positions, to the number of documents that reported it.
+	Errors map[string]int
+}
+
+// TopErrors returns the n errors reported by most documents, ordered by
+// decreasing count and then by message, or all errors if n is negative.
+func (s *Stats) TopErrors(n int) []string {
+	var a []string
+	for msg := range s.Errors {
+		a = append(a, msg)
+	}
+	sort.Slice(a, func(i, j int) bool {
+		if ci, cj := s.Errors[a[i]], s.Errors[a[j]]; ci != cj {
+			return ci > cj
+		}
+		return a[i] < a[j]
+	})
+	if n >= 0 && n < len(a) {
+		a = a[:n]
+	}
+	return a
+}
+
+type job struct {
+	index int
+	doc   Document
+	done  chan Result
+}
+
+// Validate validates the documents provided by src against the schema and
+// returns aggregated statistics. Documents are valid if they unify with the
+// schema and the result is concrete.
+//
+// An error is returned if src reports an error other than io.EOF, or if
+// the schema cannot be built. Errors in documents are not returned, but
+// recorded in their results.
+func Validate(cfg *Config, src Source) (*Stats, error) {
+	n := cfg.Concurrency
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	newContext := cfg.NewContext
+	if newContext == nil {
+		newContext = func() *cue.Context { return cuecontext.New() }
+	}
+
+	// Set up the schemas of all workers up front: building values from
+	// the same build instances or syntax concurrently is not safe.
+	var schemas []cue.Value
+	seen := map[*cue.Context]bool{}
+	for i := 0; i < n; i++ {
+		ctx := newContext()
+		if seen[ctx] {
+			break
+		}
+		seen[ctx] = true
+		v, err := cfg.Schema(ctx)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, v)
+	}
+
+	jobs := make(chan *job)
+	for _, schema := range schemas {
+		go func(schema cue.Value) {
+			for j := range jobs {
+				j.done <- Result{
+					Index: j.index,
+					Name:  j.doc.Name,
+					Err:   validate(schema, j.doc),
+				}
+			}
+		}(schema)
+	}
+
+	// The queue holds the jobs in the order of the documents. It is
+	// bounded, so that documents are read no further ahead than needed.
+	queue := make(chan *job, 2*len(schemas))
+	var readErr error
+	go func() {
+		defer close(jobs)
+		defer close(queue)
+		for i := 0; ; i++ {
+			doc, err := src.Next()
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+				}
+				return
+			}
+			j := &job{index: i, doc: doc, done: make(chan Result, 1)}
+			queue <- j
+			jobs <- j
+		}
+	}()
+
+	stats := &Stats{Errors: map[string]int{}}
+	for j := range queue {
+		r := <-j.done
+		stats.add(r)
+		if cfg.Report != nil {
+			cfg.Report(r)
+		}
+	}
+	return stats, readErr
+}
+
+func validate(schema cue.Value, doc Document) errors.Error {
+	v := schema.Context().BuildFile(doc.File)
+	if err := v.Err(); err != nil {
+		return errors.Promote(err, "")
+	}
+	v = v.Unify(schema)
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return errors.Promote(err, "")
+	}
+	return nil
+}
+
+func (s *Stats) add(r Result) {
+	s.Documents++
+	if r.Err == nil {
+		s.Valid++
+		return
+	}
+	s.Invalid++
+	seen := map[string]bool{}
+	for _, e := range errors.Errors(errors.Sanitize(r.Err)) {
+		msg := message(e)
+		if !seen[msg] {
+			seen[msg] = true
+			s.Errors[msg]++
+		}
+	}
+}
+
+// message returns the path and message of e, without positions.
+func message(e errors.Error) string {
+	format, args := e.Msg()
+	msg := fmt.Sprintf(format, args...)
+	if p := e.Path(); len(p) > 0 {
+		msg = strings.Join(p, ".") + ": " + msg
+	}
+	return msg
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulk_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/tools/bulk"
+)
+
+const schema = `
+#Event: {
+	id:    int
+	kind:  "create" | "delete"
+	extra: string | *""
+}
+`
+
+func newSchema(ctx *cue.Context) (cue.Value, error) {
+	v := ctx.CompileString(schema).LookupPath(cue.ParsePath("#Event"))
+	return v, v.Err()
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestValidate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.ndjson": `{"id": 1, "kind": "create"}
+{"id": 2, "kind": "update"}
+{"id": "3", "kind": "delete"}
+`,
+		"b.yaml": `id: 4
+kind: delete
+---
+id: 5
+kind: update
+`,
+		"ignored.txt": "not a document",
+	})
+
+	for _, n := range []int{1, 4} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			var got []string
+			stats, err := bulk.Validate(&bulk.Config{
+				Schema:      newSchema,
+				Concurrency: n,
+				Report: func(r bulk.Result) {
+					status := "ok"
+					if r.Err != nil {
+						status = "FAIL"
+					}
+					name, _ := filepath.Rel(dir, r.Name)
+					got = append(got, fmt.Sprintf("%d %s %s", r.Index, name, status))
+				},
+			}, bulk.Files(dir))
+			qt.Assert(t, qt.IsNil(err))
+
+			qt.Assert(t, qt.DeepEquals(got, []string{
+				"0 a.ndjson:1 ok",
+				"1 a.ndjson:2 FAIL",
+				"2 a.ndjson:3 FAIL",
+				"3 b.yaml:1 ok",
+				"4 b.yaml:2 FAIL",
+			}))
+			qt.Assert(t, qt.Equals(stats.Documents, 5))
+			qt.Assert(t, qt.Equals(stats.Valid, 2))
+			qt.Assert(t, qt.Equals(stats.Invalid, 3))
+			qt.Assert(t, qt.DeepEquals(stats.TopErrors(-1), []string{
+				`kind: 2 errors in empty disjunction:`,
+				`kind: conflicting values "create" and "update"`,
+				`kind: conflicting values "delete" and "update"`,
+				`id: conflicting values "3" and int (mismatched types string and int)`,
+			}))
+			qt.Assert(t, qt.DeepEquals(stats.TopErrors(1), []string{
+				`kind: 2 errors in empty disjunction:`,
+			}))
+		})
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	_, err := bulk.Validate(&bulk.Config{Schema: newSchema}, bulk.Files("nonexistent.json"))
+	qt.Assert(t, qt.ErrorMatches(err, `stat nonexistent.json: .*`))
+
+	_, err = bulk.Validate(&bulk.Config{
+		Schema: func(ctx *cue.Context) (cue.Value, error) {
+			return cue.Value{}, errors.New("no schema")
+		},
+	}, bulk.Files())
+	qt.Assert(t, qt.ErrorMatches(err, `no schema`))
+
+	dir := writeFiles(t, map[string]string{
+		"a.json": `{"id": 1, "kind": "create"} {"id": `,
+	})
+	var names []string
+	stats, err := bulk.Validate(&bulk.Config{
+		Schema: newSchema,
+		Report: func(r bulk.Result) { names = append(names, filepath.Base(r.Name)) },
+	}, bulk.Files(dir))
+	qt.Assert(t, qt.IsNotNil(err))
+	qt.Assert(t, qt.IsTrue(strings.Contains(err.Error(), "a.json")))
+	qt.Assert(t, qt.DeepEquals(names, []string{"a.json:1"}))
+	qt.Assert(t, qt.Equals(stats.Documents, 1))
+}
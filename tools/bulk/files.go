@@ -0,0 +1,113 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulk
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// dataExtensions lists the extensions of the files read from directories.
+var dataExtensions = map[string]bool{
+	".json":   true,
+	".jsonl":  true,
+	".ndjson": true,
+	".yaml":   true,
+	".yml":    true,
+}
+
+// Files returns a Source that reads the documents in the given files. JSON
+// files may hold a stream of values, such as NDJSON, and YAML files may hold
+// multiple documents separated by ---; each of these is a separate
+// document. A directory stands for the JSON and YAML files it contains,
+// in lexical order, without descending into subdirectories.
+//
+// Documents are named after their file and their index within the file,
+// starting at 1, as in "events.ndjson:3".
+func Files(paths ...string) Source {
+	return &fileSource{paths: paths}
+}
+
+type fileSource struct {
+	paths []string
+	dec   *encoding.Decoder
+	n     int // number of documents read from dec
+}
+
+func (s *fileSource) Next() (Document, error) {
+	for {
+		if s.dec != nil {
+			if !s.dec.Done() {
+				doc := Document{
+					Name: fmt.Sprintf("%s:%d", s.dec.Filename(), s.n+1),
+					File: s.dec.File(),
+				}
+				s.dec.Next()
+				s.n++
+				return doc, nil
+			}
+			err := s.dec.Err()
+			s.dec.Close()
+			s.dec = nil
+			if err != nil {
+				return Document{}, err
+			}
+		}
+		if len(s.paths) == 0 {
+			return Document{}, io.EOF
+		}
+		name := s.paths[0]
+		s.paths = s.paths[1:]
+
+		fi, err := os.Stat(name)
+		if err != nil {
+			return Document{}, err
+		}
+		if fi.IsDir() {
+			files, err := dataFiles(name)
+			if err != nil {
+				return Document{}, err
+			}
+			s.paths = append(files, s.paths...)
+			continue
+		}
+		f, err := filetypes.ParseFile(name, filetypes.Input)
+		if err != nil {
+			return Document{}, err
+		}
+		s.dec = encoding.NewDecoder(f, &encoding.Config{})
+		s.n = 0
+	}
+}
+
+// dataFiles returns the JSON and YAML files in dir.
+func dataFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && dataExtensions[filepath.Ext(e.Name())] {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,411 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cachedir manages the directory in which CUE keeps its caches,
+// such as the downloaded modules and the results recorded by cue vet and
+// cue export, so that the cue command and programs embedding CUE agree
+// on where each cache lives and on how it is cleaned up.
+//
+// Each cache is kept in its own subdirectory of the cache directory,
+// named after the cache:
+//
+//	$CUE_CACHE_DIR/
+//		mod/     downloaded modules
+//		vet/     packages that passed cue vet --cache
+//		export/  output recorded by cue export --cache
+//		lsp/     indexes of language servers
+//
+// The cache directory defaults to the cue directory of the user's cache
+// directory and is overridden by $CUE_CACHE_DIR. Some caches can be
+// moved out of it individually: $CUE_MODCACHE, for example, overrides
+// the directory of the module cache.
+//
+// Processes writing to a cache hold a shared lock on it, obtained with
+// [Dir.RLock] or [RLockDir], while [Dir.Clean] and [Dir.Trim] hold an
+// exclusive lock, so that a cache is not removed from under a write.
+//
+// Earlier versions of cue stored downloaded modules at the root of the
+// cache directory; [Dir.MigrateModules] moves them to the module cache.
+package cachedir
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rogpeppe/go-internal/lockedfile"
+	"github.com/rogpeppe/go-internal/robustio"
+)
+
+// A Cache describes one of the caches kept in a cache directory.
+type Cache struct {
+	// Name is the name of the subdirectory holding the cache.
+	Name string
+
+	// Env, if not empty, names the environment variable overriding
+	// the directory of the cache.
+	Env string
+
+	// Description describes the contents of the cache.
+	Description string
+
+	// Evictable reports whether the files of the cache are independent
+	// of each other, so that the ones that have not been used for a
+	// while can be removed by [Dir.Trim].
+	Evictable bool
+}
+
+// The caches known to CUE.
+var (
+	Modules = &Cache{
+		Name:        "mod",
+		Env:         "CUE_MODCACHE",
+		Description: "downloaded modules",
+	}
+	Vet = &Cache{
+		Name:        "vet",
+		Description: "packages that passed cue vet --cache",
+		Evictable:   true,
+	}
+	Export = &Cache{
+		Name:        "export",
+		Description: "output recorded by cue export --cache",
+		Evictable:   true,
+	}
+	LSP = &Cache{
+		Name:        "lsp",
+		Description: "indexes of language servers",
+		Evictable:   true,
+	}
+)
+
+// Caches returns all the caches known to CUE.
+func Caches() []*Cache {
+	return []*Cache{Modules, Vet, Export, LSP}
+}
+
+// Lookup returns the cache with the given name, or nil if there is none.
+func Lookup(name string) *Cache {
+	for _, c := range Caches() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// lockFile is the name of the file locked in the directory of a cache.
+const lockFile = ".lock"
+
+// usedInterval is the granularity with which [MarkUsed] records the use
+// of a file, which avoids modifying a file each time it is read.
+const usedInterval = time.Hour
+
+// Dir is a cache directory.
+type Dir struct {
+	root  string
+	paths map[*Cache]string
+}
+
+// New returns the cache directory rooted at root. Environment variables
+// are not consulted.
+func New(root string) *Dir {
+	return &Dir{
+		root:  root,
+		paths: map[*Cache]string{},
+	}
+}
+
+// Default returns the cache directory used by the cue command: the
+// directory named by $CUE_CACHE_DIR or, if it is not set, the cue
+// directory of the user's cache directory. The environment variables
+// overriding the directories of individual caches are honored.
+func Default() (*Dir, error) {
+	root := os.Getenv("CUE_CACHE_DIR")
+	if root == "" {
+		sysCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine system cache directory: %v", err)
+		}
+		root = filepath.Join(sysCacheDir, "cue")
+	}
+	d := New(root)
+	for _, c := range Caches() {
+		if c.Env == "" {
+			continue
+		}
+		if dir := os.Getenv(c.Env); dir != "" {
+			d.paths[c] = dir
+		}
+	}
+	return d, nil
+}
+
+// Root returns the root of the cache directory.
+func (d *Dir) Root() string {
+	return d.root
+}
+
+// Path returns the directory of cache c, which need not exist.
+func (d *Dir) Path(c *Cache) string {
+	if dir, ok := d.paths[c]; ok {
+		return dir
+	}
+	return filepath.Join(d.root, c.Name)
+}
+
+// Create returns the directory of cache c, creating it if needed.
+func (d *Dir) Create(c *Cache) (string, error) {
+	dir := d.Path(c)
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return "", fmt.Errorf("cannot create cache directory: %v", err)
+	}
+	return dir, nil
+}
+
+// Lock takes an exclusive lock on cache c, waiting for the processes
+// holding a lock on it to release theirs. It returns a function that
+// releases the lock.
+func (d *Dir) Lock(c *Cache) (unlock func(), err error) {
+	dir, err := d.Create(c)
+	if err != nil {
+		return nil, err
+	}
+	return lockedfile.MutexAt(filepath.Join(dir, lockFile)).Lock()
+}
+
+// RLock takes a shared lock on cache c, which is held while writing to
+// it, waiting for a process holding an exclusive lock to release it.
+// It returns a function that releases the lock.
+func (d *Dir) RLock(c *Cache) (unlock func(), err error) {
+	dir, err := d.Create(c)
+	if err != nil {
+		return nil, err
+	}
+	return RLockDir(dir)
+}
+
+// RLockDir is like [Dir.RLock] for the cache in the existing directory
+// dir. It is used by packages that are only given the directory of a
+// cache, such as the module cache.
+func RLockDir(dir string) (unlock func(), err error) {
+	f, err := lockedfile.OpenFile(filepath.Join(dir, lockFile), os.O_RDONLY|os.O_CREATE, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	return func() { f.Close() }, nil
+}
+
+// MigrateModules moves the modules that earlier versions of cue
+// downloaded to the root of the cache directory to the module cache,
+// merging them with the modules already there. It does nothing if the
+// module cache has been moved out of the cache directory, or if there
+// are no such modules.
+func (d *Dir) MigrateModules() error {
+	if _, ok := d.paths[Modules]; ok {
+		return nil
+	}
+	// The module cache always holds a cache/download directory.
+	legacy := func() bool {
+		_, err := os.Stat(filepath.Join(d.root, "cache", "download"))
+		return err == nil
+	}
+	if !legacy() {
+		return nil
+	}
+	unlock, err := d.Lock(Modules)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if !legacy() {
+		// Another process migrated the modules.
+		return nil
+	}
+	entries, err := os.ReadDir(d.root)
+	if err != nil {
+		return err
+	}
+	dst := d.Path(Modules)
+	for _, e := range entries {
+		// The names of caches are not valid hosts of modules.
+		if Lookup(e.Name()) != nil || e.Name() == lockFile {
+			continue
+		}
+		if err := moveInto(filepath.Join(d.root, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return fmt.Errorf("cannot move downloaded modules to %s: %v", dst, err)
+		}
+	}
+	return nil
+}
+
+// moveInto moves the file or directory src to dst. If dst exists, the
+// contents of directories are merged, and src is dropped where both hold
+// the same file or extracted module version.
+func moveInto(src, dst string) error {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	dstInfo, err := os.Lstat(dst)
+	if os.IsNotExist(err) {
+		return robustio.Rename(src, dst)
+	}
+	if err != nil {
+		return err
+	}
+	// Extracted module versions, named path@version, are read-only and
+	// identical in both places.
+	if !srcInfo.IsDir() || !dstInfo.IsDir() || strings.Index(srcInfo.Name(), "@") > 0 {
+		return removeAll(src)
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := moveInto(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Remove(src)
+}
+
+// Usage describes the disk usage of a cache.
+type Usage struct {
+	// Files holds the number of regular files in the cache.
+	Files int
+
+	// Size holds the total size of these files in bytes.
+	Size int64
+}
+
+// Usage returns the disk usage of cache c. A cache that does not exist
+// uses no space.
+func (d *Dir) Usage(c *Cache) (Usage, error) {
+	var u Usage
+	err := d.walkFiles(c, func(path string, info fs.FileInfo) error {
+		u.Files++
+		u.Size += info.Size()
+		return nil
+	})
+	return u, err
+}
+
+// Clean removes the contents of cache c.
+func (d *Dir) Clean(c *Cache) error {
+	dir := d.Path(c)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	unlock, err := d.Lock(c)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Name() == lockFile {
+			continue
+		}
+		if err := removeAll(filepath.Join(dir, e.Name())); err != nil {
+			return fmt.Errorf("cannot clean %s cache: %v", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// Trim removes the files of cache c that have not been used for the
+// given duration, and returns the number of files removed. Only
+// evictable caches can be trimmed.
+func (d *Dir) Trim(c *Cache, unused time.Duration) (int, error) {
+	if !c.Evictable {
+		return 0, fmt.Errorf("%s cache cannot be trimmed; clean it instead", c.Name)
+	}
+	if _, err := os.Stat(d.Path(c)); os.IsNotExist(err) {
+		return 0, nil
+	}
+	unlock, err := d.Lock(c)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	cutoff := time.Now().Add(-unused)
+	n := 0
+	err = d.walkFiles(c, func(path string, info fs.FileInfo) error {
+		if !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// walkFiles calls f for each regular file of cache c other than its
+// lock file.
+func (d *Dir) walkFiles(c *Cache, f func(path string, info fs.FileInfo) error) error {
+	dir := d.Path(c)
+	err := filepath.WalkDir(dir, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !e.Type().IsRegular() || path == filepath.Join(dir, lockFile) {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		return f(path, info)
+	})
+	return err
+}
+
+// MarkUsed records that the cache file at path was used, so that it is
+// not evicted by [Dir.Trim]. Errors are ignored, as they only affect
+// eviction.
+func MarkUsed(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if now := time.Now(); now.Sub(info.ModTime()) >= usedInterval {
+		os.Chtimes(path, now, now)
+	}
+}
+
+// removeAll removes path, first making the directories within it
+// writable, as the module cache keeps its directories read-only.
+func removeAll(path string) error {
+	filepath.WalkDir(path, func(path string, e fs.DirEntry, err error) error {
+		if err == nil && e.IsDir() {
+			os.Chmod(path, 0o777)
+		}
+		return nil
+	})
+	return robustio.RemoveAll(path)
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cachedir_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cuelang.org/go/tools/cachedir"
+)
+
+func TestDefault(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("CUE_CACHE_DIR", root)
+	t.Setenv("CUE_MODCACHE", "")
+	d, err := cachedir.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.Path(cachedir.Modules), filepath.Join(root, "mod"); got != want {
+		t.Errorf("got module cache %s; want %s", got, want)
+	}
+	if got, want := d.Path(cachedir.Vet), filepath.Join(root, "vet"); got != want {
+		t.Errorf("got vet cache %s; want %s", got, want)
+	}
+
+	modCache := t.TempDir()
+	t.Setenv("CUE_MODCACHE", modCache)
+	d, err = cachedir.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Path(cachedir.Modules); got != modCache {
+		t.Errorf("got module cache %s; want %s", got, modCache)
+	}
+}
+
+func TestCleanAndTrim(t *testing.T) {
+	d := cachedir.New(t.TempDir())
+	dir, err := d.Create(cachedir.Export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	for name, data := range map[string]string{"old": "abc", "new": "de", "used": "f"} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(data), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		if name != "new" {
+			if err := os.Chtimes(file, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	cachedir.MarkUsed(filepath.Join(dir, "used"))
+
+	u, err := d.Usage(cachedir.Export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != (cachedir.Usage{Files: 3, Size: 6}) {
+		t.Errorf("got usage %+v; want 3 files of 6 bytes", u)
+	}
+
+	n, err := d.Trim(cachedir.Export, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("trimmed %d files; want 1", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old")); !os.IsNotExist(err) {
+		t.Errorf("old file not trimmed")
+	}
+
+	if _, err := d.Trim(cachedir.Modules, time.Hour); err == nil {
+		t.Errorf("module cache trimmed")
+	}
+
+	if err := d.Clean(cachedir.Export); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := d.Usage(cachedir.Export); err != nil || u.Files != 0 {
+		t.Errorf("got usage %+v, %v after clean; want no files", u, err)
+	}
+
+	// Missing caches use no space and need no cleaning.
+	if u, err := d.Usage(cachedir.LSP); err != nil || u != (cachedir.Usage{}) {
+		t.Errorf("got usage %+v, %v for missing cache", u, err)
+	}
+	if err := d.Clean(cachedir.LSP); err != nil {
+		t.Errorf("cannot clean missing cache: %v", err)
+	}
+}
+
+func TestCleanReadOnly(t *testing.T) {
+	d := cachedir.New(t.TempDir())
+	dir, err := d.Create(cachedir.Modules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod := filepath.Join(dir, "example.com@v0.1.0")
+	if err := os.Mkdir(mod, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mod, "x.cue"), []byte("a: 1"), 0o444); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(mod, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Clean(cachedir.Modules); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(mod); !os.IsNotExist(err) {
+		t.Errorf("module not removed")
+	}
+}
+
+func TestMigrateModules(t *testing.T) {
+	root := t.TempDir()
+	d := cachedir.New(root)
+	// Modules downloaded by earlier versions of cue, some of which were
+	// also downloaded to the module cache.
+	for _, file := range []string{
+		"cache/download/example.com/foo/@v/v0.1.0.zip",
+		"cache/download/example.com/foo/@v/v0.2.0.zip",
+		"example.com/foo@v0.1.0/cue.mod/module.cue",
+		"example.com/foo@v0.2.0/cue.mod/module.cue",
+		"mod/cache/download/example.com/foo/@v/v0.2.0.zip",
+		"mod/example.com/foo@v0.2.0/cue.mod/module.cue",
+		"vet/entry",
+	} {
+		writeFile(t, filepath.Join(root, file), "old")
+	}
+	// Extracted modules are read-only.
+	if err := os.Chmod(filepath.Join(root, "example.com/foo@v0.2.0/cue.mod"), 0o555); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.MigrateModules(); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{
+		"mod/cache/download/example.com/foo/@v/v0.1.0.zip",
+		"mod/cache/download/example.com/foo/@v/v0.2.0.zip",
+		"mod/example.com/foo@v0.1.0/cue.mod/module.cue",
+		"mod/example.com/foo@v0.2.0/cue.mod/module.cue",
+		"vet/entry",
+	} {
+		if _, err := os.Stat(filepath.Join(root, file)); err != nil {
+			t.Errorf("after migration: %v", err)
+		}
+	}
+	for _, file := range []string{"cache", "example.com"} {
+		if _, err := os.Stat(filepath.Join(root, file)); !os.IsNotExist(err) {
+			t.Errorf("%s was not migrated", file)
+		}
+	}
+	// Migrating again does nothing.
+	if err := d.MigrateModules(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, file, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(data), 0o666); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compat classifies the changes between two versions of a schema.
+//
+// The definitions of both versions are compared pairwise. A change to a
+// definition is backward compatible if the new definition accepts all
+// values accepted by the old one, so that existing data remains valid.
+// It is forward compatible if the old definition accepts all values
+// accepted by the new one, so that consumers still using the old schema
+// can process data produced for the new one. A change that is neither is
+// breaking.
+//
+// Acceptance is determined by subsumption, taking the closedness of
+// definitions into account, and by comparing the required fields of both
+// versions, as subsumption does not consider whether fields are required.
+package compat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// A Compatibility classifies a change.
+type Compatibility string
+
+const (
+	// Equivalent indicates that both versions accept the same values.
+	Equivalent Compatibility = "equivalent"
+
+	// Backward indicates that the new version accepts all values accepted
+	// by the old version, but not vice versa.
+	Backward Compatibility = "backward"
+
+	// Forward indicates that the old version accepts all values accepted
+	// by the new version, but not vice versa.
+	Forward Compatibility = "forward"
+
+	// Breaking indicates that neither version accepts all values accepted
+	// by the other.
+	Breaking Compatibility = "breaking"
+)
+
+func classify(backward, forward bool) Compatibility {
+	switch {
+	case backward && forward:
+		return Equivalent
+	case backward:
+		return Backward
+	case forward:
+		return Forward
+	}
+	return Breaking
+}
+
+// IsBackward reports whether c allows the new version to accept all values
+// accepted by the old version.
+func (c Compatibility) IsBackward() bool {
+	return c == Equivalent || c == Backward
+}
+
+// IsForward reports whether c allows the old version to accept all values
+// accepted by the new version.
+func (c Compatibility) IsForward() bool {
+	return c == Equivalent || c == Forward
+}
+
+// A Change describes the change to a single definition.
+type Change struct {
+	// Path is the path of the definition.
+	Path cue.Path
+
+	// Old and New hold the definition in the old and new version. Old does
+	// not exist for an added definition and New for a removed one.
+	Old, New cue.Value
+
+	Compatibility Compatibility
+
+	// Backward and Forward explain why the change is not backward or
+	// forward compatible, respectively.
+	Backward, Forward error
+}
+
+// Added reports whether the definition was added in the new version.
+func (c *Change) Added() bool { return !c.Old.Exists() }
+
+// Removed reports whether the definition was removed in the new version.
+func (c *Change) Removed() bool { return !c.New.Exists() }
+
+// A Report holds the changes to the definitions of a schema.
+type Report struct {
+	// Changes holds a change for each definition in either version, in
+	// the order of the old version followed by the definitions that were
+	// added.
+	Changes []*Change
+}
+
+// Compatibility returns the compatibility of the schema as a whole: a
+// schema is backward or forward compatible only if all of its definitions
+// are.
+func (r *Report) Compatibility() Compatibility {
+	backward, forward := true, true
+	for _, c := range r.Changes {
+		backward = backward && c.Compatibility.IsBackward()
+		forward = forward && c.Compatibility.IsForward()
+	}
+	return classify(backward, forward)
+}
+
+// maxDepth limits the depth to which definitions are searched and required
+// fields are compared, which also guards against recursive schemas.
+const maxDepth = 10
+
+// Compare compares the definitions of the old and new versions of a
+// schema, which must have been built using the same cue.Context.
+// Definitions nested within other definitions and within regular fields
+// are compared as well.
+func Compare(old, new cue.Value) *Report {
+	r := &Report{}
+	seen := map[string]bool{}
+	definitions(cue.Path{}, old, 0, func(p cue.Path, o cue.Value) {
+		seen[p.String()] = true
+		r.Changes = append(r.Changes, compare(p, o, new.LookupPath(p)))
+	})
+	definitions(cue.Path{}, new, 0, func(p cue.Path, n cue.Value) {
+		if !seen[p.String()] {
+			r.Changes = append(r.Changes, compare(p, cue.Value{}, n))
+		}
+	})
+	return r
+}
+
+// definitions calls f for each definition in the struct v.
+func definitions(p cue.Path, v cue.Value, depth int, f func(cue.Path, cue.Value)) {
+	if v.IncompleteKind() != cue.StructKind || depth >= maxDepth {
+		return
+	}
+	iter, err := v.Fields(cue.Definitions(true))
+	if err != nil {
+		return
+	}
+	for iter.Next() {
+		fp := appendPath(p, iter.Selector())
+		if iter.Selector().IsDefinition() {
+			f(fp, iter.Value())
+		}
+		definitions(fp, iter.Value(), depth+1, f)
+	}
+}
+
+func compare(p cue.Path, old, new cue.Value) *Change {
+	c := &Change{Path: p, Old: old, New: new}
+	switch {
+	case !old.Exists():
+		c.Forward = errors.Newf(new.Pos(), "definition added")
+	case !new.Exists():
+		c.Backward = errors.Newf(old.Pos(), "definition removed")
+	default:
+		c.Backward = accepts(new, old, "new")
+		c.Forward = accepts(old, new, "old")
+	}
+	c.Compatibility = classify(c.Backward == nil, c.Forward == nil)
+	return c
+}
+
+// accepts returns an error if v, the definition in the named version, does
+// not accept all values accepted by w.
+func accepts(v, w cue.Value, version string) error {
+	if err := v.Subsume(w); err != nil {
+		// The errors reported by Subsume refer to internal details of
+		// the comparison, so report the outcome only.
+		other := "old"
+		if version == other {
+			other = "new"
+		}
+		return errors.Newf(v.Pos(),
+			"the %s version does not accept all values of the %s version", version, other)
+	}
+	return requiredFields(cue.Path{}, v, w, version, 0)
+}
+
+// requiredFields returns an error if v requires a field that w does not.
+// Regular fields are considered to be required, as they must be set in
+// concrete data.
+func requiredFields(p cue.Path, v, w cue.Value, version string, depth int) error {
+	if v.IncompleteKind() != cue.StructKind || w.IncompleteKind() != cue.StructKind || depth >= maxDepth {
+		return nil
+	}
+	required := map[string]cue.Value{}
+	iter, err := w.Fields(cue.Optional(true))
+	if err != nil {
+		return nil
+	}
+	for iter.Next() {
+		if iter.Selector().ConstraintType() != cue.OptionalConstraint {
+			required[iter.Selector().Unquoted()] = iter.Value()
+		}
+	}
+
+	iter, err = v.Fields(cue.Optional(true))
+	if err != nil {
+		return nil
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		if sel.ConstraintType() == cue.OptionalConstraint {
+			continue
+		}
+		fp := appendPath(p, cue.Str(sel.Unquoted()))
+		wv, ok := required[sel.Unquoted()]
+		if !ok {
+			return errors.Newf(iter.Value().Pos(),
+				"field %v is only required in the %s version", fp, version)
+		}
+		if err := requiredFields(fp, iter.Value(), wv, version, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendPath(p cue.Path, sel cue.Selector) cue.Path {
+	return cue.MakePath(append(p.Selectors(), sel)...)
+}
+
+// WriteText writes the changes that are not equivalent, followed by the
+// compatibility of the schema as a whole, to w.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, c := range r.Changes {
+		if c.Compatibility == Equivalent {
+			continue
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%v: ", c.Path)
+		switch {
+		case c.Added():
+			b.WriteString("added, ")
+		case c.Removed():
+			b.WriteString("removed, ")
+		}
+		b.WriteString(describe(c.Compatibility))
+		b.WriteString("\n")
+		if c.Backward != nil && !c.Removed() {
+			fmt.Fprintf(&b, "\tnot backward compatible: %v\n", c.Backward)
+		}
+		if c.Forward != nil && !c.Added() {
+			fmt.Fprintf(&b, "\tnot forward compatible: %v\n", c.Forward)
+		}
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "result: %s\n", describe(r.Compatibility()))
+	return err
+}
+
+func describe(c Compatibility) string {
+	switch c {
+	case Backward, Forward:
+		return string(c) + " compatible"
+	}
+	return string(c)
+}
+
+// WriteJSON writes all changes, along with the compatibility of the schema
+// as a whole, as JSON to w.
+func (r *Report) WriteJSON(w io.Writer) error {
+	type jsonChange struct {
+		Path          string        `json:"path"`
+		Change        string        `json:"change"`
+		Compatibility Compatibility `json:"compatibility"`
+		Backward      string        `json:"backwardError,omitempty"`
+		Forward       string        `json:"forwardError,omitempty"`
+	}
+	changes := make([]jsonChange, len(r.Changes))
+	for i, c := range r.Changes {
+		jc := jsonChange{
+			Path:          c.Path.String(),
+			Change:        "modified",
+			Compatibility: c.Compatibility,
+		}
+		switch {
+		case c.Added():
+			jc.Change = "added"
+		case c.Removed():
+			jc.Change = "removed"
+		case c.Compatibility == Equivalent:
+			jc.Change = "unchanged"
+		}
+		if c.Backward != nil {
+			jc.Backward = c.Backward.Error()
+		}
+		if c.Forward != nil {
+			jc.Forward = c.Forward.Error()
+		}
+		changes[i] = jc
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(struct {
+		Compatibility Compatibility `json:"compatibility"`
+		Changes       []jsonChange  `json:"changes"`
+	}{r.Compatibility(), changes})
+}
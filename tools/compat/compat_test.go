@@ -0,0 +1,100 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compat_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/compat"
+)
+
+func TestCompare(t *testing.T) {
+	ctx := cuecontext.New()
+	old := ctx.CompileString(`
+	#Same: {a: int}
+	#AddOptional: {a: int}
+	#AddRequired: {a: int}
+	#Widen: {a: int & <10}
+	#Narrow: {a: int}
+	#Enum: "a" | "b"
+	#Tree: {children?: [...#Tree], v: int}
+	#Removed: int
+	x: #Nested: string
+	`)
+	new := ctx.CompileString(`
+	#Same: {a: int}
+	#AddOptional: {a: int, b?: string}
+	#AddRequired: {a: int, b!: string}
+	#Widen: {a: int}
+	#Narrow: {a: int & <10}
+	#Enum: "a" | "b" | "c"
+	#Tree: {children?: [...#Tree], v: int}
+	#Added: int
+	x: #Nested: string
+	`)
+
+	r := compat.Compare(old, new)
+	got := map[string]compat.Compatibility{}
+	for _, c := range r.Changes {
+		got[c.Path.String()] = c.Compatibility
+	}
+	qt.Assert(t, qt.DeepEquals(got, map[string]compat.Compatibility{
+		"#Same":        compat.Equivalent,
+		"#AddOptional": compat.Backward,
+		"#AddRequired": compat.Breaking,
+		"#Widen":       compat.Backward,
+		"#Narrow":      compat.Forward,
+		"#Enum":        compat.Backward,
+		"#Tree":        compat.Equivalent,
+		"#Removed":     compat.Forward,
+		"#Added":       compat.Backward,
+		"x.#Nested":    compat.Equivalent,
+	}))
+	qt.Assert(t, qt.Equals(r.Compatibility(), compat.Breaking))
+
+	var b strings.Builder
+	err := r.WriteText(&b)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(b.String(), `#AddOptional: backward compatible
+	not forward compatible: the old version does not accept all values of the new version
+#AddRequired: breaking
+	not backward compatible: field b is only required in the new version
+	not forward compatible: the old version does not accept all values of the new version
+#Widen: backward compatible
+	not forward compatible: the old version does not accept all values of the new version
+#Narrow: forward compatible
+	not backward compatible: the new version does not accept all values of the old version
+#Enum: backward compatible
+	not forward compatible: the old version does not accept all values of the new version
+#Removed: removed, forward compatible
+#Added: added, backward compatible
+result: breaking
+`))
+}
+
+func TestCompareBackward(t *testing.T) {
+	ctx := cuecontext.New()
+	old := ctx.CompileString(`#A: {a: int, b?: string}`)
+	new := ctx.CompileString(`#A: {a: int, b?: string, c?: bool}, #B: string`)
+
+	r := compat.Compare(old, new)
+	qt.Assert(t, qt.Equals(r.Compatibility(), compat.Backward))
+	qt.Assert(t, qt.IsTrue(r.Compatibility().IsBackward()))
+	qt.Assert(t, qt.IsFalse(r.Compatibility().IsForward()))
+}
@@ -0,0 +1,318 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package coverage reports which parts of a schema are exercised by a
+// corpus of data, much like code coverage for programs.
+//
+// A schema is divided into items: the fields it declares, including
+// optional fields and fields matched by pattern constraints, and the
+// branches of the disjunctions it uses. A field is exercised by a data
+// value that sets it. A disjunction branch is exercised by a data value
+// that is an instance of the branch. Items that are never exercised point
+// to parts of a schema that are either dead or untested.
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/token"
+)
+
+// A Kind indicates the kind of an Item.
+type Kind string
+
+const (
+	// Field is a field declared by the schema.
+	Field Kind = "field"
+
+	// Disjunct is a branch of a disjunction.
+	Disjunct Kind = "disjunct"
+)
+
+// An Item is a part of a schema for which coverage is reported.
+type Item struct {
+	// Path is the path of the item relative to the root of the schema.
+	// Elements of lists are denoted [_] and fields matched by a pattern
+	// constraint [string].
+	Path string
+
+	Kind Kind
+
+	// Value describes the disjunct for Disjunct items.
+	Value string
+
+	// Pos is the position of the item in the schema.
+	Pos token.Pos
+
+	// Hits is the number of times the item was exercised.
+	Hits int
+}
+
+// A Coverage records the coverage of a schema by data values.
+type Coverage struct {
+	root  *node
+	items []*Item
+}
+
+// node is a part of the schema against which data is matched.
+type node struct {
+	item *Item
+	v    cue.Value
+
+	fields    map[string]*node
+	pattern   *node // fields not declared explicitly
+	elem      *node // list elements
+	disjuncts []*node
+}
+
+// New returns a Coverage for the given schema.
+func New(schema cue.Value) *Coverage {
+	c := &Coverage{}
+	c.root = c.build(nil, schema, schema.Path().String(), nil)
+	return c
+}
+
+// build creates the node for the schema value v at path. The stack holds
+// the positions of the structs being built, to detect recursive schemas.
+func (c *Coverage) build(item *Item, v cue.Value, path string, stack []token.Pos) *node {
+	n := &node{item: item, v: v}
+
+	d := cue.Dereference(v)
+	if op, args := d.Expr(); op == cue.OrOp && len(args) > 1 {
+		items := make([]*Item, len(args))
+		for i, a := range args {
+			items[i] = c.newItem(Disjunct, path, a)
+		}
+		for i, a := range args {
+			n.disjuncts = append(n.disjuncts, c.build(items[i], a, path, stack))
+		}
+		return n
+	}
+
+	pos := d.Pos()
+	for _, p := range stack {
+		if pos.IsValid() && p == pos {
+			return n
+		}
+	}
+	stack = append(stack, pos)
+
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := v.Fields(cue.Optional(true))
+		if err != nil {
+			return n
+		}
+		for iter.Next() {
+			if n.fields == nil {
+				n.fields = map[string]*node{}
+			}
+			p := iter.Selector().String()
+			if path != "" {
+				p = path + "." + p
+			}
+			f := iter.Value()
+			n.fields[iter.Selector().Unquoted()] = c.build(c.newItem(Field, p, f), f, p, stack)
+		}
+		if t, ok := v.Elem(); ok {
+			p := path + "[string]"
+			n.pattern = c.build(c.newItem(Field, p, t), t, p, stack)
+		}
+
+	case cue.ListKind:
+		if t, ok := v.Elem(); ok {
+			p := path + "[_]"
+			n.elem = c.build(nil, t, p, stack)
+		}
+	}
+	return n
+}
+
+func (c *Coverage) newItem(k Kind, path string, v cue.Value) *Item {
+	item := &Item{Path: path, Kind: k, Pos: v.Pos()}
+	if k == Disjunct {
+		switch v.IncompleteKind() {
+		case cue.StructKind:
+			item.Value = "{...}"
+		case cue.ListKind:
+			item.Value = "[...]"
+		default:
+			item.Value = fmt.Sprint(v)
+		}
+	}
+	c.items = append(c.items, item)
+	return item
+}
+
+// Add records the parts of the schema exercised by the data value v.
+func (c *Coverage) Add(v cue.Value) {
+	c.add(c.root, v)
+}
+
+func (c *Coverage) add(n *node, v cue.Value) {
+	if n.item != nil {
+		n.item.Hits++
+	}
+	for _, d := range n.disjuncts {
+		if d.v.Unify(v).Validate(cue.Concrete(true)) == nil {
+			c.add(d, v)
+		}
+	}
+
+	switch v.Kind() {
+	case cue.StructKind:
+		iter, _ := v.Fields()
+		for iter.Next() {
+			if f, ok := n.fields[iter.Selector().Unquoted()]; ok {
+				c.add(f, iter.Value())
+			} else if n.pattern != nil {
+				c.add(n.pattern, iter.Value())
+			}
+		}
+
+	case cue.ListKind:
+		if n.elem == nil {
+			return
+		}
+		iter, _ := v.List()
+		for iter.Next() {
+			c.add(n.elem, iter.Value())
+		}
+	}
+}
+
+// Report returns the coverage recorded so far.
+func (c *Coverage) Report() *Report {
+	return &Report{Items: c.items}
+}
+
+// A Report reports the coverage of a schema.
+type Report struct {
+	// Items holds all items of the schema in the order in which they are
+	// declared.
+	Items []*Item
+}
+
+// Covered returns the number of items that were exercised at least once.
+func (r *Report) Covered() int {
+	n := 0
+	for _, item := range r.Items {
+		if item.Hits > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Uncovered returns the items that were never exercised.
+func (r *Report) Uncovered() []*Item {
+	var a []*Item
+	for _, item := range r.Items {
+		if item.Hits == 0 {
+			a = append(a, item)
+		}
+	}
+	return a
+}
+
+// A Config configures the output of a Report.
+type Config struct {
+	// Cwd is the current working directory. Filename positions are taken
+	// relative to this path.
+	Cwd string
+
+	// ToSlash sets whether to use Unix paths. Mostly used for testing.
+	ToSlash bool
+}
+
+func (c *Config) pos(pos token.Pos) string {
+	if !pos.IsValid() {
+		return "-"
+	}
+	p := pos.Position()
+	s := p.Filename
+	if c != nil && c.Cwd != "" {
+		if rel, err := filepath.Rel(c.Cwd, s); err == nil {
+			s = rel
+			if !strings.HasPrefix(s, ".") {
+				s = "." + string(filepath.Separator) + s
+			}
+		}
+	}
+	if c != nil && c.ToSlash {
+		s = filepath.ToSlash(s)
+	}
+	return fmt.Sprintf("%s:%d:%d", s, p.Line, p.Column)
+}
+
+// WriteText writes a summary of the report, followed by the items that
+// were never exercised, to w. The configuration may be nil.
+func (r *Report) WriteText(w io.Writer, cfg *Config) error {
+	percent := 100.0
+	if len(r.Items) > 0 {
+		percent = 100 * float64(r.Covered()) / float64(len(r.Items))
+	}
+	_, err := fmt.Fprintf(w, "coverage: %d of %d schema items exercised (%.1f%%)\n",
+		r.Covered(), len(r.Items), percent)
+	if err != nil {
+		return err
+	}
+	for _, item := range r.Uncovered() {
+		var msg string
+		switch item.Kind {
+		case Field:
+			msg = "field never set"
+		case Disjunct:
+			msg = fmt.Sprintf("disjunct %s never matched", item.Value)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s\n", cfg.pos(item.Pos), item.Path, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes all items of the report as JSON to w. The configuration
+// may be nil.
+func (r *Report) WriteJSON(w io.Writer, cfg *Config) error {
+	type jsonItem struct {
+		Path  string `json:"path"`
+		Kind  Kind   `json:"kind"`
+		Value string `json:"value,omitempty"`
+		Pos   string `json:"pos"`
+		Hits  int    `json:"hits"`
+	}
+	items := make([]jsonItem, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = jsonItem{
+			Path:  item.Path,
+			Kind:  item.Kind,
+			Value: item.Value,
+			Pos:   cfg.pos(item.Pos),
+			Hits:  item.Hits,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(struct {
+		Covered int        `json:"covered"`
+		Total   int        `json:"total"`
+		Items   []jsonItem `json:"items"`
+	}{r.Covered(), len(r.Items), items})
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverage_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/coverage"
+)
+
+func TestCoverage(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(`
+	#Server: {
+		name:  string
+		port?: int
+		mode:  "dev" | "prod"
+		tls?:  #TLS | null
+		labels?: [string]: string
+		tags?: [...string]
+	}
+	#TLS: {
+		cert: string
+		next?: #TLS
+	}
+	`, cue.Filename("schema.cue"))
+	qt.Assert(t, qt.IsNil(v.Err()))
+
+	c := coverage.New(v.LookupPath(cue.ParsePath("#Server")))
+	for _, data := range []string{
+		`{name: "a", mode: "dev", labels: x: "y"}`,
+		`{name: "b", mode: "dev", tls: cert: "c", tags: ["x"]}`,
+	} {
+		c.Add(ctx.CompileString(data))
+	}
+
+	var b strings.Builder
+	err := c.Report().WriteText(&b, nil)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(b.String(), `coverage: 9 of 13 schema items exercised (69.2%)
+schema.cue:4:3: #Server.port?: field never set
+schema.cue:5:18: #Server.mode: disjunct "prod" never matched
+schema.cue:6:17: #Server.tls?: disjunct null never matched
+schema.cue:12:3: #Server.tls?.next?: field never set
+`))
+}
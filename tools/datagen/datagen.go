@@ -0,0 +1,574 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package datagen generates concrete data values that satisfy a schema,
+// for use as test fixtures or to fuzz systems that consume such data.
+//
+// Values are generated by walking the schema. Numbers respect the bounds
+// of the schema, strings are generated to match its regular expressions
+// and rune limits, enumerations and other disjunctions are decided by
+// picking one of the disjuncts, and lists respect the limits set with
+// list.MinItems and list.MaxItems. Fields are generated in order, so that
+// a field constrained in terms of an earlier field, such as
+//
+//	max: int & >min
+//
+// respects the value generated for that field. Constraints that the
+// generator does not understand are satisfied by chance, if at all:
+// each generated value is checked against the schema, and generation is
+// retried a number of times before giving up.
+package datagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A Config configures a Generator.
+type Config struct {
+	// Seed seeds the random number generator. Generators with the same
+	// seed generate the same values for the same schema.
+	Seed int64
+
+	// MaxDepth is the nesting depth beyond which the generator avoids
+	// growing values: optional fields are omitted, lists are kept as short
+	// as possible, and scalar disjuncts are preferred over structs and
+	// lists. It bounds the size of values of recursive schemas.
+	// The default is 4.
+	MaxDepth int
+
+	// MaxListLen is the number of elements by which a generated list
+	// may exceed its minimum length. The default is 3.
+	MaxListLen int
+}
+
+// A Generator generates concrete values for schemas.
+type Generator struct {
+	cfg  Config
+	rand *rand.Rand
+
+	// exhaustive is set while generating values for Exhaustive. In this
+	// mode, choice points pick the option indexed by pass, rather than a
+	// random one.
+	exhaustive bool
+	pass       int
+	width      int // maximum number of options of a choice point in pass
+}
+
+// maxAttempts is the number of times a value is generated before giving
+// up on finding one that satisfies the schema.
+const maxAttempts = 20
+
+// New returns a Generator for the given configuration, which may be nil.
+func New(cfg *Config) *Generator {
+	g := &Generator{}
+	if cfg != nil {
+		g.cfg = *cfg
+	}
+	if g.cfg.MaxDepth <= 0 {
+		g.cfg.MaxDepth = 4
+	}
+	if g.cfg.MaxListLen <= 0 {
+		g.cfg.MaxListLen = 3
+	}
+	g.rand = rand.New(rand.NewSource(g.cfg.Seed))
+	return g
+}
+
+// Generate returns a random concrete value that satisfies v.
+func (g *Generator) Generate(v cue.Value) (cue.Value, error) {
+	g.exhaustive = false
+	var errs errors.Error
+	for i := 0; i < maxAttempts; i++ {
+		x, err := g.generate(v)
+		if err == nil {
+			return x, nil
+		}
+		errs = errors.Promote(err, "")
+	}
+	return cue.Value{}, errors.Wrap(errors.Newf(v.Pos(),
+		"could not generate a value satisfying the schema"), errs)
+}
+
+// Exhaustive returns a set of concrete values that satisfy v and that
+// together exercise each of its choices: every disjunct of a disjunction,
+// the presence and absence of optional fields, and the bounds of numbers
+// and list lengths. Choices nested within other choices are only
+// exercised in combination with some of the options of the enclosing
+// choice, so the set is not exhaustive in the strict sense; it is
+// intended as a small set of varied fixtures.
+func (g *Generator) Exhaustive(v cue.Value) ([]cue.Value, error) {
+	g.exhaustive = true
+	defer func() { g.exhaustive = false }()
+
+	var values []cue.Value
+	var errs errors.Error
+	seen := map[string]bool{}
+	for g.pass = 0; ; g.pass++ {
+		g.width = 1
+		x, err := g.generate(v)
+		if err != nil {
+			errs = errors.Append(errs, errors.Promote(err, ""))
+		} else if b, err := json.Marshal(x); err == nil && !seen[string(b)] {
+			seen[string(b)] = true
+			values = append(values, x)
+		}
+		if g.pass+1 >= g.width {
+			break
+		}
+	}
+	if len(values) == 0 {
+		return nil, errors.Wrap(errors.Newf(v.Pos(),
+			"could not generate a value satisfying the schema"), errs)
+	}
+	return values, nil
+}
+
+// generate generates a value for v and checks it against v.
+func (g *Generator) generate(v cue.Value) (cue.Value, error) {
+	x, err := g.value(v, 0)
+	if err != nil {
+		return cue.Value{}, err
+	}
+	x = v.Unify(x)
+	if err := x.Validate(cue.Concrete(true)); err != nil {
+		return cue.Value{}, err
+	}
+	return x, nil
+}
+
+// choose returns a number in [0, n) selecting one of n options.
+func (g *Generator) choose(n int) int {
+	if !g.exhaustive {
+		return g.rand.Intn(n)
+	}
+	if n > g.width {
+		g.width = n
+	}
+	return g.pass % n
+}
+
+func (g *Generator) value(v cue.Value, depth int) (cue.Value, error) {
+	if err := v.Err(); err != nil {
+		return cue.Value{}, err
+	}
+
+	if op, args := cue.Dereference(v).Expr(); op == cue.OrOp {
+		if depth >= g.cfg.MaxDepth {
+			var scalars []cue.Value
+			for _, a := range args {
+				if !isComposite(a.IncompleteKind()) {
+					scalars = append(scalars, a)
+				}
+			}
+			if len(scalars) > 0 {
+				args = scalars
+			}
+		}
+		return g.value(args[g.choose(len(args))], depth)
+	}
+
+	k := v.IncompleteKind()
+	if v.IsConcrete() && !isComposite(k) {
+		return v, nil
+	}
+
+	var kinds []cue.Kind
+	for _, x := range []cue.Kind{
+		cue.NullKind, cue.BoolKind, cue.IntKind, cue.FloatKind,
+		cue.StringKind, cue.BytesKind, cue.StructKind, cue.ListKind,
+	} {
+		if k&x != 0 {
+			kinds = append(kinds, x)
+		}
+	}
+	if k&cue.NumberKind == cue.NumberKind {
+		// Prefer integers for values of type number.
+		kinds = removeKinds(kinds, cue.FloatKind)
+	}
+	if depth >= g.cfg.MaxDepth {
+		if a := removeKinds(kinds, compositeKinds); len(a) > 0 {
+			kinds = a
+		}
+	}
+	if len(kinds) == 0 {
+		return cue.Value{}, errors.Newf(v.Pos(), "cannot generate a value of type %v", k)
+	}
+	k = kinds[g.choose(len(kinds))]
+
+	switch k {
+	case cue.StructKind:
+		return g.structValue(v, depth)
+	case cue.ListKind:
+		return g.listValue(v, depth)
+	}
+
+	c := &constraints{minLen: -1, maxLen: -1}
+	c.add(v)
+	exhaustive := g.exhaustive
+	defer func() { g.exhaustive = exhaustive }()
+	var err error
+	for i := 0; i < maxAttempts; i++ {
+		if i > 0 {
+			// The choices made failed to produce a valid value. Retry
+			// with random ones.
+			g.exhaustive = false
+		}
+		var expr ast.Expr
+		switch k {
+		case cue.NullKind:
+			expr = ast.NewNull()
+		case cue.BoolKind:
+			expr = ast.NewBool(g.choose(2) == 0)
+		case cue.IntKind:
+			expr, err = g.intLit(c)
+		case cue.FloatKind:
+			expr, err = g.floatLit(c)
+		case cue.StringKind:
+			var s string
+			s, err = g.stringValue(c)
+			expr = ast.NewString(s)
+		case cue.BytesKind:
+			expr = &ast.BasicLit{
+				Kind:  token.STRING,
+				Value: "'" + g.letters(g.length(c)) + "'",
+			}
+		}
+		if err != nil {
+			return cue.Value{}, errors.Wrapf(err, v.Pos(), "cannot generate a value for %v", v.Path())
+		}
+		x := v.Context().BuildExpr(expr)
+		if err = v.Unify(x).Validate(cue.Concrete(true)); err == nil {
+			return x, nil
+		}
+	}
+	return cue.Value{}, err
+}
+
+func (g *Generator) structValue(v cue.Value, depth int) (cue.Value, error) {
+	top := v.Context().BuildExpr(ast.NewIdent("_"))
+	w := v
+	iter, err := v.Fields(cue.Optional(true))
+	if err != nil {
+		return cue.Value{}, err
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		p := cue.MakePath(cue.Str(sel.Unquoted()))
+		f := w.LookupPath(cue.MakePath(sel))
+		if sel.ConstraintType() == cue.OptionalConstraint {
+			if depth >= g.cfg.MaxDepth || g.choose(2) == 1 {
+				continue
+			}
+			if f.Err() != nil {
+				// Looking up an optional field of a recursive definition
+				// results in a structural cycle. Make it a regular field
+				// first.
+				f = w.FillPath(p, top).LookupPath(p)
+			}
+		}
+		if isDerived(f) {
+			// The value follows from that of other fields.
+			continue
+		}
+		x, err := g.value(f, depth+1)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		w = w.FillPath(p, x)
+	}
+
+	// Add fields for pattern constraints, if the labels we pick are
+	// allowed.
+	if t, ok := v.Elem(); ok && depth < g.cfg.MaxDepth {
+		n := g.choose(g.cfg.MaxListLen + 1)
+		for i := 0; i < n; i++ {
+			p := cue.MakePath(cue.Str("key" + strconv.Itoa(i)))
+			if w.LookupPath(p).Exists() {
+				continue
+			}
+			x, err := g.value(t, depth+1)
+			if err != nil {
+				continue
+			}
+			if u := w.FillPath(p, x); u.Err() == nil {
+				w = u
+			}
+		}
+	}
+	return w, nil
+}
+
+func (g *Generator) listValue(v cue.Value, depth int) (cue.Value, error) {
+	c := &constraints{minLen: -1, maxLen: -1}
+	c.add(v)
+
+	// The minimum length of the list is given by its prefix elements.
+	prefix := 0
+	length := v.Len()
+	if n, err := length.Int64(); err == nil {
+		prefix = int(n)
+	} else {
+		lc := &constraints{}
+		lc.add(length)
+		if lc.hasMin {
+			prefix = int(lc.min)
+		}
+	}
+	elem, open := v.Elem()
+
+	n := prefix
+	if open {
+		min := prefix
+		if c.minLen > min {
+			min = c.minLen
+		}
+		max := min + g.cfg.MaxListLen
+		if c.maxLen >= 0 && c.maxLen < max {
+			max = c.maxLen
+		}
+		switch {
+		case max < min:
+			return cue.Value{}, errors.Newf(v.Pos(), "conflicting list length constraints")
+		case depth >= g.cfg.MaxDepth:
+			n = min
+		default:
+			switch g.choose(3) {
+			case 0:
+				n = min + g.rand.Intn(max-min+1)
+			case 1:
+				n = min
+			case 2:
+				n = max
+			}
+		}
+	}
+
+	a := make([]cue.Value, n)
+	for i := range a {
+		t := elem
+		if i < prefix {
+			t = v.LookupPath(cue.MakePath(cue.Index(i)))
+		}
+		x, err := g.value(t, depth+1)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		a[i] = x
+	}
+	return v.Context().NewList(a...), nil
+}
+
+func (g *Generator) intLit(c *constraints) (ast.Expr, error) {
+	lo, hi := c.bounds()
+	if c.hasMin {
+		if c.minExcl && lo == math.Floor(lo) {
+			lo++
+		}
+		lo = math.Ceil(lo)
+	}
+	if c.hasMax {
+		if c.maxExcl && hi == math.Floor(hi) {
+			hi--
+		}
+		hi = math.Floor(hi)
+	}
+	if lo > hi {
+		return nil, errors.Newf(token.NoPos, "empty range of integers")
+	}
+	x := lo + math.Floor(g.rand.Float64()*(hi-lo+1))
+	switch g.choose(3) {
+	case 1:
+		if c.hasMin {
+			x = lo
+		}
+	case 2:
+		if c.hasMax {
+			x = hi
+		}
+	}
+	return ast.NewLit(token.INT, strconv.FormatFloat(x, 'f', 0, 64)), nil
+}
+
+func (g *Generator) floatLit(c *constraints) (ast.Expr, error) {
+	lo, hi := c.bounds()
+	if lo > hi {
+		return nil, errors.Newf(token.NoPos, "empty range of numbers")
+	}
+	x := math.Round((lo+g.rand.Float64()*(hi-lo))*100) / 100
+	switch g.choose(3) {
+	case 1:
+		if c.hasMin && !c.minExcl {
+			x = lo
+		}
+	case 2:
+		if c.hasMax && !c.maxExcl {
+			x = hi
+		}
+	}
+	s := strconv.FormatFloat(x, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return ast.NewLit(token.FLOAT, s), nil
+}
+
+func (g *Generator) stringValue(c *constraints) (string, error) {
+	if len(c.patterns) > 0 {
+		return g.regexpString(c.patterns[g.rand.Intn(len(c.patterns))])
+	}
+	return g.letters(g.length(c)), nil
+}
+
+// length returns a length for a string or bytes value.
+func (g *Generator) length(c *constraints) int {
+	min, max := 1, 8
+	if c.minLen >= 0 {
+		min = c.minLen
+		if max < min {
+			max = min + 8
+		}
+	}
+	if c.maxLen >= 0 {
+		max = c.maxLen
+		if min > max {
+			min = max
+		}
+	}
+	switch g.choose(3) {
+	case 1:
+		return min
+	case 2:
+		return max
+	}
+	return min + g.rand.Intn(max-min+1)
+}
+
+func (g *Generator) letters(n int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[g.rand.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+// constraints holds the constraints of a scalar or list value that guide
+// the generation of its values.
+type constraints struct {
+	hasMin, minExcl bool
+	min             float64
+	hasMax, maxExcl bool
+	max             float64
+
+	patterns []string // regular expressions strings must match
+
+	// minLen and maxLen are the bounds on the number of runes of a
+	// string or elements of a list, or -1 if not set.
+	minLen, maxLen int
+}
+
+// maxMagnitude bounds the magnitude of generated numbers, beyond which
+// float64 can no longer represent all integers.
+const maxMagnitude = 1 << 50
+
+// bounds returns the range from which to pick numbers.
+func (c *constraints) bounds() (lo, hi float64) {
+	const span = 100
+	lo, hi = 0, span
+	switch {
+	case c.hasMin && c.hasMax:
+		lo, hi = c.min, c.max
+	case c.hasMin:
+		lo, hi = c.min, c.min+span
+	case c.hasMax:
+		lo, hi = c.max-span, c.max
+	}
+	return math.Max(lo, -maxMagnitude), math.Min(hi, maxMagnitude)
+}
+
+// add adds the constraints of v, which are its conjuncts.
+func (c *constraints) add(v cue.Value) {
+	op, args := cue.Dereference(v).Expr()
+	switch op {
+	case cue.AndOp:
+		for _, a := range args {
+			c.add(a)
+		}
+	case cue.GreaterThanOp, cue.GreaterThanEqualOp:
+		if x, err := args[0].Float64(); err == nil && (!c.hasMin || x > c.min) {
+			c.hasMin, c.min, c.minExcl = true, x, op == cue.GreaterThanOp
+		}
+	case cue.LessThanOp, cue.LessThanEqualOp:
+		if x, err := args[0].Float64(); err == nil && (!c.hasMax || x < c.max) {
+			c.hasMax, c.max, c.maxExcl = true, x, op == cue.LessThanOp
+		}
+	case cue.RegexMatchOp:
+		if s, err := args[0].String(); err == nil {
+			c.patterns = append(c.patterns, s)
+		}
+	case cue.CallOp:
+		if len(args) != 2 {
+			return
+		}
+		n, err := args[1].Int64()
+		if err != nil {
+			return
+		}
+		switch fmt.Sprint(args[0]) {
+		case "strings.MinRunes", "list.MinItems":
+			c.minLen = int(n)
+		case "strings.MaxRunes", "list.MaxItems":
+			c.maxLen = int(n)
+		}
+	}
+}
+
+const compositeKinds = cue.StructKind | cue.ListKind
+
+func isComposite(k cue.Kind) bool {
+	return k&compositeKinds != 0
+}
+
+// isDerived reports whether v is computed from other values, rather than
+// being a constraint for which to generate a value.
+func isDerived(v cue.Value) bool {
+	switch op, _ := v.Expr(); op {
+	case cue.AddOp, cue.SubtractOp, cue.MultiplyOp, cue.FloatQuotientOp,
+		cue.IntQuotientOp, cue.IntRemainderOp, cue.IntDivideOp, cue.IntModuloOp,
+		cue.InterpolationOp, cue.IndexOp, cue.SliceOp:
+		return true
+	}
+	return false
+}
+
+// removeKinds returns kinds without the kinds in k.
+func removeKinds(kinds []cue.Kind, k cue.Kind) []cue.Kind {
+	var a []cue.Kind
+	for _, x := range kinds {
+		if x&k == 0 {
+			a = append(a, x)
+		}
+	}
+	return a
+}
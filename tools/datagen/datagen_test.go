@@ -0,0 +1,120 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datagen_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/datagen"
+)
+
+const schema = `
+import (
+	"list"
+	"strings"
+)
+
+#Server: {
+	name:   =~"^[a-z][a-z0-9-]{2,10}$"
+	port?:  int & >0 & <65536
+	mode:   "dev" | "prod" | "test"
+	ratio:  float & >=0 & <=1
+	min:    int
+	max:    int & >min
+	total:  min + max
+	nick:   strings.MinRunes(3) & strings.MaxRunes(5)
+	tags:   list.MaxItems(2) & [...string]
+	labels: [string]: string
+	tls:    #TLS | null
+}
+
+#TLS: {
+	cert:  string
+	next?: #TLS
+}
+`
+
+func TestGenerate(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(schema).LookupPath(cue.ParsePath("#Server"))
+	qt.Assert(t, qt.IsNil(v.Err()))
+
+	g := datagen.New(&datagen.Config{Seed: 1})
+	var first []string
+	for i := 0; i < 20; i++ {
+		x, err := g.Generate(v)
+		qt.Assert(t, qt.IsNil(err))
+		qt.Assert(t, qt.IsNil(v.Unify(x).Validate(cue.Concrete(true))))
+		b, err := json.Marshal(x)
+		qt.Assert(t, qt.IsNil(err))
+		first = append(first, string(b))
+	}
+
+	// The same seed yields the same values.
+	g = datagen.New(&datagen.Config{Seed: 1})
+	for _, want := range first {
+		x, err := g.Generate(v)
+		qt.Assert(t, qt.IsNil(err))
+		b, err := json.Marshal(x)
+		qt.Assert(t, qt.IsNil(err))
+		qt.Assert(t, qt.Equals(string(b), want))
+	}
+}
+
+func TestExhaustive(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(schema).LookupPath(cue.ParsePath("#Server"))
+
+	a, err := datagen.New(nil).Exhaustive(v)
+	qt.Assert(t, qt.IsNil(err))
+
+	modes := map[string]bool{}
+	var hasPort, noPort, hasTLS, nullTLS, maxPort bool
+	for _, x := range a {
+		qt.Assert(t, qt.IsNil(v.Unify(x).Validate(cue.Concrete(true))))
+		mode, _ := x.LookupPath(cue.ParsePath("mode")).String()
+		modes[mode] = true
+		port := x.LookupPath(cue.ParsePath("port"))
+		if port.Exists() {
+			hasPort = true
+			n, _ := port.Int64()
+			maxPort = maxPort || n == 65535
+		} else {
+			noPort = true
+		}
+		if x.LookupPath(cue.ParsePath("tls")).Null() == nil {
+			nullTLS = true
+		} else {
+			hasTLS = true
+		}
+	}
+	qt.Check(t, qt.DeepEquals(modes, map[string]bool{"dev": true, "prod": true, "test": true}))
+	qt.Check(t, qt.IsTrue(hasPort && noPort))
+	qt.Check(t, qt.IsTrue(hasTLS && nullTLS))
+	qt.Check(t, qt.IsTrue(maxPort))
+}
+
+func TestUnsatisfiable(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(`int & >10 & <5`)
+
+	_, err := datagen.New(nil).Generate(v)
+	qt.Assert(t, qt.ErrorMatches(err, `could not generate a value satisfying the schema.*`))
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datagen
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// maxRepeat is the number of repetitions beyond the minimum generated
+// for unbounded repetitions, such as x* and x+.
+const maxRepeat = 3
+
+// regexpString returns a string that matches the regular expression re.
+func (g *Generator) regexpString(re string) (string, error) {
+	r, err := syntax.Parse(re, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	g.writeRegexp(&b, r.Simplify())
+	return b.String(), nil
+}
+
+func (g *Generator) writeRegexp(b *strings.Builder, r *syntax.Regexp) {
+	switch r.Op {
+	case syntax.OpLiteral:
+		for _, c := range r.Rune {
+			if r.Flags&syntax.FoldCase != 0 && g.rand.Intn(2) == 0 {
+				c = unicode.SimpleFold(c)
+			}
+			b.WriteRune(c)
+		}
+
+	case syntax.OpCharClass:
+		b.WriteRune(g.classRune(r.Rune))
+
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		b.WriteByte(byte('a' + g.rand.Intn(26)))
+
+	case syntax.OpCapture:
+		g.writeRegexp(b, r.Sub[0])
+
+	case syntax.OpConcat:
+		for _, s := range r.Sub {
+			g.writeRegexp(b, s)
+		}
+
+	case syntax.OpAlternate:
+		g.writeRegexp(b, r.Sub[g.rand.Intn(len(r.Sub))])
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := r.Min, r.Max
+		switch r.Op {
+		case syntax.OpStar:
+			min, max = 0, maxRepeat
+		case syntax.OpPlus:
+			min, max = 1, 1+maxRepeat
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + maxRepeat
+		}
+		n := min + g.rand.Intn(max-min+1)
+		for i := 0; i < n; i++ {
+			g.writeRegexp(b, r.Sub[0])
+		}
+	}
+	// Other operators, such as anchors and word boundaries, match the
+	// empty string.
+}
+
+// classRune returns a rune from the character class given as a list of
+// ranges. Printable ASCII characters are preferred, if the class has any.
+func (g *Generator) classRune(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) == 0 {
+		return 'a'
+	}
+	i := 2 * g.rand.Intn(len(ranges)/2)
+	lo, hi := ranges[i], ranges[i+1]
+	return lo + rune(g.rand.Intn(int(hi-lo)+1))
+}
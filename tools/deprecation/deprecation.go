@@ -0,0 +1,212 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deprecation finds the places where configurations set fields
+// that their schema marks as deprecated.
+//
+// A field is marked as deprecated with a @deprecated attribute. The
+// attribute may hold a message explaining the deprecation, and the path of
+// the field replacing it, relative to the struct of the deprecated field:
+//
+//	#Service: {
+//		// Deprecated: use scale.replicas.
+//		replicas?: int @deprecated(replacement=scale.replicas)
+//		scale?: replicas?: int
+//
+//		legacy?: bool @deprecated("no longer has any effect")
+//	}
+//
+// A deprecated field is used if it is a regular field and at least one of
+// its declarations is not marked as deprecated, which is the case when a
+// configuration sets a field that a schema declares as optional.
+package deprecation
+
+import (
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/value"
+)
+
+// A Field is a deprecated field, along with the places where it is used.
+type Field struct {
+	// Label is the label of the field.
+	Label string
+
+	// Message holds the explanation given for the deprecation, if any.
+	Message string
+
+	// Replacement holds the path of the field that replaces the deprecated
+	// field, if any.
+	Replacement string
+
+	// Pos is the position of the @deprecated attribute.
+	Pos token.Pos
+
+	// Uses holds the paths of the values that use the field, in the order
+	// in which they were found.
+	Uses []cue.Path
+
+	// UsePos holds the positions at which the field is set, for each of
+	// its uses.
+	UsePos []token.Pos
+}
+
+// Summary returns a one-line description of the deprecation of f.
+func (f *Field) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "field %s is deprecated", f.Label)
+	if f.Message != "" {
+		fmt.Fprintf(&b, ": %s", f.Message)
+	}
+	if f.Replacement != "" {
+		fmt.Fprintf(&b, "; use %s instead", f.Replacement)
+	}
+	return b.String()
+}
+
+// A Checker collects the uses of deprecated fields across values. The
+// uses of a field are aggregated by the attribute marking it as deprecated,
+// so that a field of a schema that is used by many values, or by many
+// elements of a list, is reported once.
+type Checker struct {
+	fields []*Field
+	index  map[token.Pos]*Field
+	uses   int
+}
+
+// NewChecker returns a new Checker.
+func NewChecker() *Checker {
+	return &Checker{index: map[token.Pos]*Field{}}
+}
+
+// Check returns the deprecated fields used in v and the values nested
+// within it.
+func Check(v cue.Value) []*Field {
+	c := NewChecker()
+	c.Add(v)
+	return c.Fields()
+}
+
+// Add records the uses of deprecated fields in v and the values nested
+// within it, and reports whether there are any. Definitions, hidden fields
+// and optional fields are not traversed.
+func (c *Checker) Add(v cue.Value) bool {
+	n := c.uses
+	c.add(v)
+	return c.uses > n
+}
+
+func (c *Checker) add(v cue.Value) {
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := v.Fields()
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			c.addField(iter.Selector(), iter.Value())
+			c.add(iter.Value())
+		}
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			c.add(iter.Value())
+		}
+	}
+}
+
+// Fields returns the deprecated fields used in the values added so far, in
+// the order in which they were first found.
+func (c *Checker) Fields() []*Field {
+	return c.fields
+}
+
+// addField records a use of the field with the given selector and value if
+// the field is deprecated.
+func (c *Checker) addField(sel cue.Selector, v cue.Value) {
+	_, vertex := value.ToInternal(v)
+	var attr *ast.Attribute
+	var usePos token.Pos
+	used := false
+	for _, x := range vertex.Conjuncts {
+		a, f := deprecatedAttr(x)
+		switch {
+		case a != nil:
+			if attr == nil {
+				attr = a
+			}
+		case !used:
+			used = true
+			if f != nil {
+				usePos = f.Pos()
+			} else {
+				usePos = x.Source().Pos()
+			}
+		}
+	}
+	if attr == nil || !used {
+		return
+	}
+	f := c.index[attr.Pos()]
+	if f == nil {
+		f = newField(sel, attr)
+		c.index[attr.Pos()] = f
+		c.fields = append(c.fields, f)
+	}
+	f.Uses = append(f.Uses, v.Path())
+	f.UsePos = append(f.UsePos, usePos)
+	c.uses++
+}
+
+// deprecatedAttr returns the field declaring the conjunct x, if any, and
+// its @deprecated attribute, if any.
+func deprecatedAttr(x adt.Conjunct) (*ast.Attribute, *ast.Field) {
+	f, ok := x.Field().Source().(*ast.Field)
+	if !ok {
+		return nil, nil
+	}
+	for _, a := range f.Attrs {
+		if key, _ := a.Split(); key == "deprecated" {
+			return a, f
+		}
+	}
+	return nil, f
+}
+
+func newField(sel cue.Selector, attr *ast.Attribute) *Field {
+	f := &Field{
+		Label: sel.String(),
+		Pos:   attr.Pos(),
+	}
+	_, body := attr.Split()
+	a := internal.ParseAttrBody(attr.Pos(), body)
+	for i, kv := range a.Fields {
+		switch {
+		case kv.Key() == "replacement":
+			f.Replacement = kv.Value()
+		case kv.Key() == "" && i == 0:
+			f.Message = kv.Value()
+		}
+	}
+	return f
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deprecation_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/deprecation"
+)
+
+const schema = `
+#Service: {
+	replicas?: int @deprecated(replacement=scale.replicas)
+	scale?: replicas?: int
+
+	legacy?: bool @deprecated("no longer has any effect")
+	kind: string | *"web" @deprecated()
+}
+`
+
+const data = `
+a: #Service & {
+	replicas: 2
+}
+b: #Service & {
+	scale: replicas: 3
+}
+list: [...#Service] & [{replicas: 1}, {legacy: true, replicas: 4}]
+`
+
+func TestCheck(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(schema+data, cue.Filename("x.cue"))
+	qt.Assert(t, qt.IsNil(v.Err()))
+
+	var got []string
+	for _, f := range deprecation.Check(v) {
+		got = append(got, fmt.Sprintf("%v %s %v %v", f.Pos, f.Summary(), f.Uses, f.UsePos))
+	}
+	qt.Assert(t, qt.DeepEquals(got, []string{
+		"x.cue:3:17 field replicas is deprecated; use scale.replicas instead [a.replicas list[0].replicas list[1].replicas] [x.cue:11:2 x.cue:16:25 x.cue:16:54]",
+		"x.cue:6:16 field legacy is deprecated: no longer has any effect [list[1].legacy] [x.cue:16:40]",
+	}))
+}
+
+func TestCheckerAcrossValues(t *testing.T) {
+	ctx := cuecontext.New()
+	s := ctx.CompileString(schema, cue.Filename("schema.cue")).LookupPath(cue.ParsePath("#Service"))
+	c := deprecation.NewChecker()
+	for i, src := range []string{`{"replicas": 1}`, `{"legacy": false}`, `{"replicas": 2}`} {
+		d := ctx.CompileString(src, cue.Filename(fmt.Sprintf("data%d.json", i)))
+		c.Add(s.Unify(d))
+	}
+	var got []string
+	for _, f := range c.Fields() {
+		got = append(got, fmt.Sprintf("%s %v", f.Label, f.UsePos))
+	}
+	qt.Assert(t, qt.DeepEquals(got, []string{
+		"replicas [data0.json:1:2 data2.json:1:2]",
+		"legacy [data1.json:1:2]",
+	}))
+}
@@ -0,0 +1,199 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package examples finds and validates the examples that schemas provide
+// for their definitions.
+//
+// An example can be attached to a definition in two ways. The first is an
+// @example attribute on the definition, of which the contents is a CUE
+// expression. The expression may refer to the definitions and fields in
+// scope of the definition.
+//
+//	// Port is a TCP or UDP port number.
+//	#Port: int & >0 & <65536 @example(8080) @example(443)
+//
+// The second is a field named examples within a struct definition, of
+// which the value is a list of concrete examples.
+//
+//	#Endpoint: {
+//		host: string
+//		port: #Port
+//
+//		examples: [{host: "localhost", port: 8080}]
+//	}
+//
+// Examples are validated by unifying them with their definition and
+// checking that the result is concrete and free of errors.
+package examples
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/export"
+	"cuelang.org/go/internal/value"
+)
+
+// An Example is an example value for a definition.
+type Example struct {
+	// Path is the path of the definition for which this is an example.
+	Path cue.Path
+
+	// Definition is the value of the definition.
+	Definition cue.Value
+
+	// Value is the example value.
+	Value cue.Value
+
+	// Pos is the position at which the example is defined.
+	Pos token.Pos
+}
+
+// Validate reports whether the example conforms to its definition.
+func (e *Example) Validate() error {
+	err := e.Value.Err()
+	if err == nil {
+		err = e.Definition.Unify(e.Value).Validate(cue.Concrete(true))
+	}
+	if err != nil {
+		return errors.Wrap(errors.Newf(e.Pos, "invalid example"), err)
+	}
+	return nil
+}
+
+// Find returns the examples attached to the definitions in v and in the
+// values nested within it, in the order in which they appear. It returns an
+// error if an @example attribute does not hold a valid CUE expression.
+func Find(v cue.Value) ([]*Example, error) {
+	f := &finder{seen: map[token.Pos]bool{}}
+	f.find(v)
+	return f.examples, f.errs
+}
+
+// Validate validates all examples attached to the definitions in v and in
+// the values nested within it. It returns an error for each example that
+// does not conform to its definition.
+func Validate(v cue.Value) error {
+	a, err := Find(v)
+	var errs errors.Error
+	if err != nil {
+		errs = errors.Promote(err, "")
+	}
+	for _, e := range a {
+		if err := e.Validate(); err != nil {
+			errs = errors.Append(errs, errors.Promote(err, ""))
+		}
+	}
+	return errs
+}
+
+type finder struct {
+	examples []*Example
+	errs     errors.Error
+
+	// seen records the positions of the examples found so far, as the same
+	// definition may be reachable through multiple fields.
+	seen map[token.Pos]bool
+}
+
+func (f *finder) add(e *Example) {
+	if e.Pos.IsValid() {
+		if f.seen[e.Pos] {
+			return
+		}
+		f.seen[e.Pos] = true
+	}
+	f.examples = append(f.examples, e)
+}
+
+// find collects the examples of the definitions in the struct v. Optional
+// fields are not traversed, as recursive definitions may nest infinitely
+// through them.
+func (f *finder) find(v cue.Value) {
+	if v.IncompleteKind() != cue.StructKind {
+		return
+	}
+	iter, err := v.Fields(cue.Definitions(true))
+	if err != nil {
+		return
+	}
+	for iter.Next() {
+		if iter.Selector().IsDefinition() {
+			f.addExamples(v, iter.Value())
+		}
+		f.find(iter.Value())
+	}
+}
+
+// addExamples adds the examples of the definition d declared in the struct
+// scope.
+func (f *finder) addExamples(scope, d cue.Value) {
+	_, vertex := value.ToInternal(d)
+	for _, a := range export.ExtractFieldAttrs(vertex) {
+		key, body := a.Split()
+		if key != "example" {
+			continue
+		}
+		pos := a.Pos()
+		if f.seen[pos] {
+			continue
+		}
+		expr, err := parser.ParseExpr(pos.Filename(), body)
+		if err != nil {
+			f.seen[pos] = true
+			err = errors.Wrap(errors.Newf(pos, "invalid @example attribute"), err)
+			f.errs = errors.Append(f.errs, errors.Promote(err, ""))
+			continue
+		}
+		// Positions within the attribute are relative to its body. Report
+		// them at the position of the attribute instead.
+		ast.Walk(expr, func(n ast.Node) bool {
+			ast.SetPos(n, pos)
+			return true
+		}, nil)
+		f.add(&Example{
+			Path:       d.Path(),
+			Definition: d,
+			Value:      d.Context().BuildExpr(expr, cue.Scope(scope)),
+			Pos:        pos,
+		})
+	}
+
+	if d.IncompleteKind() != cue.StructKind {
+		return
+	}
+	list := d.LookupPath(cue.MakePath(cue.Str("examples")))
+	iter, err := list.List()
+	if err != nil || !list.IsConcrete() {
+		return
+	}
+	for iter.Next() {
+		// The example is closed as it is declared within a definition.
+		// Rebuild it from its syntax so that it can be unified with the
+		// definition, which holds the examples field itself.
+		x := iter.Value()
+		expr, ok := x.Syntax(cue.Final()).(ast.Expr)
+		if !ok {
+			continue
+		}
+		f.add(&Example{
+			Path:       d.Path(),
+			Definition: d,
+			Value:      d.Context().BuildExpr(expr),
+			Pos:        x.Pos(),
+		})
+	}
+}
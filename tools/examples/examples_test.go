@@ -0,0 +1,112 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/tools/examples"
+)
+
+const schema = `
+#Port: int & >0 & <65536 @example(8080) @example(70000)
+
+#Endpoint: {
+	host: string
+	port: #Port
+
+	examples: [{host: "localhost", port: 80}, {host: "localhost"}]
+}
+
+#Service: {
+	name: string
+	endpoint: #Endpoint
+} @example({name: "web", endpoint: #Endpoint & {host: "x", port: 443}})
+
+nested: {
+	#Name: =~"^[a-z]+$" @example("Foo")
+	ref: #Service
+}
+`
+
+func TestFind(t *testing.T) {
+	v := cuecontext.New().CompileString(schema, cue.Filename("schema.cue"))
+	a, err := examples.Find(v)
+	qt.Assert(t, qt.IsNil(err))
+
+	var got []string
+	for _, e := range a {
+		got = append(got, fmt.Sprintf("%v %v %v", e.Pos, e.Path, e.Value))
+	}
+	qt.Assert(t, qt.DeepEquals(got, []string{
+		`schema.cue:2:26 #Port 8080`,
+		`schema.cue:2:41 #Port 70000`,
+		`schema.cue:8:13 #Endpoint {
+	host: "localhost"
+	port: 80
+}`,
+		`schema.cue:8:44 #Endpoint {
+	host: "localhost"
+}`,
+		`schema.cue:14:3 #Service {
+	name: "web"
+	endpoint: {
+		host: "x"
+		port: 443
+		examples: [{
+			host: "localhost"
+			port: 80
+		}, {
+			host: "localhost"
+		}]
+	}
+}`,
+		`schema.cue:17:22 nested.#Name "Foo"`,
+	}))
+}
+
+func TestValidate(t *testing.T) {
+	v := cuecontext.New().CompileString(schema, cue.Filename("schema.cue"))
+	err := examples.Validate(v)
+	qt.Assert(t, qt.Not(qt.IsNil(err)))
+
+	var got []string
+	for _, e := range errors.Errors(err) {
+		got = append(got, strings.TrimSpace(errors.Details(e, nil)))
+	}
+	qt.Assert(t, qt.DeepEquals(got, []string{
+		`#Port: invalid example: invalid value 70000 (out of bound <65536):
+    schema.cue:2:41
+    schema.cue:2:19`,
+		`#Endpoint.port: invalid example: incomplete value >0 & <65536 & int:
+    schema.cue:8:44`,
+		`nested.#Name: invalid example: invalid value "Foo" (out of bound =~"^[a-z]+$"):
+    schema.cue:17:22
+    schema.cue:17:9`,
+	}))
+}
+
+func TestInvalidAttribute(t *testing.T) {
+	v := cuecontext.New().CompileString(`#A: int @example(1 +)`, cue.Filename("a.cue"))
+	_, err := examples.Find(v)
+	qt.Assert(t, qt.ErrorMatches(err, `invalid @example attribute: .*`))
+}
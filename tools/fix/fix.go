@@ -24,6 +24,7 @@ import (
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/token"
 )
 
@@ -31,6 +32,13 @@ type Option func(*options)
 
 type options struct {
 	simplify bool
+	selected []string
+
+	// versioned is set by UpgradeVersion, in which case only fixers with
+	// a version v such that fromVersion < v <= toVersion are applied.
+	versioned   bool
+	fromVersion string
+	toVersion   string
 }
 
 // Simplify enables fixes that simplify the code, but are not strictly
@@ -40,12 +48,22 @@ func Simplify() Option {
 }
 
 // File applies fixes to f and returns it. It alters the original f.
+//
+// By default all registered fixers that are not optional are applied.
+// Use [Select] to apply a specific set of fixers.
 func File(f *ast.File, o ...Option) *ast.File {
 	var options options
 	for _, f := range o {
 		f(&options)
 	}
 
+	for _, fixer := range options.fixers() {
+		f = fixer.Fn(f)
+	}
+	return f
+}
+
+func rewriteIntDiv(f *ast.File) *ast.File {
 	// Rewrite integer division operations to use builtins.
 	f = astutil.Apply(f, func(c astutil.Cursor) bool {
 		n := c.Node()
@@ -63,7 +81,10 @@ func File(f *ast.File, o ...Option) *ast.File {
 		}
 		return true
 	}, nil).(*ast.File)
+	return f
+}
 
+func rewriteBlockComments(f *ast.File) *ast.File {
 	// Rewrite block comments to regular comments.
 	ast.Walk(f, func(n ast.Node) bool {
 		switch x := n.(type) {
@@ -132,9 +153,37 @@ func File(f *ast.File, o ...Option) *ast.File {
 	// 	return true
 	// }, nil).(*ast.File)
 
-	if options.simplify {
-		f = simplify(f)
-	}
+	return f
+}
 
+// rewriteStrings converts string literals that need many escape sequences
+// to the form that needs the fewest, as selected by [literal.ReadableStyle].
+// Labels, import paths, and interpolations are left unchanged.
+func rewriteStrings(f *ast.File) *ast.File {
+	depth := 0 // approximates the indentation of multiline strings
+	var before func(n ast.Node) bool
+	after := func(n ast.Node) {
+		switch n.(type) {
+		case *ast.StructLit, *ast.ListLit:
+			depth--
+		}
+	}
+	before = func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.StructLit, *ast.ListLit:
+			depth++
+		case *ast.Field:
+			ast.Walk(x.Value, before, after)
+			return false
+		case *ast.ImportDecl, *ast.Interpolation:
+			return false
+		case *ast.BasicLit:
+			if x.Kind == token.STRING {
+				x.Value = literal.Requote(x.Value, literal.ReadableStyle, depth+1)
+			}
+		}
+		return true
+	}
+	ast.Walk(f, before, after)
 	return f
 }
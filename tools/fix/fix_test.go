@@ -15,6 +15,7 @@
 package fix
 
 import (
+	"strings"
 	"testing"
 
 	"cuelang.org/go/cue/format"
@@ -27,6 +28,8 @@ func TestFile(t *testing.T) {
 		in       string
 		out      string
 		simplify bool
+		fixers   []string
+		versions []string // from and to versions for UpgradeVersion
 	}{{
 		name: "rewrite integer division",
 		in: `package foo
@@ -59,6 +62,87 @@ x3: 4
 x4: 4
 x5: 9 & 4
 x6: 4 & 9
+`,
+	}, {
+		name: "deprecated builtins",
+		in: `package foo
+
+import L "list"
+
+a: L.SortStable([2, 1], L.Ascending)
+b: {
+	L: {SortStable: 1}
+	c: L.SortStable
+}
+`,
+		out: `package foo
+
+import L "list"
+
+a: L.Sort([2, 1], L.Ascending)
+b: {
+	L: {SortStable: 1}
+	c: L.SortStable
+}
+`,
+	}, {
+		name:   "select fixers",
+		fixers: []string{"sortstable"},
+		in: `import "list"
+
+a: 1 div 2
+b: list.SortStable([2, 1], list.Ascending)
+`,
+		out: `import "list"
+
+a: 1 div 2
+b: list.Sort([2, 1], list.Ascending)
+`,
+	}, {
+		name:   "strings",
+		fixers: []string{"strings"},
+		in: `re: "^\\d+\\.\\d+$"
+"a\\b\\c\\d": "a\\b"
+x: {
+	script: "set -e\necho \"$1\"\nexit 0\n"
+}
+`,
+		out: `re:           #"^\d+\.\d+$"#
+"a\\b\\c\\d": "a\\b"
+x: {
+	script: """
+		set -e
+		echo "$1"
+		exit 0
+
+		"""
+}
+`,
+	}, {
+		name:     "upgrade version",
+		versions: []string{"v0.3.0", "v0.8.0"},
+		in: `import "list"
+
+a: 1 div 2
+b: list.SortStable([2, 1], list.Ascending)
+`,
+		out: `import "list"
+
+a: 1 div 2
+b: list.Sort([2, 1], list.Ascending)
+`,
+	}, {
+		name:     "upgrade version from old version",
+		versions: []string{"v0.2.2", "v0.7.0"},
+		in: `import "list"
+
+a: 1 div 2
+b: list.SortStable([2, 1], list.Ascending)
+`,
+		out: `import "list"
+
+a: __div(1, 2)
+b: list.SortStable([2, 1], list.Ascending)
 `,
 
 		// 	}, {
@@ -116,6 +200,12 @@ x6: 4 & 9
 			if tc.simplify {
 				opts = append(opts, Simplify())
 			}
+			if tc.fixers != nil {
+				opts = append(opts, Select(tc.fixers...))
+			}
+			if tc.versions != nil {
+				opts = append(opts, UpgradeVersion(tc.versions[0], tc.versions[1]))
+			}
 			n := File(f, opts...)
 
 			b, err := format.Node(n)
@@ -133,3 +223,70 @@ x6: 4 & 9
 		})
 	}
 }
+
+func TestRenameImport(t *testing.T) {
+	in := `package foo
+
+import (
+	"example.com/old/schema"
+	"example.com/old/schema/k8s:apps"
+	x "example.com/old"
+	"example.com/other"
+)
+
+a: schema.#A & apps.#B & x.#C & other.#D
+`
+	want := `package foo
+
+import (
+	schema "example.com/new"
+	"example.com/new/k8s:apps"
+	x "example.com/new"
+	"example.com/other"
+)
+
+a: schema.#A & apps.#B & x.#C & other.#D
+`
+	f, err := parser.ParseFile("in.cue", in, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f = RenameImport("example.com/old/schema", "example.com/new")(f)
+	f = RenameImport("example.com/old", "example.com/new")(f)
+	b, err := format.Node(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(Fixer{
+		Name:     "test-rename",
+		Version:  "v0.9.0",
+		Optional: true,
+		Fn:       RenameImport("example.com/a", "example.com/b"),
+	})
+	f, ok := Lookup("test-rename")
+	if !ok || f.Version != "v0.9.0" {
+		t.Fatalf("fixer not registered: %v", f)
+	}
+
+	var names []string
+	for _, f := range Fixers() {
+		names = append(names, f.Name)
+	}
+	want := "blockcomments intdiv sortstable test-rename simplify strings"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for duplicate registration")
+		}
+	}()
+	Register(f)
+}
@@ -0,0 +1,292 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fix
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/mod/module"
+	"cuelang.org/go/internal/mod/semver"
+)
+
+// A Fixer is a named rewrite of CUE files, similar in spirit to the fixes
+// applied by go fix.
+//
+// Fixers are registered with [Register] and can be selected by name with
+// the [Select] option. Modules and tools may register their own fixers to
+// ship codemods along with API changes.
+type Fixer struct {
+	// Name uniquely identifies the fixer. It is the name used to select
+	// the fixer on the command line, as in cue fix -r name.
+	Name string
+
+	// Version is the CUE language version in which the construct
+	// rewritten by the fixer was deprecated or changed, for example
+	// "v0.8.0". It may be empty for fixers not tied to a language version.
+	Version string
+
+	// Doc is a one-line description of the fixer.
+	Doc string
+
+	// Optional indicates that the fixer is only applied when it is
+	// selected explicitly.
+	Optional bool
+
+	// Fn applies the rewrite to f and returns the resulting file.
+	// It may alter f.
+	Fn func(f *ast.File) *ast.File
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Fixer{}
+)
+
+// Register adds f to the set of known fixers. It panics if f has no name
+// or function, or if a fixer with the same name was already registered.
+func Register(f Fixer) {
+	if f.Name == "" || f.Fn == nil {
+		panic("fix: fixer must have a name and a function")
+	}
+	if f.Version != "" && !semver.IsValid(f.Version) {
+		panic(fmt.Sprintf("fix: fixer %q has invalid version %q", f.Name, f.Version))
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[f.Name]; ok {
+		panic(fmt.Sprintf("fix: fixer %q registered twice", f.Name))
+	}
+	registry[f.Name] = f
+}
+
+// Lookup returns the registered fixer with the given name.
+func Lookup(name string) (Fixer, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	f, ok := registry[name]
+	return f, ok
+}
+
+// Fixers returns all registered fixers ordered by version and then by name.
+// Fixers without a version sort last.
+func Fixers() []Fixer {
+	registryMu.RLock()
+	a := make([]Fixer, 0, len(registry))
+	for _, f := range registry {
+		a = append(a, f)
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(a, func(i, j int) bool {
+		vi, vj := a[i].Version, a[j].Version
+		switch {
+		case vi == vj:
+			return a[i].Name < a[j].Name
+		case vi == "":
+			return false
+		case vj == "":
+			return true
+		}
+		return semver.Compare(vi, vj) < 0
+	})
+	return a
+}
+
+// Select restricts the fixes applied to the fixers with the given names,
+// including optional ones. Names that are not registered are ignored;
+// use [Lookup] to validate user input.
+func Select(names ...string) Option {
+	return func(o *options) {
+		o.selected = append(o.selected, names...)
+	}
+}
+
+// UpgradeVersion restricts the fixes applied to those needed to migrate
+// code written for language version from to language version to: the
+// fixers whose version is later than from and no later than to.
+// An empty from selects all fixers up to and including to.
+// Fixers without a version are not applied.
+func UpgradeVersion(from, to string) Option {
+	return func(o *options) {
+		o.versioned = true
+		o.fromVersion = from
+		o.toVersion = to
+	}
+}
+
+// fixers returns the fixers selected by o in the order in which they
+// should be applied.
+func (o *options) fixers() []Fixer {
+	var a []Fixer
+	for _, f := range Fixers() {
+		want := !f.Optional
+		if o.selected != nil {
+			want = contains(o.selected, f.Name)
+		}
+		if o.versioned && !o.inVersionRange(f.Version) {
+			want = false
+		}
+		if f.Name == simplifyName && o.simplify {
+			want = true
+		}
+		if want {
+			a = append(a, f)
+		}
+	}
+	return a
+}
+
+func (o *options) inVersionRange(v string) bool {
+	if v == "" || semver.Compare(v, o.toVersion) > 0 {
+		return false
+	}
+	return o.fromVersion == "" || semver.Compare(v, o.fromVersion) > 0
+}
+
+func contains(a []string, s string) bool {
+	for _, x := range a {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// RenameImport returns a rewrite function that changes all imports of
+// oldPath, or of packages within oldPath, to refer to newPath instead.
+// If the package name implied by the new path differs from the old one,
+// the import is given an explicit name so that references within the
+// file remain valid.
+func RenameImport(oldPath, newPath string) func(f *ast.File) *ast.File {
+	return func(f *ast.File) *ast.File {
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			var rest string
+			switch {
+			case path == oldPath:
+			case strings.HasPrefix(path, oldPath+"/"), strings.HasPrefix(path, oldPath+":"):
+				rest = path[len(oldPath):]
+			default:
+				continue
+			}
+			p := newPath + rest
+			oldName := module.ParseImportPath(path).Qualifier
+			if spec.Name == nil && module.ParseImportPath(p).Qualifier != oldName {
+				spec.Name = ast.NewIdent(oldName)
+				ast.SetRelPos(spec.Name, spec.Path.Pos().RelPos())
+				ast.SetRelPos(spec.Path, token.Blank)
+			}
+			spec.Path.Value = strconv.Quote(p)
+		}
+		return f
+	}
+}
+
+// RenameBuiltin returns a rewrite function that replaces references to
+// pkg.oldName with pkg.newName, where pkg is the package with the given
+// import path.
+func RenameBuiltin(importPath, oldName, newName string) func(f *ast.File) *ast.File {
+	return func(f *ast.File) *ast.File {
+		names := importNames(f, importPath)
+		if len(names) == 0 {
+			return f
+		}
+		ast.Walk(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			x, ok := sel.X.(*ast.Ident)
+			if !ok || !names[x.Name] {
+				return true
+			}
+			if spec, ok := x.Node.(*ast.ImportSpec); x.Node != nil && !ok {
+				return true // shadowed
+			} else if ok && !isImportOf(spec, importPath) {
+				return true
+			}
+			if id, ok := sel.Sel.(*ast.Ident); ok && id.Name == oldName {
+				id.Name = newName
+			}
+			return true
+		}, nil)
+		return f
+	}
+}
+
+// importNames reports the names under which importPath is imported in f.
+func importNames(f *ast.File, importPath string) map[string]bool {
+	names := map[string]bool{}
+	for _, spec := range f.Imports {
+		if !isImportOf(spec, importPath) {
+			continue
+		}
+		if spec.Name != nil {
+			names[spec.Name.Name] = true
+		} else {
+			names[module.ParseImportPath(importPath).Qualifier] = true
+		}
+	}
+	return names
+}
+
+func isImportOf(spec *ast.ImportSpec, importPath string) bool {
+	path, err := strconv.Unquote(spec.Path.Value)
+	return err == nil && path == importPath
+}
+
+const simplifyName = "simplify"
+
+func init() {
+	Register(Fixer{
+		Name:    "intdiv",
+		Version: "v0.3.0",
+		Doc:     "rewrite integer division operators to builtin calls",
+		Fn:      rewriteIntDiv,
+	})
+	Register(Fixer{
+		Name:    "blockcomments",
+		Version: "v0.3.0",
+		Doc:     "rewrite block comments to line comments",
+		Fn:      rewriteBlockComments,
+	})
+	Register(Fixer{
+		Name:    "sortstable",
+		Version: "v0.8.0",
+		Doc:     "replace the deprecated list.SortStable with list.Sort",
+		Fn:      RenameBuiltin("list", "SortStable", "Sort"),
+	})
+	Register(Fixer{
+		Name:     "strings",
+		Doc:      "rewrite string literals with many escapes to raw or multiline strings",
+		Optional: true,
+		Fn:       rewriteStrings,
+	})
+	Register(Fixer{
+		Name:     simplifyName,
+		Doc:      "simplify expressions involving top",
+		Optional: true,
+		Fn:       simplify,
+	})
+}
@@ -72,9 +72,11 @@ import (
 	"os"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/logging"
 	"cuelang.org/go/cue/stats"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/convert"
@@ -91,6 +93,8 @@ var (
 	// dependency as more results may come. This is useful in server mode.
 
 	debug = os.Getenv("CUE_DEBUG_TOOLS_FLOW") != ""
+
+	logger = logging.For(logging.Flow)
 )
 
 // A TaskFunc creates a Runner for v if v defines a task or reports nil
@@ -149,6 +153,19 @@ type Config struct {
 	// updated. This includes directly after initialization. The task may be
 	// nil if this call is not the result of a task completing.
 	UpdateFunc func(c *Controller, t *Task) error
+
+	// Policy, if non-nil, restricts the tasks a workflow may run. It is
+	// called for all tasks when Run is called, before any task is run, and
+	// again for each task just before it runs, at which point the values it
+	// depends on are resolved. Run fails without running any task if Policy
+	// reports an error for any of the tasks in the first round. Tasks that
+	// are only discovered while the workflow runs are checked before they
+	// run.
+	Policy func(t *Task) error
+
+	// Report enables the recording of the outcome of each task, which can
+	// be retrieved with Controller.Report once Run has returned.
+	Report bool
 }
 
 // A Controller defines a set of Tasks to be executed.
@@ -177,6 +194,10 @@ type Controller struct {
 
 	done atomic.Bool
 
+	// start and end record the time at which Run started and finished.
+	start time.Time
+	end   time.Time
+
 	// keys maps task keys to their index. This allows a recreation of the
 	// Instance while retaining the original task indices.
 	//
@@ -253,7 +274,16 @@ func (c *Controller) Run(ctx context.Context) error {
 	c.context, c.cancelFunc = context.WithCancel(ctx)
 	defer c.cancelFunc()
 
+	c.start = time.Now()
+
+	if c.cfg.Policy != nil {
+		for _, t := range c.tasks {
+			c.checkPolicy(t)
+		}
+	}
+
 	c.runLoop()
+	c.end = time.Now()
 
 	// NOTE: track state here as runLoop might add more tasks to the flow
 	// during the execution so checking current tasks state may not be
@@ -266,6 +296,20 @@ func (c *Controller) Run(ctx context.Context) error {
 	return c.errs
 }
 
+// checkPolicy reports whether t may be run according to the configured
+// Policy, recording an error if it may not.
+func (c *Controller) checkPolicy(t *Task) bool {
+	if c.cfg.Policy == nil {
+		return true
+	}
+	if err := c.cfg.Policy(t); err != nil {
+		t.err = errors.Promote(err, "task not allowed")
+		c.addErr(err, "task not allowed")
+		return false
+	}
+	return true
+}
+
 // Value returns the value managed by the controller.
 //
 // It is safe to use the value only after Run() has returned.
@@ -374,6 +418,12 @@ type Task struct {
 	depTasks    []*Task
 
 	stats stats.Counts
+
+	// Only used for reporting.
+	start    time.Time
+	end      time.Time
+	attempts int
+	output   string
 }
 
 // Stats reports statistics on the number of CUE operations used to complete
@@ -157,6 +157,173 @@ func TestFlowValuePanic(t *testing.T) {
 	t.Errorf("Value() did not panic")
 }
 
+func TestFlowPolicy(t *testing.T) {
+	// policy rejects tasks of kind "failure" and tasks of kind "valToOut"
+	// once their value is known to be "finished".
+	policy := func(t *flow.Task) error {
+		v := t.Value()
+		switch id, _ := v.LookupPath(cue.ParsePath("$id")).String(); id {
+		case "failure":
+			return errors.Newf(v.Pos(), "%s: kind %q not allowed", t.Path(), id)
+		case "valToOut":
+			if s, err := v.LookupPath(cue.ParsePath("val")).String(); err == nil && s == "finished" {
+				return errors.Newf(v.Pos(), "%s: value %q not allowed", t.Path(), s)
+			}
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name string
+		in   string
+		ran  []string
+		err  string
+	}{{
+		name: "before run",
+		in: `
+		root: {
+			a: $id: "slow"
+			b: $id: "failure"
+		}
+		`,
+		err: `root.b: kind "failure" not allowed`,
+	}, {
+		name: "while running",
+		in: `
+		root: {
+			a: {
+				$id: "slow"
+				out: string
+			}
+			b: {
+				$id: "valToOut"
+				val: a.out
+			}
+		}
+		`,
+		ran: []string{"root.a"},
+		err: `root.b: value "finished" not allowed`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := cuecontext.New().CompileString(tc.in)
+			var ran []string
+			cfg := &flow.Config{
+				Root: cue.ParsePath("root"),
+				UpdateFunc: func(c *flow.Controller, t *flow.Task) error {
+					if t != nil {
+						ran = append(ran, t.Path().String())
+					}
+					return nil
+				},
+				Policy: policy,
+			}
+			c := flow.New(cfg, v, taskFunc)
+			err := c.Run(context.Background())
+			if got := fmt.Sprint(err); got != tc.err {
+				t.Errorf("error:\ngot  %s\nwant %s", got, tc.err)
+			}
+			if got, want := fmt.Sprint(ran), fmt.Sprint(tc.ran); got != want {
+				t.Errorf("tasks run: got %s; want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestFlowReport(t *testing.T) {
+	f := `
+	root: {
+		a: {
+			$id: "valToOut"
+			val: "hello"
+		}
+		b: {
+			$id:    "failure"
+			$after: a
+		}
+		c: {
+			$id:    "valToOut"
+			$after: b
+			val:    "world"
+		}
+	}
+	`
+	v := cuecontext.New().CompileString(f)
+	c := flow.New(&flow.Config{
+		Root:   cue.ParsePath("root"),
+		Report: true,
+	}, v, taskFunc)
+	if err := c.Run(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+
+	r := c.Report()
+	r.Start, r.Duration = time.Time{}, 0
+	for _, t := range r.Tasks {
+		t.Start, t.Duration = time.Time{}, 0
+	}
+
+	var buf strings.Builder
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	const wantJSON = `{
+    "name": "root",
+    "status": "failed",
+    "start": "0001-01-01T00:00:00Z",
+    "duration": 0,
+    "tasks": [
+        {
+            "name": "root.a",
+            "status": "passed",
+            "duration": 0,
+            "attempts": 1,
+            "output": "{\n\tout: \"hello\"\n}"
+        },
+        {
+            "name": "root.b",
+            "status": "failed",
+            "duration": 0,
+            "attempts": 1,
+            "error": "task failed: failure"
+        },
+        {
+            "name": "root.c",
+            "status": "skipped",
+            "duration": 0,
+            "attempts": 0
+        }
+    ]
+}
+`
+	if got := buf.String(); got != wantJSON {
+		t.Errorf("JSON report:\ngot:\n%s\nwant:\n%s", got, wantJSON)
+	}
+
+	buf.Reset()
+	if err := r.WriteJUnit(&buf); err != nil {
+		t.Fatal(err)
+	}
+	const wantJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="root" tests="3" failures="1" time="0.000">
+    <testsuite name="root" tests="3" failures="1" skipped="1" time="0.000">
+        <testcase name="root.a" classname="root" time="0.000">
+            <system-out>{&#xA;&#x9;out: &#34;hello&#34;&#xA;}</system-out>
+        </testcase>
+        <testcase name="root.b" classname="root" time="0.000">
+            <failure message="task failed: failure">task failed: failure</failure>
+        </testcase>
+        <testcase name="root.c" classname="root" time="0.000">
+            <skipped></skipped>
+        </testcase>
+    </testsuite>
+</testsuites>
+`
+	if got := buf.String(); got != wantJUnit {
+		t.Errorf("JUnit report:\ngot:\n%s\nwant:\n%s", got, wantJUnit)
+	}
+}
+
 func taskFunc(v cue.Value) (flow.Runner, error) {
 	switch name, err := v.Lookup("$id").String(); name {
 	default:
@@ -0,0 +1,284 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+// This file contains the logic for reporting the results of a run in formats
+// that are understood by CI systems.
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/export"
+)
+
+// MaxReportOutput is the maximum number of bytes of the output of a task that
+// is included in a Report.
+const MaxReportOutput = 4 << 10
+
+// A Status is the outcome of a task in a Report.
+type Status string
+
+const (
+	// Passed indicates a task ran successfully.
+	Passed Status = "passed"
+
+	// Failed indicates a task failed or was not allowed to run.
+	Failed Status = "failed"
+
+	// Skipped indicates a task was not run, for instance because the workflow
+	// failed before it became ready.
+	Skipped Status = "skipped"
+)
+
+// A Report describes the outcome of a run of a workflow.
+type Report struct {
+	// Name is the path of the root of the workflow, or "flow" if the root is
+	// the top of the configuration.
+	Name string
+
+	Status   Status
+	Start    time.Time
+	Duration time.Duration
+
+	Tasks []*TaskReport
+}
+
+// A TaskReport describes the outcome of a single task.
+type TaskReport struct {
+	Name     string
+	Status   Status
+	Start    time.Time // zero if the task was not started
+	Duration time.Duration
+
+	// Attempts is the number of times the task was started. Tasks are
+	// currently not retried, so it is either 0 or 1.
+	Attempts int
+
+	// Output holds the results the task filled in, formatted as CUE and
+	// truncated to MaxReportOutput bytes.
+	Output string
+
+	// Error holds the details of the error of a failed task.
+	Error string
+}
+
+// Report returns a report of the tasks run by the controller, or nil if the
+// Report option was not set.
+//
+// It is safe to use the report only after Run() has returned.
+// It panics if the flow is running.
+func (c *Controller) Report() *Report {
+	if !c.done.Load() {
+		panic("can't retrieve report before flow has terminated")
+	}
+	if !c.cfg.Report {
+		return nil
+	}
+	r := &Report{
+		Name:     c.cfg.Root.String(),
+		Status:   Passed,
+		Start:    c.start,
+		Duration: c.end.Sub(c.start),
+	}
+	if r.Name == "" {
+		r.Name = "flow"
+	}
+	if c.errs != nil {
+		r.Status = Failed
+	}
+	for _, t := range c.tasks {
+		tr := &TaskReport{
+			Name:     t.path.String(),
+			Status:   Skipped,
+			Start:    t.start,
+			Attempts: t.attempts,
+			Output:   t.output,
+		}
+		if t.attempts > 0 && !t.end.IsZero() {
+			tr.Duration = t.end.Sub(t.start)
+		}
+		switch {
+		case t.err != nil:
+			tr.Status = Failed
+			tr.Error = strings.TrimSpace(errors.Details(t.err, nil))
+		case t.state == Terminated && t.attempts > 0:
+			tr.Status = Passed
+		}
+		r.Tasks = append(r.Tasks, tr)
+	}
+	return r
+}
+
+// recordOutput records the results filled in by t for inclusion in a Report.
+func (c *Controller) recordOutput(t *Task, x adt.Expr) {
+	e, xerr := export.Expr(c.opCtx, "", x)
+	if xerr != nil {
+		return
+	}
+	b, err := format.Node(e)
+	if err != nil {
+		return
+	}
+	t.output = truncate(string(b), MaxReportOutput)
+}
+
+// truncate shortens s to at most n bytes, not counting the note marking the
+// truncation, without splitting runes.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	i := n
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return fmt.Sprintf("%s\n... (%d bytes truncated)", s[:i], len(s)-i)
+}
+
+// WriteJSON writes r to w as JSON. Durations are written in seconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	type task struct {
+		Name     string     `json:"name"`
+		Status   Status     `json:"status"`
+		Start    *time.Time `json:"start,omitempty"`
+		Duration float64    `json:"duration"`
+		Attempts int        `json:"attempts"`
+		Output   string     `json:"output,omitempty"`
+		Error    string     `json:"error,omitempty"`
+	}
+	type report struct {
+		Name     string    `json:"name"`
+		Status   Status    `json:"status"`
+		Start    time.Time `json:"start"`
+		Duration float64   `json:"duration"`
+		Tasks    []task    `json:"tasks"`
+	}
+	out := report{
+		Name:     r.Name,
+		Status:   r.Status,
+		Start:    r.Start,
+		Duration: r.Duration.Seconds(),
+		Tasks:    []task{},
+	}
+	for _, t := range r.Tasks {
+		x := task{
+			Name:     t.Name,
+			Status:   t.Status,
+			Duration: t.Duration.Seconds(),
+			Attempts: t.Attempts,
+			Output:   t.Output,
+			Error:    t.Error,
+		}
+		if !t.Start.IsZero() {
+			x.Start = &t.Start
+		}
+		out.Tasks = append(out.Tasks, x)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(out)
+}
+
+// WriteJUnit writes r to w as JUnit XML. The workflow is reported as a test
+// suite with a test case for each task.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	type message struct {
+		Message string `xml:"message,attr,omitempty"`
+		Text    string `xml:",chardata"`
+	}
+	type testCase struct {
+		Name      string   `xml:"name,attr"`
+		ClassName string   `xml:"classname,attr"`
+		Time      string   `xml:"time,attr"`
+		Failure   *message `xml:"failure"`
+		Skipped   *message `xml:"skipped"`
+		SystemOut string   `xml:"system-out,omitempty"`
+	}
+	type testSuite struct {
+		Name      string     `xml:"name,attr"`
+		Tests     int        `xml:"tests,attr"`
+		Failures  int        `xml:"failures,attr"`
+		Skipped   int        `xml:"skipped,attr"`
+		Time      string     `xml:"time,attr"`
+		Timestamp string     `xml:"timestamp,attr,omitempty"`
+		TestCases []testCase `xml:"testcase"`
+	}
+	type testSuites struct {
+		XMLName  xml.Name    `xml:"testsuites"`
+		Name     string      `xml:"name,attr"`
+		Tests    int         `xml:"tests,attr"`
+		Failures int         `xml:"failures,attr"`
+		Time     string      `xml:"time,attr"`
+		Suites   []testSuite `xml:"testsuite"`
+	}
+
+	suite := testSuite{
+		Name:  r.Name,
+		Tests: len(r.Tasks),
+		Time:  seconds(r.Duration),
+	}
+	if !r.Start.IsZero() {
+		suite.Timestamp = r.Start.UTC().Format("2006-01-02T15:04:05")
+	}
+	for _, t := range r.Tasks {
+		tc := testCase{
+			Name:      t.Name,
+			ClassName: r.Name,
+			Time:      seconds(t.Duration),
+			SystemOut: t.Output,
+		}
+		switch t.Status {
+		case Failed:
+			suite.Failures++
+			msg, _, _ := strings.Cut(t.Error, "\n")
+			msg = strings.TrimSuffix(msg, ":")
+			tc.Failure = &message{Message: msg, Text: t.Error}
+		case Skipped:
+			suite.Skipped++
+			tc.Skipped = &message{}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	out := testSuites{
+		Name:     r.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []testSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "    ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
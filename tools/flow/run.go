@@ -27,9 +27,12 @@ package flow
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"time"
 
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/logging"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/eval"
 	"cuelang.org/go/internal/value"
@@ -61,15 +64,24 @@ func (c *Controller) runLoop() {
 			case Ready:
 				running = true
 
-				t.state = Running
 				c.updateTaskValue(t)
+				if !c.checkPolicy(t) {
+					t.state = Terminated
+					return
+				}
+				t.state = Running
+				t.attempts++
+				t.start = time.Now()
 
 				t.ctxt = eval.NewContext(value.ToInternal(t.v))
 
+				logger.Debug("task started", "path", t.Path(), "index", t.Index())
+
 				go func(t *Task) {
 					if err := t.r.Run(t, nil); err != nil {
 						t.err = errors.Promote(err, "task failed")
 					}
+					t.end = time.Now()
 
 					t.c.taskCh <- t
 				}(t)
@@ -96,6 +108,7 @@ func (c *Controller) runLoop() {
 
 		case t := <-c.taskCh:
 			t.state = Terminated
+			logger.Debug("task terminated", "path", t.Path(), "index", t.Index(), "err", t.err)
 
 			taskStats := *t.ctxt.Stats()
 			t.stats.Add(taskStats)
@@ -145,6 +158,9 @@ func (c *Controller) markReady(t *Task) {
 		fmt.Fprint(os.Stderr, mermaidGraph(c))
 		fmt.Fprintln(os.Stderr, "```")
 	}
+	if t == nil && logging.Enabled(logging.Flow, slog.LevelDebug) {
+		logger.Debug("task dependency graph", "mermaid", mermaidGraph(c))
+	}
 
 	if c.cfg.UpdateFunc != nil {
 		if err := c.cfg.UpdateFunc(c, t); err != nil {
@@ -205,6 +221,9 @@ func (c *Controller) updateTaskResults(t *Task) bool {
 	}
 
 	expr := t.update
+	if c.cfg.Report {
+		c.recordOutput(t, expr)
+	}
 	for i := len(t.labels) - 1; i >= 0; i-- {
 		label := t.labels[i]
 		switch label.Typ() {
@@ -0,0 +1,160 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ignore finds the ignore directives of CUE files, which exempt
+// declarations from a check, such as the warnings of cue vet about
+// deprecated fields.
+//
+// An ignore directive is a comment of the form
+//
+//	//cue:ignore <rule> -- <reason>
+//
+// in the doc comment of a declaration or on its line. It exempts the
+// declaration, including the declarations nested within it, from the
+// check named by rule. The reason is required, so that each exemption is
+// accounted for:
+//
+//	// The replacement is not available in all clusters yet.
+//	//cue:ignore deprecated -- needs scale.replicas in all clusters
+//	replicas: 3
+package ignore
+
+import (
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+const prefix = "//cue:ignore"
+
+// A Directive is an ignore directive.
+type Directive struct {
+	// Rule is the name of the check from which the declaration is exempt.
+	Rule string
+
+	// Reason holds the justification of the directive.
+	Reason string
+
+	// Pos is the position of the directive.
+	Pos token.Pos
+
+	// Start and End delimit the declaration to which the directive
+	// applies.
+	Start, End token.Pos
+
+	// Used reports whether the directive exempted anything, as recorded
+	// by [Set.Ignored].
+	Used bool
+}
+
+// A Set holds the ignore directives of a collection of files.
+type Set struct {
+	dirs []*Directive
+	seen map[*ast.File]bool
+}
+
+// NewSet returns an empty Set.
+func NewSet() *Set {
+	return &Set{seen: map[*ast.File]bool{}}
+}
+
+// AddFile adds the ignore directives of f to s. It reports an error for
+// each directive that is malformed, such as a directive without a reason.
+// The directives of a file that was added before are not added again.
+//
+// The file must have been parsed with comments.
+func (s *Set) AddFile(f *ast.File) errors.Error {
+	if s.seen[f] {
+		return nil
+	}
+	s.seen[f] = true
+	var errs errors.Error
+	ast.Walk(f, func(n ast.Node) bool {
+		if _, ok := n.(ast.Decl); !ok {
+			return true
+		}
+		for _, cg := range ast.Comments(n) {
+			for _, c := range cg.List {
+				d, err := parse(c)
+				if err != nil {
+					errs = errors.Append(errs, err)
+					continue
+				}
+				if d == nil {
+					continue
+				}
+				d.Start, d.End = n.Pos(), n.End()
+				s.dirs = append(s.dirs, d)
+			}
+		}
+		return true
+	}, nil)
+	return errs
+}
+
+// parse parses the ignore directive of comment c, if any.
+func parse(c *ast.Comment) (*Directive, errors.Error) {
+	text, ok := strings.CutPrefix(c.Text, prefix)
+	if !ok || (text != "" && text[0] != ' ' && text[0] != '\t') {
+		return nil, nil
+	}
+	rule, reason, ok := strings.Cut(text, "--")
+	rule = strings.TrimSpace(rule)
+	reason = strings.TrimSpace(reason)
+	switch {
+	case rule == "" || strings.ContainsAny(rule, " \t"):
+		return nil, errors.Newf(c.Pos(), "invalid ignore directive: want %s <rule> -- <reason>", prefix)
+	case !ok || reason == "":
+		return nil, errors.Newf(c.Pos(), "ignore directive for %s has no reason: want %s %s -- <reason>", rule, prefix, rule)
+	}
+	return &Directive{Rule: rule, Reason: reason, Pos: c.Pos()}, nil
+}
+
+// Ignored reports whether pos lies within a declaration that is exempt
+// from the given rule, and marks the directives exempting it as used.
+func (s *Set) Ignored(rule string, pos token.Pos) bool {
+	if !pos.IsValid() {
+		return false
+	}
+	ignored := false
+	for _, d := range s.dirs {
+		if d.Rule == rule && contains(d, pos) {
+			d.Used = true
+			ignored = true
+		}
+	}
+	return ignored
+}
+
+func contains(d *Directive, pos token.Pos) bool {
+	return pos.Filename() == d.Start.Filename() &&
+		pos.Offset() >= d.Start.Offset() &&
+		pos.Offset() < d.End.Offset()
+}
+
+// Directives returns the directives of s, sorted by position.
+func (s *Set) Directives() []*Directive {
+	dirs := append([]*Directive(nil), s.dirs...)
+	sort.SliceStable(dirs, func(i, j int) bool {
+		p, q := dirs[i].Pos, dirs[j].Pos
+		if p.Filename() != q.Filename() {
+			return p.Filename() < q.Filename()
+		}
+		return p.Offset() < q.Offset()
+	})
+	return dirs
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ignore_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/tools/ignore"
+)
+
+const src = `package x
+
+// Doc comment.
+//cue:ignore deprecated -- migrating in Q3
+a: {
+	b: 1
+}
+c: 2 //cue:ignore examples -- too slow
+d: 3
+//cue:ignore deprecated
+e: 4
+//cue:ignore -- missing rule
+f: 5
+//cue:ignored is not a directive
+g: 6
+`
+
+func TestSet(t *testing.T) {
+	f, err := parser.ParseFile("x.cue", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := ignore.NewSet()
+	errs := s.AddFile(f)
+	var msgs []string
+	for _, e := range errors.Errors(errs) {
+		msgs = append(msgs, fmt.Sprintf("%v: %v", e.Position(), e))
+	}
+	wantErrs := `x.cue:10:1: ignore directive for deprecated has no reason: want //cue:ignore deprecated -- <reason>
+x.cue:12:1: invalid ignore directive: want //cue:ignore <rule> -- <reason>`
+	if got := strings.Join(msgs, "\n"); got != wantErrs {
+		t.Errorf("got errors:\n%s\nwant:\n%s", got, wantErrs)
+	}
+	if errs := s.AddFile(f); errs != nil {
+		t.Errorf("unexpected errors when adding a file again: %v", errs)
+	}
+
+	var got []string
+	for _, d := range s.Directives() {
+		got = append(got, fmt.Sprintf("%v: %s: %s", d.Pos, d.Rule, d.Reason))
+	}
+	want := `x.cue:4:1: deprecated: migrating in Q3
+x.cue:8:6: examples: too slow`
+	if g := strings.Join(got, "\n"); g != want {
+		t.Errorf("got directives:\n%s\nwant:\n%s", g, want)
+	}
+
+	pos := func(label string) token.Pos {
+		var p token.Pos
+		ast.Walk(f, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name == label {
+				p = id.Pos()
+			}
+			return true
+		}, nil)
+		return p
+	}
+	for _, tc := range []struct {
+		rule, label string
+		want        bool
+	}{
+		{"deprecated", "a", true},
+		{"deprecated", "b", true},
+		{"examples", "b", false},
+		{"examples", "c", true},
+		{"deprecated", "c", false},
+		{"deprecated", "d", false},
+		{"deprecated", "e", false},
+	} {
+		if got := s.Ignored(tc.rule, pos(tc.label)); got != tc.want {
+			t.Errorf("Ignored(%s, %s) = %v; want %v", tc.rule, tc.label, got, tc.want)
+		}
+	}
+	for _, d := range s.Directives() {
+		if !d.Used {
+			t.Errorf("directive at %v not marked as used", d.Pos)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package incomplete checks that a configuration is concrete, except for
+// values that are allowed to remain incomplete.
+//
+// A value may be incomplete if its field has an @incomplete attribute, for
+// instance
+//
+//	#Service: {
+//		name: string
+//		tls?: {
+//			cert: string
+//		} @incomplete()
+//	}
+//
+// Like other field attributes, the attribute applies to any value the
+// field is unified with, including fields matched by a pattern constraint
+// that has the attribute. Values can also be selected by a path pattern.
+//
+// Values that may be incomplete are still checked for errors, such as
+// conflicts. Values that are concrete are not affected.
+package incomplete
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A Config defines which values may be incomplete.
+type Config struct {
+	// Paths holds patterns for the paths of values that may be incomplete,
+	// in addition to those marked with an @incomplete attribute. A pattern
+	// consists of elements separated by dots, each of which is matched
+	// against the corresponding label of a path using the syntax of
+	// path.Match. List elements are matched by their index. For instance,
+	// "services.*.tls" matches the tls field of any field of services.
+	Paths []string
+}
+
+type checker struct {
+	patterns [][]string
+}
+
+func newChecker(cfg *Config) (*checker, error) {
+	c := &checker{}
+	if cfg == nil {
+		return c, nil
+	}
+	for _, s := range cfg.Paths {
+		p := strings.Split(s, ".")
+		for _, elem := range p {
+			if _, err := path.Match(elem, ""); err != nil || elem == "" {
+				return nil, errors.Newf(token.NoPos, "invalid incomplete pattern %q", s)
+			}
+		}
+		c.patterns = append(c.patterns, p)
+	}
+	return c, nil
+}
+
+// exempt reports whether the value v at path p may be incomplete.
+func (c *checker) exempt(p []string, v cue.Value) bool {
+	if a := v.Attribute("incomplete"); a.Err() == nil {
+		return true
+	}
+	for _, pat := range c.patterns {
+		if matches(pat, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(pattern, p []string) bool {
+	if len(pattern) != len(p) {
+		return false
+	}
+	for i, elem := range pattern {
+		if ok, _ := path.Match(elem, p[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Check is like v.Validate(cue.Concrete(true)), but does not report values
+// that may be incomplete according to cfg and that have no errors other
+// than being incomplete. It returns the paths of these values, relative to
+// v. Only regular fields and list elements are considered.
+func Check(v cue.Value, cfg *Config) ([]cue.Path, error) {
+	c, err := newChecker(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var paths []cue.Path
+	c.walk(nil, nil, v, func(sels []cue.Selector) {
+		paths = append(paths, cue.MakePath(sels...))
+	})
+	if len(paths) == 0 {
+		return nil, v.Validate(cue.Concrete(true))
+	}
+
+	omit := map[string]bool{}
+	parents := map[string]bool{}
+	for _, p := range paths {
+		sels := p.Selectors()
+		omit[p.String()] = true
+		for i := range sels {
+			parents[cue.MakePath(sels[:i]...).String()] = true
+		}
+	}
+	var errs errors.Error
+	addErr := func(err error) {
+		if err != nil {
+			errs = errors.Append(errs, errors.Promote(err, "validate"))
+		}
+	}
+	var validate func(sels []cue.Selector, v cue.Value)
+	validate = func(sels []cue.Selector, v cue.Value) {
+		key := cue.MakePath(sels...).String()
+		switch {
+		case omit[key]:
+			return
+		case !parents[key]:
+			addErr(v.Validate(cue.Concrete(true)))
+			return
+		}
+		// v contains values that are omitted: report errors other than
+		// incompleteness and check the concreteness of its other values.
+		addErr(v.Validate())
+		each(v, func(sel cue.Selector, _ string, w cue.Value) {
+			validate(append(sels[:len(sels):len(sels)], sel), w)
+		})
+	}
+	validate(nil, v)
+	if errs != nil {
+		return nil, errors.Sanitize(errs)
+	}
+	return paths, nil
+}
+
+func (c *checker) walk(sels []cue.Selector, labels []string, v cue.Value, f func([]cue.Selector)) {
+	if len(sels) > 0 && c.exempt(labels, v) {
+		if v.Validate() == nil && v.Validate(cue.Concrete(true)) != nil {
+			f(sels)
+		}
+		return
+	}
+	each(v, func(sel cue.Selector, label string, w cue.Value) {
+		c.walk(append(sels[:len(sels):len(sels)], sel),
+			append(labels[:len(labels):len(labels)], label),
+			w, f)
+	})
+}
+
+// each calls f for the regular fields or list elements of v.
+func each(v cue.Value, f func(sel cue.Selector, label string, w cue.Value)) {
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := v.Fields()
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			sel := iter.Selector()
+			f(sel, sel.Unquoted(), iter.Value())
+		}
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return
+		}
+		for i := 0; iter.Next(); i++ {
+			f(cue.Index(i), strconv.Itoa(i), iter.Value())
+		}
+	}
+}
+
+// Remove returns a copy of v without the fields at the given paths, such as
+// those returned by Check. List elements at these paths are replaced with
+// null, so that the indices of other elements are retained. Remove is
+// intended for encoding data; the values of v should otherwise be concrete.
+func Remove(v cue.Value, paths []cue.Path) (cue.Value, error) {
+	if len(paths) == 0 {
+		return v, nil
+	}
+	set := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		set[p.String()] = true
+	}
+	n := v.Syntax(cue.Final(), cue.Concrete(true))
+	remove(n, nil, set)
+	var w cue.Value
+	switch x := n.(type) {
+	case *ast.File:
+		w = v.Context().BuildFile(x)
+	case ast.Expr:
+		w = v.Context().BuildExpr(x)
+	default:
+		return v, fmt.Errorf("unexpected syntax %T", n)
+	}
+	return w, w.Err()
+}
+
+// remove removes the fields and list elements within n whose path, when
+// appended to sels, is in set.
+func remove(n ast.Node, sels []cue.Selector, set map[string]bool) {
+	lookup := func(sel cue.Selector) ([]cue.Selector, bool) {
+		s := append(sels[:len(sels):len(sels)], sel)
+		return s, set[cue.MakePath(s...).String()]
+	}
+	decls := func(list []ast.Decl) []ast.Decl {
+		k := 0
+		for _, d := range list {
+			if f, ok := d.(*ast.Field); ok && f.Constraint == token.ILLEGAL {
+				name, _, err := ast.LabelName(f.Label)
+				if err == nil && !strings.HasPrefix(name, "#") && !strings.HasPrefix(name, "_") {
+					s, ok := lookup(cue.Str(name))
+					if ok {
+						continue
+					}
+					remove(f.Value, s, set)
+				}
+			} else {
+				remove(d, sels, set)
+			}
+			list[k] = d
+			k++
+		}
+		return list[:k]
+	}
+
+	switch x := n.(type) {
+	case *ast.File:
+		x.Decls = decls(x.Decls)
+	case *ast.EmbedDecl:
+		remove(x.Expr, sels, set)
+	case *ast.StructLit:
+		x.Elts = decls(x.Elts)
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			if _, ok := e.(*ast.Ellipsis); ok {
+				continue
+			}
+			s, ok := lookup(cue.Index(i))
+			if ok {
+				null := ast.NewNull()
+				ast.SetPos(null, e.Pos())
+				x.Elts[i] = null
+				continue
+			}
+			remove(e, s, set)
+		}
+	}
+}
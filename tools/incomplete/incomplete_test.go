@@ -0,0 +1,88 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incomplete_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/tools/incomplete"
+)
+
+const config = `
+#Service: {
+	name: string
+	tls?: {cert: string} @incomplete()
+	[=~"Hook$"]: string @incomplete()
+}
+svc: #Service & {
+	name:    "web"
+	tls:     {}
+	preHook: string
+	postHook: "echo"
+}
+ports: [80, int]
+extra: {a: int, b: 1}
+`
+
+func TestCheck(t *testing.T) {
+	v := cuecontext.New().CompileString(config)
+
+	_, err := incomplete.Check(v, nil)
+	qt.Assert(t, qt.Equals(errors.Details(err, nil), `extra.a: incomplete value int:
+    14:12
+ports.1: incomplete value int:
+    13:13
+`))
+
+	paths, err := incomplete.Check(v, &incomplete.Config{Paths: []string{"ports.1", "extra.a"}})
+	qt.Assert(t, qt.IsNil(err))
+	var got []string
+	for _, p := range paths {
+		got = append(got, p.String())
+	}
+	qt.Assert(t, qt.DeepEquals(got, []string{
+		"svc.tls",
+		"svc.preHook",
+		"ports[1]",
+		"extra.a",
+	}))
+
+	// Values that may be incomplete are still checked for errors.
+	w := v.FillPath(cue.ParsePath("svc.preHook"), 1)
+	_, err = incomplete.Check(w, &incomplete.Config{Paths: []string{"ports.1", "extra.a"}})
+	qt.Assert(t, qt.ErrorMatches(err, `svc.preHook: conflicting values string and 1.*`))
+
+	_, err = incomplete.Check(v, &incomplete.Config{Paths: []string{"ports.["}})
+	qt.Assert(t, qt.ErrorMatches(err, `invalid incomplete pattern "ports.\["`))
+}
+
+func TestRemove(t *testing.T) {
+	v := cuecontext.New().CompileString(config)
+	paths, err := incomplete.Check(v, &incomplete.Config{Paths: []string{"ports.1", "extra.a"}})
+	qt.Assert(t, qt.IsNil(err))
+
+	w, err := incomplete.Remove(v, paths)
+	qt.Assert(t, qt.IsNil(err))
+	b, err := json.Marshal(w)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(b),
+		`{"svc":{"name":"web","postHook":"echo"},"ports":[80,null],"extra":{"b":1}}`))
+}
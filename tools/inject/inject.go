@@ -0,0 +1,419 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inject fills values in a configuration that are only known at
+// run time, such as addresses, credentials or the current version, from
+// functions provided by a Go program.
+//
+// A configuration declares the values to be filled, called holes, either
+// with an @inject attribute naming the provider of the value, or as a
+// required field:
+//
+//	db: {
+//		host:     string @inject(dbHost)
+//		port:     *5432 | int @inject(dbPort)
+//		password: string @inject(dbPassword)
+//	}
+//	version!: string
+//
+// The provider of a required field without an @inject attribute is named
+// by the path of the field, as in "version" above. A hole that has a
+// default or is concrete, like db.port, need not have a provider.
+//
+// Providers are registered with a Binder. A provider is a Go function
+// that returns the value of the hole and, optionally, an error, and whose
+// arguments are the results of the providers it depends on:
+//
+//	b := inject.NewBinder()
+//	b.Supply("dbHost", "db.internal")
+//	b.Provide("dbPassword", func(host string) (string, error) {
+//		return vault.Lookup(host)
+//	}, "dbHost")
+//	b.Supply("version", buildVersion)
+//	v, err := b.Bind(v)
+//
+// Before any provider is called, Bind checks that all holes have a
+// provider, that the dependencies of providers exist and are not cyclic,
+// and that the types of the results of providers are compatible with the
+// holes they fill and the arguments they are passed as. Each provider is
+// called at most once, and its result is then encoded as CUE and unified
+// with its holes.
+package inject
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A Hole is a value in a configuration that is filled by a provider.
+type Hole struct {
+	// Name is the name of the provider of the hole.
+	Name string
+
+	// Path is the path of the hole relative to the value in which it was
+	// found.
+	Path cue.Path
+
+	// Value is the value of the hole before it is filled, which
+	// constrains the value of its provider.
+	Value cue.Value
+
+	// Optional reports whether the hole has a value without a provider,
+	// because it is concrete or has a default.
+	Optional bool
+}
+
+// Holes returns the holes in v, in the order in which their fields are
+// declared. Definitions and optional fields are not searched for holes,
+// nor are the values of holes themselves.
+func Holes(v cue.Value) ([]Hole, error) {
+	var holes []Hole
+	err := findHoles(v, nil, &holes)
+	return holes, err
+}
+
+func findHoles(v cue.Value, path []cue.Selector, holes *[]Hole) error {
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := v.Fields(cue.Optional(true))
+		if err != nil {
+			return err
+		}
+		for iter.Next() {
+			sel := iter.Selector()
+			if sel.ConstraintType() == cue.OptionalConstraint || !sel.IsString() {
+				continue
+			}
+			p := append(path[:len(path):len(path)], cue.Str(sel.Unquoted()))
+			required := sel.ConstraintType() == cue.RequiredConstraint
+			if err := findHole(iter.Value(), p, required, holes); err != nil {
+				return err
+			}
+		}
+
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return err
+		}
+		for i := 0; iter.Next(); i++ {
+			p := append(path[:len(path):len(path)], cue.Index(i))
+			if err := findHole(iter.Value(), p, false, holes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func findHole(v cue.Value, path []cue.Selector, required bool, holes *[]Hole) error {
+	h := Hole{Path: cue.MakePath(path...), Value: v}
+	a := v.Attribute("inject")
+	switch err := a.Err(); {
+	case err == nil:
+		name, err := a.String(0)
+		if err != nil || name == "" {
+			name = h.Path.String()
+		}
+		h.Name = name
+
+	case required && !v.IsConcrete():
+		h.Name = h.Path.String()
+
+	default:
+		return findHoles(v, path, holes)
+	}
+	_, hasDefault := v.Default()
+	h.Optional = hasDefault || v.IsConcrete()
+	*holes = append(*holes, h)
+	return nil
+}
+
+// A Binder fills the holes of configurations with the results of the
+// providers registered with it. The same Binder may be used to bind
+// several configurations, but not concurrently.
+type Binder struct {
+	providers map[string]*provider
+}
+
+type provider struct {
+	name string
+	deps []string
+	fn   reflect.Value // nil if the value is supplied
+	typ  reflect.Type  // the type of the result
+
+	done  bool
+	value any
+	err   error
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// NewBinder returns a Binder without providers.
+func NewBinder() *Binder {
+	return &Binder{providers: map[string]*provider{}}
+}
+
+// Provide registers f as the provider with the given name. The function f
+// must return a single value, or a value and an error. It is passed the
+// results of the providers named by deps, in order, and must have an
+// argument for each of them.
+func (b *Binder) Provide(name string, f any, deps ...string) error {
+	fn := reflect.ValueOf(f)
+	if fn.Kind() != reflect.Func {
+		return fmt.Errorf("provider %s: %T is not a function", name, f)
+	}
+	t := fn.Type()
+	switch {
+	case t.IsVariadic() || t.NumIn() != len(deps):
+		return fmt.Errorf("provider %s: function has %d arguments for %d dependencies", name, t.NumIn(), len(deps))
+	case t.NumOut() == 2 && t.Out(1) == errorType, t.NumOut() == 1:
+	default:
+		return fmt.Errorf("provider %s: function must return a value or a value and an error", name)
+	}
+	return b.add(&provider{
+		name: name,
+		deps: deps,
+		fn:   fn,
+		typ:  t.Out(0),
+	})
+}
+
+// Supply registers the provider with the given name as providing x.
+func (b *Binder) Supply(name string, x any) error {
+	return b.add(&provider{
+		name:  name,
+		typ:   reflect.TypeOf(x),
+		done:  true,
+		value: x,
+	})
+}
+
+func (b *Binder) add(p *provider) error {
+	if _, ok := b.providers[p.name]; ok {
+		return fmt.Errorf("provider %s: already registered", p.name)
+	}
+	b.providers[p.name] = p
+	return nil
+}
+
+// Check reports whether the holes of v can be filled without calling any
+// provider. It reports all holes without a provider, dependencies that
+// are missing or cyclic, and results of providers whose type does not
+// match the holes they fill or the arguments they are passed as.
+func (b *Binder) Check(v cue.Value) error {
+	holes, err := Holes(v)
+	if err != nil {
+		return err
+	}
+	_, err = b.check(holes)
+	return err
+}
+
+// Bind returns v with its holes filled by the results of their providers.
+// Only the providers of holes and their dependencies are called. Errors
+// from providers and values that do not satisfy the constraints of their
+// holes are reported along with the name of the provider.
+func (b *Binder) Bind(v cue.Value) (cue.Value, error) {
+	holes, err := Holes(v)
+	if err != nil {
+		return v, err
+	}
+	order, err := b.check(holes)
+	if err != nil {
+		return v, err
+	}
+	for _, p := range order {
+		if err := b.call(p); err != nil {
+			return v, err
+		}
+	}
+
+	for _, h := range holes {
+		p, ok := b.providers[h.Name]
+		if !ok {
+			continue
+		}
+		x := v.Context().Encode(p.value)
+		if err := x.Err(); err != nil {
+			return v, errors.Wrapf(err, h.Value.Pos(), "provider %s", h.Name)
+		}
+		v = v.FillPath(h.Path, x)
+	}
+
+	var errs errors.Error
+	for _, h := range holes {
+		if _, ok := b.providers[h.Name]; !ok {
+			continue
+		}
+		if err := v.LookupPath(h.Path).Validate(); err != nil {
+			errs = errors.Append(errs, errors.Wrapf(err, h.Value.Pos(), "provider %s", h.Name))
+		}
+	}
+	if errs != nil {
+		return v, errs
+	}
+	return v, nil
+}
+
+// check verifies that holes can be filled and returns the providers to
+// call, each after its dependencies.
+func (b *Binder) check(holes []Hole) ([]*provider, error) {
+	var errs errors.Error
+	addErr := func(pos token.Pos, format string, args ...any) {
+		errs = errors.Append(errs, errors.Newf(pos, format, args...))
+	}
+
+	var roots []string
+	for _, h := range holes {
+		p, ok := b.providers[h.Name]
+		if !ok {
+			if !h.Optional {
+				addErr(h.Value.Pos(), "%s: no provider for hole %s", h.Path, h.Name)
+			}
+			continue
+		}
+		roots = append(roots, h.Name)
+		kind := h.Value.IncompleteKind()
+		if k := goKind(p.typ); kind&k == 0 {
+			addErr(h.Value.Pos(), "%s: provider %s returns %v, which cannot be %v", h.Path, h.Name, p.typ, kind)
+		}
+	}
+
+	// Order the providers that are needed so that each comes after its
+	// dependencies, reporting cycles along the way.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var order []*provider
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		switch state[name] {
+		case visiting:
+			i := len(stack) - 1
+			for stack[i] != name {
+				i--
+			}
+			cycle := append(stack[i:len(stack):len(stack)], name)
+			addErr(token.NoPos, "cycle in providers: %s", strings.Join(cycle, " -> "))
+			return
+		case visited:
+			return
+		}
+		state[name] = visiting
+		stack = append(stack, name)
+		p := b.providers[name]
+		for i, d := range p.deps {
+			dp, ok := b.providers[d]
+			if !ok {
+				addErr(token.NoPos, "provider %s: no provider for dependency %s", name, d)
+				continue
+			}
+			if in := p.fn.Type().In(i); dp.typ != nil && !dp.typ.AssignableTo(in) {
+				addErr(token.NoPos, "provider %s: dependency %s returns %v, which cannot be used as %v", name, d, dp.typ, in)
+			}
+			visit(d)
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		order = append(order, p)
+	}
+	sort.Strings(roots)
+	for _, name := range roots {
+		visit(name)
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return order, nil
+}
+
+// call calls p, whose dependencies must have been called, if it has not
+// been called before.
+func (b *Binder) call(p *provider) error {
+	if !p.done {
+		p.done = true
+		args := make([]reflect.Value, len(p.deps))
+		for i, d := range p.deps {
+			dp := b.providers[d]
+			if dp.err != nil {
+				p.err = dp.err
+				return p.err
+			}
+			if dp.value == nil {
+				args[i] = reflect.Zero(p.fn.Type().In(i))
+			} else {
+				args[i] = reflect.ValueOf(dp.value)
+			}
+		}
+		out := p.fn.Call(args)
+		p.value = out[0].Interface()
+		if len(out) == 2 && !out[1].IsNil() {
+			p.err = fmt.Errorf("provider %s: %w", p.name, out[1].Interface().(error))
+		}
+	}
+	return p.err
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// goKind returns the kinds of the values to which values of type t may be
+// encoded.
+func goKind(t reflect.Type) cue.Kind {
+	if t == nil {
+		return cue.NullKind
+	}
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return cue.TopKind
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return cue.BoolKind
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cue.IntKind
+	case reflect.Float32, reflect.Float64:
+		return cue.FloatKind
+	case reflect.String:
+		return cue.StringKind
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return cue.BytesKind | cue.NullKind
+		}
+		return cue.ListKind | cue.NullKind
+	case reflect.Array:
+		return cue.ListKind
+	case reflect.Map:
+		return cue.StructKind | cue.NullKind
+	case reflect.Struct:
+		return cue.StructKind
+	case reflect.Pointer:
+		return goKind(t.Elem()) | cue.NullKind
+	}
+	return cue.TopKind
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/tools/inject"
+)
+
+const config = `
+db: {
+	host:     string @inject(dbHost)
+	port:     *5432 | int @inject(dbPort)
+	url:      string @inject(dbURL)
+}
+version!: string
+replicas: [...int]
+servers: [{name: string @inject(server)}]
+#Def: x!: int
+opt?: y!: int
+`
+
+func TestHoles(t *testing.T) {
+	v := cuecontext.New().CompileString(config)
+	holes, err := inject.Holes(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, h := range holes {
+		got = append(got, fmt.Sprintf("%s %s %v", h.Path, h.Name, h.Optional))
+	}
+	want := []string{
+		"db.host dbHost false",
+		"db.port dbPort true",
+		"db.url dbURL false",
+		"version version false",
+		"servers[0].name server false",
+	}
+	if g, w := strings.Join(got, "\n"), strings.Join(want, "\n"); g != w {
+		t.Errorf("got:\n%s\nwant:\n%s", g, w)
+	}
+}
+
+func TestBind(t *testing.T) {
+	calls := map[string]int{}
+	b := inject.NewBinder()
+	must(t, b.Supply("dbHost", "db.internal"))
+	must(t, b.Provide("dbURL", func(host string, port int) string {
+		calls["dbURL"]++
+		return fmt.Sprintf("postgres://%s:%d", host, port)
+	}, "dbHost", "port"))
+	must(t, b.Provide("port", func() int {
+		calls["port"]++
+		return 6543
+	}))
+	must(t, b.Supply("version", "v1.2.3"))
+	must(t, b.Supply("server", "a"))
+	must(t, b.Provide("unused", func() int {
+		calls["unused"]++
+		return 0
+	}))
+
+	v := cuecontext.New().CompileString(config)
+	v, err := b.Bind(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprint(v.LookupPath(cue.ParsePath("db")))
+	want := `{
+	host: "db.internal"
+	port: *5432 | int
+	url:  "postgres://db.internal:6543"
+}`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if s, _ := v.LookupPath(cue.ParsePath("version")).String(); s != "v1.2.3" {
+		t.Errorf("got version %q", s)
+	}
+	if calls["dbURL"] != 1 || calls["port"] != 1 || calls["unused"] != 0 {
+		t.Errorf("unexpected calls %v", calls)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		setup func(b *inject.Binder)
+		err   string
+	}{{
+		name: "missing",
+		setup: func(b *inject.Binder) {
+			b.Supply("dbHost", "h")
+		},
+		err: `db.url: no provider for hole dbURL
+version: no provider for hole version
+servers[0].name: no provider for hole server`,
+	}, {
+		name: "kind",
+		setup: func(b *inject.Binder) {
+			supplyAll(b)
+			b.Supply("dbURL", "u")
+			b.Supply("dbPort", "5432")
+		},
+		err: `db.port: provider dbPort returns string, which cannot be int`,
+	}, {
+		name: "cycle",
+		setup: func(b *inject.Binder) {
+			supplyAll(b)
+			b.Provide("dbURL", func(string) string { return "" }, "a")
+			b.Provide("a", func(string) string { return "" }, "b")
+			b.Provide("b", func(string) string { return "" }, "a")
+		},
+		err: `cycle in providers: a -> b -> a`,
+	}, {
+		name: "dependency",
+		setup: func(b *inject.Binder) {
+			supplyAll(b)
+			b.Provide("dbURL", func(int) string { return "" }, "dbHost")
+		},
+		err: `provider dbURL: dependency dbHost returns string, which cannot be used as int`,
+	}, {
+		name: "unknownDependency",
+		setup: func(b *inject.Binder) {
+			supplyAll(b)
+			b.Provide("dbURL", func(int) string { return "" }, "nope")
+		},
+		err: `provider dbURL: no provider for dependency nope`,
+	}, {
+		name: "provider",
+		setup: func(b *inject.Binder) {
+			supplyAll(b)
+			b.Provide("dbURL", func() (string, error) { return "", fmt.Errorf("unavailable") })
+		},
+		err: `provider dbURL: unavailable`,
+	}, {
+		name: "constraint",
+		setup: func(b *inject.Binder) {
+			supplyAll(b)
+			b.Supply("dbURL", "u")
+			b.Supply("dbPort", -1)
+		},
+		err: `provider dbPort: db.port: 2 errors in empty disjunction:
+provider dbPort: db.port: conflicting values 5432 and -1
+provider dbPort: db.port: invalid value -1 (out of bound >0)`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := config
+			if tc.name == "constraint" {
+				src = strings.Replace(src, "*5432 | int", "*5432 | int & >0", 1)
+			}
+			v := cuecontext.New().CompileString(src)
+			b := inject.NewBinder()
+			tc.setup(b)
+			_, err := b.Bind(v)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var msgs []string
+			for _, e := range errors.Errors(err) {
+				msgs = append(msgs, e.Error())
+			}
+			if got := strings.Join(msgs, "\n"); got != tc.err {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.err)
+			}
+		})
+	}
+}
+
+func TestProvideInvalid(t *testing.T) {
+	b := inject.NewBinder()
+	if err := b.Provide("a", 1); err == nil {
+		t.Error("expected error for non-function")
+	}
+	if err := b.Provide("a", func(int) int { return 0 }); err == nil {
+		t.Error("expected error for missing dependency argument")
+	}
+	if err := b.Provide("a", func() (int, int) { return 0, 0 }); err == nil {
+		t.Error("expected error for second result that is not an error")
+	}
+	must(t, b.Supply("a", 1))
+	if err := b.Supply("a", 2); err == nil {
+		t.Error("expected error for duplicate provider")
+	}
+}
+
+func supplyAll(b *inject.Binder) {
+	b.Supply("dbHost", "h")
+	b.Supply("version", "v")
+	b.Supply("server", "s")
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metadata extracts the doc comments and attributes of the
+// fields of a value, which are lost when the value is exported as plain
+// data, so that they can be published alongside it.
+//
+// The metadata of a value maps the paths of its regular fields and list
+// elements that have doc comments or attributes to these. Its JSON
+// encoding is an object keyed by CUE path, in the order of the fields of
+// the value. For instance, given
+//
+//	#Service: {
+//		// Number of instances.
+//		replicas: int @ui(slider)
+//	}
+//	web: #Service & {replicas: 2}
+//
+// the metadata of the value is
+//
+//	{
+//		"web.replicas": {
+//			"doc": "Number of instances.",
+//			"attributes": [{"name": "ui", "contents": "slider"}]
+//		}
+//	}
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"cuelang.org/go/cue"
+)
+
+// Metadata holds the doc comments and attributes of a value.
+type Metadata []Entry
+
+// An Entry holds the doc comments and attributes of a field or list
+// element.
+type Entry struct {
+	// Path is the path of the value, relative to the value whose
+	// metadata was extracted.
+	Path cue.Path `json:"-"`
+
+	// Doc holds the doc comments of the value, without comment markers.
+	// The doc comments of different declarations of the value, such as a
+	// schema and the data unified with it, are separated by a blank line.
+	Doc string `json:"doc,omitempty"`
+
+	// Attributes holds the field and declaration attributes of the value.
+	Attributes []Attribute `json:"attributes,omitempty"`
+}
+
+// An Attribute is a field or declaration attribute, such as @ui(slider).
+type Attribute struct {
+	// Name is the name of the attribute, such as ui.
+	Name string `json:"name"`
+
+	// Contents holds the text between the parentheses of the attribute,
+	// such as slider.
+	Contents string `json:"contents"`
+}
+
+// Extract returns the metadata of the regular fields and list elements
+// of v, recursively. Definitions, hidden fields and optional fields are
+// skipped, as they are not exported.
+func Extract(v cue.Value) Metadata {
+	var m Metadata
+	m.extract(v, nil)
+	return m
+}
+
+func (m *Metadata) extract(v cue.Value, path []cue.Selector) {
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := v.Fields()
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			m.add(iter.Value(), append(path, iter.Selector()))
+		}
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return
+		}
+		for i := 0; iter.Next(); i++ {
+			m.add(iter.Value(), append(path, cue.Index(i)))
+		}
+	}
+}
+
+// add adds the entry of value v at path, if it has any metadata, and
+// those of its fields and elements.
+func (m *Metadata) add(v cue.Value, path []cue.Selector) {
+	path = path[:len(path):len(path)]
+	e := Entry{Path: cue.MakePath(path...)}
+	var docs []string
+	for _, cg := range v.Doc() {
+		doc := strings.TrimSpace(cg.Text())
+		if doc != "" && !contains(docs, doc) {
+			docs = append(docs, doc)
+		}
+	}
+	e.Doc = strings.Join(docs, "\n\n")
+	for _, a := range v.Attributes(cue.ValueAttr) {
+		e.Attributes = append(e.Attributes, Attribute{
+			Name:     a.Name(),
+			Contents: a.Contents(),
+		})
+	}
+	if e.Doc != "" || len(e.Attributes) > 0 {
+		*m = append(*m, e)
+	}
+	m.extract(v, path)
+}
+
+func contains(list []string, s string) bool {
+	for _, t := range list {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON encodes m as a JSON object keyed by the paths of its
+// entries, in order.
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, e := range m {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(e.Path.String())
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		value, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
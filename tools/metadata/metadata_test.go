@@ -0,0 +1,113 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/metadata"
+)
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		out  string
+	}{{
+		name: "schema and data",
+		in: `
+#Service: {
+	// Number of instances.
+	replicas: int @ui(slider,min=1)
+
+	// Internal name.
+	_id?: string
+}
+
+// The web frontend.
+web: #Service & {
+	// Scaled by hand.
+	replicas: 2
+}
+`,
+		out: `{
+	"web": {
+		"doc": "The web frontend."
+	},
+	"web.replicas": {
+		"doc": "Number of instances.\n\nScaled by hand.",
+		"attributes": [
+			{
+				"name": "ui",
+				"contents": "slider,min=1"
+			}
+		]
+	}
+}`,
+	}, {
+		name: "lists and quoted labels",
+		in: `
+ports: [
+	80,
+	{port: 443, tls: true @protocol(tls)},
+]
+"app.kubernetes.io/name": "web" @label()
+`,
+		out: `{
+	"ports[1].tls": {
+		"attributes": [
+			{
+				"name": "protocol",
+				"contents": "tls"
+			}
+		]
+	},
+	"\"app.kubernetes.io/name\"": {
+		"attributes": [
+			{
+				"name": "label",
+				"contents": ""
+			}
+		]
+	}
+}`,
+	}, {
+		name: "none",
+		in:   `a: {b: 1}`,
+		out:  `{}`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := cuecontext.New().CompileString(tc.in)
+			if err := v.Err(); err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(metadata.Extract(v))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			if err := json.Indent(&got, data, "", "\t"); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tc.out {
+				t.Errorf("got:\n%s\nwant:\n%s", got.String(), tc.out)
+			}
+		})
+	}
+}
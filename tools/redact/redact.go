@@ -0,0 +1,250 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redact replaces sensitive values in the output of a
+// configuration with a placeholder.
+//
+// A value is sensitive if its field has a @sensitive attribute, for
+// instance
+//
+//	#Database: {
+//		user:     string
+//		password: string @sensitive()
+//	}
+//
+// Like other field attributes, the attribute applies to any value the
+// field is unified with, including fields matched by a pattern constraint
+// that has the attribute. Values can also be selected by a path pattern.
+//
+// Redaction applies to the output only: values should be validated before
+// they are redacted, as the placeholder need not satisfy the constraints
+// of the values it replaces.
+package redact
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// DefaultPlaceholder is the placeholder used if none is configured.
+const DefaultPlaceholder = "<redacted>"
+
+// A Config defines which values are redacted and how.
+type Config struct {
+	// Placeholder is the string that replaces a sensitive value. It
+	// defaults to DefaultPlaceholder.
+	Placeholder string
+
+	// Paths holds additional patterns for the paths of values to redact.
+	// A pattern consists of elements separated by dots, each of which is
+	// matched against the corresponding label of a path using the syntax
+	// of path.Match. List elements are matched by their index. For
+	// instance, "db.*.password" matches the password field of any field
+	// of db.
+	Paths []string
+}
+
+func (c *Config) placeholder() string {
+	if c == nil || c.Placeholder == "" {
+		return DefaultPlaceholder
+	}
+	return c.Placeholder
+}
+
+type redactor struct {
+	patterns [][]string
+}
+
+func newRedactor(cfg *Config) (*redactor, error) {
+	r := &redactor{}
+	if cfg == nil {
+		return r, nil
+	}
+	for _, s := range cfg.Paths {
+		p := strings.Split(s, ".")
+		for _, elem := range p {
+			if _, err := path.Match(elem, ""); err != nil || elem == "" {
+				return nil, errors.Newf(token.NoPos, "invalid redaction pattern %q", s)
+			}
+		}
+		r.patterns = append(r.patterns, p)
+	}
+	return r, nil
+}
+
+// sensitive reports whether the value v at path p is to be redacted.
+func (r *redactor) sensitive(p []string, v cue.Value) bool {
+	if a := v.Attribute("sensitive"); a.Err() == nil {
+		return true
+	}
+	for _, pat := range r.patterns {
+		if matches(pat, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(pattern, p []string) bool {
+	if len(pattern) != len(p) {
+		return false
+	}
+	for i, elem := range pattern {
+		if ok, _ := path.Match(elem, p[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Paths returns the paths of the values in v that are redacted. Only
+// regular fields and list elements are considered: a value within a
+// sensitive value is not reported separately.
+func Paths(v cue.Value, cfg *Config) ([]cue.Path, error) {
+	r, err := newRedactor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var paths []cue.Path
+	r.walk(nil, nil, v, func(sels []cue.Selector) {
+		paths = append(paths, cue.MakePath(sels...))
+	})
+	return paths, nil
+}
+
+func (r *redactor) walk(sels []cue.Selector, labels []string, v cue.Value, f func([]cue.Selector)) {
+	if len(sels) > 0 && r.sensitive(labels, v) {
+		f(sels)
+		return
+	}
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := v.Fields()
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			sel := iter.Selector()
+			r.walk(append(sels[:len(sels):len(sels)], sel),
+				append(labels[:len(labels):len(labels)], sel.Unquoted()),
+				iter.Value(), f)
+		}
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return
+		}
+		for i := 0; iter.Next(); i++ {
+			r.walk(append(sels[:len(sels):len(sels)], cue.Index(i)),
+				append(labels[:len(labels):len(labels)], strconv.Itoa(i)),
+				iter.Value(), f)
+		}
+	}
+}
+
+// Syntax is like v.Syntax(opts...), but replaces the sensitive values of
+// v with the placeholder of cfg.
+func Syntax(v cue.Value, cfg *Config, opts ...cue.Option) (ast.Node, error) {
+	paths, err := Paths(v, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return syntax(v, paths, cfg, opts...), nil
+}
+
+func syntax(v cue.Value, paths []cue.Path, cfg *Config, opts ...cue.Option) ast.Node {
+	n := v.Syntax(opts...)
+	if len(paths) == 0 {
+		return n
+	}
+	set := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		set[p.String()] = true
+	}
+	rewrite(n, nil, set, cfg.placeholder())
+	return n
+}
+
+// rewrite replaces the values of the fields and list elements within n
+// whose path, when appended to sels, is in set.
+func rewrite(n ast.Node, sels []cue.Selector, set map[string]bool, placeholder string) {
+	replace := func(sel cue.Selector, x ast.Expr) ast.Expr {
+		s := append(sels[:len(sels):len(sels)], sel)
+		if set[cue.MakePath(s...).String()] {
+			lit := ast.NewString(placeholder)
+			ast.SetPos(lit, x.Pos())
+			return lit
+		}
+		rewrite(x, s, set, placeholder)
+		return x
+	}
+
+	switch x := n.(type) {
+	case *ast.File:
+		for _, d := range x.Decls {
+			rewrite(d, sels, set, placeholder)
+		}
+	case *ast.EmbedDecl:
+		rewrite(x.Expr, sels, set, placeholder)
+	case *ast.StructLit:
+		for _, d := range x.Elts {
+			rewrite(d, sels, set, placeholder)
+		}
+	case *ast.Field:
+		if x.Constraint != token.ILLEGAL {
+			return
+		}
+		name, _, err := ast.LabelName(x.Label)
+		if err != nil || strings.HasPrefix(name, "#") || strings.HasPrefix(name, "_") {
+			return
+		}
+		x.Value = replace(cue.Str(name), x.Value)
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			if _, ok := e.(*ast.Ellipsis); ok {
+				continue
+			}
+			x.Elts[i] = replace(cue.Index(i), e)
+		}
+	}
+}
+
+// Value returns a concrete copy of v in which the sensitive values are
+// replaced with the placeholder of cfg. It returns v itself if none of its
+// values are sensitive. Value is intended for encoding data; v should be
+// valid and concrete.
+func Value(v cue.Value, cfg *Config) (cue.Value, error) {
+	paths, err := Paths(v, cfg)
+	if err != nil || len(paths) == 0 {
+		return v, err
+	}
+	n := syntax(v, paths, cfg, cue.Final(), cue.Concrete(true))
+	var w cue.Value
+	switch x := n.(type) {
+	case *ast.File:
+		w = v.Context().BuildFile(x)
+	case ast.Expr:
+		w = v.Context().BuildExpr(x)
+	default:
+		return v, fmt.Errorf("unexpected syntax %T", n)
+	}
+	return w, w.Err()
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-quicktest/qt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/tools/redact"
+)
+
+const config = `
+#Database: {
+	user:     string
+	password: string @sensitive()
+	[=~"Token$"]: string @sensitive()
+}
+db: #Database & {
+	user:     "admin"
+	password: "hunter2"
+	apiToken: "abc"
+}
+keys: [{id: 1, secret: "s1"}, {id: 2, secret: "s2"}]
+port: 5432
+`
+
+func TestPaths(t *testing.T) {
+	v := cuecontext.New().CompileString(config)
+	paths, err := redact.Paths(v, &redact.Config{Paths: []string{"keys.*.secret"}})
+	qt.Assert(t, qt.IsNil(err))
+
+	var got []string
+	for _, p := range paths {
+		got = append(got, p.String())
+	}
+	qt.Assert(t, qt.DeepEquals(got, []string{
+		"db.password",
+		"db.apiToken",
+		"keys[0].secret",
+		"keys[1].secret",
+	}))
+
+	_, err = redact.Paths(v, &redact.Config{Paths: []string{"keys.[.secret"}})
+	qt.Assert(t, qt.ErrorMatches(err, `invalid redaction pattern "keys.\[.secret"`))
+}
+
+func TestSyntax(t *testing.T) {
+	v := cuecontext.New().CompileString(config)
+	n, err := redact.Syntax(v, &redact.Config{Placeholder: "***"}, cue.Final(), cue.Concrete(true))
+	qt.Assert(t, qt.IsNil(err))
+	b, err := format.Node(n)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(string(b), `{
+	db: {
+		user:     "admin"
+		password: "***" @sensitive()
+		apiToken: "***" @sensitive()
+	}
+	keys: [{
+		id:     1
+		secret: "s1"
+	}, {
+		id:     2
+		secret: "s2"
+	}]
+	port: 5432
+}`))
+}
+
+func TestValue(t *testing.T) {
+	v := cuecontext.New().CompileString(config)
+	w, err := redact.Value(v, &redact.Config{Paths: []string{"port"}})
+	qt.Assert(t, qt.IsNil(err))
+	for p, want := range map[string]string{
+		"db.user":        `"admin"`,
+		"db.password":    `"<redacted>"`,
+		"db.apiToken":    `"<redacted>"`,
+		"keys[0].secret": `"s1"`,
+		"port":           `"<redacted>"`,
+	} {
+		got := fmt.Sprint(w.LookupPath(cue.ParsePath(p)))
+		qt.Check(t, qt.Equals(got, want), qt.Commentf("path %s", p))
+	}
+
+	// Values without sensitive values are returned as is.
+	v = v.LookupPath(cue.ParsePath("keys"))
+	w, err = redact.Value(v, nil)
+	qt.Assert(t, qt.IsNil(err))
+	qt.Assert(t, qt.Equals(w, v))
+}
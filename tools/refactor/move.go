@@ -0,0 +1,585 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package refactor implements automated refactorings of CUE packages.
+//
+// Refactorings operate on the syntax trees of a set of loaded packages and
+// modify them in place. Comments and formatting of the affected declarations
+// are preserved. It is up to the caller to write back the modified files.
+package refactor
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/mod/module"
+)
+
+// MoveConfig configures MoveDefinition.
+type MoveConfig struct {
+	// File is the name of the file in the destination package to which
+	// the definition is moved. If it does not exist, a new file is created.
+	// If File is empty, the definition is moved to the file in the
+	// destination package with the same base name as the file in which
+	// the definition was declared.
+	File string
+
+	// Dir is the directory of the destination package. It is only used
+	// if the destination package is not among the given instances and
+	// File is empty.
+	Dir string
+}
+
+// MoveDefinition moves all top-level declarations of the definition with the
+// given name, such as "#Foo", from the package with import path from to the
+// package with import path to.
+//
+// References to the definition in all the given instances are updated to
+// refer to its new location, adding and removing imports as necessary.
+// References from the moved declarations to the source package are
+// qualified with an import of that package. The destination package need not
+// exist yet, in which case a new file is created for it.
+//
+// MoveDefinition returns the files that were modified or created.
+func MoveDefinition(insts []*build.Instance, name, from, to string, cfg *MoveConfig) ([]*ast.File, error) {
+	if cfg == nil {
+		cfg = &MoveConfig{}
+	}
+	if !strings.HasPrefix(name, "#") {
+		return nil, errors.Newf(token.NoPos, "%s is not a definition", name)
+	}
+
+	m := &mover{
+		name:     name,
+		from:     from,
+		to:       to,
+		modified: map[*ast.File]bool{},
+	}
+	for _, b := range insts {
+		if b.ImportPath == from {
+			m.src = b
+		}
+		if b.ImportPath == to {
+			m.dst = b
+		}
+	}
+	if m.src == nil {
+		return nil, errors.Newf(token.NoPos, "package %q not found", from)
+	}
+
+	if err := m.collect(); err != nil {
+		return nil, err
+	}
+	target, err := m.targetFile(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if from != to {
+		// Check that the move is possible before changing anything.
+		if err := m.checkMoved(); err != nil {
+			return nil, err
+		}
+		if m.createsCycle(insts) {
+			return nil, errors.Newf(token.NoPos,
+				"moving %s from %s to %s would create an import cycle", name, from, to)
+		}
+		m.rewriteMoved()
+		m.rewriteSource()
+		for _, b := range insts {
+			if b != m.src {
+				m.rewriteImporter(b)
+			}
+		}
+	}
+
+	// Move the declarations.
+	for _, f := range m.src.Files {
+		k := 0
+		for _, d := range f.Decls {
+			if !m.isMoved(d) {
+				f.Decls[k] = d
+				k++
+			}
+		}
+		if k != len(f.Decls) {
+			f.Decls = f.Decls[:k]
+			m.modified[f] = true
+		}
+	}
+	for i, d := range m.moved {
+		if i == 0 || len(target.Decls) > 0 {
+			ast.SetRelPos(d, token.NewSection)
+		}
+		target.Decls = append(target.Decls, d)
+	}
+	m.modified[target] = true
+
+	var errs errors.Error
+	var files []*ast.File
+	for _, f := range m.allFiles(insts, target) {
+		if !m.modified[f] {
+			continue
+		}
+		if err := astutil.Sanitize(f); err != nil {
+			errs = errors.Append(errs, errors.Promote(err, "sanitize"))
+		}
+		removeEmptyImports(f)
+		files = append(files, f)
+	}
+	return files, errs
+}
+
+type mover struct {
+	name     string
+	from, to string
+
+	src, dst *build.Instance
+
+	// moved holds the declarations of the definition in the source package.
+	moved []ast.Decl
+	// srcFile holds the file of the first moved declaration.
+	srcFile *ast.File
+
+	srcTop map[string]bool
+
+	modified map[*ast.File]bool
+}
+
+func (m *mover) isMoved(d ast.Decl) bool {
+	for _, x := range m.moved {
+		if x == d {
+			return true
+		}
+	}
+	return false
+}
+
+// collect finds the declarations to move and the top-level names of the
+// source package.
+func (m *mover) collect() error {
+	m.srcTop = topLevelNames(m.src.Files)
+	for _, f := range m.src.Files {
+		for _, d := range f.Decls {
+			if field, ok := d.(*ast.Field); ok && labelName(field.Label) == m.name {
+				if m.srcFile == nil {
+					m.srcFile = f
+				}
+				m.moved = append(m.moved, d)
+			}
+		}
+	}
+	if len(m.moved) == 0 {
+		return errors.Newf(token.NoPos, "definition %s not found in package %q", m.name, m.from)
+	}
+	return nil
+}
+
+// targetFile returns the file in the destination package to which
+// declarations should be moved, creating it if necessary.
+func (m *mover) targetFile(cfg *MoveConfig) (*ast.File, error) {
+	filename := cfg.File
+	if filename == "" {
+		base := filepath.Base(m.srcFile.Filename)
+		switch {
+		case m.dst != nil:
+			filename = filepath.Join(m.dst.Dir, base)
+		case cfg.Dir != "":
+			filename = filepath.Join(cfg.Dir, base)
+		default:
+			return nil, errors.Newf(token.NoPos,
+				"no directory or file specified for new package %q", m.to)
+		}
+	}
+	if m.dst != nil {
+		for _, f := range m.dst.Files {
+			if f.Filename == filename {
+				return f, nil
+			}
+		}
+		if m.from == m.to && filename == m.srcFile.Filename {
+			return nil, errors.Newf(token.NoPos, "%s is already declared in %s", m.name, filename)
+		}
+	}
+	pkgName := module.ParseImportPath(m.to).Qualifier
+	if m.dst != nil && m.dst.PkgName != "" {
+		pkgName = m.dst.PkgName
+	}
+	return &ast.File{
+		Filename: filename,
+		Decls:    []ast.Decl{&ast.Package{Name: ast.NewIdent(pkgName)}},
+	}, nil
+}
+
+// allFiles returns all files in insts followed by the created file, if any.
+func (m *mover) allFiles(insts []*build.Instance, target *ast.File) []*ast.File {
+	var files []*ast.File
+	seen := map[*ast.File]bool{}
+	for _, b := range insts {
+		for _, f := range b.Files {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	if !seen[target] {
+		files = append(files, target)
+	}
+	return files
+}
+
+// checkMoved reports an error if the moved declarations reference
+// top-level values of the source package that cannot be referenced from
+// another package.
+func (m *mover) checkMoved() error {
+	var errs errors.Error
+	for _, d := range m.moved {
+		walkRefs(d, func(c astutil.Cursor) bool {
+			x, ok := c.Node().(*ast.Ident)
+			if !ok || x.Name == m.name || !m.isPackageRef(x, m.srcTop) {
+				return true
+			}
+			if let, ok := x.Node.(*ast.LetClause); ok {
+				errs = errors.Append(errs, errors.Newf(x.Pos(),
+					"cannot move %s: it references let clause %s", m.name, let.Ident.Name))
+			} else if strings.HasPrefix(x.Name, "_") {
+				errs = errors.Append(errs, errors.Newf(x.Pos(),
+					"cannot move %s: it references hidden field %s", m.name, x.Name))
+			}
+			return false
+		})
+	}
+	return errs
+}
+
+// createsCycle reports whether the packages would import each other in a
+// cycle after the move. Imports between the given instances are derived
+// from the references that remain after the move, while those of other
+// packages are taken from their build instances.
+func (m *mover) createsCycle(insts []*build.Instance) bool {
+	graph := map[string]map[string]bool{}
+	addEdge := func(from, to string) {
+		if from == to {
+			return
+		}
+		if graph[from] == nil {
+			graph[from] = map[string]bool{}
+		}
+		graph[from][to] = true
+	}
+
+	rewritten := map[*build.Instance]bool{}
+	for _, b := range insts {
+		rewritten[b] = true
+	}
+	var addDeps func(b *build.Instance)
+	seen := map[*build.Instance]bool{}
+	addDeps = func(b *build.Instance) {
+		if seen[b] {
+			return
+		}
+		seen[b] = true
+		for _, imp := range b.Imports {
+			if !rewritten[b] {
+				addEdge(b.ImportPath, imp.ImportPath)
+			}
+			addDeps(imp)
+		}
+	}
+
+	for _, b := range insts {
+		addDeps(b)
+		for _, f := range b.Files {
+			for _, d := range f.Decls {
+				if _, ok := d.(*ast.ImportDecl); ok {
+					continue
+				}
+				owner := b.ImportPath
+				moved := b == m.src && m.isMoved(d)
+				if moved {
+					owner = m.to
+				}
+				walkRefs(d, func(c astutil.Cursor) bool {
+					switch x := c.Node().(type) {
+					case *ast.SelectorExpr:
+						path, ok := importPath(x.X)
+						if !ok {
+							return true
+						}
+						if path == m.from && labelName(x.Sel) == m.name {
+							path = m.to
+						}
+						addEdge(owner, path)
+						return false
+
+					case *ast.Ident:
+						if b != m.src || !m.isPackageRef(x, m.srcTop) {
+							return true
+						}
+						switch {
+						case moved && x.Name != m.name:
+							addEdge(m.to, m.from)
+						case !moved && x.Name == m.name:
+							addEdge(m.from, m.to)
+						}
+					}
+					return true
+				})
+			}
+		}
+	}
+
+	// The packages did not import each other in a cycle before the move,
+	// so any cycle after it involves a changed package.
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(p string) bool
+	visit = func(p string) bool {
+		switch state[p] {
+		case visiting:
+			return true
+		case done:
+			return false
+		}
+		state[p] = visiting
+		for q := range graph[p] {
+			if visit(q) {
+				return true
+			}
+		}
+		state[p] = done
+		return false
+	}
+	for p := range graph {
+		if visit(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteMoved updates the references in the moved declarations to be valid
+// within the destination package.
+func (m *mover) rewriteMoved() {
+	for i, d := range m.moved {
+		m.moved[i] = walkRefs(d, func(c astutil.Cursor) bool {
+			switch x := c.Node().(type) {
+			case *ast.SelectorExpr:
+				if isImportRef(x.X, m.to) {
+					id := ast.NewIdent(labelName(x.Sel))
+					astutil.CopyMeta(id, x.X)
+					c.Replace(id)
+					return false
+				}
+
+			case *ast.Ident:
+				if x.Name == m.name || !m.isPackageRef(x, m.srcTop) {
+					return true
+				}
+				c.Replace(qualify(m.from, x.Name, x))
+				return false
+			}
+			return true
+		}).(ast.Decl)
+	}
+}
+
+// rewriteSource updates references to the moved definition in the remaining
+// declarations of the source package.
+func (m *mover) rewriteSource() {
+	for _, f := range m.src.Files {
+		for i, d := range f.Decls {
+			if m.isMoved(d) {
+				continue
+			}
+			f.Decls[i] = walkRefs(d, func(c astutil.Cursor) bool {
+				x, ok := c.Node().(*ast.Ident)
+				if !ok || x.Name != m.name || !m.isPackageRef(x, m.srcTop) {
+					return true
+				}
+				c.Replace(qualify(m.to, m.name, x))
+				m.modified[f] = true
+				return false
+			}).(ast.Decl)
+		}
+	}
+}
+
+// rewriteImporter updates references to the moved definition through an
+// import of the source package in the files of b.
+func (m *mover) rewriteImporter(b *build.Instance) {
+	for _, f := range b.Files {
+		for i, d := range f.Decls {
+			if _, ok := d.(*ast.ImportDecl); ok {
+				continue
+			}
+			f.Decls[i] = walkRefs(d, func(c astutil.Cursor) bool {
+				x, ok := c.Node().(*ast.SelectorExpr)
+				if !ok || labelName(x.Sel) != m.name || !isImportRef(x.X, m.from) {
+					return true
+				}
+				if b == m.dst {
+					id := ast.NewIdent(m.name)
+					astutil.CopyMeta(id, x.X)
+					c.Replace(id)
+				} else {
+					c.Replace(qualify(m.to, m.name, x.X))
+				}
+				m.modified[f] = true
+				return false
+			}).(ast.Decl)
+		}
+	}
+}
+
+// isPackageRef reports whether x refers to a top-level field of the package
+// with the given top-level names.
+func (m *mover) isPackageRef(x *ast.Ident, top map[string]bool) bool {
+	switch x.Scope.(type) {
+	case *ast.File:
+		return true
+	case nil:
+		return x.Node == nil && top[x.Name]
+	}
+	return false
+}
+
+// walkRefs calls fn for all nodes in n except identifiers that declare,
+// rather than reference, a value. Nodes may be replaced using the cursor.
+func walkRefs(n ast.Node, fn func(c astutil.Cursor) bool) ast.Node {
+	return astutil.Apply(n, func(c astutil.Cursor) bool {
+		if x, ok := c.Node().(*ast.Ident); ok && isDeclIdent(c, x) {
+			return false
+		}
+		return fn(c)
+	}, nil)
+}
+
+func isDeclIdent(c astutil.Cursor, x *ast.Ident) bool {
+	p := c.Parent()
+	if p == nil {
+		return false
+	}
+	var n ast.Node = x
+	switch y := p.Node().(type) {
+	case *ast.Field:
+		return y.Label == n
+	case *ast.Alias:
+		return y.Ident == x
+	case *ast.LetClause:
+		return y.Ident == x
+	case *ast.ForClause:
+		return y.Key == x || y.Value == x
+	case *ast.SelectorExpr:
+		return y.Sel == n
+	case *ast.ImportSpec:
+		return y.Name == x
+	case *ast.Package:
+		return y.Name == x
+	}
+	return false
+}
+
+// qualify returns a reference to name in the package with the given import
+// path that takes the position and comments of the replaced node.
+func qualify(importPath, name string, replaced ast.Node) ast.Expr {
+	pkg := importRef(importPath)
+	astutil.CopyMeta(pkg, replaced)
+	return &ast.SelectorExpr{X: pkg, Sel: ast.NewIdent(name)}
+}
+
+// importRef returns an identifier referring to the package with the given
+// import path. The import is added by astutil.Sanitize.
+func importRef(importPath string) *ast.Ident {
+	spec := ast.NewImport(nil, importPath)
+	name := module.ParseImportPath(importPath).Qualifier
+	if name != astutil.ImportPathName(importPath) {
+		spec.Name = ast.NewIdent(name)
+	}
+	id := ast.NewIdent(name)
+	id.Node = spec
+	return id
+}
+
+// isImportRef reports whether x is an identifier referring to an import of
+// the package with the given import path.
+func isImportRef(x ast.Expr, path string) bool {
+	p, ok := importPath(x)
+	return ok && p == path
+}
+
+// importPath returns the import path of the package that x refers to, if
+// x is an identifier referring to an import.
+func importPath(x ast.Expr) (string, bool) {
+	id, ok := x.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	spec, ok := id.Node.(*ast.ImportSpec)
+	if !ok {
+		return "", false
+	}
+	path, err := strconv.Unquote(spec.Path.Value)
+	return path, err == nil
+}
+
+func topLevelNames(files []*ast.File) map[string]bool {
+	names := map[string]bool{}
+	for _, f := range files {
+		for _, d := range f.Decls {
+			if field, ok := d.(*ast.Field); ok {
+				if name := labelName(field.Label); name != "" {
+					names[name] = true
+				}
+			}
+		}
+	}
+	return names
+}
+
+func labelName(l ast.Label) string {
+	switch x := l.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.Alias:
+		if id, ok := x.Expr.(*ast.Ident); ok {
+			return id.Name
+		}
+	}
+	return ""
+}
+
+// removeEmptyImports removes import declarations without any specs, as may
+// be left behind by astutil.Sanitize.
+func removeEmptyImports(f *ast.File) {
+	k := 0
+	for _, d := range f.Decls {
+		if x, ok := d.(*ast.ImportDecl); ok && len(x.Specs) == 0 {
+			continue
+		}
+		f.Decls[k] = d
+		k++
+	}
+	f.Decls = f.Decls[:k]
+}
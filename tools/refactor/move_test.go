@@ -0,0 +1,262 @@
+// Copyright 2024 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refactor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/internal/cuetxtar"
+)
+
+func TestMoveDefinition(t *testing.T) {
+	testCases := []struct {
+		name     string
+		in       string
+		def      string
+		from, to string
+		file     string
+		out      string
+		err      string
+	}{{
+		name: "within package",
+		def:  "#A",
+		from: "mod.test/a",
+		to:   "mod.test/a",
+		file: "a/b.cue",
+		in: `
+-- cue.mod/module.cue --
+module: "mod.test"
+-- a/a.cue --
+package a
+
+// A is an A.
+#A: {b: #B}
+-- a/b.cue --
+package a
+
+#B: int
+`,
+		out: `
+-- a/a.cue --
+package a
+-- a/b.cue --
+package a
+
+#B: int
+
+// A is an A.
+#A: {b: #B}
+`,
+	}, {
+		name: "into importing package",
+		def:  "#A",
+		from: "mod.test/a",
+		to:   "mod.test/b",
+		in: `
+-- cue.mod/module.cue --
+module: "mod.test"
+-- a/x.cue --
+package a
+
+#A: {c: #C}
+#C: string
+-- b/x.cue --
+package b
+
+import "mod.test/a"
+
+x: a.#A
+`,
+		out: `
+-- a/x.cue --
+package a
+
+#C: string
+-- b/x.cue --
+package b
+
+import "mod.test/a"
+
+x: #A
+
+#A: {c: a.#C}
+`,
+	}, {
+		name: "cycle",
+		def:  "#A",
+		from: "mod.test/a",
+		to:   "mod.test/b",
+		in: `
+-- cue.mod/module.cue --
+module: "mod.test"
+-- a/x.cue --
+package a
+
+#A: {c: #C}
+#C: string
+#D: #A
+-- b/x.cue --
+package b
+`,
+		err: "moving #A from mod.test/a to mod.test/b would create an import cycle",
+	}, {
+		name: "cycleWithExistingImport",
+		def:  "#A",
+		from: "mod.test/a",
+		to:   "mod.test/b",
+		in: `
+-- cue.mod/module.cue --
+module: "mod.test"
+-- a/x.cue --
+package a
+
+#A: string
+#B: #A
+-- b/x.cue --
+package b
+
+import "mod.test/a"
+
+x: a.#B
+`,
+		err: "moving #A from mod.test/a to mod.test/b would create an import cycle",
+	}, {
+		name: "cycleThroughImporter",
+		def:  "#A",
+		from: "mod.test/a",
+		to:   "mod.test/b",
+		in: `
+-- cue.mod/module.cue --
+module: "mod.test"
+-- a/x.cue --
+package a
+
+#A: string
+-- b/x.cue --
+package b
+
+import "mod.test/c"
+
+x: c.y
+-- c/x.cue --
+package c
+
+import "mod.test/a"
+
+y: a.#A
+`,
+		err: "moving #A from mod.test/a to mod.test/b would create an import cycle",
+	}, {
+		name: "hidden",
+		def:  "#A",
+		from: "mod.test/a",
+		to:   "mod.test/b",
+		in: `
+-- cue.mod/module.cue --
+module: "mod.test"
+-- a/x.cue --
+package a
+
+#A: {c: _c}
+_c: string
+-- b/x.cue --
+package b
+`,
+		err: "cannot move #A: it references hidden field _c",
+	}, {
+		name: "not a definition",
+		def:  "a",
+		from: "mod.test/a",
+		to:   "mod.test/b",
+		in: `
+-- cue.mod/module.cue --
+module: "mod.test"
+-- a/x.cue --
+package a
+
+a: 1
+`,
+		err: "a is not a definition",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			insts := cuetxtar.Load(txtar.Parse([]byte(tc.in)), dir, "./...")
+			for _, b := range insts {
+				if b.Err != nil {
+					t.Fatal(b.Err)
+				}
+			}
+			cfg := &MoveConfig{}
+			if tc.file != "" {
+				cfg.File = filepath.Join(dir, tc.file)
+			}
+			before := formatAll(t, insts)
+			files, err := MoveDefinition(insts, tc.def, tc.from, tc.to, cfg)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("got error %v; want %q", err, tc.err)
+				}
+				// Nothing is changed if the definition cannot be moved.
+				if got := formatAll(t, insts); got != before {
+					t.Errorf("files were changed:\n%s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			a := &txtar.Archive{}
+			for _, f := range files {
+				b, err := format.Node(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rel, _ := filepath.Rel(dir, f.Filename)
+				a.Files = append(a.Files, txtar.File{
+					Name: filepath.ToSlash(rel),
+					Data: b,
+				})
+			}
+			got := string(txtar.Format(a))
+			if want := strings.TrimPrefix(tc.out, "\n"); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// formatAll returns the formatted files of insts.
+func formatAll(t *testing.T, insts []*build.Instance) string {
+	var sb strings.Builder
+	for _, b := range insts {
+		for _, f := range b.Files {
+			data, err := format.Node(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprintf(&sb, "-- %s --\n%s", f.Filename, data)
+		}
+	}
+	return sb.String()
+}
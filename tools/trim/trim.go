@@ -45,6 +45,11 @@
 //	light: ceiling50: {
 //		room: "MasterBedroom"
 //	}
+//
+// Values that originate from outside the files being trimmed, such as values
+// from imported packages, are never removed and are therefore also considered
+// to dominate values in the trimmed files. This allows removing fields that
+// merely repeat values or defaults declared in an imported package.
 package trim
 
 import (
@@ -78,9 +83,13 @@ func Files(files []*ast.File, inst cue.InstanceOrValue, cfg *Config) error {
 		ctx:     adt.NewContext(r, v),
 		remove:  map[ast.Node]bool{},
 		exclude: map[ast.Node]bool{},
+		files:   map[string]bool{},
 		debug:   Debug,
 		w:       os.Stderr,
 	}
+	for _, f := range files {
+		t.files[f.Filename] = true
+	}
 
 	// Mark certain expressions as off limits.
 	// TODO: We could alternatively ensure that comprehensions unconditionally
@@ -129,6 +138,7 @@ type trimmer struct {
 	ctx     *adt.OpContext
 	remove  map[ast.Node]bool
 	exclude map[ast.Node]bool
+	files   map[string]bool // names of the files being trimmed
 
 	debug  bool
 	indent int
@@ -158,8 +168,8 @@ func (t *trimmer) markKeep(x adt.Expr) {
 const dominatorNode = adt.ComprehensionSpan | adt.DefinitionSpan | adt.ConstraintSpan
 
 // isDominator reports whether a node can remove other nodes.
-func isDominator(c adt.Conjunct) (ok, mayRemove bool) {
-	if !c.CloseInfo.IsInOneOf(dominatorNode) {
+func (t *trimmer) isDominator(c adt.Conjunct) (ok, mayRemove bool) {
+	if !c.CloseInfo.IsInOneOf(dominatorNode) && !t.isForeign(c) {
 		return false, false
 	}
 	switch f := c.Field().(type) {
@@ -169,6 +179,18 @@ func isDominator(c adt.Conjunct) (ok, mayRemove bool) {
 	return true, true
 }
 
+// isForeign reports whether c originates from a file that is not being
+// trimmed, such as a file from an imported package. Such conjuncts are
+// never removed and may thus dominate conjuncts in the trimmed files.
+func (t *trimmer) isForeign(c adt.Conjunct) bool {
+	src := c.Elem().Source()
+	if src == nil {
+		return false
+	}
+	filename := src.Pos().Filename()
+	return filename != "" && !t.files[filename]
+}
+
 // Removable reports whether a non-dominator conjunct can be removed. This is
 // not the case if it has pattern constraints that could turn into dominator
 // nodes.
@@ -180,7 +202,7 @@ func removable(c adt.Conjunct, v *adt.Vertex) bool {
 // themselves as it will eliminate the reason for the trigger.
 func (t *trimmer) allowRemove(v *adt.Vertex) bool {
 	for _, c := range v.Conjuncts {
-		_, allowRemove := isDominator(c)
+		_, allowRemove := t.isDominator(c)
 		loc := c.CloseInfo.Location() != c.Elem()
 		isSpan := c.CloseInfo.RootSpanType() != adt.ConstraintSpan
 		if allowRemove && (loc || isSpan) {
@@ -229,7 +251,7 @@ func (t *trimmer) addDominators(d, v *adt.Vertex, hasDisjunction bool) (doms *ad
 
 	hasDoms := false
 	for _, c := range v.Conjuncts {
-		isDom, _ := isDominator(c)
+		isDom, _ := t.isDominator(c)
 		switch {
 		case isDom:
 			doms.AddConjunct(c)
@@ -338,7 +360,7 @@ func (t *trimmer) findSubordinates(doms, v *adt.Vertex, hasDisjunction bool) (re
 	}
 
 	for _, c := range v.Conjuncts {
-		_, allowRemove := isDominator(c)
+		_, allowRemove := t.isDominator(c)
 		if !allowRemove && removable(c, v) {
 			t.markRemove(c)
 		}
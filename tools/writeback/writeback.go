@@ -0,0 +1,651 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package writeback applies changes to the value of a CUE instance back to
+// its source files as minimal text edits.
+//
+// Programs that update configuration, such as bots bumping the versions of
+// container images, typically load a configuration, change it and
+// regenerate the files from the result, losing the comments and
+// formatting of the original. An Editor instead locates the declarations
+// of a path in the source and rewrites only the text of their values,
+// leaving the rest of the files untouched:
+//
+//	e, err := writeback.New(ctx, inst)
+//	...
+//	err = e.FillPath(cue.ParsePath("web.image"), "nginx:1.27")
+//	...
+//	for name, src := range e.Files() {
+//		os.WriteFile(name, src, 0o666)
+//	}
+//
+// Only declarations with a literal value, such as image: "nginx:1.26",
+// are rewritten. If a path has no such declaration, a field is added to
+// the innermost enclosing struct literal. After each edit, the instance is
+// rebuilt from the edited files, and the edit is rejected if the result
+// is invalid, for instance because the new value conflicts with a schema.
+//
+// The edited text is not reformatted: an added field is not aligned with
+// the fields around it until the file is formatted with cue fmt.
+package writeback
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// An Edit replaces the text between the byte offsets Start and End of a
+// file with Text.
+type Edit struct {
+	Filename   string
+	Start, End int
+	Text       string
+}
+
+// An Editor applies changes to the value of an instance to its CUE source
+// files.
+type Editor struct {
+	ctx   *cue.Context
+	inst  *build.Instance
+	files []*file
+	value cue.Value
+	edits []Edit
+}
+
+// A file is a file of the instance being edited.
+type file struct {
+	name string
+	orig []byte
+	src  []byte
+	ast  *ast.File
+
+	// editable reports whether the file is a CUE file whose source is
+	// known. The syntax of other files, such as data files, is used as is.
+	editable bool
+}
+
+// New returns an Editor for the files of inst, which must have been
+// loaded, and the instances it imports built, with ctx. The contents of
+// CUE files are taken from the Source field of the corresponding build
+// file, if it holds a []byte or string, or read from disk otherwise.
+func New(ctx *cue.Context, inst *build.Instance) (*Editor, error) {
+	sources := map[string]any{}
+	for _, f := range inst.BuildFiles {
+		sources[f.Filename] = f.Source
+	}
+	e := &Editor{ctx: ctx, inst: inst}
+	for _, af := range inst.Files {
+		f := &file{name: af.Filename, ast: af}
+		if strings.HasSuffix(f.name, ".cue") {
+			switch src := sources[f.name].(type) {
+			case []byte:
+				f.src = src
+			case string:
+				f.src = []byte(src)
+			default:
+				b, err := os.ReadFile(f.name)
+				if err != nil {
+					return nil, err
+				}
+				f.src = b
+			}
+			parsed, err := parser.ParseFile(f.name, f.src, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			f.orig, f.ast, f.editable = f.src, parsed, true
+		}
+		e.files = append(e.files, f)
+	}
+	v, err := e.build()
+	if err != nil {
+		return nil, err
+	}
+	e.value = v
+	return e, nil
+}
+
+// Value returns the value of the instance with all edits applied.
+func (e *Editor) Value() cue.Value {
+	return e.value
+}
+
+// Edits returns the edits applied so far, in order. The offsets of each
+// edit refer to the contents of the file after the edits preceding it.
+func (e *Editor) Edits() []Edit {
+	return append([]Edit(nil), e.edits...)
+}
+
+// Files returns the contents of the files that were changed, keyed by
+// filename.
+func (e *Editor) Files() map[string][]byte {
+	m := map[string][]byte{}
+	for _, f := range e.files {
+		if f.editable && !bytes.Equal(f.src, f.orig) {
+			m[f.name] = f.src
+		}
+	}
+	return m
+}
+
+// FillPath sets the value at path p to x, which is encoded as with
+// [cue.Context.Encode]. The literal values declared for p are replaced
+// with x. If there are none, a field is added to the innermost struct
+// literal enclosing p.
+//
+// It returns an error, and leaves the files unchanged, if the path cannot
+// be edited or the result is not valid.
+func (e *Editor) FillPath(p cue.Path, x any) error {
+	sels, err := selectors(p)
+	if err != nil {
+		return err
+	}
+	text, err := e.format(x)
+	if err != nil {
+		return err
+	}
+	levels := e.lookup(sels)
+
+	var edits []Edit
+	if len(levels) == len(sels)+1 {
+		for _, n := range levels[len(sels)] {
+			if isLiteral(n.expr) {
+				edits = append(edits, n.replace(text))
+			}
+		}
+	}
+	if edits == nil {
+		ed, err := insert(levels, sels, text)
+		if err != nil {
+			return wrapError(err, p, "set")
+		}
+		edits = append(edits, ed)
+	}
+	if err := e.apply(edits); err != nil {
+		return wrapError(err, p, "set")
+	}
+	return nil
+}
+
+// DeletePath removes all regular declarations of the field at path p from
+// the CUE files of the instance, along with their doc comments.
+//
+// It returns an error, and leaves the files unchanged, if there are no
+// such declarations or the result is not valid.
+func (e *Editor) DeletePath(p cue.Path) error {
+	sels, err := selectors(p)
+	if err != nil {
+		return err
+	}
+	if sels[len(sels)-1].Type() == cue.IndexLabel {
+		return errors.Newf(token.NoPos, "cannot delete %v: cannot delete list elements", p)
+	}
+	levels := e.lookup(sels)
+	if len(levels) < len(sels)+1 {
+		return errors.Newf(token.NoPos, "cannot delete %v: field not found", p)
+	}
+	var edits []Edit
+	seen := map[*ast.Field]bool{}
+	for _, n := range levels[len(sels)] {
+		if !seen[n.field] {
+			seen[n.field] = true
+			edits = append(edits, n.delete())
+		}
+	}
+	if err := e.apply(edits); err != nil {
+		return wrapError(err, p, "delete")
+	}
+	return nil
+}
+
+// wrapError reports that the given operation on path p failed because of err.
+// The path is omitted from the message if every error in err already
+// reports it.
+func wrapError(err error, p cue.Path, op string) error {
+	for _, e := range errors.Errors(err) {
+		if !samePath(e.Path(), p.Selectors()) {
+			return errors.Wrapf(err, token.NoPos, "cannot %s %v", op, p)
+		}
+	}
+	return errors.Wrapf(err, token.NoPos, "cannot %s value", op)
+}
+
+func samePath(path []string, sels []cue.Selector) bool {
+	if len(path) != len(sels) {
+		return false
+	}
+	for i, sel := range sels {
+		if path[i] != sel.String() {
+			return false
+		}
+	}
+	return true
+}
+
+func selectors(p cue.Path) ([]cue.Selector, error) {
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	sels := p.Selectors()
+	if len(sels) == 0 {
+		return nil, errors.Newf(token.NoPos, "empty path")
+	}
+	for _, sel := range sels {
+		switch {
+		case sel.ConstraintType() != 0:
+			return nil, errors.Newf(token.NoPos, "%v: path must consist of regular fields and list indices", p)
+		case sel.Type() == cue.IndexLabel, sel.Type() == cue.StringLabel, sel.Type() == cue.DefinitionLabel:
+		default:
+			return nil, errors.Newf(token.NoPos, "%v: unsupported selector %v", p, sel)
+		}
+	}
+	return sels, nil
+}
+
+// format returns the CUE syntax of x.
+func (e *Editor) format(x any) (string, error) {
+	v := e.ctx.Encode(x)
+	if err := v.Err(); err != nil {
+		return "", err
+	}
+	b, err := format.Node(v.Syntax(cue.Final(), cue.Concrete(true)))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// A node is a value expression found while looking up a path. A nil expr
+// stands for the top level of a file.
+type node struct {
+	file   *file
+	field  *ast.Field // the field declaring expr, if any
+	expr   ast.Expr
+	parent *node
+}
+
+// decls returns the declarations of n if it is a struct.
+func (n *node) decls() ([]ast.Decl, bool) {
+	if n.expr == nil {
+		return n.file.ast.Decls, true
+	}
+	if s, ok := n.expr.(*ast.StructLit); ok {
+		return s.Elts, true
+	}
+	return nil, false
+}
+
+// braced reports whether a field can be added to n, which is the case for
+// the top level of a file and for struct literals with braces.
+func (n *node) braced() bool {
+	if n.expr == nil {
+		return true
+	}
+	s, ok := n.expr.(*ast.StructLit)
+	return ok && s.Lbrace.IsValid() && s.Rbrace.IsValid()
+}
+
+// lookup returns, for each prefix of sels that is declared in the source,
+// the values declared for that prefix. The first level holds the top
+// level of each file.
+func (e *Editor) lookup(sels []cue.Selector) [][]*node {
+	var cur []*node
+	for _, f := range e.files {
+		if f.editable {
+			cur = append(cur, &node{file: f})
+		}
+	}
+	levels := [][]*node{cur}
+	for _, sel := range sels {
+		var next []*node
+		for _, n := range cur {
+			if sel.Type() == cue.IndexLabel {
+				l, ok := n.expr.(*ast.ListLit)
+				if ok && sel.Index() < len(l.Elts) {
+					if _, ok := l.Elts[sel.Index()].(*ast.Ellipsis); !ok {
+						next = append(next, n.children(nil, l.Elts[sel.Index()])...)
+					}
+				}
+				continue
+			}
+			decls, _ := n.decls()
+			for _, f := range fields(decls) {
+				if matches(f, sel) {
+					next = append(next, n.children(f, f.Value)...)
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		levels = append(levels, next)
+		cur = next
+	}
+	return levels
+}
+
+// children returns the nodes for the value x of a field or list element
+// within n, one for each operand if x is a conjunction, so that the
+// struct literals of a value like schema.#Service & {image: "nginx"} are
+// looked into.
+func (n *node) children(f *ast.Field, x ast.Expr) []*node {
+	switch y := x.(type) {
+	case *ast.ParenExpr:
+		return n.children(f, y.X)
+	case *ast.BinaryExpr:
+		if y.Op == token.AND {
+			return append(n.children(f, y.X), n.children(f, y.Y)...)
+		}
+	}
+	return []*node{{file: n.file, field: f, expr: x, parent: n}}
+}
+
+// fields returns the fields of decls, including those of embedded struct
+// literals.
+func fields(decls []ast.Decl) []*ast.Field {
+	var a []*ast.Field
+	for _, d := range decls {
+		switch d := d.(type) {
+		case *ast.Field:
+			a = append(a, d)
+		case *ast.EmbedDecl:
+			if s, ok := d.Expr.(*ast.StructLit); ok {
+				a = append(a, fields(s.Elts)...)
+			}
+		}
+	}
+	return a
+}
+
+// matches reports whether f is a regular field with the label of sel.
+func matches(f *ast.Field, sel cue.Selector) bool {
+	if f.Constraint != token.ILLEGAL {
+		return false
+	}
+	name, isIdent, err := ast.LabelName(f.Label)
+	if err != nil {
+		return false
+	}
+	switch {
+	case isIdent && internal.IsDef(name):
+		return sel.Type() == cue.DefinitionLabel && sel.String() == name
+	case isIdent && internal.IsHidden(name):
+		return false
+	}
+	return sel.Type() == cue.StringLabel && sel.Unquoted() == name
+}
+
+// isLiteral reports whether x is a literal value, which can be replaced
+// without changing the meaning of the surrounding configuration.
+func isLiteral(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.UnaryExpr:
+		_, ok := x.X.(*ast.BasicLit)
+		return ok && (x.Op == token.SUB || x.Op == token.ADD)
+	case *ast.ListLit:
+		for _, elt := range x.Elts {
+			if !isLiteral(elt) {
+				return false
+			}
+		}
+		return true
+	case *ast.StructLit:
+		for _, d := range x.Elts {
+			f, ok := d.(*ast.Field)
+			if !ok || f.Constraint != token.ILLEGAL || len(f.Attrs) > 0 || !isLiteral(f.Value) {
+				return false
+			}
+			if _, _, err := ast.LabelName(f.Label); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// replace returns the edit replacing the value of n with text.
+func (n *node) replace(text string) Edit {
+	src := n.file.src
+	start, end := n.expr.Pos().Offset(), n.expr.End().Offset()
+	return Edit{
+		Filename: n.file.name,
+		Start:    start,
+		End:      end,
+		Text:     indent(text, lineIndent(src, start)),
+	}
+}
+
+// insert returns the edit adding a field for sels to the innermost struct
+// literal enclosing it.
+func insert(levels [][]*node, sels []cue.Selector, text string) (Edit, error) {
+	// The last level need not be considered, as it holds the non-literal
+	// values of the path itself, if any.
+	for i := min(len(levels), len(sels)) - 1; i >= 0; i-- {
+		var parent *node
+		for _, n := range levels[i] {
+			if n.braced() {
+				parent = n
+			}
+		}
+		if parent == nil {
+			continue
+		}
+		var label strings.Builder
+		for _, sel := range sels[i:] {
+			if sel.Type() == cue.IndexLabel {
+				return Edit{}, errors.Newf(token.NoPos, "list element %v does not exist", sel)
+			}
+			fmt.Fprintf(&label, "%v: ", sel)
+		}
+		return parent.add(label.String() + text), nil
+	}
+	return Edit{}, errors.Newf(token.NoPos, "no struct literal to add the field to")
+}
+
+// add returns the edit adding the declaration text at the end of n.
+func (n *node) add(text string) Edit {
+	src := n.file.src
+	ed := Edit{Filename: n.file.name}
+	if n.expr == nil {
+		ed.Start = len(src)
+		ed.Text = text + "\n"
+		if len(src) > 0 && src[len(src)-1] != '\n' {
+			ed.Text = "\n" + ed.Text
+		}
+		ed.End = ed.Start
+		return ed
+	}
+	s := n.expr.(*ast.StructLit)
+	rbrace := s.Rbrace.Offset()
+	lineStart := bytes.LastIndexByte(src[:rbrace], '\n') + 1
+	switch {
+	case s.Lbrace.Line() != s.Rbrace.Line() && isSpace(src[lineStart:rbrace]):
+		// Add a line before the closing brace.
+		ind := string(src[lineStart:rbrace]) + "\t"
+		if len(s.Elts) > 0 {
+			ind = lineIndent(src, s.Elts[len(s.Elts)-1].Pos().Offset())
+		}
+		ed.Start = lineStart
+		ed.Text = ind + indent(text, ind) + "\n"
+	case len(s.Elts) > 0:
+		ed.Start = s.Elts[len(s.Elts)-1].End().Offset()
+		ed.Text = ", " + text
+	default:
+		ed.Start = rbrace
+		ed.Text = text
+	}
+	ed.End = ed.Start
+	return ed
+}
+
+// delete returns the edit removing the field of n, or the field declaring
+// its parent if the field is the only one of a struct literal without
+// braces, as in a: b: 1.
+func (n *node) delete() Edit {
+	for n.parent != nil && n.parent.field != nil && !n.parent.braced() {
+		if decls, _ := n.parent.decls(); len(decls) != 1 {
+			break
+		}
+		n = n.parent
+	}
+	src := n.file.src
+	start, end := n.field.Pos().Offset(), n.field.End().Offset()
+	for _, cg := range ast.Comments(n.field) {
+		if cg.Doc && cg.Pos().Offset() < start {
+			start = cg.Pos().Offset()
+		}
+		if cg.Line && cg.End().Offset() > end {
+			end = cg.End().Offset()
+		}
+	}
+	lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+	lineEnd := len(src)
+	if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+		lineEnd = end + i + 1
+	}
+	rest := bytes.TrimSpace(src[end:lineEnd])
+	rest = bytes.TrimSpace(bytes.TrimPrefix(rest, []byte(",")))
+	if isSpace(src[lineStart:start]) && (len(rest) == 0 || bytes.HasPrefix(rest, []byte("//"))) {
+		// Remove whole lines, and a blank line if the field was
+		// surrounded by blank lines.
+		start, end = lineStart, lineEnd
+		prevBlank := start == 0 || start >= 2 && src[start-2] == '\n'
+		if prevBlank {
+			if i := bytes.IndexByte(src[end:], '\n'); i >= 0 && isSpace(src[end:end+i]) {
+				end += i + 1
+			}
+		}
+		return Edit{Filename: n.file.name, Start: start, End: end}
+	}
+	// Remove the field from a line holding other declarations, along with
+	// a separating comma.
+	if i := end + len(src[end:]) - len(bytes.TrimLeft(src[end:], " \t")); i < len(src) && src[i] == ',' {
+		end = i + 1
+		end += len(src[end:]) - len(bytes.TrimLeft(src[end:], " \t"))
+	} else if j := len(bytes.TrimRight(src[:start], " \t")); j > 0 && src[j-1] == ',' {
+		start = j - 1
+	}
+	return Edit{Filename: n.file.name, Start: start, End: end}
+}
+
+// apply applies edits to the files and rebuilds the instance. It reverts
+// the edits if the result is not valid.
+func (e *Editor) apply(edits []Edit) error {
+	// Apply the edits of each file from the end, so that the offsets of
+	// the remaining edits remain valid.
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].Filename != edits[j].Filename {
+			return edits[i].Filename < edits[j].Filename
+		}
+		return edits[i].Start > edits[j].Start
+	})
+	type saved struct {
+		src []byte
+		ast *ast.File
+	}
+	old := map[*file]saved{}
+	for _, f := range e.files {
+		old[f] = saved{f.src, f.ast}
+	}
+	revert := func() {
+		for f, s := range old {
+			f.src, f.ast = s.src, s.ast
+		}
+	}
+	for _, ed := range edits {
+		f := e.file(ed.Filename)
+		src := make([]byte, 0, len(f.src)+len(ed.Text))
+		src = append(src, f.src[:ed.Start]...)
+		src = append(src, ed.Text...)
+		src = append(src, f.src[ed.End:]...)
+		f.src = src
+	}
+	for _, f := range e.files {
+		if !f.editable || bytes.Equal(f.src, old[f].src) {
+			continue
+		}
+		af, err := parser.ParseFile(f.name, f.src, parser.ParseComments)
+		if err != nil {
+			revert()
+			return err
+		}
+		f.ast = af
+	}
+	v, err := e.build()
+	if err != nil {
+		revert()
+		return err
+	}
+	e.value = v
+	e.edits = append(e.edits, edits...)
+	return nil
+}
+
+func (e *Editor) file(name string) *file {
+	for _, f := range e.files {
+		if f.name == name {
+			return f
+		}
+	}
+	panic("unknown file " + name)
+}
+
+// build builds a copy of the instance with the current syntax of the files
+// and reports an error if the result is invalid.
+func (e *Editor) build() (cue.Value, error) {
+	inst := *e.inst
+	inst.Files = nil
+	for _, f := range e.files {
+		inst.Files = append(inst.Files, f.ast)
+	}
+	v := e.ctx.BuildInstance(&inst)
+	if err := v.Validate(); err != nil {
+		return cue.Value{}, err
+	}
+	return v, nil
+}
+
+// lineIndent returns the indentation of the line holding offset off.
+func lineIndent(src []byte, off int) string {
+	start := bytes.LastIndexByte(src[:off], '\n') + 1
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return string(src[start:end])
+}
+
+// indent indents all but the first line of text with ind.
+func indent(text, ind string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+ind)
+}
+
+func isSpace(b []byte) bool {
+	return len(bytes.TrimSpace(b)) == 0
+}
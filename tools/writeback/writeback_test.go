@@ -0,0 +1,330 @@
+// Copyright 2026 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writeback_test
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/txtar"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/internal/cuetxtar"
+	"cuelang.org/go/tools/writeback"
+)
+
+const schema = `
+-- cue.mod/module.cue --
+module: "mod.test"
+language: version: "v0.8.0"
+-- schema/schema.cue --
+package schema
+
+#Service: {
+	image!:    string
+	replicas?: int & >0
+	ports?: [...int]
+}
+`
+
+func TestEditor(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		edit func(e *writeback.Editor) error
+		out  string
+		err  string
+	}{{
+		name: "replace",
+		in: `
+-- a.cue --
+package a
+
+import "mod.test/schema"
+
+// The web frontend.
+web: schema.#Service & {
+	image:    "nginx:1.26" // pinned
+	replicas: 2
+}
+`,
+		edit: func(e *writeback.Editor) error {
+			return e.FillPath(cue.ParsePath("web.image"), "nginx:1.27")
+		},
+		out: `
+-- a.cue --
+package a
+
+import "mod.test/schema"
+
+// The web frontend.
+web: schema.#Service & {
+	image:    "nginx:1.27" // pinned
+	replicas: 2
+}
+`,
+	}, {
+		name: "replace all declarations",
+		in: `
+-- a.cue --
+package a
+
+web: image: "nginx:1.26"
+-- b.cue --
+package a
+
+web: {image: "nginx:1.26", replicas: 1}
+`,
+		edit: func(e *writeback.Editor) error {
+			return e.FillPath(cue.ParsePath("web.image"), "nginx:1.27")
+		},
+		out: `
+-- a.cue --
+package a
+
+web: image: "nginx:1.27"
+-- b.cue --
+package a
+
+web: {image: "nginx:1.27", replicas: 1}
+`,
+	}, {
+		name: "list element and list",
+		in: `
+-- a.cue --
+package a
+
+web: {
+	containers: [{image: "nginx:1.26"}, {image: "envoy:1.0"}]
+	ports: [80,
+		443]
+}
+`,
+		edit: func(e *writeback.Editor) error {
+			if err := e.FillPath(cue.ParsePath("web.containers[1].image"), "envoy:1.1"); err != nil {
+				return err
+			}
+			return e.FillPath(cue.ParsePath("web.ports"), []int{8080})
+		},
+		out: `
+-- a.cue --
+package a
+
+web: {
+	containers: [{image: "nginx:1.26"}, {image: "envoy:1.1"}]
+	ports: [8080]
+}
+`,
+	}, {
+		name: "add",
+		in: `
+-- a.cue --
+package a
+
+import "mod.test/schema"
+
+web: schema.#Service & {
+	image: "nginx:1.26"
+}
+db: {}
+cache: {size: 1}
+`,
+		edit: func(e *writeback.Editor) error {
+			for _, s := range []struct {
+				path string
+				x    any
+			}{
+				{"web.replicas", 3},
+				{"db.image", "postgres:16"},
+				{"cache.ttl", "1h"},
+				{"worker.image", "worker:1"},
+				{"cache.labels", map[string]string{"app": "cache"}},
+			} {
+				if err := e.FillPath(cue.ParsePath(s.path), s.x); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		out: `
+-- a.cue --
+package a
+
+import "mod.test/schema"
+
+web: schema.#Service & {
+	image: "nginx:1.26"
+	replicas: 3
+}
+db: {image: "postgres:16"}
+cache: {size: 1, ttl: "1h", labels: {
+	app: "cache"
+}}
+worker: image: "worker:1"
+`,
+	}, {
+		name: "add beside constraint",
+		in: `
+-- a.cue --
+package a
+
+web: {
+	replicas: int
+}
+`,
+		edit: func(e *writeback.Editor) error {
+			return e.FillPath(cue.ParsePath("web.replicas"), 2)
+		},
+		out: `
+-- a.cue --
+package a
+
+web: {
+	replicas: int
+	replicas: 2
+}
+`,
+	}, {
+		name: "delete",
+		in: `
+-- a.cue --
+package a
+
+web: {
+	image: "nginx:1.26"
+
+	// Number of instances.
+	replicas: 2
+
+	ports: [80]
+}
+db: {image: "postgres:16", replicas: 1}
+cache: size: 1
+`,
+		edit: func(e *writeback.Editor) error {
+			for _, p := range []string{"web.replicas", "db.image", "db.replicas", "cache.size"} {
+				if err := e.DeletePath(cue.ParsePath(p)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		out: `
+-- a.cue --
+package a
+
+web: {
+	image: "nginx:1.26"
+
+	ports: [80]
+}
+db: {}
+`,
+	}, {
+		name: "schema violation",
+		in: `
+-- a.cue --
+package a
+
+import "mod.test/schema"
+
+web: schema.#Service & {
+	image:    "nginx:1.26"
+	replicas: 2
+}
+`,
+		edit: func(e *writeback.Editor) error {
+			return e.FillPath(cue.ParsePath("web.replicas"), 0)
+		},
+		err: "cannot set value: web.replicas: invalid value 0 (out of bound >0)",
+	}, {
+		name: "delete missing field",
+		in: `
+-- a.cue --
+package a
+
+web: image: "nginx:1.26"
+`,
+		edit: func(e *writeback.Editor) error {
+			return e.DeletePath(cue.ParsePath("web.replicas"))
+		},
+		err: "cannot delete web.replicas: field not found",
+	}, {
+		name: "missing list element",
+		in: `
+-- a.cue --
+package a
+
+ports: [80]
+`,
+		edit: func(e *writeback.Editor) error {
+			return e.FillPath(cue.ParsePath("ports[1]"), 443)
+		},
+		err: "cannot set ports[1]: list element 1 does not exist",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			in := txtar.Parse([]byte(schema + tc.in))
+			inst := cuetxtar.Load(in, dir, ".")[0]
+			if inst.Err != nil {
+				t.Fatal(inst.Err)
+			}
+			ctx := cuecontext.New()
+			e, err := writeback.New(ctx, inst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tc.edit(e)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("got error %v; want %q", err, tc.err)
+				}
+				if len(e.Files()) != 0 || len(e.Edits()) != 0 {
+					t.Errorf("files changed by a failed edit")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files := e.Files()
+			var names []string
+			for name := range files {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			a := &txtar.Archive{}
+			for _, name := range names {
+				rel, _ := filepath.Rel(dir, name)
+				a.Files = append(a.Files, txtar.File{
+					Name: filepath.ToSlash(rel),
+					Data: files[name],
+				})
+			}
+			got := string(txtar.Format(a))
+			if want := strings.TrimPrefix(tc.out, "\n"); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+			if err := e.Value().Validate(); err != nil {
+				t.Errorf("invalid result: %v", err)
+			}
+		})
+	}
+}